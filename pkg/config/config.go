@@ -17,6 +17,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
 	"reflect"
@@ -37,6 +38,7 @@ var (
 		".",
 		".zt",
 		".zarf-testing",
+		"etc",
 		filepath.Join(homeDir, ".zt"),
 		filepath.Join(homeDir, ".zarf-testing"),
 		"/usr/local/etc/zt",
@@ -49,44 +51,185 @@ var (
 	}
 )
 
+// defaults are the values of the configuration keys that are not set by a flag, an
+// environment variable or the config file
+var defaults = map[string]interface{}{
+	"kubectl-timeout":           30 * time.Second,
+	"deployment-timeout":        10 * time.Minute,
+	"test-timeout":              5 * time.Minute,
+	"resilience-timeout":        5 * time.Minute,
+	"print-logs":                false,
+	"zarf-dirs":                 []string{"packages"},
+	"remote":                    "origin",
+	"target-branch":             "main",
+	"since":                     "HEAD",
+	"check-version-increment":   true,
+	"validate-image-pinning":    true,
+	"validate-package-schema":   true,
+	"validate-components":       true,
+	"validate-yaml":             true,
+	"fail-on":                   "error",
+	"max-warnings":              -1,
+	"max-chart-minor-behind":    3,
+	"parallel-deploys":          1,
+	"soak":                      1,
+	"report-format":             "markdown",
+	"metrics-job":               "zt",
+	"large-file-warning":        "50MB",
+	"large-file-limit":          "100MB",
+	"repo-size-budget":          "0",
+	"plugins":                   true,
+	"scan-secrets":              true,
+	"pss-level":                 "baseline",
+	"validate-rbac":             true,
+	"validate-network-policies": true,
+	"keep-going":                true,
+	"mirror-registry-username":  "",
+	"mirror-registry-password":  "",
+	"mirror-git-username":       "",
+	"mirror-git-password":       "",
+}
+
+// Cluster is a cluster packages are installed into, selected by a kubeconfig context
+// and/or a kubeconfig file, or a kind cluster created for a Kubernetes version
+type Cluster struct {
+	Name        string `mapstructure:"name"`
+	Context     string `mapstructure:"context"`
+	Kubeconfig  string `mapstructure:"kubeconfig"`
+	KubeVersion string `mapstructure:"kube-version"`
+}
+
+// NamingPolicy overrides the pattern and severity of the naming convention of an
+// entity ('package', 'component', 'namespace', 'release' or 'variable')
+type NamingPolicy struct {
+	Pattern  string `mapstructure:"pattern"`
+	Severity string `mapstructure:"severity"`
+}
+
 type Configuration struct {
 	// Git-related configuration
 	Remote                  string        `mapstructure:"remote"`
 	TargetBranch            string        `mapstructure:"target-branch"`
 	Since                   string        `mapstructure:"since"`
+	AutoFetch               bool          `mapstructure:"auto-fetch"`
+	CacheDir                string        `mapstructure:"cache-dir"`
 	
 	// General configuration
+	Profile                 string        `mapstructure:"profile"`
 	BuildID                 string        `mapstructure:"build-id"`
 	Debug                   bool          `mapstructure:"debug"`
 	GithubGroups            bool          `mapstructure:"github-groups"`
 	
 	// Zarf package configuration
 	ZarfDirs                []string      `mapstructure:"zarf-dirs"`
+	BundleDirs              []string      `mapstructure:"bundle-dirs"`
 	ExcludedPackages        []string      `mapstructure:"excluded-packages"`
+	Selector                string        `mapstructure:"selector"`
 	Packages                []string      `mapstructure:"packages"`
 	ProcessAllPackages      bool          `mapstructure:"all"`
+	KeepGoing               bool          `mapstructure:"keep-going"`
 	
 	// Validation configuration
 	CheckVersionIncrement   bool          `mapstructure:"check-version-increment"`
+	RequireMajorBumpOnRemoval bool        `mapstructure:"require-major-bump-on-removal"`
 	ValidateImagePinning    bool          `mapstructure:"validate-image-pinning"`
 	ValidatePackageSchema   bool          `mapstructure:"validate-package-schema"`
 	ValidateComponents      bool          `mapstructure:"validate-components"`
 	ExcludeDeprecated       bool          `mapstructure:"exclude-deprecated"`
+	KubeVersion             string        `mapstructure:"kube-version"`
+	ValidateYaml            bool          `mapstructure:"validate-yaml"`
+	LintConf                string        `mapstructure:"lint-conf"`
+	FailOn                  string        `mapstructure:"fail-on"`
+	MaxWarnings             int           `mapstructure:"max-warnings"`
+	Baseline                string        `mapstructure:"baseline"`
+	WriteBaseline           string        `mapstructure:"write-baseline"`
+	LargeFileWarning        string        `mapstructure:"large-file-warning"`
+	LargeFileLimit          string        `mapstructure:"large-file-limit"`
+	ZarfVersions            []string      `mapstructure:"zarf-versions"`
+	Plugins                 bool          `mapstructure:"plugins"`
+	Incremental             bool          `mapstructure:"incremental"`
+	ScanSecrets             bool          `mapstructure:"scan-secrets"`
+	PSSLevel                string        `mapstructure:"pss-level"`
+	ApprovedImages          []string      `mapstructure:"approved-images"`
+	RequiredImageLabel      string        `mapstructure:"required-image-label"`
+	ValidateRBAC            bool          `mapstructure:"validate-rbac"`
+	AllowedLicenses         []string      `mapstructure:"allowed-licenses"`
+	DeniedLicenses          []string      `mapstructure:"denied-licenses"`
+	SBOMDir                 string        `mapstructure:"sbom-dir"`
+	ValidateNetworkPolicies bool          `mapstructure:"validate-network-policies"`
+	ResolveOCIImports       bool          `mapstructure:"resolve-oci-imports"`
+	StrictYaml              bool          `mapstructure:"strict-yaml"`
+	Architecture            string        `mapstructure:"architecture"`
+	ValidateDocs            bool          `mapstructure:"validate-docs"`
+	RequireMaintainers      bool          `mapstructure:"require-maintainers"`
+	CheckRepos              bool          `mapstructure:"check-repos"`
+	RepoSizeBudget          string        `mapstructure:"repo-size-budget"`
+	CheckChartDrift         bool          `mapstructure:"check-chart-drift"`
+	MaxChartMajorBehind     int           `mapstructure:"max-chart-major-behind"`
+	MaxChartMinorBehind     int           `mapstructure:"max-chart-minor-behind"`
+	Fix                     bool          `mapstructure:"fix"`
+	CheckDuplicateVersions  bool          `mapstructure:"check-duplicate-versions"`
+	CheckNamespaceCollisions bool         `mapstructure:"check-namespace-collisions"`
+	NamingPolicies          map[string]NamingPolicy `mapstructure:"naming-policies"`
+	ActionRules             map[string]string `mapstructure:"action-rules"`
+	SecretsAllowlist        []string      `mapstructure:"secrets-allowlist"`
+	PluginsDir              []string      `mapstructure:"plugins-dir"`
+	InstallZarf             string        `mapstructure:"install-zarf"`
+	ReportFile              string        `mapstructure:"report-file"`
+	ReportFormat            string        `mapstructure:"report-format"`
+	MetricsFile             string        `mapstructure:"metrics-file"`
+	MetricsPushgateway      string        `mapstructure:"metrics-pushgateway"`
+	MetricsJob              string        `mapstructure:"metrics-job"`
 	
 	// Zarf CLI configuration
 	ZarfExtraArgs           string        `mapstructure:"zarf-extra-args"`
 	ZarfLintExtraArgs       string        `mapstructure:"zarf-lint-extra-args"`
 	ZarfBuildExtraArgs      string        `mapstructure:"zarf-build-extra-args"`
 	ZarfDeployExtraArgs     string        `mapstructure:"zarf-deploy-extra-args"`
+	ZarfInitArgs            string        `mapstructure:"zarf-init-args"`
+	ZarfInitComponents      []string      `mapstructure:"zarf-init-components"`
+	SkipZarfInit            bool          `mapstructure:"skip-zarf-init"`
+	InitPackage             string        `mapstructure:"init-package"`
 	
 	// Deployment testing configuration
 	Upgrade                 bool          `mapstructure:"upgrade"`
 	SkipCleanUp             bool          `mapstructure:"skip-clean-up"`
+	CheckCleanup            bool          `mapstructure:"check-cleanup"`
+	Soak                    int           `mapstructure:"soak"`
+	Resilience              bool          `mapstructure:"resilience"`
+	TestRemoval             bool          `mapstructure:"test-removal"`
+	MirrorResources         bool          `mapstructure:"mirror-resources"`
+	MirrorRegistryURL       string        `mapstructure:"mirror-registry-url"`
+	MirrorRegistryUsername  string        `mapstructure:"mirror-registry-username"`
+	MirrorRegistryPassword  string        `mapstructure:"mirror-registry-password"`
+	MirrorGitURL            string        `mapstructure:"mirror-git-url"`
+	MirrorGitUsername       string        `mapstructure:"mirror-git-username"`
+	MirrorGitPassword       string        `mapstructure:"mirror-git-password"`
+	ResilienceTimeout       time.Duration `mapstructure:"resilience-timeout"`
+	Benchmark               bool          `mapstructure:"benchmark"`
+	BenchmarkHistory        string        `mapstructure:"benchmark-history"`
+	MaxDeployTime           time.Duration `mapstructure:"max-deploy-time"`
+	MaxImagePullTime        time.Duration `mapstructure:"max-image-pull-time"`
+	MaxReadyTime            time.Duration `mapstructure:"max-ready-time"`
+	MaxDeployRegression     float64       `mapstructure:"max-deploy-regression"`
+	DeployOrder             []string      `mapstructure:"deploy-order"`
+	DeploySets              []string      `mapstructure:"deploy-set"`
+	ComponentMatrix         string        `mapstructure:"component-matrix"`
+	ParallelDeploys         int           `mapstructure:"parallel-deploys"`
+	WaitForCluster          time.Duration `mapstructure:"wait-for-cluster"`
+	KubeContexts            []string      `mapstructure:"kube-context"`
+	KubeVersions            []string      `mapstructure:"kube-versions"`
+	Clusters                []Cluster     `mapstructure:"clusters"`
 	Namespace               string        `mapstructure:"namespace"`
 	DeploymentTimeout       time.Duration `mapstructure:"deployment-timeout"`
 	TestTimeout             time.Duration `mapstructure:"test-timeout"`
+	RunTimeout              time.Duration `mapstructure:"run-timeout"`
 	KubectlTimeout          time.Duration `mapstructure:"kubectl-timeout"`
 	PrintLogs               bool          `mapstructure:"print-logs"`
+	ArtifactsDir            string        `mapstructure:"artifacts-dir"`
+	BuildCacheDir           string        `mapstructure:"build-cache-dir"`
+	EphemeralCluster        bool          `mapstructure:"ephemeral-cluster"`
+	KindVersion             string        `mapstructure:"kind-version"`
 	
 	// Legacy chart-testing compatibility (kept for migration)
 	ChartDirs               []string      `mapstructure:"chart-dirs"`
@@ -97,19 +240,9 @@ type Configuration struct {
 func LoadConfiguration(cfgFile string, cmd *cobra.Command, printConfig bool) (*Configuration, error) {
 	v := viper.New()
 
-	// Set Zarf-specific defaults
-	v.SetDefault("kubectl-timeout", 30*time.Second)
-	v.SetDefault("deployment-timeout", 10*time.Minute)
-	v.SetDefault("test-timeout", 5*time.Minute)
-	v.SetDefault("print-logs", bool(true))
-	v.SetDefault("zarf-dirs", []string{"packages"})
-	v.SetDefault("remote", "origin")
-	v.SetDefault("target-branch", "main")
-	v.SetDefault("since", "HEAD")
-	v.SetDefault("check-version-increment", true)
-	v.SetDefault("validate-image-pinning", true)
-	v.SetDefault("validate-package-schema", true)
-	v.SetDefault("validate-components", true)
+	for key, value := range defaults {
+		v.SetDefault(key, value)
+	}
 
 	cmd.Flags().VisitAll(func(flag *flag.Flag) {
 		flagName := flag.Name
@@ -125,46 +258,14 @@ func LoadConfiguration(cfgFile string, cmd *cobra.Command, printConfig bool) (*C
 	v.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	v.SetEnvPrefix("ZT") // Use ZT prefix for Zarf Testing
 
-	if cfgFile != "" {
-		v.SetConfigFile(cfgFile)
-		if err := v.ReadInConfig(); err != nil {
-			return nil, fmt.Errorf("failed loading config file: %w", err)
-		}
-		if printConfig {
-			fmt.Fprintln(os.Stderr, "Using config file:", v.ConfigFileUsed())
-		}
-	} else {
-		// Look for both zt and ct config files for backward compatibility
-		configNames := []string{"zt", "zarf-testing", "ct"}
-		var configFound bool
-		
-		for _, configName := range configNames {
-			v.SetConfigName(configName)
-			
-			if cfgDir, ok := os.LookupEnv("ZT_CONFIG_DIR"); ok {
-				v.AddConfigPath(cfgDir)
-			} else if cfgDir, ok := os.LookupEnv("CT_CONFIG_DIR"); ok {
-				// Legacy support
-				v.AddConfigPath(cfgDir)
-			} else {
-				for _, searchLocation := range configSearchLocations {
-					v.AddConfigPath(searchLocation)
-				}
-			}
-			
-			if err := v.ReadInConfig(); err == nil {
-				configFound = true
-				if printConfig {
-					fmt.Fprintln(os.Stderr, "Using config file:", v.ConfigFileUsed())
-				}
-				break
-			}
-		}
-		
-		if !configFound {
-			// No config file found, proceed with defaults
-			v.SetConfigName("zt")
-		}
+	if err := readInConfig(v, cfgFile); err != nil {
+		return nil, err
+	}
+	if err := applyProfile(v); err != nil {
+		return nil, err
+	}
+	if printConfig && v.ConfigFileUsed() != "" {
+		fmt.Fprintln(os.Stderr, "Using config file:", v.ConfigFileUsed())
 	}
 
 	isInstall := strings.Contains(cmd.Use, "install")
@@ -179,6 +280,103 @@ func LoadConfiguration(cfgFile string, cmd *cobra.Command, printConfig bool) (*C
 		return nil, errors.New("specifying both, '--all' and '--packages', is not allowed")
 	}
 	
+	switch cfg.FailOn {
+	case "error", "warning", "never":
+	default:
+		return nil, fmt.Errorf("invalid value %q for '--fail-on', must be one of: error, warning, never", cfg.FailOn)
+	}
+	
+	switch cfg.PSSLevel {
+	case "privileged", "baseline", "restricted":
+	default:
+		return nil, fmt.Errorf("invalid value %q for '--pss-level', must be one of: privileged, baseline, restricted", cfg.PSSLevel)
+	}
+
+	switch cfg.Architecture {
+	case "", "amd64", "arm64":
+	default:
+		return nil, fmt.Errorf("invalid value %q for '--architecture', must be one of: amd64, arm64", cfg.Architecture)
+	}
+
+	if _, err := util.ParseSize(cfg.LargeFileWarning); err != nil {
+		return nil, fmt.Errorf("invalid value for '--large-file-warning': %w", err)
+	}
+	if _, err := util.ParseSize(cfg.LargeFileLimit); err != nil {
+		return nil, fmt.Errorf("invalid value for '--large-file-limit': %w", err)
+	}
+	if _, err := util.ParseSize(cfg.RepoSizeBudget); err != nil {
+		return nil, fmt.Errorf("invalid value for '--repo-size-budget': %w", err)
+	}
+
+	if cfg.ParallelDeploys < 1 {
+		return nil, fmt.Errorf("invalid value %d for '--parallel-deploys', must be at least 1", cfg.ParallelDeploys)
+	}
+	if cfg.MaxDeployRegression < 0 {
+		return nil, fmt.Errorf("invalid value %g for '--max-deploy-regression', must not be negative", cfg.MaxDeployRegression)
+	}
+	if cfg.MaxDeployRegression > 0 && cfg.BenchmarkHistory == "" {
+		return nil, errors.New("'--max-deploy-regression' requires '--benchmark-history'")
+	}
+	if cfg.Soak < 1 {
+		return nil, fmt.Errorf("invalid value %d for '--soak', must be at least 1", cfg.Soak)
+	}
+	if cfg.Soak > 1 && cfg.SkipCleanUp {
+		return nil, errors.New("specifying both, '--soak' and '--skip-clean-up', is not allowed")
+	}
+	if cfg.TestRemoval && cfg.SkipCleanUp {
+		return nil, errors.New("specifying both, '--test-removal' and '--skip-clean-up', is not allowed")
+	}
+	if cfg.MaxChartMajorBehind < 0 || cfg.MaxChartMinorBehind < 0 {
+		return nil, errors.New("'--max-chart-major-behind' and '--max-chart-minor-behind' must not be negative")
+	}
+	if cfg.InitPackage != "" && cfg.SkipZarfInit {
+		return nil, errors.New("specifying both, '--init-package' and '--skip-zarf-init', is not allowed")
+	}
+
+	// Each --kube-context adds a cluster named after the context
+	for _, kubeContext := range cfg.KubeContexts {
+		cfg.Clusters = append(cfg.Clusters, Cluster{Name: kubeContext, Context: kubeContext})
+	}
+	// Each --kube-versions entry adds a kind cluster running that version
+	for _, version := range cfg.KubeVersions {
+		cfg.Clusters = append(cfg.Clusters, Cluster{Name: "k8s-" + strings.TrimPrefix(version, "v"), KubeVersion: version})
+	}
+	clusterNames := map[string]bool{}
+	for i, cluster := range cfg.Clusters {
+		if cluster.KubeVersion != "" && (cluster.Context != "" || cluster.Kubeconfig != "") {
+			return nil, fmt.Errorf("cluster %d must not specify a context or a kubeconfig with a kube-version", i+1)
+		}
+		if cluster.Context == "" && cluster.Kubeconfig == "" && cluster.KubeVersion == "" {
+			return nil, fmt.Errorf("cluster %d must specify a context, a kubeconfig or a kube-version", i+1)
+		}
+		if cluster.Name == "" {
+			switch {
+			case cluster.Context != "":
+				cfg.Clusters[i].Name = cluster.Context
+			case cluster.Kubeconfig != "":
+				cfg.Clusters[i].Name = cluster.Kubeconfig
+			default:
+				cfg.Clusters[i].Name = "k8s-" + strings.TrimPrefix(cluster.KubeVersion, "v")
+			}
+		}
+		if clusterNames[cfg.Clusters[i].Name] {
+			return nil, fmt.Errorf("cluster %q is specified more than once", cfg.Clusters[i].Name)
+		}
+		clusterNames[cfg.Clusters[i].Name] = true
+	}
+
+	switch cfg.ReportFormat {
+	case "markdown", "json", "codequality":
+	default:
+		return nil, fmt.Errorf("invalid value %q for '--report-format', must be one of: markdown, json, codequality", cfg.ReportFormat)
+	}
+
+	switch cfg.ComponentMatrix {
+	case "", "full", "minimal", "each":
+	default:
+		return nil, fmt.Errorf("invalid value %q for '--component-matrix', must be one of: full, minimal, each", cfg.ComponentMatrix)
+	}
+
 	// Legacy chart-testing validation for backward compatibility (remove ProcessAllCharts)
 	if len(cfg.Charts) > 0 && cfg.ProcessAllPackages {
 		return nil, errors.New("specifying both, '--all' and '--charts', is not allowed")
@@ -195,6 +393,13 @@ func LoadConfiguration(cfgFile string, cmd *cobra.Command, printConfig bool) (*C
 		cfg.ExcludedPackages = cfg.ExcludedCharts
 	}
 
+	// Fall back to a lintconf.yaml from the config search locations
+	if cfg.ValidateYaml && cfg.LintConf == "" {
+		if lintConf, err := findConfigFile("lintconf.yaml"); err == nil {
+			cfg.LintConf = lintConf
+		}
+	}
+
 	// Disable upgrade (this does some expensive dependency building on previous revisions)
 	// when neither "install" nor "lint-and-install" have not been specified.
 	cfg.Upgrade = isInstall && cfg.Upgrade
@@ -204,13 +409,13 @@ func LoadConfiguration(cfgFile string, cmd *cobra.Command, printConfig bool) (*C
 
 	// Zarf-specific configuration handling
 	if len(cfg.Packages) > 0 || cfg.ProcessAllPackages {
-		fmt.Fprintln(os.Stderr, "Version increment checking disabled for specific packages.")
+		slog.Info("Version increment checking disabled for specific packages")
 		cfg.CheckVersionIncrement = false
 	}
 	
 	// Legacy support: disable version checking for charts too
 	if len(cfg.Charts) > 0 {
-		fmt.Fprintln(os.Stderr, "Version increment checking disabled.")
+		slog.Info("Version increment checking disabled")
 		cfg.CheckVersionIncrement = false
 	}
 
@@ -221,6 +426,61 @@ func LoadConfiguration(cfgFile string, cmd *cobra.Command, printConfig bool) (*C
 	return cfg, nil
 }
 
+// readInConfig reads cfgFile, or the first zt, zarf-testing or legacy ct config file
+// found in the config search locations, into v. It is not an error if no config file
+// is found, in which case v.ConfigFileUsed() is empty.
+func readInConfig(v *viper.Viper, cfgFile string) error {
+	if cfgFile != "" {
+		v.SetConfigFile(cfgFile)
+		if err := v.ReadInConfig(); err != nil {
+			return fmt.Errorf("failed loading config file: %w", err)
+		}
+		return nil
+	}
+
+	// Look for both zt and ct config files for backward compatibility
+	for _, configName := range []string{"zt", "zarf-testing", "ct"} {
+		v.SetConfigName(configName)
+
+		if cfgDir, ok := os.LookupEnv("ZT_CONFIG_DIR"); ok {
+			v.AddConfigPath(cfgDir)
+		} else if cfgDir, ok := os.LookupEnv("CT_CONFIG_DIR"); ok {
+			// Legacy support
+			v.AddConfigPath(cfgDir)
+		} else {
+			for _, searchLocation := range configSearchLocations {
+				v.AddConfigPath(searchLocation)
+			}
+		}
+
+		if err := v.ReadInConfig(); err == nil {
+			return nil
+		}
+	}
+
+	// No config file found, proceed with defaults
+	v.SetConfigName("zt")
+	return nil
+}
+
+// profilesKey is the config file key of the named profiles, e.g. profiles.ci
+const profilesKey = "profiles"
+
+// applyProfile merges the settings of the profile selected with --profile, ZT_PROFILE
+// or the profile key of the config file over the other settings of the config file.
+// Flags and environment variables still take precedence over the profile.
+func applyProfile(v *viper.Viper) error {
+	profile := v.GetString("profile")
+	if profile == "" {
+		return nil
+	}
+	key := profilesKey + "." + profile
+	if !v.IsSet(key) {
+		return fmt.Errorf("profile %q is not defined in the config file", profile)
+	}
+	return v.MergeConfigMap(v.GetStringMap(key))
+}
+
 func printCfg(cfg *Configuration) {
 	if !cfg.GithubGroups {
 		util.PrintDelimiterLineToWriter(os.Stderr, "-")
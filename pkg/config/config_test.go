@@ -34,34 +34,39 @@ func TestUnmarshalJson(t *testing.T) {
 }
 
 func loadAndAssertConfigFromFile(t *testing.T, configFile string) {
-	cfg, _ := LoadConfiguration(configFile, &cobra.Command{
+	cfg, err := LoadConfiguration(configFile, &cobra.Command{
 		Use: "install",
 	}, true)
+	require.NoError(t, err)
 
 	require.Equal(t, "origin", cfg.Remote)
 	require.Equal(t, "main", cfg.TargetBranch)
+	require.Equal(t, "HEAD~1", cfg.Since)
 	require.Equal(t, "pr-42", cfg.BuildID)
 	require.Equal(t, "my-lint-conf.yaml", cfg.LintConf)
-	require.Equal(t, "my-chart-yaml-schema.yaml", cfg.ChartYamlSchema)
-	require.Equal(t, true, cfg.ValidateMaintainers)
-	require.Equal(t, true, cfg.ValidateChartSchema)
 	require.Equal(t, true, cfg.ValidateYaml)
 	require.Equal(t, true, cfg.CheckVersionIncrement)
-	require.Equal(t, false, cfg.ProcessAllCharts)
-	require.Equal(t, []string{"incubator=https://incubator"}, cfg.ChartRepos)
-	require.Equal(t, []string{"incubator=--username test"}, cfg.HelmRepoExtraArgs)
-	require.Equal(t, []string{"stable", "incubator"}, cfg.ChartDirs)
-	require.Equal(t, []string{"common"}, cfg.ExcludedCharts)
-	require.Equal(t, "--timeout 300s", cfg.HelmExtraArgs)
-	require.Equal(t, "--quiet", cfg.HelmLintExtraArgs)
+	require.Equal(t, false, cfg.ProcessAllPackages)
+	require.Equal(t, []string{"packages", "examples"}, cfg.ZarfDirs)
+	require.Equal(t, []string{"common"}, cfg.ExcludedPackages)
+	require.Equal(t, "--log-level debug", cfg.ZarfExtraArgs)
+	require.Equal(t, "--quiet", cfg.ZarfLintExtraArgs)
 	require.Equal(t, true, cfg.Upgrade)
-	require.Equal(t, true, cfg.SkipMissingValues)
+	require.Equal(t, "warning", cfg.FailOn)
 	require.Equal(t, "default", cfg.Namespace)
-	require.Equal(t, "release", cfg.ReleaseLabel)
 	require.Equal(t, true, cfg.ExcludeDeprecated)
 	require.Equal(t, 120*time.Second, cfg.KubectlTimeout)
 	require.Equal(t, true, cfg.SkipCleanUp)
-	require.Equal(t, true, cfg.UseHelmignore)
+}
+
+func TestLegacyChartTestingKeys(t *testing.T) {
+	configFile := filepath.Join(t.TempDir(), "ct.yaml")
+	require.NoError(t, os.WriteFile(configFile, []byte("excluded-charts:\n  - common\n"), 0o644))
+
+	cfg, err := LoadConfiguration(configFile, &cobra.Command{Use: "lint"}, false)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"common"}, cfg.ExcludedPackages)
 }
 
 func Test_findConfigFile(t *testing.T) {
@@ -104,7 +109,9 @@ func Test_findConfigFile(t *testing.T) {
 					require.NoError(t, err)
 				})
 			}
+			searchLocations := configSearchLocations
 			configSearchLocations = []string{tt.defaultDir}
+			t.Cleanup(func() { configSearchLocations = searchLocations })
 
 			got, err := findConfigFile("test.yaml")
 			if tt.wantErr {
@@ -116,3 +123,13 @@ func Test_findConfigFile(t *testing.T) {
 		})
 	}
 }
+
+func TestLintConfInEtc(t *testing.T) {
+	t.Chdir(t.TempDir())
+	require.NoError(t, os.Mkdir("etc", 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join("etc", "lintconf.yaml"), []byte("extends: default\n"), 0o644))
+
+	cfg, err := LoadConfiguration("", &cobra.Command{Use: "lint"}, false)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join("etc", "lintconf.yaml"), cfg.LintConf)
+}
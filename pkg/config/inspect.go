@@ -0,0 +1,189 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// Sources of configuration values, in order of precedence
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceProfile = "profile"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// ResolvedValue is the effective value of a configuration key and where it came from
+type ResolvedValue struct {
+	Key    string      `json:"key" yaml:"key"`
+	Value  interface{} `json:"value" yaml:"value"`
+	Source string      `json:"source" yaml:"source"`
+}
+
+// Keys returns the keys of the configuration in the order of the Configuration fields
+func Keys() []string {
+	t := reflect.TypeOf(Configuration{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := t.Field(i).Tag.Get("mapstructure"); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// FindConfigFile returns the config file LoadConfiguration reads: cfgFile if not empty,
+// otherwise the first config file found in the config search locations. It returns an
+// empty path if there is none.
+func FindConfigFile(cfgFile string) (string, error) {
+	v := viper.New()
+	if err := readInConfig(v, cfgFile); err != nil {
+		return "", err
+	}
+	return v.ConfigFileUsed(), nil
+}
+
+// ValidateConfigFile checks that all keys of the config file and its profiles are
+// configuration keys and returns a problem for each unknown key, suggesting the key
+// that was likely meant
+func ValidateConfigFile(path string) ([]string, error) {
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed loading config file: %w", err)
+	}
+
+	known := map[string]bool{}
+	for _, key := range Keys() {
+		known[key] = true
+	}
+
+	var problems []string
+	seen := map[string]bool{}
+	for _, fullKey := range v.AllKeys() {
+		// Only top-level keys are checked, and the top-level keys of each profile
+		parts := strings.Split(fullKey, ".")
+		name, key := parts[0], parts[0]
+		if key == profilesKey {
+			if len(parts) < 3 {
+				continue
+			}
+			name, key = strings.Join(parts[:3], "."), parts[2]
+		}
+		if known[key] || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		problem := fmt.Sprintf("unknown key %q", name)
+		if suggestion := suggestKey(key); suggestion != "" {
+			problem += fmt.Sprintf(", did you mean %q?", suggestion)
+		}
+		problems = append(problems, problem)
+	}
+	sort.Strings(problems)
+	return problems, nil
+}
+
+// suggestKey returns the configuration key closest to an unknown key, e.g. target-branch
+// for target_branch, or an empty string if no key is similar
+func suggestKey(unknown string) string {
+	normalized := strings.ReplaceAll(strings.ToLower(unknown), "_", "-")
+	best, bestDistance := "", 3
+	for _, key := range Keys() {
+		if key == normalized {
+			return key
+		}
+		if distance := levenshtein(normalized, key); distance < bestDistance {
+			best, bestDistance = key, distance
+		}
+	}
+	return best
+}
+
+func levenshtein(a, b string) int {
+	previous := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current := make([]int, len(b)+1)
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous = current
+	}
+	return previous[len(b)]
+}
+
+// Resolve loads the configuration like LoadConfiguration and returns the effective value
+// of every key with the source it was taken from
+func Resolve(cfgFile string, cmd *cobra.Command) ([]ResolvedValue, error) {
+	cfg, err := LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return nil, err
+	}
+
+	file := viper.New()
+	if err := readInConfig(file, cfgFile); err != nil {
+		return nil, err
+	}
+
+	values := reflect.ValueOf(cfg).Elem()
+	var resolved []ResolvedValue
+	for i := 0; i < values.NumField(); i++ {
+		key := values.Type().Field(i).Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+		value := values.Field(i).Interface()
+		if duration, ok := value.(time.Duration); ok {
+			value = duration.String()
+		}
+
+		source := SourceDefault
+		if flag := cmd.Flags().Lookup(key); flag != nil && flag.Changed {
+			source = SourceFlag
+		} else if _, ok := os.LookupEnv(envName(key)); ok {
+			source = SourceEnv
+		} else if cfg.Profile != "" && file.IsSet(profilesKey+"."+cfg.Profile+"."+key) {
+			source = SourceProfile
+		} else if file.ConfigFileUsed() != "" && file.IsSet(key) {
+			source = SourceFile
+		}
+		resolved = append(resolved, ResolvedValue{Key: key, Value: value, Source: source})
+	}
+	return resolved, nil
+}
+
+// envName returns the environment variable of a configuration key
+func envName(key string) string {
+	return "ZT_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
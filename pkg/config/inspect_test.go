@@ -0,0 +1,103 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfigFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zt.yaml")
+	content := "remote: upstream\ntarget_branch: develop\nzarf-dir: [packages]\nnot-a-key: true\n" +
+		"profiles:\n  ci:\n    fail-on: warning\n    max_warnings: 0\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	problems, err := ValidateConfigFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`unknown key "not-a-key"`,
+		`unknown key "profiles.ci.max_warnings", did you mean "max-warnings"?`,
+		`unknown key "target_branch", did you mean "target-branch"?`,
+		`unknown key "zarf-dir", did you mean "zarf-dirs"?`,
+	}, problems)
+}
+
+func TestResolve(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zt.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("remote: upstream\ntarget-branch: develop\n"), 0644))
+	t.Setenv("ZT_TARGET_BRANCH", "release")
+
+	cmd := &cobra.Command{Use: "lint"}
+	cmd.Flags().String("remote", "origin", "")
+	cmd.Flags().String("since", "HEAD", "")
+	require.NoError(t, cmd.Flags().Set("since", "v1.0.0"))
+
+	resolved, err := Resolve(path, cmd)
+	require.NoError(t, err)
+
+	byKey := map[string]ResolvedValue{}
+	for _, value := range resolved {
+		byKey[value.Key] = value
+	}
+	assert.Equal(t, ResolvedValue{Key: "since", Value: "v1.0.0", Source: SourceFlag}, byKey["since"])
+	assert.Equal(t, ResolvedValue{Key: "target-branch", Value: "release", Source: SourceEnv}, byKey["target-branch"])
+	assert.Equal(t, ResolvedValue{Key: "remote", Value: "upstream", Source: SourceFile}, byKey["remote"])
+	assert.Equal(t, ResolvedValue{Key: "deployment-timeout", Value: "10m0s", Source: SourceDefault}, byKey["deployment-timeout"])
+}
+
+func TestLoadConfigurationProfile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zt.yaml")
+	content := "fail-on: never\nremote: upstream\nprofiles:\n  ci:\n    fail-on: warning\n    max-warnings: 0\n"
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	newCmd := func(profile string) *cobra.Command {
+		cmd := &cobra.Command{Use: "lint"}
+		cmd.Flags().String("profile", "", "")
+		cmd.Flags().String("fail-on", "error", "")
+		if profile != "" {
+			require.NoError(t, cmd.Flags().Set("profile", profile))
+		}
+		return cmd
+	}
+
+	cfg, err := LoadConfiguration(path, newCmd(""), false)
+	require.NoError(t, err)
+	assert.Equal(t, "never", cfg.FailOn)
+	assert.Equal(t, -1, cfg.MaxWarnings)
+
+	// The profile overrides the config file, other settings are kept
+	cfg, err = LoadConfiguration(path, newCmd("ci"), false)
+	require.NoError(t, err)
+	assert.Equal(t, "warning", cfg.FailOn)
+	assert.Equal(t, 0, cfg.MaxWarnings)
+	assert.Equal(t, "upstream", cfg.Remote)
+
+	resolved, err := Resolve(path, newCmd("ci"))
+	require.NoError(t, err)
+	for _, value := range resolved {
+		if value.Key == "fail-on" {
+			assert.Equal(t, SourceProfile, value.Source)
+		}
+	}
+
+	_, err = LoadConfiguration(path, newCmd("prod"), false)
+	assert.EqualError(t, err, `profile "prod" is not defined in the config file`)
+}
@@ -0,0 +1,103 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	flag "github.com/spf13/pflag"
+)
+
+// KeyReference documents a configuration key for the config file reference
+type KeyReference struct {
+	Key         string `json:"key"`
+	Type        string `json:"type"`
+	Default     string `json:"default,omitempty"`
+	Env         string `json:"env"`
+	Description string `json:"description,omitempty"`
+}
+
+// fileOnlyKeys describes the configuration keys that can only be set in the config file
+var fileOnlyKeys = map[string]string{
+	"clusters": "Clusters to install packages into, each with a name and a kubeconfig context, " +
+		"a kubeconfig file or the Kubernetes version of a kind cluster to create",
+	"naming-policies": "Pattern and severity (error, warning, info or off) of the naming convention " +
+		"per entity: package, component, namespace, release or variable",
+	"action-rules": "Severity (error, warning, info or off) of the action command rules by rule ID, " +
+		"e.g. action-unquoted-variable",
+	"validate-image-pinning":   "Warn about images that are not pinned to a tag or digest",
+	"validate-package-schema":  "Validate zarf.yaml against the Zarf package schema",
+	"validate-components":      "Validate the components of packages",
+	"kubectl-timeout":          "Timeout of kubectl commands",
+	"mirror-registry-username": "Username for pushing to mirror-registry-url, preferably set in the environment",
+	"mirror-registry-password": "Password for pushing to mirror-registry-url, preferably set in the environment",
+	"mirror-git-username":      "Username for pushing to mirror-git-url, preferably set in the environment",
+	"mirror-git-password":      "Password for pushing to mirror-git-url, preferably set in the environment",
+	"chart-dirs":               "Legacy chart-testing alias of zarf-dirs",
+	"charts":                   "Legacy chart-testing alias of packages",
+	"excluded-charts":          "Legacy chart-testing alias of excluded-packages",
+}
+
+// Reference documents every configuration key in the order of the Configuration fields.
+// Keys are described by the usage of the flag with the same name in flags, or by
+// fileOnlyKeys if there is no such flag.
+func Reference(flags *flag.FlagSet) []KeyReference {
+	t := reflect.TypeOf(Configuration{})
+	var reference []KeyReference
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			continue
+		}
+
+		entry := KeyReference{Key: key, Type: typeName(field.Type), Env: envName(key), Description: fileOnlyKeys[key]}
+		if f := flags.Lookup(key); f != nil {
+			entry.Description = strings.Join(strings.Fields(f.Usage), " ")
+			entry.Default = strings.TrimSuffix(strings.TrimPrefix(f.DefValue, "["), "]")
+		}
+		if value, ok := defaults[key]; ok {
+			entry.Default = formatDefault(value)
+		}
+		reference = append(reference, entry)
+	}
+	return reference
+}
+
+// typeName returns the name of the type of a configuration value as written in the
+// config file
+func typeName(t reflect.Type) string {
+	switch {
+	case t == reflect.TypeOf(time.Duration(0)):
+		return "duration"
+	case t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Struct:
+		return "list of objects"
+	case t.Kind() == reflect.Slice:
+		return "list of " + t.Elem().Kind().String() + "s"
+	case t.Kind() == reflect.Map:
+		return "map of objects"
+	}
+	return t.Kind().String()
+}
+
+func formatDefault(value interface{}) string {
+	if list, ok := value.([]string); ok {
+		return strings.Join(list, ",")
+	}
+	return fmt.Sprint(value)
+}
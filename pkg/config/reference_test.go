@@ -0,0 +1,52 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"testing"
+
+	flag "github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReference(t *testing.T) {
+	flags := flag.NewFlagSet("zt", flag.ContinueOnError)
+	flags.StringSlice("zarf-dirs", []string{"packages"}, "Directories containing Zarf packages.\nMay be specified multiple times")
+	flags.String("remote", "upstream", "The name of the Git remote")
+	flags.Bool("upgrade", false, "Upgrade packages")
+
+	byKey := map[string]KeyReference{}
+	reference := Reference(flags)
+	for _, key := range reference {
+		byKey[key.Key] = key
+	}
+	assert.Len(t, reference, len(Keys()))
+	assert.Equal(t, "remote", reference[0].Key)
+
+	assert.Equal(t, KeyReference{
+		Key:         "zarf-dirs",
+		Type:        "list of strings",
+		Default:     "packages",
+		Env:         "ZT_ZARF_DIRS",
+		Description: "Directories containing Zarf packages. May be specified multiple times",
+	}, byKey["zarf-dirs"])
+	// Defaults of the configuration take precedence over flag defaults
+	assert.Equal(t, "origin", byKey["remote"].Default)
+	assert.Equal(t, "false", byKey["upgrade"].Default)
+	assert.Equal(t, KeyReference{Key: "deployment-timeout", Type: "duration", Default: "10m0s", Env: "ZT_DEPLOYMENT_TIMEOUT"}, byKey["deployment-timeout"])
+	assert.Equal(t, "list of objects", byKey["clusters"].Type)
+	assert.Equal(t, fileOnlyKeys["clusters"], byKey["clusters"].Description)
+	assert.Equal(t, "map of objects", byKey["naming-policies"].Type)
+}
@@ -16,17 +16,22 @@ package exec
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/cpepper96/zarf-testing/pkg/util"
 )
 
 type ProcessExecutor struct {
 	debug bool
+	env   []string
 }
 
 func NewProcessExecutor(debug bool) ProcessExecutor {
@@ -35,22 +40,31 @@ func NewProcessExecutor(debug bool) ProcessExecutor {
 	}
 }
 
-func (p ProcessExecutor) RunProcessAndCaptureOutput(executable string, execArgs ...interface{}) (string, error) {
-	return p.RunProcessInDirAndCaptureOutput("", executable, execArgs)
+// WithEnv returns an executor that runs processes with the given "KEY=value"
+// variables in addition to the environment of zt
+func (p ProcessExecutor) WithEnv(env ...string) ProcessExecutor {
+	p.env = append(append([]string{}, p.env...), env...)
+	return p
 }
 
-func (p ProcessExecutor) RunProcessAndCaptureStdout(executable string, execArgs ...interface{}) (string, error) {
-	return p.RunProcessInDirAndCaptureStdout("", executable, execArgs)
+func (p ProcessExecutor) RunProcessAndCaptureOutput(ctx context.Context, executable string, execArgs ...interface{}) (string, error) {
+	return p.RunProcessInDirAndCaptureOutput(ctx, "", executable, execArgs)
 }
 
-func (p ProcessExecutor) RunProcessInDirAndCaptureOutput(workingDirectory string, executable string, execArgs ...interface{}) (string, error) {
-	cmd, err := p.CreateProcess(executable, execArgs...)
+func (p ProcessExecutor) RunProcessAndCaptureStdout(ctx context.Context, executable string, execArgs ...interface{}) (string, error) {
+	return p.RunProcessInDirAndCaptureStdout(ctx, "", executable, execArgs)
+}
+
+func (p ProcessExecutor) RunProcessInDirAndCaptureOutput(ctx context.Context, workingDirectory string, executable string, execArgs ...interface{}) (string, error) {
+	cmd, err := p.CreateProcess(ctx, executable, execArgs...)
 	if err != nil {
 		return "", err
 	}
 
 	cmd.Dir = workingDirectory
+	start := time.Now()
 	bytes, err := cmd.CombinedOutput()
+	logResult(cmd, start, err)
 
 	if err != nil {
 		return "", fmt.Errorf("failed running process: %w", err)
@@ -58,14 +72,16 @@ func (p ProcessExecutor) RunProcessInDirAndCaptureOutput(workingDirectory string
 	return strings.TrimSpace(string(bytes)), nil
 }
 
-func (p ProcessExecutor) RunProcessInDirAndCaptureStdout(workingDirectory string, executable string, execArgs ...interface{}) (string, error) {
-	cmd, err := p.CreateProcess(executable, execArgs...)
+func (p ProcessExecutor) RunProcessInDirAndCaptureStdout(ctx context.Context, workingDirectory string, executable string, execArgs ...interface{}) (string, error) {
+	cmd, err := p.CreateProcess(ctx, executable, execArgs...)
 	if err != nil {
 		return "", err
 	}
 
 	cmd.Dir = workingDirectory
+	start := time.Now()
 	bytes, err := cmd.Output()
+	logResult(cmd, start, err)
 
 	if err != nil {
 		return "", fmt.Errorf("failed running process: %w", err)
@@ -73,8 +89,34 @@ func (p ProcessExecutor) RunProcessInDirAndCaptureStdout(workingDirectory string
 	return strings.TrimSpace(string(bytes)), nil
 }
 
-func (p ProcessExecutor) RunProcess(executable string, execArgs ...interface{}) error {
-	cmd, err := p.CreateProcess(executable, execArgs...)
+// RunProcessInDirAndStreamOutput runs a process like RunProcessInDirAndCaptureOutput,
+// but also writes its output to out line by line as it is produced, each line
+// prefixed with '[prefix] '
+func (p ProcessExecutor) RunProcessInDirAndStreamOutput(ctx context.Context, out io.Writer, prefix string, workingDirectory string, executable string, execArgs ...interface{}) (string, error) {
+	cmd, err := p.CreateProcess(ctx, executable, execArgs...)
+	if err != nil {
+		return "", err
+	}
+
+	var captured bytes.Buffer
+	stream := newPrefixWriter(out, prefix)
+	output := io.MultiWriter(&captured, stream)
+	cmd.Dir = workingDirectory
+	cmd.Stdout = output
+	cmd.Stderr = output
+	start := time.Now()
+	err = cmd.Run()
+	stream.Flush()
+	logResult(cmd, start, err)
+
+	if err != nil {
+		return "", fmt.Errorf("failed running process: %w", err)
+	}
+	return strings.TrimSpace(captured.String()), nil
+}
+
+func (p ProcessExecutor) RunProcess(ctx context.Context, executable string, execArgs ...interface{}) error {
+	cmd, err := p.CreateProcess(ctx, executable, execArgs...)
 	if err != nil {
 		return err
 	}
@@ -98,12 +140,14 @@ func (p ProcessExecutor) RunProcess(executable string, execArgs ...interface{})
 		}
 	}()
 
+	start := time.Now()
 	err = cmd.Start()
 	if err != nil {
 		return fmt.Errorf("failed running process: %w", err)
 	}
 
 	err = cmd.Wait()
+	logResult(cmd, start, err)
 	if err != nil {
 		return fmt.Errorf("failed waiting for process: %w", err)
 	}
@@ -111,7 +155,9 @@ func (p ProcessExecutor) RunProcess(executable string, execArgs ...interface{})
 	return nil
 }
 
-func (p ProcessExecutor) CreateProcess(executable string, execArgs ...interface{}) (*exec.Cmd, error) {
+// CreateProcess prepares a command that is killed together with its child processes
+// when ctx is done before it exits
+func (p ProcessExecutor) CreateProcess(ctx context.Context, executable string, execArgs ...interface{}) (*exec.Cmd, error) {
 	args, err := util.Flatten(execArgs)
 	if p.debug {
 		fmt.Println(">>>", executable, strings.Join(args, " "))
@@ -119,21 +165,39 @@ func (p ProcessExecutor) CreateProcess(executable string, execArgs ...interface{
 	if err != nil {
 		return nil, fmt.Errorf("invalid arguments supplied: %w", err)
 	}
-	cmd := exec.Command(executable, args...)
+	cmd := exec.CommandContext(ctx, executable, args...)
+	if len(p.env) > 0 {
+		cmd.Env = append(os.Environ(), p.env...)
+	}
+	killProcessGroupOnCancel(cmd)
+	if len(p.env) > 0 {
+		slog.Debug("Running command", "command", executable, "args", args, "env", p.env)
+	} else {
+		slog.Debug("Running command", "command", executable, "args", args)
+	}
 
 	return cmd, nil
 }
 
+// logResult logs the duration and the error of a command that was started at start
+func logResult(cmd *exec.Cmd, start time.Time, err error) {
+	if err != nil {
+		slog.Debug("Command failed", "command", cmd.Args[0], "dir", cmd.Dir, "duration", time.Since(start), "error", err)
+		return
+	}
+	slog.Debug("Command succeeded", "command", cmd.Args[0], "dir", cmd.Dir, "duration", time.Since(start))
+}
+
 type fn func(port int) error
 
-func (p ProcessExecutor) RunWithProxy(withProxy fn) error {
+func (p ProcessExecutor) RunWithProxy(ctx context.Context, withProxy fn) error {
 	randomPort, err := util.GetRandomPort()
 	if err != nil {
 		return fmt.Errorf("could not find a free port for running 'kubectl proxy': %w", err)
 	}
 
-	fmt.Printf("Running 'kubectl proxy' on port %d\n", randomPort)
-	cmdProxy, err := p.CreateProcess("kubectl", "proxy", fmt.Sprintf("--port=%d", randomPort))
+	slog.Info("Running 'kubectl proxy'", "port", randomPort)
+	cmdProxy, err := p.CreateProcess(ctx, "kubectl", "proxy", fmt.Sprintf("--port=%d", randomPort))
 	if err != nil {
 		return fmt.Errorf("failed creating the 'kubectl proxy' process: %w", err)
 	}
@@ -144,7 +208,8 @@ func (p ProcessExecutor) RunWithProxy(withProxy fn) error {
 
 	err = withProxy(randomPort)
 
-	_ = cmdProxy.Process.Signal(os.Kill)
+	_ = cmdProxy.Cancel()
+	_ = cmdProxy.Wait()
 
 	if err != nil {
 		return fmt.Errorf("failed running command with proxy: %w", err)
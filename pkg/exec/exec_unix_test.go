@@ -0,0 +1,79 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !windows
+// +build !windows
+
+package exec
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunProcessKillsProcessGroupOnCancel(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	// The grandchild keeps stdout open, so the call only returns early if the
+	// whole process group is killed
+	start := time.Now()
+	_, err := NewProcessExecutor(false).RunProcessAndCaptureOutput(ctx, "sh", "-c", "sleep 30 & wait")
+	require.Error(t, err)
+	require.Less(t, time.Since(start), waitDelay)
+}
+
+func TestWithEnv(t *testing.T) {
+	t.Setenv("ZT_TEST_INHERITED", "inherited")
+	executor := NewProcessExecutor(false).WithEnv("ZT_TEST_A=a")
+	output, err := executor.WithEnv("ZT_TEST_B=b").RunProcessAndCaptureOutput(context.Background(), "sh", "-c", `echo "$ZT_TEST_INHERITED $ZT_TEST_A $ZT_TEST_B"`)
+	require.NoError(t, err)
+	require.Equal(t, "inherited a b", output)
+
+	// The original executor is not changed
+	output, err = executor.RunProcessAndCaptureOutput(context.Background(), "sh", "-c", `echo "$ZT_TEST_A $ZT_TEST_B"`)
+	require.NoError(t, err)
+	require.Equal(t, "a", output)
+}
+
+func TestCommandsAreLogged(t *testing.T) {
+	var log bytes.Buffer
+	defer slog.SetDefault(slog.Default())
+	slog.SetDefault(slog.New(slog.NewTextHandler(&log, &slog.HandlerOptions{
+		Level: slog.LevelDebug,
+		ReplaceAttr: func(_ []string, attr slog.Attr) slog.Attr {
+			if attr.Key == slog.TimeKey || attr.Key == "duration" {
+				return slog.Attr{}
+			}
+			return attr
+		},
+	})))
+
+	executor := NewProcessExecutor(false)
+	_, err := executor.RunProcessInDirAndCaptureOutput(context.Background(), "/", "sh", "-c", "exit 3")
+	require.Error(t, err)
+	_, err = executor.WithEnv("ZT_TEST=1").RunProcessAndCaptureStdout(context.Background(), "true")
+	require.NoError(t, err)
+
+	require.Equal(t, `level=DEBUG msg="Running command" command=sh args="[-c exit 3]"
+level=DEBUG msg="Command failed" command=sh dir=/ error="exit status 3"
+level=DEBUG msg="Running command" command=true args=[] env="[ZT_TEST=1]"
+level=DEBUG msg="Command succeeded" command=true dir=""
+`, log.String())
+}
@@ -0,0 +1,35 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package exec
+
+import (
+	"os/exec"
+	"time"
+)
+
+// waitDelay bounds how long Wait blocks on output pipes after the process was killed
+const waitDelay = 5 * time.Second
+
+// killProcessGroupOnCancel kills the process when the context is cancelled. Windows
+// has no process groups that can be signalled as a whole.
+func killProcessGroupOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Kill()
+	}
+	cmd.WaitDelay = waitDelay
+}
@@ -0,0 +1,69 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// prefixWriter writes complete lines to the underlying writer, each prefixed with
+// '[prefix] '. Incomplete lines are held back until they are terminated or flushed.
+type prefixWriter struct {
+	mu      sync.Mutex
+	out     io.Writer
+	prefix  string
+	pending []byte
+}
+
+func newPrefixWriter(out io.Writer, prefix string) *prefixWriter {
+	return &prefixWriter{out: out, prefix: prefix}
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		if err := w.writeLine(w.pending[:i]); err != nil {
+			return 0, err
+		}
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush writes a trailing line that was not terminated by a newline
+func (w *prefixWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(w.pending) > 0 {
+		_ = w.writeLine(w.pending)
+		w.pending = nil
+	}
+}
+
+func (w *prefixWriter) writeLine(line []byte) error {
+	_, err := fmt.Fprintf(w.out, "[%s] %s\n", w.prefix, bytes.TrimSuffix(line, []byte("\r")))
+	return err
+}
@@ -0,0 +1,34 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package exec
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrefixWriter(t *testing.T) {
+	var out bytes.Buffer
+	w := newPrefixWriter(&out, "podinfo")
+
+	_, _ = w.Write([]byte("creating package\nbuil"))
+	assert.Equal(t, "[podinfo] creating package\n", out.String())
+
+	_, _ = w.Write([]byte("ding images\r\n\ndone"))
+	w.Flush()
+	assert.Equal(t, "[podinfo] creating package\n[podinfo] building images\n[podinfo] \n[podinfo] done\n", out.String())
+}
@@ -0,0 +1,59 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logging configures the diagnostic log of zt. Results are printed by the
+// output formatter, the log records what zt does to produce them, such as the
+// external commands it runs.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+)
+
+// Log formats
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// NewHandler returns a handler writing records of at least level to out in format
+func NewHandler(out io.Writer, level string, format string) (slog.Handler, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q, must be one of: debug, info, warn, error", level)
+	}
+	options := &slog.HandlerOptions{Level: lvl}
+
+	switch strings.ToLower(format) {
+	case FormatText:
+		return slog.NewTextHandler(out, options), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(out, options), nil
+	default:
+		return nil, fmt.Errorf("invalid log format %q, must be one of: text, json", format)
+	}
+}
+
+// Setup makes a handler for out, level and format the default logger
+func Setup(out io.Writer, level string, format string) error {
+	handler, err := NewHandler(out, level, format)
+	if err != nil {
+		return err
+	}
+	slog.SetDefault(slog.New(handler))
+	return nil
+}
@@ -0,0 +1,51 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewHandler(t *testing.T) {
+	var out bytes.Buffer
+	handler, err := NewHandler(&out, "info", "json")
+	require.NoError(t, err)
+	logger := slog.New(handler)
+	logger.Debug("hidden")
+	logger.Info("Running command", "command", "zarf")
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal(out.Bytes(), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "Running command", record["msg"])
+	assert.Equal(t, "zarf", record["command"])
+
+	out.Reset()
+	handler, err = NewHandler(&out, "DEBUG", "text")
+	require.NoError(t, err)
+	slog.New(handler).Debug("shown")
+	assert.Contains(t, out.String(), "level=DEBUG msg=shown")
+
+	_, err = NewHandler(&out, "verbose", "text")
+	assert.EqualError(t, err, `invalid log level "verbose", must be one of: debug, info, warn, error`)
+	_, err = NewHandler(&out, "info", "xml")
+	assert.EqualError(t, err, `invalid log format "xml", must be one of: text, json`)
+}
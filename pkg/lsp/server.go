@@ -0,0 +1,337 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package lsp implements a minimal Language Server Protocol server that publishes
+// zt findings as diagnostics. Packages are validated from disk when a file is opened
+// or saved, so unsaved edits are not reflected.
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+)
+
+// Diagnostic severities as defined by the protocol
+const (
+	severityError       = 1
+	severityWarning     = 2
+	severityInformation = 3
+)
+
+// ValidateFunc validates the package in the given directory
+type ValidateFunc func(packagePath string) (*zarf.ValidationResult, error)
+
+// Server answers LSP requests over a single connection
+type Server struct {
+	validate  ValidateFunc
+	out       io.Writer
+	published map[string]map[string]bool // package dir -> URIs with diagnostics
+	shutdown  bool
+}
+
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  interface{}      `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type textDocumentParams struct {
+	TextDocument struct {
+		URI string `json:"uri"`
+	} `json:"textDocument"`
+}
+
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type diagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Code     string    `json:"code"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+// NewServer creates a server that validates packages with validate
+func NewServer(validate ValidateFunc) *Server {
+	return &Server{
+		validate:  validate,
+		published: make(map[string]map[string]bool),
+	}
+}
+
+// Serve reads requests from in and writes responses and notifications to out until
+// the client sends 'exit' or closes the connection
+func (s *Server) Serve(in io.Reader, out io.Writer) error {
+	s.out = out
+	reader := bufio.NewReader(in)
+	for {
+		msg, err := readMessage(reader)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return fmt.Errorf("exit received before shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(msg); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(msg *message) error {
+	switch msg.Method {
+	case "initialize":
+		return s.reply(msg.ID, map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync": map[string]interface{}{
+					"openClose": true,
+					"change":    0,
+					"save":      map[string]bool{"includeText": false},
+				},
+			},
+			"serverInfo": map[string]string{"name": "zt"},
+		})
+	case "shutdown":
+		s.shutdown = true
+		return s.reply(msg.ID, nil)
+	case "textDocument/didOpen", "textDocument/didSave":
+		params := textDocumentParams{}
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil
+		}
+		return s.validateDocument(params.TextDocument.URI)
+	default:
+		if msg.ID != nil {
+			return s.write(&message{JSONRPC: "2.0", ID: msg.ID, Error: &responseError{
+				Code:    -32601,
+				Message: fmt.Sprintf("method not found: %s", msg.Method),
+			}})
+		}
+		return nil
+	}
+}
+
+// validateDocument validates the package containing the document at uri and publishes
+// the diagnostics of every file of that package
+func (s *Server) validateDocument(uri string) error {
+	path, err := uriToPath(uri)
+	if err != nil {
+		return nil
+	}
+	packageDir := findPackageDir(filepath.Dir(path))
+	if packageDir == "" {
+		return nil
+	}
+
+	result, err := s.validate(packageDir)
+	if err != nil {
+		return s.notify("window/logMessage", map[string]interface{}{
+			"type":    1,
+			"message": fmt.Sprintf("zt: failed to validate %s: %v", packageDir, err),
+		})
+	}
+
+	byURI := map[string][]diagnostic{pathToURI(filepath.Join(packageDir, "zarf.yaml")): {}}
+	for _, finding := range result.Findings {
+		file := finding.File
+		if file == "" {
+			file = "zarf.yaml"
+		}
+		fileURI := pathToURI(filepath.Join(packageDir, filepath.FromSlash(file)))
+		byURI[fileURI] = append(byURI[fileURI], toDiagnostic(finding))
+	}
+
+	// Clear diagnostics of files that no longer have findings
+	for previous := range s.published[packageDir] {
+		if _, ok := byURI[previous]; !ok {
+			byURI[previous] = []diagnostic{}
+		}
+	}
+
+	uris := make([]string, 0, len(byURI))
+	for fileURI := range byURI {
+		uris = append(uris, fileURI)
+	}
+	sort.Strings(uris)
+
+	s.published[packageDir] = make(map[string]bool)
+	for _, fileURI := range uris {
+		diagnostics := byURI[fileURI]
+		if len(diagnostics) > 0 {
+			s.published[packageDir][fileURI] = true
+		}
+		err := s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         fileURI,
+			Diagnostics: diagnostics,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toDiagnostic(finding zarf.Finding) diagnostic {
+	severity := severityInformation
+	switch finding.Severity {
+	case zarf.SeverityError:
+		severity = severityError
+	case zarf.SeverityWarning:
+		severity = severityWarning
+	}
+
+	start := position{}
+	if finding.Line > 0 {
+		start.Line = finding.Line - 1
+	}
+	if finding.Column > 0 {
+		start.Character = finding.Column - 1
+	}
+	// Highlight to the end of the line, clients clamp the range to the line length
+	end := position{Line: start.Line + 1, Character: 0}
+
+	return diagnostic{
+		Range:    textRange{Start: start, End: end},
+		Severity: severity,
+		Code:     finding.RuleID,
+		Source:   "zt",
+		Message:  finding.Message,
+	}
+}
+
+// findPackageDir returns the closest directory containing a zarf.yaml, starting at dir
+func findPackageDir(dir string) string {
+	for {
+		if util.FileExists(filepath.Join(dir, "zarf.yaml")) {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func uriToPath(uri string) (string, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Scheme != "file" {
+		return "", fmt.Errorf("unsupported URI scheme %q", parsed.Scheme)
+	}
+	return filepath.FromSlash(parsed.Path), nil
+}
+
+func pathToURI(path string) string {
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}) error {
+	if result == nil {
+		// The protocol requires an explicit null result
+		result = json.RawMessage("null")
+	}
+	return s.write(&message{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) notify(method string, params interface{}) error {
+	content, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return s.write(&message{JSONRPC: "2.0", Method: method, Params: content})
+}
+
+func (s *Server) write(msg *message) error {
+	content, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(content), content)
+	return err
+}
+
+func readMessage(reader *bufio.Reader) (*message, error) {
+	contentLength := -1
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			break
+		}
+		if name, value, ok := strings.Cut(line, ":"); ok && strings.EqualFold(name, "Content-Length") {
+			contentLength, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+			}
+		}
+	}
+	if contentLength < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+
+	content := make([]byte, contentLength)
+	if _, err := io.ReadFull(reader, content); err != nil {
+		return nil, err
+	}
+
+	msg := &message{}
+	if err := json.Unmarshal(content, msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %w", err)
+	}
+	return msg, nil
+}
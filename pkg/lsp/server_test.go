@@ -0,0 +1,83 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lsp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func frame(t *testing.T, msg map[string]interface{}) string {
+	content, err := json.Marshal(msg)
+	require.NoError(t, err)
+	return fmt.Sprintf("Content-Length: %d\r\n\r\n%s", len(content), content)
+}
+
+func TestServer(t *testing.T) {
+	packageDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\n"), 0644))
+
+	validate := func(packagePath string) (*zarf.ValidationResult, error) {
+		result := &zarf.ValidationResult{PackagePath: packagePath, Valid: true}
+		result.AddFinding(zarf.Finding{RuleID: "package-name", Severity: zarf.SeverityError,
+			Message: "Missing package name in metadata", File: "zarf.yaml", Line: 2, Column: 3})
+		return result, nil
+	}
+
+	uri := pathToURI(filepath.Join(packageDir, "zarf.yaml"))
+	input := frame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": "initialize", "params": map[string]interface{}{}}) +
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "textDocument/didOpen", "params": map[string]interface{}{
+			"textDocument": map[string]interface{}{"uri": uri, "languageId": "yaml", "version": 1, "text": ""},
+		}}) +
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "id": 2, "method": "shutdown"}) +
+		frame(t, map[string]interface{}{"jsonrpc": "2.0", "method": "exit"})
+
+	out := &bytes.Buffer{}
+	require.NoError(t, NewServer(validate).Serve(bytes.NewBufferString(input), out))
+
+	reader := bufio.NewReader(out)
+	initialize, err := readMessage(reader)
+	require.NoError(t, err)
+	assert.JSONEq(t, "1", string(*initialize.ID))
+
+	published, err := readMessage(reader)
+	require.NoError(t, err)
+	assert.Equal(t, "textDocument/publishDiagnostics", published.Method)
+
+	params := publishDiagnosticsParams{}
+	require.NoError(t, json.Unmarshal(published.Params, &params))
+	assert.Equal(t, uri, params.URI)
+	require.Len(t, params.Diagnostics, 1)
+	assert.Equal(t, diagnostic{
+		Range:    textRange{Start: position{Line: 1, Character: 2}, End: position{Line: 2}},
+		Severity: severityError,
+		Code:     "package-name",
+		Source:   "zt",
+		Message:  "Missing package name in metadata",
+	}, params.Diagnostics[0])
+
+	shutdown, err := readMessage(reader)
+	require.NoError(t, err)
+	assert.JSONEq(t, "2", string(*shutdown.ID))
+}
@@ -0,0 +1,167 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package metrics writes run metrics in the Prometheus text exposition format, to a
+// file for the node exporter's textfile collector or to a Pushgateway.
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Gauge is the only metric type zt emits: every value describes the last run
+const Gauge = "gauge"
+
+// Sample is a value of a metric with its labels
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// Family is a metric with its help text and samples
+type Family struct {
+	Name    string
+	Help    string
+	Type    string
+	Samples []Sample
+}
+
+// Set is a set of metric families in the order they were added
+type Set struct {
+	families []*Family
+	index    map[string]*Family
+}
+
+// NewSet creates an empty set of metrics
+func NewSet() *Set {
+	return &Set{index: map[string]*Family{}}
+}
+
+// Gauge adds a sample of the gauge name. Labels are given as key, value pairs. The
+// help text of the first sample of a metric is used.
+func (s *Set) Gauge(name, help string, value float64, labels ...string) {
+	family, ok := s.index[name]
+	if !ok {
+		family = &Family{Name: name, Help: help, Type: Gauge}
+		s.index[name] = family
+		s.families = append(s.families, family)
+	}
+	sample := Sample{Labels: map[string]string{}, Value: value}
+	for i := 0; i+1 < len(labels); i += 2 {
+		sample.Labels[labels[i]] = labels[i+1]
+	}
+	family.Samples = append(family.Samples, sample)
+}
+
+// WriteTo writes the metrics in the text exposition format
+func (s *Set) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	for _, family := range s.families {
+		fmt.Fprintf(&b, "# HELP %s %s\n", family.Name, escapeHelp(family.Help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", family.Name, family.Type)
+		for _, sample := range family.Samples {
+			b.WriteString(family.Name)
+			if len(sample.Labels) > 0 {
+				keys := make([]string, 0, len(sample.Labels))
+				for key := range sample.Labels {
+					keys = append(keys, key)
+				}
+				sort.Strings(keys)
+				pairs := make([]string, len(keys))
+				for i, key := range keys {
+					pairs[i] = fmt.Sprintf("%s=\"%s\"", key, escapeLabel(sample.Labels[key]))
+				}
+				fmt.Fprintf(&b, "{%s}", strings.Join(pairs, ","))
+			}
+			fmt.Fprintf(&b, " %s\n", strconv.FormatFloat(sample.Value, 'g', -1, 64))
+		}
+	}
+	return b.WriteTo(w)
+}
+
+// WriteFile writes the metrics to path. The file is replaced atomically, so the
+// textfile collector never reads a partially written file.
+func (s *Set) WriteFile(path string) error {
+	file, err := os.CreateTemp(filepath.Dir(path), ".zt-metrics-*")
+	if err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	defer os.Remove(file.Name())
+	if _, err := s.WriteTo(file); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Chmod(file.Name(), 0644); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	if err := os.Rename(file.Name(), path); err != nil {
+		return fmt.Errorf("failed to write metrics: %w", err)
+	}
+	return nil
+}
+
+// Push replaces the metrics of the group identified by job and the grouping labels,
+// given as key, value pairs, on the Pushgateway at gateway
+func (s *Set) Push(ctx context.Context, gateway, job string, grouping ...string) error {
+	path := "/metrics/job/" + url.PathEscape(job)
+	for i := 0; i+1 < len(grouping); i += 2 {
+		path += "/" + url.PathEscape(grouping[i]) + "/" + url.PathEscape(grouping[i+1])
+	}
+
+	var body bytes.Buffer
+	if _, err := s.WriteTo(&body); err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, strings.TrimSuffix(gateway, "/")+path, &body)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	req.Header.Set("Content-Type", "text/plain; version=0.0.4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to push metrics: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to push metrics: %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+var (
+	helpReplacer  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelReplacer = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func escapeHelp(help string) string {
+	return helpReplacer.Replace(help)
+}
+
+func escapeLabel(value string) string {
+	return labelReplacer.Replace(value)
+}
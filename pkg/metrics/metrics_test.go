@@ -0,0 +1,89 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testSet() *Set {
+	set := NewSet()
+	set.Gauge("zt_run_duration_seconds", "Duration of the last run", 12.5, "command", "lint")
+	set.Gauge("zt_lint_findings", "Number of findings", 3, "severity", "error", "rule", `bad "rule"`)
+	set.Gauge("zt_lint_findings", "", 1, "severity", "warning", "rule", "image-pinning")
+	return set
+}
+
+const testExposition = `# HELP zt_run_duration_seconds Duration of the last run
+# TYPE zt_run_duration_seconds gauge
+zt_run_duration_seconds{command="lint"} 12.5
+# HELP zt_lint_findings Number of findings
+# TYPE zt_lint_findings gauge
+zt_lint_findings{rule="bad \"rule\"",severity="error"} 3
+zt_lint_findings{rule="image-pinning",severity="warning"} 1
+`
+
+func TestWriteTo(t *testing.T) {
+	var b bytes.Buffer
+	_, err := testSet().WriteTo(&b)
+	require.NoError(t, err)
+	assert.Equal(t, testExposition, b.String())
+}
+
+func TestWriteFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "zt.prom")
+	require.NoError(t, testSet().WriteFile(path))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, testExposition, string(content))
+
+	// Only the metrics file is left behind
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+}
+
+func TestPush(t *testing.T) {
+	var method, path, body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, _ := io.ReadAll(r.Body)
+		method, path, body = r.Method, r.URL.EscapedPath(), string(content)
+	}))
+	defer server.Close()
+
+	require.NoError(t, testSet().Push(context.Background(), server.URL+"/", "zt", "command", "lint"))
+	assert.Equal(t, http.MethodPut, method)
+	assert.Equal(t, "/metrics/job/zt/command/lint", path)
+	assert.Equal(t, testExposition, body)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "invalid metric", http.StatusBadRequest)
+	}))
+	defer failing.Close()
+	err := testSet().Push(context.Background(), failing.URL, "zt")
+	assert.EqualError(t, err, "failed to push metrics: 400 Bad Request: invalid metric")
+}
@@ -20,6 +20,8 @@ import (
 	"io"
 	"os"
 	"strings"
+	"sync"
+	"text/tabwriter"
 	"time"
 
 	"github.com/fatih/color"
@@ -35,8 +37,42 @@ const (
 	FormatJSON
 	// FormatGitHub represents GitHub Actions compatible output
 	FormatGitHub
+	// FormatGitLab represents GitLab CI output with collapsible sections
+	FormatGitLab
+	// FormatTeamCity represents TeamCity service messages
+	FormatTeamCity
+	// FormatNDJSON represents a stream of JSON events, one per line, written as they
+	// happen. Only events are written, messages are left out.
+	FormatNDJSON
 )
 
+// Events written in the NDJSON format
+const (
+	EventPackageStarted  = "package_started"
+	EventFinding         = "finding"
+	EventPackageFinished = "package_finished"
+	EventRunSummary      = "run_summary"
+)
+
+// ParseFormat returns the Format for the given --output value, falling back to
+// plain text for unknown values
+func ParseFormat(name string) Format {
+	switch strings.ToLower(name) {
+	case "json":
+		return FormatJSON
+	case "github":
+		return FormatGitHub
+	case "gitlab":
+		return FormatGitLab
+	case "teamcity":
+		return FormatTeamCity
+	case "ndjson":
+		return FormatNDJSON
+	default:
+		return FormatText
+	}
+}
+
 // Config contains output formatting configuration
 type Config struct {
 	Format      Format
@@ -49,6 +85,11 @@ type Config struct {
 type Formatter struct {
 	config     *Config
 	jsonBuffer []interface{}
+	// sections holds the names of the open GitLab and TeamCity sections
+	sections     []string
+	sectionCount int
+	// events serializes the NDJSON events, which may be written concurrently
+	events sync.Mutex
 }
 
 // NewFormatter creates a new output formatter
@@ -73,14 +114,17 @@ func (f *Formatter) Success(msg string, args ...interface{}) {
 	message := fmt.Sprintf(msg, args...)
 	
 	switch f.config.Format {
+	case FormatNDJSON:
 	case FormatJSON:
 		f.addJSONEvent("success", message, nil)
-	case FormatGitHub:
+	case FormatGitHub, FormatGitLab:
 		if f.config.GithubGroups {
 			fmt.Fprintf(f.config.Writer, "✅ %s\n", message)
 		} else {
 			fmt.Fprintf(f.config.Writer, "✅ %s\n", message)
 		}
+	case FormatTeamCity:
+		f.teamCityMessage(message, "NORMAL")
 	default:
 		green := color.New(color.FgGreen, color.Bold)
 		fmt.Fprintf(f.config.Writer, "%s %s\n", green.Sprint("✅"), message)
@@ -92,10 +136,13 @@ func (f *Formatter) Error(msg string, args ...interface{}) {
 	message := fmt.Sprintf(msg, args...)
 	
 	switch f.config.Format {
+	case FormatNDJSON:
 	case FormatJSON:
 		f.addJSONEvent("error", message, nil)
-	case FormatGitHub:
+	case FormatGitHub, FormatGitLab:
 		fmt.Fprintf(f.config.Writer, "❌ %s\n", message)
+	case FormatTeamCity:
+		f.teamCityMessage(message, "ERROR")
 	default:
 		red := color.New(color.FgRed, color.Bold)
 		fmt.Fprintf(f.config.Writer, "%s %s\n", red.Sprint("❌"), message)
@@ -107,10 +154,13 @@ func (f *Formatter) Warning(msg string, args ...interface{}) {
 	message := fmt.Sprintf(msg, args...)
 	
 	switch f.config.Format {
+	case FormatNDJSON:
 	case FormatJSON:
 		f.addJSONEvent("warning", message, nil)
-	case FormatGitHub:
+	case FormatGitHub, FormatGitLab:
 		fmt.Fprintf(f.config.Writer, "⚠️  %s\n", message)
+	case FormatTeamCity:
+		f.teamCityMessage(message, "WARNING")
 	default:
 		yellow := color.New(color.FgYellow, color.Bold)
 		fmt.Fprintf(f.config.Writer, "%s %s\n", yellow.Sprint("⚠️"), message)
@@ -122,10 +172,13 @@ func (f *Formatter) Info(msg string, args ...interface{}) {
 	message := fmt.Sprintf(msg, args...)
 	
 	switch f.config.Format {
+	case FormatNDJSON:
 	case FormatJSON:
 		f.addJSONEvent("info", message, nil)
-	case FormatGitHub:
+	case FormatGitHub, FormatGitLab:
 		fmt.Fprintf(f.config.Writer, "ℹ️  %s\n", message)
+	case FormatTeamCity:
+		f.teamCityMessage(message, "NORMAL")
 	default:
 		blue := color.New(color.FgBlue)
 		fmt.Fprintf(f.config.Writer, "%s %s\n", blue.Sprint("ℹ️"), message)
@@ -137,10 +190,13 @@ func (f *Formatter) Progress(msg string, args ...interface{}) {
 	message := fmt.Sprintf(msg, args...)
 	
 	switch f.config.Format {
+	case FormatNDJSON:
 	case FormatJSON:
 		f.addJSONEvent("progress", message, nil)
-	case FormatGitHub:
+	case FormatGitHub, FormatGitLab:
 		fmt.Fprintf(f.config.Writer, "🔧 %s\n", message)
+	case FormatTeamCity:
+		fmt.Fprintf(f.config.Writer, "##teamcity[progressMessage '%s']\n", teamCityEscape(message))
 	default:
 		cyan := color.New(color.FgCyan)
 		fmt.Fprintf(f.config.Writer, "%s %s\n", cyan.Sprint("🔧"), message)
@@ -150,6 +206,7 @@ func (f *Formatter) Progress(msg string, args ...interface{}) {
 // Section prints a section header
 func (f *Formatter) Section(title string) {
 	switch f.config.Format {
+	case FormatNDJSON:
 	case FormatJSON:
 		f.addJSONEvent("section", title, nil)
 	case FormatGitHub:
@@ -158,6 +215,15 @@ func (f *Formatter) Section(title string) {
 		} else {
 			fmt.Fprintf(f.config.Writer, "\n📋 %s\n", title)
 		}
+	case FormatGitLab:
+		// GitLab requires section names to be unique within the job log
+		f.sectionCount++
+		name := fmt.Sprintf("zt_section_%d", f.sectionCount)
+		f.sections = append(f.sections, name)
+		fmt.Fprintf(f.config.Writer, "\x1b[0Ksection_start:%d:%s\r\x1b[0K📋 %s\n", time.Now().Unix(), name, title)
+	case FormatTeamCity:
+		f.sections = append(f.sections, title)
+		fmt.Fprintf(f.config.Writer, "##teamcity[blockOpened name='%s']\n", teamCityEscape(title))
 	default:
 		bold := color.New(color.Bold, color.FgMagenta)
 		fmt.Fprintf(f.config.Writer, "\n%s %s\n", bold.Sprint("📋"), bold.Sprint(title))
@@ -166,30 +232,94 @@ func (f *Formatter) Section(title string) {
 
 // EndSection ends a section (mainly for GitHub Actions groups)
 func (f *Formatter) EndSection() {
-	if f.config.Format == FormatGitHub && f.config.GithubGroups {
-		fmt.Fprintf(f.config.Writer, "::endgroup::\n")
+	switch f.config.Format {
+	case FormatGitHub:
+		if f.config.GithubGroups {
+			fmt.Fprintf(f.config.Writer, "::endgroup::\n")
+		}
+	case FormatGitLab:
+		if name, ok := f.closeSection(); ok {
+			fmt.Fprintf(f.config.Writer, "\x1b[0Ksection_end:%d:%s\r\x1b[0K\n", time.Now().Unix(), name)
+		}
+	case FormatTeamCity:
+		if name, ok := f.closeSection(); ok {
+			fmt.Fprintf(f.config.Writer, "##teamcity[blockClosed name='%s']\n", teamCityEscape(name))
+		}
 	}
 }
 
+// closeSection pops the innermost open section
+func (f *Formatter) closeSection() (string, bool) {
+	if len(f.sections) == 0 {
+		return "", false
+	}
+	name := f.sections[len(f.sections)-1]
+	f.sections = f.sections[:len(f.sections)-1]
+	return name, true
+}
+
+// teamCityMessage prints a TeamCity build log message with the given status
+func (f *Formatter) teamCityMessage(text, status string) {
+	fmt.Fprintf(f.config.Writer, "##teamcity[message text='%s' status='%s']\n", teamCityEscape(text), status)
+}
+
+var teamCityReplacer = strings.NewReplacer(
+	"|", "||",
+	"'", "|'",
+	"\n", "|n",
+	"\r", "|r",
+	"[", "|[",
+	"]", "|]",
+)
+
+// teamCityEscape escapes a value for use in a TeamCity service message
+func teamCityEscape(value string) string {
+	return teamCityReplacer.Replace(value)
+}
+
 // Step prints a step within a section
 func (f *Formatter) Step(current, total int, msg string, args ...interface{}) {
 	message := fmt.Sprintf(msg, args...)
 	
 	switch f.config.Format {
+	case FormatNDJSON:
 	case FormatJSON:
 		data := map[string]interface{}{
 			"current": current,
 			"total":   total,
 		}
 		f.addJSONEvent("step", message, data)
-	case FormatGitHub:
+	case FormatGitHub, FormatGitLab:
 		fmt.Fprintf(f.config.Writer, "  [%d/%d] %s\n", current, total, message)
+	case FormatTeamCity:
+		fmt.Fprintf(f.config.Writer, "##teamcity[progressMessage '%s']\n", teamCityEscape(fmt.Sprintf("[%d/%d] %s", current, total, message)))
 	default:
 		cyan := color.New(color.FgCyan)
 		fmt.Fprintf(f.config.Writer, "  %s [%d/%d] %s\n", cyan.Sprint("→"), current, total, message)
 	}
 }
 
+// Table prints rows as columns aligned under headers
+func (f *Formatter) Table(headers []string, rows [][]string) {
+	if f.config.Format == FormatNDJSON {
+		return
+	}
+	if f.config.Format == FormatJSON {
+		f.addJSONEvent("table", "", map[string]interface{}{
+			"headers": headers,
+			"rows":    rows,
+		})
+		return
+	}
+
+	w := tabwriter.NewWriter(f.config.Writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "  %s\n", strings.Join(headers, "\t"))
+	for _, row := range rows {
+		fmt.Fprintf(w, "  %s\n", strings.Join(row, "\t"))
+	}
+	w.Flush()
+}
+
 // PrintJSON outputs all buffered events as JSON
 func (f *Formatter) PrintJSON() error {
 	if f.config.Format != FormatJSON {
@@ -206,6 +336,30 @@ func (f *Formatter) PrintJSON() error {
 	return encoder.Encode(output)
 }
 
+// PrintDocument writes doc as a single indented JSON document. Commands use it
+// instead of PrintJSON when they produce a structured result rather than an event log.
+func (f *Formatter) PrintDocument(doc interface{}) error {
+	encoder := json.NewEncoder(f.config.Writer)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(doc)
+}
+
+// Event writes an event of the given type with data as a line of JSON right away, if
+// the format is NDJSON. Events may be written from several goroutines.
+func (f *Formatter) Event(eventType string, data interface{}) {
+	if f.config.Format != FormatNDJSON {
+		return
+	}
+
+	f.events.Lock()
+	defer f.events.Unlock()
+	json.NewEncoder(f.config.Writer).Encode(map[string]interface{}{
+		"timestamp": time.Now().UTC().Format(time.RFC3339),
+		"type":      eventType,
+		"data":      data,
+	})
+}
+
 // addJSONEvent adds an event to the JSON buffer
 func (f *Formatter) addJSONEvent(eventType, message string, data map[string]interface{}) {
 	event := map[string]interface{}{
@@ -244,6 +398,7 @@ func (pb *ProgressBar) Update(current int, message string) {
 	pb.current = current
 	
 	switch pb.formatter.config.Format {
+	case FormatNDJSON:
 	case FormatJSON:
 		data := map[string]interface{}{
 			"current": current,
@@ -251,9 +406,12 @@ func (pb *ProgressBar) Update(current int, message string) {
 			"percent": float64(current) / float64(pb.total) * 100,
 		}
 		pb.formatter.addJSONEvent("progress_update", message, data)
-	case FormatGitHub:
+	case FormatGitHub, FormatGitLab:
 		percent := float64(current) / float64(pb.total) * 100
 		fmt.Fprintf(pb.formatter.config.Writer, "Progress: %.1f%% (%d/%d) - %s\n", percent, current, pb.total, message)
+	case FormatTeamCity:
+		progress := fmt.Sprintf("%s: %d/%d %s", pb.title, current, pb.total, message)
+		fmt.Fprintf(pb.formatter.config.Writer, "##teamcity[progressMessage '%s']\n", teamCityEscape(progress))
 	default:
 		percent := float64(current) / float64(pb.total) * 100
 		bar := pb.generateBar(50, percent)
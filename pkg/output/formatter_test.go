@@ -0,0 +1,89 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package output
+
+import (
+	"bytes"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFormat(t *testing.T) {
+	assert.Equal(t, FormatJSON, ParseFormat("json"))
+	assert.Equal(t, FormatGitHub, ParseFormat("GitHub"))
+	assert.Equal(t, FormatGitLab, ParseFormat("gitlab"))
+	assert.Equal(t, FormatTeamCity, ParseFormat("teamcity"))
+	assert.Equal(t, FormatNDJSON, ParseFormat("ndjson"))
+	assert.Equal(t, FormatText, ParseFormat("fancy"))
+}
+
+func TestGitLabSections(t *testing.T) {
+	var out bytes.Buffer
+	f := NewFormatter(&Config{Format: FormatGitLab, Writer: &out})
+	f.Section("Linting")
+	f.Error("bad package")
+	f.EndSection()
+	f.Section("Results")
+	f.EndSection()
+	f.EndSection()
+
+	// Timestamps vary, so they are replaced before comparing
+	output := regexp.MustCompile(`section_(start|end):\d+:`).ReplaceAllString(out.String(), "section_$1:T:")
+	assert.Equal(t, "\x1b[0Ksection_start:T:zt_section_1\r\x1b[0K📋 Linting\n"+
+		"❌ bad package\n"+
+		"\x1b[0Ksection_end:T:zt_section_1\r\x1b[0K\n"+
+		"\x1b[0Ksection_start:T:zt_section_2\r\x1b[0K📋 Results\n"+
+		"\x1b[0Ksection_end:T:zt_section_2\r\x1b[0K\n", output)
+}
+
+func TestTeamCityServiceMessages(t *testing.T) {
+	var out bytes.Buffer
+	f := NewFormatter(&Config{Format: FormatTeamCity, Writer: &out})
+	f.Section("Linting packages/a")
+	f.Step(1, 2, "Checking [images]")
+	f.Warning("it's\nfine")
+	f.Error("failed | %d error(s)", 2)
+	f.EndSection()
+
+	assert.Equal(t, "##teamcity[blockOpened name='Linting packages/a']\n"+
+		"##teamcity[progressMessage '|[1/2|] Checking |[images|]']\n"+
+		"##teamcity[message text='it|'s|nfine' status='WARNING']\n"+
+		"##teamcity[message text='failed || 2 error(s)' status='ERROR']\n"+
+		"##teamcity[blockClosed name='Linting packages/a']\n", out.String())
+}
+
+func TestNDJSONEvents(t *testing.T) {
+	var out bytes.Buffer
+	f := NewFormatter(&Config{Format: FormatNDJSON, Writer: &out})
+	f.Section("Linting")
+	f.Info("Linting packages/a")
+	f.Event(EventPackageStarted, map[string]string{"path": "packages/a"})
+	f.Step(1, 1, "Checking images")
+	f.Table([]string{"PACKAGE"}, [][]string{{"packages/a"}})
+	f.Event(EventRunSummary, map[string]int{"packages": 1})
+	f.EndSection()
+
+	// Messages are left out and every event is a line of JSON
+	output := regexp.MustCompile(`"timestamp":"[^"]+"`).ReplaceAllString(out.String(), `"timestamp":"T"`)
+	assert.Equal(t, `{"data":{"path":"packages/a"},"timestamp":"T","type":"package_started"}`+"\n"+
+		`{"data":{"packages":1},"timestamp":"T","type":"run_summary"}`+"\n", output)
+
+	// Events are only written in the NDJSON format
+	out.Reset()
+	NewFormatter(&Config{Format: FormatJSON, Writer: &out}).Event(EventRunSummary, nil)
+	assert.Empty(t, out.String())
+}
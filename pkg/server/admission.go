@@ -0,0 +1,199 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/cpepper96/zarf-testing/pkg/zarftesting"
+	"gopkg.in/yaml.v2"
+)
+
+// maxAdmissionReviewSize limits the size of admission requests. The API server
+// limits objects to about 1.5MB.
+const maxAdmissionReviewSize = 3 << 20
+
+// AdmissionReview is the admission.k8s.io/v1 request and response of a validating
+// webhook, limited to the fields used by zt
+type AdmissionReview struct {
+	APIVersion string             `json:"apiVersion"`
+	Kind       string             `json:"kind"`
+	Request    *AdmissionRequest  `json:"request,omitempty"`
+	Response   *AdmissionResponse `json:"response,omitempty"`
+}
+
+// AdmissionRequest describes the object being admitted
+type AdmissionRequest struct {
+	UID       string          `json:"uid"`
+	Operation string          `json:"operation"`
+	Name      string          `json:"name,omitempty"`
+	Namespace string          `json:"namespace,omitempty"`
+	Object    json.RawMessage `json:"object,omitempty"`
+}
+
+// AdmissionResponse allows or denies the object of the request with the same UID
+type AdmissionResponse struct {
+	UID      string           `json:"uid"`
+	Allowed  bool             `json:"allowed"`
+	Status   *AdmissionStatus `json:"status,omitempty"`
+	Warnings []string         `json:"warnings,omitempty"`
+}
+
+// AdmissionStatus is the reason a request was denied
+type AdmissionStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+// zarfPackage is a ZarfPackage custom resource. The spec is either the package
+// definition itself, or holds the raw zarf.yaml in the zarfYaml field.
+type zarfPackage struct {
+	Metadata struct {
+		Name string `json:"name"`
+	} `json:"metadata"`
+	Spec map[string]interface{} `json:"spec"`
+}
+
+// AdmissionServer is a validating admission webhook for ZarfPackage resources that
+// denies packages failing the lint rules
+type AdmissionServer struct {
+	linter *zarftesting.Linter
+	mux    *http.ServeMux
+}
+
+// NewAdmissionServer creates an AdmissionServer serving reviews on /validate. The
+// resources hold only the package definition, so the linter should be created with
+// zarftesting.WithDefinitionOnly and zarftesting.WithConfinedPaths.
+func NewAdmissionServer(linter *zarftesting.Linter) *AdmissionServer {
+	s := &AdmissionServer{linter: linter, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /healthz", healthz)
+	s.mux.HandleFunc("POST /validate", s.validate)
+	return s
+}
+
+func (s *AdmissionServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func (s *AdmissionServer) validate(w http.ResponseWriter, r *http.Request) {
+	var review AdmissionReview
+	if err := json.NewDecoder(io.LimitReader(r.Body, maxAdmissionReviewSize)).Decode(&review); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("invalid admission review: %w", err))
+		return
+	}
+	if review.Request == nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("admission review has no request"))
+		return
+	}
+
+	response := s.review(r.Context(), review.Request)
+	response.UID = review.Request.UID
+	writeJSON(w, http.StatusOK, AdmissionReview{APIVersion: review.APIVersion, Kind: review.Kind, Response: response})
+}
+
+// review lints the object of the request. Objects that cannot be linted are denied.
+func (s *AdmissionServer) review(ctx context.Context, request *AdmissionRequest) *AdmissionResponse {
+	if request.Operation != "CREATE" && request.Operation != "UPDATE" {
+		return &AdmissionResponse{Allowed: true}
+	}
+
+	content, err := packageDefinition(request.Object)
+	if err != nil {
+		return deny(http.StatusBadRequest, fmt.Sprintf("invalid ZarfPackage: %v", err))
+	}
+	dir, err := os.MkdirTemp("", "zt-admission-")
+	if err != nil {
+		return deny(http.StatusInternalServerError, err.Error())
+	}
+	defer os.RemoveAll(dir)
+	if err := os.WriteFile(filepath.Join(dir, "zarf.yaml"), content, 0644); err != nil {
+		return deny(http.StatusInternalServerError, err.Error())
+	}
+
+	result, err := s.linter.Run(ctx, []string{dir})
+	if err != nil {
+		slog.Warn("Failed to lint ZarfPackage", "namespace", request.Namespace, "name", request.Name, "error", err)
+		return deny(http.StatusInternalServerError, fmt.Sprintf("failed to lint package: %v", err))
+	}
+
+	response := &AdmissionResponse{Allowed: result.Passed()}
+	var errs []string
+	for _, pkg := range result.Report.Packages {
+		for _, finding := range pkg.Findings {
+			message := fmt.Sprintf("[%s] %s", finding.RuleID, finding.Message)
+			switch finding.Severity {
+			case zarf.SeverityError:
+				errs = append(errs, message)
+			case zarf.SeverityWarning:
+				response.Warnings = append(response.Warnings, message)
+			}
+		}
+	}
+	if !response.Allowed {
+		reason := result.Failure.Error()
+		if len(errs) > 0 {
+			reason += ": " + strings.Join(errs, "; ")
+		}
+		response.Status = &AdmissionStatus{Code: http.StatusForbidden, Message: reason}
+	}
+	return response
+}
+
+// packageDefinition returns the zarf.yaml of a ZarfPackage resource. A spec without
+// zarfYaml is the package definition, named after the resource unless it sets
+// metadata.name.
+func packageDefinition(object json.RawMessage) ([]byte, error) {
+	var resource zarfPackage
+	if err := json.Unmarshal(object, &resource); err != nil {
+		return nil, err
+	}
+	if resource.Spec == nil {
+		return nil, fmt.Errorf("missing spec")
+	}
+	if raw, ok := resource.Spec["zarfYaml"]; ok {
+		content, ok := raw.(string)
+		if !ok {
+			return nil, fmt.Errorf("spec.zarfYaml must be a string")
+		}
+		return []byte(content), nil
+	}
+
+	definition := resource.Spec
+	if _, ok := definition["kind"]; !ok {
+		definition["kind"] = "ZarfPackageConfig"
+	}
+	metadata, _ := definition["metadata"].(map[string]interface{})
+	if metadata == nil {
+		metadata = map[string]interface{}{}
+		definition["metadata"] = metadata
+	}
+	if _, ok := metadata["name"]; !ok && resource.Metadata.Name != "" {
+		metadata["name"] = resource.Metadata.Name
+	}
+	return yaml.Marshal(definition)
+}
+
+func deny(code int, message string) *AdmissionResponse {
+	return &AdmissionResponse{Allowed: false, Status: &AdmissionStatus{Code: code, Message: message}}
+}
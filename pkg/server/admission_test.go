@@ -0,0 +1,142 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/zarftesting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageDefinition(t *testing.T) {
+	content, err := packageDefinition([]byte(`{"metadata":{"name":"podinfo"},"spec":{"metadata":{"version":"1.0.0"},"components":[{"name":"web"}]}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "components:\n- name: web\nkind: ZarfPackageConfig\nmetadata:\n  name: podinfo\n  version: 1.0.0\n", string(content))
+
+	content, err = packageDefinition([]byte(`{"metadata":{"name":"podinfo"},"spec":{"zarfYaml":"kind: ZarfPackageConfig\n"}}`))
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ZarfPackageConfig\n", string(content))
+
+	_, err = packageDefinition([]byte(`{"metadata":{"name":"podinfo"}}`))
+	assert.EqualError(t, err, "missing spec")
+}
+
+// review posts an admission review of the object to the webhook and returns the response
+func review(t *testing.T, server *httptest.Server, operation, object string) *AdmissionResponse {
+	t.Helper()
+	request := AdmissionReview{
+		APIVersion: "admission.k8s.io/v1",
+		Kind:       "AdmissionReview",
+		Request:    &AdmissionRequest{UID: "705ab4f5", Operation: operation, Name: "podinfo", Object: json.RawMessage(object)},
+	}
+	body, err := json.Marshal(request)
+	require.NoError(t, err)
+	response, err := http.Post(server.URL+"/validate", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer response.Body.Close()
+	require.Equal(t, http.StatusOK, response.StatusCode)
+
+	var result AdmissionReview
+	require.NoError(t, json.NewDecoder(response.Body).Decode(&result))
+	assert.Equal(t, "admission.k8s.io/v1", result.APIVersion)
+	require.NotNil(t, result.Response)
+	assert.Equal(t, "705ab4f5", result.Response.UID)
+	return result.Response
+}
+
+// localContent is a ZarfPackage whose components refer to files of the package and
+// import a component of another package
+const localContent = `{"metadata":{"name":"podinfo"},"spec":{"metadata":{"version":"1.0.0","description":"podinfo"},"components":[` +
+	`{"name":"podinfo","required":true,"manifests":[{"name":"podinfo","namespace":"podinfo","files":["manifests/deployment.yaml"]}],` +
+	`"files":[{"source":"config/podinfo.yaml","target":"/etc/podinfo.yaml"}]},` +
+	`{"name":"common","import":{"path":"../common"}}]}}`
+
+func TestAdmissionServer(t *testing.T) {
+	linter, err := zarftesting.NewLinter(zarftesting.WithoutZarfCLI(), zarftesting.WithDefinitionOnly(),
+		zarftesting.WithConfinedPaths(), zarftesting.WithYamlLintConfig(nil))
+	require.NoError(t, err)
+	server := httptest.NewServer(NewAdmissionServer(linter))
+	defer server.Close()
+	review := func(operation, object string) *AdmissionResponse {
+		return review(t, server, operation, object)
+	}
+
+	// The package lacks a description, which is a warning
+	response := review("CREATE", `{"metadata":{"name":"podinfo"},"spec":{"metadata":{"version":"1.0.0"}}}`)
+	assert.True(t, response.Allowed)
+	assert.Contains(t, response.Warnings, "[package-description] No description provided in metadata")
+
+	response = review("UPDATE", `{"metadata":{"name":"podinfo"},"spec":{"kind":"ZarfBundleConfig","metadata":{"version":"1.0.0"}}}`)
+	assert.False(t, response.Allowed)
+	require.NotNil(t, response.Status)
+	assert.Equal(t, http.StatusForbidden, response.Status.Code)
+	assert.Contains(t, response.Status.Message, "[package-kind] Invalid kind 'ZarfBundleConfig', expected 'ZarfPackageConfig' or 'ZarfInitConfig'")
+
+	response = review("CREATE", `{"metadata":{"name":"podinfo"}}`)
+	assert.False(t, response.Allowed)
+	assert.Equal(t, http.StatusBadRequest, response.Status.Code)
+
+	response = review("DELETE", `null`)
+	assert.True(t, response.Allowed)
+
+	// Resources hold only the package definition, paths outside of the package are
+	// still rejected
+	response = review("CREATE", strings.Replace(localContent, `"path":"../common"`, `"path":"common"`, 1))
+	assert.True(t, response.Allowed, response.Status)
+	response = review("CREATE", strings.Replace(localContent, "manifests/deployment.yaml", "../../etc/kubernetes/admin.conf", 1))
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Status.Message, "[file-reference] Component 'podinfo' references path outside of the package at components[0].manifests[0].files[0]: ../../etc/kubernetes/admin.conf")
+}
+
+func TestAdmissionServerWithZarfCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake zarf CLI is a shell script")
+	}
+	bin := t.TempDir()
+	calls := filepath.Join(bin, "calls")
+	zarf := "#!/bin/sh\necho \"zarf $*\" >> " + calls + "\necho v0.60.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(bin, "zarf"), []byte(zarf), 0755))
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+
+	linter, err := zarftesting.NewLinter(zarftesting.WithDefinitionOnly(), zarftesting.WithConfinedPaths(),
+		zarftesting.WithYamlLintConfig(nil))
+	require.NoError(t, err)
+	server := httptest.NewServer(NewAdmissionServer(linter))
+	defer server.Close()
+
+	// Local content is linted with zarf but not looked for
+	response := review(t, server, "CREATE", strings.Replace(localContent, `"path":"../common"`, `"path":"common"`, 1))
+	assert.True(t, response.Allowed, response.Status)
+	recorded, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Contains(t, string(recorded), "zarf dev lint")
+
+	// Packages referring outside of their directory are not passed to zarf
+	require.NoError(t, os.Remove(calls))
+	response = review(t, server, "CREATE", localContent)
+	assert.False(t, response.Allowed)
+	assert.Contains(t, response.Status.Message, "[file-reference] Component 'common' references path outside of the package at components[1].import.path: ../common")
+	assert.NoFileExists(t, calls)
+}
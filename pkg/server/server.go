@@ -0,0 +1,258 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package server implements the REST API of 'zt serve', which validates packages
+// uploaded as a zarf.yaml or a tar archive of the package directory, and the
+// Kubernetes validating admission webhook of 'zt admission-webhook'.
+package server
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/cpepper96/zarf-testing/pkg/zarftesting"
+)
+
+// DefaultMaxUploadSize is the default limit of the size of an uploaded package
+const DefaultMaxUploadSize = 100 << 20
+
+// maxArchiveEntries limits the number of entries of an uploaded archive
+const maxArchiveEntries = 10000
+
+// errArchiveTooLarge is returned when an uploaded archive exceeds the limits once
+// decompressed
+var errArchiveTooLarge = errors.New("archive is too large")
+
+// LintResponse is the response to a lint request
+type LintResponse struct {
+	Passed  bool             `json:"passed"`
+	Failure string           `json:"failure,omitempty"`
+	Report  *zarf.LintReport `json:"report"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// Server validates uploaded packages with a Linter
+type Server struct {
+	linter        *zarftesting.Linter
+	maxUploadSize int64
+	mux           *http.ServeMux
+}
+
+// NewServer creates a Server rejecting uploads larger than maxUploadSize bytes, also
+// once decompressed, and archives of more than 10000 entries. The linter should be created with zarftesting.WithConfinedPaths, so uploads cannot make
+// it read files outside of the upload.
+func NewServer(linter *zarftesting.Linter, maxUploadSize int64) *Server {
+	s := &Server{linter: linter, maxUploadSize: maxUploadSize, mux: http.NewServeMux()}
+	s.mux.HandleFunc("GET /healthz", healthz)
+	s.mux.HandleFunc("POST /v1/lint", s.lint)
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mux.ServeHTTP(w, r)
+}
+
+func healthz(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = io.WriteString(w, "ok\n")
+}
+
+// lint validates the package in the request body. The content type selects how
+// the body is read: a zarf.yaml (application/yaml), a tar archive
+// (application/x-tar) or a gzipped tar archive (application/gzip).
+func (s *Server) lint(w http.ResponseWriter, r *http.Request) {
+	dir, err := os.MkdirTemp("", "zt-serve-")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	body := http.MaxBytesReader(w, r.Body, s.maxUploadSize)
+	packageDir, status, err := readPackage(r.Header.Get("Content-Type"), body, dir, s.maxUploadSize)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) || errors.Is(err, errArchiveTooLarge) {
+			status = http.StatusRequestEntityTooLarge
+		}
+		writeError(w, status, err)
+		return
+	}
+
+	result, err := s.linter.Run(r.Context(), []string{packageDir})
+	if err != nil {
+		slog.Warn("Failed to lint uploaded package", "error", err)
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	// Paths are reported relative to the uploaded package
+	for i := range result.Report.Packages {
+		result.Report.Packages[i].Path = "."
+	}
+	response := LintResponse{Passed: result.Passed(), Report: result.Report}
+	if result.Failure != nil {
+		response.Failure = result.Failure.Error()
+	}
+	writeJSON(w, http.StatusOK, response)
+}
+
+// readPackage writes the uploaded package to dir and returns the package directory,
+// or the HTTP status of the error. Archives must not exceed maxSize bytes once
+// decompressed.
+func readPackage(contentType string, body io.Reader, dir string, maxSize int64) (string, int, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "", "application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml":
+		content, err := io.ReadAll(body)
+		if err != nil {
+			return "", http.StatusBadRequest, err
+		}
+		if err := os.WriteFile(filepath.Join(dir, "zarf.yaml"), content, 0644); err != nil {
+			return "", http.StatusInternalServerError, err
+		}
+		return dir, 0, nil
+	case "application/x-tar":
+		return extractPackage(&limitedReader{reader: body, remaining: maxSize, limit: maxSize}, dir)
+	case "application/gzip", "application/x-gzip":
+		gz, err := gzip.NewReader(body)
+		if err != nil {
+			return "", http.StatusBadRequest, fmt.Errorf("invalid gzip archive: %w", err)
+		}
+		defer gz.Close()
+		return extractPackage(&limitedReader{reader: gz, remaining: maxSize, limit: maxSize}, dir)
+	default:
+		return "", http.StatusUnsupportedMediaType, fmt.Errorf("unsupported content type %q: must be application/yaml, application/x-tar or application/gzip", mediaType)
+	}
+}
+
+// extractPackage extracts the regular files and directories of a tar archive into
+// dir. The package is the root of the archive, or its only top-level directory.
+func extractPackage(archive io.Reader, dir string) (string, int, error) {
+	reader := tar.NewReader(archive)
+	for entries := 0; ; entries++ {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if errors.Is(err, errArchiveTooLarge) {
+			return "", http.StatusRequestEntityTooLarge, err
+		}
+		if err != nil {
+			return "", http.StatusBadRequest, fmt.Errorf("invalid tar archive: %w", err)
+		}
+		if entries == maxArchiveEntries {
+			return "", http.StatusRequestEntityTooLarge, fmt.Errorf("%w: it has more than %d entries", errArchiveTooLarge, maxArchiveEntries)
+		}
+
+		name := filepath.Clean(filepath.FromSlash(header.Name))
+		if filepath.IsAbs(name) || name == ".." || strings.HasPrefix(name, ".."+string(filepath.Separator)) {
+			return "", http.StatusBadRequest, fmt.Errorf("invalid path %q in archive", header.Name)
+		}
+		target := filepath.Join(dir, name)
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return "", http.StatusInternalServerError, err
+			}
+		case tar.TypeReg:
+			if err := writeFile(target, reader); err != nil {
+				var tooLarge *http.MaxBytesError
+				if errors.As(err, &tooLarge) || errors.Is(err, errArchiveTooLarge) {
+					return "", http.StatusRequestEntityTooLarge, err
+				}
+				return "", http.StatusInternalServerError, err
+			}
+		default:
+			// Links and special files could point outside of the package
+			slog.Debug("Skipping archive entry", "name", header.Name, "type", header.Typeflag)
+		}
+	}
+
+	if zarf.IsZarfPackage(dir) {
+		return dir, 0, nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", http.StatusInternalServerError, err
+	}
+	if len(entries) == 1 && entries[0].IsDir() && zarf.IsZarfPackage(filepath.Join(dir, entries[0].Name())) {
+		return filepath.Join(dir, entries[0].Name()), 0, nil
+	}
+	return "", http.StatusBadRequest, fmt.Errorf("archive does not contain a zarf.yaml")
+}
+
+// limitedReader fails with errArchiveTooLarge once more than limit bytes are read
+type limitedReader struct {
+	reader    io.Reader
+	remaining int64
+	limit     int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.remaining < 0 {
+		return 0, fmt.Errorf("%w: it expands to more than %d bytes", errArchiveTooLarge, r.limit)
+	}
+	if int64(len(p)) > r.remaining+1 {
+		p = p[:r.remaining+1]
+	}
+	n, err := r.reader.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, fmt.Errorf("%w: it expands to more than %d bytes", errArchiveTooLarge, r.limit)
+	}
+	return n, err
+}
+
+func writeFile(path string, content io.Reader) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(file, content); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(value); err != nil {
+		slog.Warn("Failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
@@ -0,0 +1,170 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package server
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/zarftesting"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const zarfYaml = `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+  version: 1.0.0
+components:
+  - name: podinfo
+    required: true
+    manifests:
+      - name: podinfo
+        files:
+          - manifests/deployment.yaml
+`
+
+const deployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+  namespace: podinfo
+spec:
+  selector:
+    matchLabels:
+      app: podinfo
+  template:
+    metadata:
+      labels:
+        app: podinfo
+    spec:
+      containers:
+        - name: podinfo
+          image: ghcr.io/stefanprodan/podinfo:6.4.0
+`
+
+func tarball(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := tar.NewWriter(&buf)
+	for name, content := range files {
+		require.NoError(t, writer.WriteHeader(&tar.Header{Name: name, Mode: 0644, Size: int64(len(content)), Typeflag: tar.TypeReg}))
+		_, err := writer.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, writer.Close())
+	return buf.Bytes()
+}
+
+func TestServer(t *testing.T) {
+	linter, err := zarftesting.NewLinter(zarftesting.WithoutZarfCLI(), zarftesting.WithConfinedPaths(), zarftesting.WithYamlLintConfig(nil))
+	require.NoError(t, err)
+	server := httptest.NewServer(NewServer(linter, 1<<20))
+	defer server.Close()
+
+	lint := func(contentType string, body []byte) (int, LintResponse, string) {
+		response, err := http.Post(server.URL+"/v1/lint", contentType, bytes.NewReader(body))
+		require.NoError(t, err)
+		defer response.Body.Close()
+		var buf bytes.Buffer
+		_, err = buf.ReadFrom(response.Body)
+		require.NoError(t, err)
+		var lintResponse LintResponse
+		_ = json.Unmarshal(buf.Bytes(), &lintResponse)
+		return response.StatusCode, lintResponse, buf.String()
+	}
+	hasFinding := func(response LintResponse, ruleID string) bool {
+		for _, finding := range response.Report.Packages[0].Findings {
+			if finding.RuleID == ruleID {
+				return true
+			}
+		}
+		return false
+	}
+
+	status, response, body := lint("application/yaml", []byte(strings.Replace(zarfYaml, "name: podinfo\n  version", "version", 1)))
+	require.Equal(t, http.StatusOK, status, body)
+	assert.False(t, response.Passed)
+	assert.Equal(t, "package validation failed", response.Failure)
+	assert.Equal(t, ".", response.Report.Packages[0].Path)
+	assert.True(t, hasFinding(response, "package-name"))
+
+	// The package may be the only top-level directory of the archive
+	archive := tarball(t, map[string]string{
+		"podinfo/zarf.yaml":                 zarfYaml,
+		"podinfo/manifests/deployment.yaml": deployment,
+	})
+	status, response, body = lint("application/x-tar", archive)
+	require.Equal(t, http.StatusOK, status, body)
+	assert.True(t, response.Passed)
+	assert.False(t, hasFinding(response, "package-name"))
+
+	// Files outside of the upload are never read
+	outside := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(outside, "host-secret.yaml"), []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: host-secret\n"), 0644))
+	escaping := strings.Replace(zarfYaml, "manifests/deployment.yaml", "../../../../../../../.."+filepath.Join(outside, "host-secret.yaml"), 1)
+	status, response, body = lint("application/yaml", []byte(escaping))
+	require.Equal(t, http.StatusOK, status, body)
+	assert.False(t, response.Passed)
+	assert.True(t, hasFinding(response, "file-reference"))
+	assert.NotContains(t, body, "host-secret.yaml declares")
+	assert.NotContains(t, body, "Secret/host-secret")
+
+	status, _, body = lint("application/x-tar", tarball(t, map[string]string{"../zarf.yaml": zarfYaml}))
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Contains(t, body, `invalid path \"../zarf.yaml\" in archive`)
+
+	status, _, _ = lint("application/x-tar", tarball(t, map[string]string{"README.md": "# podinfo"}))
+	assert.Equal(t, http.StatusBadRequest, status)
+
+	status, _, _ = lint("application/yaml", []byte(strings.Repeat("#", 2<<20)))
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+
+	// Highly compressible archives are limited once decompressed
+	var bomb bytes.Buffer
+	gz := gzip.NewWriter(&bomb)
+	_, err = gz.Write(tarball(t, map[string]string{"zarf.yaml": zarfYaml, "padding.yaml": strings.Repeat("#", 2<<20)}))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.Less(t, bomb.Len(), 1<<20)
+	status, _, body = lint("application/gzip", bomb.Bytes())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	assert.Contains(t, body, "archive is too large: it expands to more than 1048576 bytes")
+
+	entries := map[string]string{"zarf.yaml": zarfYaml}
+	for i := 0; i < maxArchiveEntries; i++ {
+		entries[fmt.Sprintf("empty/%d", i)] = ""
+	}
+	_, status, err = extractPackage(bytes.NewReader(tarball(t, entries)), t.TempDir())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, status)
+	assert.EqualError(t, err, "archive is too large: it has more than 10000 entries")
+
+	status, _, _ = lint("text/plain", []byte(zarfYaml))
+	assert.Equal(t, http.StatusUnsupportedMediaType, status)
+
+	response2, err := http.Get(server.URL + "/healthz")
+	require.NoError(t, err)
+	response2.Body.Close()
+	assert.Equal(t, http.StatusOK, response2.StatusCode)
+}
@@ -1,6 +1,7 @@
 package tool
 
 import (
+	"context"
 	"strings"
 	"text/template"
 
@@ -8,7 +9,7 @@ import (
 )
 
 type ProcessExecutor interface {
-	RunProcess(executable string, execArgs ...interface{}) error
+	RunProcess(ctx context.Context, executable string, execArgs ...interface{}) error
 }
 
 type CmdTemplateExecutor struct {
@@ -21,7 +22,7 @@ func NewCmdTemplateExecutor(exec ProcessExecutor) CmdTemplateExecutor {
 	}
 }
 
-func (t CmdTemplateExecutor) RunCommand(cmdTemplate string, data interface{}) error {
+func (t CmdTemplateExecutor) RunCommand(ctx context.Context, cmdTemplate string, data interface{}) error {
 	var template = template.Must(template.New("command").Parse(cmdTemplate))
 	var b strings.Builder
 	if err := template.Execute(&b, data); err != nil {
@@ -34,5 +35,5 @@ func (t CmdTemplateExecutor) RunCommand(cmdTemplate string, data interface{}) er
 		return err
 	}
 	name, args := words[0], words[1:]
-	return t.exec.RunProcess(name, args)
+	return t.exec.RunProcess(ctx, name, args)
 }
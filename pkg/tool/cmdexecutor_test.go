@@ -1,6 +1,7 @@
 package tool
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/mock"
@@ -10,7 +11,7 @@ type fakeProcessExecutor struct {
 	mock.Mock
 }
 
-func (c *fakeProcessExecutor) RunProcess(executable string, execArgs ...interface{}) error {
+func (c *fakeProcessExecutor) RunProcess(_ context.Context, executable string, execArgs ...interface{}) error {
 	c.Called(executable, execArgs[0])
 	return nil
 }
@@ -66,7 +67,7 @@ func TestCmdTemplateExecutor_RunCommand(t *testing.T) {
 			templateExecutor := CmdTemplateExecutor{
 				exec: processExecutor,
 			}
-			if err := templateExecutor.RunCommand(tt.args.cmdTemplate, tt.args.data); (err != nil) != tt.wantErr {
+			if err := templateExecutor.RunCommand(context.Background(), tt.args.cmdTemplate, tt.args.data); (err != nil) != tt.wantErr {
 				t.Errorf("RunCommand() error = %v, wantErr %v", err, tt.wantErr)
 			}
 
@@ -15,67 +15,537 @@
 package tool
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 
-	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
 )
 
+var (
+	// ErrNotRepository is returned when the directory is not inside a Git repository
+	ErrNotRepository = errors.New("not a git repository")
+	// ErrReferenceNotFound is returned when a branch, tag or commit does not exist
+	ErrReferenceNotFound = errors.New("git reference not found")
+	// ErrFileNotFound is returned when a file does not exist in a commit
+	ErrFileNotFound = errors.New("file not found in commit")
+	// ErrShallowClone is returned when history that is missing from a shallow clone
+	// is required, e.g. to find the merge base with the target branch
+	ErrShallowClone = errors.New("history is missing from shallow clone")
+)
+
+// Git reads the Git repository containing a directory. Paths of files in the
+// repository are relative to its root unless noted otherwise.
 type Git struct {
-	exec exec.ProcessExecutor
+	dir string
 }
 
-func NewGit(exec exec.ProcessExecutor) Git {
+// NewGit returns a Git for the repository containing dir, the working directory
+// if empty
+func NewGit(dir string) Git {
+	if dir == "" {
+		dir = "."
+	}
 	return Git{
-		exec: exec,
+		dir: dir,
+	}
+}
+
+func (g Git) open(ctx context.Context) (*git.Repository, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	repo, err := git.PlainOpenWithOptions(g.dir, &git.PlainOpenOptions{DetectDotGit: true, EnableDotGitCommonDir: true})
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		return nil, fmt.Errorf("%w: %s", ErrNotRepository, g.dir)
+	}
+	return repo, err
+}
+
+// isShallow reports whether the repository is a shallow clone
+func isShallow(repo *git.Repository) bool {
+	shallow, err := repo.Storer.Shallow()
+	return err == nil && len(shallow) > 0
+}
+
+// missingHistory turns errors about missing objects of shallow clones into ErrShallowClone
+func missingHistory(repo *git.Repository, err error) error {
+	if errors.Is(err, plumbing.ErrObjectNotFound) && isShallow(repo) {
+		return fmt.Errorf("%w: %v", ErrShallowClone, err)
+	}
+	return err
+}
+
+func (g Git) commit(repo *git.Repository, rev string) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		// References to commits that were not fetched cannot be resolved either
+		if isShallow(repo) && referenceExists(repo, rev) {
+			return nil, fmt.Errorf("%w: commit of %s was not fetched", ErrShallowClone, rev)
+		}
+		return nil, fmt.Errorf("%w: %s", ErrReferenceNotFound, rev)
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, missingHistory(repo, fmt.Errorf("failed to read commit %s: %w", rev, err))
 	}
+	return commit, nil
 }
 
-func (g Git) FileExistsOnBranch(file string, remote string, branch string) bool {
-	fileSpec := fmt.Sprintf("%s/%s:%s", remote, branch, file)
-	_, err := g.exec.RunProcessAndCaptureOutput("git", "cat-file", "-e", fileSpec)
+// referenceExists reports whether a branch, remote branch or tag of the name exists
+func referenceExists(repo *git.Repository, name string) bool {
+	for _, prefix := range []string{"", "refs/", "refs/tags/", "refs/heads/", "refs/remotes/"} {
+		if _, err := repo.Reference(plumbing.ReferenceName(prefix+name), true); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+func (g Git) fileAt(ctx context.Context, rev string, file string) (string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	commit, err := g.commit(repo, rev)
+	if err != nil {
+		return "", err
+	}
+	f, err := commit.File(file)
+	if errors.Is(err, object.ErrFileNotFound) {
+		return "", fmt.Errorf("%w: %s:%s", ErrFileNotFound, rev, file)
+	} else if err != nil {
+		return "", missingHistory(repo, err)
+	}
+	return f.Contents()
+}
+
+func (g Git) FileExistsOnBranch(ctx context.Context, file string, remote string, branch string) bool {
+	_, err := g.Show(ctx, file, remote, branch)
 	return err == nil
 }
 
-func (g Git) AddWorktree(path string, ref string) error {
-	return g.exec.RunProcess("git", "worktree", "add", path, ref)
+func (g Git) Show(ctx context.Context, file string, remote string, branch string) (string, error) {
+	return g.fileAt(ctx, fmt.Sprintf("%s/%s", remote, branch), file)
 }
 
-func (g Git) RemoveWorktree(path string) error {
-	return g.exec.RunProcess("git", "worktree", "remove", path)
+// ShowFile returns the content of a file at a revision, like 'git show rev:./file'
+// in the directory of the Git
+func (g Git) ShowFile(ctx context.Context, rev string, file string) (string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	path, err := g.repoPath(repo, file)
+	if err != nil {
+		return "", err
+	}
+	return g.fileAt(ctx, rev, path)
+}
+
+// repoPath returns the path of a file relative to the directory of the Git, or an
+// absolute path, relative to the root of the repository
+func (g Git) repoPath(repo *git.Repository, file string) (string, error) {
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(g.dir, file)
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return "", err
+	}
+	path, err := filepath.Rel(worktree.Filesystem.Root(), abs)
+	if err != nil {
+		return "", err
+	}
+	return filepath.ToSlash(path), nil
 }
 
-func (g Git) Show(file string, remote string, branch string) (string, error) {
-	fileSpec := fmt.Sprintf("%s/%s:%s", remote, branch, file)
-	return g.exec.RunProcessAndCaptureOutput("git", "show", fileSpec)
+// RepoPath returns the path of a file relative to the directory of the Git relative
+// to the root of the repository, failing for files outside of the repository
+func (g Git) RepoPath(ctx context.Context, file string) (string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	path, err := g.repoPath(repo, file)
+	if err != nil {
+		return "", err
+	}
+	if path == ".." || strings.HasPrefix(path, "../") {
+		return "", fmt.Errorf("%s is outside of the repository", file)
+	}
+	return path, nil
 }
 
-func (g Git) MergeBase(commit1 string, commit2 string) (string, error) {
-	return g.exec.RunProcessAndCaptureOutput("git", "merge-base", commit1, commit2)
+func (g Git) MergeBase(ctx context.Context, commit1 string, commit2 string) (string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	first, err := g.commit(repo, commit1)
+	if err != nil {
+		return "", err
+	}
+	second, err := g.commit(repo, commit2)
+	if err != nil {
+		return "", err
+	}
+
+	bases, err := first.MergeBase(second)
+	if err != nil {
+		return "", missingHistory(repo, fmt.Errorf("failed to find merge base of %s and %s: %w", commit1, commit2, err))
+	}
+	if len(bases) == 0 {
+		if isShallow(repo) {
+			return "", fmt.Errorf("%w: no merge base of %s and %s", ErrShallowClone, commit1, commit2)
+		}
+		return "", fmt.Errorf("%s and %s have no common history", commit1, commit2)
+	}
+	return bases[0].Hash.String(), nil
 }
 
-func (g Git) ListChangedFilesInDirs(commit string, dirs ...string) ([]string, error) {
-	changedChartFilesString, err :=
-		g.exec.RunProcessAndCaptureOutput("git", "diff", "--find-renames", "--name-only", commit, "--", dirs)
+// ListChangedFilesInDirs lists the files changed in the working tree compared to a
+// commit, like 'git diff --find-renames --name-only commit -- dirs'. Untracked files
+// are not included. The directories are relative to the directory of the Git.
+func (g Git) ListChangedFilesInDirs(ctx context.Context, commit string, dirs ...string) ([]string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	base, err := g.commit(repo, commit)
+	if err != nil {
+		return nil, err
+	}
+	head, err := g.commit(repo, "HEAD")
+	if err != nil {
+		return nil, err
+	}
+	baseTree, err := base.Tree()
+	if err != nil {
+		return nil, missingHistory(repo, err)
+	}
+	headTree, err := head.Tree()
+	if err != nil {
+		return nil, missingHistory(repo, err)
+	}
+
+	changes, err := object.DiffTreeWithOptions(ctx, baseTree, headTree, object.DefaultDiffTreeOptions)
 	if err != nil {
 		return nil, fmt.Errorf("failed creating diff: %w", err)
 	}
-	if changedChartFilesString == "" {
-		return nil, nil
+	changed := map[string]bool{}
+	for _, change := range changes {
+		// Renamed files are listed by their new name
+		if change.To.Name != "" {
+			changed[change.To.Name] = true
+		} else {
+			changed[change.From.Name] = true
+		}
+	}
+
+	// Uncommitted changes of tracked files
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return nil, err
+	}
+	status, err := worktree.Status()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading worktree status: %w", err)
+	}
+	for file, fileStatus := range status {
+		if fileStatus.Worktree == git.Untracked {
+			continue
+		}
+		if fileStatus.Worktree != git.Unmodified || fileStatus.Staging != git.Unmodified {
+			changed[file] = true
+		}
+	}
+
+	var prefixes []string
+	for _, dir := range dirs {
+		path, err := g.repoPath(repo, dir)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, path)
+	}
+
+	var files []string
+	for file := range changed {
+		if inDirs(file, prefixes) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ListFilesInDirs lists the files in the tree of a revision, like 'git ls-tree -r
+// --name-only rev -- dirs'. Unlike the other methods, the directories and the returned
+// paths are relative to the directory of the Git, so that they can be passed to ShowFile.
+func (g Git) ListFilesInDirs(ctx context.Context, rev string, dirs ...string) ([]string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := g.commit(repo, rev)
+	if err != nil {
+		return nil, err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return nil, missingHistory(repo, err)
+	}
+	base, err := g.repoPath(repo, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []string
+	for _, dir := range dirs {
+		path, err := g.repoPath(repo, dir)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, path)
+	}
+
+	var files []string
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !inDirs(f.Name, prefixes) {
+			return nil
+		}
+		rel, err := filepath.Rel(base, f.Name)
+		if err != nil {
+			return err
+		}
+		files = append(files, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, missingHistory(repo, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ExportDirs writes the regular files in the directories as of a revision into dest,
+// at their paths relative to the root of the repository, and returns where the
+// directory of the Git is within dest. The directories are relative to the directory
+// of the Git.
+func (g Git) ExportDirs(ctx context.Context, rev string, dest string, dirs ...string) (string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	commit, err := g.commit(repo, rev)
+	if err != nil {
+		return "", err
+	}
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", missingHistory(repo, err)
+	}
+	base, err := g.repoPath(repo, ".")
+	if err != nil {
+		return "", err
+	}
+
+	var prefixes []string
+	for _, dir := range dirs {
+		path, err := g.repoPath(repo, dir)
+		if err != nil {
+			return "", err
+		}
+		prefixes = append(prefixes, path)
+	}
+
+	err = tree.Files().ForEach(func(f *object.File) error {
+		if !inDirs(f.Name, prefixes) || !f.Mode.IsRegular() {
+			return nil
+		}
+		path := filepath.Join(dest, filepath.FromSlash(f.Name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			return err
+		}
+		reader, err := f.Reader()
+		if err != nil {
+			return err
+		}
+		defer reader.Close()
+		out, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(out, reader); err != nil {
+			out.Close()
+			return err
+		}
+		return out.Close()
+	})
+	if err != nil {
+		return "", missingHistory(repo, err)
+	}
+	return filepath.Join(dest, filepath.FromSlash(base)), nil
+}
+
+// lfsPointerPrefix starts the content of Git LFS pointer files, which are at most
+// lfsPointerMaxSize bytes
+const (
+	lfsPointerPrefix  = "version https://git-lfs.github.com/spec/v1"
+	lfsPointerMaxSize = 1024
+)
+
+// TrackedFile is a file in the index of the repository
+type TrackedFile struct {
+	// Path is relative to the directory of the Git
+	Path string
+	// Size is the size of the content stored in Git, the pointer for LFS files
+	Size int64
+	// LFSPointer reports whether the content stored in Git is a Git LFS pointer
+	LFSPointer bool
+}
+
+// TrackedFiles lists the files in the index (committed or staged) in the directories,
+// like 'git ls-files -- dirs'. The directories are relative to the directory of the Git.
+func (g Git) TrackedFiles(ctx context.Context, dirs ...string) ([]TrackedFile, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return nil, err
+	}
+	index, err := repo.Storer.Index()
+	if err != nil {
+		return nil, fmt.Errorf("failed reading index: %w", err)
+	}
+	base, err := g.repoPath(repo, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixes []string
+	for _, dir := range dirs {
+		path, err := g.repoPath(repo, dir)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, path)
+	}
+
+	var files []TrackedFile
+	for _, entry := range index.Entries {
+		if !inDirs(entry.Name, prefixes) {
+			continue
+		}
+		blob, err := object.GetBlob(repo.Storer, entry.Hash)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading %s: %w", entry.Name, err)
+		}
+		rel, err := filepath.Rel(base, entry.Name)
+		if err != nil {
+			return nil, err
+		}
+		file := TrackedFile{Path: filepath.ToSlash(rel), Size: blob.Size}
+		if blob.Size <= lfsPointerMaxSize {
+			file.LFSPointer, err = isLFSPointer(blob)
+			if err != nil {
+				return nil, fmt.Errorf("failed reading %s: %w", entry.Name, err)
+			}
+		}
+		files = append(files, file)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func isLFSPointer(blob *object.Blob) (bool, error) {
+	reader, err := blob.Reader()
+	if err != nil {
+		return false, err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return false, err
+	}
+	return strings.HasPrefix(string(content), lfsPointerPrefix), nil
+}
+
+// inDirs reports whether a file is in one of the directories, or in any if there are none
+func inDirs(file string, dirs []string) bool {
+	if len(dirs) == 0 {
+		return true
+	}
+	for _, dir := range dirs {
+		if dir == "." || file == dir || strings.HasPrefix(file, dir+"/") {
+			return true
+		}
 	}
-	return strings.Split(changedChartFilesString, "\n"), nil
+	return false
 }
 
-func (g Git) GetURLForRemote(remote string) (string, error) {
-	return g.exec.RunProcessAndCaptureOutput("git", "ls-remote", "--get-url", remote)
+func (g Git) GetURLForRemote(ctx context.Context, remote string) (string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return "", err
+	}
+	r, err := repo.Remote(remote)
+	if err != nil {
+		return "", fmt.Errorf("failed to get remote %s: %w", remote, err)
+	}
+	if urls := r.Config().URLs; len(urls) > 0 {
+		return urls[0], nil
+	}
+	return "", fmt.Errorf("remote %s has no URL", remote)
 }
 
-func (g Git) ValidateRepository() error {
-	_, err := g.exec.RunProcessAndCaptureOutput("git", "rev-parse", "--is-inside-work-tree")
+func (g Git) ValidateRepository(ctx context.Context) error {
+	_, err := g.open(ctx)
 	return err
 }
 
-func (g Git) BranchExists(branch string) bool {
-	_, err := g.exec.RunProcessAndCaptureOutput("git", "rev-parse", "--verify", branch)
+func (g Git) BranchExists(ctx context.Context, branch string) bool {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return false
+	}
+	_, err = repo.ResolveRevision(plumbing.Revision(branch))
 	return err == nil
 }
+
+// IsShallow reports whether the repository is a shallow clone
+func (g Git) IsShallow(ctx context.Context) (bool, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return false, err
+	}
+	return isShallow(repo), nil
+}
+
+// Head returns the commit checked out and the name of its branch, which is empty for
+// a detached HEAD
+func (g Git) Head(ctx context.Context) (string, string, error) {
+	repo, err := g.open(ctx)
+	if err != nil {
+		return "", "", err
+	}
+	head, err := repo.Head()
+	if err != nil {
+		return "", "", fmt.Errorf("failed to resolve HEAD: %w", err)
+	}
+	branch := ""
+	if head.Name().IsBranch() {
+		branch = head.Name().Short()
+	}
+	return head.Hash().String(), branch, nil
+}
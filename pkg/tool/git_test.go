@@ -0,0 +1,183 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepo creates a repository whose target branch origin/main is at the first of
+// the given commits. Each commit writes the given files.
+func testRepo(t *testing.T, commits ...map[string]string) (string, []plumbing.Hash) {
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+
+	var hashes []plumbing.Hash
+	for i, files := range commits {
+		for name, content := range files {
+			writeFile(t, dir, name, content)
+			_, err := worktree.Add(name)
+			require.NoError(t, err)
+		}
+		hash, err := worktree.Commit("commit", &git.CommitOptions{
+			Author: &object.Signature{Name: "zt", Email: "zt@example.com", When: time.Unix(int64(i), 0)},
+		})
+		require.NoError(t, err)
+		hashes = append(hashes, hash)
+	}
+	require.NoError(t, repo.Storer.SetReference(plumbing.NewHashReference("refs/remotes/origin/main", hashes[0])))
+	return dir, hashes
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+}
+
+func TestGit(t *testing.T) {
+	ctx := context.Background()
+	dir, hashes := testRepo(t,
+		map[string]string{"packages/api/zarf.yaml": "name: api\n", "packages/web/zarf.yaml": "name: web\n"},
+		map[string]string{"packages/api/zarf.yaml": "name: api\nversion: 1.0.1\n"},
+	)
+	// Uncommitted changes of tracked files are included, untracked files are not
+	writeFile(t, dir, "packages/web/zarf.yaml", "name: web\nversion: 2.0.0\n")
+	writeFile(t, dir, "packages/db/zarf.yaml", "name: db\n")
+
+	g := NewGit(dir)
+	require.NoError(t, g.ValidateRepository(ctx))
+	assert.True(t, g.BranchExists(ctx, "origin/main"))
+	assert.False(t, g.BranchExists(ctx, "origin/develop"))
+
+	mergeBase, err := g.MergeBase(ctx, "origin/main", "HEAD")
+	require.NoError(t, err)
+	assert.Equal(t, hashes[0].String(), mergeBase)
+
+	changed, err := g.ListChangedFilesInDirs(ctx, mergeBase, "packages")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"packages/api/zarf.yaml", "packages/web/zarf.yaml"}, changed)
+
+	changed, err = g.ListChangedFilesInDirs(ctx, mergeBase, "packages/api")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"packages/api/zarf.yaml"}, changed)
+
+	// Paths of ShowFile are relative to the directory of the Git
+	content, err := NewGit(filepath.Join(dir, "packages", "api")).ShowFile(ctx, mergeBase, "zarf.yaml")
+	require.NoError(t, err)
+	assert.Equal(t, "name: api\n", content)
+
+	content, err = g.Show(ctx, "packages/api/zarf.yaml", "origin", "main")
+	require.NoError(t, err)
+	assert.Equal(t, "name: api\n", content)
+
+	_, err = g.ShowFile(ctx, mergeBase, "packages/db/zarf.yaml")
+	assert.ErrorIs(t, err, ErrFileNotFound)
+
+	files, err := g.ListFilesInDirs(ctx, mergeBase, "packages")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"packages/api/zarf.yaml", "packages/web/zarf.yaml"}, files)
+
+	files, err = NewGit(filepath.Join(dir, "packages")).ListFilesInDirs(ctx, "HEAD", "web")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"web/zarf.yaml"}, files)
+
+	_, err = g.MergeBase(ctx, "origin/develop", "HEAD")
+	assert.ErrorIs(t, err, ErrReferenceNotFound)
+
+	err = NewGit(t.TempDir()).ValidateRepository(ctx)
+	assert.ErrorIs(t, err, ErrNotRepository)
+}
+
+func TestGitShallowClone(t *testing.T) {
+	ctx := context.Background()
+	dir, hashes := testRepo(t,
+		map[string]string{"zarf.yaml": "name: api\n"},
+		map[string]string{"zarf.yaml": "name: api\nversion: 1.0.1\n"},
+	)
+
+	// Only the last commit was fetched, the target branch points to a missing commit
+	shallow := hashes[1].String()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".git", "shallow"), []byte(shallow+"\n"), 0644))
+	first := hashes[0].String()
+	require.NoError(t, os.Remove(filepath.Join(dir, ".git", "objects", first[:2], first[2:])))
+
+	g := NewGit(dir)
+	isShallow, err := g.IsShallow(ctx)
+	require.NoError(t, err)
+	assert.True(t, isShallow)
+
+	_, err = g.MergeBase(ctx, "origin/main", "HEAD")
+	assert.ErrorIs(t, err, ErrShallowClone)
+}
+
+func TestTrackedFiles(t *testing.T) {
+	pointer := "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 12345\n"
+	dir, _ := testRepo(t, map[string]string{
+		"packages/app/zarf.yaml":      "kind: ZarfPackageConfig\n",
+		"packages/app/files/tool.bin": pointer,
+		"packages/other/zarf.yaml":    "kind: ZarfPackageConfig\n",
+	})
+	// Staged files are tracked, untracked files are not
+	writeFile(t, dir, "packages/app/files/data.bin", "data")
+	writeFile(t, dir, "packages/app/untracked.txt", "untracked")
+	repo, err := git.PlainOpen(dir)
+	require.NoError(t, err)
+	worktree, err := repo.Worktree()
+	require.NoError(t, err)
+	_, err = worktree.Add("packages/app/files/data.bin")
+	require.NoError(t, err)
+
+	files, err := NewGit(filepath.Join(dir, "packages")).TrackedFiles(context.Background(), "app")
+	require.NoError(t, err)
+	assert.Equal(t, []TrackedFile{
+		{Path: "app/files/data.bin", Size: 4},
+		{Path: "app/files/tool.bin", Size: int64(len(pointer)), LFSPointer: true},
+		{Path: "app/zarf.yaml", Size: 24},
+	}, files)
+}
+
+func TestExportDirs(t *testing.T) {
+	dir, hashes := testRepo(t,
+		map[string]string{"packages/app/zarf.yaml": "name: app\n", "shared/values.yaml": "color: blue\n", "other/file.txt": "other\n"},
+		map[string]string{"packages/app/zarf.yaml": "name: app\nversion: 1.0.1\n"},
+	)
+
+	dest := t.TempDir()
+	exported, err := NewGit(filepath.Join(dir, "packages", "app")).ExportDirs(context.Background(), hashes[0].String(), dest, ".", "../../shared")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dest, "packages", "app"), exported)
+
+	content, err := os.ReadFile(filepath.Join(exported, "zarf.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "name: app\n", string(content))
+	content, err = os.ReadFile(filepath.Join(dest, "shared", "values.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, "color: blue\n", string(content))
+	assert.NoFileExists(t, filepath.Join(dest, "other", "file.txt"))
+}
@@ -15,12 +15,18 @@
 package tool
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	osexec "os/exec"
 	"strings"
 
 	"github.com/cpepper96/zarf-testing/pkg/exec"
 )
 
+// ErrHelmNotFound is returned when charts are rendered without helm in PATH
+var ErrHelmNotFound = errors.New("helm not found in PATH")
+
 type Helm struct {
 	exec          exec.ProcessExecutor
 	extraArgs     []string
@@ -37,65 +43,97 @@ func NewHelm(exec exec.ProcessExecutor, extraArgs, lintExtraArgs, extraSetArgs [
 	}
 }
 
-func (h Helm) AddRepo(name string, url string, extraArgs []string) error {
+func (h Helm) AddRepo(ctx context.Context, name string, url string, extraArgs []string) error {
 	const ociPrefix string = "oci://"
 
 	if strings.HasPrefix(url, ociPrefix) {
 		registryDomain := url[len(ociPrefix):]
-		return h.exec.RunProcess("helm", "registry", "login", registryDomain, extraArgs)
+		return h.exec.RunProcess(ctx, "helm", "registry", "login", registryDomain, extraArgs)
 	}
 
-	return h.exec.RunProcess("helm", "repo", "add", name, url, extraArgs)
+	return h.exec.RunProcess(ctx, "helm", "repo", "add", name, url, extraArgs)
 }
 
-func (h Helm) BuildDependencies(chart string) error {
-	return h.BuildDependenciesWithArgs(chart, []string{})
+func (h Helm) BuildDependencies(ctx context.Context, chart string) error {
+	return h.BuildDependenciesWithArgs(ctx, chart, []string{})
 }
 
-func (h Helm) BuildDependenciesWithArgs(chart string, extraArgs []string) error {
-	return h.exec.RunProcess("helm", "dependency", "build", chart, extraArgs)
+func (h Helm) BuildDependenciesWithArgs(ctx context.Context, chart string, extraArgs []string) error {
+	return h.exec.RunProcess(ctx, "helm", "dependency", "build", chart, extraArgs)
 }
 
-func (h Helm) LintWithValues(chart string, valuesFile string) error {
+func (h Helm) LintWithValues(ctx context.Context, chart string, valuesFile string) error {
 	var values []string
 	if valuesFile != "" {
 		values = []string{"--values", valuesFile}
 	}
 
-	return h.exec.RunProcess("helm", "lint", chart, values, h.lintExtraArgs)
+	return h.exec.RunProcess(ctx, "helm", "lint", chart, values, h.lintExtraArgs)
 }
 
-func (h Helm) InstallWithValues(chart string, valuesFile string, namespace string, release string) error {
+func (h Helm) InstallWithValues(ctx context.Context, chart string, valuesFile string, namespace string, release string) error {
 	var values []string
 	if valuesFile != "" {
 		values = []string{"--values", valuesFile}
 	}
 
-	return h.exec.RunProcess("helm", "install", release, chart, "--namespace", namespace,
+	return h.exec.RunProcess(ctx, "helm", "install", release, chart, "--namespace", namespace,
 		"--wait", values, h.extraArgs, h.extraSetArgs)
 }
 
-func (h Helm) UpgradeWithValues(chart string, valuesFile string, namespace string, release string) error {
+func (h Helm) UpgradeWithValues(ctx context.Context, chart string, valuesFile string, namespace string, release string) error {
 	var values []string
 	if valuesFile != "" {
 		values = []string{"--values", valuesFile}
 	}
 
-	return h.exec.RunProcess("helm", "upgrade", release, chart, "--namespace", namespace,
+	return h.exec.RunProcess(ctx, "helm", "upgrade", release, chart, "--namespace", namespace,
 		"--wait", values, h.extraArgs, h.extraSetArgs)
 }
 
-func (h Helm) Test(namespace string, release string) error {
-	return h.exec.RunProcess("helm", "test", release, "--namespace", namespace, h.extraArgs)
+// Template renders the chart with the given values files and returns the resulting
+// manifests. On failure the returned error contains the output of helm.
+func (h Helm) Template(ctx context.Context, release string, chart string, namespace string, valuesFiles []string) (string, error) {
+	if _, err := osexec.LookPath("helm"); err != nil {
+		return "", ErrHelmNotFound
+	}
+
+	args := []interface{}{"template", release, chart}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	for _, valuesFile := range valuesFiles {
+		args = append(args, "--values", valuesFile)
+	}
+	cmd, err := h.exec.CreateProcess(ctx, "helm", append(args, h.extraSetArgs)...)
+	if err != nil {
+		return "", err
+	}
+
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("%s", message)
+	}
+	return string(output), nil
+}
+
+func (h Helm) Test(ctx context.Context, namespace string, release string) error {
+	return h.exec.RunProcess(ctx, "helm", "test", release, "--namespace", namespace, h.extraArgs)
 }
 
-func (h Helm) DeleteRelease(namespace string, release string) {
+func (h Helm) DeleteRelease(ctx context.Context, namespace string, release string) {
 	fmt.Printf("Deleting release %q...\n", release)
-	if err := h.exec.RunProcess("helm", "uninstall", release, "--namespace", namespace, "--wait", h.extraArgs); err != nil {
+	if err := h.exec.RunProcess(ctx, "helm", "uninstall", release, "--namespace", namespace, "--wait", h.extraArgs); err != nil {
 		fmt.Println("Error deleting Helm release:", err)
 	}
 }
 
-func (h Helm) Version() (string, error) {
-	return h.exec.RunProcessAndCaptureStdout("helm", "version", "--template", "{{ .Version }}")
+func (h Helm) Version(ctx context.Context) (string, error) {
+	return h.exec.RunProcessAndCaptureStdout(ctx, "helm", "version", "--template", "{{ .Version }}")
 }
@@ -1,6 +1,7 @@
 package tool
 
 import (
+	"context"
 	"bytes"
 	"encoding/json"
 	"errors"
@@ -26,30 +27,30 @@ func NewKubectl(exec exec.ProcessExecutor, timeout time.Duration) Kubectl {
 }
 
 // CreateNamespace creates a new namespace with the given name.
-func (k Kubectl) CreateNamespace(namespace string) error {
+func (k Kubectl) CreateNamespace(ctx context.Context, namespace string) error {
 	fmt.Printf("Creating namespace %q...\n", namespace)
-	return k.exec.RunProcess("kubectl",
+	return k.exec.RunProcess(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"create", "namespace", namespace)
 }
 
 // DeleteNamespace deletes the specified namespace. If the namespace does not terminate within 120s, pods running in the
 // namespace and, eventually, the namespace itself are force-deleted.
-func (k Kubectl) DeleteNamespace(namespace string) {
+func (k Kubectl) DeleteNamespace(ctx context.Context, namespace string) {
 	fmt.Printf("Deleting namespace %q...\n", namespace)
 	timeoutSec := "180s"
-	err := k.exec.RunProcess("kubectl",
+	err := k.exec.RunProcess(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"delete", "namespace", namespace, "--timeout", timeoutSec)
 	if err != nil {
 		fmt.Printf("Namespace %q did not terminate after %s.\n", namespace, timeoutSec)
 	}
 
-	if k.getNamespace(namespace) {
+	if k.getNamespace(ctx, namespace) {
 		fmt.Printf("Namespace %q did not terminate after %s.\n", namespace, timeoutSec)
 
 		fmt.Println("Force-deleting everything...")
-		err = k.exec.RunProcess("kubectl",
+		err = k.exec.RunProcess(ctx, "kubectl",
 			fmt.Sprintf("--request-timeout=%s", k.timeout),
 			"delete", "all", "--namespace", namespace, "--all", "--force",
 			"--grace-period=0")
@@ -60,17 +61,17 @@ func (k Kubectl) DeleteNamespace(namespace string) {
 		// Give it some more time to be deleted by K8s
 		time.Sleep(5 * time.Second)
 
-		if k.getNamespace(namespace) {
-			if err := k.forceNamespaceDeletion(namespace); err != nil {
+		if k.getNamespace(ctx, namespace) {
+			if err := k.forceNamespaceDeletion(ctx, namespace); err != nil {
 				fmt.Println("Error force deleting namespace:", err)
 			}
 		}
 	}
 }
 
-func (k Kubectl) forceNamespaceDeletion(namespace string) error {
+func (k Kubectl) forceNamespaceDeletion(ctx context.Context, namespace string) error {
 	// Getting the namespace json to remove the finalizer
-	cmdOutput, err := k.exec.RunProcessAndCaptureStdout("kubectl",
+	cmdOutput, err := k.exec.RunProcessAndCaptureStdout(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"get", "namespace", namespace, "--output=json")
 	if err != nil {
@@ -115,7 +116,7 @@ func (k Kubectl) forceNamespaceDeletion(namespace string) error {
 		return nil
 	}
 
-	err = k.exec.RunWithProxy(fun)
+	err = k.exec.RunWithProxy(ctx, fun)
 	if err != nil {
 		return fmt.Errorf("cannot force-delete namespace %q: %w", namespace, err)
 	}
@@ -124,7 +125,7 @@ func (k Kubectl) forceNamespaceDeletion(namespace string) error {
 	time.Sleep(5 * time.Second)
 
 	// Check again
-	_, err = k.exec.RunProcessAndCaptureOutput("kubectl",
+	_, err = k.exec.RunProcessAndCaptureOutput(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"get", "namespace", namespace)
 	if err != nil {
@@ -133,7 +134,7 @@ func (k Kubectl) forceNamespaceDeletion(namespace string) error {
 	}
 
 	fmt.Printf("Force-deleting namespace %q...\n", namespace)
-	err = k.exec.RunProcess("kubectl",
+	err = k.exec.RunProcess(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"delete", "namespace", namespace, "--force", "--grace-period=0",
 		"--ignore-not-found=true")
@@ -145,8 +146,8 @@ func (k Kubectl) forceNamespaceDeletion(namespace string) error {
 	return nil
 }
 
-func (k Kubectl) WaitForDeployments(namespace string, selector string) error {
-	output, err := k.exec.RunProcessAndCaptureStdout("kubectl",
+func (k Kubectl) WaitForDeployments(ctx context.Context, namespace string, selector string) error {
+	output, err := k.exec.RunProcessAndCaptureStdout(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"get", "deployments", "--namespace", namespace, "--selector", selector,
 		"--output", "jsonpath={.items[*].metadata.name}")
@@ -157,7 +158,7 @@ func (k Kubectl) WaitForDeployments(namespace string, selector string) error {
 	deployments := strings.Fields(output)
 	for _, deployment := range deployments {
 		deployment = strings.Trim(deployment, "'")
-		err = k.exec.RunProcess("kubectl",
+		err = k.exec.RunProcess(ctx, "kubectl",
 			fmt.Sprintf("--request-timeout=%s", k.timeout),
 			"rollout", "status", "deployment", deployment, "--namespace", namespace)
 		if err != nil {
@@ -169,7 +170,7 @@ func (k Kubectl) WaitForDeployments(namespace string, selector string) error {
 		//
 		// Just after rollout, pods from the previous deployment revision may still be in a
 		// terminating state.
-		unavailable, err := k.exec.RunProcessAndCaptureStdout("kubectl",
+		unavailable, err := k.exec.RunProcessAndCaptureStdout(ctx, "kubectl",
 			fmt.Sprintf("--request-timeout=%s", k.timeout),
 			"get", "deployment", deployment, "--namespace", namespace, "--output",
 			`jsonpath={.status.unavailableReplicas}`)
@@ -184,8 +185,8 @@ func (k Kubectl) WaitForDeployments(namespace string, selector string) error {
 	return nil
 }
 
-func (k Kubectl) GetPodsforDeployment(namespace string, deployment string) ([]string, error) {
-	jsonString, _ := k.exec.RunProcessAndCaptureStdout("kubectl",
+func (k Kubectl) GetPodsforDeployment(ctx context.Context, namespace string, deployment string) ([]string, error) {
+	jsonString, _ := k.exec.RunProcessAndCaptureStdout(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"get", "deployment", deployment, "--namespace", namespace, "--output=json")
 	var deploymentMap map[string]interface{}
@@ -205,13 +206,13 @@ func (k Kubectl) GetPodsforDeployment(namespace string, deployment string) ([]st
 		ls += fmt.Sprintf("%s=%s", name, value)
 	}
 
-	return k.GetPods("--selector", ls, "--namespace", namespace, "--output", "jsonpath={.items[*].metadata.name}")
+	return k.GetPods(ctx, "--selector", ls, "--namespace", namespace, "--output", "jsonpath={.items[*].metadata.name}")
 }
 
-func (k Kubectl) GetPods(args ...string) ([]string, error) {
+func (k Kubectl) GetPods(ctx context.Context, args ...string) ([]string, error) {
 	kubectlArgs := []string{"get", "pods"}
 	kubectlArgs = append(kubectlArgs, args...)
-	pods, err := k.exec.RunProcessAndCaptureStdout("kubectl",
+	pods, err := k.exec.RunProcessAndCaptureStdout(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout), kubectlArgs)
 	if err != nil {
 		return nil, err
@@ -219,34 +220,34 @@ func (k Kubectl) GetPods(args ...string) ([]string, error) {
 	return strings.Fields(pods), nil
 }
 
-func (k Kubectl) GetEvents(namespace string) error {
-	return k.exec.RunProcess("kubectl",
+func (k Kubectl) GetEvents(ctx context.Context, namespace string) error {
+	return k.exec.RunProcess(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"get", "events", "--output", "wide", "--namespace", namespace, "--sort-by", "lastTimestamp")
 }
 
-func (k Kubectl) DescribePod(namespace string, pod string) error {
-	return k.exec.RunProcess("kubectl",
+func (k Kubectl) DescribePod(ctx context.Context, namespace string, pod string) error {
+	return k.exec.RunProcess(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"describe", "pod", pod, "--namespace", namespace)
 }
 
-func (k Kubectl) Logs(namespace string, pod string, container string) error {
-	return k.exec.RunProcess("kubectl",
+func (k Kubectl) Logs(ctx context.Context, namespace string, pod string, container string) error {
+	return k.exec.RunProcess(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"logs", pod, "--namespace", namespace, "--container", container)
 }
 
-func (k Kubectl) GetInitContainers(namespace string, pod string) ([]string, error) {
-	return k.GetPods(pod, "--no-headers", "--namespace", namespace, "--output", "jsonpath={.spec.initContainers[*].name}")
+func (k Kubectl) GetInitContainers(ctx context.Context, namespace string, pod string) ([]string, error) {
+	return k.GetPods(ctx, pod, "--no-headers", "--namespace", namespace, "--output", "jsonpath={.spec.initContainers[*].name}")
 }
 
-func (k Kubectl) GetContainers(namespace string, pod string) ([]string, error) {
-	return k.GetPods(pod, "--no-headers", "--namespace", namespace, "--output", "jsonpath={.spec.containers[*].name}")
+func (k Kubectl) GetContainers(ctx context.Context, namespace string, pod string) ([]string, error) {
+	return k.GetPods(ctx, pod, "--no-headers", "--namespace", namespace, "--output", "jsonpath={.spec.containers[*].name}")
 }
 
-func (k Kubectl) getNamespace(namespace string) bool {
-	_, err := k.exec.RunProcessAndCaptureOutput("kubectl",
+func (k Kubectl) getNamespace(ctx context.Context, namespace string) bool {
+	_, err := k.exec.RunProcessAndCaptureOutput(ctx, "kubectl",
 		fmt.Sprintf("--request-timeout=%s", k.timeout),
 		"get", "namespace", namespace)
 	if err != nil {
@@ -0,0 +1,70 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	osexec "os/exec"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+)
+
+// ErrKustomizeNotFound is returned when neither kustomize nor kubectl is available
+var ErrKustomizeNotFound = errors.New("neither kustomize nor kubectl found in PATH")
+
+type Kustomize struct {
+	exec exec.ProcessExecutor
+}
+
+func NewKustomize(exec exec.ProcessExecutor) Kustomize {
+	return Kustomize{
+		exec: exec,
+	}
+}
+
+// Build renders the kustomization in dir and returns the resulting manifests. The
+// standalone kustomize binary is preferred, 'kubectl kustomize' is used as a fallback.
+// On failure the returned error contains the output of the build.
+func (k Kustomize) Build(ctx context.Context, dir string) (string, error) {
+	var executable string
+	var args []interface{}
+	if _, err := osexec.LookPath("kustomize"); err == nil {
+		executable, args = "kustomize", []interface{}{"build", dir}
+	} else if _, err := osexec.LookPath("kubectl"); err == nil {
+		executable, args = "kubectl", []interface{}{"kustomize", dir}
+	} else {
+		return "", ErrKustomizeNotFound
+	}
+
+	cmd, err := k.exec.CreateProcess(ctx, executable, args...)
+	if err != nil {
+		return "", err
+	}
+
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("%s", message)
+	}
+	return string(output), nil
+}
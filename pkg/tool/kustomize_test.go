@@ -0,0 +1,73 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tool
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// withCommands replaces PATH with a directory holding the given shell scripts by name
+func withCommands(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake commands are shell scripts")
+	}
+	bin := t.TempDir()
+	for name, body := range scripts {
+		require.NoError(t, os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+body+"\n"), 0755))
+	}
+	t.Setenv("PATH", bin)
+}
+
+func TestKustomizeBuild(t *testing.T) {
+	ctx := context.Background()
+	kustomize := NewKustomize(exec.NewProcessExecutor(false))
+	dir := t.TempDir()
+
+	// The standalone binary is preferred over kubectl
+	withCommands(t, map[string]string{
+		"kustomize": `printf 'kind: ConfigMap\nname: %s %s\n' "$1" "$2"`,
+		"kubectl":   `echo "kubectl must not be called" >&2; exit 1`,
+	})
+	output, err := kustomize.Build(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ConfigMap\nname: build "+dir+"\n", output)
+
+	withCommands(t, map[string]string{"kubectl": `printf 'kind: ConfigMap\nname: %s %s\n' "$1" "$2"`})
+	output, err = kustomize.Build(ctx, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ConfigMap\nname: kustomize "+dir+"\n", output)
+
+	// The error of a failed build is its output
+	withCommands(t, map[string]string{"kustomize": `echo "partial" ; echo "  accumulating resources: missing.yaml  " >&2; exit 1`})
+	_, err = kustomize.Build(ctx, dir)
+	assert.EqualError(t, err, "accumulating resources: missing.yaml")
+
+	withCommands(t, map[string]string{"kustomize": `exit 3`})
+	_, err = kustomize.Build(ctx, dir)
+	assert.EqualError(t, err, "exit status 3")
+
+	withCommands(t, map[string]string{})
+	_, err = kustomize.Build(ctx, dir)
+	assert.ErrorIs(t, err, ErrKustomizeNotFound)
+}
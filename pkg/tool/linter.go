@@ -14,7 +14,11 @@
 
 package tool
 
-import "github.com/cpepper96/zarf-testing/pkg/exec"
+import (
+	"context"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+)
 
 type Linter struct {
 	exec exec.ProcessExecutor
@@ -26,10 +30,10 @@ func NewLinter(exec exec.ProcessExecutor) Linter {
 	}
 }
 
-func (l Linter) YamlLint(yamlFile string, configFile string) error {
-	return l.exec.RunProcess("yamllint", "--config-file", configFile, yamlFile)
+func (l Linter) YamlLint(ctx context.Context, yamlFile string, configFile string) error {
+	return l.exec.RunProcess(ctx, "yamllint", "--config-file", configFile, yamlFile)
 }
 
-func (l Linter) Yamale(yamlFile string, schemaFile string) error {
-	return l.exec.RunProcess("yamale", "--schema", schemaFile, yamlFile)
+func (l Linter) Yamale(ctx context.Context, yamlFile string, schemaFile string) error {
+	return l.exec.RunProcess(ctx, "yamale", "--schema", schemaFile, yamlFile)
 }
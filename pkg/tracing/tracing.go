@@ -0,0 +1,303 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package tracing records the phases of a run, such as building, deploying and
+// testing a package, as OpenTelemetry spans and exports them with OTLP over HTTP.
+// Without an exporter, spans are not recorded and cost next to nothing.
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// batchSize is the number of finished spans that are exported together
+const batchSize = 256
+
+// Config configures the export of spans
+type Config struct {
+	// Endpoint is the base URL of the OTLP/HTTP receiver, e.g. http://localhost:4318
+	Endpoint string
+	// Headers are sent with every export, e.g. for authentication
+	Headers map[string]string
+	// ServiceName and ServiceVersion identify zt in the resource of the spans
+	ServiceName    string
+	ServiceVersion string
+}
+
+// exporter buffers finished spans and sends them to the OTLP endpoint in batches
+type exporter struct {
+	config Config
+	client *http.Client
+	mu     sync.Mutex
+	spans  []*Span
+}
+
+var (
+	current   *exporter
+	currentMu sync.RWMutex
+)
+
+// Setup starts recording spans and exporting them as configured
+func Setup(config Config) {
+	currentMu.Lock()
+	defer currentMu.Unlock()
+	current = &exporter{config: config, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+// Shutdown exports the remaining spans and stops recording
+func Shutdown(ctx context.Context) error {
+	currentMu.Lock()
+	e := current
+	current = nil
+	currentMu.Unlock()
+	if e == nil {
+		return nil
+	}
+	e.mu.Lock()
+	spans := e.spans
+	e.spans = nil
+	e.mu.Unlock()
+	return e.export(ctx, spans)
+}
+
+// Span is a timed phase of a run. The methods of a nil span do nothing, so callers
+// do not need to check whether tracing is enabled.
+type Span struct {
+	exporter   *exporter
+	traceID    [16]byte
+	spanID     [8]byte
+	parentID   [8]byte
+	name       string
+	start      time.Time
+	end        time.Time
+	attributes [][2]string
+	err        error
+}
+
+type spanKey struct{}
+
+// Start starts a span as a child of the span in ctx, if any, and returns a context
+// containing the new span. Attributes are given as key, value pairs.
+func Start(ctx context.Context, name string, attributes ...string) (context.Context, *Span) {
+	currentMu.RLock()
+	e := current
+	currentMu.RUnlock()
+	if e == nil {
+		return ctx, nil
+	}
+
+	span := &Span{exporter: e, name: name, start: time.Now()}
+	if parent, ok := ctx.Value(spanKey{}).(*Span); ok && parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		rand.Read(span.traceID[:])
+	}
+	rand.Read(span.spanID[:])
+	for i := 0; i+1 < len(attributes); i += 2 {
+		span.SetAttribute(attributes[i], attributes[i+1])
+	}
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SetAttribute records an attribute of the span
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.attributes = append(s.attributes, [2]string{key, value})
+}
+
+// End ends the span, marking it as failed if err is not nil
+func (s *Span) End(err error) {
+	if s == nil {
+		return
+	}
+	s.end = time.Now()
+	s.err = err
+
+	e := s.exporter
+	e.mu.Lock()
+	e.spans = append(e.spans, s)
+	var batch []*Span
+	if len(e.spans) >= batchSize {
+		batch, e.spans = e.spans, nil
+	}
+	e.mu.Unlock()
+	if batch != nil {
+		if err := e.export(context.Background(), batch); err != nil {
+			slog.Warn("Failed to export spans", "error", err)
+		}
+	}
+}
+
+// export sends spans to the endpoint as an OTLP JSON request
+func (e *exporter) export(ctx context.Context, spans []*Span) error {
+	if len(spans) == 0 {
+		return nil
+	}
+	body, err := json.Marshal(e.request(spans))
+	if err != nil {
+		return err
+	}
+
+	endpoint := strings.TrimSuffix(e.config.Endpoint, "/")
+	if !strings.HasSuffix(endpoint, "/v1/traces") {
+		endpoint += "/v1/traces"
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range e.config.Headers {
+		req.Header.Set(key, value)
+	}
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to export spans: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to export spans: %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// The OTLP JSON encoding of the spans, see
+// https://opentelemetry.io/docs/specs/otlp/#json-protobuf-encoding
+type (
+	otlpRequest struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version,omitempty"`
+	}
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+		Status            otlpStatus      `json:"status"`
+	}
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+	otlpStatus struct {
+		Code    int    `json:"code"`
+		Message string `json:"message,omitempty"`
+	}
+)
+
+// Span kind and status codes of OTLP
+const (
+	spanKindInternal = 1
+	statusOK         = 1
+	statusError      = 2
+)
+
+func (e *exporter) request(spans []*Span) otlpRequest {
+	resource := []otlpAttribute{attribute("service.name", e.config.ServiceName)}
+	if e.config.ServiceVersion != "" {
+		resource = append(resource, attribute("service.version", e.config.ServiceVersion))
+	}
+
+	encoded := make([]otlpSpan, len(spans))
+	for i, span := range spans {
+		encoded[i] = otlpSpan{
+			TraceID:           hex.EncodeToString(span.traceID[:]),
+			SpanID:            hex.EncodeToString(span.spanID[:]),
+			Name:              span.name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: fmt.Sprint(span.start.UnixNano()),
+			EndTimeUnixNano:   fmt.Sprint(span.end.UnixNano()),
+			Status:            otlpStatus{Code: statusOK},
+		}
+		if span.parentID != [8]byte{} {
+			encoded[i].ParentSpanID = hex.EncodeToString(span.parentID[:])
+		}
+		for _, attr := range span.attributes {
+			encoded[i].Attributes = append(encoded[i].Attributes, attribute(attr[0], attr[1]))
+		}
+		if span.err != nil {
+			encoded[i].Status = otlpStatus{Code: statusError, Message: span.err.Error()}
+		}
+	}
+
+	return otlpRequest{ResourceSpans: []otlpResourceSpans{{
+		Resource: otlpResource{Attributes: resource},
+		ScopeSpans: []otlpScopeSpans{{
+			Scope: otlpScope{Name: "github.com/cpepper96/zarf-testing", Version: e.config.ServiceVersion},
+			Spans: encoded,
+		}},
+	}}}
+}
+
+func attribute(key, value string) otlpAttribute {
+	return otlpAttribute{Key: key, Value: otlpValue{StringValue: value}}
+}
+
+// ParseHeaders parses headers in the format of OTEL_EXPORTER_OTLP_HEADERS, a comma
+// separated list of key=value pairs
+func ParseHeaders(value string) (map[string]string, error) {
+	headers := map[string]string{}
+	for _, pair := range strings.Split(value, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			return nil, fmt.Errorf("invalid header %q, must be key=value", pair)
+		}
+		unescaped, err := url.PathUnescape(strings.TrimSpace(val))
+		if err != nil {
+			return nil, fmt.Errorf("invalid header %q: %w", pair, err)
+		}
+		headers[strings.TrimSpace(key)] = unescaped
+	}
+	return headers, nil
+}
@@ -0,0 +1,91 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tracing
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpansWithoutExporter(t *testing.T) {
+	ctx, span := Start(context.Background(), "install.package")
+	assert.Nil(t, span)
+	assert.Equal(t, context.Background(), ctx)
+
+	// Nil spans can be used like any other span
+	span.SetAttribute("package", "packages/a")
+	span.End(errors.New("failed"))
+	assert.NoError(t, Shutdown(context.Background()))
+}
+
+func TestExport(t *testing.T) {
+	var requests []otlpRequest
+	var path, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path, authorization = r.URL.Path, r.Header.Get("Authorization")
+		var request otlpRequest
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		requests = append(requests, request)
+	}))
+	defer server.Close()
+
+	Setup(Config{Endpoint: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}, ServiceName: "zt", ServiceVersion: "v1.2.3"})
+	ctx, root := Start(context.Background(), "zt install")
+	_, child := Start(ctx, "install.deploy", "package", "packages/a")
+	child.End(errors.New("zarf package deploy failed"))
+	root.End(nil)
+	require.NoError(t, Shutdown(context.Background()))
+
+	assert.Equal(t, "/v1/traces", path)
+	assert.Equal(t, "Bearer token", authorization)
+	require.Len(t, requests, 1)
+	resourceSpans := requests[0].ResourceSpans[0]
+	assert.Equal(t, []otlpAttribute{attribute("service.name", "zt"), attribute("service.version", "v1.2.3")}, resourceSpans.Resource.Attributes)
+
+	spans := resourceSpans.ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+	deploy, install := spans[0], spans[1]
+	assert.Equal(t, "install.deploy", deploy.Name)
+	assert.Equal(t, install.TraceID, deploy.TraceID)
+	assert.Equal(t, install.SpanID, deploy.ParentSpanID)
+	assert.Len(t, deploy.SpanID, 16)
+	assert.Equal(t, []otlpAttribute{attribute("package", "packages/a")}, deploy.Attributes)
+	assert.Equal(t, otlpStatus{Code: statusError, Message: "zarf package deploy failed"}, deploy.Status)
+
+	assert.Equal(t, "zt install", install.Name)
+	assert.Empty(t, install.ParentSpanID)
+	assert.Len(t, install.TraceID, 32)
+	assert.Equal(t, otlpStatus{Code: statusOK}, install.Status)
+
+	// Spans are no longer recorded after the shutdown
+	_, span := Start(context.Background(), "lint.package")
+	assert.Nil(t, span)
+}
+
+func TestParseHeaders(t *testing.T) {
+	headers, err := ParseHeaders("api-key=secret, x-team = platform%20team,")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"api-key": "secret", "x-team": "platform team"}, headers)
+
+	_, err = ParseHeaders("api-key")
+	assert.EqualError(t, err, `invalid header "api-key", must be key=value`)
+}
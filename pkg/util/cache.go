@@ -0,0 +1,109 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// zarfYamlCacheVersion is part of the path of cached files and must be changed when
+// ZarfYaml changes so that files cached by earlier versions are not used
+const zarfYamlCacheVersion = "zarf-yaml-v2"
+
+// maxCacheEntries bounds the in-memory cache of long-running processes such as the
+// language server, which parse every edit of a file
+const maxCacheEntries = 1024
+
+// zarfYamlCache holds parsed zarf.yaml files by the SHA-256 of their content, so that
+// the validation passes reading the same file parse it only once
+var zarfYamlCache = &contentCache{entries: map[string]*ZarfYaml{}}
+
+type contentCache struct {
+	mu      sync.Mutex
+	dir     string
+	entries map[string]*ZarfYaml
+}
+
+// SetCacheDir additionally caches parsed files in dir across runs, e.g. .zt-cache.
+// An empty dir caches in memory only.
+func SetCacheDir(dir string) {
+	zarfYamlCache.mu.Lock()
+	defer zarfYamlCache.mu.Unlock()
+	zarfYamlCache.dir = dir
+}
+
+func contentHash(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+func (c *contentCache) path(hash string) string {
+	return filepath.Join(c.dir, zarfYamlCacheVersion, hash+".json")
+}
+
+func (c *contentCache) get(hash string) (*ZarfYaml, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if zarfYaml, ok := c.entries[hash]; ok {
+		return zarfYaml, true
+	}
+	if c.dir == "" {
+		return nil, false
+	}
+
+	content, err := os.ReadFile(c.path(hash))
+	if err != nil {
+		return nil, false
+	}
+	zarfYaml := &ZarfYaml{}
+	if err := json.Unmarshal(content, zarfYaml); err != nil {
+		return nil, false
+	}
+	c.add(hash, zarfYaml)
+	return zarfYaml, true
+}
+
+// put caches a parsed file. Failures to write the on-disk cache are ignored as the
+// file is parsed again on the next run.
+func (c *contentCache) put(hash string, zarfYaml *ZarfYaml) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(hash, zarfYaml)
+	if c.dir == "" {
+		return
+	}
+
+	content, err := json.Marshal(zarfYaml)
+	if err != nil {
+		return
+	}
+	path := c.path(hash)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return
+	}
+	_ = os.WriteFile(path, content, 0644)
+}
+
+func (c *contentCache) add(hash string, zarfYaml *ZarfYaml) {
+	if len(c.entries) >= maxCacheEntries {
+		c.entries = map[string]*ZarfYaml{}
+	}
+	c.entries[hash] = zarfYaml
+}
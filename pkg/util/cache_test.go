@@ -0,0 +1,55 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadZarfYamlCache(t *testing.T) {
+	cacheDir := t.TempDir()
+	SetCacheDir(cacheDir)
+	defer SetCacheDir("")
+
+	path := filepath.Join(t.TempDir(), "zarf.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\n"), 0644))
+
+	first, err := ReadZarfYaml(path)
+	require.NoError(t, err)
+	second, err := ReadZarfYaml(path)
+	require.NoError(t, err)
+	assert.Same(t, first, second, "unchanged files are parsed once")
+
+	// Files are cached on disk by content
+	cached, err := filepath.Glob(filepath.Join(cacheDir, zarfYamlCacheVersion, "*.json"))
+	require.NoError(t, err)
+	require.Len(t, cached, 1)
+
+	zarfYamlCache.entries = map[string]*ZarfYaml{}
+	fromDisk, err := ReadZarfYaml(path)
+	require.NoError(t, err)
+	assert.NotSame(t, first, fromDisk)
+	assert.Equal(t, first, fromDisk)
+
+	require.NoError(t, os.WriteFile(path, []byte("kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\n  version: 1.0.0\n"), 0644))
+	changed, err := ReadZarfYaml(path)
+	require.NoError(t, err)
+	assert.Equal(t, "1.0.0", changed.Metadata.Version)
+}
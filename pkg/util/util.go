@@ -24,6 +24,7 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"time"
 
@@ -40,12 +41,19 @@ type Maintainer struct {
 }
 
 type ChartYaml struct {
-	Name        string `yaml:"name"`
-	Version     string `yaml:"version"`
-	Deprecated  bool   `yaml:"deprecated"`
+	Name        string            `yaml:"name"`
+	Version     string            `yaml:"version"`
+	Deprecated  bool              `yaml:"deprecated"`
 	Maintainers []Maintainer
+	Annotations map[string]string `yaml:"annotations"`
 }
 
+// Kinds of zarf.yaml: regular packages and the init packages deployed by 'zarf init'
+const (
+	ZarfPackageKind = "ZarfPackageConfig"
+	ZarfInitKind    = "ZarfInitConfig"
+)
+
 type ZarfYaml struct {
 	Kind     string `yaml:"kind"`
 	Metadata struct {
@@ -54,10 +62,19 @@ type ZarfYaml struct {
 		Version      string `yaml:"version"`
 		Architecture string `yaml:"architecture,omitempty"`
 		Deprecated   bool   `yaml:"deprecated,omitempty"`
+		Annotations  map[string]string `yaml:"annotations,omitempty"`
+		Extra        map[string]interface{} `yaml:",inline"`
 	} `yaml:"metadata"`
 	Variables []ZarfVariable  `yaml:"variables,omitempty"`
 	Constants []ZarfConstant  `yaml:"constants,omitempty"`
 	Components []ZarfComponent `yaml:"components,omitempty"`
+	// Extra holds the keys that are not modeled, such as build, see ParseZarfYaml
+	Extra map[string]interface{} `yaml:",inline"`
+}
+
+// IsInitPackage reports whether the zarf.yaml defines an init package
+func (z *ZarfYaml) IsInitPackage() bool {
+	return z.Kind == ZarfInitKind
 }
 
 type ZarfVariable struct {
@@ -65,11 +82,13 @@ type ZarfVariable struct {
 	Description string `yaml:"description,omitempty"`
 	Default     string `yaml:"default,omitempty"`
 	Prompt      bool   `yaml:"prompt,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfConstant struct {
 	Name  string `yaml:"name"`
 	Value string `yaml:"value"`
+	Extra map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfComponent struct {
@@ -87,16 +106,37 @@ type ZarfComponent struct {
 	Repos       []string            `yaml:"repos,omitempty"`
 	DataInjections []ZarfDataInjection `yaml:"dataInjections,omitempty"`
 	Scripts     ZarfComponentScripts `yaml:"scripts,omitempty"`
+	Actions     ZarfComponentActions `yaml:"actions,omitempty"`
+	Import      ZarfComponentImport  `yaml:"import,omitempty"`
+	Zt          ZarfComponentZt     `yaml:"x-zt,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+// ZarfComponentZt is the 'x-zt' extension block of a component, holding the settings
+// only zt reads
+type ZarfComponentZt struct {
+	Deprecated  bool                   `yaml:"deprecated,omitempty"`
+	Replacement string                 `yaml:"replacement,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
+}
+
+type ZarfComponentImport struct {
+	Name string `yaml:"name,omitempty"`
+	Path string `yaml:"path,omitempty"`
+	URL  string `yaml:"url,omitempty"`
+	Extra map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfComponentOnly struct {
 	LocalOS      string `yaml:"localOS,omitempty"`
 	Cluster      ZarfComponentOnlyCluster `yaml:"cluster,omitempty"`
+	Extra   map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfComponentOnlyCluster struct {
 	Architecture string   `yaml:"architecture,omitempty"`
 	Distros      []string `yaml:"distros,omitempty"`
+	Extra        map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfFile struct {
@@ -105,6 +145,7 @@ type ZarfFile struct {
 	Shasum      string   `yaml:"shasum,omitempty"`
 	Executable  bool     `yaml:"executable,omitempty"`
 	ExtractPath string   `yaml:"extractPath,omitempty"`
+	Extra       map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfChart struct {
@@ -119,12 +160,14 @@ type ZarfChart struct {
 	NoWait       bool                   `yaml:"noWait,omitempty"`
 	ValuesFiles  []string               `yaml:"valuesFiles,omitempty"`
 	Variables    []ZarfChartVariable    `yaml:"variables,omitempty"`
+	Extra        map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfChartVariable struct {
 	Name        string `yaml:"name"`
 	Description string `yaml:"description,omitempty"`
 	Path        string `yaml:"path"`
+	Extra       map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfManifest struct {
@@ -133,12 +176,14 @@ type ZarfManifest struct {
 	Files             []string `yaml:"files,omitempty"`
 	KustomizeAllowAnyOf []string `yaml:"kustomizeAllowAnyOf,omitempty"`
 	Kustomizations    []string `yaml:"kustomizations,omitempty"`
+	Extra             map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfDataInjection struct {
 	Source   string `yaml:"source"`
 	Target   ZarfContainerTarget `yaml:"target"`
 	Compress bool   `yaml:"compress,omitempty"`
+	Extra    map[string]interface{} `yaml:",inline"`
 }
 
 type ZarfContainerTarget struct {
@@ -146,8 +191,80 @@ type ZarfContainerTarget struct {
 	Selector  string `yaml:"selector"`
 	Container string `yaml:"container"`
 	Path      string `yaml:"path"`
+	Extra     map[string]interface{} `yaml:",inline"`
+}
+
+// ZarfComponentActions are the commands and waits zarf runs while the package is
+// created, deployed and removed. They replace the deprecated scripts block.
+type ZarfComponentActions struct {
+	OnCreate ZarfComponentActionSet `yaml:"onCreate,omitempty"`
+	OnDeploy ZarfComponentActionSet `yaml:"onDeploy,omitempty"`
+	OnRemove ZarfComponentActionSet `yaml:"onRemove,omitempty"`
+	Extra    map[string]interface{} `yaml:",inline"`
+}
+
+type ZarfComponentActionSet struct {
+	Defaults  ZarfComponentActionDefaults `yaml:"defaults,omitempty"`
+	Before    []ZarfComponentAction       `yaml:"before,omitempty"`
+	After     []ZarfComponentAction       `yaml:"after,omitempty"`
+	OnSuccess []ZarfComponentAction       `yaml:"onSuccess,omitempty"`
+	OnFailure []ZarfComponentAction       `yaml:"onFailure,omitempty"`
+	Extra     map[string]interface{} `yaml:",inline"`
+}
+
+type ZarfComponentActionDefaults struct {
+	Mute            bool     `yaml:"mute,omitempty"`
+	MaxTotalSeconds int      `yaml:"maxTotalSeconds,omitempty"`
+	MaxRetries      int      `yaml:"maxRetries,omitempty"`
+	Dir             string   `yaml:"dir,omitempty"`
+	Env             []string `yaml:"env,omitempty"`
+	Extra           map[string]interface{} `yaml:",inline"`
+}
+
+// ZarfComponentAction runs either Cmd or waits for Wait
+type ZarfComponentAction struct {
+	Cmd             string                   `yaml:"cmd,omitempty"`
+	Description     string                   `yaml:"description,omitempty"`
+	Dir             string                   `yaml:"dir,omitempty"`
+	Env             []string                 `yaml:"env,omitempty"`
+	Mute            *bool                    `yaml:"mute,omitempty"`
+	MaxTotalSeconds *int                     `yaml:"maxTotalSeconds,omitempty"`
+	MaxRetries      *int                     `yaml:"maxRetries,omitempty"`
+	Wait            *ZarfComponentActionWait `yaml:"wait,omitempty"`
+	SetVariables    []ZarfSetVariable        `yaml:"setVariables,omitempty"`
+	Extra           map[string]interface{} `yaml:",inline"`
+}
+
+type ZarfComponentActionWait struct {
+	Cluster *ZarfComponentActionWaitCluster `yaml:"cluster,omitempty"`
+	Network *ZarfComponentActionWaitNetwork `yaml:"network,omitempty"`
+	Extra   map[string]interface{} `yaml:",inline"`
+}
+
+type ZarfComponentActionWaitCluster struct {
+	Kind      string `yaml:"kind"`
+	Name      string `yaml:"name"`
+	Namespace string `yaml:"namespace,omitempty"`
+	Condition string `yaml:"condition,omitempty"`
+	Extra     map[string]interface{} `yaml:",inline"`
+}
+
+type ZarfComponentActionWaitNetwork struct {
+	Protocol string `yaml:"protocol"`
+	Address  string `yaml:"address"`
+	Code     int    `yaml:"code,omitempty"`
+	Extra    map[string]interface{} `yaml:",inline"`
+}
+
+type ZarfSetVariable struct {
+	Name       string `yaml:"name"`
+	Sensitive  bool   `yaml:"sensitive,omitempty"`
+	AutoIndent bool   `yaml:"autoIndent,omitempty"`
+	Type       string `yaml:"type,omitempty"`
+	Extra      map[string]interface{} `yaml:",inline"`
 }
 
+// ZarfComponentScripts is the deprecated predecessor of ZarfComponentActions
 type ZarfComponentScripts struct {
 	ShowOutput bool     `yaml:"showOutput,omitempty"`
 	TimeoutSeconds int  `yaml:"timeoutSeconds,omitempty"`
@@ -155,6 +272,7 @@ type ZarfComponentScripts struct {
 	Prepare    []string `yaml:"prepare,omitempty"`
 	Before     []string `yaml:"before,omitempty"`
 	After      []string `yaml:"after,omitempty"`
+	Extra          map[string]interface{} `yaml:",inline"`
 }
 
 func Flatten(items []interface{}) ([]string, error) {
@@ -203,6 +321,49 @@ func FileExists(file string) bool {
 	return true
 }
 
+// sizeUnits are the units accepted by ParseSize, all multiples of 1024
+var sizeUnits = []struct {
+	suffix string
+	bytes  int64
+}{
+	{"GIB", 1 << 30}, {"GB", 1 << 30}, {"G", 1 << 30},
+	{"MIB", 1 << 20}, {"MB", 1 << 20}, {"M", 1 << 20},
+	{"KIB", 1 << 10}, {"KB", 1 << 10}, {"K", 1 << 10},
+	{"B", 1},
+}
+
+// ParseSize parses a size such as "50MB", "1.5GiB" or "1024" (bytes). KB, MB and GB
+// are treated like KiB, MiB and GiB.
+func ParseSize(size string) (int64, error) {
+	value := strings.ToUpper(strings.TrimSpace(size))
+	multiplier := int64(1)
+	for _, unit := range sizeUnits {
+		if strings.HasSuffix(value, unit.suffix) {
+			value = strings.TrimSpace(strings.TrimSuffix(value, unit.suffix))
+			multiplier = unit.bytes
+			break
+		}
+	}
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil || number < 0 {
+		return 0, fmt.Errorf("invalid size %q", size)
+	}
+	return int64(number * float64(multiplier)), nil
+}
+
+// FormatSize formats a number of bytes with the largest unit that keeps it above one
+func FormatSize(bytes int64) string {
+	for _, unit := range []struct {
+		suffix string
+		bytes  int64
+	}{{"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if bytes >= unit.bytes {
+			return strconv.FormatFloat(float64(bytes)/float64(unit.bytes), 'f', 1, 64) + unit.suffix
+		}
+	}
+	return fmt.Sprintf("%dB", bytes)
+}
+
 // RandomString string creates a random string of numbers and lower-case ascii characters with the specified length.
 func RandomString(length int) string {
 	n := len(chars)
@@ -291,22 +452,37 @@ func UnmarshalChartYaml(yamlBytes []byte) (*ChartYaml, error) {
 }
 
 // ReadZarfYaml attempts to parse zarf.yaml within the specified directory
-// and return a newly allocated ZarfYaml object. If no zarf.yaml is present
-// or there is an error unmarshaling the file contents, an error will be returned.
+// and return a ZarfYaml object. If no zarf.yaml is present or there is an
+// error unmarshaling the file contents, an error will be returned. Parsed
+// files are cached by content, so the result is shared and must not be modified.
 func ReadZarfYaml(path string) (*ZarfYaml, error) {
 	yamlBytes, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("could not read 'zarf.yaml': %w", err)
 	}
-	return UnmarshalZarfYaml(yamlBytes)
+
+	hash := contentHash(yamlBytes)
+	if zarfYaml, ok := zarfYamlCache.get(hash); ok {
+		return zarfYaml, nil
+	}
+	zarfYaml, err := UnmarshalZarfYaml(yamlBytes)
+	if err != nil {
+		return nil, err
+	}
+	zarfYamlCache.put(hash, zarfYaml)
+	return zarfYaml, nil
 }
 
 // UnmarshalZarfYaml parses the yaml encoded data and returns a newly
-// allocated ZarfYaml object.
+// allocated ZarfYaml object. Duplicate keys and multiple documents are
+// errors, see ParseZarfYaml.
 func UnmarshalZarfYaml(yamlBytes []byte) (*ZarfYaml, error) {
-	zarfYaml := &ZarfYaml{}
-	if err := yaml.Unmarshal(yamlBytes, zarfYaml); err != nil {
-		return nil, fmt.Errorf("could not unmarshal 'zarf.yaml': %w", err)
+	zarfYaml, problems, err := ParseZarfYaml(yamlBytes, false)
+	if err != nil {
+		return nil, err
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("could not unmarshal 'zarf.yaml': line %d: %s", problems[0].Line, problems[0].Message)
 	}
 	return zarfYaml, nil
 }
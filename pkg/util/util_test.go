@@ -118,3 +118,34 @@ func TestBreakingChangeAllowed(t *testing.T) {
 		})
 	}
 }
+
+func TestParseSize(t *testing.T) {
+	var testDataSlice = []struct {
+		size     string
+		expected int64
+	}{
+		{"1024", 1024},
+		{"10B", 10},
+		{"1K", 1 << 10},
+		{"50MB", 50 << 20},
+		{"50 mib", 50 << 20},
+		{"1.5GiB", 3 << 29},
+	}
+
+	for _, testData := range testDataSlice {
+		t.Run(testData.size, func(t *testing.T) {
+			actual, err := ParseSize(testData.size)
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expected, actual)
+		})
+	}
+
+	for _, size := range []string{"", "MB", "-1MB", "ten"} {
+		_, err := ParseSize(size)
+		assert.Error(t, err, size)
+	}
+
+	assert.Equal(t, "512B", FormatSize(512))
+	assert.Equal(t, "1.5KiB", FormatSize(1536))
+	assert.Equal(t, "120.0MiB", FormatSize(120<<20))
+}
@@ -0,0 +1,553 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package yamledit edits YAML files in place. Only the bytes of the edited values
+// change, so comments, the order of keys, indentation and the quoting of the other
+// values are kept. Paths address values by mapping keys (strings) and sequence
+// indexes (ints), e.g. "components", 0, "images", 1.
+package yamledit
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Document is the first document of a YAML file being edited
+type Document struct {
+	content []byte
+	root    *yaml.Node // Top-level node, nil for empty documents
+}
+
+// Parse parses content for editing
+func Parse(content []byte) (*Document, error) {
+	d := &Document{content: content}
+	if err := d.parse(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+// ReadFile parses the file at path for editing
+func ReadFile(path string) (*Document, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	d, err := Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return d, nil
+}
+
+// WriteFile writes the edited document to path, keeping the mode of an existing file
+func (d *Document) WriteFile(path string) error {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+	return os.WriteFile(path, d.content, mode)
+}
+
+// Bytes returns the edited content
+func (d *Document) Bytes() []byte {
+	return d.content
+}
+
+// parse refreshes the nodes, and with them the positions, after the content changed
+func (d *Document) parse() error {
+	var document yaml.Node
+	if err := yaml.NewDecoder(bytes.NewReader(d.content)).Decode(&document); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	d.root = nil
+	if len(document.Content) > 0 {
+		d.root = document.Content[0]
+	}
+	return nil
+}
+
+// Get returns the scalar value at path and whether there is one
+func (d *Document) Get(path ...interface{}) (string, bool) {
+	node, _, err := d.find(path)
+	if err != nil || node == nil || node.Kind != yaml.ScalarNode {
+		return "", false
+	}
+	return node.Value, true
+}
+
+// Strings returns the scalar values of the sequence at path and whether there is one
+func (d *Document) Strings(path ...interface{}) ([]string, bool) {
+	node, _, err := d.find(path)
+	if err != nil || node == nil || node.Kind != yaml.SequenceNode {
+		return nil, false
+	}
+	values := []string{}
+	for _, item := range node.Content {
+		if item.Kind != yaml.ScalarNode {
+			return nil, false
+		}
+		values = append(values, item.Value)
+	}
+	return values, true
+}
+
+// Position returns the 1-based line and column of the value at path, and whether
+// there is one
+func (d *Document) Position(path ...interface{}) (int, int, bool) {
+	node, _, err := d.find(path)
+	if err != nil || node == nil {
+		return 0, 0, false
+	}
+	return node.Line, node.Column, true
+}
+
+// Set sets the scalar at path to value, keeping its quoting where possible. Missing
+// mapping keys at the end of path are added after the last entry of their mapping,
+// at its indentation.
+func (d *Document) Set(value string, path ...interface{}) error {
+	if strings.Contains(value, "\n") {
+		return fmt.Errorf("cannot set %s to a value spanning several lines", FormatPath(path))
+	}
+	node, parent, err := d.find(path)
+	if err != nil {
+		return err
+	}
+	if node == nil {
+		return d.insert(parent, path, value)
+	}
+	if node.Kind != yaml.ScalarNode {
+		return fmt.Errorf("%s is not a scalar", FormatPath(path))
+	}
+	if node.Value == value {
+		return nil
+	}
+	start, end, err := d.scalarSpan(node)
+	if err != nil {
+		return fmt.Errorf("cannot edit %s: %w", FormatPath(path), err)
+	}
+	return d.replace(start, end, renderScalar(value, node.Style, node.Tag))
+}
+
+// Delete removes the mapping entry at path, including the comment above it. Deleting
+// a missing entry does nothing.
+func (d *Document) Delete(path ...interface{}) error {
+	if len(path) == 0 {
+		return errors.New("cannot delete the document")
+	}
+	name, ok := path[len(path)-1].(string)
+	if !ok {
+		return fmt.Errorf("cannot delete %s, only mapping entries can be deleted", FormatPath(path))
+	}
+	mapping, _, err := d.find(path[:len(path)-1])
+	if err != nil || mapping == nil {
+		return err
+	}
+	if mapping.Kind != yaml.MappingNode || mapping.Style&yaml.FlowStyle != 0 {
+		return fmt.Errorf("cannot delete %s, %s is not a block mapping", FormatPath(path), FormatPath(path[:len(path)-1]))
+	}
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		key, value := mapping.Content[i], mapping.Content[i+1]
+		if key.Value != name {
+			continue
+		}
+		start := d.lineOffset(key.Line)
+		if strings.TrimSpace(string(d.content[start:d.offset(key.Line, key.Column)])) != "" {
+			return fmt.Errorf("cannot delete %s, it starts a list item", FormatPath(path))
+		}
+		if key.HeadComment != "" {
+			start = d.lineOffset(key.Line - strings.Count(key.HeadComment, "\n") - 1)
+		}
+		return d.replace(start, d.lineOffset(endLine(value)+1), "")
+	}
+	return nil
+}
+
+// Sort sorts the scalars of the sequence at path by their value. The items of block
+// sequences are moved with their comments, each item must be on a line of its own.
+func (d *Document) Sort(path ...interface{}) error {
+	node, _, err := d.find(path)
+	if err != nil {
+		return err
+	}
+	if node == nil || node.Kind != yaml.SequenceNode {
+		return fmt.Errorf("%s is not a sequence", FormatPath(path))
+	}
+	type item struct {
+		value      string
+		start, end int
+	}
+	items := make([]item, len(node.Content))
+	for i, child := range node.Content {
+		if child.Kind != yaml.ScalarNode {
+			return fmt.Errorf("cannot sort %s, item %d is not a scalar", FormatPath(path), i)
+		}
+		start, end, err := d.scalarSpan(child)
+		if err != nil {
+			return fmt.Errorf("cannot sort %s: %w", FormatPath(path), err)
+		}
+		if node.Style&yaml.FlowStyle == 0 {
+			// The lines of the item and of its head comment
+			if i > 0 && node.Content[i-1].Line >= child.Line {
+				return fmt.Errorf("cannot sort %s, item %d is not on a line of its own", FormatPath(path), i)
+			}
+			line := child.Line
+			if child.HeadComment != "" {
+				line -= strings.Count(child.HeadComment, "\n") + 1
+			}
+			start, end = d.lineOffset(line), d.lineOffset(child.Line+1)
+		}
+		items[i] = item{value: child.Value, start: start, end: end}
+	}
+	if len(items) < 2 {
+		return nil
+	}
+
+	sorted := make([]item, len(items))
+	copy(sorted, items)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].value < sorted[j].value })
+	var text bytes.Buffer
+	for i, slot := range items {
+		if i > 0 {
+			text.Write(d.content[items[i-1].end:slot.start])
+		}
+		source := d.content[sorted[i].start:sorted[i].end]
+		// The last line of a file may lack a newline
+		if node.Style&yaml.FlowStyle == 0 && !bytes.HasSuffix(source, []byte("\n")) {
+			source = append(append([]byte{}, source...), '\n')
+		}
+		if node.Style&yaml.FlowStyle == 0 && i == len(items)-1 && !bytes.HasSuffix(d.content[:slot.end], []byte("\n")) {
+			source = bytes.TrimSuffix(source, []byte("\n"))
+		}
+		text.Write(source)
+	}
+	return d.replace(items[0].start, items[len(items)-1].end, text.String())
+}
+
+// find returns the node at path, or nil and the deepest mapping on path if the path
+// does not exist from there on
+func (d *Document) find(path []interface{}) (*yaml.Node, *yaml.Node, error) {
+	node := d.root
+	for i, segment := range path {
+		if node == nil {
+			return nil, nil, nil
+		}
+		if node.Kind == yaml.AliasNode {
+			return nil, nil, fmt.Errorf("cannot edit %s, %s is an alias", FormatPath(path), FormatPath(path[:i]))
+		}
+		switch segment := segment.(type) {
+		case string:
+			if node.Kind != yaml.MappingNode {
+				return nil, nil, fmt.Errorf("%s is not a mapping", FormatPath(path[:i]))
+			}
+			var next *yaml.Node
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == segment {
+					next = node.Content[j+1]
+					break
+				}
+			}
+			if next == nil {
+				return nil, node, nil
+			}
+			node = next
+		case int:
+			if node.Kind != yaml.SequenceNode {
+				return nil, nil, fmt.Errorf("%s is not a sequence", FormatPath(path[:i]))
+			}
+			if segment < 0 || segment >= len(node.Content) {
+				return nil, nil, fmt.Errorf("%s has no item %d", FormatPath(path[:i]), segment)
+			}
+			node = node.Content[segment]
+		default:
+			return nil, nil, fmt.Errorf("invalid path segment %v of type %T", segment, segment)
+		}
+	}
+	if node != nil && node.Kind == yaml.AliasNode {
+		return nil, nil, fmt.Errorf("cannot edit %s, it is an alias", FormatPath(path))
+	}
+	return node, nil, nil
+}
+
+// insert adds the missing keys at the end of path with value to mapping, the deepest
+// existing mapping on path, or to the empty document if mapping is nil
+func (d *Document) insert(mapping *yaml.Node, path []interface{}, value string) error {
+	depth := 0
+	if mapping != nil {
+		depth = pathDepth(d.root, mapping, path)
+	} else if d.root != nil && len(path) > 0 {
+		return fmt.Errorf("cannot add %s", FormatPath(path))
+	}
+	var missing []string
+	for _, segment := range path[depth:] {
+		key, ok := segment.(string)
+		if !ok {
+			return fmt.Errorf("cannot add %s, only mapping entries can be added", FormatPath(path))
+		}
+		missing = append(missing, key)
+	}
+
+	// The new entries are indented like the entries of mapping, nested entries by two
+	// more spaces
+	lines := func(indent int) string {
+		var text strings.Builder
+		for i, key := range missing {
+			text.WriteString(strings.Repeat(" ", indent+2*i) + renderScalar(key, 0, "!!str") + ":")
+			if i == len(missing)-1 {
+				text.WriteString(" " + renderScalar(value, 0, "!!str"))
+			}
+			text.WriteString("\n")
+		}
+		return text.String()
+	}
+
+	switch {
+	case mapping == nil:
+		offset := len(d.content)
+		prefix := ""
+		if offset > 0 && d.content[offset-1] != '\n' {
+			prefix = "\n"
+		}
+		return d.replace(offset, offset, prefix+lines(0))
+	case len(mapping.Content) == 0 && mapping.Style&yaml.FlowStyle != 0:
+		// An empty flow mapping such as 'annotations: {}' becomes a block mapping
+		start, end, err := d.flowSpan(mapping)
+		if err != nil {
+			return err
+		}
+		for start > 0 && d.content[start-1] == ' ' {
+			start--
+		}
+		indent := d.indentation(mapping.Line) + 2
+		return d.replace(start, end, "\n"+strings.TrimSuffix(lines(indent), "\n"))
+	case mapping.Style&yaml.FlowStyle != 0:
+		return fmt.Errorf("cannot add %s to a flow mapping", FormatPath(path))
+	}
+	first := mapping.Content[0]
+	offset := d.lineOffset(endLine(mapping) + 1)
+	prefix := ""
+	if offset > 0 && d.content[offset-1] != '\n' {
+		prefix = "\n"
+	}
+	return d.replace(offset, offset, prefix+lines(d.column(first.Line, first.Column)))
+}
+
+// pathDepth returns the number of segments of path leading from root to mapping
+func pathDepth(root, mapping *yaml.Node, path []interface{}) int {
+	node := root
+	for i, segment := range path {
+		if node == mapping {
+			return i
+		}
+		switch segment := segment.(type) {
+		case string:
+			for j := 0; j+1 < len(node.Content); j += 2 {
+				if node.Content[j].Value == segment {
+					node = node.Content[j+1]
+					break
+				}
+			}
+		case int:
+			node = node.Content[segment]
+		}
+	}
+	return len(path)
+}
+
+// replace replaces the content between the offsets and parses it again
+func (d *Document) replace(start, end int, text string) error {
+	content := make([]byte, 0, len(d.content)-(end-start)+len(text))
+	content = append(content, d.content[:start]...)
+	content = append(content, text...)
+	content = append(content, d.content[end:]...)
+	previous := d.content
+	d.content = content
+	if err := d.parse(); err != nil {
+		d.content = previous
+		d.parse()
+		return fmt.Errorf("edit would make the document invalid: %w", err)
+	}
+	return nil
+}
+
+// lineOffset returns the offset of the start of the 1-based line, or the length of
+// the content after the last line
+func (d *Document) lineOffset(line int) int {
+	offset := 0
+	for current := 1; current < line; current++ {
+		next := bytes.IndexByte(d.content[offset:], '\n')
+		if next < 0 {
+			return len(d.content)
+		}
+		offset += next + 1
+	}
+	return offset
+}
+
+// offset returns the offset of the 1-based line and column, counted in characters
+func (d *Document) offset(line, column int) int {
+	offset := d.lineOffset(line)
+	for i := 1; i < column && offset < len(d.content); i++ {
+		_, size := utf8.DecodeRune(d.content[offset:])
+		offset += size
+	}
+	return offset
+}
+
+// column returns the 0-based column in bytes of the 1-based line and column
+func (d *Document) column(line, column int) int {
+	return d.offset(line, column) - d.lineOffset(line)
+}
+
+// indentation returns the indentation of the key on the 1-based line
+func (d *Document) indentation(line int) int {
+	start := d.lineOffset(line)
+	text := strings.TrimLeft(string(d.content[start:d.lineOffset(line+1)]), " ")
+	indent := d.lineOffset(line+1) - start - len(text)
+	// Keys of list items are indented past the dash
+	if strings.HasPrefix(text, "- ") {
+		indent += 2
+	}
+	return indent
+}
+
+// scalarSpan returns the offsets of the source of a single-line scalar
+func (d *Document) scalarSpan(node *yaml.Node) (int, int, error) {
+	start := d.offset(node.Line, node.Column)
+	rest := d.content[start:]
+	switch node.Style {
+	case yaml.DoubleQuotedStyle:
+		for i := 1; i < len(rest) && rest[i] != '\n'; i++ {
+			if rest[i] == '\\' {
+				i++
+			} else if rest[i] == '"' {
+				return start, start + i + 1, nil
+			}
+		}
+	case yaml.SingleQuotedStyle:
+		for i := 1; i < len(rest) && rest[i] != '\n'; i++ {
+			if rest[i] == '\'' {
+				if i+1 < len(rest) && rest[i+1] == '\'' {
+					i++
+					continue
+				}
+				return start, start + i + 1, nil
+			}
+		}
+	case yaml.LiteralStyle, yaml.FoldedStyle:
+		return 0, 0, errors.New("block scalars cannot be edited")
+	default:
+		// Plain scalars are their value unless they span several lines
+		if bytes.HasPrefix(rest, []byte(node.Value)) {
+			return start, start + len(node.Value), nil
+		}
+	}
+	return 0, 0, errors.New("multi-line scalars cannot be edited")
+}
+
+// flowSpan returns the offsets of the source of an empty flow mapping
+func (d *Document) flowSpan(node *yaml.Node) (int, int, error) {
+	start := d.offset(node.Line, node.Column)
+	end := bytes.IndexByte(d.content[start:], '}')
+	if end < 0 {
+		return 0, 0, errors.New("unterminated flow mapping")
+	}
+	return start, start + end + 1, nil
+}
+
+// endLine returns the last line of node and its content
+func endLine(node *yaml.Node) int {
+	line := node.Line
+	if node.Kind == yaml.ScalarNode && (node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle) {
+		line += strings.Count(strings.TrimRight(node.Value, "\n"), "\n") + 1
+	}
+	for _, child := range node.Content {
+		if end := endLine(child); end > line {
+			line = end
+		}
+	}
+	return line
+}
+
+// renderScalar renders value in style if it is a quoted style. Plain values replacing
+// values of other types than strings, such as booleans, are written as they are,
+// strings are quoted if they would be read as another type otherwise.
+func renderScalar(value string, style yaml.Style, tag string) string {
+	switch {
+	case style == yaml.DoubleQuotedStyle:
+		return strconv.Quote(value)
+	case style == yaml.SingleQuotedStyle:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+	case tag != "!!str" && value != "":
+		return value
+	}
+	rendered, err := yaml.Marshal(value)
+	if err != nil {
+		return strconv.Quote(value)
+	}
+	return strings.TrimSuffix(string(rendered), "\n")
+}
+
+// FormatPath renders a path for messages, e.g. components[0].images
+func FormatPath(path []interface{}) string {
+	var text strings.Builder
+	for _, segment := range path {
+		switch segment := segment.(type) {
+		case int:
+			fmt.Fprintf(&text, "[%d]", segment)
+		default:
+			if text.Len() > 0 {
+				text.WriteString(".")
+			}
+			fmt.Fprint(&text, segment)
+		}
+	}
+	if text.Len() == 0 {
+		return "the document"
+	}
+	return text.String()
+}
+
+// ParsePath parses a path rendered by FormatPath
+func ParsePath(text string) ([]interface{}, error) {
+	var path []interface{}
+	for _, part := range strings.Split(text, ".") {
+		key, rest, _ := strings.Cut(part, "[")
+		if key == "" && (len(path) > 0 || rest == "") {
+			return nil, fmt.Errorf("invalid path %q", text)
+		}
+		if key != "" {
+			path = append(path, key)
+		}
+		for rest != "" {
+			index, next, found := strings.Cut(rest, "]")
+			value, err := strconv.Atoi(index)
+			if !found || err != nil || (next != "" && next[0] != '[') {
+				return nil, fmt.Errorf("invalid path %q", text)
+			}
+			path = append(path, value)
+			rest = strings.TrimPrefix(next, "[")
+		}
+	}
+	return path, nil
+}
@@ -0,0 +1,204 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamledit
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const zarfYaml = `# Podinfo package
+kind: ZarfPackageConfig
+metadata:
+  name: podinfo   # the package name
+  version: "1.0.0"
+components:
+  - name: web
+    required: true
+    # Shown when deploying
+    default: false
+    images: [ghcr.io/stefanprodan/podinfo:6.4.0, 'redis:7']
+    charts:
+      - name: podinfo
+        valuesFiles:
+          - values.yaml
+  - name: config
+    description: |
+      Configuration
+      of podinfo
+    annotations: {}
+`
+
+func TestSet(t *testing.T) {
+	d, err := Parse([]byte(zarfYaml))
+	require.NoError(t, err)
+
+	// Values keep their quoting and the comments around them
+	require.NoError(t, d.Set("1.1.0", "metadata", "version"))
+	require.NoError(t, d.Set("podinfo-app", "metadata", "name"))
+	require.NoError(t, d.Set("ghcr.io/stefanprodan/podinfo:6.4.0@sha256:abc", "components", 0, "images", 0))
+	require.NoError(t, d.Set("redis:7.2", "components", 0, "images", 1))
+	require.NoError(t, d.Set("false", "components", 0, "required"))
+	require.NoError(t, d.Set("1.0", "components", 0, "charts", 0, "name"))
+
+	// Missing keys are added at the end of their mapping
+	require.NoError(t, d.Set("Web frontend", "components", 0, "description"))
+	require.NoError(t, d.Set("Podinfo", "metadata", "annotations", "dev.zarf.title"))
+	require.NoError(t, d.Set("config", "components", 1, "annotations", "owner"))
+
+	assert.Equal(t, `# Podinfo package
+kind: ZarfPackageConfig
+metadata:
+  name: podinfo-app   # the package name
+  version: "1.1.0"
+  annotations:
+    dev.zarf.title: Podinfo
+components:
+  - name: web
+    required: false
+    # Shown when deploying
+    default: false
+    images: [ghcr.io/stefanprodan/podinfo:6.4.0@sha256:abc, 'redis:7.2']
+    charts:
+      - name: "1.0"
+        valuesFiles:
+          - values.yaml
+    description: Web frontend
+  - name: config
+    description: |
+      Configuration
+      of podinfo
+    annotations:
+      owner: config
+`, string(d.Bytes()))
+
+	value, ok := d.Get("components", 0, "description")
+	assert.True(t, ok)
+	assert.Equal(t, "Web frontend", value)
+	images, ok := d.Strings("components", 0, "images")
+	assert.True(t, ok)
+	assert.Equal(t, []string{"ghcr.io/stefanprodan/podinfo:6.4.0@sha256:abc", "redis:7.2"}, images)
+
+	assert.EqualError(t, d.Set("x", "components", 2, "name"), "components has no item 2")
+	assert.EqualError(t, d.Set("x", "components", 0, "charts"), "components[0].charts is not a scalar")
+	assert.EqualError(t, d.Set("x", "components", 1, "description"), "cannot edit components[1].description: block scalars cannot be edited")
+	assert.EqualError(t, d.Set("a\nb", "metadata", "name"), "cannot set metadata.name to a value spanning several lines")
+}
+
+func TestDelete(t *testing.T) {
+	d, err := Parse([]byte(zarfYaml))
+	require.NoError(t, err)
+
+	// The comment above the entry is deleted with it, as are nested entries
+	require.NoError(t, d.Delete("components", 0, "default"))
+	require.NoError(t, d.Delete("components", 0, "charts"))
+	require.NoError(t, d.Delete("components", 1, "description"))
+	require.NoError(t, d.Delete("components", 1, "missing"))
+	assert.Equal(t, `# Podinfo package
+kind: ZarfPackageConfig
+metadata:
+  name: podinfo   # the package name
+  version: "1.0.0"
+components:
+  - name: web
+    required: true
+    images: [ghcr.io/stefanprodan/podinfo:6.4.0, 'redis:7']
+  - name: config
+    annotations: {}
+`, string(d.Bytes()))
+
+	assert.EqualError(t, d.Delete("components", 0, "name"), "cannot delete components[0].name, it starts a list item")
+	assert.EqualError(t, d.Delete("components", 0), "cannot delete components[0], only mapping entries can be deleted")
+}
+
+func TestEmptyDocument(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "zarf.yaml")
+	require.NoError(t, os.WriteFile(path, nil, 0600))
+
+	d, err := ReadFile(path)
+	require.NoError(t, err)
+	require.NoError(t, d.Set("ZarfPackageConfig", "kind"))
+	require.NoError(t, d.WriteFile(path))
+
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ZarfPackageConfig\n", string(content))
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+}
+
+func TestSort(t *testing.T) {
+	d, err := Parse([]byte(`images:
+  - redis:7 # cache
+  # The frontend
+  - ghcr.io/stefanprodan/podinfo:6.4.0
+
+  - busybox:1.36
+flow: [redis:7, 'busybox:1.36']
+nested:
+  - [b, a]
+  - a
+last:
+  - b
+  - a`))
+	require.NoError(t, err)
+
+	require.NoError(t, d.Sort("images"))
+	require.NoError(t, d.Sort("flow"))
+	require.NoError(t, d.Sort("last"))
+	assert.Equal(t, `images:
+  - busybox:1.36
+  # The frontend
+  - ghcr.io/stefanprodan/podinfo:6.4.0
+
+  - redis:7 # cache
+flow: ['busybox:1.36', redis:7]
+nested:
+  - [b, a]
+  - a
+last:
+  - a
+  - b`, string(d.Bytes()))
+
+	assert.EqualError(t, d.Sort("nested"), "cannot sort nested, item 0 is not a scalar")
+	assert.EqualError(t, d.Sort("flow", 0), "flow[0] is not a sequence")
+}
+
+func TestPosition(t *testing.T) {
+	d, err := Parse([]byte(zarfYaml))
+	require.NoError(t, err)
+
+	line, column, ok := d.Position("components", 0, "images", 1)
+	assert.True(t, ok)
+	assert.Equal(t, []int{11, 50}, []int{line, column})
+	_, _, ok = d.Position("components", 0, "missing")
+	assert.False(t, ok)
+
+	path, err := ParsePath(FormatPath([]interface{}{"components", 0, "images", 1}))
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"components", 0, "images", 1}, path)
+	path, err = ParsePath("metadata.annotations[2][0]")
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{"metadata", "annotations", 2, 0}, path)
+	for _, invalid := range []string{"components[x]", "components[0", "components..name", "components[0]x"} {
+		_, err = ParsePath(invalid)
+		assert.EqualError(t, err, "invalid path \""+invalid+"\"", invalid)
+	}
+}
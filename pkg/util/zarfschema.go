@@ -0,0 +1,121 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+// yamlSchema describes the keys allowed in a YAML value. A nil schema allows any value.
+type yamlSchema struct {
+	fields map[string]*yamlSchema // keys of a mapping and the schemas of their values
+	items  *yamlSchema            // schema of the items of a list or the values of a map
+}
+
+func object(fields map[string]*yamlSchema) *yamlSchema {
+	return &yamlSchema{fields: fields}
+}
+
+func listOf(items *yamlSchema) *yamlSchema {
+	return &yamlSchema{items: items}
+}
+
+// zarfActionWait, zarfAction and zarfActionSet are shared by the onCreate, onDeploy
+// and onRemove actions
+var (
+	zarfActionWait = object(map[string]*yamlSchema{
+		"cluster": object(map[string]*yamlSchema{"kind": nil, "name": nil, "namespace": nil, "condition": nil}),
+		"network": object(map[string]*yamlSchema{"protocol": nil, "address": nil, "code": nil}),
+	})
+	zarfAction = object(map[string]*yamlSchema{
+		"mute": nil, "maxTotalSeconds": nil, "maxRetries": nil, "dir": nil, "env": nil, "cmd": nil,
+		"shell": nil, "setVariable": nil, "description": nil, "wait": zarfActionWait,
+		"setVariables": listOf(object(map[string]*yamlSchema{
+			"name": nil, "sensitive": nil, "autoIndent": nil, "pattern": nil, "type": nil,
+		})),
+	})
+	zarfActionSet = object(map[string]*yamlSchema{
+		"defaults": object(map[string]*yamlSchema{
+			"mute": nil, "maxTotalSeconds": nil, "maxRetries": nil, "dir": nil, "env": nil, "shell": nil,
+		}),
+		"before":    listOf(zarfAction),
+		"after":     listOf(zarfAction),
+		"onSuccess": listOf(zarfAction),
+		"onFailure": listOf(zarfAction),
+	})
+)
+
+// zarfSchema are the keys of the Zarf package schema (v1alpha1). It is kept apart
+// from ZarfYaml, which only models the keys zt reads, so that strict mode does not
+// flag valid fields. metadata.deprecated and components[].depsWith are read by zt in
+// addition to the schema, keys starting with 'x-' are extensions and always allowed.
+var zarfSchema = object(map[string]*yamlSchema{
+	"kind": nil,
+	"metadata": object(map[string]*yamlSchema{
+		"name": nil, "description": nil, "version": nil, "url": nil, "image": nil,
+		"uncompressed": nil, "architecture": nil, "yolo": nil, "authors": nil,
+		"documentation": nil, "source": nil, "vendor": nil, "aggregateChecksum": nil,
+		"annotations": nil, "allowNamespaceOverride": nil, "deprecated": nil,
+	}),
+	"build": object(map[string]*yamlSchema{
+		"terminal": nil, "user": nil, "architecture": nil, "timestamp": nil, "version": nil,
+		"migrations": nil, "registryOverrides": nil, "differential": nil,
+		"differentialPackageVersion": nil, "differentialMissing": nil,
+		"lastNonBreakingVersion": nil, "flavor": nil, "signed": nil, "versionRequirements": nil,
+	}),
+	"variables": listOf(object(map[string]*yamlSchema{
+		"name": nil, "description": nil, "default": nil, "prompt": nil, "sensitive": nil,
+		"autoIndent": nil, "pattern": nil, "type": nil,
+	})),
+	"constants": listOf(object(map[string]*yamlSchema{
+		"name": nil, "value": nil, "description": nil, "autoIndent": nil, "pattern": nil,
+	})),
+	"components": listOf(object(map[string]*yamlSchema{
+		"name": nil, "description": nil, "default": nil, "required": nil, "group": nil,
+		"cosignKeyPath": nil, "images": nil, "repos": nil, "extensions": nil, "depsWith": nil,
+		"only": object(map[string]*yamlSchema{
+			"localOS": nil,
+			"cluster": object(map[string]*yamlSchema{"architecture": nil, "distros": nil}),
+			"flavor":  nil,
+		}),
+		"import": object(map[string]*yamlSchema{"name": nil, "path": nil, "url": nil}),
+		"manifests": listOf(object(map[string]*yamlSchema{
+			"name": nil, "namespace": nil, "files": nil, "kustomizeAllowAnyOf": nil,
+			"kustomizations": nil, "noWait": nil, "serverSideApply": nil, "enableKustomizePlugins": nil,
+		})),
+		"charts": listOf(object(map[string]*yamlSchema{
+			"name": nil, "version": nil, "url": nil, "ociUrl": nil, "repoName": nil, "gitPath": nil,
+			"localPath": nil, "namespace": nil, "releaseName": nil, "noWait": nil,
+			"valuesFiles": nil, "schemaValidation": nil, "serverSideApply": nil,
+			"variables": listOf(object(map[string]*yamlSchema{"name": nil, "description": nil, "path": nil})),
+		})),
+		"dataInjections": listOf(object(map[string]*yamlSchema{
+			"source":   nil,
+			"target":   object(map[string]*yamlSchema{"namespace": nil, "selector": nil, "container": nil, "path": nil}),
+			"compress": nil,
+		})),
+		"files": listOf(object(map[string]*yamlSchema{
+			"source": nil, "shasum": nil, "target": nil, "executable": nil, "symlinks": nil,
+			"extractPath": nil, "template": nil,
+		})),
+		"scripts": object(map[string]*yamlSchema{
+			"showOutput": nil, "timeoutSeconds": nil, "retry": nil, "prepare": nil, "before": nil, "after": nil,
+		}),
+		"actions": object(map[string]*yamlSchema{
+			"onCreate": zarfActionSet,
+			"onDeploy": zarfActionSet,
+			"onRemove": zarfActionSet,
+		}),
+		"healthChecks": listOf(object(map[string]*yamlSchema{
+			"apiVersion": nil, "kind": nil, "name": nil, "namespace": nil,
+		})),
+	})),
+})
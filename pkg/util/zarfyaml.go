@@ -0,0 +1,192 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Kinds of problems of a zarf.yaml that do not prevent parsing it, also the rule IDs
+// they are reported under by lint
+const (
+	YamlDuplicateKey      = "duplicate-key"
+	YamlUnknownField      = "unknown-field"
+	YamlMultipleDocuments = "multiple-documents"
+)
+
+// YamlProblem is a problem of a zarf.yaml that does not prevent parsing it
+type YamlProblem struct {
+	Kind    string
+	Line    int // 1-based line of the problem
+	Message string
+}
+
+// ParseZarfYaml parses a zarf.yaml with yaml.v3, which resolves anchors, aliases and
+// merge keys. Keys the package definition does not model are kept in the Extra fields
+// of its structs so that it can be written back without losing them. Keys defined
+// more than once, the first definition of which is used, and documents after the
+// first one, which are ignored, are returned as problems. In strict mode, every key
+// the Zarf package schema does not define is a problem as well. An error is only
+// returned if the file cannot be parsed at all.
+func ParseZarfYaml(yamlBytes []byte, strict bool) (*ZarfYaml, []YamlProblem, error) {
+	zarfYaml, _, problems, err := ParseZarfYamlNode(yamlBytes, strict)
+	return zarfYaml, problems, err
+}
+
+// ParseZarfYamlNode is ParseZarfYaml that also returns the document node the package
+// definition was decoded from, so that the positions of its fields can be looked up.
+// The node is nil for an empty file.
+func ParseZarfYamlNode(yamlBytes []byte, strict bool) (*ZarfYaml, *yaml.Node, []YamlProblem, error) {
+	zarfYaml := &ZarfYaml{}
+	decoder := yaml.NewDecoder(bytes.NewReader(yamlBytes))
+	var document yaml.Node
+	if err := decoder.Decode(&document); err != nil {
+		if errors.Is(err, io.EOF) {
+			return zarfYaml, nil, nil, nil
+		}
+		return nil, nil, nil, fmt.Errorf("could not unmarshal 'zarf.yaml': %w", err)
+	}
+
+	problems := checkYamlNode(&document, zarfSchema, "", strict)
+	for {
+		var next yaml.Node
+		err := decoder.Decode(&next)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("could not unmarshal 'zarf.yaml': %w", err)
+		}
+		// A trailing separator starts an empty document
+		if len(next.Content) > 0 && next.Content[0].Tag != "!!null" {
+			problems = append(problems, YamlProblem{YamlMultipleDocuments, next.Line,
+				"zarf.yaml contains more than one document, only the first one is used"})
+			break
+		}
+	}
+
+	if err := document.Decode(zarfYaml); err != nil {
+		return nil, nil, nil, fmt.Errorf("could not unmarshal 'zarf.yaml': %w", err)
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Line < problems[j].Line })
+	return zarfYaml, &document, problems, nil
+}
+
+// checkYamlNode returns the keys defined more than once in the mappings of node and,
+// in strict mode, the keys that schema does not allow. Keys starting with 'x-' are
+// extensions and never flagged. path names the location of node in messages. Keys
+// defined more than once are removed from node, as yaml.v3 does not decode mappings
+// with duplicate keys.
+func checkYamlNode(node *yaml.Node, schema *yamlSchema, path string, strict bool) []YamlProblem {
+	for node.Kind == yaml.DocumentNode || node.Kind == yaml.AliasNode {
+		if node.Kind == yaml.AliasNode {
+			node = node.Alias
+		} else if len(node.Content) > 0 {
+			node = node.Content[0]
+		} else {
+			return nil
+		}
+	}
+
+	var problems []YamlProblem
+	switch node.Kind {
+	case yaml.SequenceNode:
+		var items *yamlSchema
+		if schema != nil {
+			items = schema.items
+		}
+		for i, item := range node.Content {
+			problems = append(problems, checkYamlNode(item, items, fmt.Sprintf("%s[%s]", path, itemName(item, i)), strict)...)
+		}
+	case yaml.MappingNode:
+		location := ""
+		if path != "" {
+			location = " in " + path
+		}
+		defined := map[string]int{}
+		content := node.Content[:0]
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if line, ok := defined[key.Value]; ok {
+				problems = append(problems, YamlProblem{YamlDuplicateKey, key.Line,
+					fmt.Sprintf("Key '%s'%s is defined more than once, first at line %d", key.Value, location, line)})
+				continue
+			}
+			defined[key.Value] = key.Line
+			content = append(content, key, value)
+			if key.Value == "<<" && key.Tag == "!!merge" {
+				problems = append(problems, checkMergedNodes(value, schema, path, strict)...)
+				continue
+			}
+
+			// Values without a schema are only checked for duplicate keys
+			var child *yamlSchema
+			switch {
+			case schema == nil:
+			case schema.fields == nil:
+				child = schema.items
+			default:
+				field, ok := schema.fields[key.Value]
+				if !ok && strict && !strings.HasPrefix(key.Value, "x-") {
+					problems = append(problems, YamlProblem{YamlUnknownField, key.Line,
+						fmt.Sprintf("Unknown field '%s'%s", key.Value, location)})
+				}
+				child = field
+			}
+			childPath := key.Value
+			if path != "" {
+				childPath = path + "." + key.Value
+			}
+			problems = append(problems, checkYamlNode(value, child, childPath, strict)...)
+		}
+		node.Content = content
+	}
+	return problems
+}
+
+// checkMergedNodes checks the mappings merged into a mapping with '<<'
+func checkMergedNodes(value *yaml.Node, schema *yamlSchema, path string, strict bool) []YamlProblem {
+	if value.Kind != yaml.SequenceNode {
+		return checkYamlNode(value, schema, path, strict)
+	}
+	var problems []YamlProblem
+	for _, item := range value.Content {
+		problems = append(problems, checkYamlNode(item, schema, path, strict)...)
+	}
+	return problems
+}
+
+// itemName names a list item in messages by its name key, or by its index
+func itemName(item *yaml.Node, index int) string {
+	if item.Kind == yaml.AliasNode {
+		item = item.Alias
+	}
+	if item.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(item.Content); i += 2 {
+			if item.Content[i].Value == "name" && item.Content[i+1].Kind == yaml.ScalarNode {
+				return item.Content[i+1].Value
+			}
+		}
+	}
+	return strconv.Itoa(index)
+}
@@ -0,0 +1,118 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestParseZarfYaml(t *testing.T) {
+	content := `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+  url: https://example.test
+  authors: Podinfo Authors
+x-defaults: &defaults
+  required: true
+  images: [ghcr.io/stefanprodan/podinfo:6.4.0]
+variables:
+  - name: PASSWORD
+    sensitive: true
+    pattern: ^.{12,}$
+components:
+  - <<: *defaults
+    name: web
+    charts:
+      - name: podinfo
+        namespace: podinfo
+        ociUrl: oci://ghcr.io/stefanprodan/charts/podinfo
+        valuesFile: values.yaml
+    healthChecks:
+      - apiVersion: apps/v1
+        kind: Deployment
+        name: podinfo
+        namespace: podinfo
+  - name: redis
+    name: cache
+    onlyy: {}
+    x-zt:
+      owner: data
+build:
+  architecture: amd64
+  labels: {a: 1, a: 2}
+`
+	zarfYaml, problems, err := ParseZarfYaml([]byte(content), true)
+	require.NoError(t, err)
+
+	// Merge keys are resolved and the first of duplicate keys is used
+	require.Len(t, zarfYaml.Components, 2)
+	assert.True(t, zarfYaml.Components[0].Required)
+	assert.Equal(t, []string{"ghcr.io/stefanprodan/podinfo:6.4.0"}, zarfYaml.Components[0].Images)
+	assert.Equal(t, "redis", zarfYaml.Components[1].Name)
+
+	// Unknown keys are kept for writing the definition back
+	assert.Equal(t, "https://example.test", zarfYaml.Metadata.Extra["url"])
+	assert.Equal(t, map[string]interface{}{"sensitive": true, "pattern": "^.{12,}$"}, zarfYaml.Variables[0].Extra)
+	assert.Equal(t, map[string]interface{}{"ociUrl": "oci://ghcr.io/stefanprodan/charts/podinfo", "valuesFile": "values.yaml"},
+		zarfYaml.Components[0].Charts[0].Extra)
+	written, err := yaml.Marshal(zarfYaml)
+	require.NoError(t, err)
+	assert.Contains(t, string(written), "valuesFile: values.yaml")
+	assert.Contains(t, string(written), "sensitive: true")
+
+	// Fields of the Zarf package schema and 'x-' extensions are not flagged
+	assert.Equal(t, []YamlProblem{
+		{YamlUnknownField, 20, "Unknown field 'valuesFile' in components[web].charts[podinfo]"},
+		{YamlDuplicateKey, 27, "Key 'name' in components[redis] is defined more than once, first at line 26"},
+		{YamlUnknownField, 28, "Unknown field 'onlyy' in components[redis]"},
+		{YamlUnknownField, 33, "Unknown field 'labels' in build"},
+		{YamlDuplicateKey, 33, "Key 'a' in build.labels is defined more than once, first at line 33"},
+	}, problems)
+
+	// Outside of strict mode, only duplicate keys are problems
+	_, problems, err = ParseZarfYaml([]byte(content), false)
+	require.NoError(t, err)
+	assert.Equal(t, []YamlProblem{
+		{YamlDuplicateKey, 27, "Key 'name' in components[redis] is defined more than once, first at line 26"},
+		{YamlDuplicateKey, 33, "Key 'a' in build.labels is defined more than once, first at line 33"},
+	}, problems)
+	_, err = UnmarshalZarfYaml([]byte(content))
+	assert.EqualError(t, err, "could not unmarshal 'zarf.yaml': line 27: Key 'name' in components[redis] is defined more than once, first at line 26")
+}
+
+func TestParseZarfYamlDocuments(t *testing.T) {
+	// A leading and a trailing separator do not start further documents
+	zarfYaml, problems, err := ParseZarfYaml([]byte("---\nkind: ZarfPackageConfig\n---\n"), false)
+	require.NoError(t, err)
+	assert.Equal(t, "ZarfPackageConfig", zarfYaml.Kind)
+	assert.Empty(t, problems)
+
+	zarfYaml, problems, err = ParseZarfYaml([]byte("kind: ZarfPackageConfig\n---\nkind: ZarfInitConfig\n"), false)
+	require.NoError(t, err)
+	assert.Equal(t, "ZarfPackageConfig", zarfYaml.Kind)
+	assert.Equal(t, []YamlProblem{{YamlMultipleDocuments, 2, "zarf.yaml contains more than one document, only the first one is used"}}, problems)
+
+	_, _, err = ParseZarfYaml([]byte("kind: ZarfPackageConfig\ncomponents:\n  - name: web\n    required: maybe\n"), false)
+	assert.ErrorContains(t, err, "could not unmarshal 'zarf.yaml': yaml: unmarshal errors:\n  line 4: cannot unmarshal !!str `maybe` into bool")
+
+	zarfYaml, problems, err = ParseZarfYaml(nil, true)
+	require.NoError(t, err)
+	assert.Equal(t, &ZarfYaml{}, zarfYaml)
+	assert.Empty(t, problems)
+}
@@ -0,0 +1,188 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamllint
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	LevelError   = "error"
+	LevelWarning = "warning"
+)
+
+// RuleConfig holds the options of a single rule as found in a yamllint config file
+type RuleConfig struct {
+	Enabled bool
+	Level   string
+	Options map[string]interface{}
+}
+
+// Config is a yamllint-compatible configuration. Only the rules implemented by this
+// package are evaluated, other rules are accepted and ignored.
+type Config struct {
+	Rules map[string]*RuleConfig
+}
+
+type rawConfig struct {
+	Extends string                 `yaml:"extends"`
+	Rules   map[string]interface{} `yaml:"rules"`
+}
+
+// DefaultConfig returns the rules applied without a lint config: yamllint's
+// 'relaxed' preset with every problem reported as a warning, so formatting alone
+// never fails a package
+func DefaultConfig() *Config {
+	cfg := relaxedPreset()
+	for _, rule := range cfg.Rules {
+		rule.Level = LevelWarning
+	}
+	return cfg
+}
+
+// defaultPreset returns the equivalent of yamllint's 'default' preset for the
+// implemented rules
+func defaultPreset() *Config {
+	return &Config{
+		Rules: map[string]*RuleConfig{
+			"line-length": {Enabled: true, Level: LevelError, Options: map[string]interface{}{
+				"max": 80, "allow-non-breakable-words": true,
+			}},
+			"indentation": {Enabled: true, Level: LevelError, Options: map[string]interface{}{
+				"spaces": "consistent", "indent-sequences": true,
+			}},
+			"trailing-spaces": {Enabled: true, Level: LevelError, Options: map[string]interface{}{}},
+			"truthy": {Enabled: true, Level: LevelWarning, Options: map[string]interface{}{
+				"allowed-values": []interface{}{"true", "false"}, "check-keys": true,
+			}},
+			"new-line-at-end-of-file": {Enabled: true, Level: LevelError, Options: map[string]interface{}{}},
+			"empty-lines": {Enabled: true, Level: LevelError, Options: map[string]interface{}{
+				"max": 2, "max-start": 0, "max-end": 0,
+			}},
+		},
+	}
+}
+
+// relaxedPreset returns the equivalent of yamllint's 'relaxed' preset for the
+// implemented rules
+func relaxedPreset() *Config {
+	cfg := defaultPreset()
+	cfg.Rules["line-length"].Level = LevelWarning
+	cfg.Rules["indentation"].Level = LevelWarning
+	cfg.Rules["trailing-spaces"].Level = LevelWarning
+	cfg.Rules["empty-lines"].Level = LevelWarning
+	cfg.Rules["truthy"].Enabled = false
+	return cfg
+}
+
+// LoadConfig reads a yamllint configuration file such as 'lintconf.yaml'
+func LoadConfig(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read lint config: %w", err)
+	}
+	return ParseConfig(content)
+}
+
+// ParseConfig parses a yamllint configuration. Rules are merged on top of the preset
+// named by 'extends'; without 'extends' only the listed rules are enabled.
+func ParseConfig(content []byte) (*Config, error) {
+	raw := rawConfig{}
+	if err := yaml.Unmarshal(content, &raw); err != nil {
+		return nil, fmt.Errorf("could not unmarshal lint config: %w", err)
+	}
+
+	cfg := &Config{Rules: map[string]*RuleConfig{}}
+	defaults := defaultPreset()
+	switch raw.Extends {
+	case "":
+	case "default":
+		cfg = defaultPreset()
+	case "relaxed":
+		cfg = relaxedPreset()
+	default:
+		return nil, fmt.Errorf("unsupported lint config preset %q", raw.Extends)
+	}
+
+	for name, value := range raw.Rules {
+		rule := &RuleConfig{Enabled: true, Level: LevelError, Options: map[string]interface{}{}}
+		if preset, ok := defaults.Rules[name]; ok {
+			for k, v := range preset.Options {
+				rule.Options[k] = v
+			}
+			rule.Level = preset.Level
+		}
+
+		switch v := value.(type) {
+		case string:
+			switch v {
+			case "enable":
+			case "disable":
+				rule.Enabled = false
+			default:
+				return nil, fmt.Errorf("invalid value %q for rule %s", v, name)
+			}
+		case map[interface{}]interface{}:
+			for key, option := range v {
+				optionName := fmt.Sprintf("%v", key)
+				if optionName == "level" {
+					level := fmt.Sprintf("%v", option)
+					if level != LevelError && level != LevelWarning {
+						return nil, fmt.Errorf("invalid level %q for rule %s", level, name)
+					}
+					rule.Level = level
+					continue
+				}
+				rule.Options[optionName] = option
+			}
+		case nil:
+		default:
+			return nil, fmt.Errorf("invalid configuration for rule %s", name)
+		}
+
+		cfg.Rules[name] = rule
+	}
+
+	return cfg, nil
+}
+
+// rule returns the configuration of an enabled rule, or nil
+func (c *Config) rule(name string) *RuleConfig {
+	if c == nil {
+		return nil
+	}
+	rule, ok := c.Rules[name]
+	if !ok || !rule.Enabled {
+		return nil
+	}
+	return rule
+}
+
+func (r *RuleConfig) intOption(name string, fallback int) int {
+	if value, ok := r.Options[name].(int); ok {
+		return value
+	}
+	return fallback
+}
+
+func (r *RuleConfig) boolOption(name string, fallback bool) bool {
+	if value, ok := r.Options[name].(bool); ok {
+		return value
+	}
+	return fallback
+}
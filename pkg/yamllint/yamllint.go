@@ -0,0 +1,431 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package yamllint implements a subset of the yamllint rules (line-length, indentation,
+// trailing-spaces, truthy, new-line-at-end-of-file and empty-lines) so that YAML files
+// can be linted without a Python installation.
+package yamllint
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	truthyValues = map[string]bool{
+		"YES": true, "Yes": true, "yes": true, "NO": true, "No": true, "no": true,
+		"TRUE": true, "True": true, "true": true, "FALSE": true, "False": true, "false": true,
+		"ON": true, "On": true, "on": true, "OFF": true, "Off": true, "off": true,
+	}
+
+	blockScalarPattern = regexp.MustCompile(`^[|>][0-9+-]*$`)
+)
+
+// Problem is a single finding reported by the linter
+type Problem struct {
+	Line    int
+	Column  int
+	Level   string
+	Rule    string
+	Message string
+}
+
+func (p Problem) String() string {
+	return fmt.Sprintf("%d:%d [%s] %s (%s)", p.Line, p.Column, p.Level, p.Message, p.Rule)
+}
+
+// LintFile lints the YAML file at path with the given configuration
+func LintFile(path string, cfg *Config) ([]Problem, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read %s: %w", path, err)
+	}
+	return Lint(content, cfg), nil
+}
+
+// Lint checks content against the enabled rules of cfg and returns the problems
+// ordered by position
+func Lint(content []byte, cfg *Config) []Problem {
+	l := &linter{cfg: cfg}
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	if strings.HasSuffix(text, "\n") {
+		lines = lines[:len(lines)-1]
+	}
+
+	l.checkTrailingSpaces(lines)
+	l.checkLineLength(lines)
+	l.checkEmptyLines(lines)
+	l.checkNewLineAtEndOfFile(text, lines)
+	l.checkStructure(lines)
+
+	sort.SliceStable(l.problems, func(i, j int) bool {
+		if l.problems[i].Line != l.problems[j].Line {
+			return l.problems[i].Line < l.problems[j].Line
+		}
+		return l.problems[i].Column < l.problems[j].Column
+	})
+	return l.problems
+}
+
+type linter struct {
+	cfg      *Config
+	problems []Problem
+}
+
+func (l *linter) report(rule *RuleConfig, name string, line, column int, msg string, args ...interface{}) {
+	l.problems = append(l.problems, Problem{
+		Line:    line,
+		Column:  column,
+		Level:   rule.Level,
+		Rule:    name,
+		Message: fmt.Sprintf(msg, args...),
+	})
+}
+
+func (l *linter) checkTrailingSpaces(lines []string) {
+	rule := l.cfg.rule("trailing-spaces")
+	if rule == nil {
+		return
+	}
+	for i, line := range lines {
+		trimmed := strings.TrimRight(line, " \t")
+		if trimmed != line {
+			l.report(rule, "trailing-spaces", i+1, utf8.RuneCountInString(trimmed)+1, "trailing spaces")
+		}
+	}
+}
+
+func (l *linter) checkLineLength(lines []string) {
+	rule := l.cfg.rule("line-length")
+	if rule == nil {
+		return
+	}
+	maxLength := rule.intOption("max", 80)
+	allowNonBreakable := rule.boolOption("allow-non-breakable-words", true)
+
+	for i, line := range lines {
+		length := utf8.RuneCountInString(line)
+		if length <= maxLength {
+			continue
+		}
+		if allowNonBreakable {
+			word := strings.TrimSpace(line)
+			word = strings.TrimLeft(word, "-# ")
+			if !strings.ContainsAny(word, " \t") {
+				continue
+			}
+		}
+		l.report(rule, "line-length", i+1, maxLength+1, "line too long (%d > %d characters)", length, maxLength)
+	}
+}
+
+func (l *linter) checkEmptyLines(lines []string) {
+	rule := l.cfg.rule("empty-lines")
+	if rule == nil {
+		return
+	}
+	maxBlank := rule.intOption("max", 2)
+	maxStart := rule.intOption("max-start", 0)
+	maxEnd := rule.intOption("max-end", 0)
+
+	blank := 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			blank++
+			continue
+		}
+		if blank > 0 {
+			if blank == i && blank > maxStart {
+				l.report(rule, "empty-lines", i, 1, "too many blank lines (%d > %d)", blank, maxStart)
+			} else if blank != i && blank > maxBlank {
+				l.report(rule, "empty-lines", i, 1, "too many blank lines (%d > %d)", blank, maxBlank)
+			}
+		}
+		blank = 0
+	}
+	if blank > 0 && blank < len(lines) && blank > maxEnd {
+		l.report(rule, "empty-lines", len(lines), 1, "too many blank lines (%d > %d)", blank, maxEnd)
+	}
+}
+
+func (l *linter) checkNewLineAtEndOfFile(text string, lines []string) {
+	rule := l.cfg.rule("new-line-at-end-of-file")
+	if rule == nil || text == "" || strings.HasSuffix(text, "\n") {
+		return
+	}
+	last := lines[len(lines)-1]
+	l.report(rule, "new-line-at-end-of-file", len(lines), utf8.RuneCountInString(last)+1,
+		"no new line character at the end of file")
+}
+
+// checkStructure runs the rules that need to understand the block structure of the
+// document: indentation and truthy
+func (l *linter) checkStructure(lines []string) {
+	indentRule := l.cfg.rule("indentation")
+	truthyRule := l.cfg.rule("truthy")
+	if indentRule == nil && truthyRule == nil {
+		return
+	}
+
+	spaces := 0
+	consistent := true
+	indentSequences := "true"
+	if indentRule != nil {
+		if value, ok := indentRule.Options["spaces"].(int); ok {
+			spaces = value
+			consistent = false
+		}
+		if value, ok := indentRule.Options["indent-sequences"]; ok {
+			indentSequences = fmt.Sprintf("%v", value)
+		}
+	}
+
+	stack := []int{0}
+	inBlockScalar := false
+	blockParent := 0
+	opensBlock := false
+	opensAt := 0
+
+	for i, raw := range lines {
+		lineNo := i + 1
+		trimmed := strings.TrimSpace(raw)
+		if trimmed == "" {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " "))
+
+		if inBlockScalar {
+			if indent > blockParent {
+				continue
+			}
+			inBlockScalar = false
+		}
+
+		if strings.HasPrefix(trimmed, "#") || strings.HasPrefix(trimmed, "%") {
+			continue
+		}
+		if trimmed == "---" || trimmed == "..." || strings.HasPrefix(trimmed, "--- ") {
+			stack = []int{0}
+			opensBlock = false
+			continue
+		}
+
+		isSequence := trimmed == "-" || strings.HasPrefix(trimmed, "- ")
+		top := stack[len(stack)-1]
+
+		if indent > top && !opensBlock {
+			// Continuation of a multi-line scalar or flow collection
+			continue
+		}
+
+		if indentRule != nil {
+			if indent > top {
+				delta := indent - top
+				expected := spaces
+				if consistent {
+					if spaces == 0 {
+						spaces = delta
+					}
+					expected = spaces
+				}
+				if delta != expected {
+					l.report(indentRule, "indentation", lineNo, indent+1,
+						"wrong indentation: expected %d but found %d", top+expected, indent)
+				} else if isSequence && indentSequences == "false" && opensAt == top {
+					l.report(indentRule, "indentation", lineNo, indent+1,
+						"wrong indentation: expected %d but found %d", top, indent)
+				}
+			} else {
+				for len(stack) > 1 && stack[len(stack)-1] > indent {
+					stack = stack[:len(stack)-1]
+				}
+				top = stack[len(stack)-1]
+				if top != indent {
+					l.report(indentRule, "indentation", lineNo, indent+1,
+						"wrong indentation: expected %d but found %d", top, indent)
+				} else if isSequence && opensBlock && opensAt == indent && indentSequences == "true" {
+					expected := spaces
+					if expected == 0 {
+						expected = 2
+					}
+					l.report(indentRule, "indentation", lineNo, indent+1,
+						"wrong indentation: expected %d but found %d", indent+expected, indent)
+				}
+			}
+		} else {
+			for len(stack) > 1 && stack[len(stack)-1] > indent {
+				stack = stack[:len(stack)-1]
+			}
+		}
+		if stack[len(stack)-1] != indent {
+			stack = append(stack, indent)
+		}
+
+		// Step over sequence indicators to find the node content
+		column := indent
+		rest := trimmed
+		for rest == "-" || strings.HasPrefix(rest, "- ") {
+			after := strings.TrimLeft(rest[1:], " ")
+			column += len(rest) - len(after)
+			rest = after
+			if rest != "" {
+				stack = append(stack, column)
+			}
+		}
+		rest = stripComment(rest)
+
+		if truthyRule != nil {
+			l.checkTruthy(truthyRule, lineNo, column, rest)
+		}
+
+		value := rest
+		keyEnd := findMappingColon(rest)
+		if keyEnd >= 0 {
+			value = strings.TrimSpace(rest[keyEnd+1:])
+		}
+
+		opensBlock = rest == "" || (keyEnd >= 0 && (value == "" || isNodeProperty(value)))
+		opensAt = column
+		if blockScalarPattern.MatchString(value) || (keyEnd < 0 && blockScalarPattern.MatchString(rest)) {
+			inBlockScalar = true
+			blockParent = column
+			if keyEnd < 0 {
+				blockParent = indent
+			}
+			opensBlock = false
+		}
+	}
+}
+
+// checkTruthy reports plain scalars that YAML 1.1 would interpret as booleans but are
+// not in the allowed values
+func (l *linter) checkTruthy(rule *RuleConfig, lineNo, column int, content string) {
+	allowed := map[string]bool{}
+	if values, ok := rule.Options["allowed-values"].([]interface{}); ok {
+		for _, value := range values {
+			allowed[fmt.Sprintf("%v", value)] = true
+		}
+	} else {
+		allowed["true"], allowed["false"] = true, true
+	}
+	checkKeys := rule.boolOption("check-keys", true)
+
+	check := func(token string, offset int) {
+		if truthyValues[token] && !allowed[token] {
+			l.report(rule, "truthy", lineNo, column+offset+1,
+				"truthy value should be one of [%s]", strings.Join(sortedKeys(allowed), ", "))
+		}
+	}
+
+	keyEnd := findMappingColon(content)
+	if keyEnd < 0 {
+		check(strings.TrimSpace(content), 0)
+		return
+	}
+
+	if checkKeys {
+		check(strings.TrimSpace(content[:keyEnd]), 0)
+	}
+
+	valueStart := keyEnd + 1
+	for valueStart < len(content) && content[valueStart] == ' ' {
+		valueStart++
+	}
+	value := strings.TrimSpace(content[valueStart:])
+	if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+		offset := valueStart + 1
+		for _, item := range strings.Split(value[1:len(value)-1], ",") {
+			leading := len(item) - len(strings.TrimLeft(item, " "))
+			check(strings.TrimSpace(item), offset+leading)
+			offset += len(item) + 1
+		}
+		return
+	}
+	check(value, valueStart)
+}
+
+// findMappingColon returns the index of the colon separating a mapping key from its
+// value, ignoring colons inside quotes and flow collections, or -1
+func findMappingColon(s string) int {
+	var quote byte
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || s[i-1] == ' ' || s[i-1] == '[' || s[i-1] == '{' || s[i-1] == ',' {
+				quote = c
+			}
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == ':' && depth == 0:
+			if i == len(s)-1 || s[i+1] == ' ' {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// stripComment removes a trailing comment that is not part of a quoted scalar
+func stripComment(s string) string {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			if i == 0 || s[i-1] == ' ' {
+				quote = c
+			}
+		case c == '#' && (i == 0 || s[i-1] == ' '):
+			return strings.TrimSpace(s[:i])
+		}
+	}
+	return strings.TrimSpace(s)
+}
+
+// isNodeProperty reports whether a value consists only of an anchor or tag, in which
+// case the node content follows on the next lines
+func isNodeProperty(value string) bool {
+	for _, field := range strings.Fields(value) {
+		if !strings.HasPrefix(field, "&") && !strings.HasPrefix(field, "!") {
+			return false
+		}
+	}
+	return true
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
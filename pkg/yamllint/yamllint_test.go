@@ -0,0 +1,126 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package yamllint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func problemStrings(problems []Problem) []string {
+	var result []string
+	for _, p := range problems {
+		result = append(result, p.String())
+	}
+	return result
+}
+
+func TestLint(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []string
+	}{
+		{
+			name: "clean zarf.yaml",
+			content: `kind: ZarfPackageConfig
+metadata:
+  name: test
+components:
+  - name: app
+    required: true
+    images:
+      - nginx:1.25
+    actions:
+      onDeploy:
+        after:
+          - cmd: |
+              echo "yes"
+                echo no
+`,
+		},
+		{
+			name:     "trailing spaces and missing newline",
+			content:  "kind: ZarfPackageConfig  \nmetadata: {}",
+			expected: []string{"1:24 [error] trailing spaces (trailing-spaces)", "2:13 [error] no new line character at the end of file (new-line-at-end-of-file)"},
+		},
+		{
+			name: "inconsistent indentation",
+			content: `metadata:
+  name: test
+components:
+    - name: app
+`,
+			expected: []string{"4:5 [error] wrong indentation: expected 2 but found 4 (indentation)"},
+		},
+		{
+			name: "non indented sequence",
+			content: `components:
+- name: app
+`,
+			expected: []string{"2:1 [error] wrong indentation: expected 2 but found 0 (indentation)"},
+		},
+		{
+			name: "truthy values",
+			content: `enabled: yes
+list: [on, true, "no"]
+items:
+  - off
+`,
+			expected: []string{
+				"1:10 [warning] truthy value should be one of [false, true] (truthy)",
+				"2:8 [warning] truthy value should be one of [false, true] (truthy)",
+				"4:5 [warning] truthy value should be one of [false, true] (truthy)",
+			},
+		},
+		{
+			name:     "line length",
+			content:  "description: " + "a very long description that keeps going and going past the limit of the line" + "\n",
+			expected: []string{"1:81 [error] line too long (90 > 80 characters) (line-length)"},
+		},
+		{
+			name:     "empty lines",
+			content:  "a: 1\n\n\n\nb: 2\n\n",
+			expected: []string{"4:1 [error] too many blank lines (3 > 2) (empty-lines)", "6:1 [error] too many blank lines (1 > 0) (empty-lines)"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, problemStrings(Lint([]byte(tc.content), defaultPreset())))
+		})
+	}
+}
+
+func TestDefaultConfig(t *testing.T) {
+	problems := Lint([]byte("kind: ZarfPackageConfig  \nmetadata:\n  description: yes"), DefaultConfig())
+	assert.Equal(t, []string{"1:24 [warning] trailing spaces (trailing-spaces)",
+		"3:19 [warning] no new line character at the end of file (new-line-at-end-of-file)"}, problemStrings(problems))
+}
+
+func TestLoadConfig(t *testing.T) {
+	cfg, err := LoadConfig("../../etc/lintconf.yaml")
+	require.NoError(t, err)
+
+	assert.Nil(t, cfg.rule("line-length"))
+	assert.NotNil(t, cfg.rule("trailing-spaces"))
+	assert.Equal(t, LevelWarning, cfg.rule("truthy").Level)
+	assert.Equal(t, "whatever", cfg.rule("indentation").Options["indent-sequences"])
+
+	problems := Lint([]byte("components:\n- name: app\n  enabled: yes\n"), cfg)
+	assert.Equal(t, []string{"3:12 [warning] truthy value should be one of [false, true] (truthy)"}, problemStrings(problems))
+}
@@ -0,0 +1,596 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// actionWaitTimeout bounds every re-check of a cluster wait after deployment. zarf
+// has already waited for the condition, so it is expected to hold right away.
+const actionWaitTimeout = "30s"
+
+// Rules of the commands of actions, whose severity is configurable, see
+// DefaultActionRules
+const (
+	ActionPipeToShell      = "action-pipe-to-shell"
+	ActionDeleteAll        = "action-delete-all"
+	ActionUnquotedVariable = "action-unquoted-variable"
+	ActionHostPath         = "action-host-path"
+	ActionWaitTimeout      = "action-wait-timeout"
+	ActionWaitTarget       = "action-wait-target"
+)
+
+// ActionRules are the configurable rules of action commands
+var ActionRules = []string{ActionPipeToShell, ActionDeleteAll, ActionUnquotedVariable, ActionHostPath, ActionWaitTimeout, ActionWaitTarget}
+
+// DefaultActionRules returns the default severities of the action command rules:
+// unquoted variables are info findings, the other rules warnings
+func DefaultActionRules() map[string]string {
+	return map[string]string{
+		ActionPipeToShell:      SeverityWarning,
+		ActionDeleteAll:        SeverityWarning,
+		ActionUnquotedVariable: SeverityInfo,
+		ActionHostPath:         SeverityWarning,
+		ActionWaitTimeout:      SeverityWarning,
+		ActionWaitTarget:       SeverityWarning,
+	}
+}
+
+// SetActionRule sets the severity of an action command rule in rules, 'off' disables
+// the rule
+func SetActionRule(rules map[string]string, rule, severity string) error {
+	if _, ok := DefaultActionRules()[rule]; !ok {
+		return fmt.Errorf("invalid action rule %q, must be one of: %s", rule, strings.Join(ActionRules, ", "))
+	}
+	switch severity {
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		rules[rule] = severity
+		return nil
+	default:
+		return fmt.Errorf("invalid severity %q of action rule %q, must be one of: error, warning, info, off", severity, rule)
+	}
+}
+
+var (
+	// pipeToShellPattern matches commands that pipe a download into a shell
+	pipeToShellPattern = regexp.MustCompile(`\b(curl|wget)\b[^|;&]*\|\s*(sudo\s+)?(ba|z|da)?sh\b`)
+	// deleteAllPattern matches kubectl deleting all objects of a kind or namespace
+	deleteAllPattern = regexp.MustCompile(`\bkubectl\s+delete\b[^|;&]*\s(--all|--all-namespaces|-A)(\s|=|$)`)
+	// hostPathPattern matches absolute paths into the file system of the host running
+	// the action, outside of the paths every system provides such as /dev/null
+	hostPathPattern    = regexp.MustCompile(`(^|[\s=:"'(])(/(etc|home|mnt|opt|root|srv|tmp|Users|usr/local|var)(/[^\s"';|&)]*)?)`)
+	sudoPattern        = regexp.MustCompile(`(^|[\s;&|(])sudo\s`)
+	waitForPattern     = regexp.MustCompile(`\bzarf\s+tools\s+wait-for\b`)
+	setVariablePattern = regexp.MustCompile(`^[A-Z0-9_]+$`)
+)
+
+// componentAction is an action of a component along with where it is defined
+type componentAction struct {
+	// Event is onCreate, onDeploy or onRemove
+	Event string
+	// Stage is before, after, onSuccess or onFailure
+	Stage  string
+	Index  int
+	Action util.ZarfComponentAction
+}
+
+func (a componentAction) String() string {
+	return fmt.Sprintf("%s.%s[%d]", a.Event, a.Stage, a.Index)
+}
+
+// componentActions returns every action of the component in the order zarf defines
+// them
+func componentActions(component util.ZarfComponent) []componentAction {
+	var actions []componentAction
+	events := []struct {
+		name string
+		set  util.ZarfComponentActionSet
+	}{
+		{"onCreate", component.Actions.OnCreate},
+		{"onDeploy", component.Actions.OnDeploy},
+		{"onRemove", component.Actions.OnRemove},
+	}
+	for _, event := range events {
+		stages := []struct {
+			name    string
+			actions []util.ZarfComponentAction
+		}{
+			{"before", event.set.Before},
+			{"after", event.set.After},
+			{"onSuccess", event.set.OnSuccess},
+			{"onFailure", event.set.OnFailure},
+		}
+		for _, stage := range stages {
+			for i, action := range stage.actions {
+				actions = append(actions, componentAction{Event: event.name, Stage: stage.name, Index: i, Action: action})
+			}
+		}
+	}
+	return actions
+}
+
+// validateActions checks that every component action is well formed, flags risky
+// commands and reports the deprecated scripts block and 'zarf tools wait-for'
+func (v *PackageValidator) validateActions(pkg *PackageContext, result *ValidationResult) error {
+	for _, component := range pkg.ZarfYaml.Components {
+		scripts := component.Scripts
+		if len(scripts.Prepare) > 0 || len(scripts.Before) > 0 || len(scripts.After) > 0 {
+			result.AddWarning("deprecation",
+				fmt.Sprintf("Component '%s' uses the deprecated scripts block, use actions instead", component.Name))
+		}
+
+		events := []struct {
+			name     string
+			defaults util.ZarfComponentActionDefaults
+		}{
+			{"onCreate", component.Actions.OnCreate.Defaults},
+			{"onDeploy", component.Actions.OnDeploy.Defaults},
+			{"onRemove", component.Actions.OnRemove.Defaults},
+		}
+		for _, event := range events {
+			if event.defaults.MaxTotalSeconds < 0 || event.defaults.MaxRetries < 0 {
+				result.AddError("action-definition",
+					fmt.Sprintf("Component '%s' %s defaults must not set negative maxTotalSeconds or maxRetries", component.Name, event.name))
+			}
+			for _, problem := range checkActionEnv(event.defaults.Env) {
+				result.AddError("action-definition",
+					fmt.Sprintf("Component '%s' %s defaults %s", component.Name, event.name, problem))
+			}
+		}
+
+		for i, script := range append(append(append([]string{}, scripts.Prepare...), scripts.Before...), scripts.After...) {
+			v.checkCommand(result, fmt.Sprintf("Component '%s' script %d", component.Name, i), script)
+		}
+
+		maxTotalSeconds := map[string]int{}
+		for _, event := range events {
+			maxTotalSeconds[event.name] = event.defaults.MaxTotalSeconds
+		}
+		for _, action := range componentActions(component) {
+			name := fmt.Sprintf("Component '%s' action %s", component.Name, action)
+			v.checkCommand(result, name, action.Action.Cmd)
+			if action.Action.Dir != "" && hostPathPattern.MatchString(action.Action.Dir) {
+				v.addActionFinding(result, ActionHostPath, "%s runs in host directory '%s'", name, action.Action.Dir)
+			}
+			if action.Action.Wait != nil && action.Action.MaxTotalSeconds == nil && maxTotalSeconds[action.Event] == 0 {
+				v.addActionFinding(result, ActionWaitTimeout, "%s waits without maxTotalSeconds, set a timeout to fail deployments that never become ready", name)
+			}
+
+			for _, problem := range checkAction(action) {
+				result.AddError("action-definition",
+					fmt.Sprintf("Component '%s' action %s %s", component.Name, action, problem))
+			}
+
+			cmd := action.Action.Cmd
+			if waitForPattern.MatchString(cmd) {
+				result.AddWarning("deprecation",
+					fmt.Sprintf("Component '%s' action %s runs 'zarf tools wait-for', use wait instead", component.Name, action))
+			}
+			if sudoPattern.MatchString(cmd) {
+				result.AddWarning("action-security",
+					fmt.Sprintf("Component '%s' action %s runs sudo", component.Name, action))
+			}
+		}
+	}
+	return nil
+}
+
+// addActionFinding reports a finding of an action command rule with its configured
+// severity, unless the rule is off
+func (v *PackageValidator) addActionFinding(result *ValidationResult, rule, format string, args ...interface{}) {
+	severity, ok := v.ActionRules[rule]
+	if !ok {
+		severity = DefaultActionRules()[rule]
+	}
+	if severity == SeverityOff {
+		return
+	}
+	result.AddFinding(Finding{RuleID: rule, Severity: severity, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkCommand reports the dangerous patterns of the command of an action or script
+func (v *PackageValidator) checkCommand(result *ValidationResult, name, cmd string) {
+	if cmd == "" {
+		return
+	}
+	if pipeToShellPattern.MatchString(cmd) {
+		v.addActionFinding(result, ActionPipeToShell, "%s pipes a download into a shell", name)
+	}
+	if deleteAllPattern.MatchString(cmd) {
+		v.addActionFinding(result, ActionDeleteAll, "%s deletes all objects with 'kubectl delete', which may remove objects it does not own", name)
+	}
+	if variables := unquotedVariables(cmd); len(variables) > 0 {
+		v.addActionFinding(result, ActionUnquotedVariable, "%s expands %s outside of double quotes, which splits values with spaces",
+			name, strings.Join(variables, ", "))
+	}
+	var paths []string
+	for _, match := range hostPathPattern.FindAllStringSubmatch(cmd, -1) {
+		paths = append(paths, match[2])
+	}
+	if len(paths) > 0 {
+		v.addActionFinding(result, ActionHostPath, "%s uses host paths %s, which may not exist on the machine deploying the package",
+			name, strings.Join(paths, ", "))
+	}
+}
+
+// unquotedVariables returns the variables a shell command expands outside of double
+// quotes, e.g. '$ZARF_VAR_DOMAIN' or '${NAME}'. Assignments ('A=$B'), single-quoted text
+// and special parameters such as '$?' are not reported.
+func unquotedVariables(cmd string) []string {
+	var variables []string
+	seen := map[string]bool{}
+	inSingle, inDouble := false, false
+	for i := 0; i < len(cmd); i++ {
+		c := cmd[i]
+		switch {
+		case c == '\\' && !inSingle:
+			i++
+		case c == '\'' && !inDouble:
+			inSingle = !inSingle
+		case c == '"' && !inSingle:
+			inDouble = !inDouble
+		case c == '$' && !inSingle && !inDouble && i+1 < len(cmd):
+			if i > 0 && cmd[i-1] == '=' {
+				continue
+			}
+			end, braced := i+1, cmd[i+1] == '{'
+			if braced {
+				end++
+			}
+			start := end
+			for end < len(cmd) && (cmd[end] == '_' || isAlphanumeric(cmd[end])) {
+				end++
+			}
+			if end == start || cmd[start] >= '0' && cmd[start] <= '9' {
+				continue
+			}
+			variable := "$" + cmd[start:end]
+			if braced {
+				variable = "${" + cmd[start:end] + "}"
+			}
+			if !seen[variable] {
+				seen[variable] = true
+				variables = append(variables, variable)
+			}
+		}
+	}
+	return variables
+}
+
+// isAlphanumeric reports whether c is an ASCII letter or digit
+func isAlphanumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// checkAction returns the problems of an action definition
+func checkAction(action componentAction) []string {
+	var problems []string
+	a := action.Action
+	switch {
+	case a.Cmd == "" && a.Wait == nil:
+		problems = append(problems, "must set cmd or wait")
+	case a.Cmd != "" && a.Wait != nil:
+		problems = append(problems, "must set only one of cmd and wait")
+	}
+	if a.MaxTotalSeconds != nil && *a.MaxTotalSeconds < 0 {
+		problems = append(problems, "must not set a negative maxTotalSeconds")
+	}
+	if a.MaxRetries != nil && *a.MaxRetries < 0 {
+		problems = append(problems, "must not set negative maxRetries")
+	}
+	problems = append(problems, checkActionEnv(a.Env)...)
+	for _, variable := range a.SetVariables {
+		if !setVariablePattern.MatchString(variable.Name) {
+			problems = append(problems, fmt.Sprintf("sets variable '%s' whose name is not uppercase letters, digits and underscores", variable.Name))
+		}
+	}
+
+	if a.Wait == nil {
+		return problems
+	}
+	if a.Wait.Cluster == nil && a.Wait.Network == nil {
+		problems = append(problems, "must set wait.cluster or wait.network")
+	} else if a.Wait.Cluster != nil && a.Wait.Network != nil {
+		problems = append(problems, "must set only one of wait.cluster and wait.network")
+	}
+	if cluster := a.Wait.Cluster; cluster != nil {
+		if action.Event == "onCreate" {
+			problems = append(problems, "cannot wait for the cluster while the package is created")
+		}
+		if cluster.Kind == "" || cluster.Name == "" {
+			problems = append(problems, "must set kind and name of wait.cluster")
+		}
+	}
+	if network := a.Wait.Network; network != nil {
+		switch network.Protocol {
+		case "http", "https", "tcp":
+		default:
+			problems = append(problems, fmt.Sprintf("has invalid wait.network protocol '%s', must be one of: http, https, tcp", network.Protocol))
+		}
+		if network.Address == "" {
+			problems = append(problems, "must set the address of wait.network")
+		}
+		if network.Code != 0 && network.Protocol == "tcp" {
+			problems = append(problems, "cannot expect a status code from a tcp wait")
+		}
+	}
+	return problems
+}
+
+// checkActionEnv returns the problems of the env entries of an action
+func checkActionEnv(env []string) []string {
+	var problems []string
+	for _, entry := range env {
+		if name, _, ok := strings.Cut(entry, "="); !ok || name == "" {
+			problems = append(problems, fmt.Sprintf("has env entry '%s' that is not of the form NAME=value", entry))
+		}
+	}
+	return problems
+}
+
+// deployedComponents returns the components deployed with the selection: the required
+// components and the selected ones, or the default components without a selection
+func deployedComponents(zarfYaml *util.ZarfYaml, selection *ComponentSelection) []util.ZarfComponent {
+	included, excluded := map[string]bool{}, map[string]bool{}
+	if selection != nil {
+		for _, name := range selection.Components {
+			if strings.HasPrefix(name, "-") {
+				excluded[strings.TrimPrefix(name, "-")] = true
+			} else {
+				included[name] = true
+			}
+		}
+	}
+
+	var components []util.ZarfComponent
+	for _, component := range zarfYaml.Components {
+		deployed := component.Required || included[component.Name]
+		if selection == nil {
+			deployed = deployed || component.Default
+		}
+		if deployed && !excluded[component.Name] {
+			components = append(components, component)
+		}
+	}
+	return components
+}
+
+// verifyActionWaits checks that the cluster waits of the onDeploy actions of every
+// deployed component still hold after the deployment. Network waits address the host
+// zarf ran on, so they are not verified.
+//
+// Failed waits for objects the package never creates are pointed out, as the wait is
+// wrong rather than the deployment.
+func (d *PackageDeployer) verifyActionWaits(ctx context.Context, name, packagePath string, zarfYaml *util.ZarfYaml, selection *ComponentSelection, namespaces namespaceMapping) []ComponentTestResult {
+	var results []ComponentTestResult
+	var objects *packageObjects
+	for _, component := range deployedComponents(zarfYaml, selection) {
+		for _, action := range componentActions(component) {
+			if action.Event != "onDeploy" || action.Stage == "onFailure" {
+				continue
+			}
+			if action.Action.Wait == nil || action.Action.Wait.Cluster == nil {
+				continue
+			}
+			wait := *action.Action.Wait.Cluster
+			result := ComponentTestResult{
+				ComponentName: fmt.Sprintf("wait/%s/%s", wait.Kind, wait.Name),
+				Success:       true,
+				Message:       "exists",
+			}
+			if wait.Condition != "" && !strings.EqualFold(wait.Condition, "exists") {
+				result.Message = fmt.Sprintf("condition %s met", wait.Condition)
+			}
+			if err := d.assertWait(ctx, name, wait, namespaces.resolve(wait.Namespace)); err != nil {
+				result.Success = false
+				result.Message = err.Error()
+				if objects == nil {
+					objects = &packageObjects{}
+					if pkg, err := LoadPackageContext(packagePath); err == nil {
+						*objects = pkg.objects(ctx)
+					}
+				}
+				if created, known := objects.creates(wait); known && !created {
+					result.Message += fmt.Sprintf(" (the package never creates %s %s)", wait.Kind, wait.Name)
+				}
+			}
+			results = append(results, result)
+			if ctx.Err() != nil {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+// controllerKinds are created by controllers from the objects of a package rather than
+// by the package itself, e.g. the pods of a deployment
+var controllerKinds = map[string]bool{
+	"pod": true, "replicaset": true, "controllerrevision": true, "endpoints": true, "endpointslice": true,
+	"persistentvolumeclaim": true, "event": true, "lease": true,
+}
+
+// waitKindAliases are the short names kubectl accepts for kinds
+var waitKindAliases = map[string]string{
+	"cm": "configmap", "cj": "cronjob", "crd": "customresourcedefinition", "deploy": "deployment",
+	"ds": "daemonset", "ep": "endpoints", "hpa": "horizontalpodautoscaler", "ing": "ingress",
+	"netpol": "networkpolicy", "ns": "namespace", "pdb": "poddisruptionbudget", "po": "pod",
+	"pvc": "persistentvolumeclaim", "rs": "replicaset", "sa": "serviceaccount", "sts": "statefulset",
+	"svc": "service",
+}
+
+// waitKinds returns the lowercase kinds the kind of a wait may name: kubectl accepts
+// kinds, their plurals and short names, optionally qualified with the API group
+func waitKinds(kind string) []string {
+	kind, _, _ = strings.Cut(strings.ToLower(kind), ".")
+	if alias, ok := waitKindAliases[kind]; ok {
+		return []string{alias}
+	}
+	kinds := []string{kind}
+	switch {
+	case strings.HasSuffix(kind, "ies"):
+		kinds = append(kinds, strings.TrimSuffix(kind, "ies")+"y")
+	case strings.HasSuffix(kind, "es"):
+		kinds = append(kinds, strings.TrimSuffix(kind, "es"), strings.TrimSuffix(kind, "s"))
+	case strings.HasSuffix(kind, "s"):
+		kinds = append(kinds, strings.TrimSuffix(kind, "s"))
+	}
+	return kinds
+}
+
+// packageObjects are the Kubernetes objects a package creates
+type packageObjects struct {
+	// namespaces are the namespaces of the objects by lowercase kind and name, e.g.
+	// 'deployment/podinfo'. The namespace is empty when it is not known.
+	namespaces map[string][]string
+	kinds      map[string]bool
+	// complete is set when every chart, manifest and kustomization of the package was
+	// rendered, so objects that are not listed are never created
+	complete bool
+}
+
+// objects returns the objects the package creates, from its rendered manifests
+func (pkg *PackageContext) objects(ctx context.Context) packageObjects {
+	objects := packageObjects{namespaces: map[string][]string{}, kinds: map[string]bool{}, complete: true}
+	for _, component := range pkg.ZarfYaml.Components {
+		if component.Import.Path != "" || component.Import.URL != "" {
+			objects.complete = false
+		}
+		for _, chart := range component.Charts {
+			if chart.LocalPath == "" || !util.FileExists(filepath.Join(pkg.Path, chart.LocalPath)) {
+				objects.complete = false
+			}
+		}
+		for _, manifest := range component.Manifests {
+			for _, file := range append(append([]string{}, manifest.Files...), manifest.Kustomizations...) {
+				if isRemoteReference(file) || !util.FileExists(filepath.Join(pkg.Path, file)) {
+					objects.complete = false
+				}
+			}
+		}
+	}
+
+	for _, manifest := range pkg.renderedManifests(ctx) {
+		if manifest.Err != nil {
+			objects.complete = false
+			continue
+		}
+		for _, doc := range manifest.documents() {
+			kind, _ := doc["kind"].(string)
+			metadata, _ := toStringMap(doc["metadata"])
+			name, _ := metadata["name"].(string)
+			if kind == "" || name == "" {
+				continue
+			}
+			namespace, _ := metadata["namespace"].(string)
+			if namespace == "" {
+				namespace = manifest.Namespace
+			}
+			kind = strings.ToLower(kind)
+			objects.kinds[kind] = true
+			objects.namespaces[kind+"/"+name] = append(objects.namespaces[kind+"/"+name], namespace)
+		}
+	}
+	return objects
+}
+
+// creates reports whether the package creates the object a cluster wait is for. The
+// answer is only known when all objects of the package are known, the package creates
+// objects of the kind and the wait names an object rather than a label selector.
+func (o packageObjects) creates(wait util.ZarfComponentActionWaitCluster) (created, known bool) {
+	if !o.complete || strings.Contains(wait.Name, "=") {
+		return false, false
+	}
+	for _, kind := range waitKinds(wait.Kind) {
+		if controllerKinds[kind] {
+			return false, false
+		}
+		if !o.kinds[kind] {
+			continue
+		}
+		known = true
+		for _, namespace := range o.namespaces[kind+"/"+wait.Name] {
+			if wait.Namespace == "" || namespace == "" || namespace == wait.Namespace {
+				return true, true
+			}
+		}
+	}
+	return false, known
+}
+
+// validateWaitTargets reports cluster waits of actions for objects the package never
+// creates, which would time out the deployment
+func (v *PackageValidator) validateWaitTargets(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	var objects *packageObjects
+	for _, component := range pkg.ZarfYaml.Components {
+		for _, action := range componentActions(component) {
+			if action.Event == "onCreate" || action.Action.Wait == nil || action.Action.Wait.Cluster == nil {
+				continue
+			}
+			if objects == nil {
+				objects = &packageObjects{}
+				*objects = pkg.objects(ctx)
+			}
+			wait := *action.Action.Wait.Cluster
+			if created, known := objects.creates(wait); known && !created {
+				target := wait.Kind + " '" + wait.Name + "'"
+				if wait.Namespace != "" {
+					target += " in namespace '" + wait.Namespace + "'"
+				}
+				v.addActionFinding(result, ActionWaitTarget, "Component '%s' action %s waits for %s, which the package never creates",
+					component.Name, action, target)
+			}
+		}
+	}
+	return nil
+}
+
+// assertWait checks the condition of a cluster wait. The name may be a label selector.
+// Without a condition, or with the condition 'exists', the resource must exist. A
+// condition starting with '{' is a JSONPath expression, any other condition is a status
+// condition of the resource.
+func (d *PackageDeployer) assertWait(ctx context.Context, name string, wait util.ZarfComponentActionWaitCluster, namespace string) error {
+	resource := []string{wait.Kind, wait.Name}
+	if strings.Contains(wait.Name, "=") {
+		resource = []string{wait.Kind, "--selector", wait.Name}
+	}
+
+	if wait.Condition == "" || strings.EqualFold(wait.Condition, "exists") {
+		output, err := d.run(ctx, name, "", "kubectl", "get", resource, namespaceArgs(namespace),
+			"--ignore-not-found", "--output", "name")
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(output) == "" {
+			return fmt.Errorf("%s %s not found", wait.Kind, wait.Name)
+		}
+		return nil
+	}
+
+	condition := "condition=" + wait.Condition
+	if strings.HasPrefix(wait.Condition, "{") {
+		condition = "jsonpath=" + wait.Condition
+	}
+	_, err := d.run(ctx, name, "", "kubectl", "wait", resource, namespaceArgs(namespace),
+		"--for", condition, "--timeout", actionWaitTimeout)
+	return err
+}
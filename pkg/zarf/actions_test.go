@@ -0,0 +1,304 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateActions(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+components:
+  - name: podinfo
+    scripts:
+      after:
+        - ./setup.sh
+    actions:
+      onCreate:
+        before:
+          - cmd: curl -sL https://example.com/install.sh | bash
+          - wait:
+              cluster:
+                kind: deployment
+                name: podinfo
+      onDeploy:
+        defaults:
+          env:
+            - LOG_LEVEL
+        after:
+          - cmd: zarf tools wait-for deployment podinfo available
+            maxTotalSeconds: 60
+          - cmd: sudo systemctl restart podinfo
+            wait:
+              network:
+                protocol: https
+                address: podinfo.local
+          - wait:
+              network:
+                protocol: udp
+                address: ""
+          - wait:
+              cluster:
+                kind: deployment
+                name: podinfo
+                condition: available
+          - cmd: echo done
+            setVariables:
+              - name: podinfo_url
+      onRemove:
+        onFailure:
+          - description: nothing to do
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validateActions(pkg, result))
+
+	var messages []string
+	for _, finding := range result.Findings {
+		messages = append(messages, string(finding.Severity)+" "+finding.RuleID+": "+finding.Message)
+	}
+	assert.Equal(t, []string{
+		"warning deprecation: Component 'podinfo' uses the deprecated scripts block, use actions instead",
+		"error action-definition: Component 'podinfo' onDeploy defaults has env entry 'LOG_LEVEL' that is not of the form NAME=value",
+		"warning action-pipe-to-shell: Component 'podinfo' action onCreate.before[0] pipes a download into a shell",
+		"warning action-wait-timeout: Component 'podinfo' action onCreate.before[1] waits without maxTotalSeconds, set a timeout to fail deployments that never become ready",
+		"error action-definition: Component 'podinfo' action onCreate.before[1] cannot wait for the cluster while the package is created",
+		"warning deprecation: Component 'podinfo' action onDeploy.after[0] runs 'zarf tools wait-for', use wait instead",
+		"warning action-wait-timeout: Component 'podinfo' action onDeploy.after[1] waits without maxTotalSeconds, set a timeout to fail deployments that never become ready",
+		"error action-definition: Component 'podinfo' action onDeploy.after[1] must set only one of cmd and wait",
+		"warning action-security: Component 'podinfo' action onDeploy.after[1] runs sudo",
+		"warning action-wait-timeout: Component 'podinfo' action onDeploy.after[2] waits without maxTotalSeconds, set a timeout to fail deployments that never become ready",
+		"error action-definition: Component 'podinfo' action onDeploy.after[2] has invalid wait.network protocol 'udp', must be one of: http, https, tcp",
+		"error action-definition: Component 'podinfo' action onDeploy.after[2] must set the address of wait.network",
+		"warning action-wait-timeout: Component 'podinfo' action onDeploy.after[3] waits without maxTotalSeconds, set a timeout to fail deployments that never become ready",
+		"error action-definition: Component 'podinfo' action onDeploy.after[4] sets variable 'podinfo_url' whose name is not uppercase letters, digits and underscores",
+		"error action-definition: Component 'podinfo' action onRemove.onFailure[0] must set cmd or wait",
+	}, messages)
+}
+
+func TestValidateActionCommands(t *testing.T) {
+	component := util.ZarfComponent{
+		Name:    "cleanup",
+		Scripts: util.ZarfComponentScripts{Before: []string{"cp config.yaml /etc/podinfo/config.yaml"}},
+	}
+	component.Actions.OnDeploy.Defaults.MaxTotalSeconds = 300
+	component.Actions.OnDeploy.Before = []util.ZarfComponentAction{
+		{Cmd: "./zarf tools kubectl delete pods --all -n $NAMESPACE"},
+		{Cmd: `echo "$ZARF_VAR_DOMAIN" '$LITERAL' ${ZARF_VAR_PORT} $? $1 URL=$HOST`},
+		{Cmd: "ls", Dir: "/opt/podinfo"},
+		{Wait: &util.ZarfComponentActionWait{Network: &util.ZarfComponentActionWaitNetwork{Protocol: "https", Address: "podinfo.local"}}},
+		{Cmd: "echo done > /dev/null"},
+	}
+	zarfYaml := &util.ZarfYaml{Components: []util.ZarfComponent{component}}
+
+	v := NewPackageValidator()
+	result := &ValidationResult{}
+	require.NoError(t, v.validateActions(&PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "deprecation", Severity: SeverityWarning, Message: "Component 'cleanup' uses the deprecated scripts block, use actions instead"},
+		{RuleID: ActionHostPath, Severity: SeverityWarning, Message: "Component 'cleanup' script 0 uses host paths /etc/podinfo/config.yaml, which may not exist on the machine deploying the package"},
+		{RuleID: ActionDeleteAll, Severity: SeverityWarning, Message: "Component 'cleanup' action onDeploy.before[0] deletes all objects with 'kubectl delete', which may remove objects it does not own"},
+		{RuleID: ActionUnquotedVariable, Severity: SeverityInfo, Message: "Component 'cleanup' action onDeploy.before[0] expands $NAMESPACE outside of double quotes, which splits values with spaces"},
+		{RuleID: ActionUnquotedVariable, Severity: SeverityInfo, Message: "Component 'cleanup' action onDeploy.before[1] expands ${ZARF_VAR_PORT} outside of double quotes, which splits values with spaces"},
+		{RuleID: ActionHostPath, Severity: SeverityWarning, Message: "Component 'cleanup' action onDeploy.before[2] runs in host directory '/opt/podinfo'"},
+	}, result.Findings)
+
+	// Rules are configurable per rule ID
+	require.NoError(t, SetActionRule(v.ActionRules, ActionUnquotedVariable, SeverityOff))
+	require.NoError(t, SetActionRule(v.ActionRules, ActionDeleteAll, SeverityError))
+	result = &ValidationResult{}
+	require.NoError(t, v.validateActions(&PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Len(t, result.Findings, 4)
+	assert.Equal(t, SeverityError, result.Findings[2].Severity)
+	assert.EqualError(t, SetActionRule(v.ActionRules, "action-sudo", SeverityOff),
+		"invalid action rule \"action-sudo\", must be one of: action-pipe-to-shell, action-delete-all, action-unquoted-variable, action-host-path, action-wait-timeout, action-wait-target")
+	assert.EqualError(t, SetActionRule(v.ActionRules, ActionHostPath, "fatal"),
+		"invalid severity \"fatal\" of action rule \"action-host-path\", must be one of: error, warning, info, off")
+}
+
+func TestDeployedComponents(t *testing.T) {
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{Name: "base", Required: true},
+			{Name: "ui", Default: true},
+			{Name: "metrics"},
+			{Name: "tracing"},
+		},
+	}
+	names := func(components []util.ZarfComponent) []string {
+		var names []string
+		for _, component := range components {
+			names = append(names, component.Name)
+		}
+		return names
+	}
+
+	assert.Equal(t, []string{"base", "ui"}, names(deployedComponents(zarfYaml, nil)))
+	assert.Equal(t, []string{"base", "metrics"}, names(deployedComponents(zarfYaml, &ComponentSelection{Components: []string{"metrics", "-ui"}})))
+}
+
+func TestVerifyActionWaits(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"kubectl": `echo "kubectl $*" >> "$ZT_TEST_CALLS"
+case "$*" in
+"get configmap podinfo-config"*) echo configmap/podinfo-config ;;
+"wait deployment --selector app=podinfo"*) echo "timed out" >&2; exit 1 ;;
+esac`,
+	})
+
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{
+				Name:     "podinfo",
+				Required: true,
+				Actions: util.ZarfComponentActions{
+					OnDeploy: util.ZarfComponentActionSet{
+						After: []util.ZarfComponentAction{
+							{Wait: &util.ZarfComponentActionWait{Cluster: &util.ZarfComponentActionWaitCluster{Kind: "configmap", Name: "podinfo-config"}}},
+							{Wait: &util.ZarfComponentActionWait{Cluster: &util.ZarfComponentActionWaitCluster{Kind: "deployment", Name: "app=podinfo", Condition: "available"}}},
+							{Wait: &util.ZarfComponentActionWait{Cluster: &util.ZarfComponentActionWaitCluster{Kind: "pod", Name: "podinfo-0", Namespace: "monitoring", Condition: "{.status.phase}=Running"}}},
+							{Wait: &util.ZarfComponentActionWait{Network: &util.ZarfComponentActionWaitNetwork{Protocol: "http", Address: "localhost:9898"}}},
+						},
+						OnFailure: []util.ZarfComponentAction{
+							{Wait: &util.ZarfComponentActionWait{Cluster: &util.ZarfComponentActionWaitCluster{Kind: "secret", Name: "podinfo-debug"}}},
+						},
+					},
+				},
+			},
+			{
+				Name: "optional",
+				Actions: util.ZarfComponentActions{
+					OnDeploy: util.ZarfComponentActionSet{
+						After: []util.ZarfComponentAction{
+							{Wait: &util.ZarfComponentActionWait{Cluster: &util.ZarfComponentActionWaitCluster{Kind: "deployment", Name: "optional"}}},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	d := NewPackageDeployer()
+	results := d.verifyActionWaits(context.Background(), "podinfo", "", zarfYaml, nil, namespaceMapping{original: "podinfo", deployed: "zt-test-1"})
+	require.Len(t, results, 3)
+	assert.Equal(t, ComponentTestResult{ComponentName: "wait/configmap/podinfo-config", Success: true, Message: "exists"}, results[0])
+	assert.Equal(t, "wait/deployment/app=podinfo", results[1].ComponentName)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, ComponentTestResult{ComponentName: "wait/pod/podinfo-0", Success: true, Message: "condition {.status.phase}=Running met"}, results[2])
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, `kubectl get configmap podinfo-config --namespace zt-test-1 --ignore-not-found --output name
+kubectl wait deployment --selector app=podinfo --namespace zt-test-1 --for condition=available --timeout 30s
+kubectl wait pod podinfo-0 --namespace monitoring --for jsonpath={.status.phase}=Running --timeout 30s
+`, string(content))
+}
+
+func TestValidateWaitTargets(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+components:
+  - name: podinfo
+    required: true
+    manifests:
+      - name: podinfo
+        namespace: podinfo
+        files:
+          - manifests.yaml
+    actions:
+      onDeploy:
+        after:
+          - wait:
+              cluster:
+                kind: deployments.apps
+                name: podinfo
+          - wait:
+              cluster:
+                kind: deploy
+                name: podinfo-ui
+          - wait:
+              cluster:
+                kind: svc
+                name: podinfo
+                namespace: monitoring
+          - wait:
+              cluster:
+                kind: pod
+                name: podinfo-0
+          - wait:
+              cluster:
+                kind: deployment
+                name: app=podinfo
+          - wait:
+              cluster:
+                kind: certificate
+                name: podinfo-tls
+`
+	manifests := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: podinfo
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "manifests.yaml"), []byte(manifests), 0644))
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	// Pods, label selectors and kinds the package does not create may come from elsewhere
+	result := &ValidationResult{}
+	require.NoError(t, NewPackageValidator().validateWaitTargets(context.Background(), pkg, result))
+	assert.Equal(t, []Finding{
+		{RuleID: ActionWaitTarget, Severity: SeverityWarning, Message: "Component 'podinfo' action onDeploy.after[1] waits for deploy 'podinfo-ui', which the package never creates"},
+		{RuleID: ActionWaitTarget, Severity: SeverityWarning, Message: "Component 'podinfo' action onDeploy.after[2] waits for svc 'podinfo' in namespace 'monitoring', which the package never creates"},
+	}, result.Findings)
+
+	// Failed waits during install point out that the package never creates the object
+	fakeCommands(t, map[string]string{"kubectl": `exit 0`})
+	results := NewPackageDeployer().verifyActionWaits(context.Background(), "podinfo", packageDir, pkg.ZarfYaml, nil,
+		namespaceMapping{original: "podinfo", deployed: "podinfo"})
+	require.Len(t, results, 6)
+	assert.Equal(t, ComponentTestResult{ComponentName: "wait/deploy/podinfo-ui", Message: "deploy podinfo-ui not found (the package never creates deploy podinfo-ui)"}, results[1])
+	assert.Equal(t, ComponentTestResult{ComponentName: "wait/certificate/podinfo-tls", Message: "certificate podinfo-tls not found"}, results[5])
+
+	// Packages with remote charts may create any object
+	pkg.ZarfYaml.Components[0].Charts = []util.ZarfChart{{Name: "podinfo", Url: "https://stefanprodan.github.io/podinfo"}}
+	pkg.rendered = nil
+	result = &ValidationResult{}
+	require.NoError(t, NewPackageValidator().validateWaitTargets(context.Background(), pkg, result))
+	assert.Empty(t, result.Findings)
+}
@@ -0,0 +1,110 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// artifactsTimeout limits how long collecting diagnostics of a failed package may take
+const artifactsTimeout = 2 * time.Minute
+
+// packageNamespaces returns the namespaces the charts, manifests and data injections of
+// a package deploy to, sorted and without duplicates
+func packageNamespaces(zarfYaml *util.ZarfYaml) []string {
+	seen := map[string]bool{}
+	for _, component := range zarfYaml.Components {
+		for _, chart := range component.Charts {
+			seen[chart.Namespace] = true
+		}
+		for _, manifest := range component.Manifests {
+			seen[manifest.Namespace] = true
+		}
+		for _, injection := range component.DataInjections {
+			seen[injection.Target.Namespace] = true
+		}
+	}
+	delete(seen, "")
+
+	namespaces := make([]string, 0, len(seen))
+	for namespace := range seen {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
+// collectArtifacts writes pod descriptions, events and pod logs of the namespaces
+// touched by the package to ArtifactsDir/<name>/<namespace> and returns the paths of
+// the written files. A command that fails has its error written instead of its output.
+func (d *PackageDeployer) collectArtifacts(ctx context.Context, name, packagePath string) ([]string, error) {
+	zarfYaml, err := util.ReadZarfYaml(filepath.Join(packagePath, "zarf.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zarf.yaml: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, artifactsTimeout)
+	defer cancel()
+
+	executor := d.executor()
+	var paths []string
+	for _, namespace := range packageNamespaces(zarfYaml) {
+		dir := filepath.Join(d.ArtifactsDir, name, namespace)
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return paths, fmt.Errorf("failed to create artifacts directory: %w", err)
+		}
+
+		write := func(file string, args ...interface{}) error {
+			output, err := executor.RunProcessAndCaptureOutput(ctx, "kubectl", args...)
+			if err != nil {
+				output = err.Error()
+			}
+			path := filepath.Join(dir, file)
+			if err := os.WriteFile(path, []byte(output+"\n"), 0644); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+			paths = append(paths, path)
+			return nil
+		}
+
+		if err := write("describe-pods.txt", "describe", "pods", "--namespace", namespace); err != nil {
+			return paths, err
+		}
+		if err := write("events.txt", "get", "events", "--output", "wide", "--namespace", namespace, "--sort-by", "lastTimestamp"); err != nil {
+			return paths, err
+		}
+
+		pods, err := executor.RunProcessAndCaptureStdout(ctx, "kubectl", "get", "pods", "--namespace", namespace,
+			"--output", "jsonpath={.items[*].metadata.name}")
+		if err != nil {
+			continue
+		}
+		for _, pod := range strings.Fields(pods) {
+			if err := write(fmt.Sprintf("logs-%s.txt", pod), "logs", pod, "--namespace", namespace, "--all-containers", "--prefix"); err != nil {
+				return paths, err
+			}
+		}
+	}
+
+	return paths, nil
+}
@@ -0,0 +1,72 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPackageNamespaces(t *testing.T) {
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{
+				Name:      "web",
+				Charts:    []util.ZarfChart{{Name: "podinfo", Namespace: "podinfo"}},
+				Manifests: []util.ZarfManifest{{Name: "config"}, {Name: "ingress", Namespace: "ingress"}},
+			},
+			{
+				Name:           "data",
+				Charts:         []util.ZarfChart{{Name: "podinfo-db", Namespace: "podinfo"}},
+				DataInjections: []util.ZarfDataInjection{{Target: util.ZarfContainerTarget{Namespace: "data"}}},
+			},
+		},
+	}
+
+	assert.Equal(t, []string{"data", "ingress", "podinfo"}, packageNamespaces(zarfYaml))
+}
+
+func TestCollectArtifacts(t *testing.T) {
+	// kubectl echoes its arguments and lists a single pod
+	fakeCommands(t, map[string]string{
+		"kubectl": "case \"$*\" in\n*jsonpath*) echo web-0 ;;\n*) echo \"kubectl $*\" ;;\nesac",
+	})
+
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\ncomponents:\n  - name: web\n    charts:\n      - name: podinfo\n        namespace: podinfo\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	d := NewPackageDeployer()
+	d.ArtifactsDir = t.TempDir()
+	paths, err := d.collectArtifacts(context.Background(), "podinfo", packageDir)
+	require.NoError(t, err)
+
+	dir := filepath.Join(d.ArtifactsDir, "podinfo", "podinfo")
+	assert.Equal(t, []string{
+		filepath.Join(dir, "describe-pods.txt"),
+		filepath.Join(dir, "events.txt"),
+		filepath.Join(dir, "logs-web-0.txt"),
+	}, paths)
+
+	logs, err := os.ReadFile(filepath.Join(dir, "logs-web-0.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "kubectl logs web-0 --namespace podinfo --all-containers --prefix\n", string(logs))
+}
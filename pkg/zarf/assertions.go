@@ -0,0 +1,360 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// AssertionsFile is the name of the file next to zarf.yaml that defines the deployment
+// assertions of a package. Alternatively they are defined in an 'x-zt' block in zarf.yaml.
+const AssertionsFile = "zt.yaml"
+
+// Assertions are checks evaluated against the cluster after a package was deployed
+type Assertions struct {
+	Deployments []DeploymentAssertion `yaml:"deployments,omitempty" json:"deployments,omitempty"`
+	HTTP        []HTTPAssertion       `yaml:"http,omitempty" json:"http,omitempty"`
+	ConfigMaps  []ConfigMapAssertion  `yaml:"configMaps,omitempty" json:"configMaps,omitempty"`
+	Exec        []ExecAssertion       `yaml:"exec,omitempty" json:"exec,omitempty"`
+}
+
+// DeploymentAssertion expects a deployment to have the given number of ready replicas,
+// or all of its desired replicas if Replicas is not set
+type DeploymentAssertion struct {
+	Name      string `yaml:"name" json:"name"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Replicas  *int   `yaml:"replicas,omitempty" json:"replicas,omitempty"`
+}
+
+// HTTPAssertion is a probe expecting a request to a service port, forwarded with
+// 'kubectl port-forward', to return Status (200 if not set) within Timeout (a duration
+// such as "2m", 1m if not set). The request is retried with backoff until then.
+type HTTPAssertion struct {
+	Service   string `yaml:"service" json:"service"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Port      int    `yaml:"port" json:"port"`
+	Path      string `yaml:"path,omitempty" json:"path,omitempty"`
+	Status    int    `yaml:"status,omitempty" json:"status,omitempty"`
+	Timeout   string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
+}
+
+// ConfigMapAssertion expects a config map to contain the given keys
+type ConfigMapAssertion struct {
+	Name      string   `yaml:"name" json:"name"`
+	Namespace string   `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Keys      []string `yaml:"keys" json:"keys"`
+}
+
+// ExecAssertion expects a command run with 'kubectl exec' in Target (e.g. a pod or
+// deploy/<name>) to succeed and, if set, its output to contain Output
+type ExecAssertion struct {
+	Target    string   `yaml:"target" json:"target"`
+	Namespace string   `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Container string   `yaml:"container,omitempty" json:"container,omitempty"`
+	Command   []string `yaml:"command" json:"command"`
+	Output    string   `yaml:"output,omitempty" json:"output,omitempty"`
+}
+
+// LoadAssertions reads the assertions of the package in packagePath from zt.yaml or,
+// if there is none, from the 'x-zt' block of zarf.yaml. It returns nil if the package
+// defines no assertions.
+func LoadAssertions(packagePath string) (*Assertions, error) {
+	assertionsPath := filepath.Join(packagePath, AssertionsFile)
+	if util.FileExists(assertionsPath) {
+		content, err := os.ReadFile(assertionsPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", AssertionsFile, err)
+		}
+		assertions := &Assertions{}
+		if err := yaml.UnmarshalStrict(content, assertions); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", AssertionsFile, err)
+		}
+		return assertions, nil
+	}
+
+	content, err := os.ReadFile(filepath.Join(packagePath, "zarf.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zarf.yaml: %w", err)
+	}
+	extension := struct {
+		Assertions *Assertions `yaml:"x-zt"`
+	}{}
+	if err := yaml.Unmarshal(content, &extension); err != nil {
+		return nil, fmt.Errorf("failed to parse x-zt in zarf.yaml: %w", err)
+	}
+	return extension.Assertions, nil
+}
+
+// namespaceMapping resolves the namespace of an assertion. Assertions without a
+// namespace use the namespace of the package, and the namespace of a package that was
+// redirected to a test namespace is replaced by the test namespace.
+type namespaceMapping struct {
+	original string
+	deployed string
+}
+
+func (m namespaceMapping) resolve(namespace string) string {
+	if m.deployed != "" && (namespace == "" || namespace == m.original) {
+		return m.deployed
+	}
+	if namespace == "" {
+		return m.original
+	}
+	return namespace
+}
+
+// evaluateAssertions evaluates every assertion and returns one result per assertion
+func (d *PackageDeployer) evaluateAssertions(ctx context.Context, name string, assertions *Assertions, namespaces namespaceMapping) []ComponentTestResult {
+	var results []ComponentTestResult
+	add := func(id string, err error, success string) {
+		result := ComponentTestResult{ComponentName: id, Success: err == nil, Message: success}
+		if err != nil {
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	for _, assertion := range assertions.Deployments {
+		ready, err := d.assertDeployment(ctx, name, assertion, namespaces.resolve(assertion.Namespace))
+		add("deployment/"+assertion.Name, err, fmt.Sprintf("%d replicas ready", ready))
+	}
+	for _, assertion := range assertions.HTTP {
+		probe, err := d.assertHTTP(ctx, assertion, namespaces.resolve(assertion.Namespace))
+		add(fmt.Sprintf("http/%s:%d%s", assertion.Service, assertion.Port, assertion.Path), err,
+			fmt.Sprintf("returned status %d in %s after %d attempt(s)", probe.status, probe.latency.Round(time.Millisecond), probe.attempts))
+		results[len(results)-1].Status = probe.status
+		results[len(results)-1].Latency = probe.latency
+	}
+	for _, assertion := range assertions.ConfigMaps {
+		err := d.assertConfigMap(ctx, name, assertion, namespaces.resolve(assertion.Namespace))
+		add("configmap/"+assertion.Name, err, fmt.Sprintf("contains keys %v", assertion.Keys))
+	}
+	for _, assertion := range assertions.Exec {
+		err := d.assertExec(ctx, name, assertion, namespaces.resolve(assertion.Namespace))
+		add(fmt.Sprintf("exec/%s: %s", assertion.Target, strings.Join(assertion.Command, " ")), err, "succeeded")
+	}
+	return results
+}
+
+func namespaceArgs(namespace string) []string {
+	if namespace == "" {
+		return nil
+	}
+	return []string{"--namespace", namespace}
+}
+
+func (d *PackageDeployer) assertDeployment(ctx context.Context, name string, assertion DeploymentAssertion, namespace string) (int, error) {
+	output, err := d.run(ctx, name, "", "kubectl", "get", "deployment", assertion.Name, namespaceArgs(namespace),
+		"--output", "jsonpath={.spec.replicas} {.status.readyReplicas}")
+	if err != nil {
+		return 0, err
+	}
+	fields := strings.Fields(output)
+	desired, ready := 0, 0
+	if len(fields) > 0 {
+		desired, _ = strconv.Atoi(fields[0])
+	}
+	if len(fields) > 1 {
+		ready, _ = strconv.Atoi(fields[1])
+	}
+
+	expected := desired
+	if assertion.Replicas != nil {
+		expected = *assertion.Replicas
+	}
+	if ready != expected {
+		return ready, fmt.Errorf("expected %d ready replicas, got %d", expected, ready)
+	}
+	return ready, nil
+}
+
+func (d *PackageDeployer) assertConfigMap(ctx context.Context, name string, assertion ConfigMapAssertion, namespace string) error {
+	output, err := d.run(ctx, name, "", "kubectl", "get", "configmap", assertion.Name, namespaceArgs(namespace), "--output", "json")
+	if err != nil {
+		return err
+	}
+	configMap := struct {
+		Data       map[string]string `json:"data"`
+		BinaryData map[string]string `json:"binaryData"`
+	}{}
+	if err := json.Unmarshal([]byte(output), &configMap); err != nil {
+		return fmt.Errorf("failed to parse config map: %w", err)
+	}
+
+	var missing []string
+	for _, key := range assertion.Keys {
+		_, inData := configMap.Data[key]
+		_, inBinaryData := configMap.BinaryData[key]
+		if !inData && !inBinaryData {
+			missing = append(missing, key)
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("missing keys %v", missing)
+	}
+	return nil
+}
+
+func (d *PackageDeployer) assertExec(ctx context.Context, name string, assertion ExecAssertion, namespace string) error {
+	args := []interface{}{"exec", assertion.Target, namespaceArgs(namespace)}
+	if assertion.Container != "" {
+		args = append(args, "--container", assertion.Container)
+	}
+	args = append(args, "--", assertion.Command)
+	output, err := d.run(ctx, name, "", "kubectl", args...)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(output, assertion.Output) {
+		return fmt.Errorf("output %q does not contain %q", output, assertion.Output)
+	}
+	return nil
+}
+
+const (
+	defaultProbeTimeout = time.Minute
+	maxProbeInterval    = 10 * time.Second
+)
+
+// probeInterval is the delay before the first retry of a failed probe, it doubles
+// with every further retry up to maxProbeInterval
+var probeInterval = time.Second
+
+// probeResult is the outcome of the last request of an HTTP probe
+type probeResult struct {
+	status   int
+	latency  time.Duration
+	attempts int
+}
+
+func (d *PackageDeployer) assertHTTP(ctx context.Context, assertion HTTPAssertion, namespace string) (probeResult, error) {
+	timeout := defaultProbeTimeout
+	if assertion.Timeout != "" {
+		parsed, err := time.ParseDuration(assertion.Timeout)
+		if err != nil {
+			return probeResult{}, fmt.Errorf("invalid timeout %q: %w", assertion.Timeout, err)
+		}
+		timeout = parsed
+	}
+	expected := assertion.Status
+	if expected == 0 {
+		expected = http.StatusOK
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	result := probeResult{}
+	interval := probeInterval
+	for {
+		result.attempts++
+		status, latency, err := d.probeHTTP(ctx, assertion, namespace)
+		result.status, result.latency = status, latency
+		if err == nil && status == expected {
+			return result, nil
+		}
+		if err == nil {
+			err = fmt.Errorf("expected status %d, got %d", expected, status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return result, fmt.Errorf("%w (%d attempt(s) within %s)", err, result.attempts, timeout)
+		case <-time.After(interval):
+		}
+		interval = min(interval*2, maxProbeInterval)
+	}
+}
+
+// probeHTTP forwards a local port to the service and sends a single request, returning
+// its status and latency
+func (d *PackageDeployer) probeHTTP(ctx context.Context, assertion HTTPAssertion, namespace string) (int, time.Duration, error) {
+	localPort, stop, err := d.portForward(ctx, namespace, "service/"+assertion.Service, assertion.Port)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer stop()
+
+	url := fmt.Sprintf("http://127.0.0.1:%d/%s", localPort, strings.TrimPrefix(assertion.Path, "/"))
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	start := time.Now()
+	response, err := http.DefaultClient.Do(request)
+	latency := time.Since(start)
+	if err != nil {
+		return 0, latency, fmt.Errorf("request failed: %w", err)
+	}
+	response.Body.Close()
+	return response.StatusCode, latency, nil
+}
+
+var forwardingPattern = regexp.MustCompile(`Forwarding from 127\.0\.0\.1:(\d+)`)
+
+// portForward runs 'kubectl port-forward' to target on a random local port, which is
+// returned once forwarding has started. The returned function stops forwarding.
+func (d *PackageDeployer) portForward(ctx context.Context, namespace, target string, port int) (int, func(), error) {
+	ctx, cancel := context.WithCancel(ctx)
+	cmd, err := d.executor().CreateProcess(ctx, "kubectl", "port-forward", target,
+		fmt.Sprintf(":%d", port), namespaceArgs(namespace))
+	if err != nil {
+		cancel()
+		return 0, nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		cancel()
+		return 0, nil, err
+	}
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		cancel()
+		return 0, nil, fmt.Errorf("failed to start port-forward: %w", err)
+	}
+	stop := func() {
+		cancel()
+		_ = cmd.Wait()
+	}
+
+	scanner := bufio.NewScanner(stdout)
+	for scanner.Scan() {
+		if match := forwardingPattern.FindStringSubmatch(scanner.Text()); match != nil {
+			localPort, _ := strconv.Atoi(match[1])
+			// Keep draining the output so kubectl does not block on a full pipe
+			go func() {
+				for scanner.Scan() {
+				}
+			}()
+			return localPort, stop, nil
+		}
+	}
+	stop()
+	return 0, nil, fmt.Errorf("port-forward to %s:%d failed: %s", target, port, strings.TrimSpace(stderr.String()))
+}
@@ -0,0 +1,169 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAssertions(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+x-zt:
+  deployments:
+    - name: podinfo
+      replicas: 2
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	assertions, err := LoadAssertions(packageDir)
+	require.NoError(t, err)
+	require.NotNil(t, assertions)
+	require.Len(t, assertions.Deployments, 1)
+	assert.Equal(t, "podinfo", assertions.Deployments[0].Name)
+	assert.Equal(t, 2, *assertions.Deployments[0].Replicas)
+
+	// zt.yaml takes precedence over x-zt
+	ztYaml := `configMaps:
+  - name: podinfo-config
+    keys: [color]
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, AssertionsFile), []byte(ztYaml), 0644))
+	assertions, err = LoadAssertions(packageDir)
+	require.NoError(t, err)
+	assert.Empty(t, assertions.Deployments)
+	assert.Equal(t, []ConfigMapAssertion{{Name: "podinfo-config", Keys: []string{"color"}}}, assertions.ConfigMaps)
+
+	// Unknown fields in zt.yaml are rejected
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, AssertionsFile), []byte("deployment: []\n"), 0644))
+	_, err = LoadAssertions(packageDir)
+	assert.Error(t, err)
+
+	// Packages without assertions
+	noAssertionsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(noAssertionsDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\n"), 0644))
+	assertions, err = LoadAssertions(noAssertionsDir)
+	require.NoError(t, err)
+	assert.Nil(t, assertions)
+}
+
+func TestNamespaceMapping(t *testing.T) {
+	redirected := namespaceMapping{original: "podinfo", deployed: "zt-test-1"}
+	assert.Equal(t, "zt-test-1", redirected.resolve(""))
+	assert.Equal(t, "zt-test-1", redirected.resolve("podinfo"))
+	assert.Equal(t, "monitoring", redirected.resolve("monitoring"))
+
+	multiple := namespaceMapping{}
+	assert.Equal(t, "", multiple.resolve(""))
+	assert.Equal(t, "monitoring", multiple.resolve("monitoring"))
+}
+
+func TestEvaluateAssertions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/healthz" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	t.Setenv("ZT_TEST_PORT", serverURL.Port())
+
+	fakeCommands(t, map[string]string{
+		"kubectl": `case "$1 $2" in
+"get deployment") echo "2 1" ;;
+"get configmap") echo '{"data":{"color":"blue"}}' ;;
+"exec "*) echo pong ;;
+"port-forward "*) echo "Forwarding from 127.0.0.1:$ZT_TEST_PORT -> 9898"; sleep 30 ;;
+esac`,
+	})
+
+	one := 1
+	assertions := &Assertions{
+		Deployments: []DeploymentAssertion{{Name: "podinfo"}, {Name: "podinfo-redis", Replicas: &one}},
+		HTTP:        []HTTPAssertion{{Service: "podinfo", Port: 9898, Path: "/healthz"}, {Service: "podinfo", Port: 9898, Path: "/missing", Timeout: "50ms"}},
+		ConfigMaps:  []ConfigMapAssertion{{Name: "podinfo", Keys: []string{"color", "size"}}},
+		Exec:        []ExecAssertion{{Target: "deploy/podinfo", Command: []string{"echo", "ping"}, Output: "pong"}},
+	}
+
+	probeInterval = 10 * time.Millisecond
+	defer func() { probeInterval = time.Second }()
+
+	d := NewPackageDeployer()
+	results := d.evaluateAssertions(context.Background(), "podinfo", assertions, namespaceMapping{})
+	require.Len(t, results, 6)
+
+	// Latencies vary, so the HTTP results are checked separately
+	for _, result := range results[2:4] {
+		assert.Greater(t, result.Latency, time.Duration(0))
+	}
+	assert.Equal(t, 200, results[2].Status)
+	assert.Regexp(t, `^returned status 200 in \S+ after 1 attempt\(s\)$`, results[2].Message)
+	assert.Equal(t, 404, results[3].Status)
+	assert.Regexp(t, `^expected status 200, got 404 \(\d+ attempt\(s\) within 50ms\)$`, results[3].Message)
+	results[2].Latency, results[2].Message = 0, ""
+	results[3].Latency, results[3].Message = 0, ""
+
+	assert.Equal(t, []ComponentTestResult{
+		{ComponentName: "deployment/podinfo", Success: false, Message: "expected 2 ready replicas, got 1"},
+		{ComponentName: "deployment/podinfo-redis", Success: true, Message: "1 replicas ready"},
+		{ComponentName: "http/podinfo:9898/healthz", Success: true, Status: 200},
+		{ComponentName: "http/podinfo:9898/missing", Success: false, Status: 404},
+		{ComponentName: "configmap/podinfo", Success: false, Message: "missing keys [size]"},
+		{ComponentName: "exec/deploy/podinfo: echo ping", Success: true, Message: "succeeded"},
+	}, results)
+}
+
+func TestHTTPProbeRetries(t *testing.T) {
+	// The service becomes available on the third request
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer server.Close()
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	t.Setenv("ZT_TEST_PORT", serverURL.Port())
+	fakeCommands(t, map[string]string{
+		"kubectl": `echo "Forwarding from 127.0.0.1:$ZT_TEST_PORT -> 9898"; sleep 30`,
+	})
+
+	probeInterval = time.Millisecond
+	defer func() { probeInterval = time.Second }()
+
+	d := NewPackageDeployer()
+	probe, err := d.assertHTTP(context.Background(), HTTPAssertion{Service: "podinfo", Port: 9898, Timeout: "5s"}, "podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, 200, probe.status)
+	assert.Equal(t, 3, probe.attempts)
+
+	_, err = d.assertHTTP(context.Background(), HTTPAssertion{Service: "podinfo", Port: 9898, Timeout: "soon"}, "podinfo")
+	assert.EqualError(t, err, `invalid timeout "soon": time: invalid duration "soon"`)
+}
@@ -0,0 +1,140 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+const baselineVersion = 1
+
+// Baseline records known findings so that only new findings fail a lint run
+type Baseline struct {
+	Version  int             `json:"version"`
+	Findings []BaselineEntry `json:"findings"`
+}
+
+// BaselineEntry identifies a known finding of a package. Findings that point at a
+// position within a file, which moves as the file is edited, are identified by the
+// file and their message without the position.
+type BaselineEntry struct {
+	Package string `json:"package"`
+	RuleID  string `json:"ruleId"`
+	File    string `json:"file,omitempty"`
+	Message string `json:"message"`
+}
+
+// yamlLintPosition matches the file and position yaml-lint findings start with
+var yamlLintPosition = regexp.MustCompile(`^YAML lint (.+?):\d+:\d+: `)
+
+// NewBaseline records all error and warning findings of the given results
+func NewBaseline(results []*ValidationResult) *Baseline {
+	baseline := &Baseline{Version: baselineVersion, Findings: []BaselineEntry{}}
+	for _, result := range results {
+		for _, finding := range result.Findings {
+			if finding.Severity == SeverityInfo {
+				continue
+			}
+			baseline.Findings = append(baseline.Findings, newBaselineEntry(result.PackagePath, finding))
+		}
+	}
+	return baseline
+}
+
+// LoadBaseline reads a baseline file written by Write
+func LoadBaseline(path string) (*Baseline, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read baseline: %w", err)
+	}
+
+	baseline := &Baseline{}
+	if err := json.Unmarshal(content, baseline); err != nil {
+		return nil, fmt.Errorf("could not parse baseline %s: %w", path, err)
+	}
+	if baseline.Version != baselineVersion {
+		return nil, fmt.Errorf("unsupported baseline version %d in %s", baseline.Version, path)
+	}
+	return baseline, nil
+}
+
+// Write saves the baseline to path
+func (b *Baseline) Write(path string) error {
+	content, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(content, '\n'), 0644)
+}
+
+// Apply removes the findings recorded in the baseline from results. Each baseline
+// entry suppresses at most one finding, so new occurrences of a known finding are
+// still reported. A result whose errors are all suppressed becomes valid again.
+func (b *Baseline) Apply(results []*ValidationResult) {
+	known := make(map[BaselineEntry]int)
+	for _, entry := range b.Findings {
+		known[entry]++
+	}
+
+	for _, result := range results {
+		var findings []Finding
+		var errs, warnings []string
+		hadErrors := false
+		for _, finding := range result.Findings {
+			if finding.Severity == SeverityError {
+				hadErrors = true
+			}
+
+			key := newBaselineEntry(result.PackagePath, finding)
+			if finding.Severity != SeverityInfo && known[key] > 0 {
+				known[key]--
+				result.Suppressed++
+				continue
+			}
+
+			findings = append(findings, finding)
+			switch finding.Severity {
+			case SeverityError:
+				errs = append(errs, finding.Message)
+			case SeverityWarning:
+				warnings = append(warnings, finding.Message)
+			}
+		}
+
+		result.Findings = findings
+		result.Errors = errs
+		result.Warnings = warnings
+		if hadErrors && len(errs) == 0 {
+			result.Valid = true
+		}
+	}
+}
+
+func newBaselineEntry(packagePath string, finding Finding) BaselineEntry {
+	entry := BaselineEntry{
+		Package: filepath.ToSlash(filepath.Clean(packagePath)),
+		RuleID:  finding.RuleID,
+		Message: finding.Message,
+	}
+	if finding.RuleID == "yaml-lint" {
+		entry.File = finding.File
+		entry.Message = yamlLintPosition.ReplaceAllString(finding.Message, "YAML lint $1: ")
+	}
+	return entry
+}
@@ -0,0 +1,87 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBaseline(t *testing.T) {
+	legacy := &ValidationResult{PackagePath: "packages/legacy", Valid: true}
+	legacy.AddError("duplicate-component", "Duplicate component name: app")
+	legacy.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+	legacy.AddInfo("zarf-lint", "Validated using Zarf CLI")
+
+	path := filepath.Join(t.TempDir(), ".zt-baseline.json")
+	require.NoError(t, NewBaseline([]*ValidationResult{legacy}).Write(path))
+
+	baseline, err := LoadBaseline(path)
+	require.NoError(t, err)
+	assert.Len(t, baseline.Findings, 2)
+
+	// The same findings plus a new occurrence of a known warning and a new error
+	current := &ValidationResult{PackagePath: "./packages/legacy", Valid: true}
+	current.AddError("duplicate-component", "Duplicate component name: app")
+	current.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+	current.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+	current.AddInfo("zarf-lint", "Validated using Zarf CLI")
+
+	fixed := &ValidationResult{PackagePath: "packages/legacy-2", Valid: true}
+	fixed.AddError("duplicate-component", "Duplicate component name: app")
+
+	baseline.Apply([]*ValidationResult{current, fixed})
+
+	assert.True(t, current.Valid)
+	assert.Equal(t, 2, current.Suppressed)
+	assert.Equal(t, []Finding{
+		{RuleID: "image-pinning", Severity: SeverityWarning, Message: "Image not pinned with digest - nginx:1.25"},
+		{RuleID: "zarf-lint", Severity: SeverityInfo, Message: "Validated using Zarf CLI"},
+	}, current.Findings)
+	assert.Empty(t, current.Errors)
+	assert.Len(t, current.Warnings, 1)
+
+	assert.False(t, fixed.Valid)
+	assert.Equal(t, 0, fixed.Suppressed)
+}
+
+func TestBaselineYamlLint(t *testing.T) {
+	yamlLint := func(file string, line int, message string) Finding {
+		return Finding{RuleID: "yaml-lint", Severity: SeverityWarning, File: file, Line: line, Column: 1,
+			Message: fmt.Sprintf("YAML lint %s:%d:1: %s", file, line, message)}
+	}
+	legacy := &ValidationResult{PackagePath: "packages/legacy", Valid: true}
+	legacy.AddFinding(yamlLint("manifests/app.yaml", 3, "too many blank lines (1 > 0) (empty-lines)"))
+
+	path := filepath.Join(t.TempDir(), ".zt-baseline.json")
+	require.NoError(t, NewBaseline([]*ValidationResult{legacy}).Write(path))
+	baseline, err := LoadBaseline(path)
+	require.NoError(t, err)
+	assert.Equal(t, []BaselineEntry{{Package: "packages/legacy", RuleID: "yaml-lint", File: "manifests/app.yaml",
+		Message: "YAML lint manifests/app.yaml: too many blank lines (1 > 0) (empty-lines)"}}, baseline.Findings)
+
+	// Known problems stay suppressed when lines are added above them, the same problem
+	// in another file is new
+	current := &ValidationResult{PackagePath: "packages/legacy", Valid: true}
+	current.AddFinding(yamlLint("manifests/app.yaml", 7, "too many blank lines (1 > 0) (empty-lines)"))
+	current.AddFinding(yamlLint("manifests/db.yaml", 3, "too many blank lines (1 > 0) (empty-lines)"))
+	baseline.Apply([]*ValidationResult{current})
+	assert.Equal(t, 1, current.Suppressed)
+	assert.Equal(t, []Finding{yamlLint("manifests/db.yaml", 3, "too many blank lines (1 > 0) (empty-lines)")}, current.Findings)
+}
@@ -0,0 +1,197 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// benchmarkBaselineRuns is the number of most recent runs of a deployment its baseline
+// deploy time is averaged over
+const benchmarkBaselineRuns = 10
+
+// pulledImage matches the message of the event of a pulled image, e.g. 'Successfully
+// pulled image "nginx:1.27" in 2.365s (2.365s including waiting)'
+var pulledImage = regexp.MustCompile(`^Successfully pulled image "[^"]*" in ([0-9.]+[a-zµ]+(?:[0-9.]+[a-zµ]+)*)`)
+
+// Benchmark holds the timings of a deployment
+type Benchmark struct {
+	Deploy    time.Duration            // Duration of 'zarf package deploy'
+	ImagePull time.Duration            // Time spent pulling images according to the events of the deployed namespaces
+	Ready     map[string]time.Duration // Time from the start of the deployment until each workload was ready
+	Baseline  time.Duration            // Average deploy time of previous runs, zero without history
+}
+
+// BenchmarkThresholds turn slow deployments into failures. Zero values disable a
+// threshold.
+type BenchmarkThresholds struct {
+	MaxDeployTime    time.Duration
+	MaxImagePullTime time.Duration
+	MaxReadyTime     time.Duration // Applies to every workload
+	// MaxRegression is the percentage by which the deploy time may exceed the baseline
+	MaxRegression float64
+}
+
+// Check returns a message for every threshold the benchmark exceeds
+func (t BenchmarkThresholds) Check(benchmark *Benchmark) []string {
+	var exceeded []string
+	if t.MaxDeployTime > 0 && benchmark.Deploy > t.MaxDeployTime {
+		exceeded = append(exceeded, fmt.Sprintf("Deploy time %s exceeds the maximum of %s", formatBenchmark(benchmark.Deploy), t.MaxDeployTime))
+	}
+	if t.MaxImagePullTime > 0 && benchmark.ImagePull > t.MaxImagePullTime {
+		exceeded = append(exceeded, fmt.Sprintf("Image pull time %s exceeds the maximum of %s", formatBenchmark(benchmark.ImagePull), t.MaxImagePullTime))
+	}
+	if t.MaxReadyTime > 0 {
+		var workloads []string
+		for workload := range benchmark.Ready {
+			workloads = append(workloads, workload)
+		}
+		sort.Strings(workloads)
+		for _, workload := range workloads {
+			if ready := benchmark.Ready[workload]; ready > t.MaxReadyTime {
+				exceeded = append(exceeded, fmt.Sprintf("Time to ready of %s %s exceeds the maximum of %s", workload, formatBenchmark(ready), t.MaxReadyTime))
+			}
+		}
+	}
+	if t.MaxRegression > 0 && benchmark.Baseline > 0 {
+		limit := time.Duration(float64(benchmark.Baseline) * (1 + t.MaxRegression/100))
+		if benchmark.Deploy > limit {
+			exceeded = append(exceeded, fmt.Sprintf("Deploy time %s is more than %g%% above the baseline of %s",
+				formatBenchmark(benchmark.Deploy), t.MaxRegression, formatBenchmark(benchmark.Baseline)))
+		}
+	}
+	return exceeded
+}
+
+func formatBenchmark(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// BenchmarkBaseline is the average deploy time of deployments in previous runs, by
+// deploymentKey
+type BenchmarkBaseline map[string]time.Duration
+
+// NewBenchmarkBaseline averages the deploy times of the most recent successful
+// deployments with a benchmark in runs, which must be ordered oldest first
+func NewBenchmarkBaseline(runs []HistoryRun) BenchmarkBaseline {
+	deployTimes := map[string][]float64{}
+	for _, run := range runs {
+		if run.Install == nil {
+			continue
+		}
+		for _, deployment := range run.Install.Deployments {
+			if !deployment.Success || deployment.Benchmark == nil {
+				continue
+			}
+			key := deploymentKey(deployment.Path, deployment.Cluster, deployment.VariableSet, deployment.Components)
+			deployTimes[key] = append(deployTimes[key], deployment.Benchmark.Deploy)
+		}
+	}
+
+	baseline := BenchmarkBaseline{}
+	for key, seconds := range deployTimes {
+		seconds = seconds[max(0, len(seconds)-benchmarkBaselineRuns):]
+		total := 0.0
+		for _, s := range seconds {
+			total += s
+		}
+		baseline[key] = time.Duration(total / float64(len(seconds)) * float64(time.Second))
+	}
+	return baseline
+}
+
+// deploymentKey identifies a deployment across runs by its package, cluster and
+// configuration
+func deploymentKey(path, cluster, variableSet, components string) string {
+	return strings.Join([]string{path, cluster, variableSet, components}, "\x00")
+}
+
+// collectBenchmark adds the image pull time and the time to ready of every workload
+// in the given namespaces, since start, to benchmark
+func (d *PackageDeployer) collectBenchmark(ctx context.Context, name string, namespaces []string, start time.Time, benchmark *Benchmark) error {
+	benchmark.Ready = map[string]time.Duration{}
+	for _, namespace := range namespaces {
+		output, err := d.run(ctx, name, "", "kubectl", "get", "events", namespaceArgs(namespace),
+			"--field-selector", "reason=Pulled", "--output", `jsonpath={range .items[*]}{.message}{"\n"}{end}`)
+		if err != nil {
+			return fmt.Errorf("failed to list events: %w", err)
+		}
+		benchmark.ImagePull += parseImagePullTime(output)
+
+		workloads, err := d.listWorkloads(ctx, name, namespace)
+		if err != nil {
+			return err
+		}
+		for _, w := range workloads {
+			ready, err := d.workloadReadyTime(ctx, name, namespace, w)
+			if err != nil {
+				return err
+			}
+			if !ready.IsZero() {
+				benchmark.Ready[w.String()] = max(0, ready.Sub(start))
+			}
+		}
+	}
+	return nil
+}
+
+// parseImagePullTime sums the pull times in the messages of Pulled events, one per
+// line. Images that were present already take no time.
+func parseImagePullTime(messages string) time.Duration {
+	var total time.Duration
+	for _, message := range strings.Split(messages, "\n") {
+		match := pulledImage.FindStringSubmatch(strings.TrimSpace(message))
+		if match == nil {
+			continue
+		}
+		if pull, err := time.ParseDuration(match[1]); err == nil {
+			total += pull
+		}
+	}
+	return total
+}
+
+// workloadReadyTime returns when the last pod of a workload became ready, or the zero
+// time if any of its pods is not ready
+func (d *PackageDeployer) workloadReadyTime(ctx context.Context, name, namespace string, w workload) (time.Time, error) {
+	output, err := d.run(ctx, name, "", "kubectl", "get", "pods", "--selector", w.Selector, namespaceArgs(namespace), "--output",
+		`jsonpath={range .items[*]}{.status.conditions[?(@.type=="Ready")].status}{" "}{.status.conditions[?(@.type=="Ready")].lastTransitionTime}{"\n"}{end}`)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to list pods: %w", err)
+	}
+	var last time.Time
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 || fields[0] != "True" {
+			return time.Time{}, nil
+		}
+		ready, err := time.Parse(time.RFC3339, fields[1])
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid ready time %q of a pod of %s: %w", fields[1], w, err)
+		}
+		if ready.After(last) {
+			last = ready
+		}
+	}
+	return last, nil
+}
@@ -0,0 +1,103 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseImagePullTime(t *testing.T) {
+	messages := `Successfully pulled image "ghcr.io/stefanprodan/podinfo:6.4.0" in 2.5s (2.5s including waiting)
+Container image "redis:7" already present on machine
+Successfully pulled image "busybox:1.36" in 1m2.25s (1m3s including waiting). Image size: 2160406 bytes.
+Successfully pulled image "nginx:1.27" in 750ms`
+	assert.Equal(t, 65500*time.Millisecond, parseImagePullTime(messages))
+}
+
+func TestBenchmarkThresholdsCheck(t *testing.T) {
+	benchmark := &Benchmark{
+		Deploy:    3 * time.Minute,
+		ImagePull: time.Minute,
+		Ready:     map[string]time.Duration{"deployment/podinfo": 90 * time.Second, "statefulset/redis": 30 * time.Second},
+		Baseline:  time.Minute,
+	}
+	assert.Empty(t, BenchmarkThresholds{}.Check(benchmark))
+
+	thresholds := BenchmarkThresholds{
+		MaxDeployTime:    5 * time.Minute,
+		MaxImagePullTime: 30 * time.Second,
+		MaxReadyTime:     time.Minute,
+		MaxRegression:    50,
+	}
+	assert.Equal(t, []string{
+		"Image pull time 1m0s exceeds the maximum of 30s",
+		"Time to ready of deployment/podinfo 1m30s exceeds the maximum of 1m0s",
+		"Deploy time 3m0s is more than 50% above the baseline of 1m0s",
+	}, thresholds.Check(benchmark))
+
+	// Without a baseline, regressions are not checked
+	benchmark.Baseline = 0
+	thresholds.MaxDeployTime = 2 * time.Minute
+	assert.Equal(t, []string{
+		"Deploy time 3m0s exceeds the maximum of 2m0s",
+		"Image pull time 1m0s exceeds the maximum of 30s",
+		"Time to ready of deployment/podinfo 1m30s exceeds the maximum of 1m0s",
+	}, thresholds.Check(benchmark))
+}
+
+func TestNewBenchmarkBaseline(t *testing.T) {
+	run := func(deployments ...DeploymentReport) HistoryRun {
+		return HistoryRun{Install: &InstallReport{Deployments: deployments}}
+	}
+	runs := []HistoryRun{
+		{Lint: &LintReport{}},
+		run(DeploymentReport{Path: "packages/podinfo", Success: true, Benchmark: &BenchmarkReport{Deploy: 60}}),
+		run(DeploymentReport{Path: "packages/podinfo", Success: true, Benchmark: &BenchmarkReport{Deploy: 90}},
+			DeploymentReport{Path: "packages/podinfo", Cluster: "k8s-1.29", Success: true, Benchmark: &BenchmarkReport{Deploy: 30}}),
+		// Failed deployments and deployments without a benchmark are ignored
+		run(DeploymentReport{Path: "packages/podinfo", Success: false, Benchmark: &BenchmarkReport{Deploy: 600}},
+			DeploymentReport{Path: "packages/redis", Success: true}),
+	}
+
+	assert.Equal(t, BenchmarkBaseline{
+		deploymentKey("packages/podinfo", "", "", ""):         75 * time.Second,
+		deploymentKey("packages/podinfo", "k8s-1.29", "", ""): 30 * time.Second,
+	}, NewBenchmarkBaseline(runs))
+}
+
+func TestCollectBenchmark(t *testing.T) {
+	start := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	fakeCommands(t, map[string]string{
+		"kubectl": `case "$*" in
+"get events"*) echo 'Successfully pulled image "podinfo:6.4.0" in 4s (4s including waiting)' ;;
+"get deployments,statefulsets,daemonsets"*) echo '{"items": [` +
+			`{"kind": "Deployment", "metadata": {"name": "podinfo"}, "spec": {"replicas": 2, "selector": {"matchLabels": {"app": "podinfo"}}}},` +
+			`{"kind": "StatefulSet", "metadata": {"name": "redis"}, "spec": {"selector": {"matchLabels": {"app": "redis"}}}}]}' ;;
+"get pods --selector app=podinfo"*) printf 'True 2024-05-01T12:00:20Z\nTrue 2024-05-01T12:00:35Z\n' ;;
+"get pods --selector app=redis"*) echo 'False 2024-05-01T12:00:10Z' ;;
+esac`,
+	})
+
+	d := NewPackageDeployer()
+	benchmark := &Benchmark{}
+	require.NoError(t, d.collectBenchmark(context.Background(), "podinfo", []string{"podinfo"}, start, benchmark))
+	assert.Equal(t, 4*time.Second, benchmark.ImagePull)
+	assert.Equal(t, map[string]time.Duration{"deployment/podinfo": 35 * time.Second}, benchmark.Ready)
+}
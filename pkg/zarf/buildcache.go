@@ -0,0 +1,164 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// buildCacheVersion is part of the build cache key and must be changed when the way
+// packages are built changes, so that packages built by earlier versions are not used
+const buildCacheVersion = "build-v1"
+
+// isPackageTarball reports whether name is a package or init package built by 'zarf
+// package create'
+func isPackageTarball(name string) bool {
+	return (strings.HasPrefix(name, "zarf-package-") || strings.HasPrefix(name, "zarf-init-")) && strings.HasSuffix(name, ".tar.zst")
+}
+
+// findPackageTarball returns the package built into dir, or an empty string if there
+// is none
+func findPackageTarball(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if isPackageTarball(entry.Name()) {
+			return filepath.Join(dir, entry.Name())
+		}
+	}
+	return ""
+}
+
+// buildCacheKey returns the key of the package build in the build cache, a hash of the
+// files of the package, the local paths outside of it referenced by its components and
+// the packages they import, and the given build inputs such as the zarf version and
+// build arguments. Packages built into the package directory and Git metadata are not
+// part of the key.
+func buildCacheKey(packagePath string, zarfYaml *util.ZarfYaml, inputs ...string) (string, error) {
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%s\x00", buildCacheVersion)
+	for _, input := range inputs {
+		fmt.Fprintf(hash, "%s\x00", input)
+	}
+
+	references := localReferences(zarfYaml)
+	// Packages imported directly or through other imports may reference files outside
+	// of the package themselves. Import cycles make the build fail, which is not cached.
+	imports, _ := resolveImports(packagePath)
+	for _, importDir := range imports {
+		imported, err := util.ReadZarfYaml(filepath.Join(importDir, "zarf.yaml"))
+		if err != nil {
+			continue
+		}
+		for _, reference := range localReferences(imported) {
+			if rel, err := filepath.Rel(packagePath, filepath.Join(importDir, reference)); err == nil {
+				references = append(references, rel)
+			}
+		}
+	}
+
+	paths := []string{"."}
+	seen := map[string]bool{}
+	for _, path := range references {
+		path = filepath.Clean(path)
+		if (path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator))) && !seen[path] {
+			seen[path] = true
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		root := filepath.Join(packagePath, path)
+		err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() {
+				if entry.Name() == ".git" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if isPackageTarball(entry.Name()) || !entry.Type().IsRegular() {
+				return nil
+			}
+			rel, err := filepath.Rel(packagePath, file)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(hash, "%s\x00", filepath.ToSlash(rel))
+			f, err := os.Open(file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			_, err = io.Copy(hash, f)
+			return err
+		})
+		// Missing references make the build fail, which is not cached
+		if err != nil && !os.IsNotExist(err) {
+			return "", fmt.Errorf("failed to hash package files: %w", err)
+		}
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// localReferences returns the local paths referenced by the components of a package,
+// relative to the package directory
+func localReferences(zarfYaml *util.ZarfYaml) []string {
+	var paths []string
+	add := func(path string) {
+		if path != "" && !isRemoteReference(path) {
+			paths = append(paths, path)
+		}
+	}
+	for _, component := range zarfYaml.Components {
+		add(component.Import.Path)
+		for _, file := range component.Files {
+			add(file.Source)
+		}
+		for _, chart := range component.Charts {
+			add(chart.LocalPath)
+			for _, valuesFile := range chart.ValuesFiles {
+				add(valuesFile)
+			}
+		}
+		for _, manifest := range component.Manifests {
+			for _, file := range manifest.Files {
+				add(file)
+			}
+			for _, kustomization := range manifest.Kustomizations {
+				add(kustomization)
+			}
+		}
+		for _, injection := range component.DataInjections {
+			add(injection.Source)
+		}
+	}
+	return paths
+}
@@ -0,0 +1,102 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCacheKey(t *testing.T) {
+	dir := t.TempDir()
+	packageDir := filepath.Join(dir, "podinfo")
+	require.NoError(t, os.MkdirAll(filepath.Join(packageDir, "manifests"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "common"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "manifests", "deployment.yaml"), []byte("replicas: 1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common", "values.yaml"), []byte("color: blue\n"), 0644))
+	zarfYaml := &util.ZarfYaml{Components: []util.ZarfComponent{{
+		Name:   "podinfo",
+		Charts: []util.ZarfChart{{Name: "podinfo", ValuesFiles: []string{"../common/values.yaml"}}},
+	}}}
+
+	key, err := buildCacheKey(packageDir, zarfYaml, "v0.42.0")
+	require.NoError(t, err)
+
+	// Built packages do not change the key
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf-package-podinfo-amd64.tar.zst"), []byte("built"), 0644))
+	unchanged, err := buildCacheKey(packageDir, zarfYaml, "v0.42.0")
+	require.NoError(t, err)
+	assert.Equal(t, key, unchanged)
+
+	changed, err := buildCacheKey(packageDir, zarfYaml, "v0.43.0")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, changed, "the zarf version is part of the key")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "common", "values.yaml"), []byte("color: red\n"), 0644))
+	changed, err = buildCacheKey(packageDir, zarfYaml, "v0.42.0")
+	require.NoError(t, err)
+	assert.NotEqual(t, key, changed, "referenced files outside of the package are part of the key")
+
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "manifests", "deployment.yaml"), []byte("replicas: 2\n"), 0644))
+	changedAgain, err := buildCacheKey(packageDir, zarfYaml, "v0.42.0")
+	require.NoError(t, err)
+	assert.NotEqual(t, changed, changedAgain, "package files are part of the key")
+}
+
+func TestBuildPackageCache(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $*" >> "$ZT_TEST_CALLS"; touch "$6/zarf-package-podinfo-amd64.tar.zst"`,
+	})
+	packageDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\n"), 0644))
+	zarfYaml := &util.ZarfYaml{Kind: "ZarfPackageConfig"}
+
+	d := NewPackageDeployer()
+	d.BuildCacheDir = t.TempDir()
+	build := func() string {
+		tarball, cleanup, err := d.buildPackage(context.Background(), "podinfo", packageDir, "v0.42.0", zarfYaml)
+		require.NoError(t, err)
+		cleanup()
+		assert.FileExists(t, tarball, "cached packages are kept")
+		return tarball
+	}
+
+	tarball := build()
+	assert.Equal(t, d.BuildCacheDir, filepath.Dir(filepath.Dir(tarball)))
+	assert.Equal(t, tarball, build())
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, 1, strings.Count(string(content), "zarf package create"), "unchanged packages are built once")
+
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\n# changed\n"), 0644))
+	assert.NotEqual(t, tarball, build())
+	content, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(string(content), "zarf package create"))
+
+	entries, err := os.ReadDir(packageDir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1, "nothing is built into the package directory")
+}
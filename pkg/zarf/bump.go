@@ -0,0 +1,202 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/util/yamledit"
+)
+
+// Bumper increments the versions of packages in their zarf.yaml. Versions are bumped
+// from the version of the package on the base revision, see baseRef, so bumping a
+// package twice does not increment its version twice.
+type Bumper struct {
+	Remote       string
+	TargetBranch string
+	Since        string
+
+	// Level is BumpMajor, BumpMinor or BumpPatch. If empty, packages get the smallest
+	// bump the version policy accepts: minor when components or images were added,
+	// major when components were removed and RequireMajorBumpOnRemoval is set, patch
+	// otherwise.
+	Level                     string
+	RequireMajorBumpOnRemoval bool
+
+	// Changelog is added as an entry of the new version to the CHANGELOG.md of every
+	// bumped package when set
+	Changelog string
+}
+
+// BumpResult is the outcome of bumping a package
+type BumpResult struct {
+	Path     string `yaml:"path" json:"path"`
+	Previous string `yaml:"previous" json:"previous"`                   // Version of the package before bumping
+	Version  string `yaml:"version" json:"version"`                     // Version of the package after bumping
+	Level    string `yaml:"level,omitempty" json:"level,omitempty"`     // Level of the bump, empty if skipped
+	Skipped  string `yaml:"skipped,omitempty" json:"skipped,omitempty"` // Why the package was not bumped
+}
+
+// NewBumper creates a bumper comparing against origin/main
+func NewBumper() *Bumper {
+	return &Bumper{Remote: "origin", TargetBranch: "main"}
+}
+
+// Bump bumps the version of the package at packagePath. Packages already bumped far
+// enough since the base revision are skipped.
+func (b *Bumper) Bump(ctx context.Context, packagePath string) (*BumpResult, error) {
+	zarfYamlPath := filepath.Join(packagePath, "zarf.yaml")
+	current, err := util.ReadZarfYaml(zarfYamlPath)
+	if err != nil {
+		return nil, err
+	}
+	result := &BumpResult{Path: packagePath, Previous: current.Metadata.Version, Version: current.Metadata.Version}
+	if current.Metadata.Version == "" {
+		return nil, fmt.Errorf("%s has no metadata.version to bump", zarfYamlPath)
+	}
+
+	// New packages are bumped from their current version
+	from, level := current.Metadata.Version, b.Level
+	previous, err := b.basePackage(ctx, packagePath)
+	if err != nil {
+		return nil, err
+	}
+	if previous != nil && previous.Metadata.Version != "" {
+		from = previous.Metadata.Version
+		if level == "" {
+			level = RequiredBump(previous, current, b.RequireMajorBumpOnRemoval)
+		}
+	}
+	if level == "" {
+		level = BumpPatch
+	}
+	version, err := BumpVersion(from, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bump the version of %s: %w", packagePath, err)
+	}
+	if previous != nil && from != current.Metadata.Version {
+		if cmp, err := util.CompareVersions(current.Metadata.Version, version); err == nil && cmp >= 0 {
+			result.Skipped = fmt.Sprintf("already bumped from %s", from)
+			return result, nil
+		}
+	}
+
+	document, err := yamledit.ReadFile(zarfYamlPath)
+	if err != nil {
+		return nil, err
+	}
+	if err := document.Set(version, "metadata", "version"); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", zarfYamlPath, err)
+	}
+	if err := document.WriteFile(zarfYamlPath); err != nil {
+		return nil, fmt.Errorf("failed to update %s: %w", zarfYamlPath, err)
+	}
+	if b.Changelog != "" {
+		if err := addChangelogEntry(filepath.Join(packagePath, "CHANGELOG.md"), version, b.Changelog); err != nil {
+			return nil, err
+		}
+	}
+	result.Version, result.Level = version, level
+	return result, nil
+}
+
+// basePackage returns the zarf.yaml of the package on the base revision, nil if the
+// package does not exist there
+func (b *Bumper) basePackage(ctx context.Context, packagePath string) (*util.ZarfYaml, error) {
+	git := tool.NewGit(packagePath)
+	base, err := baseRef(ctx, git, b.Remote, b.TargetBranch, b.Since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find the base revision of %s: %w", packagePath, err)
+	}
+	content, err := git.ShowFile(ctx, base, "zarf.yaml")
+	if errors.Is(err, tool.ErrFileNotFound) {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read the zarf.yaml of %s at %s: %w", packagePath, base, err)
+	}
+	previous, err := util.UnmarshalZarfYaml([]byte(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the zarf.yaml of %s at %s: %w", packagePath, base, err)
+	}
+	return previous, nil
+}
+
+// RequiredBump returns the smallest bump from previous to current that the version
+// policy of 'zt lint' accepts without warnings
+func RequiredBump(previous, current *util.ZarfYaml, requireMajorBumpOnRemoval bool) string {
+	added, removed := diffComponentNames(previous, current)
+	switch {
+	case requireMajorBumpOnRemoval && len(removed) > 0:
+		return BumpMajor
+	case len(added) > 0 || len(diffImages(previous, current)) > 0:
+		return BumpMinor
+	default:
+		return BumpPatch
+	}
+}
+
+// BumpVersion increments the SemVer version at level, resetting the lower levels and
+// dropping pre-release and build metadata. A 'v' prefix is kept.
+func BumpVersion(version, level string) (string, error) {
+	parsed, err := semver.NewVersion(version)
+	if err != nil {
+		return "", fmt.Errorf("version %q is not a SemVer version", version)
+	}
+	var bumped semver.Version
+	switch level {
+	case BumpMajor:
+		bumped = parsed.IncMajor()
+	case BumpMinor:
+		bumped = parsed.IncMinor()
+	case BumpPatch:
+		bumped = parsed.IncPatch()
+	default:
+		return "", fmt.Errorf("invalid bump level %q, must be one of: major, minor, patch", level)
+	}
+	next := fmt.Sprintf("%d.%d.%d", bumped.Major(), bumped.Minor(), bumped.Patch())
+	if strings.HasPrefix(version, "v") {
+		next = "v" + next
+	}
+	return next, nil
+}
+
+// addChangelogEntry adds a section for version with entry to the changelog at path,
+// below its title if it has one. The changelog is created if it does not exist.
+func addChangelogEntry(path, version, entry string) error {
+	content, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	section := fmt.Sprintf("## %s\n\n- %s\n\n", version, entry)
+	text := string(content)
+	if text == "" {
+		text = "# Changelog\n\n"
+	}
+	if strings.HasPrefix(text, "# ") {
+		title, rest, _ := strings.Cut(text, "\n")
+		text = title + "\n\n" + section + strings.TrimLeft(rest, "\n")
+	} else {
+		text = section + text
+	}
+	return os.WriteFile(path, []byte(strings.TrimRight(text, "\n")+"\n"), 0644)
+}
@@ -0,0 +1,107 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBumpVersion(t *testing.T) {
+	tests := []struct {
+		version, level, bumped string
+	}{
+		{"1.2.3", BumpPatch, "1.2.4"},
+		{"1.2.3", BumpMinor, "1.3.0"},
+		{"1.2.3", BumpMajor, "2.0.0"},
+		{"v0.4.1", BumpMinor, "v0.5.0"},
+		{"1.2.3-rc.1+build.5", BumpPatch, "1.2.3"},
+	}
+	for _, tt := range tests {
+		bumped, err := BumpVersion(tt.version, tt.level)
+		require.NoError(t, err)
+		assert.Equal(t, tt.bumped, bumped, "%s %s", tt.level, tt.version)
+	}
+
+	_, err := BumpVersion("latest", BumpPatch)
+	assert.EqualError(t, err, `version "latest" is not a SemVer version`)
+	_, err = BumpVersion("1.0.0", "none")
+	assert.EqualError(t, err, `invalid bump level "none", must be one of: major, minor, patch`)
+}
+
+func TestRequiredBump(t *testing.T) {
+	previous := &util.ZarfYaml{Components: []util.ZarfComponent{{Name: "web", Images: []string{"nginx:1.25"}}, {Name: "docs"}}}
+	assert.Equal(t, BumpPatch, RequiredBump(previous, previous, true))
+	withImage := &util.ZarfYaml{Components: []util.ZarfComponent{{Name: "web", Images: []string{"nginx:1.27"}}, {Name: "docs"}}}
+	assert.Equal(t, BumpMinor, RequiredBump(previous, withImage, true))
+	withoutDocs := &util.ZarfYaml{Components: []util.ZarfComponent{{Name: "web", Images: []string{"nginx:1.25"}}}}
+	assert.Equal(t, BumpPatch, RequiredBump(previous, withoutDocs, false))
+	assert.Equal(t, BumpMajor, RequiredBump(previous, withoutDocs, true))
+}
+
+func TestBumperBump(t *testing.T) {
+	repo := t.TempDir()
+	packageDir := filepath.Join(repo, "packages", "app")
+	require.NoError(t, os.MkdirAll(packageDir, 0755))
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		cmd.Dir = repo
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	zarfYaml := filepath.Join(packageDir, "zarf.yaml")
+	write := func(content string) {
+		require.NoError(t, os.WriteFile(zarfYaml, []byte(content), 0644))
+	}
+
+	write("kind: ZarfPackageConfig\nmetadata:\n  name: app\n  version: 1.0.0 # released\ncomponents:\n  - name: web\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("update-ref", "refs/remotes/origin/main", "HEAD")
+
+	// Adding a component requires a minor bump
+	write("kind: ZarfPackageConfig\nmetadata:\n  name: app\n  version: 1.0.0 # released\ncomponents:\n  - name: web\n  - name: docs\n")
+	b := NewBumper()
+	b.Changelog = "Add the docs component"
+	result, err := b.Bump(context.Background(), packageDir)
+	require.NoError(t, err)
+	assert.Equal(t, &BumpResult{Path: packageDir, Previous: "1.0.0", Version: "1.1.0", Level: BumpMinor}, result)
+	content, err := os.ReadFile(zarfYaml)
+	require.NoError(t, err)
+	assert.Equal(t, "kind: ZarfPackageConfig\nmetadata:\n  name: app\n  version: 1.1.0 # released\ncomponents:\n  - name: web\n  - name: docs\n", string(content))
+	changelog, err := os.ReadFile(filepath.Join(packageDir, "CHANGELOG.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Changelog\n\n## 1.1.0\n\n- Add the docs component\n", string(changelog))
+
+	// Bumping again does not bump twice, a bigger bump replaces the smaller one
+	result, err = b.Bump(context.Background(), packageDir)
+	require.NoError(t, err)
+	assert.Equal(t, "already bumped from 1.0.0", result.Skipped)
+	b.Level, b.Changelog = BumpMajor, "Rename the package"
+	result, err = b.Bump(context.Background(), packageDir)
+	require.NoError(t, err)
+	assert.Equal(t, "2.0.0", result.Version)
+	changelog, err = os.ReadFile(filepath.Join(packageDir, "CHANGELOG.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Changelog\n\n## 2.0.0\n\n- Rename the package\n\n## 1.1.0\n\n- Add the docs component\n", string(changelog))
+}
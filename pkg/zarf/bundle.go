@@ -0,0 +1,274 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// UDSBundleFile is the file defining a UDS bundle
+const UDSBundleFile = "uds-bundle.yaml"
+
+// UDSBundleKind is the kind of UDS bundles
+const UDSBundleKind = "UDSBundle"
+
+// UDSBundle is a UDS bundle, a set of zarf packages deployed together
+type UDSBundle struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name    string `yaml:"name"`
+		Version string `yaml:"version"`
+	} `yaml:"metadata"`
+	Packages []UDSBundlePackage `yaml:"packages"`
+}
+
+// UDSBundlePackage is a zarf package of a bundle, either built locally into Path or
+// published to Repository, at the version Ref
+type UDSBundlePackage struct {
+	Name       string `yaml:"name"`
+	Path       string `yaml:"path,omitempty"`
+	Repository string `yaml:"repository,omitempty"`
+	Ref        string `yaml:"ref"`
+	Line       int    `yaml:"-"` // Line of the package in the bundle file
+}
+
+// LoadBundle reads the bundle defined in bundleDir
+func LoadBundle(bundleDir string) (*UDSBundle, error) {
+	path := filepath.Join(bundleDir, UDSBundleFile)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(content, &node); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	bundle := &UDSBundle{}
+	if err := node.Decode(bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if len(node.Content) > 0 {
+		root := node.Content[0]
+		for i := 0; i+1 < len(root.Content); i += 2 {
+			if root.Content[i].Value != "packages" {
+				continue
+			}
+			for j, item := range root.Content[i+1].Content {
+				if j < len(bundle.Packages) {
+					bundle.Packages[j].Line = item.Line
+				}
+			}
+		}
+	}
+	return bundle, nil
+}
+
+// FindBundles discovers the directories containing a uds-bundle.yaml in dirs
+func FindBundles(dirs []string) ([]string, error) {
+	var bundles []string
+	for _, dir := range dirs {
+		if _, err := os.Stat(dir); os.IsNotExist(err) {
+			continue
+		}
+		err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && info.Name() == UDSBundleFile {
+				bundles = append(bundles, filepath.Dir(path))
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find bundles in directory %s: %w", dir, err)
+		}
+	}
+	return bundles, nil
+}
+
+// bundleMembers returns the local packages of the bundle, keyed by the name of the
+// bundle package. Packages are matched by their path, the directory the package is
+// built in, or else their metadata name.
+func bundleMembers(bundleDir string, bundle *UDSBundle, packageDirs []string) map[string]string {
+	byName := map[string]string{}
+	byPath := map[string]string{}
+	for _, packageDir := range packageDirs {
+		byPath[filepath.Clean(packageDir)] = packageDir
+		if zarfYaml, err := util.ReadZarfYaml(filepath.Join(packageDir, "zarf.yaml")); err == nil && zarfYaml.Metadata.Name != "" {
+			byName[zarfYaml.Metadata.Name] = packageDir
+		}
+	}
+	members := map[string]string{}
+	for _, pkg := range bundle.Packages {
+		if pkg.Path != "" {
+			if packageDir, ok := byPath[filepath.Clean(filepath.Join(bundleDir, pkg.Path))]; ok {
+				members[pkg.Name] = packageDir
+				continue
+			}
+		}
+		if packageDir, ok := byName[pkg.Name]; ok {
+			members[pkg.Name] = packageDir
+		}
+	}
+	return members
+}
+
+// ValidateBundle checks the bundle in bundleDir: every package needs a name, a ref and
+// either a path that exists or a repository, and the refs of the local packages among
+// packageDirs must match their version. Published packages may lag behind the local
+// version, so a mismatch is only a warning for them.
+func ValidateBundle(bundleDir string, packageDirs []string) (*ValidationResult, error) {
+	result := &ValidationResult{PackagePath: bundleDir, Valid: true}
+	bundle, err := LoadBundle(bundleDir)
+	if err != nil {
+		return nil, err
+	}
+	add := func(ruleID, severity string, line int, format string, args ...interface{}) {
+		result.AddFinding(Finding{RuleID: ruleID, Severity: severity, Message: fmt.Sprintf(format, args...), File: UDSBundleFile, Line: line})
+	}
+
+	if bundle.Kind != UDSBundleKind {
+		add("bundle-kind", SeverityError, 0, "Invalid kind '%s', expected '%s'", bundle.Kind, UDSBundleKind)
+	}
+	if bundle.Metadata.Name == "" {
+		add("bundle-name", SeverityError, 0, "Missing bundle name in metadata")
+	}
+
+	members := bundleMembers(bundleDir, bundle, packageDirs)
+	seen := map[string]bool{}
+	for _, pkg := range bundle.Packages {
+		switch {
+		case pkg.Name == "":
+			add("bundle-package", SeverityError, pkg.Line, "Bundle package has no name")
+			continue
+		case seen[pkg.Name]:
+			add("bundle-package", SeverityError, pkg.Line, "Package '%s' is listed more than once", pkg.Name)
+		}
+		seen[pkg.Name] = true
+		if pkg.Ref == "" {
+			add("bundle-package", SeverityError, pkg.Line, "Package '%s' has no ref", pkg.Name)
+		}
+		switch {
+		case pkg.Path == "" && pkg.Repository == "":
+			add("bundle-package", SeverityError, pkg.Line, "Package '%s' needs either a path or a repository", pkg.Name)
+		case pkg.Path != "" && pkg.Repository != "":
+			add("bundle-package", SeverityError, pkg.Line, "Package '%s' sets both a path and a repository", pkg.Name)
+		case pkg.Path != "":
+			if _, err := os.Stat(filepath.Join(bundleDir, pkg.Path)); err != nil && members[pkg.Name] == "" {
+				add("bundle-package", SeverityError, pkg.Line, "Path %s of package '%s' does not exist", pkg.Path, pkg.Name)
+			}
+		}
+
+		packageDir, ok := members[pkg.Name]
+		if !ok || pkg.Ref == "" {
+			continue
+		}
+		zarfYaml, err := util.ReadZarfYaml(filepath.Join(packageDir, "zarf.yaml"))
+		if err != nil || zarfYaml.Metadata.Version == "" || refMatchesVersion(pkg.Ref, zarfYaml.Metadata.Version) {
+			continue
+		}
+		if pkg.Repository != "" {
+			add("bundle-version", SeverityWarning, pkg.Line, "Package '%s' is referenced at %s, but %s is at version %s", pkg.Name, pkg.Ref, packageDir, zarfYaml.Metadata.Version)
+		} else {
+			add("bundle-version", SeverityError, pkg.Line, "Package '%s' is referenced at %s, but %s is at version %s", pkg.Name, pkg.Ref, packageDir, zarfYaml.Metadata.Version)
+		}
+	}
+	return result, nil
+}
+
+// refMatchesVersion reports whether a bundle ref is version, optionally followed by a
+// flavor such as '1.2.0-upstream'
+func refMatchesVersion(ref, version string) bool {
+	return ref == version || strings.HasPrefix(ref, version+"-")
+}
+
+// BundlesReferencing returns the bundles referencing one of the packages, matched
+// against all local packageDirs
+func BundlesReferencing(bundleDirs, packages, packageDirs []string) ([]string, error) {
+	referenced := map[string]bool{}
+	for _, packageDir := range packages {
+		referenced[filepath.Clean(packageDir)] = true
+	}
+	var result []string
+	for _, bundleDir := range bundleDirs {
+		bundle, err := LoadBundle(bundleDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, member := range bundleMembers(bundleDir, bundle, packageDirs) {
+			if referenced[filepath.Clean(member)] {
+				result = append(result, bundleDir)
+				break
+			}
+		}
+	}
+	return result, nil
+}
+
+// FindChangedBundles returns the bundles in bundleDirs whose files changed compared to
+// the target branch, or the since reference, or that reference one of the changed
+// packages, matched against the packages in zarfDirs
+func FindChangedBundles(ctx context.Context, remote, targetBranch, since string, bundleDirs, zarfDirs, changedPackages []string) ([]string, error) {
+	bundles, err := FindBundles(bundleDirs)
+	if err != nil || len(bundles) == 0 {
+		return nil, err
+	}
+	git := tool.NewGit("")
+	base, err := changeBase(ctx, git, remote, targetBranch, since)
+	if err != nil {
+		return nil, err
+	}
+	changedFiles, err := git.ListChangedFilesInDirs(ctx, base, bundleDirs...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get changed files: %w", err)
+	}
+	packageDirs, err := FindZarfPackages(zarfDirs)
+	if err != nil {
+		return nil, err
+	}
+	referencing, err := BundlesReferencing(bundles, changedPackages, packageDirs)
+	if err != nil {
+		return nil, err
+	}
+
+	changed := map[string]bool{}
+	for _, bundleDir := range referencing {
+		changed[bundleDir] = true
+	}
+	for _, bundleDir := range bundles {
+		prefix := filepath.ToSlash(filepath.Clean(bundleDir)) + "/"
+		for _, file := range changedFiles {
+			if strings.HasPrefix(file, prefix) {
+				changed[bundleDir] = true
+				break
+			}
+		}
+	}
+	var result []string
+	for bundleDir := range changed {
+		result = append(result, bundleDir)
+	}
+	sort.Strings(result)
+	return result, nil
+}
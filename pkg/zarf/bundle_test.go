@@ -0,0 +1,120 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeBundle writes a uds-bundle.yaml with the given packages to dir
+func writeBundle(t *testing.T, dir, packages string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	content := "kind: UDSBundle\nmetadata:\n  name: " + filepath.Base(dir) + "\n  version: 0.1.0\npackages:\n" + packages
+	require.NoError(t, os.WriteFile(filepath.Join(dir, UDSBundleFile), []byte(content), 0644))
+}
+
+func TestValidateBundle(t *testing.T) {
+	root := t.TempDir()
+	podinfo := filepath.Join(root, "packages", "podinfo")
+	require.NoError(t, os.MkdirAll(podinfo, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(podinfo, "zarf.yaml"), []byte("kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\n  version: 1.2.0\n"), 0644))
+	redis := filepath.Join(root, "packages", "redis")
+	require.NoError(t, os.MkdirAll(redis, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(redis, "zarf.yaml"), []byte("kind: ZarfPackageConfig\nmetadata:\n  name: redis\n  version: 7.2.0\n"), 0644))
+	packageDirs := []string{podinfo, redis}
+
+	bundleDir := filepath.Join(root, "bundles", "dev")
+	writeBundle(t, bundleDir, `  - name: init
+    repository: ghcr.io/zarf-dev/packages/init
+    ref: v0.40.0
+  - name: podinfo
+    path: ../../packages/podinfo
+    ref: 1.2.0
+  - name: redis
+    repository: ghcr.io/example/redis
+    ref: 7.2.0-upstream
+`)
+	result, err := ValidateBundle(bundleDir, packageDirs)
+	require.NoError(t, err)
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Findings)
+
+	writeBundle(t, bundleDir, `  - name: podinfo
+    path: ../../build
+    ref: 1.1.0
+  - name: redis
+    repository: ghcr.io/example/redis
+    ref: 7.0.0
+  - name: missing
+    path: ../../build
+    ref: 1.0.0
+  - name: redis
+    path: ../../packages/redis
+    repository: ghcr.io/example/redis
+`)
+	result, err = ValidateBundle(bundleDir, packageDirs)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, []Finding{
+		{RuleID: "bundle-version", Severity: SeverityError, Message: "Package 'podinfo' is referenced at 1.1.0, but " + podinfo + " is at version 1.2.0", File: UDSBundleFile, Line: 6},
+		{RuleID: "bundle-version", Severity: SeverityWarning, Message: "Package 'redis' is referenced at 7.0.0, but " + redis + " is at version 7.2.0", File: UDSBundleFile, Line: 9},
+		{RuleID: "bundle-package", Severity: SeverityError, Message: "Path ../../build of package 'missing' does not exist", File: UDSBundleFile, Line: 12},
+		{RuleID: "bundle-package", Severity: SeverityError, Message: "Package 'redis' is listed more than once", File: UDSBundleFile, Line: 15},
+		{RuleID: "bundle-package", Severity: SeverityError, Message: "Package 'redis' has no ref", File: UDSBundleFile, Line: 15},
+		{RuleID: "bundle-package", Severity: SeverityError, Message: "Package 'redis' sets both a path and a repository", File: UDSBundleFile, Line: 15},
+	}, result.Findings)
+}
+
+func TestFindChangedBundles(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	writePackage(t, filepath.Join("packages", "podinfo"), "  - name: web\n")
+	writePackage(t, filepath.Join("packages", "redis"), "  - name: redis\n")
+	writeBundle(t, filepath.Join("bundles", "web"), "  - name: podinfo\n    path: ../../packages/podinfo\n    ref: 1.0.0\n")
+	writeBundle(t, filepath.Join("bundles", "cache"), "  - name: redis\n    repository: ghcr.io/example/redis\n    ref: 1.0.0\n")
+	writeBundle(t, filepath.Join("bundles", "docs"), "  - name: docs\n    repository: ghcr.io/example/docs\n    ref: 1.0.0\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	// The bundle of a changed package changed, as did the bundle that was edited
+	changed := []string{filepath.Join("packages", "podinfo")}
+	writeBundle(t, filepath.Join("bundles", "docs"), "  - name: docs\n    repository: ghcr.io/example/docs\n    ref: 1.1.0\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "change")
+
+	bundles, err := FindChangedBundles(context.Background(), "origin", "main", "HEAD~1", []string{"bundles"}, []string{"packages"}, changed)
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("bundles", "docs"), filepath.Join("bundles", "web")}, bundles)
+
+	// Without bundle directories, bundles are not detected
+	bundles, err = FindChangedBundles(context.Background(), "origin", "main", "HEAD~1", nil, []string{"packages"}, changed)
+	require.NoError(t, err)
+	assert.Empty(t, bundles)
+}
@@ -0,0 +1,167 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Catalog is a browsable index of packages, e.g. for publishing to a portal
+type Catalog struct {
+	Packages []CatalogEntry `yaml:"packages" json:"packages"`
+}
+
+// CatalogEntry describes a package of the catalog
+type CatalogEntry struct {
+	Name        string `yaml:"name" json:"name"`
+	Path        string `yaml:"path" json:"path"`
+	Version     string `yaml:"version,omitempty" json:"version,omitempty"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	// Architectures are the architectures the package can be built for: the
+	// metadata.architecture of the package, else those any component deploys on
+	Architectures []string `yaml:"architectures" json:"architectures"`
+	// Maintainers are the maintainers the package declares, else the code owners of
+	// its zarf.yaml, see PackageOwners
+	Maintainers []string `yaml:"maintainers" json:"maintainers"`
+	Images      []string `yaml:"images" json:"images"`
+	// SBOM and Vulnerabilities link to the SBOM and the vulnerability summary of the
+	// package, see CatalogLinks
+	SBOM            string `yaml:"sbom,omitempty" json:"sbom,omitempty"`
+	Vulnerabilities string `yaml:"vulnerabilities,omitempty" json:"vulnerabilities,omitempty"`
+}
+
+// CatalogLinks are URL templates of the SBOM and vulnerability summary links of the
+// catalog entries, in which {name} and {version} are replaced with the name and
+// version of the package. Empty templates add no links.
+type CatalogLinks struct {
+	SBOM            string
+	Vulnerabilities string
+}
+
+// BuildCatalog builds the catalog of the packages in packageDirs
+func BuildCatalog(packageDirs []string, links CatalogLinks) (*Catalog, error) {
+	catalog := &Catalog{Packages: []CatalogEntry{}}
+	for _, packageDir := range packageDirs {
+		model, err := InspectPackage(packageDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", packageDir, err)
+		}
+		entry := CatalogEntry{
+			Name:          model.Name,
+			Path:          packageDir,
+			Version:       model.Version,
+			Description:   model.Description,
+			Architectures: packageArchitectures(model),
+			Maintainers:   append([]string{}, PackageOwners(packageDir)...),
+			Images:        model.Images,
+		}
+		replacer := strings.NewReplacer("{name}", model.Name, "{version}", model.Version)
+		if links.SBOM != "" {
+			entry.SBOM = replacer.Replace(links.SBOM)
+		}
+		if links.Vulnerabilities != "" {
+			entry.Vulnerabilities = replacer.Replace(links.Vulnerabilities)
+		}
+		catalog.Packages = append(catalog.Packages, entry)
+	}
+	return catalog, nil
+}
+
+// packageArchitectures returns the architecture of the package, else the architectures
+// at least one component is deployed on
+func packageArchitectures(model *PackageModel) []string {
+	if model.Architecture != "" {
+		return []string{model.Architecture}
+	}
+	architectures := []string{}
+	for _, arch := range onlyArchitectures {
+		for _, component := range model.Components {
+			if component.OnlyArchitecture == "" || component.OnlyArchitecture == arch {
+				architectures = append(architectures, arch)
+				break
+			}
+		}
+	}
+	return architectures
+}
+
+// annotationList splits a comma separated annotation value into its trimmed items
+func annotationList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// Markdown renders the catalog as an index page: a table of the packages followed by
+// a section per package with its images and links
+func (c *Catalog) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Package Catalog\n\n%d package(s)\n\n", len(c.Packages))
+	if len(c.Packages) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("| Package | Version | Architectures | Maintainers | Description |\n|---|---|---|---|---|\n")
+	for _, entry := range c.Packages {
+		fmt.Fprintf(&b, "| [%s](#%s) | %s | %s | %s | %s |\n", entry.Name, markdownAnchor(entry.Name), entry.Version,
+			strings.Join(entry.Architectures, ", "), markdownCell(strings.Join(entry.Maintainers, ", ")), markdownCell(entry.Description))
+	}
+
+	for _, entry := range c.Packages {
+		fmt.Fprintf(&b, "\n## %s\n\n", entry.Name)
+		if entry.Description != "" {
+			fmt.Fprintf(&b, "%s\n\n", strings.TrimSpace(entry.Description))
+		}
+		fmt.Fprintf(&b, "- **Path:** `%s`\n", entry.Path)
+		if entry.Version != "" {
+			fmt.Fprintf(&b, "- **Version:** %s\n", entry.Version)
+		}
+		if len(entry.Maintainers) > 0 {
+			fmt.Fprintf(&b, "- **Maintainers:** %s\n", strings.Join(entry.Maintainers, ", "))
+		}
+		if entry.SBOM != "" {
+			fmt.Fprintf(&b, "- **SBOM:** [%s](%s)\n", entry.SBOM, entry.SBOM)
+		}
+		if entry.Vulnerabilities != "" {
+			fmt.Fprintf(&b, "- **Vulnerabilities:** [%s](%s)\n", entry.Vulnerabilities, entry.Vulnerabilities)
+		}
+		if len(entry.Images) > 0 {
+			b.WriteString("\n| Image |\n|---|\n")
+			for _, image := range entry.Images {
+				fmt.Fprintf(&b, "| `%s` |\n", image)
+			}
+		}
+	}
+	return b.String()
+}
+
+// markdownAnchor returns the anchor GitHub generates for a heading
+func markdownAnchor(heading string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(heading) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-', r == '_':
+			b.WriteRune(r)
+		case r == ' ':
+			b.WriteRune('-')
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildCatalog(t *testing.T) {
+	root := t.TempDir()
+	podinfo := filepath.Join(root, "podinfo")
+	require.NoError(t, os.MkdirAll(podinfo, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(podinfo, "zarf.yaml"), []byte(`kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+  version: 1.0.0
+  description: Podinfo | demo app
+  annotations:
+    maintainers: "@org/web, jane@example.com"
+components:
+  - name: web
+    images: [ghcr.io/stefanprodan/podinfo:6.4.0]
+  - name: arm-tools
+    only:
+      cluster:
+        architecture: arm64
+    images: [redis:7.2]
+`), 0644))
+	tools := filepath.Join(root, "tools")
+	writePackage(t, tools, "  - name: tools\n    only:\n      cluster:\n        architecture: amd64\n")
+
+	catalog, err := BuildCatalog([]string{podinfo, tools}, CatalogLinks{SBOM: "https://portal.example.com/{name}/{version}/sbom"})
+	require.NoError(t, err)
+	assert.Equal(t, &Catalog{Packages: []CatalogEntry{
+		{
+			Name: "podinfo", Path: podinfo, Version: "1.0.0", Description: "Podinfo | demo app",
+			Architectures: []string{"amd64", "arm64"},
+			Maintainers:   []string{"@org/web", "jane@example.com"},
+			Images:        []string{"ghcr.io/stefanprodan/podinfo:6.4.0", "redis:7.2"},
+			SBOM:          "https://portal.example.com/podinfo/1.0.0/sbom",
+		},
+		{
+			Name: "tools", Path: tools, Architectures: []string{"amd64"}, Maintainers: []string{}, Images: []string{},
+			SBOM: "https://portal.example.com/tools//sbom",
+		},
+	}}, catalog)
+
+	markdown := catalog.Markdown()
+	assert.Contains(t, markdown, "| [podinfo](#podinfo) | 1.0.0 | amd64, arm64 | @org/web, jane@example.com | Podinfo \\| demo app |\n")
+	assert.Contains(t, markdown, "## tools\n\n- **Path:** `"+tools+"`\n- **SBOM:** [https://portal.example.com/tools//sbom](https://portal.example.com/tools//sbom)\n")
+	assert.Contains(t, markdown, "| Image |\n|---|\n| `ghcr.io/stefanprodan/podinfo:6.4.0` |\n| `redis:7.2` |\n")
+}
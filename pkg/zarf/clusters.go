@@ -0,0 +1,97 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+)
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// ClusterKubeconfig returns a kubeconfig file for cluster. A cluster that only names a
+// kubeconfig file uses it as is. For a context, the context is extracted into a
+// self-contained kubeconfig written to dir, so that zarf and kubectl, which are both
+// pointed at the cluster through KUBECONFIG, select it as the current context.
+func ClusterKubeconfig(ctx context.Context, cluster config.Cluster, dir string) (string, error) {
+	if cluster.Context == "" {
+		return cluster.Kubeconfig, nil
+	}
+
+	executor := exec.NewProcessExecutor(false)
+	if cluster.Kubeconfig != "" {
+		executor = executor.WithEnv("KUBECONFIG=" + cluster.Kubeconfig)
+	}
+	kubeconfig, err := executor.RunProcessAndCaptureStdout(ctx, "kubectl", "config", "view",
+		"--minify", "--flatten", "--context", cluster.Context)
+	if err != nil {
+		return "", fmt.Errorf("failed to read the kubeconfig of context %q: %w", cluster.Context, err)
+	}
+
+	path := filepath.Join(dir, unsafeFileChars.ReplaceAllString(cluster.Name, "_")+".kubeconfig")
+	if err := os.WriteFile(path, []byte(kubeconfig+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write the kubeconfig of cluster %q: %w", cluster.Name, err)
+	}
+	return path, nil
+}
+
+// ForCluster returns a deployer that installs packages into cluster. Clusters with a
+// Kubernetes version are created with kind and must be deleted with DeleteCluster.
+// Kubeconfig files extracted from contexts or written by kind are written to dir.
+// Diagnostics of failed packages are collected into a subdirectory named after the
+// cluster.
+func (d *Deployer) ForCluster(ctx context.Context, cluster config.Cluster, dir string) (*Deployer, error) {
+	var kindCluster, kubeconfig string
+	if cluster.KubeVersion != "" {
+		image, err := KindNodeImage(cluster.KubeVersion)
+		if err != nil {
+			return nil, err
+		}
+		kindCluster = kindClusterName(cluster.Name)
+		kubeconfig = filepath.Join(dir, kindCluster+".kubeconfig")
+		if err := CreateKindCluster(ctx, kindCluster, image, kubeconfig); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if kubeconfig, err = ClusterKubeconfig(ctx, cluster, dir); err != nil {
+			return nil, err
+		}
+	}
+
+	deployer := *d.deployer
+	deployer.Kubeconfig = kubeconfig
+	deployer.Cluster = cluster.Name
+	if deployer.ArtifactsDir != "" {
+		deployer.ArtifactsDir = filepath.Join(deployer.ArtifactsDir, unsafeFileChars.ReplaceAllString(cluster.Name, "_"))
+	}
+	return &Deployer{config: d.config, deployer: &deployer, kindCluster: kindCluster}, nil
+}
+
+// DeleteCluster deletes the kind cluster created for the deployer, unless clean-up is
+// skipped. It returns the name of the deleted cluster, which is empty if the deployer
+// did not create one.
+func (d *Deployer) DeleteCluster(ctx context.Context) (string, error) {
+	if d.kindCluster == "" || d.deployer.SkipCleanup {
+		return "", nil
+	}
+	return d.kindCluster, DeleteKindCluster(ctx, d.kindCluster)
+}
@@ -0,0 +1,87 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestForCluster(t *testing.T) {
+	// kubectl prints the context it was asked for and the kubeconfig it was pointed at
+	fakeCommands(t, map[string]string{
+		"kubectl": `echo "$* KUBECONFIG=$KUBECONFIG"`,
+	})
+
+	d := &Deployer{config: &config.Configuration{}, deployer: NewPackageDeployer()}
+	d.deployer.ArtifactsDir = "artifacts"
+	dir := t.TempDir()
+
+	clusterDeployer, err := d.ForCluster(context.Background(), config.Cluster{Name: "arm64/kind", Context: "kind-arm64", Kubeconfig: "/clusters.yaml"}, dir)
+	require.NoError(t, err)
+	kubeconfig := filepath.Join(dir, "arm64_kind.kubeconfig")
+	assert.Equal(t, kubeconfig, clusterDeployer.deployer.Kubeconfig)
+	assert.Equal(t, filepath.Join("artifacts", "arm64_kind"), clusterDeployer.deployer.ArtifactsDir)
+	assert.Empty(t, d.deployer.Kubeconfig)
+
+	content, err := os.ReadFile(kubeconfig)
+	require.NoError(t, err)
+	assert.Equal(t, "config view --minify --flatten --context kind-arm64 KUBECONFIG=/clusters.yaml\n", string(content))
+
+	// Commands run against the cluster see its kubeconfig
+	output, err := clusterDeployer.deployer.run(context.Background(), "test", "", "kubectl", "get", "nodes")
+	require.NoError(t, err)
+	assert.Equal(t, "get nodes KUBECONFIG="+kubeconfig, output)
+
+	// Clusters without a context use their kubeconfig as is
+	clusterDeployer, err = d.ForCluster(context.Background(), config.Cluster{Name: "amd64", Kubeconfig: "/amd64.yaml"}, dir)
+	require.NoError(t, err)
+	assert.Equal(t, "/amd64.yaml", clusterDeployer.deployer.Kubeconfig)
+}
+
+func TestForKindCluster(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"kind": `echo "kind $*" >> "$ZT_TEST_CALLS"`,
+	})
+
+	d := &Deployer{config: &config.Configuration{}, deployer: NewPackageDeployer()}
+	dir := t.TempDir()
+	clusterDeployer, err := d.ForCluster(context.Background(), config.Cluster{Name: "k8s-1.29", KubeVersion: "1.29"}, dir)
+	require.NoError(t, err)
+	kubeconfig := filepath.Join(dir, "zt-k8s-1-29.kubeconfig")
+	assert.Equal(t, kubeconfig, clusterDeployer.deployer.Kubeconfig)
+
+	deleted, err := clusterDeployer.DeleteCluster(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "zt-k8s-1-29", deleted)
+
+	// Clusters that were not created are never deleted
+	deleted, err = d.DeleteCluster(context.Background())
+	require.NoError(t, err)
+	assert.Empty(t, deleted)
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "kind create cluster --name zt-k8s-1-29 --image kindest/node:v1.29.8 --kubeconfig "+kubeconfig+" --wait 5m\n"+
+		"kind delete cluster --name zt-k8s-1-29\n", string(content))
+}
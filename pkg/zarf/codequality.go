@@ -0,0 +1,104 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"strings"
+)
+
+// CodeQualityIssue is an entry of a GitLab Code Quality report, which GitLab shows in
+// the merge request widget and the changes diff
+type CodeQualityIssue struct {
+	Description string              `json:"description"`
+	CheckName   string              `json:"check_name"`
+	Fingerprint string              `json:"fingerprint"`
+	Severity    string              `json:"severity"`
+	Location    CodeQualityLocation `json:"location"`
+}
+
+// CodeQualityLocation is the file and line a Code Quality issue refers to
+type CodeQualityLocation struct {
+	Path  string           `json:"path"`
+	Lines CodeQualityLines `json:"lines"`
+}
+
+// CodeQualityLines is the line range of a Code Quality location
+type CodeQualityLines struct {
+	Begin int `json:"begin"`
+}
+
+// CodeQuality returns the errors and warnings of the report as a GitLab Code Quality
+// report. Findings without a file are reported against the package's zarf.yaml.
+func (r *LintReport) CodeQuality() []CodeQualityIssue {
+	issues := []CodeQualityIssue{}
+	for _, pkg := range r.Packages {
+		for _, finding := range pkg.Findings {
+			if finding.Severity == SeverityInfo {
+				continue
+			}
+			file := finding.File
+			if file == "" {
+				file = "zarf.yaml"
+			}
+			severity := "minor"
+			if finding.Severity == SeverityError {
+				severity = "major"
+			}
+			issues = append(issues, newCodeQualityIssue(finding.RuleID, finding.Message, severity, path.Join(pkg.Path, file), finding.Line))
+		}
+	}
+	return issues
+}
+
+// CodeQuality returns the failed deployments of the report as a GitLab Code Quality
+// report, with an issue per error and failed test against the package's zarf.yaml
+func (r *InstallReport) CodeQuality() []CodeQualityIssue {
+	issues := []CodeQualityIssue{}
+	for _, deployment := range r.Deployments {
+		file := path.Join(deployment.Path, "zarf.yaml")
+		prefix := ""
+		if deployment.Cluster != "" {
+			prefix = "[" + deployment.Cluster + "] "
+		}
+		for _, message := range deployment.Errors {
+			issues = append(issues, newCodeQualityIssue("deployment", prefix+message, "critical", file, 1))
+		}
+		for _, test := range deployment.Tests {
+			if !test.Success {
+				issues = append(issues, newCodeQualityIssue("component-test", prefix+test.Name+": "+test.Message, "major", file, 1))
+			}
+		}
+	}
+	return issues
+}
+
+// newCodeQualityIssue creates an issue whose fingerprint is stable across runs, so
+// GitLab can tell new issues from fixed ones
+func newCodeQualityIssue(check, description, severity, file string, line int) CodeQualityIssue {
+	if line < 1 {
+		line = 1
+	}
+	sum := sha256.Sum256([]byte(strings.Join([]string{check, file, description}, "\x00")))
+	return CodeQualityIssue{
+		Description: description,
+		CheckName:   check,
+		Fingerprint: hex.EncodeToString(sum[:16]),
+		Severity:    severity,
+		Location:    CodeQualityLocation{Path: file, Lines: CodeQualityLines{Begin: line}},
+	}
+}
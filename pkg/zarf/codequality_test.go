@@ -0,0 +1,64 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintReportCodeQuality(t *testing.T) {
+	result := &ValidationResult{PackagePath: "packages/a", Valid: true}
+	result.AddFinding(Finding{RuleID: "yaml-lint", Severity: SeverityError, Message: "wrong indentation", File: "manifests/app.yaml", Line: 7})
+	result.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+	result.AddInfo("zarf-lint", "Validated using Zarf CLI")
+
+	issues := NewLintReport([]*ValidationResult{result}).CodeQuality()
+	require.Len(t, issues, 2)
+	assert.Equal(t, "yaml-lint", issues[0].CheckName)
+	assert.Equal(t, "major", issues[0].Severity)
+	assert.Equal(t, CodeQualityLocation{Path: "packages/a/manifests/app.yaml", Lines: CodeQualityLines{Begin: 7}}, issues[0].Location)
+	assert.Equal(t, "minor", issues[1].Severity)
+	assert.Equal(t, CodeQualityLocation{Path: "packages/a/zarf.yaml", Lines: CodeQualityLines{Begin: 1}}, issues[1].Location)
+
+	// Fingerprints are stable across runs and differ between issues
+	assert.Len(t, issues[0].Fingerprint, 32)
+	assert.Equal(t, issues, NewLintReport([]*ValidationResult{result}).CodeQuality())
+	assert.NotEqual(t, issues[0].Fingerprint, issues[1].Fingerprint)
+
+	assert.Equal(t, []CodeQualityIssue{}, NewLintReport(nil).CodeQuality())
+}
+
+func TestInstallReportCodeQuality(t *testing.T) {
+	report := NewInstallReport([]*DeploymentResult{
+		{PackagePath: "packages/a", Success: true},
+		{
+			PackagePath:    "packages/b",
+			Cluster:        "k8s-1.30",
+			Errors:         []string{"Deployment testing failed"},
+			ComponentTests: []ComponentTestResult{{ComponentName: "deployment/b", Message: "expected 2 ready replicas, got 1"}},
+		},
+	})
+
+	issues := report.CodeQuality()
+	require.Len(t, issues, 2)
+	assert.Equal(t, "[k8s-1.30] Deployment testing failed", issues[0].Description)
+	assert.Equal(t, "critical", issues[0].Severity)
+	assert.Equal(t, "packages/b/zarf.yaml", issues[0].Location.Path)
+	assert.Equal(t, "[k8s-1.30] deployment/b: expected 2 ready replicas, got 1", issues[1].Description)
+	assert.Equal(t, "component-test", issues[1].CheckName)
+}
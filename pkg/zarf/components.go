@@ -0,0 +1,107 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// Component matrix modes selecting the optional components to deploy
+const (
+	ComponentMatrixFull    = "full"
+	ComponentMatrixMinimal = "minimal"
+	ComponentMatrixEach    = "each"
+)
+
+// ComponentSelection is a named selection of components passed to 'zarf package deploy
+// --components'. Required components are always deployed.
+type ComponentSelection struct {
+	Name       string
+	Components []string
+}
+
+// ComponentMatrix returns the component selections to deploy the package with for the
+// given mode:
+//
+//   - full deploys every optional component
+//   - minimal deploys only the required components
+//   - each deploys the required components, then each optional component on its own
+//
+// Exactly one component of a group is deployed, so groups are represented by their
+// default component, or their first if none is the default, unless the mode deploys
+// the members of the group on their own. An empty mode returns no selections and the
+// package is deployed with its default components.
+func ComponentMatrix(zarfYaml *util.ZarfYaml, mode string) ([]ComponentSelection, error) {
+	var optional, groups []string
+	groupDefaults := map[string]string{}
+	for _, component := range zarfYaml.Components {
+		if component.Required {
+			continue
+		}
+		if component.Group == "" {
+			optional = append(optional, component.Name)
+			continue
+		}
+		if _, ok := groupDefaults[component.Group]; !ok {
+			groups = append(groups, component.Group)
+			groupDefaults[component.Group] = component.Name
+		} else if component.Default {
+			groupDefaults[component.Group] = component.Name
+		}
+	}
+
+	// groupMembers returns the default component of every group except skipGroup
+	groupMembers := func(skipGroup string) []string {
+		var members []string
+		for _, group := range groups {
+			if group != skipGroup {
+				members = append(members, groupDefaults[group])
+			}
+		}
+		return members
+	}
+
+	// Required components only, excluding the ungrouped optional components
+	minimal := ComponentSelection{Name: ComponentMatrixMinimal, Components: groupMembers("")}
+	for _, name := range optional {
+		minimal.Components = append(minimal.Components, "-"+name)
+	}
+
+	switch mode {
+	case "":
+		return nil, nil
+	case ComponentMatrixFull:
+		return []ComponentSelection{{Name: ComponentMatrixFull, Components: append(optional, groupMembers("")...)}}, nil
+	case ComponentMatrixMinimal:
+		return []ComponentSelection{minimal}, nil
+	case ComponentMatrixEach:
+		selections := []ComponentSelection{minimal}
+		for _, component := range zarfYaml.Components {
+			if component.Required {
+				continue
+			}
+			selections = append(selections, ComponentSelection{
+				Name:       component.Name,
+				Components: append([]string{component.Name}, groupMembers(component.Group)...),
+			})
+		}
+		return selections, nil
+	default:
+		return nil, fmt.Errorf("invalid component matrix %q, must be one of: %s, %s, %s",
+			mode, ComponentMatrixFull, ComponentMatrixMinimal, ComponentMatrixEach)
+	}
+}
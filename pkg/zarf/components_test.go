@@ -0,0 +1,78 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentMatrix(t *testing.T) {
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{Name: "podinfo", Required: true},
+			{Name: "redis"},
+			{Name: "monitoring", Default: true},
+			{Name: "ingress-nginx", Group: "ingress"},
+			{Name: "ingress-traefik", Group: "ingress", Default: true},
+		},
+	}
+
+	tests := []struct {
+		mode     string
+		expected []ComponentSelection
+	}{
+		{
+			mode:     "",
+			expected: nil,
+		},
+		{
+			mode: ComponentMatrixFull,
+			expected: []ComponentSelection{
+				{Name: "full", Components: []string{"redis", "monitoring", "ingress-traefik"}},
+			},
+		},
+		{
+			mode: ComponentMatrixMinimal,
+			expected: []ComponentSelection{
+				{Name: "minimal", Components: []string{"ingress-traefik", "-redis", "-monitoring"}},
+			},
+		},
+		{
+			mode: ComponentMatrixEach,
+			expected: []ComponentSelection{
+				{Name: "minimal", Components: []string{"ingress-traefik", "-redis", "-monitoring"}},
+				{Name: "redis", Components: []string{"redis", "ingress-traefik"}},
+				{Name: "monitoring", Components: []string{"monitoring", "ingress-traefik"}},
+				{Name: "ingress-nginx", Components: []string{"ingress-nginx"}},
+				{Name: "ingress-traefik", Components: []string{"ingress-traefik"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			selections, err := ComponentMatrix(zarfYaml, tt.mode)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, selections)
+		})
+	}
+
+	_, err := ComponentMatrix(zarfYaml, "all")
+	assert.EqualError(t, err, `invalid component matrix "all", must be one of: full, minimal, each`)
+}
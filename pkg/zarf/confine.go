@@ -0,0 +1,124 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// packageReference is a path in the package a component refers to
+type packageReference struct {
+	component string
+	field     string // Location of the reference in zarf.yaml, e.g. components[0].files[1].source
+	path      string
+}
+
+// fileReferences returns the files and directories of the package the components
+// refer to, with their location in zarf.yaml and without remote references. Unlike
+// localReferences, imports are not included.
+func fileReferences(zarfYaml *util.ZarfYaml) []packageReference {
+	var references []packageReference
+	add := func(component, field, path string) {
+		if path != "" && !isRemoteReference(path) {
+			references = append(references, packageReference{component, field, path})
+		}
+	}
+
+	for i, component := range zarfYaml.Components {
+		prefix := fmt.Sprintf("components[%d]", i)
+
+		for j, file := range component.Files {
+			add(component.Name, fmt.Sprintf("%s.files[%d].source", prefix, j), file.Source)
+		}
+
+		for j, chart := range component.Charts {
+			add(component.Name, fmt.Sprintf("%s.charts[%d].localPath", prefix, j), chart.LocalPath)
+			for k, valuesFile := range chart.ValuesFiles {
+				add(component.Name, fmt.Sprintf("%s.charts[%d].valuesFiles[%d]", prefix, j, k), valuesFile)
+			}
+		}
+
+		for j, manifest := range component.Manifests {
+			for k, file := range manifest.Files {
+				add(component.Name, fmt.Sprintf("%s.manifests[%d].files[%d]", prefix, j, k), file)
+			}
+			for k, kustomization := range manifest.Kustomizations {
+				add(component.Name, fmt.Sprintf("%s.manifests[%d].kustomizations[%d]", prefix, j, k), kustomization)
+			}
+		}
+
+		for j, injection := range component.DataInjections {
+			add(component.Name, fmt.Sprintf("%s.dataInjections[%d].source", prefix, j), injection.Source)
+		}
+	}
+	return references
+}
+
+// validateConfinedPaths reports the references of the package to paths outside of
+// its directory as errors, without reading them. It returns false if there are any,
+// in which case the package must not be validated further: zarf dev lint and the
+// built-in rules would read the referenced files and disclose their content in the
+// findings.
+func validateConfinedPaths(pkg *PackageContext, result *ValidationResult) bool {
+	references := fileReferences(pkg.ZarfYaml)
+	for i, component := range pkg.ZarfYaml.Components {
+		if component.Import.Path != "" {
+			references = append(references, packageReference{component.Name, fmt.Sprintf("components[%d].import.path", i), component.Import.Path})
+		}
+	}
+
+	confined := true
+	for _, reference := range references {
+		if outsidePackage(pkg.Path, reference.path) {
+			result.AddFinding(pkg.at(reference.field, "file-reference", SeverityError,
+				fmt.Sprintf("Component '%s' references path outside of the package at %s: %s", reference.component, reference.field, reference.path)))
+			confined = false
+		}
+	}
+	return confined
+}
+
+// outsidePackage reports whether path, relative to the package directory, resolves
+// outside of it. Links are followed only for paths within the package.
+func outsidePackage(packagePath, path string) bool {
+	if filepath.IsAbs(path) {
+		return true
+	}
+	target := filepath.Join(packagePath, path)
+	if !withinDir(packagePath, target) {
+		return true
+	}
+
+	root, err := filepath.EvalSymlinks(packagePath)
+	if err != nil {
+		return false
+	}
+	resolved, err := filepath.EvalSymlinks(target)
+	if err != nil {
+		// Missing paths are reported by the file reference validation
+		return false
+	}
+	return !withinDir(root, resolved)
+}
+
+// withinDir reports whether path is dir or a path below it
+func withinDir(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
@@ -0,0 +1,100 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateConfinedPaths(t *testing.T) {
+	root := t.TempDir()
+	packageDir := filepath.Join(root, "app")
+	writePackage(t, packageDir, `  - name: app
+    files:
+      - source: config/app.yaml
+        target: /etc/app.yaml
+      - source: https://example.com/app.yaml
+        target: /etc/remote.yaml
+      - source: /etc/passwd
+        target: /etc/passwd
+    manifests:
+      - name: app
+        files:
+          - manifests/../manifests/app.yaml
+          - ../outside/secret.yaml
+          - linked/secret.yaml
+  - name: shared
+    import:
+      path: ../common
+`)
+	require.NoError(t, os.MkdirAll(filepath.Join(root, "outside"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "outside", "secret.yaml"), []byte("kind: Secret\n"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(root, "outside"), filepath.Join(packageDir, "linked")))
+
+	result := &ValidationResult{Valid: true}
+	assert.False(t, validateConfinedPaths(loadPackage(t, packageDir), result))
+	assert.False(t, result.Valid)
+	assert.Equal(t, []string{
+		"Component 'app' references path outside of the package at components[0].files[2].source: /etc/passwd",
+		"Component 'app' references path outside of the package at components[0].manifests[0].files[1]: ../outside/secret.yaml",
+		"Component 'app' references path outside of the package at components[0].manifests[0].files[2]: linked/secret.yaml",
+		"Component 'shared' references path outside of the package at components[1].import.path: ../common",
+	}, result.Errors)
+
+	writePackage(t, packageDir, "  - name: app\n    manifests:\n      - name: app\n        files:\n          - manifests/app.yaml\n")
+	result = &ValidationResult{Valid: true}
+	assert.True(t, validateConfinedPaths(loadPackage(t, packageDir), result))
+	assert.Empty(t, result.Findings)
+}
+
+func TestValidatePackageConfinePaths(t *testing.T) {
+	// zarf dev lint would read the referenced file, so it must not run either
+	calls := filepath.Join(t.TempDir(), "calls")
+	fakeCommands(t, map[string]string{"zarf": `echo "zarf $*" >> ` + calls + `; echo v0.60.0`})
+
+	root := t.TempDir()
+	packageDir := filepath.Join(root, "app")
+	writePackage(t, packageDir, "  - name: app\n    required: true\n    manifests:\n      - name: app\n        files:\n          - ../secret.yaml\n")
+	require.NoError(t, os.WriteFile(filepath.Join(root, "secret.yaml"), []byte("apiVersion: v1\nkind: Secret\nmetadata:\n  name: host-secret\n"), 0644))
+
+	v := NewPackageValidator()
+	v.CheckVersionIncrement = false
+	v.ConfinePaths = true
+	result, err := v.ValidatePackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	assert.False(t, result.Valid)
+	assert.Equal(t, []Finding{{
+		RuleID:   "file-reference",
+		Severity: SeverityError,
+		Message:  "Component 'app' references path outside of the package at components[0].manifests[0].files[0]: ../secret.yaml",
+		File:     "zarf.yaml",
+		Line:     10,
+		Column:   13,
+	}}, result.Findings)
+	assert.NoFileExists(t, calls)
+
+	// Without confinement, the file is read like any other manifest
+	v.ConfinePaths = false
+	result, err = v.ValidatePackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	assert.FileExists(t, calls)
+	assert.Contains(t, result.Warnings, "Component 'app' manifest ../secret.yaml declares no namespace for Secret/host-secret, which land in the namespace of the kubeconfig context zarf deploys with")
+}
@@ -0,0 +1,215 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// customResourceDefinition is a CRD a component of the package installs
+type customResourceDefinition struct {
+	Name      string // <plural>.<group>
+	Group     string
+	Kind      string
+	Scope     string // Namespaced or Cluster
+	Component int    // Index of the component in zarf.yaml
+	Path      string // Manifest or chart the CRD comes from, relative to the package
+	// ChartCRD is set for CRDs in the crds directory of a chart, which helm installs
+	// and waits for before the templates of the chart
+	ChartCRD bool
+}
+
+// customResourceDefinitions returns the CRDs of the rendered manifests and of the crds
+// directories of the local charts of the package
+func (pkg *PackageContext) customResourceDefinitions(ctx context.Context) []customResourceDefinition {
+	indexes := componentIndexes(pkg.ZarfYaml)
+	var crds []customResourceDefinition
+	add := func(component int, path string, chartCRD bool, docs []map[string]interface{}) {
+		for _, doc := range docs {
+			if kind, _ := doc["kind"].(string); kind != "CustomResourceDefinition" {
+				continue
+			}
+			metadata, _ := toStringMap(doc["metadata"])
+			spec, _ := toStringMap(doc["spec"])
+			names, _ := toStringMap(spec["names"])
+			crd := customResourceDefinition{Component: component, Path: path, ChartCRD: chartCRD}
+			crd.Name, _ = metadata["name"].(string)
+			crd.Group, _ = spec["group"].(string)
+			crd.Kind, _ = names["kind"].(string)
+			crd.Scope, _ = spec["scope"].(string)
+			if crd.Name != "" && crd.Group != "" && crd.Kind != "" {
+				crds = append(crds, crd)
+			}
+		}
+	}
+
+	for _, manifest := range pkg.renderedManifests(ctx) {
+		if manifest.Err == nil {
+			add(indexes[manifest.Component], manifest.Path, false, manifest.documents())
+		}
+	}
+	for i, component := range pkg.ZarfYaml.Components {
+		for _, chart := range component.Charts {
+			if chart.LocalPath == "" {
+				continue
+			}
+			files, _ := filepath.Glob(filepath.Join(pkg.Path, chart.LocalPath, "crds", "*.y*ml"))
+			for _, file := range files {
+				content, err := os.ReadFile(file)
+				if err != nil {
+					continue
+				}
+				add(i, chart.LocalPath, true, renderedManifest{Content: content}.documents())
+			}
+		}
+	}
+	return crds
+}
+
+// componentIndexes maps the component names to their index in zarf.yaml. The first of
+// components sharing a name wins.
+func componentIndexes(zarfYaml *util.ZarfYaml) map[string]int {
+	indexes := map[string]int{}
+	for i, component := range zarfYaml.Components {
+		if _, ok := indexes[component.Name]; !ok {
+			indexes[component.Name] = i
+		}
+	}
+	return indexes
+}
+
+// waitsForCRD reports whether an onDeploy action of the component in one of the stages
+// waits for the CRD
+func waitsForCRD(component util.ZarfComponent, crd customResourceDefinition, stages ...string) bool {
+	for _, action := range componentActions(component) {
+		if action.Event != "onDeploy" || !contains(stages, action.Stage) {
+			continue
+		}
+		if action.Action.Wait == nil || action.Action.Wait.Cluster == nil {
+			continue
+		}
+		wait := action.Action.Wait.Cluster
+		if wait.Name == crd.Name && contains(waitKinds(wait.Kind), "customresourcedefinition") {
+			return true
+		}
+	}
+	return false
+}
+
+// validateCRDOrdering reports custom resources deployed before their CRD is established:
+// zarf applies the objects of a component together, so a custom resource in the component
+// of its CRD races with the CRD, as does one in a later component without a wait for the
+// CRD. A custom resource in a component before the one of its CRD never deploys.
+func (v *PackageValidator) validateCRDOrdering(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	crds := pkg.customResourceDefinitions(ctx)
+	if len(crds) == 0 {
+		return nil
+	}
+	byKind := map[string]customResourceDefinition{}
+	for _, crd := range crds {
+		if _, ok := byKind[crd.Group+"/"+crd.Kind]; !ok {
+			byKind[crd.Group+"/"+crd.Kind] = crd
+		}
+	}
+
+	indexes := componentIndexes(pkg.ZarfYaml)
+	reported := map[string]bool{}
+	for _, manifest := range pkg.renderedManifests(ctx) {
+		if manifest.Err != nil {
+			continue
+		}
+		index := indexes[manifest.Component]
+		for _, doc := range manifest.documents() {
+			apiVersion, _ := doc["apiVersion"].(string)
+			kind, _ := doc["kind"].(string)
+			group, _, found := strings.Cut(apiVersion, "/")
+			crd, ok := byKind[group+"/"+kind]
+			if !found || !ok {
+				continue
+			}
+			key := fmt.Sprintf("%d/%s/%s", index, manifest.Path, crd.Name)
+			if reported[key] {
+				continue
+			}
+
+			component := pkg.ZarfYaml.Components[index]
+			crdComponent := pkg.ZarfYaml.Components[crd.Component]
+			switch {
+			case crd.Component > index:
+				result.AddError("crd-ordering", fmt.Sprintf(
+					"Component '%s' %s creates %s resources before component '%s' installs the CRD %s, move the CRD to an earlier component",
+					component.Name, manifest.Path, kind, crdComponent.Name, crd.Name))
+			case crd.Component == index:
+				if crd.ChartCRD && manifest.Kind == sourceChart && crd.Path == manifest.Path {
+					continue
+				}
+				result.AddWarning("crd-ordering", fmt.Sprintf(
+					"Component '%s' %s creates %s resources in the component installing the CRD %s, which races with the CRD at deploy time; move the CRD to its own component and add it to depsWith with an onDeploy.after wait for the CRD to be established",
+					component.Name, manifest.Path, kind, crd.Name))
+			default:
+				if waitsForCRD(crdComponent, crd, "after", "onSuccess") || waitsForCRD(component, crd, "before") {
+					continue
+				}
+				result.AddWarning("crd-ordering", fmt.Sprintf(
+					"Component '%s' %s creates %s resources without waiting for the CRD %s of component '%s' to be established, add an onDeploy.after wait for it to component '%s'",
+					component.Name, manifest.Path, kind, crd.Name, crdComponent.Name, crdComponent.Name))
+			}
+			reported[key] = true
+		}
+	}
+	return nil
+}
+
+// verifyCRDs checks that the CRDs of every deployed component are established
+func (d *PackageDeployer) verifyCRDs(ctx context.Context, name, packagePath string, zarfYaml *util.ZarfYaml, selection *ComponentSelection) []ComponentTestResult {
+	pkg, err := LoadPackageContext(packagePath)
+	if err != nil {
+		return nil
+	}
+	deployed := map[string]bool{}
+	for _, component := range deployedComponents(zarfYaml, selection) {
+		deployed[component.Name] = true
+	}
+
+	var results []ComponentTestResult
+	verified := map[string]bool{}
+	for _, crd := range pkg.customResourceDefinitions(ctx) {
+		if !deployed[pkg.ZarfYaml.Components[crd.Component].Name] || verified[crd.Name] {
+			continue
+		}
+		verified[crd.Name] = true
+		result := ComponentTestResult{
+			ComponentName: "crd/" + crd.Name,
+			Success:       true,
+			Message:       "established",
+		}
+		wait := util.ZarfComponentActionWaitCluster{Kind: "crd", Name: crd.Name, Condition: "established"}
+		if err := d.assertWait(ctx, name, wait, ""); err != nil {
+			result.Success = false
+			result.Message = err.Error()
+		}
+		results = append(results, result)
+		if ctx.Err() != nil {
+			return results
+		}
+	}
+	return results
+}
@@ -0,0 +1,106 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCRDOrdering(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: widgets
+components:
+  - name: early
+    required: true
+    manifests:
+      - name: early
+        files: [early.yaml]
+  - name: crds
+    required: true
+    manifests:
+      - name: crds
+        files: [crds.yaml, same.yaml]
+  - name: unwaited
+    required: true
+    manifests:
+      - name: unwaited
+        files: [widget.yaml]
+  - name: waited
+    required: true
+    manifests:
+      - name: waited
+        files: [widget.yaml]
+    actions:
+      onDeploy:
+        before:
+          - wait:
+              cluster:
+                kind: crd
+                name: widgets.example.com
+                condition: established
+  - name: gadgets
+    required: true
+    charts:
+      - name: gadgets
+        localPath: chart
+        namespace: gadgets
+`
+	crd := func(plural, kind string) string {
+		return "apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\nmetadata:\n  name: " + plural +
+			".example.com\nspec:\n  group: example.com\n  names:\n    kind: " + kind + "\n    plural: " + plural + "\n"
+	}
+	files := map[string]string{
+		"zarf.yaml":                   zarfYaml,
+		"crds.yaml":                   crd("widgets", "Widget"),
+		"early.yaml":                  "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: early\n",
+		"same.yaml":                   "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: same\n",
+		"widget.yaml":                 "apiVersion: example.com/v1\nkind: Widget\nmetadata:\n  name: later\n",
+		"chart/Chart.yaml":            "apiVersion: v2\nname: gadgets\nversion: 1.0.0\n",
+		"chart/crds/gadgets.yaml":     crd("gadgets", "Gadget"),
+		"chart/templates/gadget.yaml": "apiVersion: example.com/v1\nkind: Gadget\nmetadata:\n  name: gadget\n",
+	}
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(packageDir, name)), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(packageDir, name), []byte(content), 0644))
+	}
+	fakeCommands(t, map[string]string{"helm": `printf 'apiVersion: example.com/v1\nkind: Gadget\nmetadata:\n  name: gadget\n'`})
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	// Helm installs the crds directory of a chart before its templates
+	result := &ValidationResult{}
+	require.NoError(t, NewPackageValidator().validateCRDOrdering(context.Background(), pkg, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "crd-ordering", Severity: SeverityError, Message: "Component 'early' early.yaml creates Widget resources before component 'crds' installs the CRD widgets.example.com, move the CRD to an earlier component"},
+		{RuleID: "crd-ordering", Severity: SeverityWarning, Message: "Component 'crds' same.yaml creates Widget resources in the component installing the CRD widgets.example.com, which races with the CRD at deploy time; move the CRD to its own component and add it to depsWith with an onDeploy.after wait for the CRD to be established"},
+		{RuleID: "crd-ordering", Severity: SeverityWarning, Message: "Component 'unwaited' widget.yaml creates Widget resources without waiting for the CRD widgets.example.com of component 'crds' to be established, add an onDeploy.after wait for it to component 'crds'"},
+	}, result.Findings)
+
+	// Install tests check that the CRDs of the deployed components are established
+	fakeCommands(t, map[string]string{"kubectl": `[ "$3" = gadgets.example.com ] && exit 1; exit 0`})
+	results := NewPackageDeployer().verifyCRDs(context.Background(), "widgets", packageDir, pkg.ZarfYaml, nil)
+	require.Len(t, results, 2)
+	assert.Equal(t, ComponentTestResult{ComponentName: "crd/widgets.example.com", Success: true, Message: "established"}, results[0])
+	assert.Equal(t, "crd/gadgets.example.com", results[1].ComponentName)
+	assert.False(t, results[1].Success)
+}
@@ -0,0 +1,307 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// dataInjectionCompressThreshold is the size of a data injection source above which
+// compressing it is recommended
+const dataInjectionCompressThreshold = 10 << 20
+
+var (
+	// labelKeyName and labelValue follow the syntax of Kubernetes label keys and values
+	labelKeyName = regexp.MustCompile(`^[A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?$`)
+	labelPrefix  = regexp.MustCompile(`^[a-z0-9]([-a-z0-9]*[a-z0-9])?(\.[a-z0-9]([-a-z0-9]*[a-z0-9])?)*$`)
+	labelValue   = regexp.MustCompile(`^([A-Za-z0-9]([-A-Za-z0-9_.]{0,61}[A-Za-z0-9])?)?$`)
+
+	// selectorSetTerm matches 'key in (a,b)' and 'key notin (a,b)' requirements
+	selectorSetTerm = regexp.MustCompile(`^(\S+)\s+(in|notin)\s+\(([^()]*)\)$`)
+
+	// checksumLine matches a line of sha256sum output
+	checksumLine = regexp.MustCompile(`^([0-9a-f]{64})\s+\*?(.+)$`)
+)
+
+// validateLabelSelector checks that selector is a valid Kubernetes label selector,
+// e.g. 'app=web,tier in (frontend,backend),!legacy'
+func validateLabelSelector(selector string) error {
+	if strings.TrimSpace(selector) == "" {
+		return fmt.Errorf("selector is empty")
+	}
+
+	// Commas separate requirements, except within the values of set requirements
+	var terms []string
+	depth, start := 0, 0
+	for i, c := range selector {
+		switch c {
+		case '(':
+			depth++
+		case ')':
+			depth--
+		case ',':
+			if depth == 0 {
+				terms = append(terms, selector[start:i])
+				start = i + 1
+			}
+		}
+		if depth < 0 || depth > 1 {
+			return fmt.Errorf("unbalanced parentheses")
+		}
+	}
+	if depth != 0 {
+		return fmt.Errorf("unbalanced parentheses")
+	}
+	terms = append(terms, selector[start:])
+
+	for _, term := range terms {
+		term = strings.TrimSpace(term)
+		if match := selectorSetTerm.FindStringSubmatch(term); match != nil {
+			if err := validateLabelKey(match[1]); err != nil {
+				return err
+			}
+			for _, value := range strings.Split(match[3], ",") {
+				if err := validateLabelValue(strings.TrimSpace(value)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		var key, value string
+		var hasValue bool
+		for _, operator := range []string{"!=", "==", "="} {
+			if key, value, hasValue = strings.Cut(term, operator); hasValue {
+				break
+			}
+		}
+		if !hasValue {
+			key = strings.TrimPrefix(term, "!")
+		}
+		if err := validateLabelKey(strings.TrimSpace(key)); err != nil {
+			return err
+		}
+		if err := validateLabelValue(strings.TrimSpace(value)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateLabelKey(key string) error {
+	prefix, name, hasPrefix := strings.Cut(key, "/")
+	if !hasPrefix {
+		name, prefix = prefix, ""
+	}
+	if !labelKeyName.MatchString(name) || (hasPrefix && (len(prefix) > 253 || !labelPrefix.MatchString(prefix))) {
+		return fmt.Errorf("invalid label key %q", key)
+	}
+	return nil
+}
+
+func validateLabelValue(value string) error {
+	if !labelValue.MatchString(value) {
+		return fmt.Errorf("invalid label value %q", value)
+	}
+	return nil
+}
+
+// validateDataInjections checks the targets of the data injections of every component:
+// the target must name a namespace, a valid label selector, a container and an
+// absolute path. Large local sources should be compressed.
+func (v *PackageValidator) validateDataInjections(pkg *PackageContext, result *ValidationResult) error {
+	for _, component := range pkg.ZarfYaml.Components {
+		for i, injection := range component.DataInjections {
+			field := fmt.Sprintf("Component '%s' dataInjections[%d]", component.Name, i)
+			target := injection.Target
+			if target.Namespace == "" {
+				result.AddError("data-injection", field+" target must set a namespace")
+			}
+			if err := validateLabelSelector(target.Selector); err != nil {
+				result.AddError("data-injection", fmt.Sprintf("%s target selector %q is not a valid label selector: %v", field, target.Selector, err))
+			}
+			if target.Container == "" {
+				result.AddError("data-injection", field+" target must set a container")
+			}
+			if !path.IsAbs(target.Path) {
+				result.AddError("data-injection", fmt.Sprintf("%s target path %q must be absolute", field, target.Path))
+			}
+
+			if injection.Compress || injection.Source == "" || isRemoteReference(injection.Source) {
+				continue
+			}
+			size, err := sourceSize(filepath.Join(pkg.Path, injection.Source))
+			if err == nil && size > dataInjectionCompressThreshold {
+				result.AddWarning("data-injection", fmt.Sprintf("%s source %s is %s, set 'compress: true' to speed up the injection",
+					field, injection.Source, util.FormatSize(size)))
+			}
+		}
+	}
+	return nil
+}
+
+// sourceSize returns the size of a file, or of the files in a directory
+func sourceSize(source string) (int64, error) {
+	var size int64
+	err := filepath.WalkDir(source, func(_ string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		size += info.Size()
+		return nil
+	})
+	return size, err
+}
+
+// injectedFiles returns the SHA-256 checksums of the files of a local data injection
+// source, keyed by their path in the target container. The files of a directory are
+// injected into the target path, a single file into the target path under its name.
+func injectedFiles(source, targetPath string) (map[string]string, error) {
+	checksums := map[string]string{}
+	err := filepath.WalkDir(source, func(file string, entry fs.DirEntry, err error) error {
+		if err != nil || !entry.Type().IsRegular() {
+			return err
+		}
+		rel, err := filepath.Rel(source, file)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			rel = filepath.Base(file)
+		}
+		sum, err := fileChecksum(file)
+		if err != nil {
+			return err
+		}
+		checksums[path.Join(targetPath, filepath.ToSlash(rel))] = sum
+		return nil
+	})
+	return checksums, err
+}
+
+func fileChecksum(file string) (string, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// verifyDataInjections checks that the local data injection sources of every deployed
+// component arrived in their target containers, comparing the SHA-256 checksums of the
+// files in the first pod matching the target selector with the sources
+func (d *PackageDeployer) verifyDataInjections(ctx context.Context, name, packagePath string, zarfYaml *util.ZarfYaml, selection *ComponentSelection, namespaces namespaceMapping) []ComponentTestResult {
+	var results []ComponentTestResult
+	for _, component := range deployedComponents(zarfYaml, selection) {
+		for _, injection := range component.DataInjections {
+			if injection.Source == "" || isRemoteReference(injection.Source) {
+				continue
+			}
+			target := injection.Target
+			result := ComponentTestResult{ComponentName: fmt.Sprintf("data/%s/%s:%s", component.Name, target.Container, target.Path), Success: true}
+			matched, err := d.assertInjectedData(ctx, name, filepath.Join(packagePath, injection.Source), target, namespaces.resolve(target.Namespace))
+			if err != nil {
+				result.Success = false
+				result.Message = err.Error()
+			} else {
+				result.Message = fmt.Sprintf("%d file(s) match the source", matched)
+			}
+			results = append(results, result)
+			if ctx.Err() != nil {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+// assertInjectedData compares the checksums of the files of source with the files in
+// the target container and returns the number of matching files
+func (d *PackageDeployer) assertInjectedData(ctx context.Context, name, source string, target util.ZarfContainerTarget, namespace string) (int, error) {
+	expected, err := injectedFiles(source, target.Path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read the source: %w", err)
+	}
+
+	pod, err := d.run(ctx, name, "", "kubectl", "get", "pods", namespaceArgs(namespace), "--selector", target.Selector,
+		"--output", "jsonpath={.items[0].metadata.name}")
+	if err != nil {
+		return 0, err
+	}
+	if pod = strings.TrimSpace(pod); pod == "" {
+		return 0, fmt.Errorf("no pod matches selector %s", target.Selector)
+	}
+
+	files := make([]string, 0, len(expected))
+	for file := range expected {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	// Missing files are detected by comparing the output, so errors are ignored
+	output, err := d.run(ctx, name, "", "kubectl", "exec", pod, namespaceArgs(namespace), "--container", target.Container,
+		"--", "sh", "-c", `sha256sum "$@" 2>/dev/null; true`, "sh", files)
+	if err != nil {
+		return 0, err
+	}
+	actual := map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if match := checksumLine.FindStringSubmatch(strings.TrimSpace(line)); match != nil {
+			actual[match[2]] = match[1]
+		}
+	}
+
+	var missing, changed []string
+	for _, file := range files {
+		switch sum, ok := actual[file]; {
+		case !ok:
+			missing = append(missing, file)
+		case sum != expected[file]:
+			changed = append(changed, file)
+		}
+	}
+	var problems []string
+	if len(missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing %s", strings.Join(missing, ", ")))
+	}
+	if len(changed) > 0 {
+		problems = append(problems, fmt.Sprintf("checksum mismatch of %s", strings.Join(changed, ", ")))
+	}
+	if len(problems) > 0 {
+		return 0, fmt.Errorf("%s in pod %s", strings.Join(problems, "; "), pod)
+	}
+	return len(files), nil
+}
@@ -0,0 +1,133 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLabelSelector(t *testing.T) {
+	for _, selector := range []string{
+		"app=web",
+		"app==web,tier!=cache",
+		"app.kubernetes.io/name=podinfo, environment in (dev, test),!legacy",
+		"zarf.dev/agent notin (ignore),ready",
+		"app=",
+	} {
+		assert.NoError(t, validateLabelSelector(selector), selector)
+	}
+
+	for selector, message := range map[string]string{
+		"":                    "selector is empty",
+		"app=web server":      `invalid label value "web server"`,
+		"-app=web":            `invalid label key "-app"`,
+		"Example.com/app=web": `invalid label key "Example.com/app"`,
+		"tier in (a,b":        "unbalanced parentheses",
+		"tier in ((a))":       "unbalanced parentheses",
+		"app=web,":            `invalid label key ""`,
+	} {
+		assert.EqualError(t, validateLabelSelector(selector), message, selector)
+	}
+}
+
+func TestValidateDataInjections(t *testing.T) {
+	packageDir := t.TempDir()
+	writePackage(t, packageDir, `  - name: data
+    dataInjections:
+      - source: small
+        target:
+          namespace: podinfo
+          selector: app=podinfo
+          container: data-loader
+          path: /data
+      - source: large.bin
+        target:
+          selector: app in (podinfo
+          path: data
+      - source: large.bin
+        compress: true
+        target:
+          namespace: podinfo
+          selector: app=podinfo
+          container: data-loader
+          path: /data
+`)
+	require.NoError(t, os.MkdirAll(filepath.Join(packageDir, "small"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "small", "file.txt"), []byte("data"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "large.bin"), make([]byte, dataInjectionCompressThreshold+1), 0644))
+
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+	result := &ValidationResult{}
+	require.NoError(t, NewPackageValidator().validateDataInjections(pkg, result))
+	assert.Equal(t, []string{
+		"Component 'data' dataInjections[1] target must set a namespace",
+		`Component 'data' dataInjections[1] target selector "app in (podinfo" is not a valid label selector: unbalanced parentheses`,
+		"Component 'data' dataInjections[1] target must set a container",
+		`Component 'data' dataInjections[1] target path "data" must be absolute`,
+	}, result.Errors)
+	assert.Equal(t, []string{
+		"Component 'data' dataInjections[1] source large.bin is 10.0MiB, set 'compress: true' to speed up the injection",
+	}, result.Warnings)
+}
+
+func TestVerifyDataInjections(t *testing.T) {
+	packageDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(packageDir, "data", "nested"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "data", "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "data", "nested", "b.txt"), []byte("b"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "config.json"), []byte("{}"), 0644))
+
+	const (
+		sumA      = "ca978112ca1bbdcafac231b39a23dc4da786eff8147c4e72b9807785afee48bb"
+		sumB      = "3e23e8160039594a33894f6564e1b1348bbd7a0088d42c4acb73eeaed59c009d"
+		sumConfig = "44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+	)
+	// The pod has the files of the directory, config.json differs from the source
+	fakeCommands(t, map[string]string{
+		"kubectl": `case "$1" in
+get) echo loader-0 ;;
+exec) echo "` + sumA + `  /data/a.txt"; echo "` + sumB + `  /data/nested/b.txt"; echo "` + sumA + `  /etc/app/config.json" ;;
+esac`,
+	})
+
+	zarfYaml := &util.ZarfYaml{Components: []util.ZarfComponent{{
+		Name:     "data",
+		Required: true,
+		DataInjections: []util.ZarfDataInjection{
+			{Source: "data", Target: util.ZarfContainerTarget{Namespace: "podinfo", Selector: "app=loader", Container: "loader", Path: "/data"}},
+			{Source: "config.json", Target: util.ZarfContainerTarget{Namespace: "podinfo", Selector: "app=loader", Container: "loader", Path: "/etc/app"}},
+			{Source: "https://example.com/data.tar", Target: util.ZarfContainerTarget{Namespace: "podinfo", Selector: "app=loader", Container: "loader", Path: "/remote"}},
+		},
+	}}}
+
+	d := NewPackageDeployer()
+	results := d.verifyDataInjections(context.Background(), "podinfo", packageDir, zarfYaml, nil, namespaceMapping{})
+	assert.Equal(t, []ComponentTestResult{
+		{ComponentName: "data/data/loader:/data", Success: true, Message: "2 file(s) match the source"},
+		{ComponentName: "data/data/loader:/etc/app", Success: false, Message: "checksum mismatch of /etc/app/config.json in pod loader-0"},
+	}, results)
+
+	checksums, err := injectedFiles(filepath.Join(packageDir, "config.json"), "/etc/app")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"/etc/app/config.json": sumConfig}, checksums)
+}
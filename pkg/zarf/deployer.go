@@ -15,14 +15,20 @@
 package zarf
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/cpepper96/zarf-testing/pkg/config"
 	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/tracing"
 	"github.com/cpepper96/zarf-testing/pkg/util"
 )
 
@@ -30,10 +36,20 @@ import (
 type DeploymentResult struct {
 	PackagePath    string
 	Success        bool
+	TimedOut       bool // Whether the test failed because a timeout expired
 	DeployTime     time.Duration
 	Errors         []string
 	Warnings       []string
 	ComponentTests []ComponentTestResult
+	VariableSet    string            // Name of the variable set the package was deployed with
+	Components     string            // Name of the component selection the package was deployed with
+	Artifacts      []string          // Diagnostics collected from the cluster after a failure
+	Leaked         []ClusterResource // Resources left behind after the package was removed
+	Cluster        string            // Name of the cluster deployed to, empty for the current context
+	Cycles         int               // Number of soak cycles run, zero without soaking
+	Benchmark      *Benchmark        // Timings of the deployment, if benchmarking is enabled and it was deployed
+	RemoveTime     time.Duration     // Duration of the package removal, if the removal was tested
+	Mirror         bool              // Whether the result is of mirroring the package resources instead of a deployment
 }
 
 // ComponentTestResult represents the test result for a single component
@@ -41,29 +57,85 @@ type ComponentTestResult struct {
 	ComponentName string
 	Success       bool
 	Message       string
+	Status        int           // Status of the last request of an HTTP probe
+	Latency       time.Duration // Latency of the last request of an HTTP probe
 }
 
 // PackageDeployer handles Zarf package deployment testing
 type PackageDeployer struct {
 	UseZarfCLI    bool
-	Timeout       time.Duration
+	Timeout       time.Duration // Timeout for building and deploying a package
+	TestTimeout   time.Duration // Timeout for testing a deployed package
 	SkipCleanup   bool
-	TestNamespace string
+	TestNamespace string // Prefix of the generated namespaces packages are deployed to
+	Namespace     string // Namespace to deploy to instead of a generated one
+	StreamOutput  bool   // Print the output of zarf and kubectl while they run
+	ArtifactsDir  string // Directory for diagnostics of failed packages, collection is disabled if empty
+	CheckCleanup  bool   // Compare the cluster before deploying and after removal
+	VariableSets  []string // Names of the variable sets to deploy, all if empty
+	ComponentMatrix string // Component selections to deploy: full, minimal, each or empty for the defaults
+	Kubeconfig    string // Kubeconfig of the cluster to deploy to, the default kubeconfig if empty
+	ZarfArgs      ZarfArgs // Additional arguments for zarf commands
+	BuildCacheDir string   // Directory to cache built packages in by their inputs, packages are rebuilt every time if empty
+	InitPackage   string   // Init package to initialize clusters with instead of the default init package of zarf
+
+	// SoakCycles is how many times every deployment is deployed, tested and removed.
+	// With more than one cycle, each cycle fails if the cluster does not return to
+	// the state before the cycle, as with CheckCleanup, or namespaces remain.
+	SoakCycles int
+
+	// Resilience deletes the pods of the deployed workloads after the tests passed and
+	// fails the deployment unless every workload recovers within ResilienceTimeout
+	Resilience        bool
+	ResilienceTimeout time.Duration
+
+	// MirrorResources tests mirroring the resources of every package to Mirror with
+	// 'zarf package mirror-resources', in addition to deploying it
+	MirrorResources bool
+	Mirror          MirrorTarget
+
+	// TestRemoval makes removing a package that deployed and passed its tests a tested
+	// phase: removal failures and workloads remaining in the namespaces created by the
+	// deployment fail it, and the removal time is recorded
+	TestRemoval bool
+
+	// Benchmark records the timings of every deployment, which fail if they exceed
+	// Thresholds. The deploy time is compared against the Baseline of the deployment
+	// on Cluster.
+	Benchmark  bool
+	Thresholds BenchmarkThresholds
+	Baseline   BenchmarkBaseline
+	Cluster    string
+
+	// KeepGoing makes DeployPackages record an error deploying a package in its result
+	// and continue with the next package, including after failed deployments
+	KeepGoing bool
+
+	// PackageStarted is called when the deployment of a package starts when set. With
+	// DeployPackagesInParallel it is called from several goroutines.
+	PackageStarted func(packagePath string)
+
+	output io.Writer    // Where streamed output is printed, stdout if nil
+	locks  *deployLocks // Coordinates concurrent deployments, nil when deploying one at a time
 }
 
 // Deployer provides Zarf package deployment testing functionality
 type Deployer struct {
-	config   *config.Configuration
-	deployer *PackageDeployer
+	config      *config.Configuration
+	deployer    *PackageDeployer
+	kindCluster string // Name of the kind cluster created for the deployer, if any
 }
 
 // NewPackageDeployer creates a new package deployer
 func NewPackageDeployer() *PackageDeployer {
 	return &PackageDeployer{
-		UseZarfCLI:    true,
-		Timeout:       10 * time.Minute,
-		SkipCleanup:   false,
-		TestNamespace: "zt-test", // Will be made unique per test
+		UseZarfCLI:        true,
+		Timeout:           10 * time.Minute,
+		TestTimeout:       5 * time.Minute,
+		SkipCleanup:       false,
+		TestNamespace:     "zt-test", // Will be made unique per test
+		KeepGoing:         true,
+		ResilienceTimeout: 5 * time.Minute,
 	}
 }
 
@@ -73,104 +145,563 @@ func NewDeployer(config *config.Configuration) (*Deployer, error) {
 		config:   config,
 		deployer: NewPackageDeployer(),
 	}
+	if config.DeploymentTimeout > 0 {
+		deployer.deployer.Timeout = config.DeploymentTimeout
+	}
+	if config.TestTimeout > 0 {
+		deployer.deployer.TestTimeout = config.TestTimeout
+	}
+	deployer.deployer.StreamOutput = config.Debug || config.PrintLogs
+	deployer.deployer.ArtifactsDir = config.ArtifactsDir
+	deployer.deployer.SkipCleanup = config.SkipCleanUp
+	deployer.deployer.Namespace = config.Namespace
+	deployer.deployer.VariableSets = config.DeploySets
+	deployer.deployer.ComponentMatrix = config.ComponentMatrix
+	deployer.deployer.CheckCleanup = config.CheckCleanup
+	deployer.deployer.SoakCycles = config.Soak
+	deployer.deployer.Resilience = config.Resilience
+	deployer.deployer.TestRemoval = config.TestRemoval
+	deployer.deployer.InitPackage = config.InitPackage
+	deployer.deployer.MirrorResources = config.MirrorResources
+	deployer.deployer.Mirror = MirrorTarget{
+		RegistryURL:      config.MirrorRegistryURL,
+		RegistryUsername: config.MirrorRegistryUsername,
+		RegistryPassword: config.MirrorRegistryPassword,
+		GitURL:           config.MirrorGitURL,
+		GitUsername:      config.MirrorGitUsername,
+		GitPassword:      config.MirrorGitPassword,
+	}
+	if config.ResilienceTimeout > 0 {
+		deployer.deployer.ResilienceTimeout = config.ResilienceTimeout
+	}
+	deployer.deployer.Thresholds = BenchmarkThresholds{
+		MaxDeployTime:    config.MaxDeployTime,
+		MaxImagePullTime: config.MaxImagePullTime,
+		MaxReadyTime:     config.MaxReadyTime,
+		MaxRegression:    config.MaxDeployRegression,
+	}
+	deployer.deployer.Benchmark = config.Benchmark || deployer.deployer.Thresholds != BenchmarkThresholds{}
+	if config.BenchmarkHistory != "" {
+		store, err := OpenHistoryStore(config.BenchmarkHistory)
+		if err != nil {
+			return nil, err
+		}
+		runs, err := store.Runs(context.Background(), time.Time{})
+		if err != nil {
+			return nil, err
+		}
+		deployer.deployer.Baseline = NewBenchmarkBaseline(runs)
+	}
+	deployer.deployer.BuildCacheDir = config.BuildCacheDir
+	deployer.deployer.KeepGoing = config.KeepGoing
+	deployer.deployer.ZarfArgs = ZarfArgs{
+		Global: config.ZarfExtraArgs,
+		Lint:   config.ZarfLintExtraArgs,
+		Build:  config.ZarfBuildExtraArgs,
+		Deploy: config.ZarfDeployExtraArgs,
+	}
+	if err := deployer.deployer.ZarfArgs.Validate(); err != nil {
+		return nil, err
+	}
 	
 	// Verify kubectl is available
 	executor := exec.NewProcessExecutor(false)
-	_, err := executor.RunProcessAndCaptureOutput("kubectl", "version", "--client")
+	_, err := executor.RunProcessAndCaptureOutput(context.Background(), "kubectl", "version", "--client")
 	if err != nil {
 		return nil, fmt.Errorf("kubectl not available: %w", err)
 	}
 	
 	// Verify zarf is available
-	_, err = executor.RunProcessAndCaptureOutput("zarf", "version")
+	_, err = executor.RunProcessAndCaptureOutput(context.Background(), "zarf", "version")
 	if err != nil {
-		return nil, fmt.Errorf("zarf CLI not available: %w", err)
+		return nil, fmt.Errorf("zarf CLI not available, install it or pass --install-zarf: %w", err)
 	}
 	
 	return deployer, nil
 }
 
-// TestPackage deploys and tests a Zarf package
-func (d *Deployer) TestPackage(packagePath string) (*DeploymentResult, error) {
-	return d.deployer.DeployPackage(packagePath)
+// TestPackage deploys and tests a Zarf package once per selected variable set
+func (d *Deployer) TestPackage(ctx context.Context, packagePath string) ([]*DeploymentResult, error) {
+	return d.deployer.DeployPackage(ctx, packagePath)
 }
 
-// DeployPackage deploys and tests a Zarf package
-func (d *PackageDeployer) DeployPackage(packagePath string) (*DeploymentResult, error) {
-	result := &DeploymentResult{
-		PackagePath:    packagePath,
-		Success:        false,
-		Errors:         []string{},
-		Warnings:       []string{},
-		ComponentTests: []ComponentTestResult{},
+// OnPackageStarted sets the function called when the deployment of a package starts,
+// see PackageDeployer.PackageStarted
+func (d *Deployer) OnPackageStarted(started func(packagePath string)) {
+	d.deployer.PackageStarted = started
+}
+
+// TestPackagesInParallel deploys and tests packages, as many at a time as configured
+// with parallel-deploys, see PackageDeployer.DeployPackagesInParallel
+func (d *Deployer) TestPackagesInParallel(ctx context.Context, packagePaths []string, dependencies map[string][]string, done func(PackageDeployment)) {
+	d.deployer.DeployPackagesInParallel(ctx, packagePaths, dependencies, d.config.ParallelDeploys, done)
+}
+
+// WaitForCluster waits for the cluster to become reachable as configured with
+// wait-for-cluster, if set
+func (d *Deployer) WaitForCluster(ctx context.Context) error {
+	if d.config.WaitForCluster <= 0 {
+		return nil
 	}
+	return d.deployer.WaitForCluster(ctx, d.config.WaitForCluster)
+}
 
+// builtPackage is a package that was built and can be deployed
+type builtPackage struct {
+	path       string
+	name       string // Prefixes streamed output
+	tarball    string
+	namespaces []string
+	// clusterScoped is set for packages installing cluster-scoped resources such as CRDs
+	clusterScoped bool
+}
+
+// DeployPackage builds a Zarf package, then deploys and tests it once per variable set
+// in its zt-values directory, or once with its default values if it has none. Building
+// and every deployment are limited by Timeout and testing by TestTimeout; expiring ctx
+// aborts any phase. Processes still running when a timeout expires are killed.
+func (d *PackageDeployer) DeployPackage(ctx context.Context, packagePath string) ([]*DeploymentResult, error) {
+	ctx, span := tracing.Start(ctx, "install.package", "package", packagePath)
+	defer span.End(nil)
+	if d.PackageStarted != nil {
+		d.PackageStarted(packagePath)
+	}
+	result := newDeploymentResult(packagePath, deployConfig{})
 	startTime := time.Now()
 
 	// First validate that this is a Zarf package
 	if !IsZarfPackage(packagePath) {
 		result.Errors = append(result.Errors, "Directory does not contain a zarf.yaml file")
-		return result, nil
+		return []*DeploymentResult{result}, nil
+	}
+
+	// Init packages can only be deployed by zarf init
+	if zarfPackage, err := LoadZarfPackage(packagePath); err == nil && zarfPackage.Metadata.IsInitPackage() {
+		result.Success = true
+		result.Warnings = append(result.Warnings, "Skipped deploying init package, init packages are deployed by zarf init with --init-package")
+		return []*DeploymentResult{result}, nil
+	}
+
+	variableSets, err := LoadVariableSets(packagePath, d.VariableSets)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return []*DeploymentResult{result}, nil
 	}
 
 	// Check if Zarf CLI is available
-	executor := exec.NewProcessExecutor(false)
-	_, err := executor.RunProcessAndCaptureOutput("zarf", "version")
+	zarfVersion, err := d.executor().RunProcessAndCaptureOutput(ctx, "zarf", "version")
 	if err != nil {
 		result.Errors = append(result.Errors, "Zarf CLI not found - please install Zarf CLI for deployment testing")
-		return result, nil
+		return []*DeploymentResult{result}, nil
 	}
 
 	// Check Kubernetes connectivity
-	err = d.checkKubernetesConnection()
+	err = d.checkKubernetesConnection(ctx)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Kubernetes connection failed: %v", err))
-		return result, nil
+		d.addPhaseError(ctx, result, "Kubernetes connection failed", 0, err)
+		return []*DeploymentResult{result}, nil
 	}
 
-	// Create a unique test namespace
-	testNamespace := d.generateTestNamespace()
-	
-	// Build the package first
-	packageTarPath, err := d.buildPackage(packagePath)
+	built := builtPackage{path: packagePath, name: filepath.Base(packagePath)}
+	var selections []ComponentSelection
+	var zarfYaml *util.ZarfYaml
+	if zarfPackage, err := LoadZarfPackage(packagePath); err == nil {
+		zarfYaml = zarfPackage.Metadata
+		built.name = zarfPackage.Name
+		built.namespaces = packageNamespaces(zarfPackage.Metadata)
+		built.clusterScoped = installsCRDs(packagePath, zarfPackage.Metadata)
+		selections, err = ComponentMatrix(zarfPackage.Metadata, d.ComponentMatrix)
+		if err != nil {
+			result.Errors = append(result.Errors, err.Error())
+			return []*DeploymentResult{result}, nil
+		}
+	}
+
+	// Build the package once for all variable sets and component selections
+	buildCtx, cancelBuild := context.WithTimeout(ctx, d.Timeout)
+	defer cancelBuild()
+	buildCtx, buildSpan := tracing.Start(buildCtx, "install.build", "package", packagePath)
+	var cleanupBuild func()
+	built.tarball, cleanupBuild, err = d.buildPackage(buildCtx, built.name, packagePath, zarfVersion, zarfYaml)
+	buildSpan.End(err)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to build package: %v", err))
-		return result, nil
+		d.addPhaseError(ctx, result, "Failed to build package", d.Timeout, err)
+		result.DeployTime = time.Since(startTime)
+		return []*DeploymentResult{result}, nil
+	}
+	defer cleanupBuild()
+
+	var results []*DeploymentResult
+	if d.MirrorResources {
+		results = append(results, d.testMirrorResources(ctx, built, zarfYaml))
+	}
+	for _, config := range deployConfigs(variableSets, selections) {
+		if ctx.Err() != nil {
+			break
+		}
+		results = append(results, d.soakBuiltPackage(ctx, built, config))
+	}
+	return results, nil
+}
+
+// soakBuiltPackage deploys, tests and removes a built package SoakCycles times and
+// combines the cycles into one result. Messages of a cycle are prefixed with its
+// number, and no further cycles are run after a cycle failed.
+func (d *PackageDeployer) soakBuiltPackage(ctx context.Context, built builtPackage, config deployConfig) *DeploymentResult {
+	if d.SoakCycles <= 1 {
+		return d.deployBuiltPackage(ctx, built, config)
 	}
 
-	// Deploy the package
-	err = d.deployPackageToCluster(packageTarPath, testNamespace)
+	result := newDeploymentResult(built.path, config)
+	result.Success = true
+	for cycle := 1; cycle <= d.SoakCycles && ctx.Err() == nil; cycle++ {
+		slog.Info("Starting soak cycle", "package", built.path, "cycle", cycle, "cycles", d.SoakCycles)
+		cycleResult := d.deployBuiltPackage(ctx, built, config)
+		prefix := fmt.Sprintf("Cycle %d/%d: ", cycle, d.SoakCycles)
+		for _, msg := range cycleResult.Errors {
+			result.Errors = append(result.Errors, prefix+msg)
+		}
+		for _, msg := range cycleResult.Warnings {
+			result.Warnings = append(result.Warnings, prefix+msg)
+		}
+		result.Cycles = cycle
+		result.DeployTime += cycleResult.DeployTime
+		result.RemoveTime += cycleResult.RemoveTime
+		result.TimedOut = result.TimedOut || cycleResult.TimedOut
+		result.ComponentTests = cycleResult.ComponentTests
+		result.Benchmark = cycleResult.Benchmark
+		result.Artifacts = append(result.Artifacts, cycleResult.Artifacts...)
+		result.Leaked = append(result.Leaked, cycleResult.Leaked...)
+		if !cycleResult.Success {
+			result.Success = false
+			break
+		}
+	}
+	if result.Cycles < d.SoakCycles && result.Success {
+		result.Success = false
+		result.Errors = append(result.Errors, fmt.Sprintf("Interrupted after %d of %d soak cycles", result.Cycles, d.SoakCycles))
+	}
+	return result
+}
+
+// deployConfig is the variable set and component selection of a single deployment,
+// either of which may be nil to use the defaults of the package
+type deployConfig struct {
+	variableSet *VariableSet
+	components  *ComponentSelection
+}
+
+// args returns the arguments for 'zarf package deploy' applying the configuration
+func (c deployConfig) args() []string {
+	var args []string
+	if c.variableSet != nil {
+		args = append(args, c.variableSet.SetArgs()...)
+	}
+	if c.components != nil && len(c.components.Components) > 0 {
+		args = append(args, "--components", strings.Join(c.components.Components, ","))
+	}
+	return args
+}
+
+// deployConfigs returns every combination of variable sets and component selections
+func deployConfigs(variableSets []VariableSet, selections []ComponentSelection) []deployConfig {
+	configs := []deployConfig{{}}
+	if len(variableSets) > 0 {
+		configs = nil
+		for i := range variableSets {
+			configs = append(configs, deployConfig{variableSet: &variableSets[i]})
+		}
+	}
+	if len(selections) == 0 {
+		return configs
+	}
+
+	var combined []deployConfig
+	for _, config := range configs {
+		for i := range selections {
+			combined = append(combined, deployConfig{variableSet: config.variableSet, components: &selections[i]})
+		}
+	}
+	return combined
+}
+
+func newDeploymentResult(packagePath string, config deployConfig) *DeploymentResult {
+	result := &DeploymentResult{
+		PackagePath:    packagePath,
+		Success:        false,
+		Errors:         []string{},
+		Warnings:       []string{},
+		ComponentTests: []ComponentTestResult{},
+	}
+	if config.variableSet != nil {
+		result.VariableSet = config.variableSet.Name
+	}
+	if config.components != nil {
+		result.Components = config.components.Name
+	}
+	return result
+}
+
+// deployBuiltPackage deploys a built package with the given configuration, tests and
+// removes it
+func (d *PackageDeployer) deployBuiltPackage(ctx context.Context, built builtPackage, config deployConfig) *DeploymentResult {
+	result := newDeploymentResult(built.path, config)
+	startTime := time.Now()
+	name := built.name
+	ctx, span := tracing.Start(ctx, "install.deployment", "package", built.path,
+		"variable-set", result.VariableSet, "components", result.Components)
+	defer func() {
+		if !result.Success {
+			span.End(fmt.Errorf("%s", strings.Join(result.Errors, "; ")))
+			return
+		}
+		span.End(nil)
+	}()
+
+	// A package with a single namespace is deployed to a test namespace instead, so
+	// repeated runs do not collide. Zarf cannot override multiple namespaces.
+	namespaces := built.namespaces
+	testNamespace := ""
+	mapping := namespaceMapping{}
+	if len(namespaces) == 1 {
+		mapping.original = namespaces[0]
+		testNamespace = d.Namespace
+		if testNamespace == "" {
+			testNamespace = d.generateTestNamespace()
+		}
+		namespaces = []string{testNamespace}
+		mapping.deployed = testNamespace
+	}
+	slog.Info("Deploying package", "package", built.path, "namespaces", namespaces,
+		"variableSet", result.VariableSet, "components", result.Components)
+
+	// Concurrent deployments must not share namespaces, and the cluster state must not
+	// change while cluster-scoped resources are installed or the cleanup is checked
+	if d.locks != nil {
+		release := d.locks.acquire(d.CheckCleanup || d.SoakCycles > 1 || built.clusterScoped, namespaces)
+		defer release()
+	}
+
+	// Record the cluster state to find resources the package leaves behind
+	var before ClusterSnapshot
+	var err error
+	if (d.CheckCleanup || d.SoakCycles > 1) && !d.SkipCleanup {
+		before, err = TakeClusterSnapshot(ctx, d.executor())
+		if err != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Skipping cleanup check: %v", err))
+		}
+	}
+
+	// Namespaces that exist before deploying are never deleted during cleanup
+	existingNamespaces, err := d.listNamespaces(ctx)
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to deploy package: %v", err))
-		return result, nil
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Namespaces created by the deployment will not be deleted: %v", err))
 	}
 
-	// Test the deployment
-	componentResults, err := d.testDeployment(packagePath, testNamespace)
+	// Deploy the package within the deployment timeout
+	deployCtx, cancelDeploy := context.WithTimeout(ctx, d.Timeout)
+	defer cancelDeploy()
+	deployCtx, deploySpan := tracing.Start(deployCtx, "install.deploy", "namespace", testNamespace)
+	deployStart := time.Now()
+	err = d.deployPackageToCluster(deployCtx, name, built, testNamespace, config)
+	deploySpan.End(err)
+	passed := false
 	if err != nil {
-		result.Errors = append(result.Errors, fmt.Sprintf("Deployment testing failed: %v", err))
+		d.addPhaseError(ctx, result, "Failed to deploy package", d.Timeout, err)
+		d.addArtifacts(ctx, result, name, built.path)
+	} else {
+		if d.Benchmark {
+			d.benchmarkDeployment(ctx, result, name, namespaces, deployStart)
+		}
+		// Test the deployment
+		testCtx, cancelTest := context.WithTimeout(ctx, d.TestTimeout)
+		defer cancelTest()
+		testCtx, testSpan := tracing.Start(testCtx, "install.test")
+		componentResults, err := d.testDeployment(testCtx, name, built.path, config.components, mapping)
+		testSpan.End(err)
+		if err != nil {
+			d.addPhaseError(ctx, result, "Deployment testing failed", d.TestTimeout, err)
+		}
+		result.ComponentTests = componentResults
+		testFailed := err != nil
+		for _, componentResult := range componentResults {
+			testFailed = testFailed || !componentResult.Success
+		}
+		if !testFailed && d.Resilience && ctx.Err() == nil {
+			testFailed = d.testDeploymentResilience(ctx, result, name, namespaces)
+		}
+		if testFailed {
+			d.addArtifacts(ctx, result, name, built.path)
+		}
+		passed = !testFailed
 	}
-	result.ComponentTests = componentResults
 
-	// Cleanup if not skipped
+	// Cleanup if not skipped, also after a partially failed deployment. The cleanup is
+	// not canceled with ctx and gets its own timeout, so packages are removed after the
+	// run timeout expired or zt was interrupted as well.
 	if !d.SkipCleanup {
-		err = d.cleanupDeployment(testNamespace)
-		if err != nil {
+		cleanupCtx, cancelCleanup := context.WithTimeout(context.WithoutCancel(ctx), d.Timeout)
+		defer cancelCleanup()
+		cleanupCtx, cleanupSpan := tracing.Start(cleanupCtx, "install.cleanup")
+		testRemoval := d.TestRemoval && passed && ctx.Err() == nil
+		removeStart := time.Now()
+		err = d.cleanupDeployment(cleanupCtx, name, built, testNamespace)
+		if testRemoval {
+			result.RemoveTime = time.Since(removeStart)
+		}
+		if err != nil && testRemoval {
+			d.addPhaseError(ctx, result, "Removal testing failed", d.Timeout, err)
+		} else if err != nil {
 			result.Warnings = append(result.Warnings, fmt.Sprintf("Cleanup failed: %v", err))
+		} else {
+			// Checked before deleting namespaces, so namespaces that package removal
+			// leaves behind are reported
+			if before != nil && ctx.Err() == nil {
+				d.checkCleanup(cleanupCtx, result, before)
+			}
+			if testRemoval && existingNamespaces != nil {
+				var created []string
+				for _, namespace := range namespaces {
+					if !existingNamespaces[namespace] {
+						created = append(created, namespace)
+					}
+				}
+				d.checkRemoval(cleanupCtx, result, name, created)
+			}
+			if existingNamespaces != nil {
+				for _, namespace := range namespaces {
+					if existingNamespaces[namespace] {
+						continue
+					}
+					if err := d.deleteNamespace(cleanupCtx, name, namespace); err != nil {
+						result.Warnings = append(result.Warnings, fmt.Sprintf("Cleanup failed: %v", err))
+					}
+				}
+				if d.SoakCycles > 1 && ctx.Err() == nil {
+					d.checkNamespaces(cleanupCtx, result, existingNamespaces)
+				}
+			}
 		}
+		cleanupSpan.End(err)
 	}
 
 	result.DeployTime = time.Since(startTime)
 	result.Success = len(result.Errors) == 0
 
-	return result, nil
+	return result
 }
 
-// checkKubernetesConnection verifies we can connect to Kubernetes
-func (d *PackageDeployer) checkKubernetesConnection() error {
+// benchmarkDeployment records the timings of a deployment that started at start and
+// adds an error for every threshold they exceed
+func (d *PackageDeployer) benchmarkDeployment(ctx context.Context, result *DeploymentResult, name string, namespaces []string, start time.Time) {
+	result.Benchmark = &Benchmark{
+		Deploy:   time.Since(start),
+		Baseline: d.Baseline[deploymentKey(result.PackagePath, d.Cluster, result.VariableSet, result.Components)],
+	}
+	if err := d.collectBenchmark(ctx, name, namespaces, start, result.Benchmark); err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Incomplete benchmark: %v", err))
+	}
+	result.Errors = append(result.Errors, d.Thresholds.Check(result.Benchmark)...)
+}
+
+// testDeploymentResilience runs the resilience phase on the namespaces of a deployment
+// and adds its results. It returns whether any workload did not recover.
+func (d *PackageDeployer) testDeploymentResilience(ctx context.Context, result *DeploymentResult, name string, namespaces []string) bool {
+	ctx, span := tracing.Start(ctx, "install.resilience")
+	componentResults := d.testResilience(ctx, name, namespaces, d.ResilienceTimeout)
+	result.ComponentTests = append(result.ComponentTests, componentResults...)
+
+	var unrecovered []string
+	for _, componentResult := range componentResults {
+		if !componentResult.Success {
+			unrecovered = append(unrecovered, strings.TrimPrefix(componentResult.ComponentName, "resilience/"))
+		}
+	}
+	if len(unrecovered) == 0 {
+		span.End(nil)
+		return false
+	}
+	err := fmt.Errorf("%s did not recover", strings.Join(unrecovered, ", "))
+	span.End(err)
+	result.Errors = append(result.Errors, fmt.Sprintf("Resilience testing failed: %v", err))
+	return true
+}
+
+// addPhaseError records a failed phase. Timeouts are reported as such, distinguishing
+// the phase timeout from the expiry of the overall run.
+func (d *PackageDeployer) addPhaseError(ctx context.Context, result *DeploymentResult, msg string, timeout time.Duration, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		result.TimedOut = true
+		if ctx.Err() != nil || timeout == 0 {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: run timeout exceeded", msg))
+		} else {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: timed out after %s", msg, timeout))
+		}
+		return
+	}
+	if errors.Is(err, context.Canceled) {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: interrupted", msg))
+		return
+	}
+	result.Errors = append(result.Errors, fmt.Sprintf("%s: %v", msg, err))
+}
+
+// addArtifacts collects diagnostics of a failed package into ArtifactsDir, unless
+// collection is disabled or ctx is already done
+func (d *PackageDeployer) addArtifacts(ctx context.Context, result *DeploymentResult, name, packagePath string) {
+	if d.ArtifactsDir == "" || ctx.Err() != nil {
+		return
+	}
+	artifacts, err := d.collectArtifacts(ctx, name, packagePath)
+	result.Artifacts = append(result.Artifacts, artifacts...)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Collecting diagnostics failed: %v", err))
+	}
+}
+
+// executor returns the executor for zarf and kubectl, pointing them at Kubeconfig if set
+func (d *PackageDeployer) executor() exec.ProcessExecutor {
 	executor := exec.NewProcessExecutor(false)
-	_, err := executor.RunProcessAndCaptureOutput("kubectl", "cluster-info")
+	if d.Kubeconfig != "" {
+		executor = executor.WithEnv("KUBECONFIG=" + d.Kubeconfig)
+	}
+	return executor
+}
+
+// run runs a command in dir and returns its combined output. With StreamOutput the
+// output is also printed while the command runs, prefixed with name. If ctx expires
+// the process is killed and the context error is returned.
+func (d *PackageDeployer) run(ctx context.Context, name string, dir string, executable string, args ...interface{}) (string, error) {
+	return d.runWithEnv(ctx, name, dir, nil, executable, args...)
+}
+
+// runWithEnv is run with additional "KEY=value" environment variables
+func (d *PackageDeployer) runWithEnv(ctx context.Context, name string, dir string, env []string, executable string, args ...interface{}) (string, error) {
+	executor := d.executor().WithEnv(env...)
+	var output string
+	var err error
+	if d.StreamOutput {
+		out := d.output
+		if out == nil {
+			out = os.Stdout
+		}
+		output, err = executor.RunProcessInDirAndStreamOutput(ctx, out, name, dir, executable, args...)
+	} else {
+		output, err = executor.RunProcessInDirAndCaptureOutput(ctx, dir, executable, args...)
+	}
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	return output, err
+}
+
+// checkKubernetesConnection verifies we can connect to Kubernetes
+func (d *PackageDeployer) checkKubernetesConnection(ctx context.Context) error {
+	_, err := d.run(ctx, "cluster", "", "kubectl", "cluster-info")
 	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
 		return fmt.Errorf("kubectl cluster-info failed: %w", err)
 	}
 	return nil
@@ -183,46 +714,94 @@ func (d *PackageDeployer) generateTestNamespace() string {
 	return fmt.Sprintf("%s-%s-%s", d.TestNamespace, timestamp, randomSuffix)
 }
 
-// buildPackage builds the Zarf package
-func (d *PackageDeployer) buildPackage(packagePath string) (string, error) {
-	executor := exec.NewProcessExecutor(false)
-	
-	// Build the package using zarf package create
-	_, err := executor.RunProcessInDirAndCaptureOutput(packagePath, "zarf", "package", "create", ".", "--confirm")
+// buildPackage builds the Zarf package into the build cache if BuildCacheDir is set,
+// reusing a package built from the same inputs, or into a temporary directory. The
+// returned function removes the temporary directory once the package was deployed.
+func (d *PackageDeployer) buildPackage(ctx context.Context, name, packagePath, zarfVersion string, zarfYaml *util.ZarfYaml) (string, func(), error) {
+	args, err := d.ZarfArgs.build(packagePath)
 	if err != nil {
-		return "", fmt.Errorf("zarf package create failed: %w", err)
+		return "", nil, err
 	}
 
-	// Find the created package file
-	files, err := os.ReadDir(packagePath)
-	if err != nil {
-		return "", fmt.Errorf("failed to read package directory: %w", err)
+	var cacheDir string
+	if d.BuildCacheDir != "" && zarfYaml != nil {
+		key, err := buildCacheKey(packagePath, zarfYaml, append([]string{zarfVersion}, args...)...)
+		if err != nil {
+			return "", nil, err
+		}
+		cacheDir = filepath.Join(d.BuildCacheDir, key)
+		if tarball := findPackageTarball(cacheDir); tarball != "" {
+			slog.Info("Using cached package build", "package", packagePath, "tarball", tarball)
+			return tarball, func() {}, nil
+		}
+		if err := os.MkdirAll(d.BuildCacheDir, 0755); err != nil {
+			return "", nil, fmt.Errorf("failed to create build cache directory: %w", err)
+		}
 	}
 
-	for _, file := range files {
-		if strings.HasPrefix(file.Name(), "zarf-package-") && strings.HasSuffix(file.Name(), ".tar.zst") {
-			return filepath.Join(packagePath, file.Name()), nil
+	// Packages are built outside of the package directory, into the build cache
+	// once the build succeeded
+	outputDir, err := os.MkdirTemp(d.BuildCacheDir, "zt-build-")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to create build directory: %w", err)
+	}
+	cleanup := func() { os.RemoveAll(outputDir) }
+	_, err = d.runWithEnv(ctx, name, packagePath, zarfConfigEnv(packagePath), "zarf", "package", "create", ".", "--confirm", "--output", outputDir, args)
+	if err != nil {
+		cleanup()
+		if ctx.Err() != nil {
+			return "", nil, err
 		}
+		return "", nil, fmt.Errorf("zarf package create failed: %w", err)
+	}
+	tarball := findPackageTarball(outputDir)
+	if tarball == "" {
+		cleanup()
+		return "", nil, fmt.Errorf("no zarf package file found after build")
+	}
+	if cacheDir == "" {
+		return tarball, cleanup, nil
 	}
 
-	return "", fmt.Errorf("no zarf package file found after build")
+	// Another deployment may have cached the same build in the meantime
+	if err := os.Rename(outputDir, cacheDir); err != nil {
+		if cached := findPackageTarball(cacheDir); cached != "" {
+			cleanup()
+			return cached, func() {}, nil
+		}
+		slog.Warn("Failed to cache package build", "package", packagePath, "error", err)
+		return tarball, cleanup, nil
+	}
+	return filepath.Join(cacheDir, filepath.Base(tarball)), func() {}, nil
 }
 
-// deployPackageToCluster deploys the package to the test cluster
-func (d *PackageDeployer) deployPackageToCluster(packageTarPath, namespace string) error {
-	executor := exec.NewProcessExecutor(false)
-	
-	// Deploy the package
-	_, err := executor.RunProcessAndCaptureOutput("zarf", "package", "deploy", packageTarPath, "--confirm")
+// deployPackageToCluster deploys the package to the test cluster. A non-empty
+// namespace overrides the namespace of the package.
+func (d *PackageDeployer) deployPackageToCluster(ctx context.Context, name string, built builtPackage, namespace string, config deployConfig) error {
+	extraArgs, err := d.ZarfArgs.deploy(built.path)
+	if err != nil {
+		return err
+	}
+	args := []interface{}{"package", "deploy", built.tarball, "--confirm"}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	args = append(args, config.args(), extraArgs)
+	_, err = d.runWithEnv(ctx, name, "", zarfConfigEnv(built.path), "zarf", args...)
 	if err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
 		return fmt.Errorf("zarf package deploy failed: %w", err)
 	}
 
 	return nil
 }
 
-// testDeployment tests that the deployment is working
-func (d *PackageDeployer) testDeployment(packagePath, namespace string) ([]ComponentTestResult, error) {
+// testDeployment tests that the deployment is working, verifies the cluster waits and
+// data injections of the deployed components and evaluates the assertions defined by
+// the package
+func (d *PackageDeployer) testDeployment(ctx context.Context, name, packagePath string, components *ComponentSelection, namespaces namespaceMapping) ([]ComponentTestResult, error) {
 	var results []ComponentTestResult
 	
 	// Load the zarf.yaml to understand what components were deployed
@@ -232,10 +811,11 @@ func (d *PackageDeployer) testDeployment(packagePath, namespace string) ([]Compo
 	}
 
 	// For now, just do basic connectivity tests
-	executor := exec.NewProcessExecutor(false)
-	
 	// Check if any pods are running (basic test)
-	_, err = executor.RunProcessAndCaptureOutput("kubectl", "get", "pods", "--all-namespaces")
+	_, err = d.run(ctx, name, "", "kubectl", "get", "pods", "--all-namespaces")
+	if ctx.Err() != nil {
+		return results, ctx.Err()
+	}
 	if err != nil {
 		results = append(results, ComponentTestResult{
 			ComponentName: "basic-connectivity",
@@ -257,16 +837,89 @@ func (d *PackageDeployer) testDeployment(packagePath, namespace string) ([]Compo
 		Message:       "Package metadata loaded successfully",
 	})
 
+	results = append(results, d.verifyActionWaits(ctx, name, packagePath, zarfYaml, components, namespaces)...)
+	if ctx.Err() != nil {
+		return results, ctx.Err()
+	}
+	results = append(results, d.verifyCRDs(ctx, name, packagePath, zarfYaml, components)...)
+	if ctx.Err() != nil {
+		return results, ctx.Err()
+	}
+	results = append(results, d.verifyDataInjections(ctx, name, packagePath, zarfYaml, components, namespaces)...)
+	if ctx.Err() != nil {
+		return results, ctx.Err()
+	}
+
+	assertions, err := LoadAssertions(packagePath)
+	if err != nil {
+		return results, err
+	}
+	if assertions != nil {
+		results = append(results, d.evaluateAssertions(ctx, name, assertions, namespaces)...)
+		if ctx.Err() != nil {
+			return results, ctx.Err()
+		}
+	}
+
 	return results, nil
 }
 
-// cleanupDeployment removes the test deployment
-func (d *PackageDeployer) cleanupDeployment(namespace string) error {
-	executor := exec.NewProcessExecutor(false)
-	
-	// Remove the package (this is more complex in real Zarf)
-	// For now, just log that we would cleanup
-	_, err := executor.RunProcessAndCaptureOutput("zarf", "package", "remove", "--confirm")
+// checkCleanup reports every resource present after the package was removed that was
+// not present before it was deployed
+func (d *PackageDeployer) checkCleanup(ctx context.Context, result *DeploymentResult, before ClusterSnapshot) {
+	after, err := TakeClusterSnapshot(ctx, d.executor())
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Skipping cleanup check: %v", err))
+		return
+	}
+	result.Leaked = before.Leaked(after)
+	for _, resource := range result.Leaked {
+		result.Errors = append(result.Errors, fmt.Sprintf("Resource left behind after package removal: %s", resource))
+	}
+}
+
+// checkNamespaces adds every namespace present after the deployment was cleaned up
+// that was not present before it was deployed to the leaked resources, unless it was
+// reported already
+func (d *PackageDeployer) checkNamespaces(ctx context.Context, result *DeploymentResult, before map[string]bool) {
+	after, err := d.listNamespaces(ctx)
+	if err != nil {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("Skipping namespace check: %v", err))
+		return
+	}
+	reported := map[string]bool{}
+	for _, resource := range result.Leaked {
+		if resource.Kind == "Namespace" {
+			reported[resource.Name] = true
+		}
+	}
+	var remaining []string
+	for namespace := range after {
+		if !before[namespace] && !reported[namespace] {
+			remaining = append(remaining, namespace)
+		}
+	}
+	sort.Strings(remaining)
+	for _, namespace := range remaining {
+		resource := ClusterResource{APIVersion: "v1", Kind: "Namespace", Name: namespace}
+		result.Leaked = append(result.Leaked, resource)
+		result.Errors = append(result.Errors, fmt.Sprintf("Resource left behind after package removal: %s", resource))
+	}
+}
+
+// cleanupDeployment removes the deployed package. The namespace must match the one
+// passed to deployPackageToCluster.
+func (d *PackageDeployer) cleanupDeployment(ctx context.Context, name string, built builtPackage, namespace string) error {
+	extraArgs, err := d.ZarfArgs.global(built.path)
+	if err != nil {
+		return err
+	}
+	args := []interface{}{"package", "remove", built.tarball, "--confirm"}
+	if namespace != "" {
+		args = append(args, "--namespace", namespace)
+	}
+	args = append(args, extraArgs)
+	_, err = d.runWithEnv(ctx, name, "", zarfConfigEnv(built.path), "zarf", args...)
 	if err != nil {
 		// Don't fail if cleanup fails, just warn
 		return fmt.Errorf("package removal failed: %w", err)
@@ -275,19 +928,53 @@ func (d *PackageDeployer) cleanupDeployment(namespace string) error {
 	return nil
 }
 
-// DeployPackages deploys and tests multiple packages
-func (d *PackageDeployer) DeployPackages(packagePaths []string) ([]*DeploymentResult, error) {
+// listNamespaces returns the namespaces present in the cluster
+func (d *PackageDeployer) listNamespaces(ctx context.Context) (map[string]bool, error) {
+	output, err := d.executor().RunProcessAndCaptureStdout(ctx, "kubectl", "get", "namespaces",
+		"--output", "jsonpath={.items[*].metadata.name}")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list namespaces: %w", err)
+	}
+	namespaces := map[string]bool{}
+	for _, namespace := range strings.Fields(output) {
+		namespaces[namespace] = true
+	}
+	return namespaces, nil
+}
+
+// deleteNamespace deletes a namespace created by the deployment
+func (d *PackageDeployer) deleteNamespace(ctx context.Context, name, namespace string) error {
+	_, err := d.run(ctx, name, "", "kubectl", "delete", "namespace", namespace, "--ignore-not-found")
+	if err != nil {
+		return fmt.Errorf("failed to delete namespace %s: %w", namespace, err)
+	}
+	return nil
+}
+
+// DeployPackages deploys and tests multiple packages. Without KeepGoing, the first
+// error deploying a package is returned instead, and packages after a failed
+// deployment are not deployed unless clean-up is skipped.
+func (d *PackageDeployer) DeployPackages(ctx context.Context, packagePaths []string) ([]*DeploymentResult, error) {
 	var results []*DeploymentResult
 	
 	for _, path := range packagePaths {
-		result, err := d.DeployPackage(path)
-		if err != nil {
+		packageResults, err := d.DeployPackage(ctx, path)
+		if err != nil && !d.KeepGoing {
 			return nil, fmt.Errorf("failed to deploy package %s: %w", path, err)
 		}
-		results = append(results, result)
+		if err != nil {
+			result := newDeploymentResult(path, deployConfig{})
+			result.Errors = append(result.Errors, fmt.Sprintf("Failed to deploy package: %v", err))
+			packageResults = append(packageResults, result)
+		}
+		results = append(results, packageResults...)
 		
 		// If deployment failed and we're not skipping cleanup, stop
-		if !result.Success && !d.SkipCleanup {
+		failed := false
+		for _, result := range packageResults {
+			failed = failed || !result.Success
+		}
+		if failed && !d.SkipCleanup && !d.KeepGoing {
 			break
 		}
 	}
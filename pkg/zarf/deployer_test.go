@@ -0,0 +1,353 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeCommands puts shell scripts with the given bodies first on PATH, named by the
+// keys of scripts
+func fakeCommands(t *testing.T, scripts map[string]string) {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("fake commands are shell scripts")
+	}
+
+	bin := t.TempDir()
+	for name, body := range scripts {
+		require.NoError(t, os.WriteFile(filepath.Join(bin, name), []byte("#!/bin/sh\n"+body+"\n"), 0755))
+	}
+	t.Setenv("PATH", bin+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// deployedTarball returns the package deployed according to the recorded calls, which
+// must have been built outside of the package directory and removed after deploying
+func deployedTarball(t *testing.T, packageDir string, calls string) string {
+	t.Helper()
+	match := regexp.MustCompile(`zarf package deploy (\S+)`).FindStringSubmatch(calls)
+	require.NotNil(t, match, "no package was deployed")
+	assert.Equal(t, "zarf-package-podinfo-amd64.tar.zst", filepath.Base(match[1]))
+	assert.NotEqual(t, packageDir, filepath.Dir(match[1]))
+	assert.NoFileExists(t, match[1])
+	return match[1]
+}
+
+func TestDeployerRunTimeout(t *testing.T) {
+	d := NewPackageDeployer()
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := d.run(ctx, "podinfo", "", "sleep", "10")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second, "process should be killed on timeout")
+}
+
+func TestAddPhaseError(t *testing.T) {
+	d := NewPackageDeployer()
+
+	result := &DeploymentResult{}
+	d.addPhaseError(context.Background(), result, "Failed to deploy package", 10*time.Minute, context.DeadlineExceeded)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, []string{"Failed to deploy package: timed out after 10m0s"}, result.Errors)
+
+	expired, cancel := context.WithDeadline(context.Background(), time.Now())
+	defer cancel()
+	result = &DeploymentResult{}
+	d.addPhaseError(expired, result, "Failed to build package", 10*time.Minute, context.DeadlineExceeded)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, []string{"Failed to build package: run timeout exceeded"}, result.Errors)
+
+	result = &DeploymentResult{}
+	d.addPhaseError(context.Background(), result, "Failed to build package", 10*time.Minute, errors.New("zarf package create failed"))
+	assert.False(t, result.TimedOut)
+	assert.Equal(t, []string{"Failed to build package: zarf package create failed"}, result.Errors)
+
+	result = &DeploymentResult{}
+	d.addPhaseError(context.Background(), result, "Failed to deploy package", 10*time.Minute, context.Canceled)
+	assert.False(t, result.TimedOut)
+	assert.Equal(t, []string{"Failed to deploy package: interrupted"}, result.Errors)
+}
+
+func TestDeployPackageCleanup(t *testing.T) {
+	// zarf and kubectl record their arguments; 'zarf package create' writes a tarball
+	// and the cluster only has the default namespace
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $*" >> "$ZT_TEST_CALLS"
+[ "$1 $2" = "package create" ] && touch "$6/zarf-package-podinfo-amd64.tar.zst"
+exit 0`,
+		"kubectl": `echo "kubectl $*" >> "$ZT_TEST_CALLS"
+case "$*" in
+"get namespaces"*) echo default ;;
+esac`,
+	})
+
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\ncomponents:\n  - name: web\n    charts:\n      - name: podinfo\n        namespace: podinfo\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	d := NewPackageDeployer()
+	d.Namespace = "zt-podinfo"
+	results, err := d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Success, results[0].Errors)
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	tarball := deployedTarball(t, packageDir, string(content))
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Contains(t, lines, "zarf package deploy "+tarball+" --confirm --namespace zt-podinfo")
+	assert.Contains(t, lines, "zarf package remove "+tarball+" --confirm --namespace zt-podinfo")
+	assert.Equal(t, "kubectl delete namespace zt-podinfo --ignore-not-found", lines[len(lines)-1])
+
+	// Nothing is removed with SkipCleanup
+	require.NoError(t, os.Remove(calls))
+	d.SkipCleanup = true
+	_, err = d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	content, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "remove")
+	assert.NotContains(t, string(content), "delete")
+}
+
+func TestDeployPackageTestRemoval(t *testing.T) {
+	// Removing the package leaves its PVC behind, and fails once ZT_TEST_REMOVE_FAILS
+	// is set
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $*" >> "$ZT_TEST_CALLS"
+[ "$1 $2" = "package create" ] && touch "$6/zarf-package-podinfo-amd64.tar.zst"
+[ "$1 $2" = "package remove" ] && [ -n "$ZT_TEST_REMOVE_FAILS" ] && exit 1
+exit 0`,
+		"kubectl": `echo "kubectl $*" >> "$ZT_TEST_CALLS"
+case "$*" in
+"get namespaces"*) echo default ;;
+"get deployments,statefulsets,daemonsets,replicasets,jobs,cronjobs,pods,services,persistentvolumeclaims --namespace zt-podinfo"*)
+  echo "v1|PersistentVolumeClaim|zt-podinfo|data" ;;
+esac`,
+	})
+
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\ncomponents:\n  - name: web\n    charts:\n      - name: podinfo\n        namespace: podinfo\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	probeInterval = time.Millisecond
+	removalGracePeriod = 50 * time.Millisecond
+	defer func() { probeInterval, removalGracePeriod = time.Second, time.Minute }()
+
+	d := NewPackageDeployer()
+	d.Namespace = "zt-podinfo"
+	d.TestRemoval = true
+	results, err := d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.False(t, results[0].Success)
+	assert.Greater(t, results[0].RemoveTime, time.Duration(0))
+	assert.Equal(t, []string{"Resource left behind after package removal: v1 PersistentVolumeClaim/data (namespace zt-podinfo)"}, results[0].Errors)
+
+	// The namespace is deleted after the check
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Equal(t, "kubectl delete namespace zt-podinfo --ignore-not-found", lines[len(lines)-1])
+
+	// A failed removal fails the deployment instead of being a warning
+	t.Setenv("ZT_TEST_REMOVE_FAILS", "1")
+	results, err = d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, []string{"Removal testing failed: package removal failed: failed running process: exit status 1"}, results[0].Errors)
+	assert.Empty(t, results[0].Warnings)
+}
+
+func TestDeployPackageSoak(t *testing.T) {
+	// From the second deployment on, removing the package leaves a PVC and a namespace
+	// behind
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $*" >> "$ZT_TEST_CALLS"
+[ "$1 $2" = "package create" ] && touch "$6/zarf-package-podinfo-amd64.tar.zst"
+if [ "$1 $2" = "package deploy" ]; then
+  [ -f "$ZT_TEST_CALLS.deployed" ] && touch "$ZT_TEST_CALLS.leak"
+  touch "$ZT_TEST_CALLS.deployed"
+fi
+exit 0`,
+		"kubectl": `case "$*" in
+"api-resources"*) echo persistentvolumeclaims ;;
+"get persistentvolumeclaims"*) [ -f "$ZT_TEST_CALLS.leak" ] && echo "v1|PersistentVolumeClaim|zt-podinfo|data" ;;
+"get namespaces"*) [ -f "$ZT_TEST_CALLS.leak" ] && echo default zt-stuck || echo default ;;
+esac
+exit 0`,
+	})
+
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\ncomponents:\n  - name: web\n    charts:\n      - name: podinfo\n        namespace: podinfo\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	d := NewPackageDeployer()
+	d.Namespace = "zt-podinfo"
+	d.SoakCycles = 3
+	results, err := d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+
+	result := results[0]
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, result.Cycles, "no cycles are run after a failed cycle")
+	assert.Equal(t, []ClusterResource{
+		{APIVersion: "v1", Kind: "PersistentVolumeClaim", Namespace: "zt-podinfo", Name: "data"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "zt-stuck"},
+	}, result.Leaked)
+	assert.Equal(t, []string{
+		"Cycle 2/3: Resource left behind after package removal: v1 PersistentVolumeClaim/data (namespace zt-podinfo)",
+		"Cycle 2/3: Resource left behind after package removal: v1 Namespace/zt-stuck",
+	}, result.Errors)
+}
+
+func TestDeployPackageInterrupted(t *testing.T) {
+	// 'zarf package deploy' hangs until it is killed
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $*" >> "$ZT_TEST_CALLS"
+[ "$1 $2" = "package create" ] && touch "$6/zarf-package-podinfo-amd64.tar.zst"
+[ "$1 $2" = "package deploy" ] && touch "$ZT_TEST_CALLS.deploying" && exec sleep 30
+exit 0`,
+		"kubectl": `echo "kubectl $*" >> "$ZT_TEST_CALLS"
+case "$*" in
+"get namespaces"*) echo default ;;
+esac`,
+	})
+
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\ncomponents:\n  - name: web\n    charts:\n      - name: podinfo\n        namespace: podinfo\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	// zt is interrupted once the package is being deployed
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		for ctx.Err() == nil {
+			if _, err := os.Stat(calls + ".deploying"); err == nil {
+				cancel()
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	d := NewPackageDeployer()
+	d.Namespace = "zt-podinfo"
+	start := time.Now()
+	results, err := d.DeployPackage(ctx, packageDir)
+	require.NoError(t, err)
+	assert.Less(t, time.Since(start), 10*time.Second, "the deployment should be killed when interrupted")
+	require.Len(t, results, 1)
+	assert.Equal(t, []string{"Failed to deploy package: interrupted"}, results[0].Errors)
+
+	// The package is removed and its namespace deleted after the interrupt
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	tarball := deployedTarball(t, packageDir, string(content))
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	assert.Contains(t, lines, "zarf package remove "+tarball+" --confirm --namespace zt-podinfo")
+	assert.Equal(t, "kubectl delete namespace zt-podinfo --ignore-not-found", lines[len(lines)-1])
+}
+
+func TestDeployPackageVariableSets(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $*" >> "$ZT_TEST_CALLS"
+[ "$1 $2" = "package create" ] && touch "$6/zarf-package-podinfo-amd64.tar.zst"
+exit 0`,
+		"kubectl": "exit 0",
+	})
+
+	packageDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(packageDir, VariableSetsDir), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, VariableSetsDir, "small.yaml"), []byte("REPLICAS: 1\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, VariableSetsDir, "large.yaml"), []byte("REPLICAS: 5\n"), 0644))
+
+	d := NewPackageDeployer()
+	results, err := d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "large", results[0].VariableSet)
+	assert.Equal(t, "small", results[1].VariableSet)
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	tarball := deployedTarball(t, packageDir, string(content))
+	assert.Equal(t, 1, strings.Count(string(content), "zarf package create"), "the package is built once")
+	assert.Contains(t, string(content), "zarf package deploy "+tarball+" --confirm --set REPLICAS=5\n")
+	assert.Contains(t, string(content), "zarf package deploy "+tarball+" --confirm --set REPLICAS=1\n")
+
+	// Only selected sets are deployed
+	d.VariableSets = []string{"small"}
+	results, err = d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.Equal(t, "small", results[0].VariableSet)
+}
+
+func TestDeployPackageComponentMatrix(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $*" >> "$ZT_TEST_CALLS"
+[ "$1 $2" = "package create" ] && touch "$6/zarf-package-podinfo-amd64.tar.zst"
+exit 0`,
+		"kubectl": "exit 0",
+	})
+
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\ncomponents:\n  - name: podinfo\n    required: true\n  - name: redis\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	d := NewPackageDeployer()
+	d.ComponentMatrix = ComponentMatrixEach
+	results, err := d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "minimal", results[0].Components)
+	assert.Equal(t, "redis", results[1].Components)
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	tarball := deployedTarball(t, packageDir, string(content))
+	assert.Contains(t, string(content), "zarf package deploy "+tarball+" --confirm --components -redis\n")
+	assert.Contains(t, string(content), "zarf package deploy "+tarball+" --confirm --components redis\n")
+}
@@ -0,0 +1,348 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// Version bumps of a PackageDiff
+const (
+	BumpMajor     = "major"
+	BumpMinor     = "minor"
+	BumpPatch     = "patch"
+	BumpNone      = "none"
+	BumpDowngrade = "downgrade"
+)
+
+// PackageDiff is the semantic difference between the zarf.yaml of a package at a Git
+// reference and its current zarf.yaml
+type PackageDiff struct {
+	Path string `yaml:"path" json:"path"`
+	Ref  string `yaml:"ref" json:"ref"`
+	// New is set if the package does not exist at Ref
+	New        bool          `yaml:"new" json:"new"`
+	Version    VersionChange `yaml:"version" json:"version"`
+	Components ListDiff      `yaml:"components" json:"components"`
+	Images     ListDiff      `yaml:"images" json:"images"`
+	Variables  ListDiff      `yaml:"variables" json:"variables"`
+	// VariableChanges are the changed fields of variables present in both revisions
+	VariableChanges []VariableChange `yaml:"variableChanges" json:"variableChanges"`
+	// Manifests are the changed objects of the rendered manifests, see
+	// DiffManifestsAt, and ManifestWarnings the manifests that could not be rendered.
+	// Both are only set if the manifests were rendered.
+	Manifests        []ManifestChange `yaml:"manifests,omitempty" json:"manifests,omitempty"`
+	ManifestWarnings []string         `yaml:"manifestWarnings,omitempty" json:"manifestWarnings,omitempty"`
+}
+
+// VersionChange is the change of the package version. Bump is empty if either
+// version is not a semantic version.
+type VersionChange struct {
+	From string `yaml:"from" json:"from"`
+	To   string `yaml:"to" json:"to"`
+	Bump string `yaml:"bump,omitempty" json:"bump,omitempty"`
+}
+
+// ListDiff lists the names added, removed and, where applicable, changed between two
+// revisions
+type ListDiff struct {
+	Added   []string `yaml:"added" json:"added"`
+	Removed []string `yaml:"removed" json:"removed"`
+	Changed []string `yaml:"changed,omitempty" json:"changed,omitempty"`
+}
+
+// VariableChange is a changed field of a variable
+type VariableChange struct {
+	Name  string `yaml:"name" json:"name"`
+	Field string `yaml:"field" json:"field"`
+	From  string `yaml:"from" json:"from"`
+	To    string `yaml:"to" json:"to"`
+}
+
+// Empty reports whether the revisions do not differ in their components, images,
+// variables or version
+func (d *PackageDiff) Empty() bool {
+	return !d.New && d.Version.From == d.Version.To &&
+		len(d.Components.Added)+len(d.Components.Removed)+len(d.Components.Changed) == 0 &&
+		len(d.Images.Added)+len(d.Images.Removed) == 0 &&
+		len(d.Variables.Added)+len(d.Variables.Removed)+len(d.VariableChanges) == 0 &&
+		len(d.Manifests) == 0
+}
+
+// DiffPackageAt compares the current zarf.yaml of the package in packagePath against
+// the one at the Git reference ref
+func DiffPackageAt(ctx context.Context, packagePath, ref string) (*PackageDiff, error) {
+	current, err := util.ReadZarfYaml(filepath.Join(packagePath, "zarf.yaml"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Git reads the repository of the package so that paths are resolved relative to
+	// it regardless of the working directory of zt
+	var previous *util.ZarfYaml
+	content, err := tool.NewGit(packagePath).ShowFile(ctx, ref, "zarf.yaml")
+	if err != nil && !errors.Is(err, tool.ErrFileNotFound) {
+		return nil, err
+	} else if err == nil {
+		previous, err = util.UnmarshalZarfYaml([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse zarf.yaml at %s: %w", ref, err)
+		}
+	}
+
+	diff := DiffPackages(previous, current)
+	diff.Path, diff.Ref = packagePath, ref
+	return diff, nil
+}
+
+// DiffPackages compares two revisions of a package. A nil previous revision is a new
+// package.
+func DiffPackages(previous, current *util.ZarfYaml) *PackageDiff {
+	diff := &PackageDiff{
+		New:             previous == nil,
+		Version:         VersionChange{To: current.Metadata.Version},
+		VariableChanges: []VariableChange{},
+	}
+	if previous == nil {
+		previous = &util.ZarfYaml{}
+	} else {
+		diff.Version.From = previous.Metadata.Version
+		diff.Version.Bump = versionBump(diff.Version.From, diff.Version.To)
+	}
+
+	previousComponents, currentComponents := map[string]util.ZarfComponent{}, map[string]util.ZarfComponent{}
+	for _, component := range previous.Components {
+		previousComponents[component.Name] = component
+	}
+	for _, component := range current.Components {
+		currentComponents[component.Name] = component
+	}
+	diff.Components = diffNames(keysOf(previousComponents), keysOf(currentComponents))
+	for _, name := range sortedKeys(keysOf(currentComponents)) {
+		if before, ok := previousComponents[name]; ok && !reflect.DeepEqual(before, currentComponents[name]) {
+			diff.Components.Changed = append(diff.Components.Changed, name)
+		}
+	}
+
+	previousImages, currentImages := map[string]bool{}, map[string]bool{}
+	for _, component := range previous.Components {
+		for _, image := range component.Images {
+			previousImages[image] = true
+		}
+	}
+	for _, component := range current.Components {
+		for _, image := range component.Images {
+			currentImages[image] = true
+		}
+	}
+	diff.Images = diffNames(previousImages, currentImages)
+
+	previousVariables, currentVariables := map[string]util.ZarfVariable{}, map[string]util.ZarfVariable{}
+	for _, variable := range previous.Variables {
+		previousVariables[variable.Name] = variable
+	}
+	for _, variable := range current.Variables {
+		currentVariables[variable.Name] = variable
+	}
+	diff.Variables = diffNames(keysOf(previousVariables), keysOf(currentVariables))
+	for _, name := range sortedKeys(keysOf(currentVariables)) {
+		before, ok := previousVariables[name]
+		if !ok {
+			continue
+		}
+		after := currentVariables[name]
+		fields := []struct{ name, from, to string }{
+			{"default", before.Default, after.Default},
+			{"description", before.Description, after.Description},
+			{"prompt", fmt.Sprint(before.Prompt), fmt.Sprint(after.Prompt)},
+		}
+		for _, field := range fields {
+			if field.from != field.to {
+				diff.VariableChanges = append(diff.VariableChanges, VariableChange{Name: name, Field: field.name, From: field.from, To: field.to})
+			}
+		}
+	}
+	return diff
+}
+
+// keysOf returns the set of keys of a map keyed by name
+func keysOf[V any](m map[string]V) map[string]bool {
+	keys := make(map[string]bool, len(m))
+	for key := range m {
+		keys[key] = true
+	}
+	return keys
+}
+
+// diffNames returns the sorted names of current missing from previous and the other
+// way around
+func diffNames(previous, current map[string]bool) ListDiff {
+	diff := ListDiff{Added: []string{}, Removed: []string{}}
+	for _, name := range sortedKeys(current) {
+		if !previous[name] {
+			diff.Added = append(diff.Added, name)
+		}
+	}
+	for _, name := range sortedKeys(previous) {
+		if !current[name] {
+			diff.Removed = append(diff.Removed, name)
+		}
+	}
+	return diff
+}
+
+// versionBump returns the kind of bump from one semantic version to another
+func versionBump(from, to string) string {
+	previous, err := semver.NewVersion(from)
+	if err != nil {
+		return ""
+	}
+	current, err := semver.NewVersion(to)
+	if err != nil {
+		return ""
+	}
+	switch {
+	case current.LessThan(previous):
+		return BumpDowngrade
+	case current.Major() != previous.Major():
+		return BumpMajor
+	case current.Minor() != previous.Minor():
+		return BumpMinor
+	case !current.Equal(previous):
+		return BumpPatch
+	default:
+		return BumpNone
+	}
+}
+
+// Text renders the diff with a line per change, prefixed with + for additions, - for
+// removals and ~ for changes
+func (d *PackageDiff) Text() string {
+	var b strings.Builder
+	switch {
+	case d.New:
+		fmt.Fprintf(&b, "%s: new package (version %s)\n", d.Path, d.Version.To)
+	case d.Empty():
+		fmt.Fprintf(&b, "%s: no changes since %s\n", d.Path, d.Ref)
+		return b.String()
+	default:
+		fmt.Fprintf(&b, "%s: changes since %s\n", d.Path, d.Ref)
+	}
+	if !d.New && d.Version.From != d.Version.To {
+		fmt.Fprintf(&b, "~ version %s -> %s%s\n", d.Version.From, d.Version.To, bumpSuffix(d.Version.Bump))
+	}
+	for _, section := range d.sections() {
+		for _, name := range section.diff.Added {
+			fmt.Fprintf(&b, "+ %s %s\n", section.kind, name)
+		}
+		for _, name := range section.diff.Removed {
+			fmt.Fprintf(&b, "- %s %s\n", section.kind, name)
+		}
+		for _, name := range section.diff.Changed {
+			fmt.Fprintf(&b, "~ %s %s\n", section.kind, name)
+		}
+	}
+	for _, change := range d.VariableChanges {
+		fmt.Fprintf(&b, "~ variable %s %s: %q -> %q\n", change.Name, change.Field, change.From, change.To)
+	}
+	for _, change := range d.Manifests {
+		fmt.Fprintf(&b, "%s manifest %s (component %s)\n%s", manifestChangePrefix[change.Change], change.Object(), change.Component, change.Diff)
+	}
+	for _, warning := range d.ManifestWarnings {
+		fmt.Fprintf(&b, "! %s\n", warning)
+	}
+	return b.String()
+}
+
+var manifestChangePrefix = map[string]string{ManifestAdded: "+", ManifestRemoved: "-", ManifestChanged: "~"}
+
+// Markdown renders the diff as a summary for pull request comments
+func (d *PackageDiff) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### zt diff: `%s`\n\n", d.Path)
+	switch {
+	case d.New:
+		fmt.Fprintf(&b, "New package, version `%s`\n\n", d.Version.To)
+	case d.Empty():
+		fmt.Fprintf(&b, "No changes since `%s`\n", d.Ref)
+		return b.String()
+	case d.Version.From != d.Version.To:
+		fmt.Fprintf(&b, "Version `%s` → `%s`%s since `%s`\n\n", d.Version.From, d.Version.To, bumpSuffix(d.Version.Bump), d.Ref)
+	default:
+		fmt.Fprintf(&b, "Version `%s` unchanged since `%s`\n\n", d.Version.To, d.Ref)
+	}
+
+	var rows []string
+	for _, section := range d.sections() {
+		for _, name := range section.diff.Added {
+			rows = append(rows, fmt.Sprintf("| ➕ added | %s | `%s` |", section.kind, name))
+		}
+		for _, name := range section.diff.Removed {
+			rows = append(rows, fmt.Sprintf("| ➖ removed | %s | `%s` |", section.kind, name))
+		}
+		for _, name := range section.diff.Changed {
+			rows = append(rows, fmt.Sprintf("| ✏️ changed | %s | `%s` |", section.kind, name))
+		}
+	}
+	for _, change := range d.VariableChanges {
+		rows = append(rows, fmt.Sprintf("| ✏️ changed | variable | `%s` %s: %s → %s |", change.Name, change.Field,
+			markdownCell(fmt.Sprintf("%q", change.From)), markdownCell(fmt.Sprintf("%q", change.To))))
+	}
+	if len(rows) > 0 {
+		b.WriteString("| Change | Kind | Name |\n|---|---|---|\n")
+		b.WriteString(strings.Join(rows, "\n"))
+		b.WriteString("\n")
+	}
+
+	if len(d.Manifests)+len(d.ManifestWarnings) > 0 {
+		b.WriteString("\n#### Rendered manifests\n")
+	}
+	for _, change := range d.Manifests {
+		fmt.Fprintf(&b, "\n<details><summary><code>%s</code> %s (component <code>%s</code>)</summary>\n\n```diff\n%s```\n\n</details>\n",
+			change.Object(), change.Change, change.Component, change.Diff)
+	}
+	if len(d.ManifestWarnings) > 0 {
+		b.WriteString("\n")
+	}
+	for _, warning := range d.ManifestWarnings {
+		fmt.Fprintf(&b, "- ⚠️ %s\n", markdownCell(warning))
+	}
+	return b.String()
+}
+
+type diffSection struct {
+	kind string
+	diff ListDiff
+}
+
+func (d *PackageDiff) sections() []diffSection {
+	return []diffSection{{"component", d.Components}, {"image", d.Images}, {"variable", d.Variables}}
+}
+
+func bumpSuffix(bump string) string {
+	if bump == "" {
+		return ""
+	}
+	return fmt.Sprintf(" (%s)", bump)
+}
@@ -0,0 +1,154 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const diffPreviousZarfYaml = `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+  version: 1.2.0
+variables:
+  - name: REPLICAS
+    default: "1"
+  - name: DOMAIN
+components:
+  - name: podinfo
+    images:
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+  - name: redis
+    images:
+      - redis:7.2
+`
+
+const diffCurrentZarfYaml = `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+  version: 1.3.0
+variables:
+  - name: REPLICAS
+    default: "2"
+    prompt: true
+  - name: LOG_LEVEL
+components:
+  - name: podinfo
+    images:
+      - ghcr.io/stefanprodan/podinfo:6.5.0
+  - name: valkey
+    images:
+      - valkey/valkey:7.2
+`
+
+func TestDiffPackages(t *testing.T) {
+	previous, err := util.UnmarshalZarfYaml([]byte(diffPreviousZarfYaml))
+	require.NoError(t, err)
+	current, err := util.UnmarshalZarfYaml([]byte(diffCurrentZarfYaml))
+	require.NoError(t, err)
+
+	diff := DiffPackages(previous, current)
+	assert.Equal(t, &PackageDiff{
+		Version:    VersionChange{From: "1.2.0", To: "1.3.0", Bump: BumpMinor},
+		Components: ListDiff{Added: []string{"valkey"}, Removed: []string{"redis"}, Changed: []string{"podinfo"}},
+		Images: ListDiff{
+			Added:   []string{"ghcr.io/stefanprodan/podinfo:6.5.0", "valkey/valkey:7.2"},
+			Removed: []string{"ghcr.io/stefanprodan/podinfo:6.4.0", "redis:7.2"},
+		},
+		Variables: ListDiff{Added: []string{"LOG_LEVEL"}, Removed: []string{"DOMAIN"}},
+		VariableChanges: []VariableChange{
+			{Name: "REPLICAS", Field: "default", From: "1", To: "2"},
+			{Name: "REPLICAS", Field: "prompt", From: "false", To: "true"},
+		},
+	}, diff)
+	assert.False(t, diff.Empty())
+
+	diff.Path, diff.Ref = "packages/podinfo", "main"
+	assert.Equal(t, `packages/podinfo: changes since main
+~ version 1.2.0 -> 1.3.0 (minor)
++ component valkey
+- component redis
+~ component podinfo
++ image ghcr.io/stefanprodan/podinfo:6.5.0
++ image valkey/valkey:7.2
+- image ghcr.io/stefanprodan/podinfo:6.4.0
+- image redis:7.2
++ variable LOG_LEVEL
+- variable DOMAIN
+~ variable REPLICAS default: "1" -> "2"
+~ variable REPLICAS prompt: "false" -> "true"
+`, diff.Text())
+	assert.Contains(t, diff.Markdown(), "Version `1.2.0` → `1.3.0` (minor) since `main`")
+	assert.Contains(t, diff.Markdown(), "| ➖ removed | component | `redis` |\n")
+
+	unchanged := DiffPackages(current, current)
+	assert.True(t, unchanged.Empty())
+	assert.Equal(t, BumpNone, unchanged.Version.Bump)
+
+	added := DiffPackages(nil, current)
+	assert.True(t, added.New)
+	assert.Equal(t, []string{"podinfo", "valkey"}, added.Components.Added)
+	assert.Empty(t, added.Version.Bump)
+}
+
+func TestVersionBump(t *testing.T) {
+	assert.Equal(t, BumpMajor, versionBump("1.2.3", "2.0.0"))
+	assert.Equal(t, BumpMinor, versionBump("1.2.3", "1.3.0"))
+	assert.Equal(t, BumpPatch, versionBump("1.2.3", "1.2.4"))
+	assert.Equal(t, BumpPatch, versionBump("1.2.3-rc.1", "1.2.3"))
+	assert.Equal(t, BumpDowngrade, versionBump("1.2.3", "1.2.0"))
+	assert.Equal(t, "", versionBump("latest", "1.2.0"))
+}
+
+func TestDiffPackageAt(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	packageDir := filepath.Join("packages", "podinfo")
+	require.NoError(t, os.MkdirAll(packageDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(diffPreviousZarfYaml), 0644))
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1.2.0")
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(diffCurrentZarfYaml), 0644))
+
+	diff, err := DiffPackageAt(context.Background(), packageDir, "v1.2.0")
+	require.NoError(t, err)
+	assert.Equal(t, packageDir, diff.Path)
+	assert.Equal(t, "v1.2.0", diff.Ref)
+	assert.Equal(t, []string{"valkey"}, diff.Components.Added)
+
+	// Packages that did not exist at the reference are new
+	newDir := filepath.Join("packages", "new")
+	require.NoError(t, os.MkdirAll(newDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(newDir, "zarf.yaml"), []byte(diffCurrentZarfYaml), 0644))
+	diff, err = DiffPackageAt(context.Background(), newDir, "v1.2.0")
+	require.NoError(t, err)
+	assert.True(t, diff.New)
+}
@@ -17,7 +17,9 @@ package zarf
 import (
 	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/cpepper96/zarf-testing/pkg/util"
@@ -28,6 +30,11 @@ type ZarfPackage struct {
 	Path     string
 	Name     string
 	Metadata *util.ZarfYaml
+	// Imports are the directories of the local packages imported by the components,
+	// directly or through other imports
+	Imports []string
+	// ZarfConfig is the path of the zarf config file of the package, if it has one
+	ZarfConfig string
 }
 
 // FindZarfPackages discovers Zarf packages in the specified directories
@@ -103,34 +110,117 @@ func LoadZarfPackage(dir string) (*ZarfPackage, error) {
 		packageName = filepath.Base(dir)
 	}
 	
+	// Import cycles are reported by lint, the imports resolved until then are kept
+	imports, _ := resolveImports(dir)
+
 	return &ZarfPackage{
-		Path:     dir,
-		Name:     packageName,
-		Metadata: metadata,
+		Path:       dir,
+		Name:       packageName,
+		Metadata:   metadata,
+		Imports:    imports,
+		ZarfConfig: FindZarfConfig(dir),
 	}, nil
 }
 
-// FilterExcludedPackages removes excluded packages from the list
-func FilterExcludedPackages(packages []string, excluded []string) []string {
+// FilterExcludedPackages removes packages matching any of the excluded patterns.
+// A pattern prefixed with "re:" is a regular expression matched against the
+// slash-separated package path. Any other pattern is a glob where "*" matches
+// within a path segment and "**" matches any number of segments. Globs are
+// matched against the trailing segments of the path, so a plain package name
+// or "team-a/*" matches regardless of the zarf directory the package is in.
+func FilterExcludedPackages(packages []string, excluded []string) ([]string, error) {
 	if len(excluded) == 0 {
-		return packages
+		return packages, nil
 	}
-	
-	var filtered []string
-	excludeSet := make(map[string]bool)
-	
-	// Create set of excluded package names/patterns
-	for _, exc := range excluded {
-		excludeSet[exc] = true
+
+	matchers := make([]func(string) bool, 0, len(excluded))
+	for _, pattern := range excluded {
+		matcher, err := compileExcludePattern(pattern)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, matcher)
 	}
-	
+
+	var filtered []string
 	for _, pkg := range packages {
-		packageName := filepath.Base(pkg)
-		if !excludeSet[packageName] && !excludeSet[pkg] {
+		packagePath := filepath.ToSlash(filepath.Clean(pkg))
+		excluded := false
+		for _, matches := range matchers {
+			if matches(packagePath) {
+				excluded = true
+				break
+			}
+		}
+		if !excluded {
 			filtered = append(filtered, pkg)
 		}
 	}
-	
+	return filtered, nil
+}
+
+// compileExcludePattern returns a function reporting whether a slash-separated
+// package path matches the pattern
+func compileExcludePattern(pattern string) (func(string) bool, error) {
+	if expr, ok := strings.CutPrefix(pattern, "re:"); ok {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid excluded package pattern %q: %w", pattern, err)
+		}
+		return re.MatchString, nil
+	}
+
+	segments := strings.Split(strings.Trim(filepath.ToSlash(pattern), "/"), "/")
+	for _, segment := range segments {
+		if _, err := path.Match(segment, ""); err != nil {
+			return nil, fmt.Errorf("invalid excluded package pattern %q: %w", pattern, err)
+		}
+	}
+	return func(packagePath string) bool {
+		parts := strings.Split(packagePath, "/")
+		for i := range parts {
+			if matchSegments(segments, parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// matchSegments matches path segments against glob segments, where a "**"
+// segment matches zero or more path segments
+func matchSegments(pattern, parts []string) bool {
+	if len(pattern) == 0 {
+		return len(parts) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(parts); i++ {
+			if matchSegments(pattern[1:], parts[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(parts) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], parts[0]); !ok {
+		return false
+	}
+	return matchSegments(pattern[1:], parts[1:])
+}
+
+// FilterDeprecatedPackages removes packages whose metadata is marked as deprecated.
+// Packages whose zarf.yaml cannot be read are kept so that linting reports the problem.
+func FilterDeprecatedPackages(packages []string) []string {
+	var filtered []string
+	for _, pkg := range packages {
+		zarfYaml, err := util.ReadZarfYaml(filepath.Join(pkg, "zarf.yaml"))
+		if err == nil && zarfYaml.Metadata.Deprecated {
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
 	return filtered
 }
 
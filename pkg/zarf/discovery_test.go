@@ -0,0 +1,82 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFilterExcludedPackages(t *testing.T) {
+	packages := []string{
+		"packages/podinfo",
+		"packages/team-a/api",
+		"packages/team-a/web",
+		"packages/team-b/examples",
+		"examples",
+		"packages/redis-dev",
+	}
+
+	tests := []struct {
+		name     string
+		excluded []string
+		expected []string
+	}{
+		{
+			name:     "no patterns",
+			expected: packages,
+		},
+		{
+			name:     "name and path",
+			excluded: []string{"podinfo", "packages/team-a/web"},
+			expected: []string{"packages/team-a/api", "packages/team-b/examples", "examples", "packages/redis-dev"},
+		},
+		{
+			name:     "glob",
+			excluded: []string{"team-a/*"},
+			expected: []string{"packages/podinfo", "packages/team-b/examples", "examples", "packages/redis-dev"},
+		},
+		{
+			name:     "double star",
+			excluded: []string{"**/examples"},
+			expected: []string{"packages/podinfo", "packages/team-a/api", "packages/team-a/web", "packages/redis-dev"},
+		},
+		{
+			name:     "anchored double star",
+			excluded: []string{"packages/**/web"},
+			expected: []string{"packages/podinfo", "packages/team-a/api", "packages/team-b/examples", "examples", "packages/redis-dev"},
+		},
+		{
+			name:     "regex",
+			excluded: []string{"re:-dev$", "re:^packages/team-"},
+			expected: []string{"packages/podinfo", "examples"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filtered, err := FilterExcludedPackages(packages, tt.excluded)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, filtered)
+		})
+	}
+
+	_, err := FilterExcludedPackages(packages, []string{"re:("})
+	assert.ErrorContains(t, err, `invalid excluded package pattern "re:("`)
+	_, err = FilterExcludedPackages(packages, []string{"team-[a"})
+	assert.ErrorContains(t, err, `invalid excluded package pattern "team-[a"`)
+}
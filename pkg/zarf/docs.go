@@ -0,0 +1,78 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// readmeFile returns the path of the README.md of the package directory, matched case
+// insensitively, or "" when there is none
+func readmeFile(dir string) string {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && strings.EqualFold(entry.Name(), "README.md") {
+			return filepath.Join(dir, entry.Name())
+		}
+	}
+	return ""
+}
+
+// validateDocs reports packages that are not documented well enough to be published
+// in a catalog: packages without a README.md, components and variables without a
+// description, and prompted variables without a default or a mention in the README.
+// The docs rules are opt-in with ValidateDocs.
+func (v *PackageValidator) validateDocs(pkg *PackageContext, result *ValidationResult) error {
+	if !v.ValidateDocs {
+		return nil
+	}
+
+	// A package definition validated with DefinitionOnly comes without its README.md
+	var readme string
+	if path := readmeFile(pkg.Path); path == "" && !v.DefinitionOnly {
+		result.AddWarning("docs-readme", "Package has no README.md describing its purpose, configuration and usage")
+	} else if content, err := os.ReadFile(path); err == nil {
+		readme = string(content)
+	}
+
+	for _, component := range pkg.ZarfYaml.Components {
+		if strings.TrimSpace(component.Description) == "" {
+			result.AddWarning("docs-component-description", fmt.Sprintf("Component '%s' has no description", component.Name))
+		}
+	}
+	for _, variable := range pkg.ZarfYaml.Variables {
+		if strings.TrimSpace(variable.Description) == "" {
+			result.AddWarning("docs-variable-description", fmt.Sprintf("Variable '%s' has no description", variable.Name))
+		}
+		if !variable.Prompt {
+			continue
+		}
+		if variable.Default == "" {
+			result.AddWarning("docs-variable-default", fmt.Sprintf(
+				"Variable '%s' is prompted for without a default, set one so unattended deployments with --confirm work", variable.Name))
+		}
+		if readme != "" && !strings.Contains(readme, variable.Name) {
+			result.AddWarning("docs-variable-default", fmt.Sprintf(
+				"Variable '%s' is prompted for but README.md does not document it or its default", variable.Name))
+		}
+	}
+	return nil
+}
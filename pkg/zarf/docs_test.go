@@ -0,0 +1,67 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateDocs(t *testing.T) {
+	packageDir := t.TempDir()
+	pkg := &PackageContext{Path: packageDir, ZarfYaml: &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{Name: "web", Description: "Web frontend"},
+			{Name: "cache"},
+		},
+		Variables: []util.ZarfVariable{
+			{Name: "DOMAIN", Description: "Domain of the web frontend", Prompt: true, Default: "example.com"},
+			{Name: "REPLICAS", Prompt: true},
+			{Name: "LOG_LEVEL", Default: "info"},
+		},
+	}}
+
+	// The docs rules are opt-in
+	v := NewPackageValidator()
+	result := &ValidationResult{}
+	require.NoError(t, v.validateDocs(pkg, result))
+	assert.Empty(t, result.Findings)
+
+	v.ValidateDocs = true
+	require.NoError(t, v.validateDocs(pkg, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "docs-readme", Severity: SeverityWarning, Message: "Package has no README.md describing its purpose, configuration and usage"},
+		{RuleID: "docs-component-description", Severity: SeverityWarning, Message: "Component 'cache' has no description"},
+		{RuleID: "docs-variable-description", Severity: SeverityWarning, Message: "Variable 'REPLICAS' has no description"},
+		{RuleID: "docs-variable-default", Severity: SeverityWarning, Message: "Variable 'REPLICAS' is prompted for without a default, set one so unattended deployments with --confirm work"},
+		{RuleID: "docs-variable-description", Severity: SeverityWarning, Message: "Variable 'LOG_LEVEL' has no description"},
+	}, result.Findings)
+
+	// Prompted variables are documented in the README
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "Readme.md"), []byte("# Web\n\n`DOMAIN` defaults to example.com\n"), 0644))
+	result = &ValidationResult{}
+	require.NoError(t, v.validateDocs(pkg, result))
+	var rules []string
+	for _, finding := range result.Findings {
+		rules = append(rules, finding.RuleID)
+	}
+	assert.Equal(t, []string{"docs-component-description", "docs-variable-description", "docs-variable-default", "docs-variable-default", "docs-variable-description"}, rules)
+	assert.Equal(t, "Variable 'REPLICAS' is prompted for but README.md does not document it or its default", result.Findings[3].Message)
+}
@@ -0,0 +1,208 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// Duplicates lists the images and charts used at more than one version across a set
+// of packages, sorted by name
+type Duplicates struct {
+	Images []Duplicate `yaml:"images" json:"images"`
+	Charts []Duplicate `yaml:"charts" json:"charts"`
+}
+
+// Duplicate is an image repository or a chart and the versions it is used at
+type Duplicate struct {
+	Name     string             `yaml:"name" json:"name"`
+	Versions []DuplicateVersion `yaml:"versions" json:"versions"`
+}
+
+// DuplicateVersion is a version of an image or chart and the components using it
+type DuplicateVersion struct {
+	Version string      `yaml:"version" json:"version"`
+	Users   []ImageUser `yaml:"users" json:"users"`
+}
+
+// FindDuplicates reads the packages and returns the images pinned at different tags
+// or digests, and the charts used at different versions. Images are compared by their
+// fully qualified repository, so 'nginx' and 'docker.io/library/nginx' are the same
+// image. Local charts are compared by the name and version of their Chart.yaml.
+func FindDuplicates(packageDirs []string) (*Duplicates, error) {
+	images := versionsBuilder{}
+	charts := versionsBuilder{}
+	for _, dir := range packageDirs {
+		zarfYaml, err := util.ReadZarfYaml(filepath.Join(dir, "zarf.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package %s: %w", dir, err)
+		}
+		for _, component := range zarfYaml.Components {
+			user := ImageUser{Package: dir, Component: component.Name}
+			for _, image := range component.Images {
+				repository, version := imageVersion(image)
+				images.add(repository, version, user)
+			}
+			for _, chart := range component.Charts {
+				if name, version := chartVersion(dir, chart); name != "" && version != "" {
+					charts.add(name, version, user)
+				}
+			}
+		}
+	}
+	return &Duplicates{Images: images.duplicates(), Charts: charts.duplicates()}, nil
+}
+
+// imageVersion splits image into its fully qualified repository and its tag, digest or
+// both. Images without either are 'latest'.
+func imageVersion(image string) (string, string) {
+	image = qualifyImage(image)
+	repository := imageRepository(image)
+	version := strings.TrimLeft(strings.TrimPrefix(image, repository), ":@")
+	if version == "" {
+		version = "latest"
+	}
+	return repository, version
+}
+
+// chartVersion returns the name and version of chart. Local charts are read from their
+// Chart.yaml, OCI charts are named by the last element of their URL and charts of Git
+// repositories by their path in the repository.
+func chartVersion(packageDir string, chart util.ZarfChart) (string, string) {
+	switch {
+	case chart.LocalPath != "":
+		chartYaml, err := util.ReadChartYaml(filepath.Join(packageDir, chart.LocalPath))
+		if err != nil {
+			return "", ""
+		}
+		return chartYaml.Name, chartYaml.Version
+	case chart.RepoName != "":
+		return chart.RepoName, chart.Version
+	case strings.HasPrefix(chart.Url, "oci://"):
+		return path.Base(imageRepository(strings.TrimPrefix(chart.Url, "oci://"))), chart.Version
+	case chart.GitPath != "":
+		url, ref, _ := strings.Cut(chart.Url, "@")
+		if chart.Version != "" {
+			ref = chart.Version
+		}
+		return strings.TrimSuffix(path.Base(url), ".git") + "/" + path.Clean(chart.GitPath), ref
+	default:
+		return chart.Name, chart.Version
+	}
+}
+
+// versionsBuilder collects the users of each version of each image or chart
+type versionsBuilder map[string]map[string][]ImageUser
+
+func (b versionsBuilder) add(name, version string, user ImageUser) {
+	if b[name] == nil {
+		b[name] = map[string][]ImageUser{}
+	}
+	for _, existing := range b[name][version] {
+		if existing == user {
+			return
+		}
+	}
+	b[name][version] = append(b[name][version], user)
+}
+
+// duplicates returns the names used at more than one version
+func (b versionsBuilder) duplicates() []Duplicate {
+	duplicates := []Duplicate{}
+	for _, name := range sortedKeys(keysOf(b)) {
+		if len(b[name]) < 2 {
+			continue
+		}
+		duplicate := Duplicate{Name: name}
+		for _, version := range sortedKeys(keysOf(b[name])) {
+			users := b[name][version]
+			sort.Slice(users, func(i, j int) bool {
+				if users[i].Package != users[j].Package {
+					return users[i].Package < users[j].Package
+				}
+				return users[i].Component < users[j].Component
+			})
+			duplicate.Versions = append(duplicate.Versions, DuplicateVersion{Version: version, Users: users})
+		}
+		duplicates = append(duplicates, duplicate)
+	}
+	return duplicates
+}
+
+// otherVersions returns the versions of the duplicate used by packages other than
+// packagePath, formatted as 'version (package, ...)'
+func (d Duplicate) otherVersions(packagePath, version string) []string {
+	var others []string
+	for _, other := range d.Versions {
+		if other.Version == version {
+			continue
+		}
+		var packages []string
+		for _, user := range other.Users {
+			if absPath("", user.Package) != absPath("", packagePath) && !contains(packages, user.Package) {
+				packages = append(packages, user.Package)
+			}
+		}
+		if len(packages) > 0 {
+			others = append(others, fmt.Sprintf("%s (%s)", other.Version, strings.Join(packages, ", ")))
+		}
+	}
+	return others
+}
+
+// validateDuplicateVersions warns about images and charts of the package that other
+// packages use at a different version
+func (v *PackageValidator) validateDuplicateVersions(_ context.Context, pkg *PackageContext, result *ValidationResult) error {
+	if v.Duplicates == nil {
+		return nil
+	}
+
+	images := map[string]Duplicate{}
+	for _, duplicate := range v.Duplicates.Images {
+		images[duplicate.Name] = duplicate
+	}
+	charts := map[string]Duplicate{}
+	for _, duplicate := range v.Duplicates.Charts {
+		charts[duplicate.Name] = duplicate
+	}
+
+	for _, component := range pkg.ZarfYaml.Components {
+		for _, image := range component.Images {
+			repository, version := imageVersion(image)
+			if others := images[repository].otherVersions(pkg.Path, version); len(others) > 0 {
+				result.AddWarning("duplicate-versions", fmt.Sprintf("Component '%s' uses image %s, which other packages use at %s",
+					component.Name, image, strings.Join(others, ", ")))
+			}
+		}
+		for _, chart := range component.Charts {
+			name, version := chartVersion(pkg.Path, chart)
+			if name == "" || version == "" {
+				continue
+			}
+			if others := charts[name].otherVersions(pkg.Path, version); len(others) > 0 {
+				result.AddWarning("duplicate-versions", fmt.Sprintf("Component '%s' uses chart %s %s, which other packages use at %s",
+					component.Name, name, version, strings.Join(others, ", ")))
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindDuplicates(t *testing.T) {
+	root := t.TempDir()
+	api := filepath.Join(root, "api")
+	web := filepath.Join(root, "web")
+	writePackage(t, api, `  - name: api
+    images:
+      - nginx:1.25
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+    charts:
+      - name: podinfo
+        url: oci://ghcr.io/stefanprodan/charts/podinfo
+        version: 6.4.0
+      - name: common
+        localPath: chart
+`)
+	require.NoError(t, os.MkdirAll(filepath.Join(api, "chart"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(api, "chart", "Chart.yaml"), []byte("name: common\nversion: 1.0.0\n"), 0644))
+	writePackage(t, web, `  - name: web
+    images:
+      - docker.io/library/nginx:1.26
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+      - redis@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef
+    charts:
+      - name: podinfo
+        url: oci://ghcr.io/stefanprodan/charts/podinfo
+        version: 6.5.0
+  - name: cache
+    images:
+      - redis:7.2
+`)
+
+	duplicates, err := FindDuplicates([]string{api, web})
+	require.NoError(t, err)
+	assert.Equal(t, []Duplicate{
+		{Name: "docker.io/library/nginx", Versions: []DuplicateVersion{
+			{Version: "1.25", Users: []ImageUser{{Package: api, Component: "api"}}},
+			{Version: "1.26", Users: []ImageUser{{Package: web, Component: "web"}}},
+		}},
+		{Name: "docker.io/library/redis", Versions: []DuplicateVersion{
+			{Version: "7.2", Users: []ImageUser{{Package: web, Component: "cache"}}},
+			{Version: "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef", Users: []ImageUser{{Package: web, Component: "web"}}},
+		}},
+	}, duplicates.Images)
+	assert.Equal(t, []Duplicate{
+		{Name: "podinfo", Versions: []DuplicateVersion{
+			{Version: "6.4.0", Users: []ImageUser{{Package: api, Component: "api"}}},
+			{Version: "6.5.0", Users: []ImageUser{{Package: web, Component: "web"}}},
+		}},
+	}, duplicates.Charts)
+
+	// Versions used by other packages are warned about, versions used only within the
+	// package are not
+	v := NewPackageValidator()
+	v.Duplicates = duplicates
+	pkg, err := LoadPackageContext(web)
+	require.NoError(t, err)
+	result := &ValidationResult{}
+	require.NoError(t, v.validateDuplicateVersions(context.Background(), pkg, result))
+	assert.Equal(t, []string{
+		"Component 'web' uses image docker.io/library/nginx:1.26, which other packages use at 1.25 (" + api + ")",
+		"Component 'web' uses chart podinfo 6.5.0, which other packages use at 6.4.0 (" + api + ")",
+	}, result.Warnings)
+}
+
+func TestChartVersion(t *testing.T) {
+	name, version := chartVersion("", util.ZarfChart{Name: "app", Url: "https://github.com/org/charts.git@v1.2.0", GitPath: "charts/app"})
+	assert.Equal(t, "charts/charts/app", name)
+	assert.Equal(t, "v1.2.0", version)
+
+	name, version = chartVersion("", util.ZarfChart{Name: "release", RepoName: "podinfo", Url: "https://stefanprodan.github.io/podinfo", Version: "6.4.0"})
+	assert.Equal(t, "podinfo", name)
+	assert.Equal(t, "6.4.0", version)
+
+	name, _ = chartVersion(t.TempDir(), util.ZarfChart{Name: "missing", LocalPath: "chart"})
+	assert.Empty(t, name)
+}
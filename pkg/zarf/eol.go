@@ -0,0 +1,126 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Distro is the operating system of an image, as found in its SBOM
+type Distro struct {
+	ID      string `yaml:"id" json:"id"`
+	Version string `yaml:"version" json:"version"`
+}
+
+// String renders the distro as 'id version'
+func (d Distro) String() string {
+	return strings.TrimSpace(d.ID + " " + d.Version)
+}
+
+// distroEOL are the end of life dates of distro releases commonly used as base
+// images, by distro ID and release. Releases match versions equal to them or starting
+// with them followed by a dot, e.g. '3.18' matches Alpine '3.18.4'. For releases with
+// extended support, the end of standard support is used.
+var distroEOL = map[string]map[string]string{
+	"alpine": {
+		"3.14": "2023-05-01", "3.15": "2023-11-01", "3.16": "2024-05-23", "3.17": "2024-11-22",
+		"3.18": "2025-05-09", "3.19": "2025-11-01", "3.20": "2026-04-01", "3.21": "2026-11-01",
+		"3.22": "2027-05-01",
+	},
+	"debian": {
+		"9": "2022-06-30", "10": "2024-06-30", "11": "2026-08-31", "12": "2028-06-30", "13": "2030-06-30",
+	},
+	"ubuntu": {
+		"16.04": "2021-04-30", "18.04": "2023-05-31", "20.04": "2025-05-31", "22.04": "2027-06-01",
+		"24.04": "2029-05-31",
+	},
+	"centos": {
+		"7": "2024-06-30", "8": "2021-12-31",
+	},
+	"rhel": {
+		"7": "2024-06-30", "8": "2029-05-31", "9": "2032-05-31",
+	},
+	"amzn": {
+		"2": "2026-06-30", "2023": "2029-06-30",
+	},
+	"rocky": {
+		"8": "2029-05-31", "9": "2032-05-31",
+	},
+}
+
+// EOLSoon is how long before its end of life a distro is reported as reaching it soon
+const EOLSoon = 90 * 24 * time.Hour
+
+// distroEOLDate returns the end of life date of the distro release, and whether it is
+// known
+func distroEOLDate(distro Distro) (time.Time, bool) {
+	releases := distroEOL[strings.ToLower(distro.ID)]
+	// The longest matching release wins, e.g. '2023' over '2' for Amazon Linux
+	var date string
+	longest := 0
+	for release, eol := range releases {
+		if (distro.Version == release || strings.HasPrefix(distro.Version, release+".")) && len(release) > longest {
+			date, longest = eol, len(release)
+		}
+	}
+	if date == "" {
+		return time.Time{}, false
+	}
+	parsed, err := time.Parse("2006-01-02", date)
+	return parsed, err == nil
+}
+
+// parseSBOMDistro returns the operating system recorded in a syft JSON or CycloneDX
+// JSON SBOM. SPDX SBOMs do not record it.
+func parseSBOMDistro(content []byte) (Distro, error) {
+	var sbom struct {
+		// syft
+		Distro struct {
+			ID        string `json:"id"`
+			VersionID string `json:"versionID"`
+		} `json:"distro"`
+		// CycloneDX
+		Components []struct {
+			Type    string `json:"type"`
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(content, &sbom); err != nil {
+		return Distro{}, err
+	}
+	if sbom.Distro.ID != "" {
+		return Distro{ID: sbom.Distro.ID, Version: sbom.Distro.VersionID}, nil
+	}
+	for _, component := range sbom.Components {
+		if component.Type == "operating-system" {
+			return Distro{ID: component.Name, Version: component.Version}, nil
+		}
+	}
+	return Distro{}, nil
+}
+
+// sbomFileNameSeparators are the characters of images replaced in SBOM file names
+var sbomFileNameSeparators = regexp.MustCompile(`[^A-Za-z0-9.-]`)
+
+// sbomFileName is the name of the SBOM of image extracted with 'zarf package inspect
+// sbom', less the extension: characters other than letters, digits, dots and hyphens
+// are replaced by underscores
+func sbomFileName(image string) string {
+	return sbomFileNameSeparators.ReplaceAllString(image, "_")
+}
@@ -0,0 +1,244 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/util/yamledit"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// Fixer applies safe automatic fixes to the zarf.yaml of packages: it pins images to
+// digests, normalizes component names, adds description stubs, sorts image lists and
+// removes 'default' from required components. Only the edited values change, so
+// comments and formatting are kept.
+type Fixer struct {
+	// ComponentNaming is the pattern component names are normalized to match. Names
+	// that cannot be normalized to match it are left alone.
+	ComponentNaming *regexp.Regexp
+
+	// ResolveDigest returns the digest of an image, 'crane digest' by default
+	ResolveDigest func(ctx context.Context, image string) (string, error)
+}
+
+// Fix is a fix applied to a package
+type Fix struct {
+	RuleID  string `json:"ruleId" yaml:"ruleId"`
+	Message string `json:"message" yaml:"message"`
+}
+
+// FixResult lists the fixes applied to a package and the unified diff of its zarf.yaml
+type FixResult struct {
+	Path  string `json:"path" yaml:"path"`
+	Fixes []Fix  `json:"fixes,omitempty" yaml:"fixes,omitempty"`
+	// Skipped lists fixes that could not be applied, e.g. images whose digest could
+	// not be resolved
+	Skipped []Fix  `json:"skipped,omitempty" yaml:"skipped,omitempty"`
+	Diff    string `json:"diff,omitempty" yaml:"diff,omitempty"`
+}
+
+// NewFixer creates a fixer normalizing component names to the default naming policy
+// and resolving digests with crane
+func NewFixer() *Fixer {
+	return &Fixer{
+		ComponentNaming: DefaultNamingPolicies()[NamingComponent].Pattern,
+		ResolveDigest:   craneDigest,
+	}
+}
+
+// componentNameSeparators are runs of characters replaced by a hyphen when normalizing
+// component names
+var componentNameSeparators = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// Fix applies the fixes to the zarf.yaml of the package at packagePath and writes it
+// back if anything changed
+func (f *Fixer) Fix(ctx context.Context, packagePath string) (*FixResult, error) {
+	zarfYamlPath := filepath.Join(packagePath, "zarf.yaml")
+	content, err := os.ReadFile(zarfYamlPath)
+	if err != nil {
+		return nil, err
+	}
+	zarfYaml, _, err := util.ParseZarfYaml(content, false)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", zarfYamlPath, err)
+	}
+	d, err := yamledit.Parse(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", zarfYamlPath, err)
+	}
+
+	result := &FixResult{Path: packagePath}
+	fixed := func(ruleID, format string, args ...interface{}) {
+		result.Fixes = append(result.Fixes, Fix{RuleID: ruleID, Message: fmt.Sprintf(format, args...)})
+	}
+	skipped := func(ruleID, format string, args ...interface{}) {
+		result.Skipped = append(result.Skipped, Fix{RuleID: ruleID, Message: fmt.Sprintf(format, args...)})
+	}
+
+	if zarfYaml.Metadata.Description == "" && zarfYaml.Metadata.Name != "" {
+		if err := d.Set(fmt.Sprintf("TODO: describe the %s package", zarfYaml.Metadata.Name), "metadata", "description"); err != nil {
+			return nil, err
+		}
+		fixed("package-description", "Added a description stub to the package")
+	}
+
+	renames := f.componentRenames(zarfYaml.Components)
+	for i, component := range zarfYaml.Components {
+		name := component.Name
+		if renamed, ok := renames[name]; ok {
+			if err := d.Set(renamed, "components", i, "name"); err != nil {
+				return nil, err
+			}
+			fixed("component-naming", "Renamed component '%s' to '%s'", name, renamed)
+			name = renamed
+		}
+		for j, dependency := range component.DepsWith {
+			if renamed, ok := renames[dependency]; ok {
+				if err := d.Set(renamed, "components", i, "depsWith", j); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if component.Description == "" {
+			if err := d.Set(fmt.Sprintf("TODO: describe the %s component", name), "components", i, "description"); err != nil {
+				return nil, err
+			}
+			fixed("component-description", "Added a description stub to component '%s'", name)
+		}
+
+		if component.Required && component.Default {
+			if err := d.Delete("components", i, "default"); err != nil {
+				return nil, err
+			}
+			fixed("redundant-default", "Removed 'default' from required component '%s'", name)
+		}
+
+		if err := f.fixImages(ctx, d, i, name, component.Images, fixed, skipped); err != nil {
+			return nil, err
+		}
+	}
+
+	updated := d.Bytes()
+	if string(updated) == string(content) {
+		return result, nil
+	}
+	result.Diff, err = difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(content)),
+		B:        difflib.SplitLines(string(updated)),
+		FromFile: "a/" + filepath.ToSlash(zarfYamlPath),
+		ToFile:   "b/" + filepath.ToSlash(zarfYamlPath),
+		Context:  3,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, d.WriteFile(zarfYamlPath)
+}
+
+// componentRenames maps the names of components not matching the naming pattern to
+// their normalized name: lowercase, with runs of other characters replaced by a
+// hyphen. Imported components keep their name, which may select the imported
+// component, as do components whose normalized name is taken or still does not match.
+func (f *Fixer) componentRenames(components []util.ZarfComponent) map[string]string {
+	taken := map[string]bool{}
+	for _, component := range components {
+		taken[component.Name] = true
+	}
+	renames := map[string]string{}
+	for _, component := range components {
+		if f.ComponentNaming == nil || f.ComponentNaming.MatchString(component.Name) || isImported(component) {
+			continue
+		}
+		normalized := strings.Trim(componentNameSeparators.ReplaceAllString(strings.ToLower(component.Name), "-"), "-")
+		if normalized == "" || taken[normalized] || !f.ComponentNaming.MatchString(normalized) {
+			continue
+		}
+		taken[normalized] = true
+		renames[component.Name] = normalized
+	}
+	return renames
+}
+
+// fixImages pins the images of component i to their digest and sorts them, moving
+// their comments with them. Templated images and images whose digest cannot be
+// resolved are not pinned.
+func (f *Fixer) fixImages(ctx context.Context, d *yamledit.Document, i int, name string, images []string,
+	fixed, skipped func(ruleID, format string, args ...interface{})) error {
+	if len(images) == 0 {
+		return nil
+	}
+	if _, ok := d.Strings("components", i, "images"); !ok {
+		return nil
+	}
+	updated := make([]string, len(images))
+	copy(updated, images)
+	for j, image := range updated {
+		if strings.Contains(image, "@") || isTemplated(image) || f.ResolveDigest == nil {
+			continue
+		}
+		digest, err := f.ResolveDigest(ctx, image)
+		if err != nil {
+			skipped("image-pinning", "Could not pin image %s of component '%s': %v", image, name, err)
+			continue
+		}
+		updated[j] = image + "@" + digest
+		fixed("image-pinning", "Pinned image %s of component '%s' to %s", image, name, digest)
+	}
+	for j, image := range updated {
+		if image == images[j] {
+			continue
+		}
+		if err := d.Set(image, "components", i, "images", j); err != nil {
+			return err
+		}
+	}
+	if sort.StringsAreSorted(updated) {
+		return nil
+	}
+	if err := d.Sort("components", i, "images"); err != nil {
+		skipped("image-order", "Could not sort the images of component '%s': %v", name, err)
+		return nil
+	}
+	fixed("image-order", "Sorted the images of component '%s'", name)
+	return nil
+}
+
+// isTemplated reports whether image is set by a package template or variable
+func isTemplated(image string) bool {
+	return strings.Contains(image, "###") || strings.Contains(image, "{{") || strings.Contains(image, "${")
+}
+
+// craneDigest resolves the digest of image from its registry with 'crane digest'
+func craneDigest(ctx context.Context, image string) (string, error) {
+	if _, err := osexec.LookPath("crane"); err != nil {
+		return "", errCraneNotFound
+	}
+	output, err := exec.NewProcessExecutor(false).RunProcessAndCaptureStdout(ctx, "crane", "digest", image)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(output), nil
+}
@@ -0,0 +1,112 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFixerFix(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := filepath.Join(packageDir, "zarf.yaml")
+	require.NoError(t, os.WriteFile(zarfYaml, []byte(`kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+  version: 1.0.0
+components:
+  # The frontend
+  - name: Web_Frontend
+    required: true
+    default: true
+    images:
+      - redis:7 # cache
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+      - "###ZARF_PKG_TMPL_IMAGE###"
+  - name: docs
+    description: Documentation
+    depsWith: [Web_Frontend]
+    images: [nginx@sha256:123, busybox:1.36]
+  - name: Imported
+    import:
+      path: ../shared
+`), 0644))
+
+	f := NewFixer()
+	f.ResolveDigest = func(_ context.Context, image string) (string, error) {
+		if image == "busybox:1.36" {
+			return "", errors.New("not found")
+		}
+		return "sha256:" + strings.Split(image, ":")[1], nil
+	}
+	result, err := f.Fix(context.Background(), packageDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, []Fix{
+		{RuleID: "package-description", Message: "Added a description stub to the package"},
+		{RuleID: "component-naming", Message: "Renamed component 'Web_Frontend' to 'web-frontend'"},
+		{RuleID: "component-description", Message: "Added a description stub to component 'web-frontend'"},
+		{RuleID: "redundant-default", Message: "Removed 'default' from required component 'web-frontend'"},
+		{RuleID: "image-pinning", Message: "Pinned image redis:7 of component 'web-frontend' to sha256:7"},
+		{RuleID: "image-pinning", Message: "Pinned image ghcr.io/stefanprodan/podinfo:6.4.0 of component 'web-frontend' to sha256:6.4.0"},
+		{RuleID: "image-order", Message: "Sorted the images of component 'web-frontend'"},
+		{RuleID: "image-order", Message: "Sorted the images of component 'docs'"},
+		{RuleID: "component-description", Message: "Added a description stub to component 'Imported'"},
+	}, result.Fixes)
+	assert.Equal(t, []Fix{
+		{RuleID: "image-pinning", Message: "Could not pin image busybox:1.36 of component 'docs': not found"},
+	}, result.Skipped)
+
+	content, err := os.ReadFile(zarfYaml)
+	require.NoError(t, err)
+	assert.Equal(t, `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+  version: 1.0.0
+  description: 'TODO: describe the podinfo package'
+components:
+  # The frontend
+  - name: web-frontend
+    required: true
+    images:
+      - "###ZARF_PKG_TMPL_IMAGE###"
+      - ghcr.io/stefanprodan/podinfo:6.4.0@sha256:6.4.0
+      - redis:7@sha256:7 # cache
+    description: 'TODO: describe the web-frontend component'
+  - name: docs
+    description: Documentation
+    depsWith: [web-frontend]
+    images: [busybox:1.36, nginx@sha256:123]
+  - name: Imported
+    import:
+      path: ../shared
+    description: 'TODO: describe the Imported component'
+`, string(content))
+	assert.Contains(t, result.Diff, "--- a/"+filepath.ToSlash(zarfYaml)+"\n+++ b/"+filepath.ToSlash(zarfYaml)+"\n")
+	assert.Contains(t, result.Diff, "-  - name: Web_Frontend\n+  - name: web-frontend\n")
+
+	// Fixed packages are left alone
+	result, err = f.Fix(context.Background(), packageDir)
+	require.NoError(t, err)
+	assert.Empty(t, result.Fixes)
+	assert.Empty(t, result.Diff)
+}
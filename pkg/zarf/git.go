@@ -15,26 +15,67 @@
 package zarf
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/cpepper96/zarf-testing/pkg/exec"
 	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/tracing"
 )
 
-// FindChangedPackages identifies Zarf packages that have been changed between Git references
-func FindChangedPackages(remote, targetBranch string, dirs []string) ([]string, error) {
-	executor := exec.NewProcessExecutor(false) // debug = false
-	git := tool.NewGit(executor)
+// changedPackagesCache holds the results of FindChangedPackages so that commands
+// running several steps, such as lint-and-install, read the repository once
+var changedPackagesCache = struct {
+	sync.Mutex
+	results map[string][]string
+}{results: map[string][]string{}}
+
+// FindChangedPackages identifies Zarf packages that have been changed between Git references.
+// Changes are identified against the merge base of the target branch and HEAD, or against
+// the 'since' reference (e.g. the last release tag) if it is not empty or HEAD. Results
+// are cached for the lifetime of the process.
+func FindChangedPackages(ctx context.Context, remote, targetBranch, since string, dirs []string) ([]string, error) {
+	// Paths are relative to the working directory, which is part of the key
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil, err
+	}
+	key := strings.Join(append([]string{wd, remote, targetBranch, since}, dirs...), "\x00")
+	changedPackagesCache.Lock()
+	cached, ok := changedPackagesCache.results[key]
+	changedPackagesCache.Unlock()
+	if ok {
+		return append([]string(nil), cached...), nil
+	}
+	
+	gitCtx, span := tracing.Start(ctx, "git.changed-packages", "since", since, "target-branch", targetBranch)
+	result, err := findChangedPackages(gitCtx, remote, targetBranch, since, dirs)
+	span.End(err)
+	if err != nil {
+		return nil, err
+	}
+	changedPackagesCache.Lock()
+	changedPackagesCache.results[key] = append([]string(nil), result...)
+	changedPackagesCache.Unlock()
+	return result, nil
+}
+
+func findChangedPackages(ctx context.Context, remote, targetBranch, since string, dirs []string) ([]string, error) {
+	git := tool.NewGit("")
 	
-	// Get list of changed files using merge base
-	mergeBase, err := git.MergeBase(fmt.Sprintf("%s/%s", remote, targetBranch), "HEAD")
+	base, err := changeBase(ctx, git, remote, targetBranch, since)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get merge base: %w", err)
+		return nil, err
 	}
 	
-	changedFiles, err := git.ListChangedFilesInDirs(mergeBase, dirs...)
+	// Imported packages may live outside of dirs, so changes are listed for the whole
+	// repository
+	changedFiles, err := git.ListChangedFilesInDirs(ctx, base)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get changed files: %w", err)
 	}
@@ -51,6 +92,17 @@ func FindChangedPackages(remote, targetBranch string, dirs []string) ([]string,
 			changedPackages[packageDir] = true
 		}
 	}
+
+	// Packages importing a changed package changed as well
+	packages, err := FindZarfPackages(dirs)
+	if err != nil {
+		return nil, err
+	}
+	for _, pkg := range packages {
+		if !changedPackages[pkg] && importsChangedFiles(pkg, changedFiles) {
+			changedPackages[pkg] = true
+		}
+	}
 	
 	// Convert map to slice
 	var result []string
@@ -61,6 +113,22 @@ func FindChangedPackages(remote, targetBranch string, dirs []string) ([]string,
 	return result, nil
 }
 
+// importsChangedFiles reports whether one of the files is part of a package imported
+// by the package, directly or through other imports
+func importsChangedFiles(packagePath string, files []string) bool {
+	// Imports resolved before a cycle still count, the cycle is reported by lint
+	imports, _ := resolveImports(packagePath)
+	for _, importDir := range imports {
+		prefix := filepath.ToSlash(importDir) + "/"
+		for _, file := range files {
+			if strings.HasPrefix(file, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // findPackageContainingFile finds the Zarf package directory that contains the given file
 func findPackageContainingFile(file string, dirs []string) (string, error) {
 	// Walk up the directory tree to find a zarf.yaml file
@@ -83,17 +151,15 @@ func findPackageContainingFile(file string, dirs []string) (string, error) {
 }
 
 // GetChangedFilesMatchingPattern gets changed files that match a specific pattern (e.g., "zarf.yaml")
-func GetChangedFilesMatchingPattern(remote, targetBranch, pattern string) ([]string, error) {
-	executor := exec.NewProcessExecutor(false) // debug = false
-	git := tool.NewGit(executor)
+func GetChangedFilesMatchingPattern(ctx context.Context, remote, targetBranch, since, pattern string) ([]string, error) {
+	git := tool.NewGit("")
 	
-	// Get merge base and then changed files
-	mergeBase, err := git.MergeBase(fmt.Sprintf("%s/%s", remote, targetBranch), "HEAD")
+	base, err := changeBase(ctx, git, remote, targetBranch, since)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get merge base: %w", err)
+		return nil, err
 	}
 	
-	allChangedFiles, err := git.ListChangedFilesInDirs(mergeBase, ".")
+	allChangedFiles, err := git.ListChangedFilesInDirs(ctx, base, ".")
 	if err != nil {
 		return nil, fmt.Errorf("failed to get changed files: %w", err)
 	}
@@ -107,3 +173,60 @@ func GetChangedFilesMatchingPattern(remote, targetBranch, pattern string) ([]str
 	
 	return matchingFiles, nil
 }
+
+// changeBase returns the commit changes are identified against: the 'since' reference
+// if it is set to anything but HEAD, otherwise the merge base of the target branch and HEAD
+func changeBase(ctx context.Context, git tool.Git, remote, targetBranch, since string) (string, error) {
+	if since != "" && since != "HEAD" {
+		if !git.BranchExists(ctx, since) {
+			return "", fmt.Errorf("%w: %q given with '--since'", tool.ErrReferenceNotFound, since)
+		}
+		return since, nil
+	}
+	
+	mergeBase, err := git.MergeBase(ctx, fmt.Sprintf("%s/%s", remote, targetBranch), "HEAD")
+	if err != nil {
+		return "", fmt.Errorf("failed to get merge base: %w", err)
+	}
+	return mergeBase, nil
+}
+
+// EnsureHistory checks that the history needed to identify changed packages is
+// available. Shallow clones, e.g. of actions/checkout, and missing target branches
+// fail with tool.ErrShallowClone or tool.ErrReferenceNotFound unless autoFetch is
+// set, in which case the history is fetched with the git CLI and checked again. It
+// returns whether the history was fetched.
+func EnsureHistory(ctx context.Context, remote, targetBranch, since string, autoFetch bool) (bool, error) {
+	git := tool.NewGit("")
+	
+	_, err := changeBase(ctx, git, remote, targetBranch, since)
+	if err == nil || !autoFetch {
+		return false, err
+	}
+	if !errors.Is(err, tool.ErrShallowClone) && !errors.Is(err, tool.ErrReferenceNotFound) {
+		return false, err
+	}
+	
+	shallow, err := git.IsShallow(ctx)
+	if err != nil {
+		return false, err
+	}
+	
+	args := []interface{}{"fetch", "--tags"}
+	if shallow {
+		args = append(args, "--unshallow")
+	}
+	args = append(args, remote, fmt.Sprintf("+refs/heads/%s:refs/remotes/%s/%s", targetBranch, remote, targetBranch))
+	executor := exec.NewProcessExecutor(false) // debug = false
+	if _, err := executor.RunProcessAndCaptureOutput(ctx, "git", args...); err != nil {
+		return false, fmt.Errorf("failed to fetch history of %s/%s: %w", remote, targetBranch, err)
+	}
+	
+	// Changes identified before the fetch are stale
+	changedPackagesCache.Lock()
+	changedPackagesCache.results = map[string][]string{}
+	changedPackagesCache.Unlock()
+	
+	_, err = changeBase(ctx, git, remote, targetBranch, since)
+	return true, err
+}
@@ -0,0 +1,135 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindChangedPackagesSince(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	commit := func(pkg, message string) {
+		dir := filepath.Join("packages", pkg)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		content := "kind: ZarfPackageConfig\nmetadata:\n  name: " + pkg + "\n  description: " + message + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "zarf.yaml"), []byte(content), 0644))
+		git("add", "-A")
+		git("commit", "-q", "-m", message)
+	}
+
+	git("init", "-q")
+	commit("api", "initial")
+	git("tag", "v1.0.0")
+	commit("web", "release")
+	git("update-ref", "refs/remotes/origin/main", "HEAD")
+	commit("api", "change")
+
+	// By default changes are relative to the merge base with the target branch
+	changed, err := FindChangedPackages(context.Background(), "origin", "main", "HEAD", []string{"packages"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("packages", "api")}, changed)
+
+	// --since compares against the given reference
+	changed, err = FindChangedPackages(context.Background(), "origin", "main", "v1.0.0", []string{"packages"})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{filepath.Join("packages", "api"), filepath.Join("packages", "web")}, changed)
+
+	_, err = FindChangedPackages(context.Background(), "origin", "main", "v2.0.0", []string{"packages"})
+	assert.ErrorIs(t, err, tool.ErrReferenceNotFound)
+}
+
+func TestFindChangedPackagesImports(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	// app imports common/web, which imports common/base outside of the package dirs
+	writePackage(t, filepath.Join("packages", "app"), "  - name: web\n    import:\n      path: ../../common/web\n")
+	writePackage(t, filepath.Join("packages", "api"), "  - name: api\n")
+	writePackage(t, filepath.Join("common", "web"), "  - name: web\n    import:\n      path: ../base\n      name: base\n")
+	writePackage(t, filepath.Join("common", "base"), "  - name: base\n")
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	require.NoError(t, os.WriteFile(filepath.Join("common", "base", "values.yaml"), []byte("replicas: 2\n"), 0644))
+	git("add", "-A")
+	git("commit", "-q", "-m", "change base")
+
+	changed, err := FindChangedPackages(context.Background(), "origin", "main", "HEAD~1", []string{"packages"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join("packages", "app")}, changed)
+}
+
+func TestEnsureHistory(t *testing.T) {
+	git := func(dir string, args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		cmd.Dir = dir
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	commit := func(dir, message string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "zarf.yaml"), []byte("description: "+message+"\n"), 0644))
+		git(dir, "add", "-A")
+		git(dir, "commit", "-q", "-m", message)
+	}
+
+	// main and feature diverge after the initial commit
+	origin := t.TempDir()
+	git(origin, "init", "-q", "-b", "main")
+	commit(origin, "initial")
+	git(origin, "checkout", "-q", "-b", "feature")
+	commit(origin, "feature")
+	git(origin, "checkout", "-q", "main")
+	commit(origin, "main")
+
+	// A shallow clone of both branches lacks their merge base
+	clone := filepath.Join(t.TempDir(), "clone")
+	git(origin, "clone", "-q", "--depth", "1", "--no-single-branch", "--branch", "feature", "file://"+origin, clone)
+	t.Chdir(clone)
+
+	fetched, err := EnsureHistory(context.Background(), "origin", "main", "HEAD", false)
+	assert.False(t, fetched)
+	assert.ErrorIs(t, err, tool.ErrShallowClone)
+
+	fetched, err = EnsureHistory(context.Background(), "origin", "main", "HEAD", true)
+	require.NoError(t, err)
+	assert.True(t, fetched)
+
+	// The history is complete now
+	fetched, err = EnsureHistory(context.Background(), "origin", "main", "HEAD", true)
+	require.NoError(t, err)
+	assert.False(t, fetched)
+}
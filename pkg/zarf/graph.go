@@ -0,0 +1,401 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// Edge kinds of a DependencyGraph
+const (
+	EdgeDependsOn  = "depsWith"
+	EdgeImport     = "import"
+	EdgeReference  = "reference"
+	EdgeSharedFile = "shared-file"
+)
+
+// DependencyGraph describes the dependencies between packages and their components.
+// Package nodes are identified by their directory, component nodes by
+// '<package dir>#<component name>'.
+type DependencyGraph struct {
+	Packages []GraphPackage
+	Edges    []GraphEdge
+}
+
+// GraphPackage is a package node and its components
+type GraphPackage struct {
+	Path       string
+	Name       string
+	Components []string
+}
+
+// GraphEdge is a directed dependency from one node to another
+type GraphEdge struct {
+	From string
+	To   string
+	Kind string
+}
+
+// ComponentNodeID returns the node ID of a component of the package at packagePath
+func ComponentNodeID(packagePath, component string) string {
+	return packagePath + "#" + component
+}
+
+// BuildDependencyGraph builds the dependency graph of the given packages. Packages
+// imported by components are added to the graph even if they are not listed.
+func BuildDependencyGraph(packageDirs []string) (*DependencyGraph, error) {
+	graph := &DependencyGraph{}
+	known := make(map[string]string) // absolute dir -> package node ID
+	var queue []string
+	for _, dir := range packageDirs {
+		queue = append(queue, filepath.Clean(dir))
+	}
+
+	type fileUse struct {
+		packageID string
+		component string
+	}
+	fileUses := make(map[string][]fileUse)
+	edgeSet := make(map[GraphEdge]bool)
+	addEdge := func(edge GraphEdge) {
+		if edge.From != edge.To && !edgeSet[edge] {
+			edgeSet[edge] = true
+			graph.Edges = append(graph.Edges, edge)
+		}
+	}
+
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		absDir, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := known[absDir]; ok {
+			continue
+		}
+		known[absDir] = dir
+
+		zarfYaml, err := util.ReadZarfYaml(filepath.Join(dir, "zarf.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read zarf.yaml of %s: %w", dir, err)
+		}
+
+		pkg := GraphPackage{Path: dir, Name: zarfYaml.Metadata.Name}
+		for _, component := range zarfYaml.Components {
+			pkg.Components = append(pkg.Components, component.Name)
+			from := ComponentNodeID(dir, component.Name)
+
+			for _, dep := range component.DepsWith {
+				addEdge(GraphEdge{From: from, To: ComponentNodeID(dir, dep), Kind: EdgeDependsOn})
+			}
+
+			if component.Import.Path != "" {
+				importDir := filepath.Join(dir, component.Import.Path)
+				if IsZarfPackage(importDir) {
+					queue = append(queue, importDir)
+					to := importDir
+					if component.Import.Name != "" {
+						to = ComponentNodeID(importDir, component.Import.Name)
+					}
+					addEdge(GraphEdge{From: from, To: to, Kind: EdgeImport})
+				}
+			}
+
+			for _, chart := range component.Charts {
+				if chart.LocalPath != "" {
+					fileUses[absPath(dir, chart.LocalPath)] = append(fileUses[absPath(dir, chart.LocalPath)], fileUse{dir, component.Name})
+				}
+				for _, valuesFile := range chart.ValuesFiles {
+					fileUses[absPath(dir, valuesFile)] = append(fileUses[absPath(dir, valuesFile)], fileUse{dir, component.Name})
+				}
+			}
+			for _, file := range component.Files {
+				if file.Source != "" && !isRemoteReference(file.Source) {
+					fileUses[absPath(dir, file.Source)] = append(fileUses[absPath(dir, file.Source)], fileUse{dir, component.Name})
+				}
+			}
+			for _, manifest := range component.Manifests {
+				for _, file := range manifest.Files {
+					if !isRemoteReference(file) {
+						fileUses[absPath(dir, file)] = append(fileUses[absPath(dir, file)], fileUse{dir, component.Name})
+					}
+				}
+			}
+		}
+		graph.Packages = append(graph.Packages, pkg)
+	}
+
+	// Files referenced from outside their package: a chart or file living in another
+	// package is a dependency on that package, files outside of any package that are
+	// shared by several packages link them
+	paths := make([]string, 0, len(fileUses))
+	for path := range fileUses {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		uses := fileUses[path]
+		owner := owningPackage(path, known)
+		for _, use := range uses {
+			if owner != "" && owner != use.packageID {
+				addEdge(GraphEdge{From: ComponentNodeID(use.packageID, use.component), To: owner, Kind: EdgeReference})
+			}
+		}
+		if owner != "" {
+			continue
+		}
+		for i := 1; i < len(uses); i++ {
+			if uses[i].packageID != uses[0].packageID {
+				addEdge(GraphEdge{From: uses[i].packageID, To: uses[0].packageID, Kind: EdgeSharedFile})
+			}
+		}
+	}
+
+	// Imported packages may have been listed under a different relative path
+	for i, edge := range graph.Edges {
+		graph.Edges[i].To = canonicalNodeID(edge.To, known)
+	}
+
+	return graph, nil
+}
+
+// canonicalNodeID rewrites the package part of nodeID to the ID the package was
+// added to the graph with
+func canonicalNodeID(nodeID string, known map[string]string) string {
+	pkg := PackageOf(nodeID)
+	abs, err := filepath.Abs(pkg)
+	if err != nil {
+		return nodeID
+	}
+	id, ok := known[abs]
+	if !ok || id == pkg {
+		return nodeID
+	}
+	return id + strings.TrimPrefix(nodeID, pkg)
+}
+
+// PackageOf returns the package node ID a node belongs to
+func PackageOf(nodeID string) string {
+	if i := strings.LastIndex(nodeID, "#"); i >= 0 {
+		return nodeID[:i]
+	}
+	return nodeID
+}
+
+// DOT renders the graph in Graphviz DOT format with one cluster per package
+func (g *DependencyGraph) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph zarf {\n")
+	b.WriteString("  rankdir=LR;\n")
+	for i, pkg := range g.Packages {
+		fmt.Fprintf(&b, "  subgraph cluster_%d {\n", i)
+		fmt.Fprintf(&b, "    label=%q;\n", pkg.Path)
+		fmt.Fprintf(&b, "    %q [label=%q, shape=box];\n", pkg.Path, graphLabel(pkg))
+		for _, component := range pkg.Components {
+			fmt.Fprintf(&b, "    %q [label=%q];\n", ComponentNodeID(pkg.Path, component), component)
+		}
+		b.WriteString("  }\n")
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %q -> %q [label=%q];\n", edge.From, edge.To, edge.Kind)
+	}
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// Mermaid renders the graph as a Mermaid flowchart with one subgraph per package
+func (g *DependencyGraph) Mermaid() string {
+	ids := make(map[string]string)
+	id := func(node string) string {
+		if _, ok := ids[node]; !ok {
+			ids[node] = fmt.Sprintf("n%d", len(ids))
+		}
+		return ids[node]
+	}
+
+	var b strings.Builder
+	b.WriteString("graph LR\n")
+	for _, pkg := range g.Packages {
+		fmt.Fprintf(&b, "  subgraph %s_pkg [%q]\n", id(pkg.Path), pkg.Path)
+		fmt.Fprintf(&b, "    %s[%q]\n", id(pkg.Path), graphLabel(pkg))
+		for _, component := range pkg.Components {
+			fmt.Fprintf(&b, "    %s(%q)\n", id(ComponentNodeID(pkg.Path, component)), component)
+		}
+		b.WriteString("  end\n")
+	}
+	for _, edge := range g.Edges {
+		fmt.Fprintf(&b, "  %s -->|%s| %s\n", id(edge.From), edge.Kind, id(edge.To))
+	}
+	return b.String()
+}
+
+func graphLabel(pkg GraphPackage) string {
+	if pkg.Name == "" {
+		return pkg.Path
+	}
+	return pkg.Name
+}
+
+func absPath(dir, path string) string {
+	abs, err := filepath.Abs(filepath.Join(dir, path))
+	if err != nil {
+		return filepath.Join(dir, path)
+	}
+	return abs
+}
+
+// owningPackage returns the node ID of the innermost known package containing path
+func owningPackage(path string, known map[string]string) string {
+	owner, ownerLen := "", 0
+	for absDir, id := range known {
+		if (path == absDir || strings.HasPrefix(path, absDir+string(filepath.Separator))) && len(absDir) > ownerLen {
+			owner, ownerLen = id, len(absDir)
+		}
+	}
+	return owner
+}
+
+// DeployOrder returns packages ordered so that every package comes after the packages
+// it depends on. Dependencies on packages outside of the list are ignored. explicit
+// lists packages (by path or metadata name) that must be deployed in the given
+// relative order. Ties are broken by path so the order is deterministic. An error
+// naming the cycle is returned if the dependencies are cyclic.
+func (g *DependencyGraph) DeployOrder(packages []string, explicit []string) ([]string, error) {
+	deps := g.deployDependencies(packages, explicit)
+
+	var order []string
+	done := make(map[string]bool)
+	for len(order) < len(deps) {
+		var ready []string
+		for pkg := range deps {
+			if done[pkg] {
+				continue
+			}
+			blocked := false
+			for dep := range deps[pkg] {
+				if !done[dep] {
+					blocked = true
+					break
+				}
+			}
+			if !blocked {
+				ready = append(ready, pkg)
+			}
+		}
+		if len(ready) == 0 {
+			return nil, fmt.Errorf("dependency cycle between packages: %s", strings.Join(findCycle(deps, done), " -> "))
+		}
+		sort.Strings(ready)
+		done[ready[0]] = true
+		order = append(order, ready[0])
+	}
+	return order, nil
+}
+
+// DeployDependencies returns the packages each of the packages must be deployed after,
+// sorted by path, following the same rules as DeployOrder. Paths are cleaned.
+func (g *DependencyGraph) DeployDependencies(packages []string, explicit []string) map[string][]string {
+	dependencies := make(map[string][]string)
+	for pkg, deps := range g.deployDependencies(packages, explicit) {
+		dependencies[pkg] = sortedKeys(deps)
+	}
+	return dependencies
+}
+
+// deployDependencies returns a map with an entry for every package, where deps[a][b]
+// means a must be deployed after b
+func (g *DependencyGraph) deployDependencies(packages []string, explicit []string) map[string]map[string]bool {
+	selected := make(map[string]bool)
+	for _, pkg := range packages {
+		selected[filepath.Clean(pkg)] = true
+	}
+	byName := make(map[string]string)
+	for _, pkg := range g.Packages {
+		if pkg.Name != "" {
+			byName[pkg.Name] = pkg.Path
+		}
+	}
+
+	deps := make(map[string]map[string]bool)
+	for pkg := range selected {
+		deps[pkg] = make(map[string]bool)
+	}
+	for _, edge := range g.Edges {
+		if edge.Kind == EdgeSharedFile {
+			continue
+		}
+		from, to := PackageOf(edge.From), PackageOf(edge.To)
+		if from != to && selected[from] && selected[to] {
+			deps[from][to] = true
+		}
+	}
+
+	var previous string
+	for _, entry := range explicit {
+		pkg := filepath.Clean(entry)
+		if path, ok := byName[entry]; ok && !selected[pkg] {
+			pkg = path
+		}
+		if !selected[pkg] {
+			continue
+		}
+		if previous != "" {
+			deps[pkg][previous] = true
+		}
+		previous = pkg
+	}
+
+	return deps
+}
+
+// findCycle returns a dependency cycle among the packages not yet done, starting and
+// ending with the same package
+func findCycle(deps map[string]map[string]bool, done map[string]bool) []string {
+	var pending []string
+	for pkg := range deps {
+		if !done[pkg] {
+			pending = append(pending, pkg)
+		}
+	}
+	sort.Strings(pending)
+
+	// Every pending package has a pending dependency, so following the first one
+	// eventually revisits a package
+	var path []string
+	index := make(map[string]int)
+	current := pending[0]
+	for {
+		if i, ok := index[current]; ok {
+			return append(path[i:], current)
+		}
+		index[current] = len(path)
+		path = append(path, current)
+
+		var next []string
+		for dep := range deps[current] {
+			if !done[dep] {
+				next = append(next, dep)
+			}
+		}
+		sort.Strings(next)
+		current = next[0]
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildDependencyGraph(t *testing.T) {
+	graph, err := BuildDependencyGraph([]string{"testdata/graph/app"})
+	require.NoError(t, err)
+
+	// The imported package is added to the graph
+	require.Len(t, graph.Packages, 2)
+	assert.Equal(t, GraphPackage{Path: "testdata/graph/app", Name: "app", Components: []string{"operator", "app"}}, graph.Packages[0])
+	assert.Equal(t, GraphPackage{Path: "testdata/graph/base", Name: "base", Components: []string{"crds", "operator"}}, graph.Packages[1])
+
+	assert.ElementsMatch(t, []GraphEdge{
+		{From: "testdata/graph/app#operator", To: "testdata/graph/base#operator", Kind: EdgeImport},
+		{From: "testdata/graph/base#operator", To: "testdata/graph/base#crds", Kind: EdgeDependsOn},
+		{From: "testdata/graph/app#app", To: "testdata/graph/base", Kind: EdgeReference},
+		{From: "testdata/graph/base", To: "testdata/graph/app", Kind: EdgeSharedFile},
+	}, graph.Edges)
+
+	assert.Contains(t, graph.DOT(), `"testdata/graph/app#operator" -> "testdata/graph/base#operator" [label="import"];`)
+	assert.Contains(t, graph.Mermaid(), "subgraph n0_pkg [\"testdata/graph/app\"]")
+}
+
+func TestDeployOrder(t *testing.T) {
+	graph := &DependencyGraph{
+		Packages: []GraphPackage{
+			{Path: "packages/app", Name: "app"},
+			{Path: "packages/base", Name: "base"},
+			{Path: "packages/db", Name: "db"},
+			{Path: "packages/tools", Name: "tools"},
+		},
+		Edges: []GraphEdge{
+			{From: "packages/app#app", To: "packages/base#operator", Kind: EdgeImport},
+			{From: "packages/app#app", To: "packages/db", Kind: EdgeReference},
+			{From: "packages/tools", To: "packages/app", Kind: EdgeSharedFile},
+		},
+	}
+	all := []string{"packages/tools", "packages/app", "packages/db", "packages/base"}
+
+	order, err := graph.DeployOrder(all, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"packages/base", "packages/db", "packages/app", "packages/tools"}, order)
+
+	// Explicit order by name and path
+	order, err = graph.DeployOrder(all, []string{"tools", "packages/db"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"packages/base", "packages/tools", "packages/db", "packages/app"}, order)
+
+	// Dependencies outside of the selection are ignored
+	order, err = graph.DeployOrder([]string{"packages/app"}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"packages/app"}, order)
+
+	// Cycles are reported
+	_, err = graph.DeployOrder(all, []string{"app", "base"})
+	assert.EqualError(t, err, "dependency cycle between packages: packages/app -> packages/base -> packages/app")
+}
@@ -0,0 +1,492 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// HistoryRun is a lint or install run recorded in the history store. Exactly one of
+// Lint and Install is set.
+type HistoryRun struct {
+	Timestamp string         `json:"timestamp"`
+	Commit    string         `json:"commit,omitempty"`
+	Branch    string         `json:"branch,omitempty"`
+	BuildID   string         `json:"buildId,omitempty"`
+	Lint      *LintReport    `json:"lint,omitempty"`
+	Install   *InstallReport `json:"install,omitempty"`
+}
+
+// Time returns when the run took place
+func (r HistoryRun) Time() time.Time {
+	t, _ := time.Parse(time.RFC3339, r.Timestamp)
+	return t
+}
+
+// HistoryStore records the results of runs to report trends over time
+type HistoryStore interface {
+	// Append adds runs to the store
+	Append(ctx context.Context, runs []HistoryRun) error
+	// Runs returns the runs that took place at or after since, oldest first
+	Runs(ctx context.Context, since time.Time) ([]HistoryRun, error)
+}
+
+// objectStoreCommands are the CLIs uploading a file to and downloading a prefix from
+// the object stores, keyed by URL scheme
+var objectStoreCommands = map[string]struct{ upload, download []string }{
+	"s3": {upload: []string{"aws", "s3", "cp"}, download: []string{"aws", "s3", "sync"}},
+	"gs": {upload: []string{"gcloud", "storage", "cp"}, download: []string{"gcloud", "storage", "rsync"}},
+}
+
+// OpenHistoryStore opens the store at location:
+//   - a file:// URL or a path: a file holding a run per line as JSON, so it can be
+//     appended to cheaply and kept between CI runs like any other cache
+//   - a sqlite:// URL: a SQLite database, accessed with the sqlite3 CLI
+//   - an s3:// or gs:// URL: a bucket prefix holding a file of JSON lines per
+//     append, accessed with the aws or gcloud CLI, so concurrent CI runs never
+//     overwrite each other's results
+func OpenHistoryStore(location string) (HistoryStore, error) {
+	scheme, path, found := strings.Cut(location, "://")
+	if !found {
+		return &fileHistoryStore{path: location}, nil
+	}
+	if path == "" {
+		return nil, fmt.Errorf("invalid history store %q, the path is missing", location)
+	}
+	switch scheme {
+	case "file":
+		return &fileHistoryStore{path: path}, nil
+	case "sqlite":
+		return &sqliteHistoryStore{path: path}, nil
+	case "s3", "gs":
+		commands := objectStoreCommands[scheme]
+		return &objectHistoryStore{url: strings.TrimSuffix(location, "/"), upload: commands.upload, download: commands.download}, nil
+	default:
+		return nil, fmt.Errorf("unsupported history store %q, must be a path or a file://, sqlite://, s3:// or gs:// URL", location)
+	}
+}
+
+// fileHistoryStore stores runs as JSON lines in a file
+type fileHistoryStore struct {
+	path string
+}
+
+func (s *fileHistoryStore) Append(_ context.Context, runs []HistoryRun) error {
+	var content []byte
+	for _, run := range runs {
+		line, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		content = append(append(content, line...), '\n')
+	}
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history store: %w", err)
+	}
+	file, err := os.OpenFile(s.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open history store: %w", err)
+	}
+	if _, err := file.Write(content); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write history store: %w", err)
+	}
+	return file.Close()
+}
+
+func (s *fileHistoryStore) Runs(_ context.Context, since time.Time) ([]HistoryRun, error) {
+	file, err := os.Open(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history store: %w", err)
+	}
+	defer file.Close()
+
+	runs, err := readHistoryRuns(file, s.path, since)
+	if err != nil {
+		return nil, err
+	}
+	sortHistoryRuns(runs)
+	return runs, nil
+}
+
+// readHistoryRuns reads the runs, one per line as JSON, that took place at or after
+// since. name names the source in errors.
+func readHistoryRuns(reader io.Reader, name string, since time.Time) ([]HistoryRun, error) {
+	var runs []HistoryRun
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(nil, 64*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var run HistoryRun
+		if err := json.Unmarshal(scanner.Bytes(), &run); err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid run: %w", name, line, err)
+		}
+		if !run.Time().Before(since) {
+			runs = append(runs, run)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read history store: %w", err)
+	}
+	return runs, nil
+}
+
+func sortHistoryRuns(runs []HistoryRun) {
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].Time().Before(runs[j].Time()) })
+}
+
+// sqliteHistoryStore stores runs as JSON in the runs table of a SQLite database
+type sqliteHistoryStore struct {
+	path string
+}
+
+const sqliteHistorySchema = "CREATE TABLE IF NOT EXISTS runs (timestamp TEXT NOT NULL, run TEXT NOT NULL);\n"
+
+func (s *sqliteHistoryStore) Append(ctx context.Context, runs []HistoryRun) error {
+	statements := &strings.Builder{}
+	statements.WriteString(sqliteHistorySchema + "BEGIN;\n")
+	for _, run := range runs {
+		line, err := json.Marshal(run)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(statements, "INSERT INTO runs (timestamp, run) VALUES (%s, %s);\n", sqlQuote(run.Timestamp), sqlQuote(string(line)))
+	}
+	statements.WriteString("COMMIT;\n")
+
+	if err := os.MkdirAll(filepath.Dir(s.path), 0755); err != nil {
+		return fmt.Errorf("failed to create history store: %w", err)
+	}
+	_, err := s.run(ctx, statements.String())
+	return err
+}
+
+func (s *sqliteHistoryStore) Runs(ctx context.Context, since time.Time) ([]HistoryRun, error) {
+	if !util.FileExists(s.path) {
+		return nil, nil
+	}
+	output, err := s.run(ctx, sqliteHistorySchema+"SELECT run FROM runs ORDER BY rowid;\n")
+	if err != nil {
+		return nil, err
+	}
+	runs, err := readHistoryRuns(strings.NewReader(output), s.path, since)
+	if err != nil {
+		return nil, err
+	}
+	sortHistoryRuns(runs)
+	return runs, nil
+}
+
+// run runs SQL statements against the database with the sqlite3 CLI and returns
+// their output, a row per line
+func (s *sqliteHistoryStore) run(ctx context.Context, statements string) (string, error) {
+	cmd, err := exec.NewProcessExecutor(false).CreateProcess(ctx, "sqlite3", "-batch", s.path)
+	if err != nil {
+		return "", err
+	}
+	cmd.Stdin = strings.NewReader(statements)
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	output, err := cmd.Output()
+	if err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return "", fmt.Errorf("failed to access history store %s with sqlite3: %s", s.path, message)
+	}
+	return string(output), nil
+}
+
+// sqlQuote quotes a value as an SQL string literal
+func sqlQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", "''") + "'"
+}
+
+// objectHistoryStore stores runs below a bucket prefix, as a file of JSON lines per
+// append
+type objectHistoryStore struct {
+	url      string
+	upload   []string // command copying a local file to a URL
+	download []string // command syncing a prefix to a local directory
+}
+
+func (s *objectHistoryStore) Append(ctx context.Context, runs []HistoryRun) error {
+	dir, err := os.MkdirTemp("", "zt-history-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	name := fmt.Sprintf("%s-%s.jsonl", time.Now().UTC().Format("20060102T150405Z"), util.RandomString(6))
+	file := filepath.Join(dir, name)
+	if err := (&fileHistoryStore{path: file}).Append(ctx, runs); err != nil {
+		return err
+	}
+	return s.run(ctx, s.upload, file, s.url+"/"+name)
+}
+
+func (s *objectHistoryStore) Runs(ctx context.Context, since time.Time) ([]HistoryRun, error) {
+	dir, err := os.MkdirTemp("", "zt-history-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+
+	if err := s.run(ctx, s.download, s.url, dir); err != nil {
+		return nil, err
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	var runs []HistoryRun
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		fileRuns, err := readHistoryRuns(bytes.NewReader(content), s.url+"/"+filepath.Base(file), since)
+		if err != nil {
+			return nil, err
+		}
+		runs = append(runs, fileRuns...)
+	}
+	sortHistoryRuns(runs)
+	return runs, nil
+}
+
+func (s *objectHistoryStore) run(ctx context.Context, command []string, args ...string) error {
+	execArgs := []interface{}{}
+	for _, arg := range append(command[1:len(command):len(command)], args...) {
+		execArgs = append(execArgs, arg)
+	}
+	cmd, err := exec.NewProcessExecutor(false).CreateProcess(ctx, command[0], execArgs...)
+	if err != nil {
+		return err
+	}
+	if output, err := cmd.CombinedOutput(); err != nil {
+		message := strings.TrimSpace(string(output))
+		if message == "" {
+			message = err.Error()
+		}
+		return fmt.Errorf("failed to access history store %s with %s: %s", s.url, command[0], message)
+	}
+	return nil
+}
+
+// Trends summarizes the runs of a history store
+type Trends struct {
+	Runs            int               `json:"runs"`
+	From            string            `json:"from,omitempty"`
+	To              string            `json:"to,omitempty"`
+	SlowestPackages []PackageTiming   `json:"slowestPackages"`
+	FailingRules    []RuleFailures    `json:"failingRules"`
+	FlakyInstalls   []FlakyDeployment `json:"flakyInstalls"`
+}
+
+// PackageTiming is the time taken to lint or deploy a package across runs
+type PackageTiming struct {
+	Path    string  `json:"path"`
+	Kind    string  `json:"kind"`
+	Runs    int     `json:"runs"`
+	Average float64 `json:"averageSeconds"`
+	Max     float64 `json:"maxSeconds"`
+}
+
+// RuleFailures counts the findings of a lint rule across runs
+type RuleFailures struct {
+	Rule     string `json:"rule"`
+	Errors   int    `json:"errors"`
+	Warnings int    `json:"warnings"`
+	Packages int    `json:"packages"`
+}
+
+// FlakyDeployment is a deployment that both passed and failed across runs
+type FlakyDeployment struct {
+	Path        string `json:"path"`
+	Cluster     string `json:"cluster,omitempty"`
+	VariableSet string `json:"variableSet,omitempty"`
+	Components  string `json:"components,omitempty"`
+	Mirror      bool   `json:"mirror,omitempty"`
+	Passed      int    `json:"passed"`
+	Failed      int    `json:"failed"`
+	// Flips is how often the result changed from one run to the next
+	Flips int `json:"flips"`
+}
+
+// Label describes the deployment by its package, cluster and configuration
+func (f FlakyDeployment) Label() string {
+	return f.Path + deploymentLabel(DeploymentReport{Cluster: f.Cluster, VariableSet: f.VariableSet, Components: f.Components, Mirror: f.Mirror})
+}
+
+// NewTrends summarizes runs, which must be ordered oldest first, into the limit
+// slowest packages, most frequently failing rules and flakiest installs
+func NewTrends(runs []HistoryRun, limit int) *Trends {
+	trends := &Trends{
+		Runs:            len(runs),
+		SlowestPackages: []PackageTiming{},
+		FailingRules:    []RuleFailures{},
+		FlakyInstalls:   []FlakyDeployment{},
+	}
+	if len(runs) > 0 {
+		trends.From, trends.To = runs[0].Timestamp, runs[len(runs)-1].Timestamp
+	}
+
+	timings := map[[2]string]*PackageTiming{}
+	addTiming := func(kind, path string, seconds float64) {
+		key := [2]string{kind, path}
+		timing, ok := timings[key]
+		if !ok {
+			timing = &PackageTiming{Path: path, Kind: kind}
+			timings[key] = timing
+		}
+		timing.Average = (timing.Average*float64(timing.Runs) + seconds) / float64(timing.Runs+1)
+		timing.Runs++
+		if seconds > timing.Max {
+			timing.Max = seconds
+		}
+	}
+
+	rules := map[string]*RuleFailures{}
+	rulePackages := map[string]map[string]bool{}
+	type outcome struct {
+		flaky *FlakyDeployment
+		last  bool
+	}
+	deployments := map[string]*outcome{}
+
+	for _, run := range runs {
+		if run.Lint != nil {
+			for _, pkg := range run.Lint.Packages {
+				if pkg.Duration > 0 {
+					addTiming(ReportKindLint, pkg.Path, pkg.Duration)
+				}
+				for _, finding := range pkg.Findings {
+					if finding.Severity == SeverityInfo {
+						continue
+					}
+					rule, ok := rules[finding.RuleID]
+					if !ok {
+						rule = &RuleFailures{Rule: finding.RuleID}
+						rules[finding.RuleID] = rule
+						rulePackages[finding.RuleID] = map[string]bool{}
+					}
+					if finding.Severity == SeverityError {
+						rule.Errors++
+					} else {
+						rule.Warnings++
+					}
+					rulePackages[finding.RuleID][pkg.Path] = true
+				}
+			}
+		}
+		if run.Install != nil {
+			for _, deployment := range run.Install.Deployments {
+				addTiming(ReportKindInstall, deployment.Path, deployment.Duration)
+				key := deploymentKey(deployment.Path, deployment.Cluster, deployment.VariableSet, deployment.Components)
+				if deployment.Mirror {
+					key += "\x00mirror"
+				}
+				state, ok := deployments[key]
+				if !ok {
+					state = &outcome{flaky: &FlakyDeployment{Path: deployment.Path, Cluster: deployment.Cluster,
+						VariableSet: deployment.VariableSet, Components: deployment.Components, Mirror: deployment.Mirror}}
+					deployments[key] = state
+				} else if state.last != deployment.Success {
+					state.flaky.Flips++
+				}
+				state.last = deployment.Success
+				if deployment.Success {
+					state.flaky.Passed++
+				} else {
+					state.flaky.Failed++
+				}
+			}
+		}
+	}
+
+	for _, timing := range timings {
+		trends.SlowestPackages = append(trends.SlowestPackages, *timing)
+	}
+	sort.Slice(trends.SlowestPackages, func(i, j int) bool {
+		a, b := trends.SlowestPackages[i], trends.SlowestPackages[j]
+		if a.Average != b.Average {
+			return a.Average > b.Average
+		}
+		return a.Kind+a.Path < b.Kind+b.Path
+	})
+
+	for name, rule := range rules {
+		rule.Packages = len(rulePackages[name])
+		trends.FailingRules = append(trends.FailingRules, *rule)
+	}
+	sort.Slice(trends.FailingRules, func(i, j int) bool {
+		a, b := trends.FailingRules[i], trends.FailingRules[j]
+		if a.Errors+a.Warnings != b.Errors+b.Warnings {
+			return a.Errors+a.Warnings > b.Errors+b.Warnings
+		}
+		return a.Rule < b.Rule
+	})
+
+	for _, state := range deployments {
+		if state.flaky.Passed > 0 && state.flaky.Failed > 0 {
+			trends.FlakyInstalls = append(trends.FlakyInstalls, *state.flaky)
+		}
+	}
+	sort.Slice(trends.FlakyInstalls, func(i, j int) bool {
+		a, b := trends.FlakyInstalls[i], trends.FlakyInstalls[j]
+		if a.Flips != b.Flips {
+			return a.Flips > b.Flips
+		}
+		if a.Failed != b.Failed {
+			return a.Failed > b.Failed
+		}
+		return a.Label() < b.Label()
+	})
+
+	if limit > 0 {
+		trends.SlowestPackages = truncate(trends.SlowestPackages, limit)
+		trends.FailingRules = truncate(trends.FailingRules, limit)
+		trends.FlakyInstalls = truncate(trends.FlakyInstalls, limit)
+	}
+	return trends
+}
+
+func truncate[T any](items []T, limit int) []T {
+	if len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
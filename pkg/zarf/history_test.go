@@ -0,0 +1,165 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenHistoryStore(t *testing.T) {
+	store, err := OpenHistoryStore("file://.zt/history.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, &fileHistoryStore{path: ".zt/history.jsonl"}, store)
+
+	store, err = OpenHistoryStore("history.jsonl")
+	require.NoError(t, err)
+	assert.Equal(t, &fileHistoryStore{path: "history.jsonl"}, store)
+
+	store, err = OpenHistoryStore("sqlite://.zt/history.db")
+	require.NoError(t, err)
+	assert.Equal(t, &sqliteHistoryStore{path: ".zt/history.db"}, store)
+
+	store, err = OpenHistoryStore("gs://bucket/history/")
+	require.NoError(t, err)
+	assert.Equal(t, &objectHistoryStore{url: "gs://bucket/history", upload: []string{"gcloud", "storage", "cp"},
+		download: []string{"gcloud", "storage", "rsync"}}, store)
+
+	_, err = OpenHistoryStore("s3://")
+	assert.EqualError(t, err, `invalid history store "s3://", the path is missing`)
+
+	_, err = OpenHistoryStore("postgres://db/history")
+	assert.EqualError(t, err, `unsupported history store "postgres://db/history", must be a path or a file://, sqlite://, s3:// or gs:// URL`)
+}
+
+// testHistoryStore appends runs out of order to an empty store and reads them back
+func testHistoryStore(t *testing.T, store HistoryStore) {
+	t.Helper()
+	ctx := context.Background()
+
+	// A store that does not exist yet has no runs
+	runs, err := store.Runs(ctx, time.Time{})
+	require.NoError(t, err)
+	assert.Empty(t, runs)
+
+	old := HistoryRun{Timestamp: "2026-01-01T00:00:00Z", Commit: "abc", Lint: &LintReport{Kind: ReportKindLint}}
+	recent := HistoryRun{Timestamp: "2026-03-01T00:00:00Z", Branch: "it's main", Install: &InstallReport{Kind: ReportKindInstall}}
+	require.NoError(t, store.Append(ctx, []HistoryRun{recent}))
+	require.NoError(t, store.Append(ctx, []HistoryRun{old}))
+
+	runs, err = store.Runs(ctx, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, runs, 2)
+	assert.Equal(t, []string{"2026-01-01T00:00:00Z", "2026-03-01T00:00:00Z"}, []string{runs[0].Timestamp, runs[1].Timestamp})
+	assert.Equal(t, "abc", runs[0].Commit)
+	assert.NotNil(t, runs[1].Install)
+
+	runs, err = store.Runs(ctx, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "it's main", runs[0].Branch)
+}
+
+func TestFileHistoryStore(t *testing.T) {
+	store, err := OpenHistoryStore(filepath.Join(t.TempDir(), "zt", "history.jsonl"))
+	require.NoError(t, err)
+	testHistoryStore(t, store)
+}
+
+func TestSQLiteHistoryStore(t *testing.T) {
+	if _, err := osexec.LookPath("sqlite3"); err != nil {
+		t.Skip("sqlite3 not found in PATH")
+	}
+	store, err := OpenHistoryStore("sqlite://" + filepath.Join(t.TempDir(), "zt", "history.db"))
+	require.NoError(t, err)
+	testHistoryStore(t, store)
+}
+
+func TestObjectHistoryStore(t *testing.T) {
+	// The fake aws CLI serves the bucket from a directory
+	bucket := t.TempDir()
+	fakeCommands(t, map[string]string{"aws": fmt.Sprintf(`path() { case "$1" in s3://*) echo "%s/${1#s3://}" ;; *) echo "$1" ;; esac; }
+case "$2" in
+cp) mkdir -p "$(dirname "$(path "$4")")" && cp "$(path "$3")" "$(path "$4")" ;;
+sync) mkdir -p "$(path "$3")" && cp -R "$(path "$3")/." "$4" ;;
+esac`, bucket)})
+
+	store, err := OpenHistoryStore("s3://results/zt-history")
+	require.NoError(t, err)
+	testHistoryStore(t, store)
+
+	objects, err := filepath.Glob(filepath.Join(bucket, "results", "zt-history", "*.jsonl"))
+	require.NoError(t, err)
+	assert.Len(t, objects, 2)
+
+	fakeCommands(t, map[string]string{"aws": "echo 'An error occurred (AccessDenied)' >&2; exit 1"})
+	_, err = store.Runs(context.Background(), time.Time{})
+	assert.EqualError(t, err, "failed to access history store s3://results/zt-history with aws: An error occurred (AccessDenied)")
+}
+
+func TestNewTrends(t *testing.T) {
+	lintRun := func(timestamp string, duration float64, findings ...Finding) HistoryRun {
+		return HistoryRun{Timestamp: timestamp, Lint: &LintReport{Packages: []PackageReport{
+			{Path: "packages/a", Duration: duration, Findings: findings},
+			{Path: "packages/b", Duration: 1, Findings: []Finding{{RuleID: "image-pinning", Severity: SeverityWarning}}},
+		}}}
+	}
+	installRun := func(timestamp string, successA bool, durationA float64) HistoryRun {
+		return HistoryRun{Timestamp: timestamp, Install: &InstallReport{Deployments: []DeploymentReport{
+			{Path: "packages/a", Cluster: "k8s-1.30", Success: successA, Duration: durationA},
+			{Path: "packages/b", Success: false, Duration: 10},
+		}}}
+	}
+
+	runs := []HistoryRun{
+		lintRun("2026-01-01T00:00:00Z", 2,
+			Finding{RuleID: "image-pinning", Severity: SeverityError},
+			Finding{RuleID: "zarf-lint", Severity: SeverityInfo}),
+		installRun("2026-01-01T00:05:00Z", true, 100),
+		lintRun("2026-01-02T00:00:00Z", 4, Finding{RuleID: "file-reference", Severity: SeverityError}),
+		installRun("2026-01-02T00:05:00Z", false, 200),
+		installRun("2026-01-03T00:05:00Z", true, 120),
+	}
+
+	trends := NewTrends(runs, 10)
+	assert.Equal(t, 5, trends.Runs)
+	assert.Equal(t, "2026-01-01T00:00:00Z", trends.From)
+	assert.Equal(t, "2026-01-03T00:05:00Z", trends.To)
+	assert.Equal(t, []PackageTiming{
+		{Path: "packages/a", Kind: ReportKindInstall, Runs: 3, Average: 140, Max: 200},
+		{Path: "packages/b", Kind: ReportKindInstall, Runs: 3, Average: 10, Max: 10},
+		{Path: "packages/a", Kind: ReportKindLint, Runs: 2, Average: 3, Max: 4},
+		{Path: "packages/b", Kind: ReportKindLint, Runs: 2, Average: 1, Max: 1},
+	}, trends.SlowestPackages)
+	assert.Equal(t, []RuleFailures{
+		{Rule: "image-pinning", Errors: 1, Warnings: 2, Packages: 2},
+		{Rule: "file-reference", Errors: 1, Packages: 1},
+	}, trends.FailingRules)
+	assert.Equal(t, []FlakyDeployment{
+		{Path: "packages/a", Cluster: "k8s-1.30", Passed: 2, Failed: 1, Flips: 2},
+	}, trends.FlakyInstalls)
+	assert.Equal(t, "packages/a (cluster k8s-1.30)", trends.FlakyInstalls[0].Label())
+
+	limited := NewTrends(runs, 1)
+	assert.Len(t, limited.SlowestPackages, 1)
+	assert.Len(t, limited.FailingRules, 1)
+}
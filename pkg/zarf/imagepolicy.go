@@ -0,0 +1,202 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	osexec "os/exec"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+)
+
+// ImagePolicy requires every image of a package to come from an approved registry or
+// repository, e.g. Iron Bank or Chainguard, or to carry a label such as an approval
+// stamp of a hardening pipeline
+type ImagePolicy struct {
+	// Approved are image prefixes such as 'registry1.dso.mil/ironbank/', or glob
+	// patterns such as 'cgr.dev/chainguard/*', matched against the fully qualified
+	// image ('nginx' is 'docker.io/library/nginx')
+	Approved []string
+
+	// Label is a 'key' or 'key=value' label that images outside the approved list must
+	// carry. The labels are read with 'crane config'.
+	Label string
+}
+
+// errCraneNotFound is returned when image labels are read without crane in PATH
+var errCraneNotFound = errors.New("crane not found in PATH")
+
+// approved reports whether image matches one of the approved prefixes or patterns
+func (p ImagePolicy) approved(image string) bool {
+	image = qualifyImage(image)
+	for _, pattern := range p.Approved {
+		pattern = qualifyImage(pattern)
+		if strings.ContainsAny(pattern, "*?[") {
+			if matched, _ := path.Match(pattern, imageRepository(image)); matched {
+				return true
+			}
+			continue
+		}
+		if strings.HasPrefix(image, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// labeled reports whether the labels carry the label of the policy
+func (p ImagePolicy) labeled(labels map[string]string) bool {
+	key, value, hasValue := strings.Cut(p.Label, "=")
+	actual, ok := labels[key]
+	return ok && (!hasValue || actual == value)
+}
+
+// qualifyImage prefixes images of Docker Hub with docker.io and, for official images,
+// the library namespace
+func qualifyImage(image string) string {
+	if registry, rest, found := strings.Cut(image, "/"); found && (registry == "docker.io" || registry == "index.docker.io") {
+		image = rest
+	}
+	first, rest, found := strings.Cut(image, "/")
+	if found && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		return image
+	}
+	if !found {
+		return "docker.io/library/" + image
+	}
+	return "docker.io/" + first + "/" + rest
+}
+
+// imageRepository strips the tag and digest from image
+func imageRepository(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if i := strings.LastIndex(image, ":"); i > strings.LastIndex(image, "/") {
+		image = image[:i]
+	}
+	return image
+}
+
+// imageLabels reads the labels of image from its registry with 'crane config'
+func imageLabels(ctx context.Context, image string) (map[string]string, error) {
+	if _, err := osexec.LookPath("crane"); err != nil {
+		return nil, errCraneNotFound
+	}
+	output, err := exec.NewProcessExecutor(false).RunProcessAndCaptureStdout(ctx, "crane", "config", image)
+	if err != nil {
+		return nil, err
+	}
+	var config struct {
+		Config struct {
+			Labels map[string]string `json:"Labels"`
+		} `json:"config"`
+	}
+	if err := json.Unmarshal([]byte(output), &config); err != nil {
+		return nil, fmt.Errorf("failed to parse the config of %s: %w", image, err)
+	}
+	return config.Config.Labels, nil
+}
+
+// Check reports whether image complies with the policy and why it does not. The labels
+// of images outside the approved list are read from the registry.
+func (p ImagePolicy) Check(ctx context.Context, image string) (bool, string) {
+	if p.approved(image) {
+		return true, ""
+	}
+	if p.Label == "" {
+		return false, "is not from an approved registry"
+	}
+	labels, err := imageLabels(ctx, image)
+	switch {
+	case err != nil:
+		return false, fmt.Sprintf("is not from an approved registry and its labels could not be read: %v", err)
+	case p.labeled(labels):
+		return true, ""
+	default:
+		return false, fmt.Sprintf("is not from an approved registry and does not carry the label %s", p.Label)
+	}
+}
+
+// ImageViolation is an image not complying with the image policy
+type ImageViolation struct {
+	Image      string   `yaml:"image" json:"image"`
+	Components []string `yaml:"components" json:"components"`
+	Reason     string   `yaml:"reason" json:"reason"`
+}
+
+// PackageImageViolations are the non-compliant images of a package
+type PackageImageViolations struct {
+	Package string           `yaml:"package" json:"package"`
+	Images  []ImageViolation `yaml:"images" json:"images"`
+}
+
+// Violations checks every image of the inventory once and returns the non-compliant
+// images per package, sorted by package and image. Packages without violations are
+// omitted.
+func (p ImagePolicy) Violations(ctx context.Context, inventory *ImageInventory) []PackageImageViolations {
+	byPackage := map[string]*PackageImageViolations{}
+	var packages []string
+	for _, usage := range inventory.Images {
+		compliant, reason := p.Check(ctx, usage.Image)
+		if compliant {
+			continue
+		}
+		components := map[string][]string{}
+		for _, user := range usage.Users {
+			components[user.Package] = append(components[user.Package], user.Component)
+		}
+		for _, user := range usage.Users {
+			if components[user.Package] == nil {
+				continue
+			}
+			if byPackage[user.Package] == nil {
+				byPackage[user.Package] = &PackageImageViolations{Package: user.Package}
+				packages = append(packages, user.Package)
+			}
+			violations := byPackage[user.Package]
+			violations.Images = append(violations.Images, ImageViolation{Image: usage.Image, Components: components[user.Package], Reason: reason})
+			components[user.Package] = nil
+		}
+	}
+
+	sort.Strings(packages)
+	result := []PackageImageViolations{}
+	for _, pkg := range packages {
+		result = append(result, *byPackage[pkg])
+	}
+	return result
+}
+
+// validateImagePolicy reports the images of every component that neither come from an
+// approved registry nor carry the label of the image policy
+func (v *PackageValidator) validateImagePolicy(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	if v.ImagePolicy == nil {
+		return nil
+	}
+
+	for _, component := range pkg.ZarfYaml.Components {
+		for _, image := range component.Images {
+			if compliant, reason := v.ImagePolicy.Check(ctx, image); !compliant {
+				result.AddError("image-policy", fmt.Sprintf("Component '%s' uses an image that %s: %s", component.Name, reason, image))
+			}
+		}
+	}
+	return nil
+}
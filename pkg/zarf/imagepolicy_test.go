@@ -0,0 +1,108 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQualifyImage(t *testing.T) {
+	assert.Equal(t, "docker.io/library/nginx:1.25", qualifyImage("nginx:1.25"))
+	assert.Equal(t, "docker.io/library/redis:5.0", qualifyImage("docker.io/redis:5.0"))
+	assert.Equal(t, "docker.io/bitnami/redis", qualifyImage("bitnami/redis"))
+	assert.Equal(t, "registry1.dso.mil/ironbank/opensource/nginx:1.25", qualifyImage("registry1.dso.mil/ironbank/opensource/nginx:1.25"))
+	assert.Equal(t, "localhost:5000/app", qualifyImage("localhost:5000/app"))
+	assert.Equal(t, "localhost/app", qualifyImage("localhost/app"))
+}
+
+func TestImagePolicyApproved(t *testing.T) {
+	policy := ImagePolicy{Approved: []string{"registry1.dso.mil/ironbank/", "cgr.dev/chainguard/*", "nginx"}}
+	assert.True(t, policy.approved("registry1.dso.mil/ironbank/opensource/nginx:1.25"))
+	assert.True(t, policy.approved("cgr.dev/chainguard/static:latest@sha256:abc"))
+	assert.False(t, policy.approved("cgr.dev/chainguard/nested/static:latest"))
+	assert.True(t, policy.approved("nginx:1.25"))
+	assert.True(t, policy.approved("docker.io/library/nginx"))
+	assert.False(t, policy.approved("ghcr.io/stefanprodan/podinfo:6.4.0"))
+	assert.False(t, policy.approved("registry1.dso.mil/other/image:1"))
+}
+
+func TestImagePolicyCheck(t *testing.T) {
+	// crane returns the config of images, labeled for 'approved' images only
+	fakeCommands(t, map[string]string{
+		"crane": `case "$2" in
+*approved*) echo '{"config":{"Labels":{"mil.dso.ironbank.approved":"true"}}}' ;;
+*missing*) echo "MANIFEST_UNKNOWN" >&2; exit 1 ;;
+*) echo '{"config":{"Labels":{"maintainer":"me"}}}' ;;
+esac`,
+	})
+
+	policy := ImagePolicy{Approved: []string{"registry1.dso.mil/ironbank/"}, Label: "mil.dso.ironbank.approved=true"}
+	ctx := context.Background()
+	compliant, _ := policy.Check(ctx, "registry1.dso.mil/ironbank/opensource/nginx:1.25")
+	assert.True(t, compliant)
+	compliant, _ = policy.Check(ctx, "ghcr.io/org/approved-app:1.0")
+	assert.True(t, compliant)
+	compliant, reason := policy.Check(ctx, "ghcr.io/org/app:1.0")
+	assert.False(t, compliant)
+	assert.Equal(t, "is not from an approved registry and does not carry the label mil.dso.ironbank.approved=true", reason)
+	compliant, reason = policy.Check(ctx, "ghcr.io/org/missing:1.0")
+	assert.False(t, compliant)
+	assert.Contains(t, reason, "its labels could not be read")
+
+	// A label without a value only needs to be present
+	policy.Label = "maintainer"
+	compliant, _ = policy.Check(ctx, "ghcr.io/org/app:1.0")
+	assert.True(t, compliant)
+}
+
+func TestImagePolicyViolations(t *testing.T) {
+	inventory := &ImageInventory{Images: []ImageUsage{
+		{Image: "cgr.dev/chainguard/static:latest", Users: []ImageUser{{Package: "packages/app", Component: "web"}}},
+		{Image: "nginx:1.25", Users: []ImageUser{
+			{Package: "packages/app", Component: "proxy"},
+			{Package: "packages/app", Component: "web"},
+			{Package: "packages/docs", Component: "site"},
+		}},
+	}}
+
+	policy := ImagePolicy{Approved: []string{"cgr.dev/chainguard/"}}
+	assert.Equal(t, []PackageImageViolations{
+		{Package: "packages/app", Images: []ImageViolation{{Image: "nginx:1.25", Components: []string{"proxy", "web"}, Reason: "is not from an approved registry"}}},
+		{Package: "packages/docs", Images: []ImageViolation{{Image: "nginx:1.25", Components: []string{"site"}, Reason: "is not from an approved registry"}}},
+	}, policy.Violations(context.Background(), inventory))
+}
+
+func TestValidateImagePolicy(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: app\ncomponents:\n  - name: web\n    images:\n      - cgr.dev/chainguard/nginx:latest\n      - nginx:1.25\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validateImagePolicy(context.Background(), pkg, result))
+	assert.Empty(t, result.Findings)
+
+	v.ImagePolicy = &ImagePolicy{Approved: []string{"cgr.dev/chainguard/"}}
+	require.NoError(t, v.validateImagePolicy(context.Background(), pkg, result))
+	assert.Equal(t, []string{"Component 'web' uses an image that is not from an approved registry: nginx:1.25"}, result.Errors)
+}
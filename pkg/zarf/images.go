@@ -0,0 +1,158 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// ImageUser is a component of a package that uses an image
+type ImageUser struct {
+	Package   string `yaml:"package" json:"package"`
+	Component string `yaml:"component" json:"component"`
+}
+
+// ImageUsage is an image and the components using it
+type ImageUsage struct {
+	Image string      `yaml:"image" json:"image"`
+	Users []ImageUser `yaml:"users" json:"users"`
+}
+
+// ImageInventory lists the images of a set of packages, sorted by image
+type ImageInventory struct {
+	Images []ImageUsage `yaml:"images" json:"images"`
+}
+
+// ImageDiff is the difference between the image sets of two inventories
+type ImageDiff struct {
+	Added   []ImageUsage `yaml:"added" json:"added"`
+	Removed []ImageUsage `yaml:"removed" json:"removed"`
+}
+
+// BuildImageInventory lists the images of the components of the packages
+func BuildImageInventory(packageDirs []string) (*ImageInventory, error) {
+	inventory := newInventoryBuilder()
+	for _, dir := range packageDirs {
+		zarfYaml, err := util.ReadZarfYaml(filepath.Join(dir, "zarf.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package %s: %w", dir, err)
+		}
+		inventory.add(dir, zarfYaml)
+	}
+	return inventory.build(), nil
+}
+
+// FindZarfPackagesAt finds the packages in the directories as of a Git revision
+func FindZarfPackagesAt(ctx context.Context, rev string, dirs []string) ([]string, error) {
+	files, err := tool.NewGit("").ListFilesInDirs(ctx, rev, dirs...)
+	if err != nil {
+		return nil, err
+	}
+	var packageDirs []string
+	for _, file := range files {
+		if path.Base(file) == "zarf.yaml" {
+			packageDirs = append(packageDirs, filepath.FromSlash(path.Dir(file)))
+		}
+	}
+	return packageDirs, nil
+}
+
+// BuildImageInventoryAt lists the images of the packages as of a Git revision.
+// Packages that do not exist at the revision are skipped.
+func BuildImageInventoryAt(ctx context.Context, rev string, packageDirs []string) (*ImageInventory, error) {
+	git := tool.NewGit("")
+	inventory := newInventoryBuilder()
+	for _, dir := range packageDirs {
+		content, err := git.ShowFile(ctx, rev, filepath.Join(dir, "zarf.yaml"))
+		if errors.Is(err, tool.ErrFileNotFound) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+		zarfYaml, err := util.UnmarshalZarfYaml([]byte(content))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package %s at %s: %w", dir, rev, err)
+		}
+		inventory.add(dir, zarfYaml)
+	}
+	return inventory.build(), nil
+}
+
+// DiffImages returns the images of head that are not in base, and the images of base
+// that are not in head
+func DiffImages(base, head *ImageInventory) ImageDiff {
+	diff := ImageDiff{Added: []ImageUsage{}, Removed: []ImageUsage{}}
+	baseImages := map[string]bool{}
+	for _, usage := range base.Images {
+		baseImages[usage.Image] = true
+	}
+	headImages := map[string]bool{}
+	for _, usage := range head.Images {
+		headImages[usage.Image] = true
+		if !baseImages[usage.Image] {
+			diff.Added = append(diff.Added, usage)
+		}
+	}
+	for _, usage := range base.Images {
+		if !headImages[usage.Image] {
+			diff.Removed = append(diff.Removed, usage)
+		}
+	}
+	return diff
+}
+
+type inventoryBuilder map[string][]ImageUser
+
+func newInventoryBuilder() inventoryBuilder {
+	return inventoryBuilder{}
+}
+
+func (b inventoryBuilder) add(packageDir string, zarfYaml *util.ZarfYaml) {
+	for _, component := range zarfYaml.Components {
+		seen := map[string]bool{}
+		for _, image := range component.Images {
+			if seen[image] {
+				continue
+			}
+			seen[image] = true
+			b[image] = append(b[image], ImageUser{Package: packageDir, Component: component.Name})
+		}
+	}
+}
+
+func (b inventoryBuilder) build() *ImageInventory {
+	inventory := &ImageInventory{Images: []ImageUsage{}}
+	for image, users := range b {
+		sort.Slice(users, func(i, j int) bool {
+			if users[i].Package != users[j].Package {
+				return users[i].Package < users[j].Package
+			}
+			return users[i].Component < users[j].Component
+		})
+		inventory.Images = append(inventory.Images, ImageUsage{Image: image, Users: users})
+	}
+	sort.Slice(inventory.Images, func(i, j int) bool {
+		return inventory.Images[i].Image < inventory.Images[j].Image
+	})
+	return inventory
+}
@@ -0,0 +1,80 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImageInventory(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	writePackage := func(pkg, components string) {
+		dir := filepath.Join("packages", pkg)
+		require.NoError(t, os.MkdirAll(dir, 0755))
+		content := "kind: ZarfPackageConfig\nmetadata:\n  name: " + pkg + "\ncomponents:\n" + components
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "zarf.yaml"), []byte(content), 0644))
+	}
+
+	git("init", "-q")
+	writePackage("api", "  - name: api\n    images: [nginx:1.24, redis:7]\n")
+	writePackage("legacy", "  - name: legacy\n    images: [busybox:1.36]\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("tag", "v1.0.0")
+
+	require.NoError(t, os.RemoveAll(filepath.Join("packages", "legacy")))
+	writePackage("api", "  - name: api\n    images: [nginx:1.25, redis:7, redis:7]\n")
+	writePackage("web", "  - name: web\n    images: [nginx:1.25]\n  - name: cache\n    images: [redis:7]\n")
+
+	api, web := filepath.Join("packages", "api"), filepath.Join("packages", "web")
+	head, err := BuildImageInventory([]string{api, web})
+	require.NoError(t, err)
+	assert.Equal(t, []ImageUsage{
+		{Image: "nginx:1.25", Users: []ImageUser{{Package: api, Component: "api"}, {Package: web, Component: "web"}}},
+		{Image: "redis:7", Users: []ImageUser{{Package: api, Component: "api"}, {Package: web, Component: "cache"}}},
+	}, head.Images)
+
+	refDirs, err := FindZarfPackagesAt(context.Background(), "v1.0.0", []string{"packages"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{api, filepath.Join("packages", "legacy")}, refDirs)
+
+	// Packages that do not exist at the reference are skipped
+	base, err := BuildImageInventoryAt(context.Background(), "v1.0.0", append(refDirs, web))
+	require.NoError(t, err)
+	assert.Len(t, base.Images, 3)
+
+	diff := DiffImages(base, head)
+	assert.Equal(t, []ImageUsage{
+		{Image: "nginx:1.25", Users: []ImageUser{{Package: api, Component: "api"}, {Package: web, Component: "web"}}},
+	}, diff.Added)
+	assert.Equal(t, []ImageUsage{
+		{Image: "busybox:1.36", Users: []ImageUser{{Package: filepath.Join("packages", "legacy"), Component: "legacy"}}},
+		{Image: "nginx:1.24", Users: []ImageUser{{Package: api, Component: "api"}}},
+	}, diff.Removed)
+}
@@ -0,0 +1,177 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// resolveImports returns the directories of the local packages imported by the
+// components of the package at packagePath, directly or through the packages they
+// import, in the order they are first imported. Imports of paths without a zarf.yaml
+// are skipped. Import cycles are an error, returned along with the imports resolved
+// until then.
+func resolveImports(packagePath string) ([]string, error) {
+	var imports []string
+	seen := map[string]bool{}
+
+	var visit func(dir string, stack []string) error
+	visit = func(dir string, stack []string) error {
+		zarfYaml, err := util.ReadZarfYaml(filepath.Join(dir, "zarf.yaml"))
+		if err != nil {
+			return fmt.Errorf("failed to read zarf.yaml of %s: %w", dir, err)
+		}
+		for _, component := range zarfYaml.Components {
+			if component.Import.Path == "" || filepath.IsAbs(component.Import.Path) {
+				continue
+			}
+			importDir := filepath.Join(dir, component.Import.Path)
+			abs := absPath(importDir, ".")
+			for i, ancestor := range stack {
+				if absPath(ancestor, ".") == abs {
+					cycle := append(append([]string{}, stack[i:]...), importDir)
+					return fmt.Errorf("imports form a cycle: %s", strings.Join(cycle, " -> "))
+				}
+			}
+			if seen[abs] || !IsZarfPackage(importDir) {
+				continue
+			}
+			seen[abs] = true
+			imports = append(imports, importDir)
+			if err := visit(importDir, append(stack[:len(stack):len(stack)], importDir)); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	packagePath = filepath.Clean(packagePath)
+	err := visit(packagePath, []string{packagePath})
+	return imports, err
+}
+
+// skeletonDefinition reads the zarf.yaml of the skeleton package published at url, an
+// oci:// reference, with 'zarf package inspect definition'
+func skeletonDefinition(ctx context.Context, url string) (*util.ZarfYaml, error) {
+	output, err := exec.NewProcessExecutor(false).RunProcessAndCaptureStdout(ctx, "zarf", "package", "inspect",
+		"definition", url, "--architecture", "skeleton")
+	if err != nil {
+		return nil, err
+	}
+	return util.UnmarshalZarfYaml([]byte(output))
+}
+
+// hasComponent reports whether the package defines a component with the name
+func hasComponent(zarfYaml *util.ZarfYaml, name string) bool {
+	for _, component := range zarfYaml.Components {
+		if component.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// validateImports checks that every component import names either a path or an OCI
+// reference, that imported paths contain a package defining the imported component,
+// and that imports do not form a cycle. Skeletons imported by URL are checked when
+// ResolveOCIImports is set.
+func (v *PackageValidator) validateImports(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	for _, component := range pkg.ZarfYaml.Components {
+		imp := component.Import
+		if imp.Path == "" && imp.URL == "" {
+			if imp.Name != "" {
+				result.AddError("component-import",
+					fmt.Sprintf("Component '%s' import must set a path or url", component.Name))
+			}
+			continue
+		}
+		if imp.Path != "" && imp.URL != "" {
+			result.AddError("component-import",
+				fmt.Sprintf("Component '%s' import must set only one of path and url", component.Name))
+			continue
+		}
+		// Components are imported by their own name unless the import names another
+		name := imp.Name
+		if name == "" {
+			name = component.Name
+		}
+
+		if imp.URL != "" {
+			ref := strings.TrimPrefix(imp.URL, "oci://")
+			if ref == imp.URL {
+				result.AddError("component-import",
+					fmt.Sprintf("Component '%s' imports url '%s' which is not an oci:// reference", component.Name, imp.URL))
+				continue
+			}
+			if lastSegment := ref[strings.LastIndex(ref, "/")+1:]; !strings.ContainsAny(lastSegment, ":@") {
+				result.AddError("component-import",
+					fmt.Sprintf("Component '%s' imports url '%s' without a tag or digest", component.Name, imp.URL))
+				continue
+			}
+			if !v.ResolveOCIImports {
+				continue
+			}
+			skeleton, err := skeletonDefinition(ctx, imp.URL)
+			if err != nil {
+				result.AddWarning("component-import",
+					fmt.Sprintf("Component '%s' imports url '%s' which could not be resolved: %v", component.Name, imp.URL, err))
+			} else if !hasComponent(skeleton, name) {
+				result.AddError("component-import",
+					fmt.Sprintf("Component '%s' imports component '%s' which is not defined by '%s'", component.Name, name, imp.URL))
+			}
+			continue
+		}
+
+		if filepath.IsAbs(imp.Path) {
+			result.AddError("component-import",
+				fmt.Sprintf("Component '%s' imports absolute path '%s', imports must be relative to the package", component.Name, imp.Path))
+			continue
+		}
+		if v.DefinitionOnly {
+			// The imported package is not available
+			continue
+		}
+		importDir := filepath.Join(pkg.Path, imp.Path)
+		if !IsZarfPackage(importDir) {
+			result.AddError("component-import",
+				fmt.Sprintf("Component '%s' imports path '%s' which does not contain a zarf.yaml", component.Name, imp.Path))
+			continue
+		}
+		imported, err := util.ReadZarfYaml(filepath.Join(importDir, "zarf.yaml"))
+		if err != nil {
+			result.AddError("component-import",
+				fmt.Sprintf("Component '%s' imports path '%s' whose zarf.yaml is invalid: %v", component.Name, imp.Path, err))
+			continue
+		}
+		if !hasComponent(imported, name) {
+			result.AddError("component-import",
+				fmt.Sprintf("Component '%s' imports component '%s' which is not defined by '%s'", component.Name, name, imp.Path))
+		}
+	}
+
+	if v.DefinitionOnly {
+		return nil
+	}
+	if _, err := resolveImports(pkg.Path); err != nil {
+		result.AddError("component-import", fmt.Sprintf("Failed to resolve the imports of the package: %v", err))
+	}
+	return nil
+}
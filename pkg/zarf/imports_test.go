@@ -0,0 +1,139 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writePackage writes a zarf.yaml with the given components into dir
+func writePackage(t *testing.T, dir, components string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	content := "kind: ZarfPackageConfig\nmetadata:\n  name: " + filepath.Base(dir) + "\ncomponents:\n" + components
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "zarf.yaml"), []byte(content), 0644))
+}
+
+func TestResolveImports(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "packages", "app")
+	writePackage(t, app, "  - name: web\n    import:\n      path: ../../common/web\n  - name: db\n    import:\n      path: ../../common/db\n")
+	writePackage(t, filepath.Join(root, "common", "web"), "  - name: web\n    import:\n      path: ../base\n      name: base\n")
+	writePackage(t, filepath.Join(root, "common", "db"), "  - name: db\n    import:\n      path: ../base\n      name: base\n")
+	writePackage(t, filepath.Join(root, "common", "base"), "  - name: base\n")
+
+	imports, err := resolveImports(app)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "common", "web"),
+		filepath.Join(root, "common", "base"),
+		filepath.Join(root, "common", "db"),
+	}, imports)
+
+	// base imports web, which imports base
+	writePackage(t, filepath.Join(root, "common", "base"), "  - name: base\n    import:\n      path: ../web\n      name: web\n")
+	_, err = resolveImports(app)
+	assert.EqualError(t, err, "imports form a cycle: "+filepath.Join(root, "common", "web")+" -> "+
+		filepath.Join(root, "common", "base")+" -> "+filepath.Join(root, "common", "web"))
+}
+
+func TestValidateImports(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	writePackage(t, app, `  - name: web
+    import:
+      path: ../common
+  - name: db
+    import:
+      path: ../common
+      name: postgres
+  - name: cache
+    import:
+      path: ../missing
+  - name: both
+    import:
+      path: ../common
+      url: oci://ghcr.io/example/common:1.0.0
+  - name: unnamed
+    import:
+      name: web
+  - name: remote
+    import:
+      url: https://example.com/common
+  - name: untagged
+    import:
+      url: oci://ghcr.io/example/common
+  - name: skeleton
+    import:
+      url: oci://ghcr.io/example/common:1.0.0
+  - name: self
+    import:
+      path: .
+      name: web
+`)
+	writePackage(t, filepath.Join(root, "common"), "  - name: web\n")
+	pkg, err := LoadPackageContext(app)
+	require.NoError(t, err)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{PackagePath: app, Valid: true}
+	require.NoError(t, v.validateImports(context.Background(), pkg, result))
+
+	var messages []string
+	for _, finding := range result.Findings {
+		messages = append(messages, string(finding.Severity)+": "+finding.Message)
+	}
+	assert.Equal(t, []string{
+		"error: Component 'db' imports component 'postgres' which is not defined by '../common'",
+		"error: Component 'cache' imports path '../missing' which does not contain a zarf.yaml",
+		"error: Component 'both' import must set only one of path and url",
+		"error: Component 'unnamed' import must set a path or url",
+		"error: Component 'remote' imports url 'https://example.com/common' which is not an oci:// reference",
+		"error: Component 'untagged' imports url 'oci://ghcr.io/example/common' without a tag or digest",
+		"error: Failed to resolve the imports of the package: imports form a cycle: " + app + " -> " + app,
+	}, messages)
+}
+
+func TestValidateOCIImports(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		"zarf": "echo 'kind: ZarfPackageConfig'; echo 'components:'; echo '  - name: web'",
+	})
+	app := filepath.Join(t.TempDir(), "app")
+	writePackage(t, app, `  - name: web
+    import:
+      url: oci://ghcr.io/example/common:1.0.0
+  - name: db
+    import:
+      url: oci://ghcr.io/example/common:1.0.0
+`)
+	pkg, err := LoadPackageContext(app)
+	require.NoError(t, err)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{PackagePath: app, Valid: true}
+	require.NoError(t, v.validateImports(context.Background(), pkg, result))
+	assert.Empty(t, result.Findings)
+
+	v.ResolveOCIImports = true
+	require.NoError(t, v.validateImports(context.Background(), pkg, result))
+	require.Len(t, result.Findings, 1)
+	assert.Equal(t, "Component 'db' imports component 'db' which is not defined by 'oci://ghcr.io/example/common:1.0.0'", result.Findings[0].Message)
+}
@@ -0,0 +1,158 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+)
+
+// ruleInputs are the files of a package a rule depends on. A change of zarf.yaml
+// affects every rule.
+type ruleInputs int
+
+const (
+	zarfYamlOnly ruleInputs = iota // Only zarf.yaml
+	yamlFiles                      // zarf.yaml and the other YAML files, e.g. manifests
+	anyFile                        // Any file of the package
+	ownership                      // zarf.yaml and the CODEOWNERS file of the repository
+)
+
+// affected reports whether a rule with the inputs must run for the changed files,
+// given relative to the package directory
+func (i ruleInputs) affected(changed []string) bool {
+	for _, file := range changed {
+		switch {
+		case file == "zarf.yaml", i == anyFile:
+			return true
+		case i == yamlFiles:
+			if ext := path.Ext(file); ext == ".yaml" || ext == ".yml" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// packageChanges are the files changed compared to the change base, loaded once
+// per validator
+type packageChanges struct {
+	files []string
+	err   error
+}
+
+// changedPackageFiles returns the files of the package changed since the change
+// base, relative to the package directory. Files outside of the package count when
+// a component refers to them, e.g. ../common/values.yaml, and a changed zarf.yaml of
+// an imported package counts as a change of zarf.yaml. The package path may be
+// absolute or relative to the working directory, packages outside of the repository
+// are an error.
+func (v *PackageValidator) changedPackageFiles(ctx context.Context, pkg *PackageContext) ([]string, error) {
+	git := tool.NewGit("")
+	if v.changes == nil {
+		v.changes = &packageChanges{}
+		base, err := changeBase(ctx, git, v.Remote, v.TargetBranch, v.Since)
+		if err == nil {
+			v.changes.files, err = git.ListChangedFilesInDirs(ctx, base)
+		}
+		v.changes.err = err
+	}
+	if v.changes.err != nil {
+		return nil, v.changes.err
+	}
+
+	repoPath, err := git.RepoPath(ctx, pkg.Path)
+	if err != nil {
+		return nil, err
+	}
+	prefix := repoPath + "/"
+	if repoPath == "." {
+		prefix = ""
+	}
+	var changed []string
+	for _, file := range v.changes.files {
+		if strings.HasPrefix(file, prefix) {
+			changed = append(changed, strings.TrimPrefix(file, prefix))
+		} else if reference, ok := externalReference(file, repoPath, localReferences(pkg.ZarfYaml)); ok {
+			changed = append(changed, reference)
+		}
+	}
+	for _, component := range pkg.ZarfYaml.Components {
+		imported := component.Import.Path
+		if imported != "" && !isRemoteReference(imported) && contains(v.changes.files, path.Join(repoPath, filepath.ToSlash(imported), "zarf.yaml")) {
+			changed = append(changed, "zarf.yaml")
+		}
+	}
+	return changed, nil
+}
+
+// externalReference returns file, relative to the repository, relative to the package
+// at repoPath if it is one of the references outside of the package or within one
+func externalReference(file, repoPath string, references []string) (string, bool) {
+	for _, reference := range references {
+		reference = path.Clean(filepath.ToSlash(reference))
+		if reference != ".." && !strings.HasPrefix(reference, "../") {
+			continue
+		}
+		target := path.Join(repoPath, reference)
+		switch {
+		case target == ".":
+			return path.Join(reference, file), true
+		case file == target || strings.HasPrefix(file, target+"/"):
+			return reference + strings.TrimPrefix(file, target), true
+		}
+	}
+	return "", false
+}
+
+// codeOwnersChanged reports whether the CODEOWNERS file of the repository changed
+// since the change base
+func (v *PackageValidator) codeOwnersChanged() bool {
+	for _, file := range v.changes.files {
+		if contains(codeOwnersFiles, file) {
+			return true
+		}
+	}
+	return false
+}
+
+// affectedRules returns the rules depending on the files of the package that changed.
+// All rules are returned if the changed files cannot be determined.
+func (v *PackageValidator) affectedRules(ctx context.Context, pkg *PackageContext, rules []packageRule, result *ValidationResult) []packageRule {
+	changed, err := v.changedPackageFiles(ctx, pkg)
+	if err != nil {
+		result.AddWarning("incremental", fmt.Sprintf("Running all rules, the changed files could not be determined: %v", err))
+		return rules
+	}
+
+	var affected []packageRule
+	var skipped []string
+	for _, rule := range rules {
+		if rule.inputs.affected(changed) || rule.inputs == ownership && v.codeOwnersChanged() {
+			affected = append(affected, rule)
+		} else {
+			skipped = append(skipped, rule.name)
+		}
+	}
+	if len(skipped) > 0 {
+		result.AddInfo("incremental", fmt.Sprintf("Skipped rules unaffected by the changed files: %s", strings.Join(skipped, ", ")))
+	}
+	return affected
+}
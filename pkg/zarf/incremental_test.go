@@ -0,0 +1,151 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleInputsAffected(t *testing.T) {
+	assert.True(t, zarfYamlOnly.affected([]string{"zarf.yaml"}))
+	assert.False(t, zarfYamlOnly.affected([]string{"manifests/deployment.yaml"}))
+	assert.True(t, yamlFiles.affected([]string{"manifests/deployment.yaml"}))
+	assert.False(t, yamlFiles.affected([]string{"files/config.json"}))
+	assert.True(t, anyFile.affected([]string{"files/config.json"}))
+	assert.False(t, anyFile.affected(nil))
+}
+
+func TestIncrementalValidation(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	packageDir := filepath.Join("packages", "podinfo")
+	require.NoError(t, os.MkdirAll(filepath.Join(packageDir, "manifests"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "manifests", "deployment.yaml"), []byte("kind: Deployment\n"), 0644))
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("update-ref", "refs/remotes/origin/main", "HEAD")
+
+	// Only a manifest changed
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "manifests", "deployment.yaml"), []byte("kind: Deployment\nmetadata: {}\n"), 0644))
+
+	v := NewPackageValidator()
+	v.Incremental = true
+	result := &ValidationResult{PackagePath: packageDir, Valid: true}
+	rules := v.affectedRules(context.Background(), loadPackage(t, packageDir), v.rules(), result)
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.name)
+	}
+	assert.Contains(t, names, "manifest validation")
+	assert.Contains(t, names, "version increment validation")
+	assert.NotContains(t, names, "image pinning validation")
+	assert.NotContains(t, names, "maintainers validation")
+	require.Len(t, result.Findings, 1)
+	assert.Equal(t, "incremental", result.Findings[0].RuleID)
+	assert.Contains(t, result.Findings[0].Message, "image pinning validation")
+
+	// A change of CODEOWNERS affects the maintainers validation
+	require.NoError(t, os.WriteFile("CODEOWNERS", []byte("* @org/platform\n"), 0644))
+	git("add", "CODEOWNERS")
+	v.changes = nil
+	result = &ValidationResult{PackagePath: packageDir, Valid: true}
+	names = nil
+	for _, rule := range v.affectedRules(context.Background(), loadPackage(t, packageDir), v.rules(), result) {
+		names = append(names, rule.name)
+	}
+	assert.Contains(t, names, "maintainers validation")
+	assert.NotContains(t, names, "image pinning validation")
+	rules = append(rules, packageRule{name: "maintainers validation"})
+
+	// Packages are found from subdirectories and by absolute path
+	absolute, err := filepath.Abs(packageDir)
+	require.NoError(t, err)
+	t.Chdir("packages")
+	for _, path := range []string{"podinfo", absolute} {
+		result = &ValidationResult{PackagePath: path, Valid: true}
+		assert.Len(t, v.affectedRules(context.Background(), loadPackage(t, path), v.rules(), result), len(rules), path)
+	}
+
+	// Packages outside of the repository run all rules
+	outside := t.TempDir()
+	writePackage(t, outside, "")
+	result = &ValidationResult{PackagePath: outside, Valid: true}
+	assert.Len(t, v.affectedRules(context.Background(), loadPackage(t, outside), v.rules(), result), len(v.rules()))
+	assert.Contains(t, result.Findings[0].Message, "is outside of the repository")
+	t.Chdir(repo)
+
+	// Without the history all rules run
+	v = NewPackageValidator()
+	v.Since = "v9.9.9"
+	result = &ValidationResult{PackagePath: packageDir, Valid: true}
+	assert.Len(t, v.affectedRules(context.Background(), loadPackage(t, packageDir), v.rules(), result), len(v.rules()))
+	assert.Equal(t, SeverityWarning, result.Findings[0].Severity)
+}
+
+func TestIncrementalValidationExternalReferences(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	packageDir := filepath.Join("packages", "app")
+	writePackage(t, packageDir, "  - name: app\n    manifests:\n      - name: app\n        files:\n          - ../../common/manifests/app.yaml\n  - name: web\n    import:\n      path: ../../common/web\n")
+	writePackage(t, filepath.Join("common", "web"), "  - name: web\n")
+	require.NoError(t, os.MkdirAll(filepath.Join("common", "manifests"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("common", "manifests", "app.yaml"), []byte("kind: Deployment\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join("common", "unrelated.yaml"), []byte("kind: Deployment\n"), 0644))
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	git("update-ref", "refs/remotes/origin/main", "HEAD")
+
+	changed := func() []string {
+		v := NewPackageValidator()
+		v.Incremental = true
+		files, err := v.changedPackageFiles(context.Background(), loadPackage(t, packageDir))
+		require.NoError(t, err)
+		return files
+	}
+
+	// Files outside of the package the components do not refer to are no change
+	require.NoError(t, os.WriteFile(filepath.Join("common", "unrelated.yaml"), []byte("kind: Service\n"), 0644))
+	assert.Empty(t, changed())
+
+	// A referenced manifest is a change relative to the package
+	require.NoError(t, os.WriteFile(filepath.Join("common", "manifests", "app.yaml"), []byte("kind: Service\n"), 0644))
+	assert.Equal(t, []string{"../../common/manifests/app.yaml"}, changed())
+
+	// The definition of an imported package changes the components of the package
+	writePackage(t, filepath.Join("common", "web"), "  - name: web\n    required: true\n")
+	assert.ElementsMatch(t, []string{"../../common/manifests/app.yaml", "../../common/web/zarf.yaml", "zarf.yaml"}, changed())
+}
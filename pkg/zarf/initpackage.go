@@ -0,0 +1,160 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// Components every init package needs to bootstrap the registry of a cluster: the
+// injector seeds a temporary registry with the images of the seed registry component,
+// from which the registry component is then deployed
+const (
+	injectorComponent     = "zarf-injector"
+	seedRegistryComponent = "zarf-seed-registry"
+	registryComponent     = "zarf-registry"
+)
+
+// initComponents are the components required in init packages
+var initComponents = []string{injectorComponent, seedRegistryComponent, registryComponent}
+
+// validateInitPackage applies the rules of init packages, packages of kind
+// ZarfInitConfig: the injector, seed registry and registry components must be present
+// and required, the injector must ship an executable zarf-injector binary, checksummed
+// when it is downloaded, and the seed registry must list the images it seeds. Imported
+// components are only checked for presence.
+func (v *PackageValidator) validateInitPackage(pkg *PackageContext, result *ValidationResult) error {
+	zarfYaml := pkg.ZarfYaml
+	if !zarfYaml.IsInitPackage() {
+		return nil
+	}
+
+	components := map[string]util.ZarfComponent{}
+	for _, component := range zarfYaml.Components {
+		components[component.Name] = component
+	}
+	for _, name := range initComponents {
+		component, ok := components[name]
+		if !ok {
+			result.AddError("init-components", fmt.Sprintf("Init package is missing the '%s' component", name))
+		} else if !component.Required {
+			result.AddError("init-components", fmt.Sprintf("Component '%s' of an init package must be required", name))
+		}
+	}
+
+	if injector, ok := components[injectorComponent]; ok && !isImported(injector) {
+		validateInjector(injector, result)
+	}
+	seed, ok := components[seedRegistryComponent]
+	if !ok || isImported(seed) {
+		return nil
+	}
+	if len(seed.Images) == 0 {
+		result.AddError("seed-registry-images", fmt.Sprintf("Component '%s' has no images to seed the registry with", seedRegistryComponent))
+		return nil
+	}
+	if registry, ok := components[registryComponent]; ok && !isImported(registry) {
+		for _, image := range seed.Images {
+			if !util.StringSliceContains(registry.Images, image) {
+				result.AddWarning("seed-registry-images",
+					fmt.Sprintf("Component '%s' does not deploy the seed registry image %s", registryComponent, image))
+			}
+		}
+	}
+	return nil
+}
+
+// validateInjector checks that the injector component ships the zarf-injector binary
+func validateInjector(injector util.ZarfComponent, result *ValidationResult) {
+	for _, file := range injector.Files {
+		if filepath.Base(file.Target) != injectorComponent {
+			continue
+		}
+		if !file.Executable {
+			result.AddError("injector-binary", fmt.Sprintf("Injector binary %s must be executable", file.Target))
+		}
+		if isRemoteSource(file.Source) && file.Shasum == "" {
+			result.AddError("injector-checksum", fmt.Sprintf("Injector binary downloaded from %s has no shasum", file.Source))
+		}
+		return
+	}
+	result.AddError("injector-binary", fmt.Sprintf("Component '%s' has no file with the target zarf-injector", injectorComponent))
+}
+
+// isImported reports whether the component is imported from another package, whose
+// definition is not checked
+func isImported(component util.ZarfComponent) bool {
+	return component.Import.Path != "" || component.Import.URL != ""
+}
+
+// initPackageDir builds the init package at packagePath into a directory 'zarf init'
+// picks it up from, where it is named after the architecture it was built for and the
+// version of zarf, as zarf expects. The returned function removes the directory.
+func (d *PackageDeployer) initPackageDir(ctx context.Context, packagePath string) (string, func(), error) {
+	zarfPackage, err := LoadZarfPackage(packagePath)
+	if err != nil {
+		return "", nil, err
+	}
+	if !zarfPackage.Metadata.IsInitPackage() {
+		return "", nil, fmt.Errorf("%s is not an init package, its kind is not %s", packagePath, util.ZarfInitKind)
+	}
+	output, err := d.executor().RunProcessAndCaptureOutput(ctx, "zarf", "version")
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to get the zarf version: %w", err)
+	}
+	zarfVersion, err := ParseZarfVersion(output)
+	if err != nil {
+		return "", nil, err
+	}
+
+	tarball, cleanupBuild, err := d.buildPackage(ctx, "zarf-init", packagePath, zarfVersion, zarfPackage.Metadata)
+	if err != nil {
+		return "", nil, err
+	}
+	dir, err := os.MkdirTemp("", "zt-init-")
+	if err != nil {
+		cleanupBuild()
+		return "", nil, fmt.Errorf("failed to create init package directory: %w", err)
+	}
+	cleanup := func() {
+		os.RemoveAll(dir)
+		cleanupBuild()
+	}
+	name := fmt.Sprintf("zarf-init-%s-%s.tar.zst", tarballArchitecture(tarball), zarfVersion)
+	if err := os.Symlink(tarball, filepath.Join(dir, name)); err != nil {
+		cleanup()
+		return "", nil, fmt.Errorf("failed to link init package: %w", err)
+	}
+	return dir, cleanup, nil
+}
+
+// tarballArchitecture returns the architecture of a package from the name of its
+// tarball, zarf-init-<arch>-<version>.tar.zst or zarf-package-<name>-<arch>-...,
+// falling back to the architecture zt runs on
+func tarballArchitecture(tarball string) string {
+	for _, arch := range []string{"amd64", "arm64"} {
+		if strings.Contains(filepath.Base(tarball), "-"+arch) {
+			return arch
+		}
+	}
+	return runtime.GOARCH
+}
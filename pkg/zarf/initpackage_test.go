@@ -0,0 +1,148 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateInitPackage(t *testing.T) {
+	injector := util.ZarfComponent{Name: "zarf-injector", Required: true, Files: []util.ZarfFile{
+		{Source: "https://example.test/zarf-injector", Target: "###ZARF_TEMP###/zarf-injector", Shasum: "abc123", Executable: true},
+	}}
+	seed := util.ZarfComponent{Name: "zarf-seed-registry", Required: true, Images: []string{"ghcr.io/zarf-dev/zarf/agent:v0.40.0"}}
+	registry := util.ZarfComponent{Name: "zarf-registry", Required: true, Images: []string{"ghcr.io/zarf-dev/zarf/agent:v0.40.0"}}
+
+	tests := []struct {
+		name       string
+		kind       string
+		components []util.ZarfComponent
+		findings   []Finding
+	}{
+		{
+			name:       "valid init package",
+			kind:       util.ZarfInitKind,
+			components: []util.ZarfComponent{injector, seed, registry},
+		},
+		{
+			name:       "regular package",
+			kind:       util.ZarfPackageKind,
+			components: []util.ZarfComponent{{Name: "web"}},
+		},
+		{
+			name: "imported components are only checked for presence",
+			kind: util.ZarfInitKind,
+			components: []util.ZarfComponent{
+				{Name: "zarf-injector", Required: true, Import: util.ZarfComponentImport{Path: "packages/zarf-registry"}},
+				{Name: "zarf-seed-registry", Required: true, Import: util.ZarfComponentImport{Path: "packages/zarf-registry"}},
+			},
+			findings: []Finding{
+				{RuleID: "init-components", Severity: SeverityError, Message: "Init package is missing the 'zarf-registry' component"},
+			},
+		},
+		{
+			name: "broken injector and seed registry",
+			kind: util.ZarfInitKind,
+			components: []util.ZarfComponent{
+				{Name: "zarf-injector", Files: []util.ZarfFile{{Source: "https://example.test/zarf-injector", Target: "/tmp/zarf-injector"}}},
+				{Name: "zarf-seed-registry", Required: true},
+				registry,
+			},
+			findings: []Finding{
+				{RuleID: "init-components", Severity: SeverityError, Message: "Component 'zarf-injector' of an init package must be required"},
+				{RuleID: "injector-binary", Severity: SeverityError, Message: "Injector binary /tmp/zarf-injector must be executable"},
+				{RuleID: "injector-checksum", Severity: SeverityError, Message: "Injector binary downloaded from https://example.test/zarf-injector has no shasum"},
+				{RuleID: "seed-registry-images", Severity: SeverityError, Message: "Component 'zarf-seed-registry' has no images to seed the registry with"},
+			},
+		},
+		{
+			name: "registry without the seed image",
+			kind: util.ZarfInitKind,
+			components: []util.ZarfComponent{
+				{Name: "zarf-injector", Required: true},
+				seed,
+				{Name: "zarf-registry", Required: true, Images: []string{"registry:2"}},
+			},
+			findings: []Finding{
+				{RuleID: "injector-binary", Severity: SeverityError, Message: "Component 'zarf-injector' has no file with the target zarf-injector"},
+				{RuleID: "seed-registry-images", Severity: SeverityWarning, Message: "Component 'zarf-registry' does not deploy the seed registry image ghcr.io/zarf-dev/zarf/agent:v0.40.0"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			zarfYaml := &util.ZarfYaml{Kind: tt.kind, Components: tt.components}
+			result := &ValidationResult{}
+			require.NoError(t, NewPackageValidator().validateInitPackage(&PackageContext{ZarfYaml: zarfYaml}, result))
+			assert.Equal(t, tt.findings, result.Findings)
+		})
+	}
+}
+
+func TestEnsureZarfInitializedInitPackage(t *testing.T) {
+	// 'zarf init' records the init packages in the directory it runs in
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `case "$1 $2" in
+"version "*) echo v0.40.0 ;;
+"package create") touch "$6/zarf-init-arm64-1.0.0.tar.zst" ;;
+"init "*) echo "zarf $* in $PWD: $(ls)" >> "$ZT_TEST_CALLS" ;;
+esac`,
+		"kubectl": "echo",
+	})
+
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfInitConfig\nmetadata:\n  name: init\n  version: 1.0.0\ncomponents:\n  - name: zarf-injector\n    required: true\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	d := NewPackageDeployer()
+	d.InitPackage = packageDir
+	initialized, err := d.EnsureZarfInitialized(context.Background(), nil, nil)
+	require.NoError(t, err)
+	assert.True(t, initialized)
+
+	// The init package is named after the zarf version and removed afterwards
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	match := regexp.MustCompile(`^zarf init --confirm in (\S+): zarf-init-arm64-v0\.40\.0\.tar\.zst\n$`).FindStringSubmatch(string(content))
+	require.NotNil(t, match, string(content))
+	assert.NoDirExists(t, match[1])
+
+	// Regular packages cannot initialize clusters
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\n"), 0644))
+	_, err = d.EnsureZarfInitialized(context.Background(), nil, nil)
+	assert.EqualError(t, err, "failed to build init package "+packageDir+": "+packageDir+" is not an init package, its kind is not ZarfInitConfig")
+}
+
+func TestDeployPackageSkipsInitPackages(t *testing.T) {
+	packageDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfInitConfig\nmetadata:\n  name: init\n"), 0644))
+
+	results, err := NewPackageDeployer().DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].Success)
+	assert.Equal(t, []string{"Skipped deploying init package, init packages are deployed by zarf init with --init-package"}, results[0].Warnings)
+}
@@ -0,0 +1,275 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// PackageModel is the normalized view of a package that zt works with: the parsed
+// zarf.yaml with defaults applied, plus the test configuration found next to it
+type PackageModel struct {
+	Path         string            `yaml:"path" json:"path"`
+	Name         string            `yaml:"name" json:"name"`
+	Description  string            `yaml:"description,omitempty" json:"description,omitempty"`
+	Version      string            `yaml:"version,omitempty" json:"version,omitempty"`
+	Architecture string            `yaml:"architecture,omitempty" json:"architecture,omitempty"`
+	Deprecated   bool              `yaml:"deprecated" json:"deprecated"`
+	Annotations  map[string]string `yaml:"annotations,omitempty" json:"annotations,omitempty"`
+	Variables    []VariableModel   `yaml:"variables" json:"variables"`
+	Constants    []ConstantModel   `yaml:"constants" json:"constants"`
+	Components   []ComponentModel  `yaml:"components" json:"components"`
+	// Images lists the images of all components, deduplicated and sorted
+	Images []string `yaml:"images" json:"images"`
+	// Imports lists the directories of the local packages imported by the components,
+	// directly or through other imports
+	Imports []string `yaml:"imports" json:"imports"`
+	// ZarfConfig is the name of the zarf config file of the package, if it has one
+	ZarfConfig string `yaml:"zarfConfig,omitempty" json:"zarfConfig,omitempty"`
+	// VariableSets lists the names of the variable sets in zt-values
+	VariableSets []string    `yaml:"variableSets" json:"variableSets"`
+	Assertions   *Assertions `yaml:"assertions,omitempty" json:"assertions,omitempty"`
+}
+
+// VariableModel is a package variable
+type VariableModel struct {
+	Name        string `yaml:"name" json:"name"`
+	Description string `yaml:"description,omitempty" json:"description,omitempty"`
+	Default     string `yaml:"default" json:"default"`
+	Prompt      bool   `yaml:"prompt" json:"prompt"`
+}
+
+// ConstantModel is a package constant
+type ConstantModel struct {
+	Name  string `yaml:"name" json:"name"`
+	Value string `yaml:"value" json:"value"`
+}
+
+// ComponentModel is a component of a package
+type ComponentModel struct {
+	Name        string   `yaml:"name" json:"name"`
+	Description string   `yaml:"description,omitempty" json:"description,omitempty"`
+	Required    bool     `yaml:"required" json:"required"`
+	Default     bool     `yaml:"default" json:"default"`
+	Group       string   `yaml:"group,omitempty" json:"group,omitempty"`
+	DependsOn   []string `yaml:"dependsOn" json:"dependsOn"`
+	Deprecated  bool     `yaml:"deprecated" json:"deprecated"`
+	Replacement string   `yaml:"replacement,omitempty" json:"replacement,omitempty"`
+	// Import is the source of an imported component, a path or URL
+	Import string `yaml:"import,omitempty" json:"import,omitempty"`
+	// OnlyLocalOS, OnlyArchitecture and OnlyDistros restrict where the component is deployed
+	OnlyLocalOS      string          `yaml:"onlyLocalOS,omitempty" json:"onlyLocalOS,omitempty"`
+	OnlyArchitecture string          `yaml:"onlyArchitecture,omitempty" json:"onlyArchitecture,omitempty"`
+	OnlyDistros      []string        `yaml:"onlyDistros,omitempty" json:"onlyDistros,omitempty"`
+	Images           []string        `yaml:"images" json:"images"`
+	Charts           []ChartModel    `yaml:"charts" json:"charts"`
+	Manifests        []ManifestModel `yaml:"manifests" json:"manifests"`
+	Files            []FileModel     `yaml:"files" json:"files"`
+	Repos            []string        `yaml:"repos" json:"repos"`
+}
+
+// ChartModel is a Helm chart of a component
+type ChartModel struct {
+	Name string `yaml:"name" json:"name"`
+	// Source is where the chart comes from: local, git, oci or repo
+	Source      string   `yaml:"source" json:"source"`
+	Location    string   `yaml:"location" json:"location"`
+	GitPath     string   `yaml:"gitPath,omitempty" json:"gitPath,omitempty"`
+	Version     string   `yaml:"version,omitempty" json:"version,omitempty"`
+	Namespace   string   `yaml:"namespace" json:"namespace"`
+	ReleaseName string   `yaml:"releaseName" json:"releaseName"`
+	Wait        bool     `yaml:"wait" json:"wait"`
+	ValuesFiles []string `yaml:"valuesFiles" json:"valuesFiles"`
+}
+
+// ManifestModel is a set of manifests of a component
+type ManifestModel struct {
+	Name           string   `yaml:"name" json:"name"`
+	Namespace      string   `yaml:"namespace" json:"namespace"`
+	Files          []string `yaml:"files" json:"files"`
+	Kustomizations []string `yaml:"kustomizations" json:"kustomizations"`
+}
+
+// FileModel is a file copied to the host by a component
+type FileModel struct {
+	Source     string `yaml:"source" json:"source"`
+	Target     string `yaml:"target" json:"target"`
+	Remote     bool   `yaml:"remote" json:"remote"`
+	Shasum     string `yaml:"shasum,omitempty" json:"shasum,omitempty"`
+	Executable bool   `yaml:"executable" json:"executable"`
+}
+
+// InspectPackage loads the package in packagePath and returns its normalized model.
+// Defaults are made explicit: the name falls back to the directory name, release
+// names to the chart name, and lists are empty rather than missing.
+func InspectPackage(packagePath string) (*PackageModel, error) {
+	pkg, err := LoadZarfPackage(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	zarfYaml := pkg.Metadata
+
+	model := &PackageModel{
+		Path:         packagePath,
+		Name:         pkg.Name,
+		Description:  zarfYaml.Metadata.Description,
+		Version:      zarfYaml.Metadata.Version,
+		Architecture: zarfYaml.Metadata.Architecture,
+		Deprecated:   zarfYaml.Metadata.Deprecated,
+		Annotations:  zarfYaml.Metadata.Annotations,
+		Variables:    []VariableModel{},
+		Constants:    []ConstantModel{},
+		Components:   []ComponentModel{},
+		Imports:      append([]string{}, pkg.Imports...),
+		VariableSets: []string{},
+	}
+
+	if pkg.ZarfConfig != "" {
+		model.ZarfConfig = filepath.Base(pkg.ZarfConfig)
+	}
+
+	for _, variable := range zarfYaml.Variables {
+		model.Variables = append(model.Variables, VariableModel{
+			Name:        variable.Name,
+			Description: variable.Description,
+			Default:     variable.Default,
+			Prompt:      variable.Prompt,
+		})
+	}
+
+	for _, constant := range zarfYaml.Constants {
+		model.Constants = append(model.Constants, ConstantModel{Name: constant.Name, Value: constant.Value})
+	}
+
+	images := map[string]bool{}
+	for _, component := range zarfYaml.Components {
+		componentModel := inspectComponent(component)
+		for _, image := range componentModel.Images {
+			images[image] = true
+		}
+		model.Components = append(model.Components, componentModel)
+	}
+	model.Images = sortedKeys(images)
+
+	sets, err := LoadVariableSets(packagePath, nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, set := range sets {
+		model.VariableSets = append(model.VariableSets, set.Name)
+	}
+
+	model.Assertions, err = LoadAssertions(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	return model, nil
+}
+
+func inspectComponent(component util.ZarfComponent) ComponentModel {
+	model := ComponentModel{
+		Name:             component.Name,
+		Description:      component.Description,
+		Required:         component.Required,
+		Default:          component.Default,
+		Group:            component.Group,
+		DependsOn:        append([]string{}, component.DepsWith...),
+		Deprecated:       component.Zt.Deprecated,
+		Replacement:      component.Zt.Replacement,
+		OnlyLocalOS:      component.Only.LocalOS,
+		OnlyArchitecture: component.Only.Cluster.Architecture,
+		OnlyDistros:      component.Only.Cluster.Distros,
+		Images:           append([]string{}, component.Images...),
+		Charts:           []ChartModel{},
+		Manifests:        []ManifestModel{},
+		Files:            []FileModel{},
+		Repos:            append([]string{}, component.Repos...),
+	}
+	if component.Import.URL != "" {
+		model.Import = component.Import.URL
+	} else if component.Import.Path != "" {
+		model.Import = component.Import.Path
+	}
+
+	for _, chart := range component.Charts {
+		chartModel := ChartModel{
+			Name:        chart.Name,
+			Version:     chart.Version,
+			Namespace:   chart.Namespace,
+			ReleaseName: chart.ReleaseName,
+			Wait:        !chart.NoWait,
+			ValuesFiles: append([]string{}, chart.ValuesFiles...),
+		}
+		if chartModel.ReleaseName == "" {
+			chartModel.ReleaseName = chart.Name
+		}
+		chartModel.Source, chartModel.Location = chartSource(chart)
+		if chartModel.Source == "git" {
+			chartModel.GitPath = chart.GitPath
+		}
+		model.Charts = append(model.Charts, chartModel)
+	}
+
+	for _, manifest := range component.Manifests {
+		model.Manifests = append(model.Manifests, ManifestModel{
+			Name:           manifest.Name,
+			Namespace:      manifest.Namespace,
+			Files:          append([]string{}, manifest.Files...),
+			Kustomizations: append([]string{}, manifest.Kustomizations...),
+		})
+	}
+
+	for _, file := range component.Files {
+		model.Files = append(model.Files, FileModel{
+			Source:     file.Source,
+			Target:     file.Target,
+			Remote:     isRemoteSource(file.Source),
+			Shasum:     file.Shasum,
+			Executable: file.Executable,
+		})
+	}
+	return model
+}
+
+// chartSource returns the kind of source of a chart and its location
+func chartSource(chart util.ZarfChart) (string, string) {
+	switch {
+	case chart.LocalPath != "":
+		return "local", chart.LocalPath
+	case strings.HasPrefix(chart.Url, "oci://"):
+		return "oci", chart.Url
+	case chart.GitPath != "" || strings.HasSuffix(chart.Url, ".git") || strings.Contains(chart.Url, ".git@"):
+		return "git", chart.Url
+	default:
+		return "repo", chart.Url
+	}
+}
+
+func isRemoteSource(source string) bool {
+	return strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://")
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
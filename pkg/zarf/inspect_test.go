@@ -0,0 +1,90 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInspectPackage(t *testing.T) {
+	packageDir := filepath.Join(t.TempDir(), "podinfo")
+	require.NoError(t, os.MkdirAll(filepath.Join(packageDir, VariableSetsDir), 0755))
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  version: 1.0.0
+variables:
+  - name: REPLICAS
+    default: "2"
+components:
+  - name: web
+    required: true
+    images:
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+      - redis:7.2
+    charts:
+      - name: podinfo
+        url: oci://ghcr.io/stefanprodan/charts/podinfo
+        version: 6.4.0
+        namespace: podinfo
+      - name: local
+        localPath: chart
+        releaseName: local-release
+        noWait: true
+    files:
+      - source: https://example.com/tool
+        target: /usr/local/bin/tool
+  - name: cache
+    depsWith: [web]
+    import:
+      path: ../redis
+    images:
+      - redis:7.2
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, VariableSetsDir, "ha.yaml"), []byte("REPLICAS: 3\n"), 0644))
+
+	model, err := InspectPackage(packageDir)
+	require.NoError(t, err)
+
+	assert.Equal(t, "podinfo", model.Name)
+	assert.Equal(t, "1.0.0", model.Version)
+	assert.Equal(t, []VariableModel{{Name: "REPLICAS", Default: "2"}}, model.Variables)
+	assert.Empty(t, model.Constants)
+	assert.Equal(t, []string{"ghcr.io/stefanprodan/podinfo:6.4.0", "redis:7.2"}, model.Images)
+	assert.Equal(t, []string{"ha"}, model.VariableSets)
+	assert.Nil(t, model.Assertions)
+
+	require.Len(t, model.Components, 2)
+	web := model.Components[0]
+	assert.True(t, web.Required)
+	assert.Equal(t, []ChartModel{
+		{Name: "podinfo", Source: "oci", Location: "oci://ghcr.io/stefanprodan/charts/podinfo", Version: "6.4.0", Namespace: "podinfo", ReleaseName: "podinfo", Wait: true, ValuesFiles: []string{}},
+		{Name: "local", Source: "local", Location: "chart", ReleaseName: "local-release", Wait: false, ValuesFiles: []string{}},
+	}, web.Charts)
+	assert.Equal(t, []FileModel{{Source: "https://example.com/tool", Target: "/usr/local/bin/tool", Remote: true}}, web.Files)
+	assert.Empty(t, web.Manifests)
+
+	cache := model.Components[1]
+	assert.Equal(t, []string{"web"}, cache.DependsOn)
+	assert.Equal(t, "../redis", cache.Import)
+
+	_, err = InspectPackage(t.TempDir())
+	assert.Error(t, err)
+}
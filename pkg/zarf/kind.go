@@ -0,0 +1,89 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/tracing"
+)
+
+// kindNodeImages are the kind node images of recent Kubernetes minor versions, so that
+// versions can be given without a patch release
+var kindNodeImages = map[string]string{
+	"1.25": "kindest/node:v1.25.16",
+	"1.26": "kindest/node:v1.26.15",
+	"1.27": "kindest/node:v1.27.16",
+	"1.28": "kindest/node:v1.28.13",
+	"1.29": "kindest/node:v1.29.8",
+	"1.30": "kindest/node:v1.30.4",
+	"1.31": "kindest/node:v1.31.0",
+}
+
+var (
+	patchVersionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+$`)
+	kindNameChars       = regexp.MustCompile(`[^a-z0-9-]+`)
+)
+
+// KindNodeImage returns the kind node image for a Kubernetes version. Versions are
+// either a minor version known to zt (1.29), a patch release (1.29.2) or a node image.
+func KindNodeImage(version string) (string, error) {
+	if strings.ContainsAny(version, ":/") {
+		return version, nil
+	}
+	version = strings.TrimPrefix(version, "v")
+	if image, ok := kindNodeImages[version]; ok {
+		return image, nil
+	}
+	if patchVersionPattern.MatchString(version) {
+		return "kindest/node:v" + version, nil
+	}
+	return "", fmt.Errorf("no kind node image known for Kubernetes %q, specify a patch release such as 1.29.8 or a node image", version)
+}
+
+// kindClusterName returns the name of the kind cluster created for cluster name
+func kindClusterName(name string) string {
+	return "zt-" + strings.Trim(kindNameChars.ReplaceAllString(strings.ToLower(name), "-"), "-")
+}
+
+// CreateKindCluster creates a kind cluster running image and writes its kubeconfig to
+// the kubeconfig file
+func CreateKindCluster(ctx context.Context, name string, image string, kubeconfig string) error {
+	ctx, span := tracing.Start(ctx, "cluster.create", "cluster", name, "image", image)
+	executor := exec.NewProcessExecutor(false)
+	_, err := executor.RunProcessAndCaptureOutput(ctx, "kind", "create", "cluster",
+		"--name", name, "--image", image, "--kubeconfig", kubeconfig, "--wait", "5m")
+	span.End(err)
+	if err != nil {
+		return fmt.Errorf("failed to create kind cluster %q: %w", name, err)
+	}
+	return nil
+}
+
+// DeleteKindCluster deletes the kind cluster name
+func DeleteKindCluster(ctx context.Context, name string) error {
+	ctx, span := tracing.Start(ctx, "cluster.delete", "cluster", name)
+	executor := exec.NewProcessExecutor(false)
+	_, err := executor.RunProcessAndCaptureOutput(ctx, "kind", "delete", "cluster", "--name", name)
+	span.End(err)
+	if err != nil {
+		return fmt.Errorf("failed to delete kind cluster %q: %w", name, err)
+	}
+	return nil
+}
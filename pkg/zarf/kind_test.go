@@ -0,0 +1,44 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKindNodeImage(t *testing.T) {
+	tests := []struct {
+		version string
+		image   string
+	}{
+		{version: "1.29", image: "kindest/node:v1.29.8"},
+		{version: "v1.31", image: "kindest/node:v1.31.0"},
+		{version: "1.30.2", image: "kindest/node:v1.30.2"},
+		{version: "registry.example.com/node:v1.32.0", image: "registry.example.com/node:v1.32.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			image, err := KindNodeImage(tt.version)
+			require.NoError(t, err)
+			assert.Equal(t, tt.image, image)
+		})
+	}
+
+	_, err := KindNodeImage("1.12")
+	assert.EqualError(t, err, `no kind node image known for Kubernetes "1.12", specify a patch release such as 1.29.8 or a node image`)
+}
@@ -0,0 +1,328 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// ChartLicenseAnnotation is the Chart.yaml annotation naming the license of a chart
+const ChartLicenseAnnotation = "artifacthub.io/license"
+
+// UnknownLicense is reported for artifacts whose SBOM entry has no license
+const UnknownLicense = "UNKNOWN"
+
+// LicensePolicy allows and forbids licenses by SPDX identifier. Entries are
+// case-insensitive glob patterns such as 'AGPL-*'.
+type LicensePolicy struct {
+	// Allowed licenses, any license is allowed if empty
+	Allowed []string
+	// Denied licenses, taking precedence over Allowed
+	Denied []string
+}
+
+// Check reports whether the SPDX license expression complies with the policy and why
+// it does not. One alternative of an OR expression must comply, every license of an
+// AND expression. Unknown licenses comply.
+func (p LicensePolicy) Check(expression string) (bool, string) {
+	if expression == UnknownLicense {
+		return true, ""
+	}
+	var reasons []string
+	for _, alternative := range strings.Split(strings.NewReplacer("(", "", ")", "").Replace(expression), " OR ") {
+		reason := ""
+		for _, license := range strings.Split(alternative, " AND ") {
+			license, _, _ = strings.Cut(strings.TrimSpace(license), " WITH ")
+			switch {
+			case matchLicense(p.Denied, license):
+				reason = fmt.Sprintf("%s is denied", license)
+			case len(p.Allowed) > 0 && !matchLicense(p.Allowed, license):
+				reason = fmt.Sprintf("%s is not allowed", license)
+			default:
+				continue
+			}
+			break
+		}
+		if reason == "" {
+			return true, ""
+		}
+		reasons = append(reasons, reason)
+	}
+	return false, strings.Join(reasons, ", ")
+}
+
+// matchLicense reports whether license matches one of the patterns
+func matchLicense(patterns []string, license string) bool {
+	for _, pattern := range patterns {
+		if matched, _ := path.Match(strings.ToLower(pattern), strings.ToLower(license)); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// LicenseUsage is a license found in a package and where it was found
+type LicenseUsage struct {
+	License   string   `yaml:"license" json:"license"`
+	Artifacts int      `yaml:"artifacts" json:"artifacts"`
+	Sources   []string `yaml:"sources" json:"sources"`
+	Forbidden bool     `yaml:"forbidden,omitempty" json:"forbidden,omitempty"`
+	Reason    string   `yaml:"reason,omitempty" json:"reason,omitempty"`
+}
+
+// PackageLicenses are the licenses of the images and charts of a package, sorted by
+// license
+type PackageLicenses struct {
+	Package  string         `yaml:"package" json:"package"`
+	Licenses []LicenseUsage `yaml:"licenses" json:"licenses"`
+}
+
+// Forbidden returns the licenses of the package violating the policy
+func (p PackageLicenses) Forbidden() []LicenseUsage {
+	var forbidden []LicenseUsage
+	for _, usage := range p.Licenses {
+		if usage.Forbidden {
+			forbidden = append(forbidden, usage)
+		}
+	}
+	return forbidden
+}
+
+// sbomArtifact is a package found by an SBOM generator, e.g. an OS or language package
+type sbomArtifact struct {
+	Licenses []string
+}
+
+// LicenseScanner collects the licenses of the images and local charts of packages
+type LicenseScanner struct {
+	// SBOMDir holds SBOMs extracted with 'zarf package inspect sbom', read from
+	// SBOMDir/<package name>/*.json. Without it, SBOMs of images are generated with
+	// syft.
+	SBOMDir string
+	Policy  LicensePolicy
+}
+
+// Scan aggregates the licenses of the images and local charts of the package at
+// packagePath. Charts are licensed by their 'artifacthub.io/license' annotation.
+func (s LicenseScanner) Scan(ctx context.Context, packagePath string) (*PackageLicenses, error) {
+	pkg, err := LoadZarfPackage(packagePath)
+	if err != nil {
+		return nil, err
+	}
+
+	usages := map[string]*LicenseUsage{}
+	add := func(source string, licenses []string) {
+		if len(licenses) == 0 {
+			licenses = []string{UnknownLicense}
+		}
+		for _, license := range licenses {
+			usage := usages[license]
+			if usage == nil {
+				usage = &LicenseUsage{License: license}
+				if compliant, reason := s.Policy.Check(license); !compliant {
+					usage.Forbidden, usage.Reason = true, reason
+				}
+				usages[license] = usage
+			}
+			usage.Artifacts++
+			if len(usage.Sources) == 0 || usage.Sources[len(usage.Sources)-1] != source {
+				usage.Sources = append(usage.Sources, source)
+			}
+		}
+	}
+
+	sboms, err := s.sboms(ctx, pkg)
+	if err != nil {
+		return nil, err
+	}
+	for _, sbom := range sboms {
+		for _, artifact := range sbom.artifacts {
+			add(sbom.source, artifact.Licenses)
+		}
+	}
+
+	for _, component := range pkg.Metadata.Components {
+		for _, chart := range component.Charts {
+			if chart.LocalPath == "" {
+				continue
+			}
+			chartYaml, err := util.ReadChartYaml(filepath.Join(packagePath, chart.LocalPath))
+			if err != nil {
+				return nil, fmt.Errorf("chart %s: %w", chart.Name, err)
+			}
+			var licenses []string
+			if license := chartYaml.Annotations[ChartLicenseAnnotation]; license != "" {
+				licenses = []string{license}
+			}
+			add("chart "+chart.Name, licenses)
+		}
+	}
+
+	result := &PackageLicenses{Package: packagePath, Licenses: []LicenseUsage{}}
+	for _, usage := range usages {
+		sort.Strings(usage.Sources)
+		result.Licenses = append(result.Licenses, *usage)
+	}
+	sort.Slice(result.Licenses, func(i, j int) bool {
+		return result.Licenses[i].License < result.Licenses[j].License
+	})
+	return result, nil
+}
+
+// packageSBOM is the SBOM of an image or file source of a package
+type packageSBOM struct {
+	source    string
+	artifacts []sbomArtifact
+}
+
+// sboms reads the SBOMs of the package from SBOMDir, or generates the SBOMs of its
+// images with syft
+func (s LicenseScanner) sboms(ctx context.Context, pkg *ZarfPackage) ([]packageSBOM, error) {
+	var sboms []packageSBOM
+	if s.SBOMDir != "" {
+		files, err := filepath.Glob(filepath.Join(s.SBOMDir, pkg.Name, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no SBOMs of package %s found in %s", pkg.Name, filepath.Join(s.SBOMDir, pkg.Name))
+		}
+		for _, file := range files {
+			content, err := os.ReadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			artifacts, err := parseSBOM(content)
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse SBOM %s: %w", file, err)
+			}
+			sboms = append(sboms, packageSBOM{source: strings.TrimSuffix(filepath.Base(file), ".json"), artifacts: artifacts})
+		}
+		return sboms, nil
+	}
+
+	executor := exec.NewProcessExecutor(false)
+	seen := map[string]bool{}
+	for _, component := range pkg.Metadata.Components {
+		for _, image := range component.Images {
+			if seen[image] {
+				continue
+			}
+			seen[image] = true
+			output, err := executor.RunProcessAndCaptureStdout(ctx, "syft", "scan", image, "--output", "syft-json", "--quiet")
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate the SBOM of %s: %w", image, err)
+			}
+			artifacts, err := parseSBOM([]byte(output))
+			if err != nil {
+				return nil, fmt.Errorf("failed to parse the SBOM of %s: %w", image, err)
+			}
+			sboms = append(sboms, packageSBOM{source: image, artifacts: artifacts})
+		}
+	}
+	return sboms, nil
+}
+
+// parseSBOM returns the artifacts of a syft JSON, SPDX JSON or CycloneDX JSON SBOM
+func parseSBOM(content []byte) ([]sbomArtifact, error) {
+	var sbom struct {
+		// syft
+		Artifacts []struct {
+			Licenses []json.RawMessage `json:"licenses"`
+		} `json:"artifacts"`
+		// SPDX
+		SPDXVersion string `json:"spdxVersion"`
+		Packages    []struct {
+			LicenseConcluded string `json:"licenseConcluded"`
+			LicenseDeclared  string `json:"licenseDeclared"`
+		} `json:"packages"`
+		// CycloneDX
+		BOMFormat  string `json:"bomFormat"`
+		Components []struct {
+			Licenses []struct {
+				License struct {
+					ID   string `json:"id"`
+					Name string `json:"name"`
+				} `json:"license"`
+				Expression string `json:"expression"`
+			} `json:"licenses"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(content, &sbom); err != nil {
+		return nil, err
+	}
+
+	var artifacts []sbomArtifact
+	switch {
+	case sbom.SPDXVersion != "":
+		for _, pkg := range sbom.Packages {
+			artifact := sbomArtifact{}
+			for _, license := range []string{pkg.LicenseConcluded, pkg.LicenseDeclared} {
+				if license != "" && license != "NOASSERTION" && license != "NONE" {
+					artifact.Licenses = []string{license}
+					break
+				}
+			}
+			artifacts = append(artifacts, artifact)
+		}
+	case sbom.BOMFormat == "CycloneDX":
+		for _, component := range sbom.Components {
+			artifact := sbomArtifact{}
+			for _, license := range component.Licenses {
+				for _, value := range []string{license.Expression, license.License.ID, license.License.Name} {
+					if value != "" {
+						artifact.Licenses = append(artifact.Licenses, value)
+						break
+					}
+				}
+			}
+			artifacts = append(artifacts, artifact)
+		}
+	default:
+		for _, item := range sbom.Artifacts {
+			artifact := sbomArtifact{}
+			for _, raw := range item.Licenses {
+				// syft lists license strings, or objects since v0.80
+				var license struct {
+					Value          string `json:"value"`
+					SPDXExpression string `json:"spdxExpression"`
+				}
+				if err := json.Unmarshal(raw, &license.Value); err != nil {
+					if err := json.Unmarshal(raw, &license); err != nil {
+						return nil, err
+					}
+				}
+				if license.SPDXExpression != "" {
+					license.Value = license.SPDXExpression
+				}
+				if license.Value != "" {
+					artifact.Licenses = append(artifact.Licenses, license.Value)
+				}
+			}
+			artifacts = append(artifacts, artifact)
+		}
+	}
+	return artifacts, nil
+}
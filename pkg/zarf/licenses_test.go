@@ -0,0 +1,145 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLicensePolicyCheck(t *testing.T) {
+	policy := LicensePolicy{Allowed: []string{"MIT", "Apache-2.0", "BSD-*", "GPL-2.0-only"}, Denied: []string{"agpl-*"}}
+	tests := []struct {
+		expression string
+		compliant  bool
+		reason     string
+	}{
+		{expression: "MIT", compliant: true},
+		{expression: "BSD-3-Clause", compliant: true},
+		{expression: "AGPL-3.0-only", reason: "AGPL-3.0-only is denied"},
+		{expression: "MPL-2.0", reason: "MPL-2.0 is not allowed"},
+		{expression: "MIT OR AGPL-3.0-only", compliant: true},
+		{expression: "(MIT AND AGPL-3.0-or-later)", reason: "AGPL-3.0-or-later is denied"},
+		{expression: "MPL-2.0 OR AGPL-3.0-only", reason: "MPL-2.0 is not allowed, AGPL-3.0-only is denied"},
+		{expression: "GPL-2.0-only WITH Classpath-exception-2.0", compliant: true},
+		{expression: UnknownLicense, compliant: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			compliant, reason := policy.Check(tt.expression)
+			assert.Equal(t, tt.compliant, compliant)
+			assert.Equal(t, tt.reason, reason)
+		})
+	}
+
+	compliant, _ := LicensePolicy{}.Check("AGPL-3.0-only")
+	assert.True(t, compliant)
+}
+
+func TestParseSBOM(t *testing.T) {
+	syft := `{"artifacts":[
+		{"name":"musl","licenses":["MIT"]},
+		{"name":"openssl","licenses":[{"value":"Apache 2.0","spdxExpression":"Apache-2.0"}]},
+		{"name":"busybox","licenses":[]}
+	]}`
+	artifacts, err := parseSBOM([]byte(syft))
+	require.NoError(t, err)
+	assert.Equal(t, []sbomArtifact{{Licenses: []string{"MIT"}}, {Licenses: []string{"Apache-2.0"}}, {}}, artifacts)
+
+	spdx := `{"spdxVersion":"SPDX-2.3","packages":[
+		{"name":"musl","licenseConcluded":"NOASSERTION","licenseDeclared":"MIT"},
+		{"name":"busybox","licenseConcluded":"NONE"}
+	]}`
+	artifacts, err = parseSBOM([]byte(spdx))
+	require.NoError(t, err)
+	assert.Equal(t, []sbomArtifact{{Licenses: []string{"MIT"}}, {}}, artifacts)
+
+	cyclonedx := `{"bomFormat":"CycloneDX","components":[
+		{"name":"musl","licenses":[{"license":{"id":"MIT"}}]},
+		{"name":"curl","licenses":[{"license":{"name":"curl License"}},{"expression":"MIT OR Apache-2.0"}]}
+	]}`
+	artifacts, err = parseSBOM([]byte(cyclonedx))
+	require.NoError(t, err)
+	assert.Equal(t, []sbomArtifact{{Licenses: []string{"MIT"}}, {Licenses: []string{"curl License", "MIT OR Apache-2.0"}}}, artifacts)
+
+	_, err = parseSBOM([]byte("not json"))
+	assert.Error(t, err)
+}
+
+func TestLicenseScannerScan(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+components:
+  - name: podinfo
+    images:
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+    charts:
+      - name: podinfo
+        localPath: chart
+      - name: redis
+        url: oci://registry-1.docker.io/bitnamicharts/redis
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(packageDir, "chart"), 0755))
+	chartYaml := "name: podinfo\nversion: 6.4.0\nannotations:\n  artifacthub.io/license: Apache-2.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "chart", "Chart.yaml"), []byte(chartYaml), 0644))
+
+	// syft is run once per image
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"syft": `echo "syft $*" >> "$ZT_TEST_CALLS"
+echo '{"artifacts":[{"licenses":["MIT"]},{"licenses":["AGPL-3.0-only"]},{"licenses":["MIT"]},{"licenses":[]}]}'`,
+	})
+
+	scanner := LicenseScanner{Policy: LicensePolicy{Denied: []string{"AGPL-*"}}}
+	licenses, err := scanner.Scan(context.Background(), packageDir)
+	require.NoError(t, err)
+	image := "ghcr.io/stefanprodan/podinfo:6.4.0"
+	assert.Equal(t, &PackageLicenses{Package: packageDir, Licenses: []LicenseUsage{
+		{License: "AGPL-3.0-only", Artifacts: 1, Sources: []string{image}, Forbidden: true, Reason: "AGPL-3.0-only is denied"},
+		{License: "Apache-2.0", Artifacts: 1, Sources: []string{"chart podinfo"}},
+		{License: "MIT", Artifacts: 2, Sources: []string{image}},
+		{License: UnknownLicense, Artifacts: 1, Sources: []string{image}},
+	}}, licenses)
+	assert.Len(t, licenses.Forbidden(), 1)
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "syft scan "+image+" --output syft-json --quiet\n", string(content))
+
+	// SBOMs extracted from the built package are read instead
+	sbomDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sbomDir, "podinfo"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(sbomDir, "podinfo", "ghcr.io_stefanprodan_podinfo_6.4.0.json"), []byte(`{"artifacts":[{"licenses":["BSD-2-Clause"]}]}`), 0644))
+	scanner.SBOMDir = sbomDir
+	licenses, err = scanner.Scan(context.Background(), packageDir)
+	require.NoError(t, err)
+	assert.Equal(t, []LicenseUsage{
+		{License: "Apache-2.0", Artifacts: 1, Sources: []string{"chart podinfo"}},
+		{License: "BSD-2-Clause", Artifacts: 1, Sources: []string{"ghcr.io_stefanprodan_podinfo_6.4.0"}},
+	}, licenses.Licenses)
+
+	scanner.SBOMDir = t.TempDir()
+	_, err = scanner.Scan(context.Background(), packageDir)
+	assert.ErrorContains(t, err, "no SBOMs of package podinfo found")
+}
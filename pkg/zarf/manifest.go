@@ -0,0 +1,463 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// kindSchema describes the top-level shape of a built-in Kubernetes kind
+type kindSchema struct {
+	// Fields lists the allowed top-level fields besides apiVersion, kind and metadata
+	Fields []string
+	// Required lists dotted field paths that must be present
+	Required []string
+	// PodSpec is the dotted path to an embedded pod spec, if any
+	PodSpec string
+}
+
+// apiDeprecation records when a group/version of a kind was deprecated and removed
+type apiDeprecation struct {
+	DeprecatedIn string
+	RemovedIn    string
+	Replacement  string
+}
+
+var (
+	workloadFields = []string{"spec", "status"}
+
+	// builtinKinds is a catalog of the stable Kubernetes API kinds keyed by apiVersion,
+	// used to validate manifests without access to a cluster
+	builtinKinds = map[string]map[string]kindSchema{
+		"v1": {
+			"ConfigMap":             {Fields: []string{"data", "binaryData", "immutable"}},
+			"Secret":                {Fields: []string{"data", "stringData", "type", "immutable"}},
+			"Service":               {Fields: workloadFields, Required: []string{"spec"}},
+			"ServiceAccount":        {Fields: []string{"secrets", "imagePullSecrets", "automountServiceAccountToken"}},
+			"Namespace":             {Fields: workloadFields},
+			"Pod":                   {Fields: workloadFields, Required: []string{"spec.containers"}, PodSpec: "spec"},
+			"PersistentVolumeClaim": {Fields: workloadFields, Required: []string{"spec"}},
+			"PersistentVolume":      {Fields: workloadFields, Required: []string{"spec"}},
+			"ResourceQuota":         {Fields: workloadFields},
+			"LimitRange":            {Fields: workloadFields},
+			"Endpoints":             {Fields: []string{"subsets"}},
+			"ReplicationController": {Fields: workloadFields, PodSpec: "spec.template.spec"},
+			"PodTemplate":           {Fields: []string{"template"}},
+			"Node":                  {Fields: workloadFields},
+			"Binding":               {Fields: []string{"target"}, Required: []string{"target"}},
+			"ComponentStatus":       {Fields: []string{"conditions"}},
+			"List":                  {Fields: []string{"items"}},
+			"Event": {Fields: []string{"involvedObject", "reason", "message", "source", "firstTimestamp", "lastTimestamp",
+				"count", "type", "eventTime", "series", "action", "related", "reportingComponent", "reportingInstance"}},
+		},
+		"apps/v1": {
+			"Deployment":         {Fields: workloadFields, Required: []string{"spec.selector", "spec.template"}, PodSpec: "spec.template.spec"},
+			"StatefulSet":        {Fields: workloadFields, Required: []string{"spec.selector", "spec.template"}, PodSpec: "spec.template.spec"},
+			"DaemonSet":          {Fields: workloadFields, Required: []string{"spec.selector", "spec.template"}, PodSpec: "spec.template.spec"},
+			"ReplicaSet":         {Fields: workloadFields, Required: []string{"spec.selector", "spec.template"}, PodSpec: "spec.template.spec"},
+			"ControllerRevision": {Fields: []string{"data", "revision"}},
+		},
+		"batch/v1": {
+			"Job":     {Fields: workloadFields, Required: []string{"spec.template"}, PodSpec: "spec.template.spec"},
+			"CronJob": {Fields: workloadFields, Required: []string{"spec.schedule", "spec.jobTemplate"}, PodSpec: "spec.jobTemplate.spec.template.spec"},
+		},
+		"networking.k8s.io/v1": {
+			"Ingress":       {Fields: workloadFields},
+			"IngressClass":  {Fields: []string{"spec"}},
+			"NetworkPolicy": {Fields: []string{"spec"}, Required: []string{"spec.podSelector"}},
+			"IPAddress":     {Fields: []string{"spec"}},
+			"ServiceCIDR":   {Fields: workloadFields},
+		},
+		"rbac.authorization.k8s.io/v1": {
+			"Role":               {Fields: []string{"rules"}},
+			"ClusterRole":        {Fields: []string{"rules", "aggregationRule"}},
+			"RoleBinding":        {Fields: []string{"subjects", "roleRef"}, Required: []string{"roleRef"}},
+			"ClusterRoleBinding": {Fields: []string{"subjects", "roleRef"}, Required: []string{"roleRef"}},
+		},
+		"policy/v1": {
+			"PodDisruptionBudget": {Fields: workloadFields},
+		},
+		"autoscaling/v1": {
+			"HorizontalPodAutoscaler": {Fields: workloadFields, Required: []string{"spec.scaleTargetRef"}},
+		},
+		"autoscaling/v2": {
+			"HorizontalPodAutoscaler": {Fields: workloadFields, Required: []string{"spec.scaleTargetRef"}},
+		},
+		"storage.k8s.io/v1": {
+			"StorageClass": {Fields: []string{"provisioner", "parameters", "reclaimPolicy", "mountOptions",
+				"allowVolumeExpansion", "volumeBindingMode", "allowedTopologies"}, Required: []string{"provisioner"}},
+			"CSIDriver":             {Fields: []string{"spec"}},
+			"VolumeAttachment":      {Fields: workloadFields},
+			"CSIStorageCapacity":    {Fields: []string{"storageClassName", "capacity", "maximumVolumeSize", "nodeTopology"}},
+			"CSINode":               {Fields: []string{"spec"}},
+			"VolumeAttributesClass": {Fields: []string{"driverName", "parameters"}, Required: []string{"driverName"}},
+		},
+		"apiextensions.k8s.io/v1": {
+			"CustomResourceDefinition": {Fields: workloadFields, Required: []string{"spec.group", "spec.names", "spec.versions"}},
+		},
+		"admissionregistration.k8s.io/v1": {
+			"ValidatingWebhookConfiguration":   {Fields: []string{"webhooks"}},
+			"MutatingWebhookConfiguration":     {Fields: []string{"webhooks"}},
+			"ValidatingAdmissionPolicy":        {Fields: workloadFields},
+			"ValidatingAdmissionPolicyBinding": {Fields: []string{"spec"}},
+		},
+		"flowcontrol.apiserver.k8s.io/v1": {
+			"FlowSchema":                 {Fields: workloadFields},
+			"PriorityLevelConfiguration": {Fields: workloadFields},
+		},
+		"scheduling.k8s.io/v1": {
+			"PriorityClass": {Fields: []string{"value", "globalDefault", "description", "preemptionPolicy"}, Required: []string{"value"}},
+		},
+		"coordination.k8s.io/v1": {
+			"Lease": {Fields: []string{"spec"}},
+		},
+		"node.k8s.io/v1": {
+			"RuntimeClass": {Fields: []string{"handler", "overhead", "scheduling"}, Required: []string{"handler"}},
+		},
+		"discovery.k8s.io/v1": {
+			"EndpointSlice": {Fields: []string{"addressType", "endpoints", "ports"}, Required: []string{"addressType"}},
+		},
+		"certificates.k8s.io/v1": {
+			"CertificateSigningRequest": {Fields: workloadFields},
+		},
+		"events.k8s.io/v1": {
+			"Event": {Fields: []string{"eventTime", "series", "reportingController", "reportingInstance", "action", "reason",
+				"regarding", "related", "note", "type", "deprecatedSource", "deprecatedFirstTimestamp",
+				"deprecatedLastTimestamp", "deprecatedCount"}},
+		},
+		"resource.k8s.io/v1": {
+			"DeviceClass":           {Fields: []string{"spec"}},
+			"ResourceClaim":         {Fields: workloadFields},
+			"ResourceClaimTemplate": {Fields: []string{"spec"}},
+			"ResourceSlice":         {Fields: []string{"spec"}},
+		},
+	}
+
+	// partialGroupVersions are the group/versions of the catalog that Kubernetes keeps
+	// adding kinds to, so a kind missing from them may just be newer than the catalog.
+	// The other group/versions are complete, a kind missing from them does not exist.
+	partialGroupVersions = map[string]bool{
+		"admissionregistration.k8s.io/v1": true,
+		"resource.k8s.io/v1":              true,
+	}
+
+	// deprecatedAPIs lists deprecated or removed group/versions keyed by apiVersion and kind
+	deprecatedAPIs = map[string]map[string]apiDeprecation{
+		"extensions/v1beta1": {
+			"Deployment":        {"1.9", "1.16", "apps/v1"},
+			"DaemonSet":         {"1.9", "1.16", "apps/v1"},
+			"ReplicaSet":        {"1.9", "1.16", "apps/v1"},
+			"NetworkPolicy":     {"1.9", "1.16", "networking.k8s.io/v1"},
+			"PodSecurityPolicy": {"1.10", "1.16", "policy/v1beta1"},
+			"Ingress":           {"1.14", "1.22", "networking.k8s.io/v1"},
+		},
+		"apps/v1beta1": {
+			"Deployment":  {"1.9", "1.16", "apps/v1"},
+			"StatefulSet": {"1.9", "1.16", "apps/v1"},
+		},
+		"apps/v1beta2": {
+			"Deployment":  {"1.9", "1.16", "apps/v1"},
+			"StatefulSet": {"1.9", "1.16", "apps/v1"},
+			"DaemonSet":   {"1.9", "1.16", "apps/v1"},
+			"ReplicaSet":  {"1.9", "1.16", "apps/v1"},
+		},
+		"networking.k8s.io/v1beta1": {
+			"Ingress":      {"1.19", "1.22", "networking.k8s.io/v1"},
+			"IngressClass": {"1.19", "1.22", "networking.k8s.io/v1"},
+		},
+		"rbac.authorization.k8s.io/v1beta1": {
+			"Role":               {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+			"ClusterRole":        {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+			"RoleBinding":        {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+			"ClusterRoleBinding": {"1.17", "1.22", "rbac.authorization.k8s.io/v1"},
+		},
+		"apiextensions.k8s.io/v1beta1": {
+			"CustomResourceDefinition": {"1.16", "1.22", "apiextensions.k8s.io/v1"},
+		},
+		"admissionregistration.k8s.io/v1beta1": {
+			"ValidatingWebhookConfiguration": {"1.16", "1.22", "admissionregistration.k8s.io/v1"},
+			"MutatingWebhookConfiguration":   {"1.16", "1.22", "admissionregistration.k8s.io/v1"},
+		},
+		"scheduling.k8s.io/v1beta1": {
+			"PriorityClass": {"1.14", "1.22", "scheduling.k8s.io/v1"},
+		},
+		"storage.k8s.io/v1beta1": {
+			"StorageClass":       {"1.6", "1.22", "storage.k8s.io/v1"},
+			"CSIDriver":          {"1.19", "1.22", "storage.k8s.io/v1"},
+			"VolumeAttachment":   {"1.13", "1.22", "storage.k8s.io/v1"},
+			"CSIStorageCapacity": {"1.24", "1.27", "storage.k8s.io/v1"},
+		},
+		"coordination.k8s.io/v1beta1": {
+			"Lease": {"1.14", "1.22", "coordination.k8s.io/v1"},
+		},
+		"certificates.k8s.io/v1beta1": {
+			"CertificateSigningRequest": {"1.19", "1.22", "certificates.k8s.io/v1"},
+		},
+		"batch/v1beta1": {
+			"CronJob": {"1.21", "1.25", "batch/v1"},
+		},
+		"policy/v1beta1": {
+			"PodDisruptionBudget": {"1.21", "1.25", "policy/v1"},
+			"PodSecurityPolicy":   {"1.21", "1.25", ""},
+		},
+		"discovery.k8s.io/v1beta1": {
+			"EndpointSlice": {"1.21", "1.25", "discovery.k8s.io/v1"},
+		},
+		"events.k8s.io/v1beta1": {
+			"Event": {"1.19", "1.25", "events.k8s.io/v1"},
+		},
+		"node.k8s.io/v1beta1": {
+			"RuntimeClass": {"1.20", "1.25", "node.k8s.io/v1"},
+		},
+		"autoscaling/v2beta1": {
+			"HorizontalPodAutoscaler": {"1.22", "1.25", "autoscaling/v2"},
+		},
+		"autoscaling/v2beta2": {
+			"HorizontalPodAutoscaler": {"1.23", "1.26", "autoscaling/v2"},
+		},
+		"flowcontrol.apiserver.k8s.io/v1beta1": {
+			"FlowSchema":                 {"1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+			"PriorityLevelConfiguration": {"1.23", "1.26", "flowcontrol.apiserver.k8s.io/v1"},
+		},
+		"flowcontrol.apiserver.k8s.io/v1beta2": {
+			"FlowSchema":                 {"1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+			"PriorityLevelConfiguration": {"1.26", "1.29", "flowcontrol.apiserver.k8s.io/v1"},
+		},
+		"flowcontrol.apiserver.k8s.io/v1beta3": {
+			"FlowSchema":                 {"1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+			"PriorityLevelConfiguration": {"1.29", "1.32", "flowcontrol.apiserver.k8s.io/v1"},
+		},
+	}
+)
+
+// ManifestLintResult holds the problems found in a single manifest file
+type ManifestLintResult struct {
+	Errors   []string
+	Warnings []string
+	Infos    []string // Objects that could only be validated partially
+}
+
+// LintManifestFile validates every YAML document in a manifest file against the built-in
+// Kubernetes kind catalog and flags deprecated or removed API versions for kubeVersion.
+// An empty kubeVersion reports every deprecated API as a warning.
+func LintManifestFile(path string, kubeVersion string) (*ManifestLintResult, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return LintManifest(content, kubeVersion)
+}
+
+// LintManifest validates the YAML documents in content, see LintManifestFile
+func LintManifest(content []byte, kubeVersion string) (*ManifestLintResult, error) {
+	result := &ManifestLintResult{}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	for index := 1; ; index++ {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			result.Errors = append(result.Errors, fmt.Sprintf("document %d: invalid YAML: %v", index, err))
+			break
+		}
+		if len(doc) == 0 {
+			continue
+		}
+		lintManifestDocument(doc, index, kubeVersion, result)
+	}
+
+	return result, nil
+}
+
+// lintManifestDocument validates a single decoded Kubernetes object
+func lintManifestDocument(doc map[string]interface{}, index int, kubeVersion string, result *ManifestLintResult) {
+	apiVersion, _ := doc["apiVersion"].(string)
+	kind, _ := doc["kind"].(string)
+
+	name := ""
+	if metadata, ok := toStringMap(doc["metadata"]); ok {
+		name, _ = metadata["name"].(string)
+		if name == "" {
+			if generateName, _ := metadata["generateName"].(string); generateName == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("document %d (%s): missing metadata.name", index, kind))
+			}
+		}
+	} else {
+		result.Errors = append(result.Errors, fmt.Sprintf("document %d (%s): missing metadata", index, kind))
+	}
+
+	ref := fmt.Sprintf("document %d (%s/%s)", index, kind, name)
+	if apiVersion == "" || kind == "" {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: apiVersion and kind are required", ref))
+		return
+	}
+
+	if deprecation, ok := deprecatedAPIs[apiVersion][kind]; ok {
+		lintDeprecatedAPI(ref, apiVersion, deprecation, kubeVersion, result)
+		return
+	}
+
+	kinds, knownGroupVersion := builtinKinds[apiVersion]
+	if !knownGroupVersion {
+		// Custom resources and API groups outside the catalog cannot be validated offline
+		return
+	}
+
+	schema, knownKind := kinds[kind]
+	if !knownKind {
+		hint := ""
+		if served := catalogAPIVersions(kind); len(served) > 0 {
+			hint = fmt.Sprintf(" (the catalog has it in %s)", strings.Join(served, ", "))
+		}
+		if partialGroupVersions[apiVersion] {
+			result.Infos = append(result.Infos, fmt.Sprintf("%s: kind %s of %s is not in the built-in catalog, its fields are not validated%s", ref, kind, apiVersion, hint))
+		} else {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: kind %s does not exist in %s%s", ref, kind, apiVersion, hint))
+		}
+		return
+	}
+
+	allowed := map[string]bool{"apiVersion": true, "kind": true, "metadata": true}
+	for _, field := range schema.Fields {
+		allowed[field] = true
+	}
+	var unknown []string
+	for field := range doc {
+		if !allowed[field] {
+			unknown = append(unknown, field)
+		}
+	}
+	sort.Strings(unknown)
+	for _, field := range unknown {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: unknown field %q", ref, field))
+	}
+
+	for _, required := range schema.Required {
+		if _, ok := lookupPath(doc, required); !ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: missing required field %s", ref, required))
+		}
+	}
+
+	if schema.PodSpec != "" {
+		lintPodSpec(doc, ref, schema.PodSpec, result)
+	}
+}
+
+// catalogAPIVersions returns the apiVersions of the built-in catalog serving kind
+func catalogAPIVersions(kind string) []string {
+	var apiVersions []string
+	for apiVersion, kinds := range builtinKinds {
+		if _, ok := kinds[kind]; ok {
+			apiVersions = append(apiVersions, apiVersion)
+		}
+	}
+	sort.Strings(apiVersions)
+	return apiVersions
+}
+
+// lintDeprecatedAPI reports a deprecated or removed apiVersion relative to kubeVersion
+func lintDeprecatedAPI(ref, apiVersion string, deprecation apiDeprecation, kubeVersion string, result *ManifestLintResult) {
+	replacement := ""
+	if deprecation.Replacement != "" {
+		replacement = fmt.Sprintf(", use %s instead", deprecation.Replacement)
+	}
+
+	if kubeVersion == "" {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s is deprecated since Kubernetes %s and removed in %s%s",
+			ref, apiVersion, deprecation.DeprecatedIn, deprecation.RemovedIn, replacement))
+		return
+	}
+
+	if removed, err := util.CompareVersions(kubeVersion, deprecation.RemovedIn); err == nil && removed >= 0 {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %s was removed in Kubernetes %s (target %s)%s",
+			ref, apiVersion, deprecation.RemovedIn, kubeVersion, replacement))
+		return
+	}
+	if deprecated, err := util.CompareVersions(kubeVersion, deprecation.DeprecatedIn); err == nil && deprecated >= 0 {
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %s is deprecated since Kubernetes %s and removed in %s%s",
+			ref, apiVersion, deprecation.DeprecatedIn, deprecation.RemovedIn, replacement))
+	}
+}
+
+// lintPodSpec checks that the containers of an embedded pod spec have a name and image
+func lintPodSpec(doc map[string]interface{}, ref, podSpecPath string, result *ManifestLintResult) {
+	value, ok := lookupPath(doc, podSpecPath+".containers")
+	if !ok {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: missing required field %s.containers", ref, podSpecPath))
+		return
+	}
+
+	containers, ok := value.([]interface{})
+	if !ok || len(containers) == 0 {
+		result.Errors = append(result.Errors, fmt.Sprintf("%s: %s.containers must be a non-empty list", ref, podSpecPath))
+		return
+	}
+
+	for i, item := range containers {
+		container, ok := toStringMap(item)
+		if !ok {
+			result.Errors = append(result.Errors, fmt.Sprintf("%s: %s.containers[%d] must be an object", ref, podSpecPath, i))
+			continue
+		}
+		for _, field := range []string{"name", "image"} {
+			if value, _ := container[field].(string); strings.TrimSpace(value) == "" {
+				result.Errors = append(result.Errors, fmt.Sprintf("%s: %s.containers[%d].%s is required", ref, podSpecPath, i, field))
+			}
+		}
+	}
+}
+
+// lookupPath resolves a dotted field path in a decoded YAML document
+func lookupPath(doc map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = doc
+	for _, part := range strings.Split(path, ".") {
+		m, ok := toStringMap(current)
+		if !ok {
+			return nil, false
+		}
+		current, ok = m[part]
+		if !ok || current == nil {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+// toStringMap converts the map types produced by yaml.v2 into a map keyed by string
+func toStringMap(value interface{}) (map[string]interface{}, bool) {
+	switch m := value.(type) {
+	case map[string]interface{}:
+		return m, true
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(m))
+		for k, v := range m {
+			converted[fmt.Sprintf("%v", k)] = v
+		}
+		return converted, true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,187 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintManifest(t *testing.T) {
+	testCases := []struct {
+		name        string
+		manifest    string
+		kubeVersion string
+		errors      []string
+		warnings    []string
+		infos       []string
+	}{
+		{
+			name: "valid deployment",
+			manifest: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spec:
+  selector:
+    matchLabels:
+      app: app
+  template:
+    spec:
+      containers:
+        - name: app
+          image: nginx:1.25
+`,
+		},
+		{
+			name: "unknown field and missing container image",
+			manifest: `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: app
+spek: {}
+spec:
+  selector: {}
+  template:
+    spec:
+      containers:
+        - name: app
+`,
+			errors: []string{
+				`document 1 (Deployment/app): unknown field "spek"`,
+				"document 1 (Deployment/app): spec.template.spec.containers[0].image is required",
+			},
+		},
+		{
+			name: "kind missing from a group version the catalog covers completely",
+			manifest: `
+apiVersion: v1
+kind: Deployment
+metadata:
+  name: app
+`,
+			errors: []string{"document 1 (Deployment/app): kind Deployment does not exist in v1 (the catalog has it in apps/v1)"},
+		},
+		{
+			name: "kind missing from a group version the catalog covers partially",
+			manifest: `
+apiVersion: admissionregistration.k8s.io/v1
+kind: MutatingAdmissionPolicy
+metadata:
+  name: policy
+`,
+			infos: []string{"document 1 (MutatingAdmissionPolicy/policy): kind MutatingAdmissionPolicy of admissionregistration.k8s.io/v1 is not in the built-in catalog, its fields are not validated"},
+		},
+		{
+			name: "kinds of newer Kubernetes versions",
+			manifest: `
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: policy
+spec:
+  validations:
+    - expression: object.spec.replicas <= 5
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: policy
+spec:
+  policyName: policy
+---
+apiVersion: v1
+kind: PodTemplate
+metadata:
+  name: template
+template:
+  spec:
+    containers:
+      - name: app
+        image: nginx:1.25
+`,
+			kubeVersion: "1.30",
+		},
+		{
+			name: "multiple documents with missing name",
+			manifest: `
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: ConfigMap
+metadata: {}
+`,
+			errors: []string{"document 2 (ConfigMap): missing metadata.name"},
+		},
+		{
+			name: "deprecated api without target version",
+			manifest: `
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: pdb
+`,
+			warnings: []string{"document 1 (PodDisruptionBudget/pdb): policy/v1beta1 is deprecated since Kubernetes 1.21 and removed in 1.25, use policy/v1 instead"},
+		},
+		{
+			name: "removed api for target version",
+			manifest: `
+apiVersion: policy/v1beta1
+kind: PodDisruptionBudget
+metadata:
+  name: pdb
+`,
+			kubeVersion: "1.29",
+			errors:      []string{"document 1 (PodDisruptionBudget/pdb): policy/v1beta1 was removed in Kubernetes 1.25 (target 1.29), use policy/v1 instead"},
+		},
+		{
+			name: "api not yet deprecated for target version",
+			manifest: `
+apiVersion: flowcontrol.apiserver.k8s.io/v1beta3
+kind: FlowSchema
+metadata:
+  name: schema
+`,
+			kubeVersion: "1.27",
+		},
+		{
+			name: "custom resources are skipped",
+			manifest: `
+apiVersion: example.com/v1
+kind: Widget
+metadata:
+  name: widget
+whatever: true
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			result, err := LintManifest([]byte(tc.manifest), tc.kubeVersion)
+			require.NoError(t, err)
+			assert.Equal(t, tc.errors, result.Errors)
+			assert.Equal(t, tc.warnings, result.Warnings)
+			assert.Equal(t, tc.infos, result.Infos)
+		})
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+)
+
+// Changes of a ManifestChange
+const (
+	ManifestAdded   = "added"
+	ManifestRemoved = "removed"
+	ManifestChanged = "changed"
+)
+
+// ManifestChange is a Kubernetes object of the rendered manifests, kustomizations and
+// local charts of a package that was added, removed or changed between two revisions
+type ManifestChange struct {
+	Component string `yaml:"component" json:"component"`
+	Kind      string `yaml:"kind" json:"kind"`
+	Namespace string `yaml:"namespace,omitempty" json:"namespace,omitempty"`
+	Name      string `yaml:"name" json:"name"`
+	Change    string `yaml:"change" json:"change"`
+	// Diff is a unified diff of the object as YAML. Values of Secrets are redacted.
+	Diff string `yaml:"diff" json:"diff"`
+}
+
+// Object identifies the object as kind, namespace (if set) and name
+func (c ManifestChange) Object() string {
+	if c.Namespace == "" {
+		return c.Kind + "/" + c.Name
+	}
+	return c.Kind + "/" + c.Namespace + "/" + c.Name
+}
+
+// renderedObject is a Kubernetes object of a rendered manifest
+type renderedObject struct {
+	component, kind, namespace, name string
+	yaml                             string
+}
+
+// DiffManifestsAt renders the manifests, kustomizations and local charts of the package
+// in packagePath, and of the package as of the Git reference ref, and returns the
+// objects that differ. Manifests that could not be rendered in either revision are
+// returned as warnings.
+func DiffManifestsAt(ctx context.Context, packagePath, ref string) ([]ManifestChange, []string, error) {
+	current, err := LoadPackageContext(packagePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	var warnings []string
+	currentObjects := renderedObjects(current.renderedManifests(ctx), "", &warnings)
+
+	// The package and the local files outside of it that it references are exported
+	// from Git, so that paths between them resolve as in the working tree
+	previousObjects := map[string]renderedObject{}
+	git := tool.NewGit(packagePath)
+	content, err := git.ShowFile(ctx, ref, "zarf.yaml")
+	if err != nil && !errors.Is(err, tool.ErrFileNotFound) {
+		return nil, nil, err
+	} else if err == nil {
+		dest, err := os.MkdirTemp("", "zt-diff-")
+		if err != nil {
+			return nil, nil, err
+		}
+		defer os.RemoveAll(dest)
+
+		dirs := []string{"."}
+		if zarfYaml, err := util.UnmarshalZarfYaml([]byte(content)); err == nil {
+			for _, reference := range localReferences(zarfYaml) {
+				if reference = filepath.Clean(reference); strings.HasPrefix(reference, "..") {
+					dirs = append(dirs, reference)
+				}
+			}
+		}
+		dir, err := git.ExportDirs(ctx, ref, dest, dirs...)
+		if err != nil {
+			return nil, nil, err
+		}
+		previous, err := LoadPackageContext(dir)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read the package at %s: %w", ref, err)
+		}
+		previousObjects = renderedObjects(previous.renderedManifests(ctx), ref, &warnings)
+	}
+
+	keys := map[string]bool{}
+	for key := range currentObjects {
+		keys[key] = true
+	}
+	for key := range previousObjects {
+		keys[key] = true
+	}
+
+	var changes []ManifestChange
+	for _, key := range sortedKeys(keys) {
+		before, existed := previousObjects[key]
+		after, exists := currentObjects[key]
+		object := after
+		change := ManifestChanged
+		switch {
+		case !existed:
+			change = ManifestAdded
+		case !exists:
+			object, change = before, ManifestRemoved
+		case before.yaml == after.yaml:
+			continue
+		}
+		diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+			A:        difflib.SplitLines(before.yaml),
+			B:        difflib.SplitLines(after.yaml),
+			FromFile: ref,
+			ToFile:   "current",
+			Context:  3,
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		changes = append(changes, ManifestChange{
+			Component: object.component,
+			Kind:      object.kind,
+			Namespace: object.namespace,
+			Name:      object.name,
+			Change:    change,
+			Diff:      diff,
+		})
+	}
+	return changes, warnings, nil
+}
+
+// renderedObjects returns the objects of the manifests keyed by kind, namespace and
+// name. Manifests that could not be rendered are added to warnings, labeled with ref
+// for the previous revision.
+func renderedObjects(manifests []renderedManifest, ref string, warnings *[]string) map[string]renderedObject {
+	objects := map[string]renderedObject{}
+	for _, manifest := range manifests {
+		if manifest.Err != nil {
+			warning := fmt.Sprintf("Component '%s' %s %s could not be rendered: %v", manifest.Component, manifest.Kind, manifest.Path, manifest.Err)
+			if ref != "" {
+				warning += " (at " + ref + ")"
+			}
+			*warnings = append(*warnings, warning)
+			continue
+		}
+		for _, doc := range manifest.documents() {
+			kind, _ := doc["kind"].(string)
+			metadata, _ := toStringMap(doc["metadata"])
+			name := fmt.Sprintf("%v", metadata["name"])
+			namespace, _ := metadata["namespace"].(string)
+			if namespace == "" {
+				namespace = manifest.Namespace
+			}
+			if kind == "Secret" {
+				redactSecretData(doc)
+			}
+			content, err := yaml.Marshal(doc)
+			if err != nil {
+				continue
+			}
+			object := renderedObject{component: manifest.Component, kind: kind, namespace: namespace, name: name, yaml: string(content)}
+			objects[strings.Join([]string{kind, namespace, name}, "/")] = object
+		}
+	}
+	return objects
+}
+
+// redactSecretData replaces the values of a Secret with a hash, so that changed values
+// show up in diffs without being revealed
+func redactSecretData(doc map[string]interface{}) {
+	for _, field := range []string{"data", "stringData"} {
+		data, ok := toStringMap(doc[field])
+		if !ok {
+			continue
+		}
+		redacted := map[string]interface{}{}
+		for key, value := range data {
+			sum := sha256.Sum256([]byte(fmt.Sprintf("%v", value)))
+			redacted[key] = "redacted:sha256:" + hex.EncodeToString(sum[:])[:12]
+		}
+		doc[field] = redacted
+	}
+}
@@ -0,0 +1,129 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const manifestDiffZarfYaml = `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+components:
+  - name: podinfo
+    manifests:
+      - name: podinfo
+        namespace: podinfo
+        files:
+          - ../shared/podinfo.yaml
+`
+
+func TestDiffManifestsAt(t *testing.T) {
+	repo := t.TempDir()
+	t.Chdir(repo)
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+
+	// The manifest lives outside of the package, so it has to be exported as well
+	require.NoError(t, os.MkdirAll("podinfo", 0755))
+	require.NoError(t, os.MkdirAll("shared", 0755))
+	require.NoError(t, os.WriteFile(filepath.Join("podinfo", "zarf.yaml"), []byte(manifestDiffZarfYaml), 0644))
+	previous := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: podinfo
+data:
+  color: blue
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: podinfo
+stringData:
+  password: hunter2
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: podinfo-legacy
+`
+	require.NoError(t, os.WriteFile(filepath.Join("shared", "podinfo.yaml"), []byte(previous), 0644))
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+
+	current := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: podinfo
+data:
+  color: green
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: podinfo
+stringData:
+  password: hunter3
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: podinfo
+`
+	require.NoError(t, os.WriteFile(filepath.Join("shared", "podinfo.yaml"), []byte(current), 0644))
+
+	changes, warnings, err := DiffManifestsAt(context.Background(), "podinfo", "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, warnings)
+	require.Len(t, changes, 4)
+
+	objects := map[string]string{}
+	for _, change := range changes {
+		assert.Equal(t, "podinfo", change.Component)
+		objects[change.Object()] = change.Change
+	}
+	assert.Equal(t, map[string]string{
+		"ConfigMap/podinfo/podinfo":      ManifestChanged,
+		"Secret/podinfo/podinfo":         ManifestChanged,
+		"Service/podinfo/podinfo":        ManifestAdded,
+		"Service/podinfo/podinfo-legacy": ManifestRemoved,
+	}, objects)
+
+	assert.Contains(t, changes[0].Diff, "--- HEAD\n+++ current\n")
+	assert.Contains(t, changes[0].Diff, "-  color: blue\n+  color: green\n")
+
+	// Secret values are redacted, but changes to them still show up
+	secret := changes[1].Diff
+	assert.NotContains(t, secret, "hunter")
+	assert.Regexp(t, `-  password: redacted:sha256:[0-9a-f]{12}\n\+  password: redacted:sha256:[0-9a-f]{12}\n`, secret)
+
+	// Unchanged packages have no changes
+	git("add", "-A")
+	git("commit", "-q", "-m", "update")
+	changes, _, err = DiffManifestsAt(context.Background(), "podinfo", "HEAD")
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
@@ -0,0 +1,226 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Markdown renders the report as a summary for pull request comments, with a table of
+// packages and the findings of each failing package in a collapsible section
+func (r *LintReport) Markdown() string {
+	var b strings.Builder
+	summary := r.Summary
+	fmt.Fprintf(&b, "### %s zt lint: %d passed, %d failed\n\n", statusIcon(summary.Failed == 0), summary.Passed, summary.Failed)
+	fmt.Fprintf(&b, "%d package(s), %d error(s), %d warning(s)", summary.Packages, summary.Errors, summary.Warnings)
+	if summary.Suppressed > 0 {
+		fmt.Fprintf(&b, ", %d suppressed by the baseline", summary.Suppressed)
+	}
+	if summary.Grade != "" {
+		fmt.Fprintf(&b, ", average quality score %d (%s)", summary.Score, summary.Grade)
+	}
+	b.WriteString("\n\n")
+	if len(r.Packages) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("| Package | Result | Errors | Warnings | Score | Duration |\n|---|---|---|---|---|---|\n")
+	for _, pkg := range r.Packages {
+		errors, warnings := countFindings(pkg.Findings)
+		fmt.Fprintf(&b, "| `%s` | %s | %d | %d | %d (%s) | %s |\n", pkg.Path, resultText(pkg.Valid), errors, warnings,
+			pkg.Quality.Score, pkg.Quality.Grade, formatSeconds(pkg.Duration))
+	}
+
+	for _, pkg := range r.Packages {
+		errors, warnings := countFindings(pkg.Findings)
+		if errors+warnings == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n<details><summary><code>%s</code>: %d error(s), %d warning(s)</summary>\n\n", pkg.Path, errors, warnings)
+		b.WriteString(ownersLine(pkg.Owners))
+		b.WriteString("| Severity | Rule | Message | Location |\n|---|---|---|---|\n")
+		for _, finding := range pkg.Findings {
+			if finding.Severity == SeverityInfo {
+				continue
+			}
+			location := ""
+			if finding.File != "" {
+				location = fmt.Sprintf("`%s:%d`", finding.File, finding.Line)
+			}
+			fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", finding.Severity, finding.RuleID, markdownCell(finding.Message), location)
+		}
+		b.WriteString("\n</details>\n")
+	}
+	return b.String()
+}
+
+// Markdown renders the report as a summary for pull request comments, with a table of
+// deployments and the errors of each failing deployment in a collapsible section
+func (r *InstallReport) Markdown() string {
+	var b strings.Builder
+	summary := r.Summary
+	fmt.Fprintf(&b, "### %s zt install: %d passed, %d failed\n\n", statusIcon(summary.Failed == 0), summary.Passed, summary.Failed)
+	fmt.Fprintf(&b, "%d deployment(s) in %s\n\n", summary.Deployments, formatSeconds(summary.Duration))
+	if len(r.Deployments) == 0 {
+		return b.String()
+	}
+
+	b.WriteString("| Package | Cluster | Variable set | Components | Result | Duration |\n|---|---|---|---|---|---|\n")
+	for _, deployment := range r.Deployments {
+		result := resultText(deployment.Success)
+		if deployment.TimedOut {
+			result = "⏱️ timed out"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | %s | %s | %s |\n", deployment.Path, deployment.Cluster,
+			deployment.VariableSet, deployment.Components, result, formatSeconds(deployment.Duration))
+	}
+
+	b.WriteString(benchmarkTable(r.Deployments))
+
+	for _, deployment := range r.Deployments {
+		if deployment.Success {
+			continue
+		}
+		fmt.Fprintf(&b, "\n<details><summary><code>%s</code>%s</summary>\n\n", deployment.Path, deploymentLabel(deployment))
+		b.WriteString(ownersLine(deployment.Owners))
+		for _, message := range deployment.Errors {
+			fmt.Fprintf(&b, "- ❌ %s\n", markdownCell(message))
+		}
+		for _, test := range deployment.Tests {
+			if !test.Success {
+				fmt.Fprintf(&b, "- ❌ `%s`: %s\n", test.Name, markdownCell(test.Message))
+			}
+		}
+		for _, resource := range deployment.Leaked {
+			fmt.Fprintf(&b, "- ❌ left behind after removal: `%s`\n", resource)
+		}
+		for _, message := range deployment.Warnings {
+			fmt.Fprintf(&b, "- ⚠️ %s\n", markdownCell(message))
+		}
+		b.WriteString("\n</details>\n")
+	}
+	return b.String()
+}
+
+// ReportMarkdown renders a report saved as JSON, a lint or an install report, as
+// Markdown
+func ReportMarkdown(content []byte) (string, error) {
+	lint, install, err := ParseReport(content)
+	if err != nil {
+		return "", err
+	}
+	if lint != nil {
+		return lint.Markdown(), nil
+	}
+	return install.Markdown(), nil
+}
+
+// deploymentLabel describes the cluster and configuration of a deployment
+func deploymentLabel(deployment DeploymentReport) string {
+	var details []string
+	if deployment.Cluster != "" {
+		details = append(details, "cluster "+deployment.Cluster)
+	}
+	if deployment.VariableSet != "" {
+		details = append(details, "variable set "+deployment.VariableSet)
+	}
+	if deployment.Components != "" {
+		details = append(details, "components "+deployment.Components)
+	}
+	if deployment.Mirror {
+		details = append(details, "mirror-resources")
+	}
+	if len(details) == 0 {
+		return ""
+	}
+	return " (" + strings.Join(details, ", ") + ")"
+}
+
+func countFindings(findings []Finding) (errors, warnings int) {
+	for _, finding := range findings {
+		switch finding.Severity {
+		case SeverityError:
+			errors++
+		case SeverityWarning:
+			warnings++
+		}
+	}
+	return errors, warnings
+}
+
+func statusIcon(passed bool) string {
+	if passed {
+		return "✅"
+	}
+	return "❌"
+}
+
+func resultText(passed bool) string {
+	if passed {
+		return "✅ passed"
+	}
+	return "❌ failed"
+}
+
+// benchmarkTable renders the timings of the deployments with a benchmark, if any
+func benchmarkTable(deployments []DeploymentReport) string {
+	var b strings.Builder
+	for _, deployment := range deployments {
+		benchmark := deployment.Benchmark
+		if benchmark == nil {
+			continue
+		}
+		if b.Len() == 0 {
+			b.WriteString("\n| Package | Deploy | Baseline | Image pull | Slowest workload |\n|---|---|---|---|---|\n")
+		}
+		baseline, slowest := "", ""
+		if benchmark.Baseline > 0 {
+			baseline = formatSeconds(benchmark.Baseline)
+		}
+		var slowestSeconds float64
+		for workload, seconds := range benchmark.Ready {
+			if slowest == "" || seconds > slowestSeconds || seconds == slowestSeconds && workload < slowest {
+				slowest, slowestSeconds = workload, seconds
+			}
+		}
+		if slowest != "" {
+			slowest = fmt.Sprintf("`%s` %s", slowest, formatSeconds(slowestSeconds))
+		}
+		fmt.Fprintf(&b, "| `%s`%s | %s | %s | %s | %s |\n", deployment.Path, deploymentLabel(deployment),
+			formatSeconds(benchmark.Deploy), baseline, formatSeconds(benchmark.ImagePull), slowest)
+	}
+	return b.String()
+}
+
+// formatSeconds formats a duration in seconds to a tenth of a second
+func formatSeconds(seconds float64) string {
+	return time.Duration(seconds * float64(time.Second)).Round(100 * time.Millisecond).String()
+}
+
+// ownersLine names the owners of a failing package, see PackageOwners
+func ownersLine(owners []string) string {
+	if len(owners) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Owners: %s\n\n", strings.Join(owners, ", "))
+}
+
+// markdownCell escapes text for a table cell or list item
+func markdownCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(strings.TrimSpace(text), "\n", "<br>")
+}
@@ -0,0 +1,107 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintReportMarkdown(t *testing.T) {
+	failing := &ValidationResult{PackagePath: "packages/a", Valid: true, Duration: 1250 * time.Millisecond}
+	failing.AddFinding(Finding{RuleID: "file-reference", Severity: SeverityError, Message: "missing file a|b", File: "zarf.yaml", Line: 12})
+	failing.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+	failing.AddInfo("zarf-lint", "Validated using Zarf CLI")
+	passing := &ValidationResult{PackagePath: "packages/b", Valid: true, Duration: 300 * time.Millisecond}
+
+	assert.Equal(t, "### ❌ zt lint: 1 passed, 1 failed\n\n"+
+		"2 package(s), 1 error(s), 1 warning(s), average quality score 80 (B)\n\n"+
+		"| Package | Result | Errors | Warnings | Score | Duration |\n|---|---|---|---|---|---|\n"+
+		"| `packages/a` | ❌ failed | 1 | 1 | 59 (F) | 1.3s |\n"+
+		"| `packages/b` | ✅ passed | 0 | 0 | 100 (A) | 300ms |\n"+
+		"\n<details><summary><code>packages/a</code>: 1 error(s), 1 warning(s)</summary>\n\n"+
+		"| Severity | Rule | Message | Location |\n|---|---|---|---|\n"+
+		"| error | `file-reference` | missing file a\\|b | `zarf.yaml:12` |\n"+
+		"| warning | `image-pinning` | Image not pinned with digest - nginx:1.25 |  |\n"+
+		"\n</details>\n", NewLintReport([]*ValidationResult{failing, passing}).Markdown())
+}
+
+func TestInstallReportMarkdown(t *testing.T) {
+	results := []*DeploymentResult{
+		{
+			PackagePath: "packages/a",
+			Success:     true,
+			DeployTime:  90 * time.Second,
+			Cluster:     "kind-arm64",
+			Benchmark: &Benchmark{
+				Deploy:    80 * time.Second,
+				ImagePull: 20 * time.Second,
+				Ready:     map[string]time.Duration{"deployment/a": 40 * time.Second, "deployment/a-db": 60 * time.Second},
+				Baseline:  70 * time.Second,
+			},
+		},
+		{
+			PackagePath:    "packages/b",
+			VariableSet:    "ha",
+			DeployTime:     30 * time.Second,
+			Errors:         []string{"Deployment testing failed"},
+			Warnings:       []string{"Cleanup failed"},
+			ComponentTests: []ComponentTestResult{{ComponentName: "deployment/b", Message: "expected 2 ready replicas, got 1"}},
+			Leaked:         []ClusterResource{{Kind: "Namespace", Name: "b"}},
+		},
+	}
+	report := NewInstallReport(results)
+	assert.Equal(t, InstallSummary{Deployments: 2, Passed: 1, Failed: 1, Duration: 120}, report.Summary)
+
+	assert.Equal(t, "### ❌ zt install: 1 passed, 1 failed\n\n"+
+		"2 deployment(s) in 2m0s\n\n"+
+		"| Package | Cluster | Variable set | Components | Result | Duration |\n|---|---|---|---|---|---|\n"+
+		"| `packages/a` | kind-arm64 |  |  | ✅ passed | 1m30s |\n"+
+		"| `packages/b` |  | ha |  | ❌ failed | 30s |\n"+
+		"\n| Package | Deploy | Baseline | Image pull | Slowest workload |\n|---|---|---|---|---|\n"+
+		"| `packages/a` (cluster kind-arm64) | 1m20s | 1m10s | 20s | `deployment/a-db` 1m0s |\n"+
+		"\n<details><summary><code>packages/b</code> (variable set ha)</summary>\n\n"+
+		"- ❌ Deployment testing failed\n"+
+		"- ❌ `deployment/b`: expected 2 ready replicas, got 1\n"+
+		"- ❌ left behind after removal: `"+ClusterResource{Kind: "Namespace", Name: "b"}.String()+"`\n"+
+		"- ⚠️ Cleanup failed\n"+
+		"\n</details>\n", report.Markdown())
+}
+
+func TestReportMarkdown(t *testing.T) {
+	lint, err := json.Marshal(NewLintReport(nil))
+	require.NoError(t, err)
+	markdown, err := ReportMarkdown(lint)
+	require.NoError(t, err)
+	assert.Equal(t, "### ✅ zt lint: 0 passed, 0 failed\n\n0 package(s), 0 error(s), 0 warning(s)\n\n", markdown)
+
+	// Lint reports without a kind
+	markdown, err = ReportMarkdown([]byte(`{"packages": [], "summary": {"packages": 0}}`))
+	require.NoError(t, err)
+	assert.Contains(t, markdown, "zt lint")
+
+	install, err := json.Marshal(NewInstallReport(nil))
+	require.NoError(t, err)
+	markdown, err = ReportMarkdown(install)
+	require.NoError(t, err)
+	assert.Equal(t, "### ✅ zt install: 0 passed, 0 failed\n\n0 deployment(s) in 0s\n\n", markdown)
+
+	_, err = ReportMarkdown([]byte(`{"kind": "graph"}`))
+	assert.EqualError(t, err, `unknown report kind "graph"`)
+}
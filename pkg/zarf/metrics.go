@@ -0,0 +1,92 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"time"
+
+	"github.com/cpepper96/zarf-testing/pkg/metrics"
+)
+
+// Metrics returns the metrics of a lint run that took duration: the number of packages
+// by result, the findings by rule and severity, and the lint duration of each package
+func (r *LintReport) Metrics(duration time.Duration) *metrics.Set {
+	set := metrics.NewSet()
+	addRunMetrics(set, ReportKindLint, duration)
+	set.Gauge("zt_lint_packages", "Number of packages linted in the last run, by result",
+		float64(r.Summary.Passed), "result", "passed")
+	set.Gauge("zt_lint_packages", "", float64(r.Summary.Failed), "result", "failed")
+
+	type ruleKey struct{ rule, severity string }
+	counts := map[ruleKey]int{}
+	var keys []ruleKey
+	for _, pkg := range r.Packages {
+		for _, finding := range pkg.Findings {
+			if finding.Severity == SeverityInfo {
+				continue
+			}
+			key := ruleKey{finding.RuleID, finding.Severity}
+			if counts[key] == 0 {
+				keys = append(keys, key)
+			}
+			counts[key]++
+		}
+	}
+	for _, key := range keys {
+		set.Gauge("zt_lint_findings", "Number of findings in the last run, by rule and severity",
+			float64(counts[key]), "rule", key.rule, "severity", key.severity)
+	}
+
+	for _, pkg := range r.Packages {
+		set.Gauge("zt_lint_duration_seconds", "Time taken to lint each package in the last run",
+			pkg.Duration, "package", pkg.Path)
+	}
+	for _, pkg := range r.Packages {
+		set.Gauge("zt_lint_quality_score", "Quality score of each package from 0 to 100 in the last run",
+			float64(pkg.Quality.Score), "package", pkg.Path)
+	}
+	return set
+}
+
+// Metrics returns the metrics of an install run that took duration: the number of
+// deployments by result and the deploy duration of each package
+func (r *InstallReport) Metrics(duration time.Duration) *metrics.Set {
+	set := metrics.NewSet()
+	addRunMetrics(set, ReportKindInstall, duration)
+	set.Gauge("zt_deployments", "Number of package deployments tested in the last run, by result",
+		float64(r.Summary.Passed), "result", "passed")
+	set.Gauge("zt_deployments", "", float64(r.Summary.Failed), "result", "failed")
+
+	for _, deployment := range r.Deployments {
+		success := 0.0
+		if deployment.Success {
+			success = 1
+		}
+		labels := []string{"package", deployment.Path, "cluster", deployment.Cluster,
+			"variable_set", deployment.VariableSet, "components", deployment.Components}
+		set.Gauge("zt_deploy_duration_seconds", "Time taken to deploy and test each package in the last run",
+			deployment.Duration, labels...)
+		set.Gauge("zt_deploy_success", "Whether the deployment of each package passed its tests in the last run",
+			success, labels...)
+	}
+	return set
+}
+
+// addRunMetrics adds the metrics every run reports
+func addRunMetrics(set *metrics.Set, command string, duration time.Duration) {
+	set.Gauge("zt_run_duration_seconds", "Duration of the last run", duration.Seconds(), "command", command)
+	set.Gauge("zt_run_timestamp_seconds", "Time the last run finished, in seconds since the epoch",
+		float64(time.Now().Unix()), "command", command)
+}
@@ -0,0 +1,59 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLintReportMetrics(t *testing.T) {
+	failing := &ValidationResult{PackagePath: "packages/a", Valid: true, Duration: 1500 * time.Millisecond}
+	failing.AddError("file-reference", "missing file a.yaml")
+	failing.AddError("file-reference", "missing file b.yaml")
+	failing.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+	failing.AddInfo("zarf-lint", "Validated using Zarf CLI")
+	passing := &ValidationResult{PackagePath: "packages/b", Valid: true, Duration: 250 * time.Millisecond}
+
+	var b bytes.Buffer
+	_, err := NewLintReport([]*ValidationResult{failing, passing}).Metrics(4 * time.Second).WriteTo(&b)
+	require.NoError(t, err)
+	assert.Contains(t, b.String(), "zt_run_duration_seconds{command=\"lint\"} 4\n")
+	assert.Contains(t, b.String(), "zt_lint_packages{result=\"passed\"} 1\nzt_lint_packages{result=\"failed\"} 1\n")
+	assert.Contains(t, b.String(), "zt_lint_findings{rule=\"file-reference\",severity=\"error\"} 2\n"+
+		"zt_lint_findings{rule=\"image-pinning\",severity=\"warning\"} 1\n")
+	assert.Contains(t, b.String(), "zt_lint_duration_seconds{package=\"packages/a\"} 1.5\n"+
+		"zt_lint_duration_seconds{package=\"packages/b\"} 0.25\n")
+	assert.NotContains(t, b.String(), "zarf-lint")
+}
+
+func TestInstallReportMetrics(t *testing.T) {
+	report := NewInstallReport([]*DeploymentResult{
+		{PackagePath: "packages/a", Success: true, DeployTime: 90 * time.Second, Cluster: "k8s-1.30"},
+		{PackagePath: "packages/b", VariableSet: "ha", DeployTime: 30 * time.Second},
+	})
+
+	var b bytes.Buffer
+	_, err := report.Metrics(2 * time.Minute).WriteTo(&b)
+	require.NoError(t, err)
+	assert.Contains(t, b.String(), "zt_run_duration_seconds{command=\"install\"} 120\n")
+	assert.Contains(t, b.String(), "zt_deployments{result=\"passed\"} 1\nzt_deployments{result=\"failed\"} 1\n")
+	assert.Contains(t, b.String(), "zt_deploy_duration_seconds{cluster=\"k8s-1.30\",components=\"\",package=\"packages/a\",variable_set=\"\"} 90\n")
+	assert.Contains(t, b.String(), "zt_deploy_success{cluster=\"\",components=\"\",package=\"packages/b\",variable_set=\"ha\"} 0\n")
+}
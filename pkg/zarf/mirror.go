@@ -0,0 +1,252 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/cpepper96/zarf-testing/pkg/tracing"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// mirrorRegistryImage is the image of the disposable registry packages are mirrored to
+// if no registry is configured
+const mirrorRegistryImage = "registry:2"
+
+// manifestMediaTypes are the media types of image manifests and indexes accepted when
+// checking a mirrored image
+var manifestMediaTypes = []string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}
+
+// MirrorTarget is where 'zarf package mirror-resources' pushes the images and
+// repositories of packages
+type MirrorTarget struct {
+	RegistryURL      string // Registry to push images to, a disposable registry is started with docker if empty
+	RegistryUsername string
+	RegistryPassword string
+	GitURL           string // Git server to push repositories to, repositories are not mirrored if empty
+	GitUsername      string
+	GitPassword      string
+}
+
+// env returns the environment passing the credentials to zarf, so they do not show up
+// in its arguments
+func (t MirrorTarget) env() []string {
+	var env []string
+	for name, value := range map[string]string{
+		"ZARF_INIT_REGISTRY_PUSH_USERNAME": t.RegistryUsername,
+		"ZARF_INIT_REGISTRY_PUSH_PASSWORD": t.RegistryPassword,
+		"ZARF_INIT_GIT_PUSH_USERNAME":      t.GitUsername,
+		"ZARF_INIT_GIT_PUSH_PASSWORD":      t.GitPassword,
+	} {
+		if value != "" {
+			env = append(env, name+"="+value)
+		}
+	}
+	return env
+}
+
+// testMirrorResources mirrors the images and repositories of a built package with
+// 'zarf package mirror-resources' and reports the outcome per image and repository.
+// Images are pushed without checksums in their tags and looked up in the registry
+// afterwards.
+func (d *PackageDeployer) testMirrorResources(ctx context.Context, built builtPackage, zarfYaml *util.ZarfYaml) *DeploymentResult {
+	result := newDeploymentResult(built.path, deployConfig{})
+	result.Mirror = true
+	start := time.Now()
+	defer func() {
+		result.DeployTime = time.Since(start)
+		result.Success = len(result.Errors) == 0
+	}()
+
+	mirrorCtx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+	mirrorCtx, span := tracing.Start(mirrorCtx, "install.mirror", "package", built.path)
+	var err error
+	defer func() { span.End(err) }()
+
+	target := d.Mirror
+	if target.RegistryURL == "" {
+		var stop func()
+		target.RegistryURL, stop, err = d.startRegistry(mirrorCtx, built.name)
+		if err != nil {
+			d.addPhaseError(ctx, result, "Failed to start a registry", d.Timeout, err)
+			return result
+		}
+		defer stop()
+	}
+
+	globalArgs, err := d.ZarfArgs.global(built.path)
+	if err != nil {
+		result.Errors = append(result.Errors, err.Error())
+		return result
+	}
+	args := []interface{}{"package", "mirror-resources", built.tarball, "--confirm", "--no-img-checksum",
+		"--registry-url", target.RegistryURL}
+	if target.GitURL != "" {
+		args = append(args, "--git-url", target.GitURL)
+	}
+	args = append(args, globalArgs)
+	env := append(zarfConfigEnv(built.path), target.env()...)
+	_, err = d.runWithEnv(mirrorCtx, built.name, "", env, "zarf", args...)
+	if err != nil {
+		d.addPhaseError(ctx, result, "Failed to mirror package resources", d.Timeout, err)
+	}
+	mirrored := err == nil
+
+	var images, repos []string
+	if zarfYaml != nil {
+		for _, component := range zarfYaml.Components {
+			images = append(images, component.Images...)
+			repos = append(repos, component.Repos...)
+		}
+	}
+	for _, image := range images {
+		test := ComponentTestResult{ComponentName: "mirror/image/" + image, Success: true, Message: "pushed to " + target.RegistryURL}
+		if err := target.manifestExists(mirrorCtx, image); err != nil {
+			test.Success = false
+			test.Message = err.Error()
+		}
+		result.ComponentTests = append(result.ComponentTests, test)
+	}
+	if len(repos) > 0 && target.GitURL == "" {
+		result.Warnings = append(result.Warnings, "Repositories are not mirrored without a git server")
+		repos = nil
+	}
+	for _, repo := range repos {
+		test := ComponentTestResult{ComponentName: "mirror/repo/" + repo, Success: mirrored, Message: "pushed to " + target.GitURL}
+		if !mirrored {
+			test.Message = "not pushed, mirroring failed"
+		}
+		result.ComponentTests = append(result.ComponentTests, test)
+	}
+
+	var missing []string
+	for _, test := range result.ComponentTests {
+		if !test.Success && strings.HasPrefix(test.ComponentName, "mirror/image/") {
+			missing = append(missing, strings.TrimPrefix(test.ComponentName, "mirror/image/"))
+		}
+	}
+	if mirrored && len(missing) > 0 {
+		result.Errors = append(result.Errors, fmt.Sprintf("Mirrored images missing from the registry: %s", strings.Join(missing, ", ")))
+	}
+	return result
+}
+
+// startRegistry starts a disposable registry with docker, listening on a random port
+// of the loopback interface. It returns the address of the registry and a function
+// removing it.
+func (d *PackageDeployer) startRegistry(ctx context.Context, name string) (string, func(), error) {
+	id, err := d.run(ctx, name, "", "docker", "run", "--detach", "--publish", "127.0.0.1::5000", mirrorRegistryImage)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to start a registry with docker: %w", err)
+	}
+	fields := strings.Fields(id)
+	if len(fields) == 0 {
+		return "", nil, fmt.Errorf("failed to start a registry with docker: no container ID")
+	}
+	id = fields[len(fields)-1]
+	stop := func() {
+		stopCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), time.Minute)
+		defer cancel()
+		_, _ = d.run(stopCtx, name, "", "docker", "rm", "--force", "--volumes", id)
+	}
+
+	port, err := d.run(ctx, name, "", "docker", "port", id, "5000/tcp")
+	if err != nil || len(strings.Fields(port)) == 0 {
+		stop()
+		return "", nil, fmt.Errorf("failed to find the port of the registry: %v", err)
+	}
+	address := strings.Fields(port)[0]
+
+	// The registry takes a moment to accept requests
+	target := MirrorTarget{RegistryURL: address}
+	for {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, target.registryBase()+"/v2/", nil)
+		if err == nil {
+			if response, err := http.DefaultClient.Do(request); err == nil {
+				response.Body.Close()
+				if response.StatusCode == http.StatusOK {
+					return address, stop, nil
+				}
+			}
+		}
+		select {
+		case <-ctx.Done():
+			stop()
+			return "", nil, ctx.Err()
+		case <-time.After(probeInterval):
+		}
+	}
+}
+
+// registryBase returns the URL of the registry. Registries on the loopback interface
+// are accessed with HTTP, others with HTTPS, unless the URL has a scheme.
+func (t MirrorTarget) registryBase() string {
+	if strings.Contains(t.RegistryURL, "://") {
+		return strings.TrimSuffix(t.RegistryURL, "/")
+	}
+	host := t.RegistryURL
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	if ip := net.ParseIP(host); host == "localhost" || ip != nil && ip.IsLoopback() {
+		return "http://" + t.RegistryURL
+	}
+	return "https://" + t.RegistryURL
+}
+
+// manifestExists checks that the registry has the manifest of image, as pushed by
+// 'zarf package mirror-resources --no-img-checksum': under the path of the image
+// without its registry, by its digest if it has one and by its tag otherwise
+func (t MirrorTarget) manifestExists(ctx context.Context, image string) error {
+	repository, version := imageVersion(image)
+	_, path, _ := strings.Cut(repository, "/")
+	reference := version
+	if _, digest, found := strings.Cut(version, "@"); found {
+		reference = digest
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, fmt.Sprintf("%s/v2/%s/manifests/%s", t.registryBase(), path, reference), nil)
+	if err != nil {
+		return err
+	}
+	request.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if t.RegistryUsername != "" {
+		request.SetBasicAuth(t.RegistryUsername, t.RegistryPassword)
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to look up the image: %w", err)
+	}
+	response.Body.Close()
+	switch response.StatusCode {
+	case http.StatusOK:
+		return nil
+	case http.StatusNotFound:
+		return fmt.Errorf("not found in %s", t.RegistryURL)
+	default:
+		return fmt.Errorf("looking up the image returned %s", response.Status)
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegistryBase(t *testing.T) {
+	assert.Equal(t, "http://127.0.0.1:5000", MirrorTarget{RegistryURL: "127.0.0.1:5000"}.registryBase())
+	assert.Equal(t, "http://localhost:5000", MirrorTarget{RegistryURL: "localhost:5000"}.registryBase())
+	assert.Equal(t, "https://registry.test:5000", MirrorTarget{RegistryURL: "registry.test:5000"}.registryBase())
+	assert.Equal(t, "http://registry.test", MirrorTarget{RegistryURL: "http://registry.test/"}.registryBase())
+}
+
+func TestTestMirrorResources(t *testing.T) {
+	// The registry only has the podinfo image
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/", "/v2/stefanprodan/podinfo/manifests/6.4.0":
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer registry.Close()
+	address := strings.TrimPrefix(registry.URL, "http://")
+
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	t.Setenv("ZT_TEST_REGISTRY", address)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $* password=$ZARF_INIT_REGISTRY_PUSH_PASSWORD" >> "$ZT_TEST_CALLS"`,
+		"docker": `echo "docker $*" >> "$ZT_TEST_CALLS"
+case "$1" in
+run) echo c0ffee ;;
+port) echo "$ZT_TEST_REGISTRY" ;;
+esac`,
+	})
+
+	probeInterval = time.Millisecond
+	defer func() { probeInterval = time.Second }()
+
+	zarfYaml := &util.ZarfYaml{Components: []util.ZarfComponent{
+		{Name: "web", Images: []string{"ghcr.io/stefanprodan/podinfo:6.4.0", "redis:7"}},
+		{Name: "config", Repos: []string{"https://github.com/stefanprodan/podinfo.git"}},
+	}}
+	built := builtPackage{path: "packages/podinfo", name: "podinfo", tarball: "/tmp/zarf-package-podinfo-amd64.tar.zst"}
+
+	// Without a registry, a disposable one is started and removed afterwards
+	d := NewPackageDeployer()
+	result := d.testMirrorResources(context.Background(), built, zarfYaml)
+	assert.True(t, result.Mirror)
+	assert.False(t, result.Success)
+	assert.Equal(t, []string{"Mirrored images missing from the registry: redis:7"}, result.Errors)
+	assert.Equal(t, []string{"Repositories are not mirrored without a git server"}, result.Warnings)
+	assert.Equal(t, []ComponentTestResult{
+		{ComponentName: "mirror/image/ghcr.io/stefanprodan/podinfo:6.4.0", Success: true, Message: "pushed to " + address},
+		{ComponentName: "mirror/image/redis:7", Success: false, Message: "not found in " + address},
+	}, result.ComponentTests)
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "docker run --detach --publish 127.0.0.1::5000 registry:2\n"+
+		"docker port c0ffee 5000/tcp\n"+
+		"zarf package mirror-resources /tmp/zarf-package-podinfo-amd64.tar.zst --confirm --no-img-checksum --registry-url "+address+" password=\n"+
+		"docker rm --force --volumes c0ffee\n", string(content))
+
+	// A configured registry and git server are used as they are, credentials are passed
+	// in the environment
+	require.NoError(t, os.Remove(calls))
+	d.Mirror = MirrorTarget{RegistryURL: address, RegistryPassword: "secret", GitURL: "https://git.test"}
+	result = d.testMirrorResources(context.Background(), built, zarfYaml)
+	assert.Empty(t, result.Warnings)
+	assert.Contains(t, result.ComponentTests, ComponentTestResult{
+		ComponentName: "mirror/repo/https://github.com/stefanprodan/podinfo.git", Success: true, Message: "pushed to https://git.test"})
+
+	content, err = os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "zarf package mirror-resources /tmp/zarf-package-podinfo-amd64.tar.zst --confirm --no-img-checksum --registry-url "+address+
+		" --git-url https://git.test password=secret\n", string(content))
+}
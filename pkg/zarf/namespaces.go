@@ -0,0 +1,174 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// reservedNamespaces are the namespaces of the cluster itself that packages should not
+// deploy to
+var reservedNamespaces = map[string]bool{"default": true, "kube-system": true}
+
+// clusterScopedKinds are the built-in kinds of objects that do not belong to a
+// namespace, whose metadata.namespace is ignored
+var clusterScopedKinds = map[string]bool{
+	"APIService": true, "CertificateSigningRequest": true, "ClusterIssuer": true, "ClusterRole": true,
+	"ClusterRoleBinding": true, "ClusterTrustBundle": true, "ComponentStatus": true, "CSIDriver": true,
+	"CSINode": true, "CustomResourceDefinition": true, "DeviceClass": true, "FlowSchema": true,
+	"IngressClass": true, "IPAddress": true, "MutatingAdmissionPolicy": true,
+	"MutatingAdmissionPolicyBinding": true, "MutatingWebhookConfiguration": true, "Namespace": true,
+	"Node": true, "PersistentVolume": true, "PriorityClass": true, "PriorityLevelConfiguration": true,
+	"ResourceSlice": true, "RuntimeClass": true, "SelfSubjectAccessReview": true,
+	"SelfSubjectReview": true, "SelfSubjectRulesReview": true, "ServiceCIDR": true,
+	"StorageClass": true, "StorageVersionMigration": true, "SubjectAccessReview": true,
+	"TokenReview": true, "ValidatingAdmissionPolicy": true, "ValidatingAdmissionPolicyBinding": true,
+	"ValidatingWebhookConfiguration": true, "VolumeAttachment": true, "VolumeAttributesClass": true,
+}
+
+// clusterScopedCRDs returns the kinds defined by CRDs of the package with the Cluster
+// scope, as <kind>.<group>
+func (pkg *PackageContext) clusterScopedCRDs(ctx context.Context) map[string]bool {
+	kinds := map[string]bool{}
+	for _, crd := range pkg.customResourceDefinitions(ctx) {
+		if crd.Scope == "Cluster" {
+			kinds[crd.Kind+"."+crd.Group] = true
+		}
+	}
+	return kinds
+}
+
+// isClusterScoped reports whether the object does not belong to a namespace, being of
+// a built-in cluster-scoped kind or of a kind of clusterCRDs, see clusterScopedCRDs
+func isClusterScoped(doc map[string]interface{}, clusterCRDs map[string]bool) bool {
+	kind, _ := doc["kind"].(string)
+	if clusterScopedKinds[kind] {
+		return true
+	}
+	apiVersion, _ := doc["apiVersion"].(string)
+	group, _, found := strings.Cut(apiVersion, "/")
+	return found && clusterCRDs[kind+"."+group]
+}
+
+// componentNamespaces returns the namespaces the charts and manifests of component are
+// deployed to in zarf.yaml, sorted
+func componentNamespaces(component util.ZarfComponent) []string {
+	namespaces := map[string]bool{}
+	for _, chart := range component.Charts {
+		namespaces[chart.Namespace] = true
+	}
+	for _, manifest := range component.Manifests {
+		namespaces[manifest.Namespace] = true
+	}
+	delete(namespaces, "")
+	return sortedKeys(namespaces)
+}
+
+// FindNamespaceUsers reads the packages and returns the components deploying charts or
+// manifests to each namespace
+func FindNamespaceUsers(packageDirs []string) (map[string][]ImageUser, error) {
+	users := map[string][]ImageUser{}
+	for _, dir := range packageDirs {
+		zarfYaml, err := util.ReadZarfYaml(filepath.Join(dir, "zarf.yaml"))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read package %s: %w", dir, err)
+		}
+		for _, component := range zarfYaml.Components {
+			for _, namespace := range componentNamespaces(component) {
+				users[namespace] = append(users[namespace], ImageUser{Package: dir, Component: component.Name})
+			}
+		}
+	}
+	return users, nil
+}
+
+// validateNamespaces reports charts and manifests deployed to the default or
+// kube-system namespace, objects whose namespace is hardcoded to another namespace than
+// the one declared for their chart or manifest, namespaced objects of manifests without
+// a declared namespace, and, with NamespaceUsers set, namespaces other packages deploy
+// to as well
+func (v *PackageValidator) validateNamespaces(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	for _, component := range pkg.ZarfYaml.Components {
+		for _, namespace := range componentNamespaces(component) {
+			if reservedNamespaces[namespace] {
+				result.AddWarning("namespace-reserved",
+					fmt.Sprintf("Component '%s' deploys to the '%s' namespace, use a namespace of its own", component.Name, namespace))
+			}
+
+			var others []string
+			for _, user := range v.NamespaceUsers[namespace] {
+				if absPath("", user.Package) != absPath("", pkg.Path) {
+					others = append(others, user.Package+"/"+user.Component)
+				}
+			}
+			if len(others) > 0 {
+				result.AddWarning("namespace-collision",
+					fmt.Sprintf("Component '%s' deploys to namespace '%s', which other packages deploy to as well: %s",
+						component.Name, namespace, strings.Join(others, ", ")))
+			}
+		}
+	}
+
+	clusterCRDs := pkg.clusterScopedCRDs(ctx)
+	for _, manifest := range pkg.renderedManifests(ctx) {
+		if manifest.Err != nil {
+			continue
+		}
+		hardcoded := map[string][]string{} // Objects by hardcoded namespace
+		var undeclared []string            // Objects without a namespace to land in
+		for _, doc := range manifest.documents() {
+			kind, _ := doc["kind"].(string)
+			if isClusterScoped(doc, clusterCRDs) {
+				continue
+			}
+			metadata, _ := toStringMap(doc["metadata"])
+			namespace, _ := metadata["namespace"].(string)
+			if namespace == "" && manifest.Namespace == "" && kind != "" && kind != "List" {
+				undeclared = append(undeclared, fmt.Sprintf("%s/%v", kind, metadata["name"]))
+			}
+			if namespace == "" || namespace == manifest.Namespace {
+				continue
+			}
+			hardcoded[namespace] = append(hardcoded[namespace], fmt.Sprintf("%s/%v", kind, metadata["name"]))
+		}
+		if len(undeclared) > 0 {
+			sort.Strings(undeclared)
+			result.AddFinding(Finding{RuleID: "namespace-undeclared", Severity: SeverityWarning, File: manifest.Path,
+				Message: fmt.Sprintf("Component '%s' %s %s declares no namespace for %s, which land in the namespace of the kubeconfig context zarf deploys with",
+					manifest.Component, manifest.Kind, manifest.Path, strings.Join(undeclared, ", "))})
+		}
+		for _, namespace := range sortedKeys(keysOf(hardcoded)) {
+			objects := hardcoded[namespace]
+			sort.Strings(objects)
+			switch {
+			case reservedNamespaces[namespace]:
+				result.AddFinding(Finding{RuleID: "namespace-reserved", Severity: SeverityWarning, File: manifest.Path,
+					Message: fmt.Sprintf("Component '%s' %s %s deploys %s to the '%s' namespace, use a namespace of its own",
+						manifest.Component, manifest.Kind, manifest.Path, strings.Join(objects, ", "), namespace)})
+			case manifest.Namespace != "":
+				result.AddFinding(Finding{RuleID: "namespace-hardcoded", Severity: SeverityError, File: manifest.Path,
+					Message: fmt.Sprintf("Component '%s' %s %s hardcodes namespace '%s' for %s instead of its namespace '%s'",
+						manifest.Component, manifest.Kind, manifest.Path, namespace, strings.Join(objects, ", "), manifest.Namespace)})
+			}
+		}
+	}
+	return nil
+}
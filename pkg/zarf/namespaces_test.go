@@ -0,0 +1,168 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNamespaces(t *testing.T) {
+	root := t.TempDir()
+	app := filepath.Join(root, "app")
+	writePackage(t, app, `  - name: web
+    manifests:
+      - name: web
+        namespace: web
+        files:
+          - manifests.yaml
+  - name: agent
+    charts:
+      - name: agent
+        namespace: kube-system
+        url: https://example.com/charts
+        version: 1.0.0
+  - name: setup
+    manifests:
+      - name: setup
+        files:
+          - setup.yaml
+`)
+	manifests := `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: config
+---
+apiVersion: v1
+kind: ServiceMonitor
+metadata:
+  name: web
+  namespace: monitoring
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: dashboards
+  namespace: monitoring
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-pull
+  namespace: kube-system
+`
+	require.NoError(t, os.WriteFile(filepath.Join(app, "manifests.yaml"), []byte(manifests), 0644))
+	setup := `apiVersion: v1
+kind: Namespace
+metadata:
+  name: web
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: web-reader
+  namespace: web
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: web-token
+  namespace: web
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: setup
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicy
+metadata:
+  name: replicas
+---
+apiVersion: admissionregistration.k8s.io/v1
+kind: ValidatingAdmissionPolicyBinding
+metadata:
+  name: replicas
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: clusterwidgets.example.com
+spec:
+  group: example.com
+  scope: Cluster
+  names:
+    kind: ClusterWidget
+    plural: clusterwidgets
+  versions:
+    - name: v1
+---
+apiVersion: example.com/v1
+kind: ClusterWidget
+metadata:
+  name: global
+---
+apiVersion: other.example.com/v1
+kind: ClusterWidget
+metadata:
+  name: namespaced
+`
+	require.NoError(t, os.WriteFile(filepath.Join(app, "setup.yaml"), []byte(setup), 0644))
+	other := filepath.Join(root, "other")
+	writePackage(t, other, `  - name: web-extras
+    manifests:
+      - name: extras
+        namespace: web
+        files:
+          - extras.yaml
+`)
+
+	users, err := FindNamespaceUsers([]string{app, other})
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]ImageUser{
+		"kube-system": {{Package: app, Component: "agent"}},
+		"web":         {{Package: app, Component: "web"}, {Package: other, Component: "web-extras"}},
+	}, users)
+
+	v := NewPackageValidator()
+	v.NamespaceUsers = users
+	pkg, err := LoadPackageContext(app)
+	require.NoError(t, err)
+	result := &ValidationResult{}
+	require.NoError(t, v.validateNamespaces(context.Background(), pkg, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "namespace-collision", Severity: SeverityWarning,
+			Message: "Component 'web' deploys to namespace 'web', which other packages deploy to as well: " + other + "/web-extras"},
+		{RuleID: "namespace-reserved", Severity: SeverityWarning,
+			Message: "Component 'agent' deploys to the 'kube-system' namespace, use a namespace of its own"},
+		{RuleID: "namespace-reserved", Severity: SeverityWarning, File: "manifests.yaml",
+			Message: "Component 'web' manifest manifests.yaml deploys Secret/web-pull to the 'kube-system' namespace, use a namespace of its own"},
+		{RuleID: "namespace-hardcoded", Severity: SeverityError, File: "manifests.yaml",
+			Message: "Component 'web' manifest manifests.yaml hardcodes namespace 'monitoring' for ConfigMap/dashboards, ServiceMonitor/web instead of its namespace 'web'"},
+		{RuleID: "namespace-undeclared", Severity: SeverityWarning, File: "setup.yaml",
+			Message: "Component 'setup' manifest setup.yaml declares no namespace for ClusterWidget/namespaced, ConfigMap/setup, which land in the namespace of the kubeconfig context zarf deploys with"},
+	}, result.Findings)
+
+	// Without the namespaces of other packages, collisions are not checked
+	v.NamespaceUsers = nil
+	result = &ValidationResult{}
+	require.NoError(t, v.validateNamespaces(context.Background(), pkg, result))
+	assert.Len(t, result.Findings, 4)
+}
@@ -0,0 +1,131 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// Entities naming policies apply to
+const (
+	NamingPackage   = "package"
+	NamingComponent = "component"
+	NamingNamespace = "namespace"
+	NamingRelease   = "release"
+	NamingVariable  = "variable"
+)
+
+// NamingEntities are the entities naming policies apply to
+var NamingEntities = []string{NamingPackage, NamingComponent, NamingNamespace, NamingRelease, NamingVariable}
+
+// SeverityOff disables a naming policy
+const SeverityOff = "off"
+
+// NamingPolicy requires the names of an entity to match Pattern. Names that do not are
+// reported with Severity.
+type NamingPolicy struct {
+	Pattern  *regexp.Regexp
+	Severity string
+}
+
+// DefaultNamingPolicies returns the default naming policies: lowercase alphanumeric
+// names with hyphens for packages and components, DNS labels for namespaces, Helm
+// release names, and uppercase names usable in ZARF_VAR_ environment variables for
+// variables. Invalid namespaces, releases and variables fail deployments, so they are
+// errors.
+func DefaultNamingPolicies() map[string]NamingPolicy {
+	return map[string]NamingPolicy{
+		NamingPackage:   {regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`), SeverityWarning},
+		NamingComponent: {regexp.MustCompile(`^[a-z0-9][a-z0-9-]*$`), SeverityWarning},
+		NamingNamespace: {regexp.MustCompile(`^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$`), SeverityError},
+		NamingRelease:   {regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,51}[a-z0-9])?$`), SeverityError},
+		NamingVariable:  {regexp.MustCompile(`^[A-Z0-9_]+$`), SeverityError},
+	}
+}
+
+// NewNamingPolicy overrides the default naming policy of entity. An empty pattern or
+// severity keeps the default, severity 'off' disables the policy.
+func NewNamingPolicy(entity, pattern, severity string) (NamingPolicy, error) {
+	policy, ok := DefaultNamingPolicies()[entity]
+	if !ok {
+		return NamingPolicy{}, fmt.Errorf("invalid naming policy %q, must be one of: %s", entity, strings.Join(NamingEntities, ", "))
+	}
+	if pattern != "" {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return NamingPolicy{}, fmt.Errorf("invalid pattern of naming policy %q: %w", entity, err)
+		}
+		policy.Pattern = compiled
+	}
+	switch severity {
+	case "":
+	case SeverityError, SeverityWarning, SeverityInfo, SeverityOff:
+		policy.Severity = severity
+	default:
+		return NamingPolicy{}, fmt.Errorf("invalid severity %q of naming policy %q, must be one of: error, warning, info, off", severity, entity)
+	}
+	return policy, nil
+}
+
+// validateNaming checks the names of the package, its components, the namespaces and
+// release names of their charts and manifests, and its variables against the naming
+// policies
+func (v *PackageValidator) validateNaming(pkg *PackageContext, result *ValidationResult) error {
+	check := func(entity, name, message string) {
+		policy, ok := v.NamingPolicies[entity]
+		if !ok || policy.Severity == SeverityOff || name == "" || policy.Pattern.MatchString(name) {
+			return
+		}
+		result.AddFinding(Finding{
+			RuleID:   entity + "-naming",
+			Severity: policy.Severity,
+			Message:  fmt.Sprintf("%s doesn't follow the naming convention %s", message, policy.Pattern),
+		})
+	}
+
+	zarfYaml := pkg.ZarfYaml
+	check(NamingPackage, zarfYaml.Metadata.Name, fmt.Sprintf("Package name '%s'", zarfYaml.Metadata.Name))
+	for _, component := range zarfYaml.Components {
+		check(NamingComponent, component.Name, fmt.Sprintf("Component name '%s'", component.Name))
+		namespaces := map[string]bool{}
+		for _, chart := range component.Charts {
+			namespaces[chart.Namespace] = true
+			check(NamingRelease, chartReleaseName(chart),
+				fmt.Sprintf("Component '%s' chart '%s' release name '%s'", component.Name, chart.Name, chartReleaseName(chart)))
+		}
+		for _, manifest := range component.Manifests {
+			namespaces[manifest.Namespace] = true
+		}
+		for _, namespace := range sortedKeys(namespaces) {
+			check(NamingNamespace, namespace, fmt.Sprintf("Component '%s' namespace '%s'", component.Name, namespace))
+		}
+	}
+	for _, variable := range zarfYaml.Variables {
+		check(NamingVariable, variable.Name, fmt.Sprintf("Variable name '%s'", variable.Name))
+	}
+	return nil
+}
+
+// chartReleaseName returns the Helm release name of chart, which defaults to its name
+func chartReleaseName(chart util.ZarfChart) string {
+	if chart.ReleaseName != "" {
+		return chart.ReleaseName
+	}
+	return chart.Name
+}
@@ -0,0 +1,80 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNaming(t *testing.T) {
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{
+				Name:      "Web App",
+				Charts:    []util.ZarfChart{{Name: "podinfo", Namespace: "podinfo"}, {Name: "db", ReleaseName: "DB_Release", Namespace: "Data"}},
+				Manifests: []util.ZarfManifest{{Name: "config", Namespace: "podinfo"}},
+			},
+			{Name: "redis"},
+		},
+		Variables: []util.ZarfVariable{{Name: "DOMAIN"}, {Name: "log-level"}},
+	}
+	zarfYaml.Metadata.Name = "platform_apps"
+
+	v := NewPackageValidator()
+	result := &ValidationResult{}
+	require.NoError(t, v.validateNaming(&PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "package-naming", Severity: SeverityWarning, Message: "Package name 'platform_apps' doesn't follow the naming convention ^[a-z0-9][a-z0-9-]*$"},
+		{RuleID: "component-naming", Severity: SeverityWarning, Message: "Component name 'Web App' doesn't follow the naming convention ^[a-z0-9][a-z0-9-]*$"},
+		{RuleID: "release-naming", Severity: SeverityError, Message: "Component 'Web App' chart 'db' release name 'DB_Release' doesn't follow the naming convention ^[a-z0-9]([-a-z0-9.]{0,51}[a-z0-9])?$"},
+		{RuleID: "namespace-naming", Severity: SeverityError, Message: "Component 'Web App' namespace 'Data' doesn't follow the naming convention ^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$"},
+		{RuleID: "variable-naming", Severity: SeverityError, Message: "Variable name 'log-level' doesn't follow the naming convention ^[A-Z0-9_]+$"},
+	}, result.Findings)
+
+	// Policies are configurable per entity and can be disabled
+	component, err := NewNamingPolicy(NamingComponent, `^(web|redis)(-[a-z]+)*$`, SeverityError)
+	require.NoError(t, err)
+	v.NamingPolicies[NamingComponent] = component
+	v.NamingPolicies[NamingPackage], err = NewNamingPolicy(NamingPackage, "", SeverityOff)
+	require.NoError(t, err)
+	v.NamingPolicies[NamingNamespace], err = NewNamingPolicy(NamingNamespace, "", SeverityInfo)
+	require.NoError(t, err)
+	delete(v.NamingPolicies, NamingRelease)
+	delete(v.NamingPolicies, NamingVariable)
+
+	result = &ValidationResult{}
+	require.NoError(t, v.validateNaming(&PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "component-naming", Severity: SeverityError, Message: "Component name 'Web App' doesn't follow the naming convention ^(web|redis)(-[a-z]+)*$"},
+		{RuleID: "namespace-naming", Severity: SeverityInfo, Message: "Component 'Web App' namespace 'Data' doesn't follow the naming convention ^[a-z0-9]([-a-z0-9]{0,61}[a-z0-9])?$"},
+	}, result.Findings)
+}
+
+func TestNewNamingPolicy(t *testing.T) {
+	policy, err := NewNamingPolicy(NamingVariable, "", "")
+	require.NoError(t, err)
+	assert.Equal(t, DefaultNamingPolicies()[NamingVariable], policy)
+
+	_, err = NewNamingPolicy("chart", "", "")
+	assert.EqualError(t, err, `invalid naming policy "chart", must be one of: package, component, namespace, release, variable`)
+	_, err = NewNamingPolicy(NamingPackage, "[", "")
+	assert.ErrorContains(t, err, `invalid pattern of naming policy "package"`)
+	_, err = NewNamingPolicy(NamingPackage, "", "fatal")
+	assert.EqualError(t, err, `invalid severity "fatal" of naming policy "package", must be one of: error, warning, info, off`)
+}
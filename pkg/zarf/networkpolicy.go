@@ -0,0 +1,79 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+// validateNetworkPolicies reports the namespaces a component deploys workloads to
+// without a NetworkPolicy in the package. Objects without a namespace are deployed to
+// the namespace of their manifest or chart in zarf.yaml, or to 'default'.
+func (v *PackageValidator) validateNetworkPolicies(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	if !v.ValidateNetworkPolicies {
+		return nil
+	}
+
+	covered := map[string]bool{}
+	workloads := map[string]map[string]bool{} // Namespaces with workloads by component
+	var components []string
+	for _, manifest := range pkg.renderedManifests(ctx) {
+		if manifest.Err != nil {
+			continue
+		}
+		for _, doc := range manifest.documents() {
+			apiVersion, _ := doc["apiVersion"].(string)
+			kind, _ := doc["kind"].(string)
+			namespace := manifest.Namespace
+			if metadata, ok := toStringMap(doc["metadata"]); ok {
+				if value, _ := metadata["namespace"].(string); value != "" {
+					namespace = value
+				}
+			}
+			if namespace == "" {
+				namespace = "default"
+			}
+
+			if apiVersion == "networking.k8s.io/v1" && kind == "NetworkPolicy" {
+				covered[namespace] = true
+				continue
+			}
+			if schema, ok := builtinKinds[apiVersion][kind]; ok && schema.PodSpec != "" {
+				if workloads[manifest.Component] == nil {
+					workloads[manifest.Component] = map[string]bool{}
+					components = append(components, manifest.Component)
+				}
+				workloads[manifest.Component][namespace] = true
+			}
+		}
+	}
+
+	for _, component := range components {
+		var namespaces []string
+		for namespace := range workloads[component] {
+			if !covered[namespace] {
+				namespaces = append(namespaces, namespace)
+			}
+		}
+		sort.Strings(namespaces)
+		for _, namespace := range namespaces {
+			result.AddWarning("network-policy",
+				fmt.Sprintf("Component '%s' deploys workloads to namespace '%s' without a NetworkPolicy", component, namespace))
+		}
+	}
+	return nil
+}
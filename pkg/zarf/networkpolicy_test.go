@@ -0,0 +1,60 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateNetworkPolicies(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+components:
+  - name: web
+    manifests:
+      - name: web
+        namespace: web
+        files:
+          - web.yaml
+  - name: jobs
+    manifests:
+      - name: jobs
+        files:
+          - jobs.yaml
+`
+	web := "apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: web\n---\napiVersion: networking.k8s.io/v1\nkind: NetworkPolicy\nmetadata:\n  name: default-deny\n"
+	jobs := "apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n  namespace: web\n---\napiVersion: batch/v1\nkind: CronJob\nmetadata:\n  name: cleanup\n  namespace: jobs\n---\napiVersion: v1\nkind: Pod\nmetadata:\n  name: debug\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: config\n  namespace: config\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "web.yaml"), []byte(web), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "jobs.yaml"), []byte(jobs), 0644))
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validateNetworkPolicies(context.Background(), pkg, result))
+	assert.Equal(t, []string{
+		"Component 'jobs' deploys workloads to namespace 'default' without a NetworkPolicy",
+		"Component 'jobs' deploys workloads to namespace 'jobs' without a NetworkPolicy",
+	}, result.Warnings)
+}
@@ -0,0 +1,110 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// onlyLocalOSes are the operating systems Zarf runs on, the values of only.localOS
+var onlyLocalOSes = []string{"linux", "darwin", "windows"}
+
+// onlyArchitectures are the architectures Zarf builds packages for, the values of
+// metadata.architecture and only.cluster.architecture
+var onlyArchitectures = []string{"amd64", "arm64"}
+
+// onlyDistros are the Kubernetes distributions Zarf detects, the values of
+// only.cluster.distros
+var onlyDistros = []string{
+	"aks", "dockerdesktop", "eks", "eksanywhere", "gke", "k3d", "k3s", "kind",
+	"microk8s", "openshift", "rke2", "tkg",
+}
+
+// testArchitecture returns the architecture packages are tested on: Architecture of
+// the validator, else the architecture of the package, else the one zt runs on
+func (v *PackageValidator) testArchitecture(pkg *PackageContext) string {
+	if v.Architecture != "" {
+		return v.Architecture
+	}
+	if pkg.ZarfYaml.Metadata.Architecture != "" {
+		return pkg.ZarfYaml.Metadata.Architecture
+	}
+	return runtime.GOARCH
+}
+
+// validateOnly checks the 'only' block of components: the local OS, architecture and
+// distros must be values Zarf knows, components limited to the machine deploying the
+// package should not deploy to the cluster, and at least one component must be
+// deployed on the architecture the package is tested on
+func (v *PackageValidator) validateOnly(pkg *PackageContext, result *ValidationResult) error {
+	zarfYaml := pkg.ZarfYaml
+	if len(zarfYaml.Components) == 0 {
+		return nil
+	}
+
+	arch := v.testArchitecture(pkg)
+	excluded := 0
+	for _, component := range zarfYaml.Components {
+		only := component.Only
+		if only.LocalOS != "" && !contains(onlyLocalOSes, only.LocalOS) {
+			result.AddError("only-local-os",
+				fmt.Sprintf("Component '%s' has unknown only.localOS '%s', must be one of: %s", component.Name, only.LocalOS, strings.Join(onlyLocalOSes, ", ")))
+		}
+		if only.Cluster.Architecture != "" && !contains(onlyArchitectures, only.Cluster.Architecture) {
+			result.AddError("only-architecture",
+				fmt.Sprintf("Component '%s' has unknown only.cluster.architecture '%s', must be one of: %s", component.Name, only.Cluster.Architecture, strings.Join(onlyArchitectures, ", ")))
+		}
+		for _, distro := range only.Cluster.Distros {
+			if !contains(onlyDistros, distro) {
+				result.AddWarning("only-distro",
+					fmt.Sprintf("Component '%s' has unknown only.cluster.distros entry '%s', Zarf detects: %s", component.Name, distro, strings.Join(onlyDistros, ", ")))
+			}
+		}
+
+		// Components for darwin and windows install tools on the machine deploying the
+		// package, while charts, manifests and images go to a Linux cluster
+		if only.LocalOS == "darwin" || only.LocalOS == "windows" {
+			var deploys []string
+			if len(component.Charts) > 0 {
+				deploys = append(deploys, "charts")
+			}
+			if len(component.Manifests) > 0 {
+				deploys = append(deploys, "manifests")
+			}
+			if len(component.Images) > 0 {
+				deploys = append(deploys, "images")
+			}
+			if len(component.DataInjections) > 0 {
+				deploys = append(deploys, "data injections")
+			}
+			if len(deploys) > 0 {
+				result.AddWarning("only-conflict",
+					fmt.Sprintf("Component '%s' is only deployed from %s but includes %s, which are deployed to the cluster", component.Name, only.LocalOS, strings.Join(deploys, ", ")))
+			}
+		}
+
+		if only.Cluster.Architecture != "" && only.Cluster.Architecture != arch {
+			excluded++
+		}
+	}
+
+	if excluded == len(zarfYaml.Components) {
+		result.AddWarning("only-no-components",
+			fmt.Sprintf("All components are limited to other architectures than %s, deploying the package on %s would deploy nothing", arch, arch))
+	}
+	return nil
+}
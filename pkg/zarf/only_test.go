@@ -0,0 +1,59 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateOnly(t *testing.T) {
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{Name: "cli-mac", Only: util.ZarfComponentOnly{LocalOS: "darwin"}, Files: []util.ZarfFile{{Source: "zarf", Target: "/usr/local/bin/zarf"}}},
+			{Name: "cli-win", Only: util.ZarfComponentOnly{LocalOS: "windows"}, Charts: []util.ZarfChart{{Name: "podinfo"}}, Images: []string{"nginx:1.25"}},
+			{Name: "cli-bsd", Only: util.ZarfComponentOnly{LocalOS: "freebsd"}},
+			{Name: "web", Only: util.ZarfComponentOnly{Cluster: util.ZarfComponentOnlyCluster{Architecture: "arm64", Distros: []string{"k3s", "kind", "minikube"}}}},
+			{Name: "api", Only: util.ZarfComponentOnly{Cluster: util.ZarfComponentOnlyCluster{Architecture: "x86_64"}}},
+		},
+	}
+
+	v := NewPackageValidator()
+	v.Architecture = "amd64"
+	result := &ValidationResult{}
+	require.NoError(t, v.validateOnly(&PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "only-conflict", Severity: SeverityWarning, Message: "Component 'cli-win' is only deployed from windows but includes charts, images, which are deployed to the cluster"},
+		{RuleID: "only-local-os", Severity: SeverityError, Message: "Component 'cli-bsd' has unknown only.localOS 'freebsd', must be one of: linux, darwin, windows"},
+		{RuleID: "only-distro", Severity: SeverityWarning, Message: "Component 'web' has unknown only.cluster.distros entry 'minikube', Zarf detects: aks, dockerdesktop, eks, eksanywhere, gke, k3d, k3s, kind, microk8s, openshift, rke2, tkg"},
+		{RuleID: "only-architecture", Severity: SeverityError, Message: "Component 'api' has unknown only.cluster.architecture 'x86_64', must be one of: amd64, arm64"},
+	}, result.Findings)
+
+	// Components limited to other architectures deploy nothing on the tested one
+	zarfYaml.Components = zarfYaml.Components[3:4]
+	result = &ValidationResult{}
+	require.NoError(t, v.validateOnly(&PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Equal(t, "All components are limited to other architectures than amd64, deploying the package on amd64 would deploy nothing", result.Findings[1].Message)
+
+	// The architecture of the package is tested by default
+	v.Architecture = ""
+	zarfYaml.Metadata.Architecture = "arm64"
+	result = &ValidationResult{}
+	require.NoError(t, v.validateOnly(&PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Len(t, result.Findings, 1)
+}
@@ -0,0 +1,462 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Masterminds/semver"
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"gopkg.in/yaml.v3"
+)
+
+// StalenessPolicy decides how far a chart may fall behind the latest version of its
+// repository before it is reported
+type StalenessPolicy struct {
+	// MaxMajorBehind is the number of major versions a chart may lag behind
+	MaxMajorBehind int
+	// MaxMinorBehind is the number of minor versions a chart on the latest major
+	// version may lag behind
+	MaxMinorBehind int
+}
+
+// DefaultStalenessPolicy reports charts behind by a major version or by more than
+// three minor versions
+func DefaultStalenessPolicy() StalenessPolicy {
+	return StalenessPolicy{MaxMajorBehind: 0, MaxMinorBehind: 3}
+}
+
+// OutdatedChart is a chart pinned to an older version than the latest one of its
+// repository. Error is set instead of Latest when the repository could not be read.
+type OutdatedChart struct {
+	Package   string `yaml:"package" json:"package"`
+	Component string `yaml:"component" json:"component"`
+	Chart     string `yaml:"chart" json:"chart"`
+	URL       string `yaml:"url" json:"url"`
+	Version   string `yaml:"version" json:"version"`
+	Latest    string `yaml:"latest,omitempty" json:"latest,omitempty"`
+	// Behind is the size of the update to the latest version: major, minor or patch
+	Behind string `yaml:"behind,omitempty" json:"behind,omitempty"`
+	// Stale is set when the chart is further behind than the staleness policy allows
+	Stale bool   `yaml:"stale" json:"stale"`
+	Error string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// Message describes how far the chart is behind
+func (c OutdatedChart) Message() string {
+	if c.Error != "" {
+		return fmt.Sprintf("Could not read the versions of chart '%s' of component '%s' from %s: %s", c.Chart, c.Component, c.URL, c.Error)
+	}
+	return fmt.Sprintf("Chart '%s' of component '%s' is at version %s, %s behind the latest version %s", c.Chart, c.Component, c.Version, versionsBehind(c.Version, c.Latest), c.Latest)
+}
+
+// versionsBehind describes the distance between two versions, e.g. '2 major versions'
+func versionsBehind(from, to string) string {
+	previous, err := semver.NewVersion(from)
+	if err != nil {
+		return "a version"
+	}
+	latest, err := semver.NewVersion(to)
+	if err != nil {
+		return "a version"
+	}
+	count, kind := latest.Major()-previous.Major(), "major"
+	if count == 0 {
+		count, kind = latest.Minor()-previous.Minor(), "minor"
+	}
+	if count == 0 {
+		count, kind = latest.Patch()-previous.Patch(), "patch"
+	}
+	if count == 1 {
+		return fmt.Sprintf("1 %s version", kind)
+	}
+	return fmt.Sprintf("%d %s versions", count, kind)
+}
+
+// stale reports whether a chart at version is further behind latest than the policy
+// allows
+func (p StalenessPolicy) stale(version, latest string) bool {
+	previous, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	current, err := semver.NewVersion(latest)
+	if err != nil {
+		return false
+	}
+	if majors := current.Major() - previous.Major(); majors != 0 {
+		return majors > int64(p.MaxMajorBehind)
+	}
+	return current.Minor()-previous.Minor() > int64(p.MaxMinorBehind)
+}
+
+// ChartRepositories reads the versions of charts from Helm repositories, with their
+// index.yaml, and OCI registries, with 'crane ls'. Each repository is read once.
+type ChartRepositories struct {
+	mu      sync.Mutex
+	indexes map[string]map[string][]string // Versions by chart name by repository
+	errors  map[string]error
+}
+
+// NewChartRepositories creates an empty chart version cache
+func NewChartRepositories() *ChartRepositories {
+	return &ChartRepositories{indexes: map[string]map[string][]string{}, errors: map[string]error{}}
+}
+
+// remoteChart reports whether the chart is pulled from a Helm repository or an OCI
+// registry. Charts from Git repositories and local charts are not.
+func remoteChart(chart util.ZarfChart) bool {
+	if chart.Url == "" || chart.Version == "" || chart.GitPath != "" || strings.Contains(chart.Url, ".git") {
+		return false
+	}
+	return strings.HasPrefix(chart.Url, "oci://") || strings.HasPrefix(chart.Url, "http://") || strings.HasPrefix(chart.Url, "https://")
+}
+
+// Versions returns the versions of the chart published in its repository
+func (r *ChartRepositories) Versions(ctx context.Context, chart util.ZarfChart) ([]string, error) {
+	repository := strings.TrimSuffix(chart.Url, "/")
+	r.mu.Lock()
+	index, cached := r.indexes[repository]
+	err := r.errors[repository]
+	r.mu.Unlock()
+	if !cached && err == nil {
+		if strings.HasPrefix(repository, "oci://") {
+			index, err = ociChartVersions(ctx, repository)
+		} else {
+			index, err = repositoryIndex(ctx, repository)
+		}
+		r.mu.Lock()
+		r.indexes[repository], r.errors[repository] = index, err
+		r.mu.Unlock()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// OCI repositories hold a single chart, Helm repositories name it by repoName
+	if strings.HasPrefix(repository, "oci://") {
+		return index[""], nil
+	}
+	name := chart.Name
+	if chart.RepoName != "" {
+		name = chart.RepoName
+	}
+	versions, ok := index[name]
+	if !ok {
+		return nil, fmt.Errorf("the repository has no chart %s", name)
+	}
+	return versions, nil
+}
+
+// repositoryIndex reads the versions of the charts of a Helm repository from its
+// index.yaml
+func repositoryIndex(ctx context.Context, url string) (map[string][]string, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url+"/index.yaml", nil)
+	if err != nil {
+		return nil, err
+	}
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("reading the repository index failed with status %s", response.Status)
+	}
+	content, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	var index struct {
+		Entries map[string][]struct {
+			Version string `yaml:"version"`
+		} `yaml:"entries"`
+	}
+	if err := yaml.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse the repository index: %w", err)
+	}
+	versions := map[string][]string{}
+	for name, entries := range index.Entries {
+		for _, entry := range entries {
+			versions[name] = append(versions[name], entry.Version)
+		}
+	}
+	return versions, nil
+}
+
+// ociChartVersions lists the tags of a chart in an OCI registry, keyed by an empty
+// name. Helm replaces the '+' of versions with '_' in tags.
+func ociChartVersions(ctx context.Context, url string) (map[string][]string, error) {
+	if _, err := osexec.LookPath("crane"); err != nil {
+		return nil, errCraneNotFound
+	}
+	output, err := exec.NewProcessExecutor(false).RunProcessAndCaptureStdout(ctx, "crane", "ls", strings.TrimPrefix(url, "oci://"))
+	if err != nil {
+		return nil, err
+	}
+	var versions []string
+	for _, tag := range strings.Fields(output) {
+		versions = append(versions, strings.ReplaceAll(tag, "_", "+"))
+	}
+	return map[string][]string{"": versions}, nil
+}
+
+// latestVersion returns the highest SemVer version that is not a pre-release, or an
+// empty string if there is none
+func latestVersion(versions []string) string {
+	var latest *semver.Version
+	var latestText string
+	for _, version := range versions {
+		parsed, err := semver.NewVersion(version)
+		if err != nil || parsed.Prerelease() != "" {
+			continue
+		}
+		if latest == nil || parsed.GreaterThan(latest) {
+			latest, latestText = parsed, version
+		}
+	}
+	return latestText
+}
+
+// OutdatedCharts returns the charts of the package pulled from Helm repositories or
+// OCI registries that are behind the latest version, and those whose versions could
+// not be read. Charts not pinned to a SemVer version are skipped.
+func (r *ChartRepositories) OutdatedCharts(ctx context.Context, packageDir string, policy StalenessPolicy) ([]OutdatedChart, error) {
+	zarfYaml, err := util.ReadZarfYaml(filepath.Join(packageDir, "zarf.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package %s: %w", packageDir, err)
+	}
+	var outdated []OutdatedChart
+	for _, component := range zarfYaml.Components {
+		for _, chart := range component.Charts {
+			if !remoteChart(chart) {
+				continue
+			}
+			if _, err := semver.NewVersion(chart.Version); err != nil {
+				continue
+			}
+			result := OutdatedChart{Package: packageDir, Component: component.Name, Chart: chart.Name, URL: chart.Url, Version: chart.Version}
+			versions, err := r.Versions(ctx, chart)
+			if err != nil {
+				result.Error = err.Error()
+				outdated = append(outdated, result)
+				continue
+			}
+			result.Latest = latestVersion(versions)
+			switch result.Behind = versionBump(chart.Version, result.Latest); result.Behind {
+			case BumpMajor, BumpMinor, BumpPatch:
+				result.Stale = policy.stale(chart.Version, result.Latest)
+				outdated = append(outdated, result)
+			}
+		}
+	}
+	return outdated, nil
+}
+
+// validateChartDrift warns about charts further behind the latest version of their
+// repository than the staleness policy allows. Repositories that cannot be read are
+// reported as info, so that lint does not fail without network access.
+func (v *PackageValidator) validateChartDrift(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	if v.ChartDrift == nil {
+		return nil
+	}
+	outdated, err := v.chartRepositories.OutdatedCharts(ctx, pkg.Path, *v.ChartDrift)
+	if err != nil {
+		return err
+	}
+	for _, chart := range outdated {
+		switch {
+		case chart.Error != "":
+			result.AddInfo("chart-outdated", chart.Message())
+		case chart.Stale:
+			result.AddWarning("chart-outdated", chart.Message())
+		}
+	}
+	return nil
+}
+
+// OutdatedImage is an image with a newer patch release of its tag in its registry, or
+// based on a distro that reached or is about to reach its end of life
+type OutdatedImage struct {
+	Package   string `yaml:"package" json:"package"`
+	Component string `yaml:"component" json:"component"`
+	Image     string `yaml:"image" json:"image"`
+	// Latest is the newest patch release of the tag, if newer than the tag
+	Latest string  `yaml:"latest,omitempty" json:"latest,omitempty"`
+	Distro *Distro `yaml:"distro,omitempty" json:"distro,omitempty"`
+	// EOL is the end of life date of the distro, set when it passed or is within EOLSoon
+	EOL string `yaml:"eol,omitempty" json:"eol,omitempty"`
+	// EndOfLife is set when the distro reached its end of life
+	EndOfLife bool   `yaml:"endOfLife,omitempty" json:"endOfLife,omitempty"`
+	Error     string `yaml:"error,omitempty" json:"error,omitempty"`
+}
+
+// patchTagPattern matches tags with a patch version, optionally prefixed with 'v' and
+// followed by a variant such as '-alpine'
+var patchTagPattern = regexp.MustCompile(`^(v?)(\d+)\.(\d+)\.(\d+)([-_.+].*)?$`)
+
+// newerPatchTag returns the newest of tags with the same major and minor version,
+// prefix and variant as tag and a higher patch version, or an empty string
+func newerPatchTag(tag string, tags []string) string {
+	match := patchTagPattern.FindStringSubmatch(tag)
+	if match == nil {
+		return ""
+	}
+	latest, latestPatch := "", atoi(match[4])
+	for _, candidate := range tags {
+		other := patchTagPattern.FindStringSubmatch(candidate)
+		if other == nil || other[1] != match[1] || other[2] != match[2] || other[3] != match[3] || other[5] != match[5] {
+			continue
+		}
+		if patch := atoi(other[4]); patch > latestPatch {
+			latest, latestPatch = candidate, patch
+		}
+	}
+	return latest
+}
+
+// atoi converts the digits matched by a pattern
+func atoi(digits string) int {
+	value, _ := strconv.Atoi(digits)
+	return value
+}
+
+// ImageFreshness checks the images of packages for newer patch releases of their tag,
+// listed with 'crane ls', and for distros past or near their end of life, read from
+// the SBOMs in SBOMDir or generated with syft when CheckEOL is set
+type ImageFreshness struct {
+	CheckEOL bool
+	// SBOMDir holds SBOMs extracted with 'zarf package inspect sbom', read from
+	// SBOMDir/<package name>/<image>.json
+	SBOMDir string
+	// Now returns the date EOL dates are compared with
+	Now func() time.Time
+
+	mu   sync.Mutex
+	tags map[string][]string // Tags by repository
+}
+
+// NewImageFreshness creates an image checker comparing EOL dates with the current time
+func NewImageFreshness() *ImageFreshness {
+	return &ImageFreshness{Now: time.Now, tags: map[string][]string{}}
+}
+
+// repositoryTags lists the tags of the repository with 'crane ls', once per repository
+func (f *ImageFreshness) repositoryTags(ctx context.Context, repository string) ([]string, error) {
+	f.mu.Lock()
+	tags, ok := f.tags[repository]
+	f.mu.Unlock()
+	if ok {
+		return tags, nil
+	}
+	if _, err := osexec.LookPath("crane"); err != nil {
+		return nil, errCraneNotFound
+	}
+	output, err := exec.NewProcessExecutor(false).RunProcessAndCaptureStdout(ctx, "crane", "ls", repository)
+	if err != nil {
+		return nil, err
+	}
+	tags = strings.Fields(output)
+	f.mu.Lock()
+	f.tags[repository] = tags
+	f.mu.Unlock()
+	return tags, nil
+}
+
+// distro returns the distro of image from its SBOM
+func (f *ImageFreshness) distro(ctx context.Context, packageName, image string) (Distro, error) {
+	var content []byte
+	if f.SBOMDir != "" {
+		var err error
+		content, err = os.ReadFile(filepath.Join(f.SBOMDir, packageName, sbomFileName(image)+".json"))
+		if os.IsNotExist(err) {
+			return Distro{}, nil
+		}
+		if err != nil {
+			return Distro{}, err
+		}
+	} else {
+		output, err := exec.NewProcessExecutor(false).RunProcessAndCaptureStdout(ctx, "syft", "scan", image, "--output", "syft-json", "--quiet")
+		if err != nil {
+			return Distro{}, fmt.Errorf("failed to generate the SBOM: %w", err)
+		}
+		content = []byte(output)
+	}
+	return parseSBOMDistro(content)
+}
+
+// OutdatedImages returns the images of the package with a newer patch release of their
+// tag, based on a distro past or within EOLSoon of its end of life, or that could not
+// be checked. Templated images and images without a tag are not checked for newer
+// releases.
+func (f *ImageFreshness) OutdatedImages(ctx context.Context, packageDir string) ([]OutdatedImage, error) {
+	zarfYaml, err := util.ReadZarfYaml(filepath.Join(packageDir, "zarf.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read package %s: %w", packageDir, err)
+	}
+	var outdated []OutdatedImage
+	for _, component := range zarfYaml.Components {
+		for _, image := range component.Images {
+			if isTemplated(image) {
+				continue
+			}
+			result := OutdatedImage{Package: packageDir, Component: component.Name, Image: image}
+			var problems []string
+
+			reference, _, _ := strings.Cut(image, "@")
+			if repository := imageRepository(reference); repository != reference {
+				tag := strings.TrimPrefix(reference, repository+":")
+				tags, err := f.repositoryTags(ctx, repository)
+				if err != nil {
+					problems = append(problems, err.Error())
+				} else if newer := newerPatchTag(tag, tags); newer != "" {
+					result.Latest = repository + ":" + newer
+				}
+			}
+
+			if f.CheckEOL {
+				distro, err := f.distro(ctx, zarfYaml.Metadata.Name, image)
+				if err != nil {
+					problems = append(problems, err.Error())
+				} else if eol, known := distroEOLDate(distro); known {
+					now := f.Now()
+					if now.Add(EOLSoon).After(eol) {
+						result.Distro = &distro
+						result.EOL = eol.Format("2006-01-02")
+						result.EndOfLife = !now.Before(eol)
+					}
+				}
+			}
+
+			result.Error = strings.Join(problems, "; ")
+			if result.Latest != "" || result.EOL != "" || result.Error != "" {
+				outdated = append(outdated, result)
+			}
+		}
+	}
+	return outdated, nil
+}
@@ -0,0 +1,187 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	assert.Equal(t, "2.1.0", latestVersion([]string{"1.0.0", "2.1.0", "2.0.5", "3.0.0-rc.1", "latest"}))
+	assert.Equal(t, "v1.2.0", latestVersion([]string{"v1.2.0", "v1.1.9"}))
+	assert.Empty(t, latestVersion([]string{"latest"}))
+}
+
+func TestOutdatedCharts(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/charts/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, `apiVersion: v1
+entries:
+  podinfo:
+    - version: 6.7.0
+    - version: 6.3.0
+    - version: 7.0.0-beta.1
+  redis:
+    - version: 18.2.0
+    - version: 20.1.3
+`)
+	}))
+	defer server.Close()
+	fakeCommands(t, map[string]string{"crane": `echo 1.0.0; echo 1.0.1_build.2`})
+
+	packageDir := t.TempDir()
+	zarfYaml := fmt.Sprintf(`kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+components:
+  - name: web
+    charts:
+      - name: podinfo
+        url: %[1]s/charts/
+        version: 6.3.0
+      - name: podinfo-local
+        localPath: chart
+        version: 0.1.0
+      - name: cache
+        repoName: redis
+        url: %[1]s/charts
+        version: 18.2.0
+  - name: api
+    charts:
+      - name: api
+        url: oci://ghcr.io/example/charts/api
+        version: 1.0.0
+      - name: missing
+        url: %[1]s/other
+        version: 1.0.0
+      - name: current
+        url: oci://ghcr.io/example/charts/current
+        version: 1.0.1+build.2
+`, server.URL)
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	repositories := NewChartRepositories()
+	outdated, err := repositories.OutdatedCharts(context.Background(), packageDir, DefaultStalenessPolicy())
+	require.NoError(t, err)
+	assert.Equal(t, []OutdatedChart{
+		{Package: packageDir, Component: "web", Chart: "podinfo", URL: server.URL + "/charts/", Version: "6.3.0", Latest: "6.7.0", Behind: BumpMinor, Stale: true},
+		{Package: packageDir, Component: "web", Chart: "cache", URL: server.URL + "/charts", Version: "18.2.0", Latest: "20.1.3", Behind: BumpMajor, Stale: true},
+		{Package: packageDir, Component: "api", Chart: "api", URL: "oci://ghcr.io/example/charts/api", Version: "1.0.0", Latest: "1.0.1+build.2", Behind: BumpPatch},
+		{Package: packageDir, Component: "api", Chart: "missing", URL: server.URL + "/other", Version: "1.0.0", Error: "reading the repository index failed with status 404 Not Found"},
+	}, outdated)
+	assert.Equal(t, "Chart 'podinfo' of component 'web' is at version 6.3.0, 4 minor versions behind the latest version 6.7.0", outdated[0].Message())
+	assert.Equal(t, "Chart 'cache' of component 'web' is at version 18.2.0, 2 major versions behind the latest version 20.1.3", outdated[1].Message())
+
+	// Indexes are read once per run
+	assert.Equal(t, 2, requests)
+	_, err = repositories.OutdatedCharts(context.Background(), packageDir, StalenessPolicy{MaxMajorBehind: 2, MaxMinorBehind: 4})
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	// Lint warns about stale charts only
+	validator := NewPackageValidator()
+	validator.ChartDrift = &StalenessPolicy{MaxMajorBehind: 2, MaxMinorBehind: 3}
+	result := &ValidationResult{}
+	require.NoError(t, validator.validateChartDrift(context.Background(), &PackageContext{Path: packageDir}, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "chart-outdated", Severity: SeverityWarning, Message: outdated[0].Message()},
+		{RuleID: "chart-outdated", Severity: SeverityInfo, Message: "Could not read the versions of chart 'missing' of component 'api' from " + server.URL + "/other: reading the repository index failed with status 404 Not Found"},
+	}, result.Findings)
+}
+
+func TestNewerPatchTag(t *testing.T) {
+	tags := []string{"1.25.3", "1.25.4", "1.25.10-alpine", "1.25.4-alpine", "1.26.0", "v1.25.9", "latest"}
+	assert.Equal(t, "1.25.4", newerPatchTag("1.25.3", tags))
+	assert.Equal(t, "1.25.10-alpine", newerPatchTag("1.25.3-alpine", tags))
+	assert.Equal(t, "v1.25.9", newerPatchTag("v1.25.0", tags))
+	assert.Empty(t, newerPatchTag("1.25.4", tags))
+	assert.Empty(t, newerPatchTag("1.25", tags))
+	assert.Empty(t, newerPatchTag("latest", tags))
+}
+
+func TestDistroEOLDate(t *testing.T) {
+	eol, known := distroEOLDate(Distro{ID: "alpine", Version: "3.18.4"})
+	assert.True(t, known)
+	assert.Equal(t, "2025-05-09", eol.Format("2006-01-02"))
+	eol, known = distroEOLDate(Distro{ID: "amzn", Version: "2023"})
+	assert.True(t, known)
+	assert.Equal(t, "2029-06-30", eol.Format("2006-01-02"))
+	_, known = distroEOLDate(Distro{ID: "alpine", Version: "3.180"})
+	assert.False(t, known)
+	_, known = distroEOLDate(Distro{ID: "wolfi"})
+	assert.False(t, known)
+}
+
+func TestOutdatedImages(t *testing.T) {
+	fakeCommands(t, map[string]string{"crane": `case "$2" in
+nginx) echo 1.25.3-alpine; echo 1.25.5-alpine; echo 1.25.6 ;;
+ghcr.io/example/api) echo 2.0.0 ;;
+*) echo "unknown repository $2" >&2; exit 1 ;;
+esac`})
+
+	sbomDir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(sbomDir, "web"), 0755))
+	sboms := map[string]string{
+		"nginx_1.25.3-alpine":                  `{"distro": {"id": "alpine", "versionID": "3.18.4"}, "artifacts": []}`,
+		"ghcr.io_example_api_2.0.0":            `{"bomFormat": "CycloneDX", "components": [{"type": "operating-system", "name": "debian", "version": "12.5"}]}`,
+		"ghcr.io_example_old_1.0.0_sha256_abc": `{"distro": {"id": "debian", "versionID": "11"}}`,
+	}
+	for name, content := range sboms {
+		require.NoError(t, os.WriteFile(filepath.Join(sbomDir, "web", name+".json"), []byte(content), 0644))
+	}
+
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: web
+components:
+  - name: web
+    images:
+      - nginx:1.25.3-alpine
+      - ghcr.io/example/api:2.0.0
+      - ghcr.io/example/old:1.0.0@sha256:abc
+      - "###ZARF_PKG_TMPL_IMAGE###"
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	freshness := NewImageFreshness()
+	freshness.CheckEOL = true
+	freshness.SBOMDir = sbomDir
+	freshness.Now = func() time.Time { return time.Date(2026, 7, 1, 0, 0, 0, 0, time.UTC) }
+	outdated, err := freshness.OutdatedImages(context.Background(), packageDir)
+	require.NoError(t, err)
+	assert.Equal(t, []OutdatedImage{
+		{Package: packageDir, Component: "web", Image: "nginx:1.25.3-alpine", Latest: "nginx:1.25.5-alpine",
+			Distro: &Distro{ID: "alpine", Version: "3.18.4"}, EOL: "2025-05-09", EndOfLife: true},
+		{Package: packageDir, Component: "web", Image: "ghcr.io/example/old:1.0.0@sha256:abc",
+			Distro: &Distro{ID: "debian", Version: "11"}, EOL: "2026-08-31",
+			Error: "failed running process: exit status 1"},
+	}, outdated)
+}
@@ -0,0 +1,249 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// MaintainersAnnotation is the metadata annotation listing the maintainers of a
+// package, comma separated, e.g. '@org/platform, jane@example.com'
+const MaintainersAnnotation = "maintainers"
+
+// codeOwnersFiles are the locations of the CODEOWNERS file in a repository, in the
+// order GitHub looks for them
+var codeOwnersFiles = []string{".github/CODEOWNERS", "CODEOWNERS", "docs/CODEOWNERS"}
+
+// CodeOwners are the rules of a CODEOWNERS file
+type CodeOwners struct {
+	// Path is the path of the file relative to the repository
+	Path  string
+	rules []codeOwnersRule
+}
+
+type codeOwnersRule struct {
+	pattern *regexp.Regexp
+	owners  []string
+}
+
+// ParseCodeOwners parses the rules of a CODEOWNERS file. Patterns follow the gitignore
+// syntax supported by GitHub, invalid lines are skipped.
+func ParseCodeOwners(path string, content []byte) *CodeOwners {
+	codeOwners := &CodeOwners{Path: path}
+	for _, line := range strings.Split(string(content), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		var owners []string
+		for _, owner := range fields[1:] {
+			if strings.HasPrefix(owner, "#") {
+				break
+			}
+			owners = append(owners, owner)
+		}
+		pattern, err := codeOwnersPattern(fields[0])
+		if err != nil {
+			continue
+		}
+		codeOwners.rules = append(codeOwners.rules, codeOwnersRule{pattern: pattern, owners: owners})
+	}
+	return codeOwners
+}
+
+// codeOwnersPattern compiles a CODEOWNERS pattern. Patterns with a leading or inner
+// slash are relative to the repository, others match at any depth, and a pattern
+// matching a directory matches everything in it.
+func codeOwnersPattern(pattern string) (*regexp.Regexp, error) {
+	anchored := strings.Contains(strings.TrimSuffix(pattern, "/"), "/")
+	pattern = strings.Trim(pattern, "/")
+	var b strings.Builder
+	if anchored {
+		b.WriteString("^")
+	} else {
+		b.WriteString("^(.*/)?")
+	}
+	for i := 0; i < len(pattern); i++ {
+		switch {
+		case strings.HasPrefix(pattern[i:], "**/"):
+			b.WriteString("(.*/)?")
+			i += 2
+		case strings.HasPrefix(pattern[i:], "**"):
+			b.WriteString(".*")
+			i++
+		case pattern[i] == '*':
+			b.WriteString("[^/]*")
+		case pattern[i] == '?':
+			b.WriteString("[^/]")
+		default:
+			b.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		}
+	}
+	b.WriteString("(/.*)?$")
+	return regexp.Compile(b.String())
+}
+
+// Owners returns the owners of a path relative to the repository, from the last rule
+// matching it. covered is false if no rule matches.
+func (c *CodeOwners) Owners(path string) (owners []string, covered bool) {
+	path = strings.TrimPrefix(filepath.ToSlash(path), "./")
+	for i := len(c.rules) - 1; i >= 0; i-- {
+		if c.rules[i].pattern.MatchString(path) {
+			return c.rules[i].owners, true
+		}
+	}
+	return nil, false
+}
+
+// repositoryRoot returns the closest directory containing dir with a .git entry, or ""
+// if dir is not in a Git repository
+func repositoryRoot(dir string) string {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return ""
+	}
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+var (
+	codeOwnersMutex sync.Mutex
+	codeOwnersCache = map[string]*CodeOwners{}
+)
+
+// LoadCodeOwners reads the CODEOWNERS file of the repository root, returning nil if
+// the repository has none. Files are read once per root.
+func LoadCodeOwners(root string) *CodeOwners {
+	codeOwnersMutex.Lock()
+	defer codeOwnersMutex.Unlock()
+	if codeOwners, ok := codeOwnersCache[root]; ok {
+		return codeOwners
+	}
+	var codeOwners *CodeOwners
+	for _, file := range codeOwnersFiles {
+		if content, err := os.ReadFile(filepath.Join(root, file)); err == nil {
+			codeOwners = ParseCodeOwners(file, content)
+			break
+		}
+	}
+	codeOwnersCache[root] = codeOwners
+	return codeOwners
+}
+
+// packageCodeOwners returns the CODEOWNERS file of the repository of the package and
+// the owners of its zarf.yaml. The file is nil outside of a repository or without one.
+func packageCodeOwners(packagePath string) (codeOwners *CodeOwners, owners []string, covered bool) {
+	root := repositoryRoot(packagePath)
+	if root == "" {
+		return nil, nil, false
+	}
+	if codeOwners = LoadCodeOwners(root); codeOwners == nil {
+		return nil, nil, false
+	}
+	absolute, err := filepath.Abs(filepath.Join(packagePath, "zarf.yaml"))
+	if err != nil {
+		return codeOwners, nil, false
+	}
+	relative, err := filepath.Rel(root, absolute)
+	if err != nil {
+		return codeOwners, nil, false
+	}
+	owners, covered = codeOwners.Owners(relative)
+	return codeOwners, owners, covered
+}
+
+// PackageMaintainers returns the maintainers a package declares in its maintainers
+// annotation or, failing that, in the top-level x-maintainers key as a list or a comma
+// separated string
+func PackageMaintainers(zarfYaml *util.ZarfYaml) []string {
+	if value := zarfYaml.Metadata.Annotations[MaintainersAnnotation]; value != "" {
+		return annotationList(value)
+	}
+	switch value := zarfYaml.Extra["x-maintainers"].(type) {
+	case string:
+		return annotationList(value)
+	case []interface{}:
+		maintainers := []string{}
+		for _, item := range value {
+			if maintainer, ok := item.(string); ok && strings.TrimSpace(maintainer) != "" {
+				maintainers = append(maintainers, strings.TrimSpace(maintainer))
+			}
+		}
+		return maintainers
+	}
+	return []string{}
+}
+
+// PackageOwners returns the people responsible for the package at packagePath: its
+// maintainers, else the code owners of its zarf.yaml. Failures are routed to them.
+func PackageOwners(packagePath string) []string {
+	if pkg, err := LoadPackageContext(packagePath); err == nil {
+		if maintainers := PackageMaintainers(pkg.ZarfYaml); len(maintainers) > 0 {
+			return maintainers
+		}
+	}
+	_, owners, _ := packageCodeOwners(packagePath)
+	return owners
+}
+
+// validateMaintainers requires packages to declare their maintainers with
+// RequireMaintainers, and cross-checks the declared maintainers with the CODEOWNERS
+// file of the repository so that every package has a responsible team
+func (v *PackageValidator) validateMaintainers(pkg *PackageContext, result *ValidationResult) error {
+	maintainers := PackageMaintainers(pkg.ZarfYaml)
+	if len(maintainers) == 0 && v.RequireMaintainers {
+		result.AddWarning("maintainers-missing", fmt.Sprintf(
+			"Package declares no maintainers, list the team responsible for it in the '%s' annotation of metadata", MaintainersAnnotation))
+	}
+
+	codeOwners, owners, covered := packageCodeOwners(pkg.Path)
+	if codeOwners == nil {
+		return nil
+	}
+	if !covered || len(owners) == 0 {
+		if v.RequireMaintainers {
+			result.AddWarning("maintainers-codeowners", fmt.Sprintf("Package has no owners in %s, add an entry for its directory", codeOwners.Path))
+		}
+		return nil
+	}
+	if len(maintainers) == 0 {
+		return nil
+	}
+	for _, maintainer := range maintainers {
+		for _, owner := range owners {
+			if strings.EqualFold(maintainer, owner) {
+				return nil
+			}
+		}
+	}
+	result.AddWarning("maintainers-codeowners", fmt.Sprintf("None of the maintainers %s are owners of the package in %s: %s",
+		strings.Join(maintainers, ", "), codeOwners.Path, strings.Join(owners, ", ")))
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodeOwners(t *testing.T) {
+	codeOwners := ParseCodeOwners("CODEOWNERS", []byte(`# Default owners
+*                       @org/platform
+packages/               @org/packages # all packages
+/packages/web/          @org/web jane@example.com
+**/charts/*.yaml        @org/charts
+packages/legacy/zarf.yaml
+`))
+	tests := []struct {
+		path    string
+		owners  []string
+		covered bool
+	}{
+		{"README.md", []string{"@org/platform"}, true},
+		{"packages/db/zarf.yaml", []string{"@org/packages"}, true},
+		{"packages/web/zarf.yaml", []string{"@org/web", "jane@example.com"}, true},
+		{"./packages/web/charts/values.yaml", []string{"@org/charts"}, true},
+		{"packages/legacy/zarf.yaml", nil, true},
+		{"other/packages/web/zarf.yaml", []string{"@org/packages"}, true},
+	}
+	for _, tt := range tests {
+		owners, covered := codeOwners.Owners(tt.path)
+		assert.Equal(t, tt.owners, owners, tt.path)
+		assert.Equal(t, tt.covered, covered, tt.path)
+	}
+
+	_, covered := ParseCodeOwners("CODEOWNERS", []byte("/docs/ @org/docs\n")).Owners("packages/web/zarf.yaml")
+	assert.False(t, covered)
+}
+
+func TestPackageMaintainers(t *testing.T) {
+	zarfYaml := &util.ZarfYaml{}
+	assert.Equal(t, []string{}, PackageMaintainers(zarfYaml))
+	zarfYaml.Extra = map[string]interface{}{"x-maintainers": []interface{}{"@org/web", " jane@example.com "}}
+	assert.Equal(t, []string{"@org/web", "jane@example.com"}, PackageMaintainers(zarfYaml))
+	zarfYaml.Metadata.Annotations = map[string]string{MaintainersAnnotation: "@org/platform, @org/web"}
+	assert.Equal(t, []string{"@org/platform", "@org/web"}, PackageMaintainers(zarfYaml))
+}
+
+func TestValidateMaintainersCodeOwners(t *testing.T) {
+	repo := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".git"), 0755))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, ".github"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(repo, ".github", "CODEOWNERS"), []byte("/packages/web/ @org/web\n/packages/db/ @org/data\n"), 0644))
+	web := filepath.Join(repo, "packages", "web")
+	writePackage(t, web, "  - name: web\n")
+	db := filepath.Join(repo, "packages", "db")
+	writePackage(t, db, "  - name: db\n")
+	cache := filepath.Join(repo, "packages", "cache")
+	writePackage(t, cache, "  - name: cache\n")
+
+	validate := func(v *PackageValidator, path string, maintainers string) []Finding {
+		pkg, err := LoadPackageContext(path)
+		require.NoError(t, err)
+		if maintainers != "" {
+			pkg.ZarfYaml.Metadata.Annotations = map[string]string{MaintainersAnnotation: maintainers}
+		}
+		result := &ValidationResult{}
+		require.NoError(t, v.validateMaintainers(pkg, result))
+		return result.Findings
+	}
+
+	// Declared maintainers are always cross-checked with CODEOWNERS
+	v := NewPackageValidator()
+	assert.Empty(t, validate(v, web, ""))
+	assert.Empty(t, validate(v, web, "@org/platform, @org/web"))
+	assert.Equal(t, []Finding{
+		{RuleID: "maintainers-codeowners", Severity: SeverityWarning, Message: "None of the maintainers @org/web are owners of the package in .github/CODEOWNERS: @org/data"},
+	}, validate(v, db, "@org/web"))
+
+	v.RequireMaintainers = true
+	assert.Equal(t, []Finding{
+		{RuleID: "maintainers-missing", Severity: SeverityWarning, Message: "Package declares no maintainers, list the team responsible for it in the 'maintainers' annotation of metadata"},
+		{RuleID: "maintainers-codeowners", Severity: SeverityWarning, Message: "Package has no owners in .github/CODEOWNERS, add an entry for its directory"},
+	}, validate(v, cache, ""))
+
+	// Failures are routed to the maintainers, else the code owners
+	assert.Equal(t, []string{"@org/web"}, PackageOwners(web))
+	assert.Empty(t, PackageOwners(cache))
+	report := NewLintReport([]*ValidationResult{{PackagePath: web, Findings: []Finding{{RuleID: "package-name", Severity: SeverityError, Message: "Missing package name"}}}})
+	assert.Equal(t, []string{"@org/web"}, report.Packages[0].Owners)
+	assert.Contains(t, report.Markdown(), "</summary>\n\nOwners: @org/web\n\n| Severity |")
+}
@@ -0,0 +1,197 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// PackageDeployment is the outcome of deploying one package with DeployPackagesInParallel
+type PackageDeployment struct {
+	PackagePath string
+	Results     []*DeploymentResult
+	Err         error
+	// Skipped is set if the package was not deployed because ctx expired
+	Skipped bool
+	// Output is the streamed output of zarf and kubectl, collected while the package
+	// was deployed so the output of concurrent deployments is not interleaved
+	Output string
+}
+
+// DeployPackagesInParallel deploys up to parallelism packages at the same time. A
+// package is started once the packages it depends on are done, whether they succeeded
+// or not; dependencies maps packages to the packages they depend on, see
+// DependencyGraph.DeployDependencies. Packages are started in the given order as far
+// as their dependencies allow. done is called for every package when it is done, one
+// package at a time.
+func (d *PackageDeployer) DeployPackagesInParallel(ctx context.Context, packagePaths []string, dependencies map[string][]string, parallelism int, done func(PackageDeployment)) {
+	if parallelism < 1 {
+		parallelism = 1
+	}
+	locks := newDeployLocks()
+	finished := make(map[string]chan struct{})
+	for _, path := range packagePaths {
+		finished[filepath.Clean(path)] = make(chan struct{})
+	}
+
+	slots := make(chan struct{}, parallelism)
+	deployments := make(chan PackageDeployment)
+	for _, path := range packagePaths {
+		go func(path string) {
+			defer close(finished[filepath.Clean(path)])
+			deployment := PackageDeployment{PackagePath: path}
+			defer func() { deployments <- deployment }()
+
+			for _, dep := range dependencies[filepath.Clean(path)] {
+				if ch, ok := finished[dep]; ok {
+					select {
+					case <-ch:
+					case <-ctx.Done():
+					}
+				}
+			}
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				deployment.Skipped = true
+				return
+			}
+			defer func() { <-slots }()
+
+			// Each package gets its own deployer sharing the locks, so its output can
+			// be collected separately
+			var output bytes.Buffer
+			packageDeployer := *d
+			packageDeployer.locks = locks
+			if d.StreamOutput {
+				packageDeployer.output = &output
+			}
+			deployment.Results, deployment.Err = packageDeployer.DeployPackage(ctx, path)
+			deployment.Output = output.String()
+		}(path)
+	}
+
+	for range packagePaths {
+		done(<-deployments)
+	}
+}
+
+// deployLocks coordinates concurrent deployments. Deployments of packages with
+// cluster-scoped resources run exclusively, and a namespace is used by one deployment
+// at a time.
+type deployLocks struct {
+	cluster sync.RWMutex
+
+	mu         sync.Mutex
+	namespaces map[string]*sync.Mutex
+}
+
+func newDeployLocks() *deployLocks {
+	return &deployLocks{namespaces: map[string]*sync.Mutex{}}
+}
+
+// acquire blocks until the deployment may run and returns the function releasing
+// the locks. Namespaces are locked in sorted order so deployments cannot deadlock.
+func (l *deployLocks) acquire(exclusive bool, namespaces []string) func() {
+	if exclusive {
+		l.cluster.Lock()
+	} else {
+		l.cluster.RLock()
+	}
+
+	sorted := append([]string{}, namespaces...)
+	sort.Strings(sorted)
+	var held []*sync.Mutex
+	for _, namespace := range sorted {
+		l.mu.Lock()
+		lock, ok := l.namespaces[namespace]
+		if !ok {
+			lock = &sync.Mutex{}
+			l.namespaces[namespace] = lock
+		}
+		l.mu.Unlock()
+		lock.Lock()
+		held = append(held, lock)
+	}
+
+	return func() {
+		for i := len(held) - 1; i >= 0; i-- {
+			held[i].Unlock()
+		}
+		if exclusive {
+			l.cluster.Unlock()
+		} else {
+			l.cluster.RUnlock()
+		}
+	}
+}
+
+var crdPattern = regexp.MustCompile(`(?m)^kind:\s*["']?CustomResourceDefinition\b`)
+
+// installsCRDs reports whether the local manifests or charts of a package contain
+// custom resource definitions
+func installsCRDs(packagePath string, zarfYaml *util.ZarfYaml) bool {
+	for _, component := range zarfYaml.Components {
+		for _, manifest := range component.Manifests {
+			for _, file := range manifest.Files {
+				if isRemoteReference(file) {
+					continue
+				}
+				content, err := os.ReadFile(filepath.Join(packagePath, file))
+				if err == nil && crdPattern.Match(content) {
+					return true
+				}
+			}
+		}
+		for _, chart := range component.Charts {
+			if chart.LocalPath == "" {
+				continue
+			}
+			if stat, err := os.Stat(filepath.Join(packagePath, chart.LocalPath, "crds")); err == nil && stat.IsDir() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// WaitForCluster waits up to timeout for the cluster to be reachable, for clusters
+// that are still starting when zt is run
+func (d *PackageDeployer) WaitForCluster(ctx context.Context, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	for {
+		err := d.checkKubernetesConnection(ctx)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		select {
+		case <-time.After(probeInterval):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
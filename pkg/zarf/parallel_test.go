@@ -0,0 +1,162 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeployPackagesInParallel(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `case "$1 $2" in
+"package create") touch "$6/zarf-package-$(basename "$PWD")-amd64.tar.zst" ;;
+"package deploy")
+	pkg=$(basename "$3" | cut -d- -f3)
+	echo "deploying $pkg"
+	echo "start $pkg" >> "$ZT_TEST_CALLS"
+	sleep 0.3
+	echo "end $pkg" >> "$ZT_TEST_CALLS" ;;
+esac
+exit 0`,
+		"kubectl": "exit 0",
+	})
+
+	dir := t.TempDir()
+	var packages []string
+	for _, name := range []string{"a", "b", "c"} {
+		packageDir := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(packageDir, 0755))
+		zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: " + name + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+		packages = append(packages, packageDir)
+	}
+	// b depends on a, c can be deployed at any time
+	dependencies := map[string][]string{packages[1]: {packages[0]}}
+
+	d := NewPackageDeployer()
+	d.StreamOutput = true
+	var deployments []PackageDeployment
+	d.DeployPackagesInParallel(context.Background(), packages, dependencies, 2, func(deployment PackageDeployment) {
+		deployments = append(deployments, deployment)
+	})
+
+	require.Len(t, deployments, 3)
+	for _, deployment := range deployments {
+		require.NoError(t, deployment.Err)
+		require.Len(t, deployment.Results, 1)
+		assert.True(t, deployment.Results[0].Success, deployment.Results[0].Errors)
+		// The output of each package is collected separately
+		name := filepath.Base(deployment.PackagePath)
+		assert.Contains(t, deployment.Output, "deploying "+name)
+		for _, other := range []string{"a", "b", "c"} {
+			if other != name {
+				assert.NotContains(t, deployment.Output, "deploying "+other)
+			}
+		}
+	}
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	events := strings.Split(strings.TrimSpace(string(content)), "\n")
+	index := func(event string) int {
+		for i, e := range events {
+			if e == event {
+				return i
+			}
+		}
+		t.Fatalf("missing event %q in %v", event, events)
+		return -1
+	}
+	// a and c are deployed at the same time, b only after a is done
+	assert.Less(t, index("start c"), index("end a"))
+	assert.Less(t, index("end a"), index("start b"))
+}
+
+func TestDeployPackagesInParallelSkipped(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	var deployments []PackageDeployment
+	NewPackageDeployer().DeployPackagesInParallel(ctx, []string{"a", "b"}, nil, 2, func(deployment PackageDeployment) {
+		deployments = append(deployments, deployment)
+	})
+	require.Len(t, deployments, 2)
+	assert.True(t, deployments[0].Skipped)
+	assert.True(t, deployments[1].Skipped)
+}
+
+func TestDeployLocks(t *testing.T) {
+	locks := newDeployLocks()
+	release := locks.acquire(false, []string{"podinfo"})
+
+	// Other namespaces can be used at the same time
+	acquired := make(chan struct{})
+	go func() {
+		locks.acquire(false, []string{"redis"})()
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("deployment to another namespace was blocked")
+	}
+
+	// The same namespace and exclusive access wait for the release
+	for _, acquire := range []func() func(){
+		func() func() { return locks.acquire(false, []string{"podinfo"}) },
+		func() func() { return locks.acquire(true, nil) },
+	} {
+		acquired := make(chan func())
+		go func() { acquired <- acquire() }()
+		select {
+		case <-acquired:
+			t.Fatal("lock was acquired while held")
+		case <-time.After(50 * time.Millisecond):
+		}
+		release()
+		(<-acquired)()
+		release = locks.acquire(false, []string{"podinfo"})
+	}
+	release()
+}
+
+func TestInstallsCRDs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crd.yaml"), []byte("apiVersion: apiextensions.k8s.io/v1\nkind: CustomResourceDefinition\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "deploy.yaml"), []byte("apiVersion: apps/v1\nkind: Deployment\n"), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "chart", "crds"), 0755))
+
+	zarfYaml := func(manifest, chart string) *util.ZarfYaml {
+		return &util.ZarfYaml{Components: []util.ZarfComponent{{
+			Name:      "app",
+			Manifests: []util.ZarfManifest{{Name: "app", Files: []string{manifest}}},
+			Charts:    []util.ZarfChart{{Name: "app", LocalPath: chart}},
+		}}}
+	}
+	assert.True(t, installsCRDs(dir, zarfYaml("crd.yaml", "")))
+	assert.False(t, installsCRDs(dir, zarfYaml("deploy.yaml", "")))
+	assert.True(t, installsCRDs(dir, zarfYaml("deploy.yaml", "chart")))
+}
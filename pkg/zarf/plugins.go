@@ -0,0 +1,175 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"gopkg.in/yaml.v2"
+)
+
+// PluginPrefix is the name prefix of executables run as validation plugins
+const PluginPrefix = "zt-validate-"
+
+// Plugin is an executable validating packages, run with the package as JSON on stdin.
+// It writes its findings as JSON to stdout.
+type Plugin struct {
+	Name string // Name of the plugin, the executable name without PluginPrefix
+	Path string // Path of the executable
+}
+
+// PluginInput is the document written to the stdin of a plugin
+type PluginInput struct {
+	Path     string      `json:"path"`     // Directory of the package
+	Content  string      `json:"content"`  // Raw content of zarf.yaml
+	ZarfYaml interface{} `json:"zarfYaml"` // zarf.yaml converted to JSON
+}
+
+// PluginOutput is the document a plugin writes to stdout
+type PluginOutput struct {
+	Findings []Finding `json:"findings"`
+}
+
+// DiscoverPlugins finds the plugins in dirs followed by the directories of PATH. When
+// several executables have the same name, the first one found is used.
+func DiscoverPlugins(dirs []string) ([]Plugin, error) {
+	for _, dir := range dirs {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("plugins directory %q does not exist", dir)
+		}
+	}
+	dirs = append(append([]string{}, dirs...), filepath.SplitList(os.Getenv("PATH"))...)
+
+	var plugins []Plugin
+	seen := map[string]bool{}
+	for _, dir := range dirs {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			// PATH commonly contains directories that do not exist
+			continue
+		}
+		for _, entry := range entries {
+			name := strings.TrimSuffix(entry.Name(), ".exe")
+			if !strings.HasPrefix(name, PluginPrefix) || name == PluginPrefix || seen[name] {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			info, err := os.Stat(path)
+			if err != nil || info.IsDir() || info.Mode()&0111 == 0 {
+				continue
+			}
+			seen[name] = true
+			plugins = append(plugins, Plugin{Name: strings.TrimPrefix(name, PluginPrefix), Path: path})
+		}
+	}
+	sort.Slice(plugins, func(i, j int) bool { return plugins[i].Name < plugins[j].Name })
+	return plugins, nil
+}
+
+// Run runs the plugin against the package and returns its findings. Findings without
+// a rule ID are attributed to the plugin.
+func (p Plugin) Run(ctx context.Context, pkg *PackageContext) ([]Finding, error) {
+	var document interface{}
+	if err := yaml.Unmarshal(pkg.Content, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse 'zarf.yaml': %w", err)
+	}
+	input, err := json.Marshal(PluginInput{Path: pkg.Path, Content: string(pkg.Content), ZarfYaml: toJSONValue(document)})
+	if err != nil {
+		return nil, err
+	}
+
+	cmd, err := exec.NewProcessExecutor(false).WithEnv("ZT_PACKAGE_PATH="+pkg.Path).CreateProcess(ctx, p.Path)
+	if err != nil {
+		return nil, err
+	}
+	var stdout, stderr bytes.Buffer
+	cmd.Dir = pkg.Path
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return nil, fmt.Errorf("%w: %s", err, message)
+		}
+		return nil, err
+	}
+
+	var output PluginOutput
+	if err := json.Unmarshal(stdout.Bytes(), &output); err != nil {
+		return nil, fmt.Errorf("invalid output: %w", err)
+	}
+	for i := range output.Findings {
+		finding := &output.Findings[i]
+		if finding.RuleID == "" {
+			finding.RuleID = p.Name
+		}
+		switch finding.Severity {
+		case SeverityError, SeverityWarning, SeverityInfo:
+		case "":
+			finding.Severity = SeverityError
+		default:
+			return nil, fmt.Errorf("invalid severity %q of finding %q", finding.Severity, finding.Message)
+		}
+	}
+	return output.Findings, nil
+}
+
+// validatePlugins runs the plugins against the package. A plugin that fails or
+// writes invalid output is reported as an error finding.
+func (v *PackageValidator) validatePlugins(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	for _, plugin := range v.Plugins {
+		findings, err := plugin.Run(ctx, pkg)
+		if err != nil {
+			result.AddError(plugin.Name, fmt.Sprintf("plugin %s failed: %v", plugin.Name, err))
+			continue
+		}
+		for _, finding := range findings {
+			result.AddFinding(finding)
+		}
+	}
+	return nil
+}
+
+// toJSONValue converts the map types produced by yaml.v2 into maps keyed by string,
+// so the document can be encoded as JSON
+func toJSONValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[interface{}]interface{}:
+		converted := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			converted[fmt.Sprintf("%v", key)] = toJSONValue(item)
+		}
+		return converted
+	case []interface{}:
+		converted := make([]interface{}, len(v))
+		for i, item := range v {
+			converted[i] = toJSONValue(item)
+		}
+		return converted
+	default:
+		return v
+	}
+}
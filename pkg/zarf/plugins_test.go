@@ -0,0 +1,82 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiscoverPlugins(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		"zt-validate-naming": "exit 0",
+		"zt-validate-labels": "exit 0",
+		"zt-other":           "exit 0",
+	})
+
+	// Plugins in the plugins directory take precedence over PATH
+	pluginsDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(pluginsDir, "zt-validate-naming"), []byte("#!/bin/sh\n"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(pluginsDir, "zt-validate-notes"), []byte("not executable"), 0644))
+
+	plugins, err := DiscoverPlugins([]string{pluginsDir})
+	require.NoError(t, err)
+	require.Len(t, plugins, 2)
+	assert.Equal(t, "labels", plugins[0].Name)
+	assert.Equal(t, Plugin{Name: "naming", Path: filepath.Join(pluginsDir, "zt-validate-naming")}, plugins[1])
+
+	_, err = DiscoverPlugins([]string{filepath.Join(pluginsDir, "missing")})
+	assert.Error(t, err)
+}
+
+func TestValidatePlugins(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		// Reports the package name read from the zarf.yaml on stdin
+		"zt-validate-naming": `input=$(cat)
+case "$input" in
+*'"name":"podinfo"'*) echo '{"findings":[{"severity":"warning","message":"name lacks team prefix","file":"zarf.yaml","line":3},{"ruleId":"naming/kind","severity":"info","message":"ok"}]}' ;;
+*) echo '{"findings":[]}' ;;
+esac`,
+		"zt-validate-broken":  `echo "missing policy file" >&2; exit 3`,
+		"zt-validate-garbage": `echo "not json"`,
+	})
+
+	packageDir := t.TempDir()
+	content := []byte("kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\n")
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), content, 0644))
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	plugins, err := DiscoverPlugins(nil)
+	require.NoError(t, err)
+	v := NewPackageValidator()
+	v.Plugins = plugins
+	result := &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validatePlugins(context.Background(), pkg, result))
+
+	require.Len(t, result.Findings, 4)
+	assert.Equal(t, "broken", result.Findings[0].RuleID)
+	assert.Contains(t, result.Findings[0].Message, "missing policy file")
+	assert.Equal(t, "garbage", result.Findings[1].RuleID)
+	assert.Contains(t, result.Findings[1].Message, "invalid output")
+	assert.Equal(t, Finding{RuleID: "naming", Severity: SeverityWarning, Message: "name lacks team prefix", File: "zarf.yaml", Line: 3}, result.Findings[2])
+	assert.Equal(t, Finding{RuleID: "naming/kind", Severity: SeverityInfo, Message: "ok"}, result.Findings[3])
+	assert.False(t, result.Valid)
+}
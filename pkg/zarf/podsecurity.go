@@ -0,0 +1,231 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pod Security Standards levels, see
+// https://kubernetes.io/docs/concepts/security/pod-security-standards/
+const (
+	PSSPrivileged = "privileged" // Unrestricted, no checks
+	PSSBaseline   = "baseline"   // Prevents known privilege escalations
+	PSSRestricted = "restricted" // Follows pod hardening best practices
+)
+
+// baselineCapabilities may be added to containers under the baseline level
+var baselineCapabilities = map[string]bool{
+	"AUDIT_WRITE": true, "CHOWN": true, "DAC_OVERRIDE": true, "FOWNER": true, "FSETID": true,
+	"KILL": true, "MKNOD": true, "NET_BIND_SERVICE": true, "SETFCAP": true, "SETGID": true,
+	"SETPCAP": true, "SETUID": true, "SYS_CHROOT": true,
+}
+
+// safeSysctls may be set under the baseline level
+var safeSysctls = map[string]bool{
+	"kernel.shm_rmid_forced": true, "net.ipv4.ip_local_port_range": true, "net.ipv4.ip_unprivileged_port_start": true,
+	"net.ipv4.tcp_syncookies": true, "net.ipv4.ping_group_range": true, "net.ipv4.ip_local_reserved_ports": true,
+	"net.ipv4.tcp_keepalive_time": true, "net.ipv4.tcp_fin_timeout": true, "net.ipv4.tcp_keepalive_intvl": true,
+	"net.ipv4.tcp_keepalive_probes": true,
+}
+
+// restrictedVolumeTypes are the volume types allowed under the restricted level
+var restrictedVolumeTypes = []string{
+	"configMap", "csi", "downwardAPI", "emptyDir", "ephemeral", "persistentVolumeClaim", "projected", "secret",
+}
+
+// podSecurityViolation is a pod spec field violating a Pod Security Standards level
+type podSecurityViolation struct {
+	RuleID  string
+	Level   string
+	Message string
+}
+
+// ValidatePSSLevel returns an error unless level is a Pod Security Standards level
+func ValidatePSSLevel(level string) error {
+	switch level {
+	case PSSPrivileged, PSSBaseline, PSSRestricted:
+		return nil
+	}
+	return fmt.Errorf("invalid Pod Security Standards level %q, must be one of: %s, %s, %s", level, PSSPrivileged, PSSBaseline, PSSRestricted)
+}
+
+// checkPodSecurity evaluates the pod spec of a workload against level. Objects without
+// a pod spec, such as services and custom resources, have no violations.
+func checkPodSecurity(doc map[string]interface{}, level string) []podSecurityViolation {
+	if level == PSSPrivileged {
+		return nil
+	}
+	apiVersion, _ := doc["apiVersion"].(string)
+	kind, _ := doc["kind"].(string)
+	schema, ok := builtinKinds[apiVersion][kind]
+	if !ok || schema.PodSpec == "" {
+		return nil
+	}
+	value, _ := lookupPath(doc, schema.PodSpec)
+	spec, ok := toStringMap(value)
+	if !ok {
+		return nil
+	}
+
+	var violations []podSecurityViolation
+	add := func(ruleID, level, format string, args ...interface{}) {
+		violations = append(violations, podSecurityViolation{RuleID: ruleID, Level: level, Message: fmt.Sprintf(format, args...)})
+	}
+	podContext, _ := toStringMap(spec["securityContext"])
+
+	// Baseline
+	for _, field := range []string{"hostNetwork", "hostPID", "hostIPC"} {
+		if enabled, _ := spec[field].(bool); enabled {
+			add("pss-host-namespaces", PSSBaseline, "must not set %s", field)
+		}
+	}
+	for _, volume := range listOfMaps(spec["volumes"]) {
+		if _, ok := volume["hostPath"]; ok {
+			add("pss-host-path", PSSBaseline, "volume '%v' must not use hostPath", volume["name"])
+		}
+	}
+	if profile := seccompProfileType(podContext); profile == "Unconfined" {
+		add("pss-seccomp", PSSBaseline, "securityContext.seccompProfile.type must not be Unconfined")
+	}
+	for _, sysctl := range listOfMaps(podContext["sysctls"]) {
+		if name := fmt.Sprintf("%v", sysctl["name"]); !safeSysctls[name] {
+			add("pss-sysctls", PSSBaseline, "sysctl %s is not allowed", name)
+		}
+	}
+
+	containers := podContainers(spec)
+	for _, container := range containers {
+		ref := fmt.Sprintf("container '%v'", container["name"])
+		securityContext, _ := toStringMap(container["securityContext"])
+		if privileged, _ := securityContext["privileged"].(bool); privileged {
+			add("pss-privileged", PSSBaseline, "%s must not be privileged", ref)
+		}
+		capabilities, _ := toStringMap(securityContext["capabilities"])
+		for _, capability := range stringList(capabilities["add"]) {
+			if !baselineCapabilities[strings.TrimPrefix(capability, "CAP_")] {
+				add("pss-capabilities", PSSBaseline, "%s must not add capability %s", ref, capability)
+			}
+		}
+		for _, port := range listOfMaps(container["ports"]) {
+			if hostPort, _ := port["hostPort"].(int); hostPort != 0 {
+				add("pss-host-ports", PSSBaseline, "%s must not use hostPort %d", ref, hostPort)
+			}
+		}
+		if profile := seccompProfileType(securityContext); profile == "Unconfined" {
+			add("pss-seccomp", PSSBaseline, "%s securityContext.seccompProfile.type must not be Unconfined", ref)
+		}
+		if procMount, _ := securityContext["procMount"].(string); procMount != "" && procMount != "Default" {
+			add("pss-proc-mount", PSSBaseline, "%s must not set procMount %s", ref, procMount)
+		}
+	}
+	if level != PSSRestricted {
+		return violations
+	}
+
+	// Restricted
+	allowed := map[string]bool{}
+	for _, volumeType := range restrictedVolumeTypes {
+		allowed[volumeType] = true
+	}
+	for _, volume := range listOfMaps(spec["volumes"]) {
+		for field := range volume {
+			if field != "name" && field != "hostPath" && !allowed[field] {
+				add("pss-volume-types", PSSRestricted, "volume '%v' must not use %s", volume["name"], field)
+			}
+		}
+	}
+	if runAsUser, ok := podContext["runAsUser"].(int); ok && runAsUser == 0 {
+		add("pss-run-as-user", PSSRestricted, "securityContext.runAsUser must not be 0")
+	}
+	podNonRoot, _ := podContext["runAsNonRoot"].(bool)
+	podSeccomp := seccompProfileType(podContext)
+	for _, container := range containers {
+		ref := fmt.Sprintf("container '%v'", container["name"])
+		securityContext, _ := toStringMap(container["securityContext"])
+		if escalation, ok := securityContext["allowPrivilegeEscalation"].(bool); !ok || escalation {
+			add("pss-privilege-escalation", PSSRestricted, "%s must set securityContext.allowPrivilegeEscalation to false", ref)
+		}
+		nonRoot, set := securityContext["runAsNonRoot"].(bool)
+		if set && !nonRoot || !set && !podNonRoot {
+			add("pss-run-as-non-root", PSSRestricted, "%s must set securityContext.runAsNonRoot to true", ref)
+		}
+		if runAsUser, ok := securityContext["runAsUser"].(int); ok && runAsUser == 0 {
+			add("pss-run-as-user", PSSRestricted, "%s securityContext.runAsUser must not be 0", ref)
+		}
+		profile := seccompProfileType(securityContext)
+		if profile == "" {
+			profile = podSeccomp
+		}
+		if profile != "RuntimeDefault" && profile != "Localhost" && profile != "Unconfined" {
+			add("pss-seccomp", PSSRestricted, "%s must set securityContext.seccompProfile.type to RuntimeDefault or Localhost", ref)
+		}
+		capabilities, _ := toStringMap(securityContext["capabilities"])
+		dropsAll := false
+		for _, capability := range stringList(capabilities["drop"]) {
+			dropsAll = dropsAll || capability == "ALL"
+		}
+		if !dropsAll {
+			add("pss-capabilities", PSSRestricted, "%s must drop ALL capabilities", ref)
+		}
+		for _, capability := range stringList(capabilities["add"]) {
+			if name := strings.TrimPrefix(capability, "CAP_"); name != "NET_BIND_SERVICE" && baselineCapabilities[name] {
+				add("pss-capabilities", PSSRestricted, "%s may only add capability NET_BIND_SERVICE, not %s", ref, capability)
+			}
+		}
+	}
+	return violations
+}
+
+// podContainers returns the containers, init containers and ephemeral containers of
+// a pod spec
+func podContainers(spec map[string]interface{}) []map[string]interface{} {
+	var containers []map[string]interface{}
+	for _, field := range []string{"initContainers", "containers", "ephemeralContainers"} {
+		containers = append(containers, listOfMaps(spec[field])...)
+	}
+	return containers
+}
+
+// seccompProfileType returns the seccomp profile type of a pod or container security
+// context, or an empty string
+func seccompProfileType(securityContext map[string]interface{}) string {
+	profile, _ := toStringMap(securityContext["seccompProfile"])
+	profileType, _ := profile["type"].(string)
+	return profileType
+}
+
+// listOfMaps returns the objects of a decoded YAML list, skipping other items
+func listOfMaps(value interface{}) []map[string]interface{} {
+	items, _ := value.([]interface{})
+	var maps []map[string]interface{}
+	for _, item := range items {
+		if m, ok := toStringMap(item); ok {
+			maps = append(maps, m)
+		}
+	}
+	return maps
+}
+
+// stringList returns the items of a decoded YAML list as strings
+func stringList(value interface{}) []string {
+	items, _ := value.([]interface{})
+	var list []string
+	for _, item := range items {
+		list = append(list, fmt.Sprintf("%v", item))
+	}
+	return list
+}
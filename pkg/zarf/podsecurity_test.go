@@ -0,0 +1,179 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+func TestCheckPodSecurity(t *testing.T) {
+	hardened := `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: podinfo
+spec:
+  template:
+    spec:
+      securityContext:
+        runAsNonRoot: true
+        seccompProfile:
+          type: RuntimeDefault
+      volumes:
+        - name: config
+          configMap:
+            name: podinfo
+      containers:
+        - name: podinfo
+          image: ghcr.io/stefanprodan/podinfo:6.4.0
+          securityContext:
+            allowPrivilegeEscalation: false
+            capabilities:
+              drop: [ALL]
+              add: [NET_BIND_SERVICE]
+`
+	privileged := `apiVersion: v1
+kind: Pod
+metadata:
+  name: agent
+spec:
+  hostNetwork: true
+  securityContext:
+    sysctls:
+      - name: kernel.msgmax
+        value: "65536"
+  volumes:
+    - name: root
+      hostPath:
+        path: /
+    - name: data
+      nfs:
+        server: nfs.example.com
+        path: /data
+  initContainers:
+    - name: setup
+      image: busybox
+      securityContext:
+        runAsUser: 0
+        capabilities:
+          add: [CHOWN]
+  containers:
+    - name: agent
+      image: agent
+      ports:
+        - containerPort: 9100
+          hostPort: 9100
+      securityContext:
+        privileged: true
+        procMount: Unmasked
+        seccompProfile:
+          type: Unconfined
+        capabilities:
+          add: [SYS_ADMIN]
+`
+
+	tests := []struct {
+		name     string
+		manifest string
+		level    string
+		ruleIDs  []string
+	}{
+		{name: "hardened restricted", manifest: hardened, level: PSSRestricted},
+		{name: "privileged level", manifest: privileged, level: PSSPrivileged},
+		{name: "not a workload", manifest: "apiVersion: v1\nkind: Service\nmetadata:\n  name: podinfo\n", level: PSSRestricted},
+		{
+			name:     "privileged baseline",
+			manifest: privileged,
+			level:    PSSBaseline,
+			ruleIDs:  []string{"pss-host-namespaces", "pss-host-path", "pss-sysctls", "pss-privileged", "pss-capabilities", "pss-host-ports", "pss-seccomp", "pss-proc-mount"},
+		},
+		{
+			name:     "privileged restricted",
+			manifest: privileged,
+			level:    PSSRestricted,
+			ruleIDs: []string{"pss-host-namespaces", "pss-host-path", "pss-sysctls", "pss-privileged", "pss-capabilities", "pss-host-ports", "pss-seccomp", "pss-proc-mount",
+				"pss-volume-types",
+				"pss-privilege-escalation", "pss-run-as-non-root", "pss-run-as-user", "pss-seccomp", "pss-capabilities", "pss-capabilities",
+				"pss-privilege-escalation", "pss-run-as-non-root", "pss-capabilities"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var doc map[string]interface{}
+			require.NoError(t, yaml.Unmarshal([]byte(tt.manifest), &doc))
+			var ruleIDs []string
+			for _, violation := range checkPodSecurity(doc, tt.level) {
+				ruleIDs = append(ruleIDs, violation.RuleID)
+			}
+			assert.Equal(t, tt.ruleIDs, ruleIDs)
+		})
+	}
+}
+
+func TestValidatePodSecurity(t *testing.T) {
+	// helm renders a pod running as root
+	fakeCommands(t, map[string]string{
+		"helm": `printf 'apiVersion: v1\nkind: Pod\nmetadata:\n  name: %s\nspec:\n  containers:\n    - name: app\n      image: app\n      securityContext:\n        runAsUser: 0\n' "$2"`,
+	})
+
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+components:
+  - name: podinfo
+    charts:
+      - name: podinfo
+        localPath: chart
+        namespace: podinfo
+    manifests:
+      - name: agent
+        files:
+          - agent.yaml
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	require.NoError(t, os.MkdirAll(filepath.Join(packageDir, "chart"), 0755))
+	agent := "apiVersion: apps/v1\nkind: DaemonSet\nmetadata:\n  name: agent\nspec:\n  template:\n    spec:\n      hostPID: true\n      containers:\n        - name: agent\n          image: agent\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "agent.yaml"), []byte(agent), 0644))
+
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validatePodSecurity(context.Background(), pkg, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "pss-host-namespaces", Severity: SeverityWarning, File: "agent.yaml",
+			Message: "Component 'podinfo' manifest agent.yaml: DaemonSet/agent must not set hostPID (baseline)"},
+	}, result.Findings)
+
+	v.PSSLevel = PSSRestricted
+	result = &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validatePodSecurity(context.Background(), pkg, result))
+	var messages []string
+	for _, finding := range result.Findings {
+		messages = append(messages, finding.Message)
+	}
+	assert.Contains(t, messages, "Component 'podinfo' chart chart: Pod/podinfo container 'app' securityContext.runAsUser must not be 0 (restricted)")
+	assert.Contains(t, messages, "Component 'podinfo' manifest agent.yaml: DaemonSet/agent container 'agent' must drop ALL capabilities (restricted)")
+	assert.True(t, result.Valid)
+}
@@ -0,0 +1,86 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// The porcelain output of 'zt lint --porcelain' and 'zt install --porcelain' is a
+// stable, line-oriented format for scripts. Each line is a record type followed by
+// tab-separated fields; new record types and trailing fields may be added, but the
+// existing fields never change.
+//
+//	package     <path> <passed|failed> <errors> <warnings>
+//	finding     <path> <severity> <rule> <file>[:<line>] <message>
+//	deployment  <path> <passed|failed|timeout> <cluster> <variable set> <components> <seconds>
+//	test        <path> <passed|failed> <cluster> <test> <message>
+
+// Porcelain renders the report in the porcelain format
+func (r *LintReport) Porcelain() string {
+	var b strings.Builder
+	for _, pkg := range r.Packages {
+		errors, warnings := countFindings(pkg.Findings)
+		porcelainLine(&b, "package", pkg.Path, passedText(pkg.Valid), fmt.Sprint(errors), fmt.Sprint(warnings))
+		for _, finding := range pkg.Findings {
+			if finding.Severity == SeverityInfo {
+				continue
+			}
+			location := finding.File
+			if finding.File != "" && finding.Line > 0 {
+				location = fmt.Sprintf("%s:%d", finding.File, finding.Line)
+			}
+			porcelainLine(&b, "finding", pkg.Path, finding.Severity, finding.RuleID, location, finding.Message)
+		}
+	}
+	return b.String()
+}
+
+// Porcelain renders the report in the porcelain format
+func (r *InstallReport) Porcelain() string {
+	var b strings.Builder
+	for _, deployment := range r.Deployments {
+		status := passedText(deployment.Success)
+		if deployment.TimedOut {
+			status = "timeout"
+		}
+		porcelainLine(&b, "deployment", deployment.Path, status, deployment.Cluster, deployment.VariableSet,
+			deployment.Components, fmt.Sprintf("%.1f", deployment.Duration))
+		for _, test := range deployment.Tests {
+			porcelainLine(&b, "test", deployment.Path, passedText(test.Success), deployment.Cluster, test.Name, test.Message)
+		}
+	}
+	return b.String()
+}
+
+func passedText(passed bool) string {
+	if passed {
+		return "passed"
+	}
+	return "failed"
+}
+
+var porcelainReplacer = strings.NewReplacer("\t", " ", "\r\n", " ", "\n", " ", "\r", " ")
+
+// porcelainLine writes a record, replacing tabs and line breaks in the fields so
+// every record stays on a single line
+func porcelainLine(b *strings.Builder, fields ...string) {
+	for i, field := range fields {
+		fields[i] = porcelainReplacer.Replace(field)
+	}
+	b.WriteString(strings.Join(fields, "\t"))
+	b.WriteString("\n")
+}
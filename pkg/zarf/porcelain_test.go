@@ -0,0 +1,52 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLintReportPorcelain(t *testing.T) {
+	failing := &ValidationResult{PackagePath: "packages/a", Valid: true}
+	failing.AddFinding(Finding{RuleID: "file-reference", Severity: SeverityError, Message: "missing file\ta.yaml", File: "zarf.yaml", Line: 12})
+	failing.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+	failing.AddInfo("zarf-lint", "Validated using Zarf CLI")
+	passing := &ValidationResult{PackagePath: "packages/b", Valid: true}
+
+	assert.Equal(t, "package\tpackages/a\tfailed\t1\t1\n"+
+		"finding\tpackages/a\terror\tfile-reference\tzarf.yaml:12\tmissing file a.yaml\n"+
+		"finding\tpackages/a\twarning\timage-pinning\t\tImage not pinned with digest - nginx:1.25\n"+
+		"package\tpackages/b\tpassed\t0\t0\n", NewLintReport([]*ValidationResult{failing, passing}).Porcelain())
+}
+
+func TestInstallReportPorcelain(t *testing.T) {
+	report := NewInstallReport([]*DeploymentResult{
+		{PackagePath: "packages/a", Success: true, DeployTime: 90 * time.Second, Cluster: "k8s-1.30"},
+		{
+			PackagePath:    "packages/b",
+			VariableSet:    "ha",
+			DeployTime:     1500 * time.Millisecond,
+			TimedOut:       true,
+			ComponentTests: []ComponentTestResult{{ComponentName: "deployment/b", Message: "expected 2 ready replicas,\ngot 1"}},
+		},
+	})
+
+	assert.Equal(t, "deployment\tpackages/a\tpassed\tk8s-1.30\t\t\t90.0\n"+
+		"deployment\tpackages/b\ttimeout\t\tha\t\t1.5\n"+
+		"test\tpackages/b\tfailed\t\tdeployment/b\texpected 2 ready replicas, got 1\n", report.Porcelain())
+}
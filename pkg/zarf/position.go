@@ -0,0 +1,170 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	quotedPattern = regexp.MustCompile(`'([^']+)'`)
+	indexPattern  = regexp.MustCompile(`\[(\d+)\]`)
+)
+
+// at returns a finding of zarf.yaml positioned at field, the location of a value in
+// the package definition such as components[0].files[1].source. If the field cannot
+// be found, the finding has no position and is located by locateFindings.
+func (pkg *PackageContext) at(field, ruleID, severity, message string) Finding {
+	finding := Finding{RuleID: ruleID, Severity: severity, Message: message}
+	if line, column := locateField(pkg.Node, field); line > 0 {
+		finding.File, finding.Line, finding.Column = "zarf.yaml", line, column
+	}
+	return finding
+}
+
+// locateField returns the 1-based position of the value at field in the document
+// node, following aliases and merge keys, or zeros
+func locateField(node *yaml.Node, field string) (int, int) {
+	if node == nil {
+		return 0, 0
+	}
+	if node.Kind == yaml.DocumentNode && len(node.Content) > 0 {
+		node = node.Content[0]
+	}
+
+	for _, segment := range strings.Split(field, ".") {
+		key, _, _ := strings.Cut(segment, "[")
+		if node = mappingValue(node, key); node == nil {
+			return 0, 0
+		}
+		for _, match := range indexPattern.FindAllStringSubmatch(segment, -1) {
+			index, _ := strconv.Atoi(match[1])
+			node = resolveAlias(node)
+			if node.Kind != yaml.SequenceNode || index >= len(node.Content) {
+				return 0, 0
+			}
+			node = node.Content[index]
+		}
+	}
+	node = resolveAlias(node)
+	return node.Line, node.Column
+}
+
+// mappingValue returns the value of key in the mapping node, including the keys it
+// merges, or nil
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	node = resolveAlias(node)
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		name, value := node.Content[i], node.Content[i+1]
+		switch {
+		case name.Value == key:
+			return value
+		case name.Tag == "!!merge":
+			if value = resolveAlias(value); value.Kind == yaml.SequenceNode {
+				merged = append(merged, value.Content...)
+			} else {
+				merged = append(merged, value)
+			}
+		}
+	}
+	// Keys of the mapping take precedence over merged ones, earlier merges over later
+	for _, source := range merged {
+		if value := mappingValue(source, key); value != nil {
+			return value
+		}
+	}
+	return nil
+}
+
+// resolveAlias returns the node an alias node refers to
+func resolveAlias(node *yaml.Node) *yaml.Node {
+	for node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+	return node
+}
+
+// locateFindings attributes findings without a position to zarf.yaml, see at for the
+// rules that know the field a finding is about. The line is derived from the values
+// named in the message: quoted names such as component names are looked up as
+// 'name:' entries first, list items before plain keys, then the last word of the
+// message (usually an image or path) is searched verbatim. Findings that cannot be
+// located point at the file without a line.
+func locateFindings(packagePath string, result *ValidationResult) {
+	content, err := os.ReadFile(filepath.Join(packagePath, "zarf.yaml"))
+	if err != nil {
+		return
+	}
+	lines := strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n")
+
+	for i := range result.Findings {
+		finding := &result.Findings[i]
+		if finding.File != "" {
+			continue
+		}
+		finding.File = "zarf.yaml"
+		finding.Line, finding.Column = locateMessage(lines, finding.Message)
+	}
+}
+
+// locateMessage returns the 1-based position of the first value of message found in
+// lines, or zeros
+func locateMessage(lines []string, message string) (int, int) {
+	var candidates []string
+	for _, match := range quotedPattern.FindAllStringSubmatch(message, -1) {
+		candidates = append(candidates, match[1])
+	}
+	if fields := strings.Fields(message); len(fields) > 1 {
+		candidates = append(candidates, strings.Trim(fields[len(fields)-1], "()"))
+	}
+
+	// List items ('- name: x') name components and charts, prefer them over metadata
+	for _, prefix := range []string{"- name:", "name:"} {
+		for _, candidate := range candidates {
+			for i, line := range lines {
+				trimmed := strings.TrimSpace(line)
+				if !strings.HasPrefix(trimmed, prefix) {
+					continue
+				}
+				value := strings.Trim(strings.TrimSpace(strings.TrimPrefix(trimmed, prefix)), `"'`)
+				if value == candidate {
+					return i + 1, strings.Index(line, "name:") + 1
+				}
+			}
+		}
+	}
+	for _, candidate := range candidates {
+		if len(candidate) < 3 {
+			continue
+		}
+		for i, line := range lines {
+			if column := strings.Index(line, candidate); column >= 0 {
+				return i + 1, column + 1
+			}
+		}
+	}
+	return 0, 0
+}
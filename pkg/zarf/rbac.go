@@ -0,0 +1,123 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// validateRBAC reports bindings to the cluster-admin role and roles granting wildcard
+// verbs or resources in the manifests, kustomizations and local charts of every
+// component
+func (v *PackageValidator) validateRBAC(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	if !v.ValidateRBAC {
+		return nil
+	}
+
+	for _, manifest := range pkg.renderedManifests(ctx) {
+		if manifest.Err != nil {
+			continue
+		}
+		for _, doc := range manifest.documents() {
+			for _, problem := range checkRBAC(doc) {
+				finding := Finding{
+					RuleID:   problem.RuleID,
+					Severity: SeverityWarning,
+					Message:  fmt.Sprintf("Component '%s' %s %s: %s", manifest.Component, manifest.Kind, manifest.Path, problem.Message),
+				}
+				if manifest.Kind == sourceManifest {
+					finding.File = manifest.Path
+				}
+				result.AddFinding(finding)
+			}
+		}
+	}
+	return nil
+}
+
+// rbacProblem is an over-permissive RBAC object
+type rbacProblem struct {
+	RuleID  string
+	Message string
+}
+
+// checkRBAC returns the over-permissions granted by a Role, ClusterRole, RoleBinding or
+// ClusterRoleBinding
+func checkRBAC(doc map[string]interface{}) []rbacProblem {
+	apiVersion, _ := doc["apiVersion"].(string)
+	if !strings.HasPrefix(apiVersion, "rbac.authorization.k8s.io/") {
+		return nil
+	}
+	kind, _ := doc["kind"].(string)
+	metadata, _ := toStringMap(doc["metadata"])
+	ref := fmt.Sprintf("%s/%v", kind, metadata["name"])
+
+	var problems []rbacProblem
+	switch kind {
+	case "RoleBinding", "ClusterRoleBinding":
+		roleRef, _ := toStringMap(doc["roleRef"])
+		if roleRef["kind"] != "ClusterRole" || roleRef["name"] != "cluster-admin" {
+			return nil
+		}
+		var subjects []string
+		for _, subject := range listOfMaps(doc["subjects"]) {
+			name := fmt.Sprintf("%v", subject["name"])
+			if namespace, ok := subject["namespace"]; ok {
+				name = fmt.Sprintf("%v/%s", namespace, name)
+			}
+			subjects = append(subjects, fmt.Sprintf("%v %s", subject["kind"], name))
+		}
+		scope := "the cluster"
+		if kind == "RoleBinding" {
+			scope = fmt.Sprintf("namespace %v", metadata["namespace"])
+		}
+		problems = append(problems, rbacProblem{"rbac-cluster-admin",
+			fmt.Sprintf("%s grants cluster-admin on %s to %s", ref, scope, strings.Join(subjects, ", "))})
+	case "Role", "ClusterRole":
+		for i, rule := range listOfMaps(doc["rules"]) {
+			if contains(stringList(rule["verbs"]), "*") {
+				problems = append(problems, rbacProblem{"rbac-wildcard-verbs",
+					fmt.Sprintf("%s rules[%d] grants all verbs on %s", ref, i, strings.Join(stringList(rule["resources"]), ", "))})
+			}
+			if contains(stringList(rule["resources"]), "*") {
+				problems = append(problems, rbacProblem{"rbac-wildcard-resources",
+					fmt.Sprintf("%s rules[%d] grants %s on all resources of API groups %s", ref, i,
+						strings.Join(stringList(rule["verbs"]), ", "), strings.Join(quoteEach(stringList(rule["apiGroups"])), ", "))})
+			}
+		}
+	}
+	return problems
+}
+
+// contains reports whether list contains value
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteEach quotes every item of list, making the empty core API group visible
+func quoteEach(list []string) []string {
+	quoted := make([]string, len(list))
+	for i, item := range list {
+		quoted[i] = fmt.Sprintf("%q", item)
+	}
+	return quoted
+}
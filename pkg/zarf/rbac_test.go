@@ -0,0 +1,101 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateRBAC(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: operator
+components:
+  - name: operator
+    manifests:
+      - name: rbac
+        files:
+          - rbac.yaml
+`
+	rbac := `apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRoleBinding
+metadata:
+  name: operator
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: cluster-admin
+subjects:
+  - kind: ServiceAccount
+    name: operator
+    namespace: operator
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: ClusterRole
+metadata:
+  name: operator
+rules:
+  - apiGroups: [""]
+    resources: [configmaps]
+    verbs: [get, list, watch]
+  - apiGroups: [apps]
+    resources: [deployments]
+    verbs: ["*"]
+  - apiGroups: ["", apps]
+    resources: ["*"]
+    verbs: [get]
+---
+apiVersion: rbac.authorization.k8s.io/v1
+kind: RoleBinding
+metadata:
+  name: operator-view
+  namespace: operator
+roleRef:
+  apiGroup: rbac.authorization.k8s.io
+  kind: ClusterRole
+  name: view
+subjects:
+  - kind: Group
+    name: developers
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "rbac.yaml"), []byte(rbac), 0644))
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validateRBAC(context.Background(), pkg, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "rbac-cluster-admin", Severity: SeverityWarning, File: "rbac.yaml",
+			Message: "Component 'operator' manifest rbac.yaml: ClusterRoleBinding/operator grants cluster-admin on the cluster to ServiceAccount operator/operator"},
+		{RuleID: "rbac-wildcard-verbs", Severity: SeverityWarning, File: "rbac.yaml",
+			Message: "Component 'operator' manifest rbac.yaml: ClusterRole/operator rules[1] grants all verbs on deployments"},
+		{RuleID: "rbac-wildcard-resources", Severity: SeverityWarning, File: "rbac.yaml",
+			Message: `Component 'operator' manifest rbac.yaml: ClusterRole/operator rules[2] grants get on all resources of API groups "", "apps"`},
+	}, result.Findings)
+
+	v.ValidateRBAC = false
+	result = &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validateRBAC(context.Background(), pkg, result))
+	assert.Empty(t, result.Findings)
+}
@@ -0,0 +1,92 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// removalKinds are the kinds of resources that must not remain in the namespaces
+// created by a deployment once its package was removed
+const removalKinds = "deployments,statefulsets,daemonsets,replicasets,jobs,cronjobs,pods,services,persistentvolumeclaims"
+
+// removalGracePeriod is how long resources may take to disappear after the package was
+// removed, e.g. pods that are still terminating
+var removalGracePeriod = time.Minute
+
+// checkRemoval waits up to removalGracePeriod for the workloads, pods, services and
+// PVCs in the given namespaces to be gone after the package was removed, and reports
+// each remaining resource as left behind, unless it was reported already
+func (d *PackageDeployer) checkRemoval(ctx context.Context, result *DeploymentResult, name string, namespaces []string) {
+	if len(namespaces) == 0 {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, removalGracePeriod)
+	defer cancel()
+
+	var remaining []ClusterResource
+	var lastErr error
+	for ctx.Err() == nil {
+		resources, err := d.remainingResources(ctx, name, namespaces)
+		if err == nil && len(resources) == 0 {
+			return
+		}
+		if err == nil {
+			remaining = resources
+		} else if ctx.Err() == nil {
+			lastErr = err
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(probeInterval):
+		}
+	}
+	if remaining == nil {
+		if lastErr != nil {
+			result.Warnings = append(result.Warnings, fmt.Sprintf("Skipping removal check: %v", lastErr))
+		}
+		return
+	}
+
+	reported := map[ClusterResource]bool{}
+	for _, resource := range result.Leaked {
+		reported[resource] = true
+	}
+	for _, resource := range remaining {
+		if reported[resource] {
+			continue
+		}
+		result.Leaked = append(result.Leaked, resource)
+		result.Errors = append(result.Errors, fmt.Sprintf("Resource left behind after package removal: %s", resource))
+	}
+}
+
+// remainingResources lists the resources of removalKinds in the given namespaces
+func (d *PackageDeployer) remainingResources(ctx context.Context, name string, namespaces []string) ([]ClusterResource, error) {
+	snapshot := ClusterSnapshot{}
+	for _, namespace := range namespaces {
+		output, err := d.run(ctx, name, "", "kubectl", "get", removalKinds, "--namespace", namespace,
+			"--ignore-not-found", "--output", "jsonpath="+snapshotTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources in namespace %s: %w", namespace, err)
+		}
+		for resource := range parseClusterSnapshot(output) {
+			snapshot[resource] = true
+		}
+	}
+	return ClusterSnapshot{}.Leaked(snapshot), nil
+}
@@ -0,0 +1,117 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// Sources of rendered manifests
+const (
+	sourceManifest      = "manifest"
+	sourceKustomization = "kustomization"
+	sourceChart         = "chart"
+)
+
+// renderedManifest holds the Kubernetes objects of a manifest file, kustomization or
+// local chart of a component
+type renderedManifest struct {
+	Component string
+	Kind      string // sourceManifest, sourceKustomization or sourceChart
+	Path      string // Relative to the package
+	Namespace string // Namespace of objects without one, if set in zarf.yaml
+	Content   []byte
+	Err       error // Why the manifest could not be read or rendered
+}
+
+// documents decodes the non-empty YAML documents of the manifest. Documents after an
+// invalid one are skipped, YAML errors are reported by the manifest validation.
+func (m renderedManifest) documents() []map[string]interface{} {
+	var docs []map[string]interface{}
+	decoder := yaml.NewDecoder(bytes.NewReader(m.Content))
+	for {
+		var doc map[string]interface{}
+		if err := decoder.Decode(&doc); err != nil {
+			return docs
+		}
+		if len(doc) > 0 {
+			docs = append(docs, doc)
+		}
+	}
+}
+
+// renderedManifests returns the local manifest files, the built kustomizations and the
+// rendered local charts of all components. Remote manifests, kustomizations and charts
+// are skipped. Rendering happens once per package, the result is shared by the rules.
+func (pkg *PackageContext) renderedManifests(ctx context.Context) []renderedManifest {
+	if pkg.rendered != nil {
+		return pkg.rendered
+	}
+
+	kustomize := tool.NewKustomize(exec.NewProcessExecutor(false))
+	helm := tool.NewHelm(exec.NewProcessExecutor(false), nil, nil, nil)
+	rendered := []renderedManifest{}
+	for _, component := range pkg.ZarfYaml.Components {
+		for _, manifest := range component.Manifests {
+			for _, file := range manifest.Files {
+				if isRemoteReference(file) {
+					continue
+				}
+				content, err := os.ReadFile(filepath.Join(pkg.Path, file))
+				rendered = append(rendered, renderedManifest{Component: component.Name, Kind: sourceManifest,
+					Path: file, Namespace: manifest.Namespace, Content: content, Err: err})
+			}
+			for _, kustomization := range manifest.Kustomizations {
+				kustomizationPath := filepath.Join(pkg.Path, kustomization)
+				if isRemoteReference(kustomization) || !util.FileExists(kustomizationPath) {
+					continue
+				}
+				output, err := kustomize.Build(ctx, kustomizationPath)
+				rendered = append(rendered, renderedManifest{Component: component.Name, Kind: sourceKustomization,
+					Path: kustomization, Namespace: manifest.Namespace, Content: []byte(output), Err: err})
+			}
+		}
+		for _, chart := range component.Charts {
+			chartPath := filepath.Join(pkg.Path, chart.LocalPath)
+			if chart.LocalPath == "" || !util.FileExists(chartPath) {
+				continue
+			}
+			var valuesFiles []string
+			for _, valuesFile := range chart.ValuesFiles {
+				if !isRemoteReference(valuesFile) {
+					valuesFiles = append(valuesFiles, filepath.Join(pkg.Path, valuesFile))
+				}
+			}
+			release := chart.ReleaseName
+			if release == "" {
+				release = chart.Name
+			}
+			output, err := helm.Template(ctx, release, chartPath, chart.Namespace, valuesFiles)
+			rendered = append(rendered, renderedManifest{Component: component.Name, Kind: sourceChart,
+				Path: chart.LocalPath, Namespace: chart.Namespace, Content: []byte(output), Err: err})
+		}
+	}
+
+	pkg.rendered = rendered
+	return rendered
+}
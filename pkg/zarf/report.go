@@ -0,0 +1,314 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Failure thresholds for Failure
+const (
+	FailOnError   = "error"
+	FailOnWarning = "warning"
+	FailOnNever   = "never"
+)
+
+// Report kinds, recorded in saved reports so that they can be rendered later
+const (
+	ReportKindLint    = "lint"
+	ReportKindInstall = "install"
+)
+
+// LintReport is the machine-readable result of linting a set of packages
+type LintReport struct {
+	Kind      string          `json:"kind"`
+	Timestamp string          `json:"timestamp"`
+	Packages  []PackageReport `json:"packages"`
+	Summary   ReportSummary   `json:"summary"`
+}
+
+// PackageReport holds the findings of a single package
+type PackageReport struct {
+	Path       string    `json:"path"`
+	Owners     []string  `json:"owners,omitempty"`
+	Valid      bool      `json:"valid"`
+	Findings   []Finding `json:"findings"`
+	Suppressed int       `json:"suppressed,omitempty"`
+	Duration   float64   `json:"durationSeconds,omitempty"`
+
+	// Quality is the quality score of the package, see ScoreFindings
+	Quality PackageScore `json:"quality"`
+}
+
+// ReportSummary holds the aggregated counts of a LintReport
+type ReportSummary struct {
+	Packages   int `json:"packages"`
+	Passed     int `json:"passed"`
+	Failed     int `json:"failed"`
+	Errors     int `json:"errors"`
+	Warnings   int `json:"warnings"`
+	Suppressed int `json:"suppressed,omitempty"`
+
+	// Score is the average quality score of the packages and Grade its letter grade
+	Score int    `json:"score"`
+	Grade string `json:"grade,omitempty"`
+}
+
+// NewLintReport builds a LintReport from validation results
+func NewLintReport(results []*ValidationResult) *LintReport {
+	report := &LintReport{
+		Kind:      ReportKindLint,
+		Timestamp: time.Now().UTC().Format(time.RFC3339),
+		Packages:  []PackageReport{},
+	}
+
+	for _, result := range results {
+		pkg := NewPackageReport(result)
+		findings := pkg.Findings
+		report.Packages = append(report.Packages, pkg)
+		report.Summary.Suppressed += result.Suppressed
+
+		report.Summary.Packages++
+		if result.Valid {
+			report.Summary.Passed++
+		} else {
+			report.Summary.Failed++
+		}
+		for _, finding := range findings {
+			switch finding.Severity {
+			case SeverityError:
+				report.Summary.Errors++
+			case SeverityWarning:
+				report.Summary.Warnings++
+			}
+		}
+	}
+
+	if len(report.Packages) > 0 {
+		total := 0
+		for _, pkg := range report.Packages {
+			total += pkg.Quality.Score
+		}
+		report.Summary.Score = (total + len(report.Packages)/2) / len(report.Packages)
+		report.Summary.Grade = Grade(report.Summary.Score)
+	}
+	return report
+}
+
+// Failure returns an error if the report fails the given threshold. failOn is the
+// minimum severity that fails the run (FailOnError, FailOnWarning or FailOnNever);
+// maxWarnings fails the run when the total warning count exceeds it, unless negative,
+// and applies regardless of failOn. Packages that could not be validated at all fail
+// the run whatever the threshold.
+func (r *LintReport) Failure(failOn string, maxWarnings int) error {
+	var internalErrors []string
+	for _, pkg := range r.Packages {
+		for _, finding := range pkg.Findings {
+			if finding.RuleID == RuleInternalError {
+				internalErrors = append(internalErrors, pkg.Path)
+				break
+			}
+		}
+	}
+	if len(internalErrors) > 0 {
+		return fmt.Errorf("failed to validate %d package(s): %s", len(internalErrors), strings.Join(internalErrors, ", "))
+	}
+
+	switch failOn {
+	case FailOnNever:
+	case FailOnWarning:
+		if r.Summary.Errors > 0 || r.Summary.Warnings > 0 {
+			return fmt.Errorf("package validation failed: %d error(s), %d warning(s)", r.Summary.Errors, r.Summary.Warnings)
+		}
+	default:
+		if r.Summary.Failed > 0 {
+			return fmt.Errorf("package validation failed")
+		}
+	}
+
+	if maxWarnings >= 0 && r.Summary.Warnings > maxWarnings {
+		return fmt.Errorf("too many warnings: %d (max %d)", r.Summary.Warnings, maxWarnings)
+	}
+	return nil
+}
+
+// InstallReport is the machine-readable result of deploying and testing a set of
+// packages
+type InstallReport struct {
+	Kind        string             `json:"kind"`
+	Timestamp   string             `json:"timestamp"`
+	Deployments []DeploymentReport `json:"deployments"`
+	Summary     InstallSummary     `json:"summary"`
+}
+
+// DeploymentReport holds the result of a single deployment of a package
+type DeploymentReport struct {
+	Path        string                `json:"path"`
+	Owners      []string              `json:"owners,omitempty"`
+	Cluster     string                `json:"cluster,omitempty"`
+	VariableSet string                `json:"variableSet,omitempty"`
+	Components  string                `json:"components,omitempty"`
+	Mirror      bool                  `json:"mirror,omitempty"`
+	Success     bool                  `json:"success"`
+	TimedOut    bool                  `json:"timedOut,omitempty"`
+	Duration    float64               `json:"durationSeconds"`
+	Errors      []string              `json:"errors,omitempty"`
+	Warnings    []string              `json:"warnings,omitempty"`
+	Tests       []ComponentTestReport `json:"tests,omitempty"`
+	Leaked      []string              `json:"leaked,omitempty"`
+	Cycles      int                   `json:"cycles,omitempty"`
+	RemoveTime  float64               `json:"removeSeconds,omitempty"`
+	Benchmark   *BenchmarkReport      `json:"benchmark,omitempty"`
+}
+
+// BenchmarkReport holds the timings of a deployment in seconds
+type BenchmarkReport struct {
+	Deploy    float64            `json:"deploySeconds"`
+	ImagePull float64            `json:"imagePullSeconds"`
+	Ready     map[string]float64 `json:"readySeconds,omitempty"`
+	Baseline  float64            `json:"baselineSeconds,omitempty"`
+}
+
+// ComponentTestReport holds the result of a single test of a deployment
+type ComponentTestReport struct {
+	Name    string `json:"name"`
+	Success bool   `json:"success"`
+	Message string `json:"message,omitempty"`
+}
+
+// InstallSummary holds the aggregated counts of an InstallReport
+type InstallSummary struct {
+	Deployments int     `json:"deployments"`
+	Passed      int     `json:"passed"`
+	Failed      int     `json:"failed"`
+	Duration    float64 `json:"durationSeconds"`
+}
+
+// NewInstallReport builds an InstallReport from deployment results
+func NewInstallReport(results []*DeploymentResult) *InstallReport {
+	report := &InstallReport{
+		Kind:        ReportKindInstall,
+		Timestamp:   time.Now().UTC().Format(time.RFC3339),
+		Deployments: []DeploymentReport{},
+	}
+
+	for _, result := range results {
+		deployment := NewDeploymentReport(result)
+		report.Deployments = append(report.Deployments, deployment)
+
+		report.Summary.Deployments++
+		report.Summary.Duration += deployment.Duration
+		if result.Success {
+			report.Summary.Passed++
+		} else {
+			report.Summary.Failed++
+		}
+	}
+
+	return report
+}
+
+// NewPackageReport builds the report of a single package from its validation result
+func NewPackageReport(result *ValidationResult) PackageReport {
+	findings := result.Findings
+	if findings == nil {
+		findings = []Finding{}
+	}
+	return PackageReport{
+		Path:       result.PackagePath,
+		Owners:     PackageOwners(result.PackagePath),
+		Valid:      result.Valid,
+		Findings:   findings,
+		Suppressed: result.Suppressed,
+		Duration:   result.Duration.Seconds(),
+		Quality:    ScoreFindings(findings),
+	}
+}
+
+// NewDeploymentReport builds the report of a single deployment from its result
+func NewDeploymentReport(result *DeploymentResult) DeploymentReport {
+	deployment := DeploymentReport{
+		Path:        result.PackagePath,
+		Owners:      PackageOwners(result.PackagePath),
+		Cluster:     result.Cluster,
+		VariableSet: result.VariableSet,
+		Components:  result.Components,
+		Mirror:      result.Mirror,
+		Success:     result.Success,
+		TimedOut:    result.TimedOut,
+		Duration:    result.DeployTime.Seconds(),
+		Errors:      result.Errors,
+		Warnings:    result.Warnings,
+		Cycles:      result.Cycles,
+		RemoveTime:  result.RemoveTime.Seconds(),
+	}
+	for _, test := range result.ComponentTests {
+		deployment.Tests = append(deployment.Tests, ComponentTestReport{Name: test.ComponentName, Success: test.Success, Message: test.Message})
+	}
+	for _, resource := range result.Leaked {
+		deployment.Leaked = append(deployment.Leaked, resource.String())
+	}
+	if result.Benchmark != nil {
+		deployment.Benchmark = &BenchmarkReport{
+			Deploy:    result.Benchmark.Deploy.Seconds(),
+			ImagePull: result.Benchmark.ImagePull.Seconds(),
+			Baseline:  result.Benchmark.Baseline.Seconds(),
+		}
+		for workload, ready := range result.Benchmark.Ready {
+			if deployment.Benchmark.Ready == nil {
+				deployment.Benchmark.Ready = map[string]float64{}
+			}
+			deployment.Benchmark.Ready[workload] = ready.Seconds()
+		}
+	}
+	return deployment
+}
+
+// ParseReport parses a lint or install report saved as JSON. Exactly one of the
+// returned reports is set.
+func ParseReport(content []byte) (*LintReport, *InstallReport, error) {
+	var header struct {
+		Kind        string          `json:"kind"`
+		Deployments json.RawMessage `json:"deployments"`
+	}
+	if err := json.Unmarshal(content, &header); err != nil {
+		return nil, nil, fmt.Errorf("invalid report: %w", err)
+	}
+
+	// Lint reports written before the kind was recorded have no kind
+	kind := header.Kind
+	if kind == "" && header.Deployments == nil {
+		kind = ReportKindLint
+	}
+	switch kind {
+	case ReportKindLint:
+		var report LintReport
+		if err := json.Unmarshal(content, &report); err != nil {
+			return nil, nil, fmt.Errorf("invalid lint report: %w", err)
+		}
+		return &report, nil, nil
+	case ReportKindInstall:
+		var report InstallReport
+		if err := json.Unmarshal(content, &report); err != nil {
+			return nil, nil, fmt.Errorf("invalid install report: %w", err)
+		}
+		return nil, &report, nil
+	default:
+		return nil, nil, fmt.Errorf("unknown report kind %q", header.Kind)
+	}
+}
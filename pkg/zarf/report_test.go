@@ -0,0 +1,86 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewLintReport(t *testing.T) {
+	failing := &ValidationResult{PackagePath: "packages/a", Valid: true}
+	failing.AddError("file-reference", "missing file")
+	failing.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+
+	passing := &ValidationResult{PackagePath: "packages/b", Valid: true}
+
+	report := NewLintReport([]*ValidationResult{failing, passing})
+
+	assert.Equal(t, ReportSummary{Packages: 2, Passed: 1, Failed: 1, Errors: 1, Warnings: 1, Score: 80, Grade: "B"}, report.Summary)
+	assert.Len(t, report.Packages, 2)
+	assert.False(t, report.Packages[0].Valid)
+	assert.Equal(t, []Finding{
+		{RuleID: "file-reference", Severity: SeverityError, Message: "missing file"},
+		{RuleID: "image-pinning", Severity: SeverityWarning, Message: "Image not pinned with digest - nginx:1.25"},
+	}, report.Packages[0].Findings)
+	assert.Equal(t, []Finding{}, report.Packages[1].Findings)
+	assert.Equal(t, 59, report.Packages[0].Quality.Score)
+	assert.Equal(t, 100, report.Packages[1].Quality.Score)
+}
+
+func TestLintReportFailure(t *testing.T) {
+	warningsOnly := &ValidationResult{PackagePath: "packages/a", Valid: true}
+	warningsOnly.AddWarning("image-pinning", "Image not pinned with digest - nginx:1.25")
+	warningsOnly.AddWarning("package-description", "No description provided in metadata")
+	warningsOnly.AddInfo("zarf-lint", "Validated using Zarf CLI")
+
+	withErrors := &ValidationResult{PackagePath: "packages/b", Valid: true}
+	withErrors.AddError("file-reference", "missing file")
+
+	internalError := &ValidationResult{PackagePath: "packages/c", Valid: true}
+	internalError.AddError(RuleInternalError, "Failed to validate package: context canceled")
+
+	testCases := []struct {
+		name        string
+		results     []*ValidationResult
+		failOn      string
+		maxWarnings int
+		wantErr     bool
+	}{
+		{"warnings pass on error", []*ValidationResult{warningsOnly}, FailOnError, -1, false},
+		{"warnings fail on warning", []*ValidationResult{warningsOnly}, FailOnWarning, -1, true},
+		{"errors fail on error", []*ValidationResult{withErrors}, FailOnError, -1, true},
+		{"errors pass on never", []*ValidationResult{withErrors}, FailOnNever, -1, false},
+		{"warnings within limit", []*ValidationResult{warningsOnly}, FailOnError, 2, false},
+		{"warnings above limit", []*ValidationResult{warningsOnly}, FailOnError, 1, true},
+		{"limit applies on never", []*ValidationResult{warningsOnly}, FailOnNever, 0, true},
+		{"internal errors fail on never", []*ValidationResult{warningsOnly, internalError}, FailOnNever, -1, true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := NewLintReport(tc.results).Failure(tc.failOn, tc.maxWarnings)
+			if tc.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+
+	err := NewLintReport([]*ValidationResult{warningsOnly, internalError}).Failure(FailOnNever, -1)
+	assert.EqualError(t, err, "failed to validate 1 package(s): packages/c")
+}
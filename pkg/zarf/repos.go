@@ -0,0 +1,226 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// commitPattern matches full commit SHAs
+var commitPattern = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// githubAPIURL is the GitHub API the sizes of GitHub repositories are read from
+var githubAPIURL = "https://api.github.com"
+
+// splitRepoRef splits a Git repository of a component into its URL and the ref after
+// '@', e.g. 'v1.2.0', 'refs/tags/v1.2.0', 'refs/heads/main' or a commit SHA. The ref is
+// empty when the repository is not pinned. Credentials in the host part of the URL
+// ('https://user@host/...') are not mistaken for a ref.
+func splitRepoRef(repo string) (string, string) {
+	start := 0
+	if _, rest, found := strings.Cut(repo, "://"); found {
+		start = len(repo) - len(rest)
+		if slash := strings.Index(rest, "/"); slash >= 0 {
+			start += slash
+		}
+	}
+	i := strings.LastIndex(repo[start:], "@")
+	if i < 0 {
+		return repo, ""
+	}
+	return repo[:start+i], repo[start+i+1:]
+}
+
+// GitRemotes reads the refs and sizes of Git repositories, caching them by URL as
+// packages commonly mirror the same repositories
+type GitRemotes struct {
+	mu    sync.Mutex
+	refs  map[string]map[string]string
+	sizes map[string]int64
+}
+
+// NewGitRemotes creates an empty cache of Git repositories
+func NewGitRemotes() *GitRemotes {
+	return &GitRemotes{refs: map[string]map[string]string{}, sizes: map[string]int64{}}
+}
+
+// Refs returns the refs of a repository by name, e.g. 'refs/tags/v1.0.0', with the
+// commits they point to, read with 'git ls-remote'
+func (g *GitRemotes) Refs(ctx context.Context, repoURL string) (map[string]string, error) {
+	g.mu.Lock()
+	refs, ok := g.refs[repoURL]
+	g.mu.Unlock()
+	if ok {
+		return refs, nil
+	}
+	output, err := exec.NewProcessExecutor(false).RunProcessAndCaptureStdout(ctx, "git", "ls-remote", repoURL)
+	if err != nil {
+		return nil, err
+	}
+	refs = map[string]string{}
+	for _, line := range strings.Split(output, "\n") {
+		if commit, ref, ok := strings.Cut(strings.TrimSpace(line), "\t"); ok {
+			refs[ref] = commit
+		}
+	}
+	g.mu.Lock()
+	g.refs[repoURL] = refs
+	g.mu.Unlock()
+	return refs, nil
+}
+
+// Size estimates the size of a clone of a repository in bytes from the size GitHub
+// reports for it. The size of repositories on other hosts is not known, -1 is returned.
+func (g *GitRemotes) Size(ctx context.Context, repoURL string) (int64, error) {
+	g.mu.Lock()
+	size, ok := g.sizes[repoURL]
+	g.mu.Unlock()
+	if ok {
+		return size, nil
+	}
+
+	size = -1
+	if u, err := url.Parse(repoURL); err == nil && u.Host == "github.com" {
+		slug := strings.TrimSuffix(strings.Trim(u.Path, "/"), ".git")
+		if strings.Count(slug, "/") != 1 {
+			return 0, fmt.Errorf("%s is not a GitHub repository URL", repoURL)
+		}
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, githubAPIURL+"/repos/"+slug, nil)
+		if err != nil {
+			return 0, err
+		}
+		request.Header.Set("Accept", "application/vnd.github+json")
+		if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+			request.Header.Set("Authorization", "Bearer "+token)
+		}
+		response, err := http.DefaultClient.Do(request)
+		if err != nil {
+			return 0, err
+		}
+		defer response.Body.Close()
+		if response.StatusCode != http.StatusOK {
+			return 0, fmt.Errorf("GitHub API returned %s for %s", response.Status, slug)
+		}
+		var repository struct {
+			Size int64 `json:"size"`
+		}
+		if err := json.NewDecoder(response.Body).Decode(&repository); err != nil {
+			return 0, fmt.Errorf("failed to read GitHub repository %s: %w", slug, err)
+		}
+		// GitHub reports the size in KiB
+		size = repository.Size << 10
+	}
+
+	g.mu.Lock()
+	g.sizes[repoURL] = size
+	g.mu.Unlock()
+	return size, nil
+}
+
+// validateRepos checks the Git repositories of components: repositories should be
+// pinned to a tag or commit, and with CheckRepos be reachable and define the ref they
+// are pinned to. With RepoSizeBudget, the estimated size of their clones is compared
+// with the budget of the package.
+func (v *PackageValidator) validateRepos(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	type repoSize struct {
+		url  string
+		size int64
+	}
+	var sizes []repoSize
+	var total int64
+	var unknown []string
+	seen := map[string]bool{}
+
+	for _, component := range pkg.ZarfYaml.Components {
+		for _, repo := range component.Repos {
+			if isTemplated(repo) {
+				continue
+			}
+			repoURL, ref := splitRepoRef(repo)
+			switch {
+			case ref == "":
+				result.AddWarning("repo-pinning",
+					fmt.Sprintf("Component '%s' repository '%s' is not pinned to a tag or commit, all of its branches and tags are mirrored", component.Name, repo))
+			case strings.HasPrefix(ref, "refs/heads/"):
+				result.AddWarning("repo-pinning",
+					fmt.Sprintf("Component '%s' repository '%s' tracks branch '%s', pin it to a tag or commit for reproducible packages", component.Name, repoURL, strings.TrimPrefix(ref, "refs/heads/")))
+			}
+
+			if v.CheckRepos {
+				refs, err := v.gitRemotes.Refs(ctx, repoURL)
+				if err != nil {
+					result.AddWarning("repo-reachable",
+						fmt.Sprintf("Component '%s' repository '%s' is not reachable: %v", component.Name, repoURL, err))
+				} else if ref != "" && !commitPattern.MatchString(ref) && !hasRef(refs, ref) {
+					result.AddError("repo-ref",
+						fmt.Sprintf("Component '%s' repository '%s' has no ref '%s'", component.Name, repoURL, ref))
+				}
+			}
+
+			if v.RepoSizeBudget > 0 && !seen[repoURL] {
+				seen[repoURL] = true
+				size, err := v.gitRemotes.Size(ctx, repoURL)
+				if err != nil {
+					result.AddInfo("repo-size",
+						fmt.Sprintf("Could not estimate the size of repository '%s': %v", repoURL, err))
+				} else if size < 0 {
+					unknown = append(unknown, repoURL)
+				} else {
+					sizes = append(sizes, repoSize{repoURL, size})
+					total += size
+				}
+			}
+		}
+	}
+
+	if len(unknown) > 0 {
+		result.AddInfo("repo-size",
+			fmt.Sprintf("The size of repositories outside of GitHub is not estimated: %s", strings.Join(unknown, ", ")))
+	}
+	if v.RepoSizeBudget > 0 && total > v.RepoSizeBudget {
+		sort.SliceStable(sizes, func(i, j int) bool { return sizes[i].size > sizes[j].size })
+		var largest []string
+		for _, repo := range sizes {
+			largest = append(largest, fmt.Sprintf("%s (%s)", repo.url, util.FormatSize(repo.size)))
+		}
+		result.AddWarning("repo-size",
+			fmt.Sprintf("The Git repositories of the package are estimated at %s, above the budget of %s: %s",
+				util.FormatSize(total), util.FormatSize(v.RepoSizeBudget), strings.Join(largest, ", ")))
+	}
+	return nil
+}
+
+// hasRef reports whether ref names one of refs: a full ref such as 'refs/tags/v1.0.0',
+// or a tag or branch name
+func hasRef(refs map[string]string, ref string) bool {
+	for _, name := range []string{ref, "refs/tags/" + ref, "refs/heads/" + ref} {
+		if _, ok := refs[name]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,115 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplitRepoRef(t *testing.T) {
+	tests := []struct {
+		repo, url, ref string
+	}{
+		{"https://github.com/org/repo.git", "https://github.com/org/repo.git", ""},
+		{"https://github.com/org/repo.git@v1.2.0", "https://github.com/org/repo.git", "v1.2.0"},
+		{"https://github.com/org/repo.git@refs/heads/main", "https://github.com/org/repo.git", "refs/heads/main"},
+		{"https://user@example.com/org/repo.git", "https://user@example.com/org/repo.git", ""},
+		{"https://user@example.com/org/repo.git@refs/tags/1.0.0", "https://user@example.com/org/repo.git", "refs/tags/1.0.0"},
+	}
+	for _, tt := range tests {
+		url, ref := splitRepoRef(tt.repo)
+		assert.Equal(t, tt.url, url, tt.repo)
+		assert.Equal(t, tt.ref, ref, tt.repo)
+	}
+}
+
+func TestValidateRepos(t *testing.T) {
+	repo := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		cmd.Dir = repo
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	git("init", "-q", "-b", "main")
+	git("commit", "-q", "--allow-empty", "-m", "initial")
+	git("tag", "v1.0.0")
+	repoURL := "file://" + repo
+
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{Name: "config", Repos: []string{
+				repoURL + "@v1.0.0",
+				repoURL + "@refs/heads/main",
+				repoURL + "@v2.0.0",
+				repoURL,
+				"file:///missing/repo.git@v1.0.0",
+				"###ZARF_PKG_TMPL_REPO###",
+			}},
+		},
+	}
+	v := NewPackageValidator()
+	v.CheckRepos = true
+	result := &ValidationResult{}
+	require.NoError(t, v.validateRepos(context.Background(), &PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "repo-pinning", Severity: SeverityWarning, Message: fmt.Sprintf("Component 'config' repository '%s' tracks branch 'main', pin it to a tag or commit for reproducible packages", repoURL)},
+		{RuleID: "repo-ref", Severity: SeverityError, Message: fmt.Sprintf("Component 'config' repository '%s' has no ref 'v2.0.0'", repoURL)},
+		{RuleID: "repo-pinning", Severity: SeverityWarning, Message: fmt.Sprintf("Component 'config' repository '%s' is not pinned to a tag or commit, all of its branches and tags are mirrored", repoURL)},
+		{RuleID: "repo-reachable", Severity: SeverityWarning, Message: "Component 'config' repository 'file:///missing/repo.git' is not reachable: failed running process: exit status 128"},
+	}, result.Findings)
+}
+
+func TestValidateRepoSizeBudget(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/org/big":
+			fmt.Fprint(w, `{"size": 409600}`)
+		case "/repos/org/small":
+			fmt.Fprint(w, `{"size": 204800}`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+	defer func(previous string) { githubAPIURL = previous }(githubAPIURL)
+	githubAPIURL = server.URL
+
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{Name: "docs", Repos: []string{"https://github.com/org/small.git@v1.0.0", "https://gitlab.com/org/other.git@v1.0.0"}},
+			{Name: "app", Repos: []string{"https://github.com/org/big.git@v2.0.0", "https://github.com/org/small.git@v1.0.0"}},
+			{Name: "gone", Repos: []string{"https://github.com/org/gone@v1.0.0"}},
+		},
+	}
+	v := NewPackageValidator()
+	v.RepoSizeBudget = 500 << 20
+	result := &ValidationResult{}
+	require.NoError(t, v.validateRepos(context.Background(), &PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "repo-size", Severity: SeverityInfo, Message: "Could not estimate the size of repository 'https://github.com/org/gone': GitHub API returned 404 Not Found for org/gone"},
+		{RuleID: "repo-size", Severity: SeverityInfo, Message: "The size of repositories outside of GitHub is not estimated: https://gitlab.com/org/other.git"},
+		{RuleID: "repo-size", Severity: SeverityWarning, Message: "The Git repositories of the package are estimated at 600.0MiB, above the budget of 500.0MiB: https://github.com/org/big.git (400.0MiB), https://github.com/org/small.git (200.0MiB)"},
+	}, result.Findings)
+}
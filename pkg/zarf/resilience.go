@@ -0,0 +1,177 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// resilienceKinds are the kinds of workloads whose pods are deleted when testing the
+// resilience of a deployment
+const resilienceKinds = "deployments,statefulsets,daemonsets"
+
+// workload is a deployment, stateful set or daemon set and the pods it manages
+type workload struct {
+	Kind     string
+	Name     string
+	Selector string // Label selector of the pods of the workload
+	Replicas int    // Number of ready pods expected after a recovery
+}
+
+func (w workload) String() string {
+	return strings.ToLower(w.Kind) + "/" + w.Name
+}
+
+// testResilience deletes the pods of every deployment, stateful set and daemon set in
+// the given namespaces and waits for each workload to recover, i.e. to be back at its
+// number of ready pods without any of the deleted pods, within timeout. One result is
+// returned per workload, reporting how long it took to recover.
+func (d *PackageDeployer) testResilience(ctx context.Context, name string, namespaces []string, timeout time.Duration) []ComponentTestResult {
+	var results []ComponentTestResult
+	for _, namespace := range namespaces {
+		workloads, err := d.listWorkloads(ctx, name, namespace)
+		if err != nil {
+			results = append(results, ComponentTestResult{ComponentName: "resilience/" + namespace, Message: err.Error()})
+			continue
+		}
+		for _, workload := range workloads {
+			result := ComponentTestResult{ComponentName: "resilience/" + workload.String()}
+			workloadCtx, cancel := context.WithTimeout(ctx, timeout)
+			recovery, err := d.disruptWorkload(workloadCtx, name, namespace, workload)
+			cancel()
+			if err != nil {
+				result.Message = err.Error()
+			} else {
+				result.Success = true
+				result.Message = fmt.Sprintf("recovered in %s", recovery.Round(time.Millisecond))
+			}
+			results = append(results, result)
+			if ctx.Err() != nil {
+				return results
+			}
+		}
+	}
+	return results
+}
+
+// listWorkloads returns the workloads in a namespace that select their pods by labels
+func (d *PackageDeployer) listWorkloads(ctx context.Context, name, namespace string) ([]workload, error) {
+	output, err := d.run(ctx, name, "", "kubectl", "get", resilienceKinds, namespaceArgs(namespace), "--output", "json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list workloads: %w", err)
+	}
+	var list struct {
+		Items []struct {
+			Kind     string `json:"kind"`
+			Metadata struct {
+				Name string `json:"name"`
+			} `json:"metadata"`
+			Spec struct {
+				Replicas *int `json:"replicas"`
+				Selector struct {
+					MatchLabels map[string]string `json:"matchLabels"`
+				} `json:"selector"`
+			} `json:"spec"`
+			Status struct {
+				DesiredNumberScheduled int `json:"desiredNumberScheduled"`
+			} `json:"status"`
+		} `json:"items"`
+	}
+	if err := json.Unmarshal([]byte(output), &list); err != nil {
+		return nil, fmt.Errorf("failed to parse workloads: %w", err)
+	}
+
+	var workloads []workload
+	for _, item := range list.Items {
+		if len(item.Spec.Selector.MatchLabels) == 0 {
+			continue
+		}
+		var labels []string
+		for key, value := range item.Spec.Selector.MatchLabels {
+			labels = append(labels, key+"="+value)
+		}
+		sort.Strings(labels)
+		w := workload{Kind: item.Kind, Name: item.Metadata.Name, Selector: strings.Join(labels, ","), Replicas: 1}
+		switch {
+		case item.Kind == "DaemonSet":
+			w.Replicas = item.Status.DesiredNumberScheduled
+		case item.Spec.Replicas != nil:
+			w.Replicas = *item.Spec.Replicas
+		}
+		if w.Replicas > 0 {
+			workloads = append(workloads, w)
+		}
+	}
+	return workloads, nil
+}
+
+// disruptWorkload deletes the pods of a workload and returns how long it took until
+// the workload was back at its number of ready pods, waiting until ctx is done
+func (d *PackageDeployer) disruptWorkload(ctx context.Context, name, namespace string, w workload) (time.Duration, error) {
+	before, err := d.workloadPods(ctx, name, namespace, w)
+	if err != nil {
+		return 0, err
+	}
+	start := time.Now()
+	if _, err := d.run(ctx, name, "", "kubectl", "delete", "pods", "--selector", w.Selector,
+		namespaceArgs(namespace), "--wait=false"); err != nil {
+		return 0, fmt.Errorf("failed to delete pods: %w", err)
+	}
+
+	ready := 0
+	for {
+		pods, err := d.workloadPods(ctx, name, namespace, w)
+		if err == nil {
+			ready = 0
+			for pod, podReady := range pods {
+				if _, deleted := before[pod]; !deleted && podReady {
+					ready++
+				}
+			}
+			if ready >= w.Replicas {
+				return time.Since(start), nil
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return 0, fmt.Errorf("not recovered after %s, %d of %d pod(s) ready", time.Since(start).Round(time.Second), ready, w.Replicas)
+		case <-time.After(probeInterval):
+		}
+	}
+}
+
+// workloadPods returns the UIDs of the pods of a workload and whether each of them is
+// ready. Pods are identified by UID, as stateful sets recreate pods with the same name.
+func (d *PackageDeployer) workloadPods(ctx context.Context, name, namespace string, w workload) (map[string]bool, error) {
+	output, err := d.run(ctx, name, "", "kubectl", "get", "pods", "--selector", w.Selector, namespaceArgs(namespace),
+		"--output", `jsonpath={range .items[*]}{.metadata.uid}{" "}{.status.conditions[?(@.type=="Ready")].status}{"\n"}{end}`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods: %w", err)
+	}
+	pods := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		pods[fields[0]] = len(fields) > 1 && fields[1] == "True"
+	}
+	return pods, nil
+}
@@ -0,0 +1,60 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTestResilience(t *testing.T) {
+	// The podinfo deployment replaces its deleted pods, the redis stateful set does not
+	// become ready again and the scaled down deployment is not disrupted
+	t.Setenv("ZT_TEST_DELETED", filepath.Join(t.TempDir(), "deleted"))
+	workloads := `{"items": [` +
+		`{"kind": "Deployment", "metadata": {"name": "podinfo"}, "spec": {"replicas": 2, "selector": {"matchLabels": {"app": "podinfo"}}}},` +
+		`{"kind": "StatefulSet", "metadata": {"name": "redis"}, "spec": {"selector": {"matchLabels": {"app": "redis"}}}},` +
+		`{"kind": "Deployment", "metadata": {"name": "idle"}, "spec": {"replicas": 0, "selector": {"matchLabels": {"app": "idle"}}}}]}`
+	fakeCommands(t, map[string]string{
+		"kubectl": `case "$*" in
+"get deployments,statefulsets,daemonsets"*) echo '` + workloads + `' ;;
+"delete pods"*) touch "$ZT_TEST_DELETED" ;;
+"get pods --selector app=podinfo"*)
+  if [ -f "$ZT_TEST_DELETED" ]; then printf 'uid-1 False\nuid-3 True\nuid-4 True\n'; else printf 'uid-1 True\nuid-2 True\n'; fi ;;
+"get pods --selector app=redis"*)
+  if [ -f "$ZT_TEST_DELETED" ]; then echo uid-6 False; else echo uid-5 True; fi ;;
+esac`,
+	})
+
+	probeInterval = time.Millisecond
+	defer func() { probeInterval = time.Second }()
+
+	d := NewPackageDeployer()
+	results := d.testResilience(context.Background(), "podinfo", []string{"podinfo"}, 100*time.Millisecond)
+	require.Len(t, results, 2)
+
+	assert.Equal(t, "resilience/deployment/podinfo", results[0].ComponentName)
+	assert.True(t, results[0].Success)
+	assert.Regexp(t, `^recovered in \S+$`, results[0].Message)
+
+	assert.Equal(t, "resilience/statefulset/redis", results[1].ComponentName)
+	assert.False(t, results[1].Success)
+	assert.Equal(t, "not recovered after 0s, 0 of 1 pod(s) ready", results[1].Message)
+}
@@ -0,0 +1,185 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import "strings"
+
+// Rule categories of the quality score
+const (
+	CategorySecurity        = "security"
+	CategoryCorrectness     = "correctness"
+	CategoryReliability     = "reliability"
+	CategoryMaintainability = "maintainability"
+)
+
+// scoreCategories are the categories of the quality score and the points each
+// contributes to the maximum score of 100, in the order they are reported
+var scoreCategories = []struct {
+	name   string
+	points int
+}{
+	{CategorySecurity, 35},
+	{CategoryCorrectness, 30},
+	{CategoryReliability, 20},
+	{CategoryMaintainability, 15},
+}
+
+// Points an error or a warning deducts from the points of its category
+const (
+	errorPenalty   = 10
+	warningPenalty = 3
+)
+
+// maxFailingScore is the highest score of a package with errors, an F
+const maxFailingScore = 59
+
+// ruleCategories maps rule IDs, or rule ID prefixes ending in '-', to their category.
+// Rules not listed, such as those of plugins, count towards maintainability.
+var ruleCategories = map[string]string{
+	"secret-":              CategorySecurity,
+	"pss-":                 CategorySecurity,
+	"pod-security":         CategorySecurity,
+	"rbac-":                CategorySecurity,
+	"network-policy":       CategorySecurity,
+	"image-policy":         CategorySecurity,
+	"image-pinning":        CategorySecurity,
+	"untrusted-registry":   CategorySecurity,
+	"action-security":      CategorySecurity,
+	"action-pipe-to-shell": CategorySecurity,
+	"action-delete-all":    CategoryReliability,
+	"action-host-path":     CategoryReliability,
+	"action-wait-timeout":  CategoryReliability,
+	"action-wait-target":   CategoryCorrectness,
+	"namespace-reserved":   CategorySecurity,
+	"zarf-":                CategoryCorrectness,
+	"internal-error":       CategoryCorrectness,
+	"duplicate-key":        CategoryCorrectness,
+	"unknown-field":        CategoryCorrectness,
+	"multiple-documents":   CategoryCorrectness,
+	"init-components":      CategoryCorrectness,
+	"injector-":            CategoryCorrectness,
+	"empty-component":      CategoryCorrectness,
+	"package-kind":         CategoryCorrectness,
+	"package-structure":    CategoryCorrectness,
+	"package-name":         CategoryCorrectness,
+	"no-components":        CategoryCorrectness,
+	"duplicate-component":  CategoryCorrectness,
+	"file-reference":       CategoryCorrectness,
+	"manifest-lint":        CategoryCorrectness,
+	"kustomize-build":      CategoryCorrectness,
+	"component-import":     CategoryCorrectness,
+	"action-definition":    CategoryCorrectness,
+	"data-injection":       CategoryCorrectness,
+	"missing-dependency":   CategoryCorrectness,
+	"circular-dependency":  CategoryCorrectness,
+	"self-dependency":      CategoryCorrectness,
+	"crd-ordering":         CategoryCorrectness,
+	"group-":               CategoryCorrectness,
+	"only-":                CategoryCorrectness,
+	"min-zarf-version":     CategoryCorrectness,
+	"bundle-":              CategoryCorrectness,
+	"docs-":                CategoryMaintainability,
+	"maintainers-":         CategoryMaintainability,
+	"version-":             CategoryReliability,
+	"package-version":      CategoryReliability,
+	"resource-limits":      CategoryReliability,
+	"large-file":           CategoryReliability,
+	"image-count":          CategoryReliability,
+	"namespace-hardcoded":  CategoryReliability,
+	"namespace-undeclared": CategoryReliability,
+	"namespace-collision":  CategoryReliability,
+	"duplicate-versions":   CategoryReliability,
+	"chart-outdated":       CategoryReliability,
+	"repo-pinning":         CategoryReliability,
+	"repo-reachable":       CategoryReliability,
+	"repo-ref":             CategoryCorrectness,
+	"repo-size":            CategoryReliability,
+}
+
+// ruleCategory returns the category of a rule ID, see ruleCategories
+func ruleCategory(ruleID string) string {
+	if category, ok := ruleCategories[ruleID]; ok {
+		return category
+	}
+	// The longest matching prefix wins, e.g. 'zarf-' for 'zarf-version-build'
+	category, longest := CategoryMaintainability, 0
+	for prefix, prefixCategory := range ruleCategories {
+		if strings.HasSuffix(prefix, "-") && strings.HasPrefix(ruleID, prefix) && len(prefix) > longest {
+			category, longest = prefixCategory, len(prefix)
+		}
+	}
+	return category
+}
+
+// PackageScore is the quality score of a package from 0 to 100 and its letter grade
+type PackageScore struct {
+	Score      int             `json:"score"`
+	Grade      string          `json:"grade"`
+	Categories []CategoryScore `json:"categories"`
+}
+
+// CategoryScore is the part of the quality score of a rule category
+type CategoryScore struct {
+	Category string `json:"category"`
+	Score    int    `json:"score"`
+	Max      int    `json:"max"`
+}
+
+// ScoreFindings aggregates findings into a quality score. Every category contributes
+// its points to the score, less 10 points per error and 3 per warning of its rules,
+// down to zero. Info findings do not count. A package with errors fails validation,
+// so its score is capped at 59, an F.
+func ScoreFindings(findings []Finding) PackageScore {
+	penalties := map[string]int{}
+	hasErrors := false
+	for _, finding := range findings {
+		switch finding.Severity {
+		case SeverityError:
+			hasErrors = true
+			penalties[ruleCategory(finding.RuleID)] += errorPenalty
+		case SeverityWarning:
+			penalties[ruleCategory(finding.RuleID)] += warningPenalty
+		}
+	}
+
+	score := PackageScore{Categories: []CategoryScore{}}
+	for _, category := range scoreCategories {
+		points := max(category.points-penalties[category.name], 0)
+		score.Categories = append(score.Categories, CategoryScore{Category: category.name, Score: points, Max: category.points})
+		score.Score += points
+	}
+	if hasErrors {
+		score.Score = min(score.Score, maxFailingScore)
+	}
+	score.Grade = Grade(score.Score)
+	return score
+}
+
+// Grade returns the letter grade of a quality score: A from 90, B from 80, C from 70,
+// D from 60 and F below
+func Grade(score int) string {
+	switch {
+	case score >= 90:
+		return "A"
+	case score >= 80:
+		return "B"
+	case score >= 70:
+		return "C"
+	case score >= 60:
+		return "D"
+	default:
+		return "F"
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRuleCategory(t *testing.T) {
+	assert.Equal(t, CategorySecurity, ruleCategory("secret-private-key"))
+	assert.Equal(t, CategorySecurity, ruleCategory("pss-host-path"))
+	assert.Equal(t, CategoryCorrectness, ruleCategory("zarf-version-build"))
+	assert.Equal(t, CategoryReliability, ruleCategory("version-increment"))
+	assert.Equal(t, CategoryMaintainability, ruleCategory("component-naming"))
+	assert.Equal(t, CategoryCorrectness, ruleCategory(RuleInternalError))
+	assert.Equal(t, CategoryCorrectness, ruleCategory("unknown-field"))
+	assert.Equal(t, CategoryCorrectness, ruleCategory("injector-checksum"))
+	assert.Equal(t, CategoryReliability, ruleCategory("package-version"))
+	assert.Equal(t, CategoryMaintainability, ruleCategory("plugin/team-prefix"))
+}
+
+func TestScoreFindings(t *testing.T) {
+	assert.Equal(t, PackageScore{Score: 100, Grade: "A", Categories: []CategoryScore{
+		{Category: CategorySecurity, Score: 35, Max: 35},
+		{Category: CategoryCorrectness, Score: 30, Max: 30},
+		{Category: CategoryReliability, Score: 20, Max: 20},
+		{Category: CategoryMaintainability, Score: 15, Max: 15},
+	}}, ScoreFindings(nil))
+
+	// Warnings lower the score of their category, info findings do not count
+	score := ScoreFindings([]Finding{
+		{RuleID: "package-description", Severity: SeverityWarning},
+		{RuleID: "zarf-lint", Severity: SeverityInfo},
+	})
+	assert.Equal(t, 97, score.Score)
+	assert.Equal(t, "A", score.Grade)
+
+	// Categories do not drop below zero, packages with errors get an F
+	findings := []Finding{
+		{RuleID: "rbac-cluster-admin", Severity: SeverityError},
+		{RuleID: "secret-generic", Severity: SeverityWarning},
+		{RuleID: "package-description", Severity: SeverityWarning},
+		{RuleID: "yaml-lint", Severity: SeverityError},
+		{RuleID: "variable-naming", Severity: SeverityError},
+		{RuleID: "zarf-lint", Severity: SeverityInfo},
+	}
+	score = ScoreFindings(findings)
+	assert.Equal(t, []CategoryScore{
+		{Category: CategorySecurity, Score: 22, Max: 35},
+		{Category: CategoryCorrectness, Score: 30, Max: 30},
+		{Category: CategoryReliability, Score: 20, Max: 20},
+		{Category: CategoryMaintainability, Score: 0, Max: 15},
+	}, score.Categories)
+	assert.Equal(t, 59, score.Score)
+	assert.Equal(t, "F", score.Grade)
+
+	score = ScoreFindings([]Finding{{RuleID: "yaml-lint", Severity: SeverityError}})
+	assert.Equal(t, 59, score.Score)
+	assert.Equal(t, "F", score.Grade)
+}
+
+func TestGrade(t *testing.T) {
+	for score, grade := range map[int]string{100: "A", 90: "A", 89: "B", 80: "B", 75: "C", 60: "D", 59: "F", 0: "F"} {
+		assert.Equal(t, grade, Grade(score), score)
+	}
+}
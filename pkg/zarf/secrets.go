@@ -0,0 +1,257 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// SecretAllowComment on a line suppresses the secret findings of that line
+const SecretAllowComment = "zt:allow-secret"
+
+// maxSecretScanSize is the size of the largest file scanned for secrets, larger files
+// are usually archives or generated data
+const maxSecretScanSize = 1 << 20
+
+// secretEntropyThreshold is the Shannon entropy in bits per character above which a
+// value assigned to a secret-like key other than a password is considered a
+// credential rather than a word such as 'disabled'
+const secretEntropyThreshold = 3.5
+
+// secretRule detects a credential format. The secret is the first submatch of
+// pattern, or the whole match.
+type secretRule struct {
+	id          string
+	description string
+	pattern     *regexp.Regexp
+}
+
+// secretRules are the credential formats that are reported as errors
+var secretRules = []secretRule{
+	{"secret-private-key", "private key", regexp.MustCompile(`-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY(?: BLOCK)?-----`)},
+	{"secret-aws-access-key-id", "AWS access key ID", regexp.MustCompile(`\b((?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16})\b`)},
+	{"secret-aws-secret-access-key", "AWS secret access key", regexp.MustCompile(`(?i)aws_?secret_?access_?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+]{40})\b`)},
+	{"secret-github-token", "GitHub token", regexp.MustCompile(`\b(gh[pousr]_[A-Za-z0-9]{36,255})\b`)},
+	{"secret-gitlab-token", "GitLab token", regexp.MustCompile(`\b(glpat-[A-Za-z0-9_-]{20,})\b`)},
+	{"secret-slack-token", "Slack token", regexp.MustCompile(`\b(xox[abposr]-[A-Za-z0-9-]{10,})\b`)},
+	{"secret-google-api-key", "Google API key", regexp.MustCompile(`\b(AIza[0-9A-Za-z_-]{35})\b`)},
+	{"secret-jwt", "JSON web token", regexp.MustCompile(`\b(eyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,})`)},
+	{"secret-url-credentials", "password in URL", regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^/\s:@"']+:([^/\s:@"']+)@`)},
+}
+
+// secretAssignment matches values assigned to secret-like keys in YAML, env files and
+// scripts, e.g. 'password: ...' or 'API_TOKEN=...'
+var secretAssignment = regexp.MustCompile(`(?i)\b([a-z0-9_.-]*(?:password|passwd|secret|token|api_?key|access_?key|credentials?)[a-z0-9_.-]*)["']?\s*[:=]\s*["']?([^\s"',}]{8,})`)
+
+// passwordKey matches keys of passwords, whose values are reported regardless of
+// their entropy
+var passwordKey = regexp.MustCompile(`(?i)passw(or)?d`)
+
+// secretReferenceKey matches secret-like keys naming or pointing at a secret rather
+// than holding one, e.g. secretName or existingSecret
+var secretReferenceKey = regexp.MustCompile(`(?i)(name|ref|file|path|dir|id|url|ttl|length|size|type|mode|enabled)$|^existing`)
+
+// SecretMatch is a possible credential found in a file
+type SecretMatch struct {
+	RuleID      string
+	Description string
+	File        string
+	Line        int
+	Column      int
+	Secret      string
+	// Generic matches are found by entropy rather than a known credential format
+	Generic bool
+}
+
+// CompileSecretsAllowlist compiles the allowlist patterns of ScanSecrets
+func CompileSecretsAllowlist(patterns []string) ([]*regexp.Regexp, error) {
+	var allowlist []*regexp.Regexp
+	for _, pattern := range patterns {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid secrets allowlist pattern %q: %w", pattern, err)
+		}
+		allowlist = append(allowlist, compiled)
+	}
+	return allowlist, nil
+}
+
+// ScanSecrets scans the files of the package at packagePath for credentials. Known
+// credential formats are matched by pattern, literal passwords and high-entropy
+// values of other secret-like keys are generic matches.
+// Matches on lines with a 'zt:allow-secret' comment, or whose secret or file path
+// relative to the package matches an allowlist pattern, are skipped.
+func ScanSecrets(packagePath string, allowlist []*regexp.Regexp) ([]SecretMatch, error) {
+	var matches []SecretMatch
+	err := filepath.WalkDir(packagePath, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !entry.Type().IsRegular() || isPackageTarball(entry.Name()) {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil || info.Size() > maxSecretScanSize {
+			return err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if bytes.IndexByte(content[:min(len(content), 8000)], 0) >= 0 {
+			return nil
+		}
+		relative, err := filepath.Rel(packagePath, path)
+		if err != nil {
+			return err
+		}
+		matches = append(matches, scanContent(filepath.ToSlash(relative), string(content), allowlist)...)
+		return nil
+	})
+	return matches, err
+}
+
+// scanContent returns the secrets found in the content of file
+func scanContent(file, content string, allowlist []*regexp.Regexp) []SecretMatch {
+	var matches []SecretMatch
+	allowed := func(secret string) bool {
+		for _, pattern := range allowlist {
+			if pattern.MatchString(secret) || pattern.MatchString(file) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for i, line := range strings.Split(strings.ReplaceAll(content, "\r\n", "\n"), "\n") {
+		if strings.Contains(line, SecretAllowComment) {
+			continue
+		}
+
+		found := false
+		for _, rule := range secretRules {
+			for _, match := range rule.pattern.FindAllStringSubmatchIndex(line, -1) {
+				start, end := match[0], match[1]
+				if len(match) > 2 {
+					start, end = match[2], match[3]
+				}
+				secret := line[start:end]
+				if isPlaceholder(secret) || allowed(secret) {
+					continue
+				}
+				found = true
+				matches = append(matches, SecretMatch{RuleID: rule.id, Description: rule.description,
+					File: file, Line: i + 1, Column: start + 1, Secret: secret})
+			}
+		}
+		if found {
+			continue
+		}
+
+		for _, match := range secretAssignment.FindAllStringSubmatchIndex(line, -1) {
+			key, secret := line[match[2]:match[3]], line[match[4]:match[5]]
+			if secretReferenceKey.MatchString(key) || isPlaceholder(secret) || allowed(secret) {
+				continue
+			}
+			// Passwords are often short words, other keys need random looking values
+			description := fmt.Sprintf("hardcoded value of '%s'", key)
+			if !passwordKey.MatchString(key) {
+				if shannonEntropy(secret) < secretEntropyThreshold {
+					continue
+				}
+				description = fmt.Sprintf("high-entropy value of '%s'", key)
+			}
+			matches = append(matches, SecretMatch{RuleID: "secret-generic", Description: description,
+				File: file, Line: i + 1, Column: match[4] + 1, Secret: secret, Generic: true})
+		}
+	}
+	return matches
+}
+
+// isPlaceholder reports whether value is substituted at deploy time, e.g. a Helm or
+// zarf template, a zarf variable or a shell variable
+func isPlaceholder(value string) bool {
+	for _, marker := range []string{"{{", "${", "$(", "###ZARF_", "<"} {
+		if strings.Contains(value, marker) {
+			return true
+		}
+	}
+	return strings.HasPrefix(value, "$")
+}
+
+// shannonEntropy returns the entropy of value in bits per character
+func shannonEntropy(value string) float64 {
+	counts := map[rune]int{}
+	for _, r := range value {
+		counts[r]++
+	}
+	total := float64(len([]rune(value)))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / total
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// redactSecret keeps the first characters of secret for identification
+func redactSecret(secret string) string {
+	if len(secret) <= 8 {
+		return strings.Repeat("*", len(secret))
+	}
+	return secret[:4] + strings.Repeat("*", min(len(secret)-4, 8))
+}
+
+// validateSecrets reports the credentials found in the package files. Known credential
+// formats are errors, high-entropy values of secret-like keys are warnings.
+func (v *PackageValidator) validateSecrets(_ context.Context, pkg *PackageContext, result *ValidationResult) error {
+	if !v.ScanSecrets {
+		return nil
+	}
+	matches, err := ScanSecrets(pkg.Path, v.SecretsAllowlist)
+	if err != nil {
+		return err
+	}
+	for _, match := range matches {
+		severity := SeverityError
+		if match.Generic {
+			severity = SeverityWarning
+		}
+		result.AddFinding(Finding{
+			RuleID:   match.RuleID,
+			Severity: severity,
+			Message: fmt.Sprintf("Possible %s (%s) in %s; use a zarf variable or a Kubernetes secret, or add a '%s' comment if it is not a secret",
+				match.Description, redactSecret(match.Secret), match.File, SecretAllowComment),
+			File:   match.File,
+			Line:   match.Line,
+			Column: match.Column,
+		})
+	}
+	return nil
+}
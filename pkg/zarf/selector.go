@@ -0,0 +1,136 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+)
+
+// selectorOperator is the comparison of a single selector requirement
+type selectorOperator string
+
+const (
+	selectorEquals       selectorOperator = "="
+	selectorNotEquals    selectorOperator = "!="
+	selectorExists       selectorOperator = "exists"
+	selectorDoesNotExist selectorOperator = "!exists"
+)
+
+// selectorRequirement is a single comma-separated term of a selector
+type selectorRequirement struct {
+	key      string
+	operator selectorOperator
+	value    string
+}
+
+// Selector selects packages by their metadata annotations. All requirements must match.
+type Selector struct {
+	requirements []selectorRequirement
+}
+
+// ParseSelector parses a comma-separated list of requirements on metadata annotations:
+// "key=value" (or "key==value"), "key!=value", "key" (the annotation is set) and "!key"
+// (the annotation is not set). An empty selector matches every package.
+func ParseSelector(selector string) (*Selector, error) {
+	s := &Selector{}
+	for _, term := range strings.Split(selector, ",") {
+		term = strings.TrimSpace(term)
+		if term == "" {
+			continue
+		}
+
+		var req selectorRequirement
+		switch {
+		case strings.Contains(term, "!="):
+			key, value, _ := strings.Cut(term, "!=")
+			req = selectorRequirement{key: key, operator: selectorNotEquals, value: value}
+		case strings.Contains(term, "=="):
+			key, value, _ := strings.Cut(term, "==")
+			req = selectorRequirement{key: key, operator: selectorEquals, value: value}
+		case strings.Contains(term, "="):
+			key, value, _ := strings.Cut(term, "=")
+			req = selectorRequirement{key: key, operator: selectorEquals, value: value}
+		case strings.HasPrefix(term, "!"):
+			req = selectorRequirement{key: strings.TrimPrefix(term, "!"), operator: selectorDoesNotExist}
+		default:
+			req = selectorRequirement{key: term, operator: selectorExists}
+		}
+
+		req.key = strings.TrimSpace(req.key)
+		req.value = strings.TrimSpace(req.value)
+		if req.key == "" || strings.ContainsAny(req.key, "=!") || strings.ContainsAny(req.value, "=!") {
+			return nil, fmt.Errorf("invalid selector %q: malformed requirement %q", selector, term)
+		}
+		s.requirements = append(s.requirements, req)
+	}
+	return s, nil
+}
+
+// Empty reports whether the selector has no requirements
+func (s *Selector) Empty() bool {
+	return len(s.requirements) == 0
+}
+
+// Matches reports whether the annotations satisfy every requirement of the selector
+func (s *Selector) Matches(annotations map[string]string) bool {
+	for _, req := range s.requirements {
+		value, ok := annotations[req.key]
+		switch req.operator {
+		case selectorEquals:
+			if !ok || value != req.value {
+				return false
+			}
+		case selectorNotEquals:
+			if ok && value == req.value {
+				return false
+			}
+		case selectorExists:
+			if !ok {
+				return false
+			}
+		case selectorDoesNotExist:
+			if ok {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// FilterSelectedPackages keeps the packages whose metadata annotations match the selector.
+// Packages whose zarf.yaml cannot be read are kept so that linting reports the problem.
+func FilterSelectedPackages(packages []string, selector string) ([]string, error) {
+	s, err := ParseSelector(selector)
+	if err != nil {
+		return nil, err
+	}
+	if s.Empty() {
+		return packages, nil
+	}
+
+	var filtered []string
+	for _, pkg := range packages {
+		zarfYaml, err := util.ReadZarfYaml(filepath.Join(pkg, "zarf.yaml"))
+		if err == nil && !s.Matches(zarfYaml.Metadata.Annotations) {
+			continue
+		}
+		filtered = append(filtered, pkg)
+	}
+	return filtered, nil
+}
@@ -0,0 +1,98 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSelectorMatches(t *testing.T) {
+	annotations := map[string]string{"team": "platform", "tier": "stable"}
+
+	tests := []struct {
+		selector string
+		matches  bool
+	}{
+		{selector: "", matches: true},
+		{selector: "team=platform", matches: true},
+		{selector: "team==platform", matches: true},
+		{selector: "team=apps", matches: false},
+		{selector: "team=platform, tier!=experimental", matches: true},
+		{selector: "tier!=stable", matches: false},
+		{selector: "owner!=me", matches: true},
+		{selector: "tier", matches: true},
+		{selector: "owner", matches: false},
+		{selector: "!owner", matches: true},
+		{selector: "!team", matches: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.selector, func(t *testing.T) {
+			s, err := ParseSelector(tt.selector)
+			require.NoError(t, err)
+			assert.Equal(t, tt.matches, s.Matches(annotations))
+		})
+	}
+
+	for _, selector := range []string{"=platform", "team=a=b", "!team=platform", "!"} {
+		_, err := ParseSelector(selector)
+		assert.Error(t, err, selector)
+	}
+}
+
+func TestFilterSelectedPackages(t *testing.T) {
+	dir := t.TempDir()
+	packages := map[string]string{
+		"platform":     "  annotations:\n    team: platform\n",
+		"experimental": "  annotations:\n    team: platform\n    tier: experimental\n",
+		"apps":         "  annotations:\n    team: apps\n",
+		"plain":        "",
+	}
+	for name, annotations := range packages {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, name), 0755))
+		zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: " + name + "\n" + annotations
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name, "zarf.yaml"), []byte(zarfYaml), 0644))
+	}
+	broken := filepath.Join(dir, "broken")
+	require.NoError(t, os.MkdirAll(broken, 0755))
+
+	all := []string{
+		filepath.Join(dir, "apps"),
+		filepath.Join(dir, "experimental"),
+		filepath.Join(dir, "plain"),
+		filepath.Join(dir, "platform"),
+		broken,
+	}
+
+	filtered, err := FilterSelectedPackages(all, "team=platform,tier!=experimental")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "platform"), broken}, filtered)
+
+	filtered, err = FilterSelectedPackages(all, "!team")
+	require.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "plain"), broken}, filtered)
+
+	filtered, err = FilterSelectedPackages(all, "")
+	require.NoError(t, err)
+	assert.Equal(t, all, filtered)
+
+	_, err = FilterSelectedPackages(all, "team=a=b")
+	assert.EqualError(t, err, `invalid selector "team=a=b": malformed requirement "team=a=b"`)
+}
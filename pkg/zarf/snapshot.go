@@ -0,0 +1,101 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+)
+
+// snapshotIgnoredKinds are kinds the cluster creates and expires on its own, which
+// would otherwise show up as leaked
+var snapshotIgnoredKinds = map[string]bool{
+	"Event":         true,
+	"Lease":         true,
+	"EndpointSlice": true,
+	"Endpoints":     true,
+}
+
+// snapshotTemplate prints one line per resource: apiVersion|kind|namespace|name
+const snapshotTemplate = `{range .items[*]}{.apiVersion}{"|"}{.kind}{"|"}{.metadata.namespace}{"|"}{.metadata.name}{"\n"}{end}`
+
+// ClusterResource identifies a resource in the cluster by GVK, namespace and name
+type ClusterResource struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Namespace  string `json:"namespace,omitempty"`
+	Name       string `json:"name"`
+}
+
+func (r ClusterResource) String() string {
+	if r.Namespace == "" {
+		return fmt.Sprintf("%s %s/%s", r.APIVersion, r.Kind, r.Name)
+	}
+	return fmt.Sprintf("%s %s/%s (namespace %s)", r.APIVersion, r.Kind, r.Name, r.Namespace)
+}
+
+// ClusterSnapshot is the set of resources present in the cluster at a point in time
+type ClusterSnapshot map[ClusterResource]bool
+
+// TakeClusterSnapshot lists all resources of every listable resource type in all
+// namespaces of the cluster executor's kubectl calls go to
+func TakeClusterSnapshot(ctx context.Context, executor exec.ProcessExecutor) (ClusterSnapshot, error) {
+	resourceTypes, err := executor.RunProcessAndCaptureStdout(ctx, "kubectl", "api-resources", "--verbs=list", "--output=name")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resource types: %w", err)
+	}
+	types := strings.Join(strings.Fields(resourceTypes), ",")
+	if types == "" {
+		return ClusterSnapshot{}, nil
+	}
+
+	output, err := executor.RunProcessAndCaptureStdout(ctx, "kubectl", "get", types, "--all-namespaces",
+		"--ignore-not-found", "--output", "jsonpath="+snapshotTemplate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list cluster resources: %w", err)
+	}
+	return parseClusterSnapshot(output), nil
+}
+
+func parseClusterSnapshot(output string) ClusterSnapshot {
+	snapshot := ClusterSnapshot{}
+	for _, line := range strings.Split(output, "\n") {
+		fields := strings.Split(strings.TrimSpace(line), "|")
+		if len(fields) != 4 || snapshotIgnoredKinds[fields[1]] {
+			continue
+		}
+		snapshot[ClusterResource{APIVersion: fields[0], Kind: fields[1], Namespace: fields[2], Name: fields[3]}] = true
+	}
+	return snapshot
+}
+
+// Leaked returns the resources in after that were not present in s, sorted by their
+// string representation
+func (s ClusterSnapshot) Leaked(after ClusterSnapshot) []ClusterResource {
+	var leaked []ClusterResource
+	for resource := range after {
+		if !s[resource] {
+			leaked = append(leaked, resource)
+		}
+	}
+	sort.Slice(leaked, func(i, j int) bool {
+		return leaked[i].String() < leaked[j].String()
+	})
+	return leaked
+}
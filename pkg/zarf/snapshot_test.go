@@ -0,0 +1,50 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterSnapshotLeaked(t *testing.T) {
+	before := parseClusterSnapshot(`v1|Namespace||default
+v1|Namespace||zarf
+apps/v1|Deployment|zarf|agent-hook
+v1|Event|zarf|agent-hook.1
+`)
+	after := parseClusterSnapshot(`v1|Namespace||default
+v1|Namespace||zarf
+v1|Namespace||podinfo
+apps/v1|Deployment|zarf|agent-hook
+rbac.authorization.k8s.io/v1|ClusterRole||podinfo-reader
+apiextensions.k8s.io/v1|CustomResourceDefinition||canaries.flagger.app
+v1|Event|podinfo|podinfo.2
+coordination.k8s.io/v1|Lease|kube-system|podinfo
+`)
+
+	leaked := before.Leaked(after)
+	assert.Equal(t, []ClusterResource{
+		{APIVersion: "apiextensions.k8s.io/v1", Kind: "CustomResourceDefinition", Name: "canaries.flagger.app"},
+		{APIVersion: "rbac.authorization.k8s.io/v1", Kind: "ClusterRole", Name: "podinfo-reader"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "podinfo"},
+	}, leaked)
+	assert.Equal(t, "v1 Namespace/podinfo", leaked[2].String())
+	assert.Equal(t, "apps/v1 Deployment/web (namespace podinfo)",
+		ClusterResource{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "podinfo", Name: "web"}.String())
+
+	assert.Empty(t, after.Leaked(before))
+}
@@ -0,0 +1,284 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/util/yamledit"
+)
+
+// Types of updates
+const (
+	UpdateChart = "chart"
+	UpdateImage = "image"
+	UpdateRepo  = "repo"
+)
+
+// Update is an available update of a chart, image or Git repository of a package. Field
+// is the YAML path of the value in File, which changes from Value to NewValue.
+type Update struct {
+	Type      string `yaml:"type" json:"type"`
+	Package   string `yaml:"package" json:"package"`
+	Component string `yaml:"component" json:"component"`
+	Name      string `yaml:"name" json:"name"`
+	From      string `yaml:"from" json:"from"`
+	To        string `yaml:"to" json:"to"`
+	Bump      string `yaml:"bump" json:"bump"`
+	File      string `yaml:"file" json:"file"`
+	Line      int    `yaml:"line" json:"line"`
+	Column    int    `yaml:"column" json:"column"`
+	Field     string `yaml:"field" json:"field"`
+	Value     string `yaml:"value" json:"value"`
+	NewValue  string `yaml:"newValue" json:"newValue"`
+}
+
+// UpdatePlan lists the available updates of packages, and the charts, images and
+// repositories that could not be checked
+type UpdatePlan struct {
+	Updates []Update `yaml:"updates" json:"updates"`
+	Errors  []string `yaml:"errors,omitempty" json:"errors,omitempty"`
+}
+
+// Updater finds the updates of packages: charts are updated to the latest version of
+// their repository, images and Git repositories pinned to a tag to the newest patch
+// release of it. Images pinned to a digest are pinned to the digest of the new tag.
+type Updater struct {
+	// Types are the types of updates to find, all if empty
+	Types []string
+
+	Charts *ChartRepositories
+	Images *ImageFreshness
+	// ResolveDigest returns the digest of an image, 'crane digest' by default
+	ResolveDigest func(ctx context.Context, image string) (string, error)
+	// ListTags returns the tags of a Git repository, 'git ls-remote' by default
+	ListTags func(ctx context.Context, url string) ([]string, error)
+}
+
+// NewUpdater creates an updater finding all types of updates
+func NewUpdater() *Updater {
+	return &Updater{
+		Charts:        NewChartRepositories(),
+		Images:        NewImageFreshness(),
+		ResolveDigest: craneDigest,
+		ListTags:      gitTags,
+	}
+}
+
+// wants reports whether updates of kind are to be found
+func (u *Updater) wants(kind string) bool {
+	if len(u.Types) == 0 {
+		return true
+	}
+	for _, t := range u.Types {
+		if t == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// Plan returns the updates available for the packages
+func (u *Updater) Plan(ctx context.Context, packageDirs []string) (*UpdatePlan, error) {
+	plan := &UpdatePlan{Updates: []Update{}}
+	for _, packageDir := range packageDirs {
+		if err := u.planPackage(ctx, packageDir, plan); err != nil {
+			return nil, err
+		}
+	}
+	return plan, nil
+}
+
+// planPackage adds the updates of the package to the plan
+func (u *Updater) planPackage(ctx context.Context, packageDir string, plan *UpdatePlan) error {
+	file := filepath.Join(packageDir, "zarf.yaml")
+	zarfYaml, err := util.ReadZarfYaml(file)
+	if err != nil {
+		return fmt.Errorf("failed to read package %s: %w", packageDir, err)
+	}
+	d, err := yamledit.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	add := func(update Update, path ...interface{}) {
+		update.Package, update.File = packageDir, file
+		update.Field = yamledit.FormatPath(path)
+		update.Line, update.Column, _ = d.Position(path...)
+		if update.Bump == "" {
+			update.Bump = versionBump(update.From, update.To)
+		}
+		plan.Updates = append(plan.Updates, update)
+	}
+	failed := func(format string, args ...interface{}) {
+		plan.Errors = append(plan.Errors, fmt.Sprintf("%s: ", packageDir)+fmt.Sprintf(format, args...))
+	}
+
+	for i, component := range zarfYaml.Components {
+		if u.wants(UpdateChart) {
+			for j, chart := range component.Charts {
+				if !remoteChart(chart) {
+					continue
+				}
+				versions, err := u.Charts.Versions(ctx, chart)
+				if err != nil {
+					failed("could not read the versions of chart '%s': %v", chart.Name, err)
+					continue
+				}
+				latest := latestVersion(versions)
+				if bump := versionBump(chart.Version, latest); bump == BumpMajor || bump == BumpMinor || bump == BumpPatch {
+					add(Update{Type: UpdateChart, Component: component.Name, Name: chart.Name, From: chart.Version, To: latest,
+						Value: chart.Version, NewValue: latest}, "components", i, "charts", j, "version")
+				}
+			}
+		}
+
+		if u.wants(UpdateImage) {
+			for j, image := range component.Images {
+				if isTemplated(image) {
+					continue
+				}
+				reference, digest, pinned := strings.Cut(image, "@")
+				repository := imageRepository(reference)
+				if repository == reference {
+					continue
+				}
+				tag := strings.TrimPrefix(reference, repository+":")
+				tags, err := u.Images.repositoryTags(ctx, repository)
+				if err != nil {
+					failed("could not list the tags of image %s: %v", image, err)
+					continue
+				}
+				newer := newerPatchTag(tag, tags)
+				if newer == "" {
+					continue
+				}
+				updated := repository + ":" + newer
+				if pinned && digest != "" {
+					resolved, err := u.ResolveDigest(ctx, updated)
+					if err != nil {
+						failed("could not resolve the digest of image %s: %v", updated, err)
+						continue
+					}
+					updated += "@" + resolved
+				}
+				add(Update{Type: UpdateImage, Component: component.Name, Name: repository, From: tag, To: newer, Bump: BumpPatch,
+					Value: image, NewValue: updated}, "components", i, "images", j)
+			}
+		}
+
+		if u.wants(UpdateRepo) {
+			for j, repo := range component.Repos {
+				url, ref, ok := repoTag(repo)
+				if !ok {
+					continue
+				}
+				tags, err := u.ListTags(ctx, url)
+				if err != nil {
+					failed("could not list the tags of repository %s: %v", url, err)
+					continue
+				}
+				tag := strings.TrimPrefix(ref, "refs/tags/")
+				newer := newerPatchTag(tag, tags)
+				if newer == "" {
+					continue
+				}
+				add(Update{Type: UpdateRepo, Component: component.Name, Name: url, From: tag, To: newer, Bump: BumpPatch,
+					Value: repo, NewValue: url + "@" + strings.TrimSuffix(ref, tag) + newer}, "components", i, "repos", j)
+			}
+		}
+	}
+	return nil
+}
+
+// repoTag splits a Git repository of a component into its URL and the tag it is
+// pinned to, e.g. 'https://github.com/org/repo.git@v1.2.0' or '...@refs/tags/v1.2.0'.
+// Repositories not pinned to a tag with a patch version are not.
+func repoTag(repo string) (string, string, bool) {
+	url, ref := splitRepoRef(repo)
+	tag := strings.TrimPrefix(ref, "refs/tags/")
+	if strings.ContainsAny(tag, "/:") || !patchTagPattern.MatchString(tag) {
+		return "", "", false
+	}
+	return url, ref, true
+}
+
+// gitTags lists the tags of a Git repository with 'git ls-remote'
+func gitTags(ctx context.Context, url string) ([]string, error) {
+	output, err := exec.NewProcessExecutor(false).RunProcessAndCaptureStdout(ctx, "git", "ls-remote", "--tags", "--refs", url)
+	if err != nil {
+		return nil, err
+	}
+	var tags []string
+	for _, line := range strings.Split(output, "\n") {
+		if _, ref, ok := strings.Cut(line, "\t"); ok {
+			tags = append(tags, strings.TrimPrefix(strings.TrimSpace(ref), "refs/tags/"))
+		}
+	}
+	return tags, nil
+}
+
+// ApplyUpdates writes the updates to their files. Updates whose value changed since
+// the plan was made are skipped and returned as errors, the other updates are applied.
+func ApplyUpdates(updates []Update) ([]Update, []error) {
+	byFile := map[string][]Update{}
+	var files []string
+	for _, update := range updates {
+		if _, ok := byFile[update.File]; !ok {
+			files = append(files, update.File)
+		}
+		byFile[update.File] = append(byFile[update.File], update)
+	}
+	sort.Strings(files)
+
+	var applied []Update
+	var errs []error
+	for _, file := range files {
+		d, err := yamledit.ReadFile(file)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		changed := false
+		for _, update := range byFile[file] {
+			path, err := yamledit.ParsePath(update.Field)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", file, err))
+				continue
+			}
+			if current, ok := d.Get(path...); !ok || current != update.Value {
+				errs = append(errs, fmt.Errorf("%s: %s is no longer %s, skipped updating it to %s", file, update.Field, update.Value, update.NewValue))
+				continue
+			}
+			if err := d.Set(update.NewValue, path...); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", file, err))
+				continue
+			}
+			applied = append(applied, update)
+			changed = true
+		}
+		if changed {
+			if err := d.WriteFile(file); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return applied, errs
+}
@@ -0,0 +1,117 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoTag(t *testing.T) {
+	url, ref, ok := repoTag("https://github.com/org/repo.git@v1.2.0")
+	assert.True(t, ok)
+	assert.Equal(t, "https://github.com/org/repo.git", url)
+	assert.Equal(t, "v1.2.0", ref)
+	_, ref, ok = repoTag("https://github.com/org/repo.git@refs/tags/1.2.0")
+	assert.True(t, ok)
+	assert.Equal(t, "refs/tags/1.2.0", ref)
+	_, _, ok = repoTag("https://github.com/org/repo.git@refs/heads/main")
+	assert.False(t, ok)
+	_, _, ok = repoTag("https://github.com/org/repo.git")
+	assert.False(t, ok)
+}
+
+func TestUpdatePlan(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "apiVersion: v1\nentries:\n  podinfo:\n    - version: 6.3.0\n    - version: 6.5.1\n")
+	}))
+	defer server.Close()
+	fakeCommands(t, map[string]string{"crane": `case "$1 $2" in
+"ls nginx") echo 1.25.3; echo 1.25.4 ;;
+"ls ghcr.io/example/api") echo 2.0.0; echo 2.0.1 ;;
+"digest ghcr.io/example/api:2.0.1") echo sha256:new ;;
+*) exit 1 ;;
+esac`})
+
+	packageDir := t.TempDir()
+	zarfYaml := fmt.Sprintf(`kind: ZarfPackageConfig
+metadata:
+  name: web
+components:
+  - name: web
+    charts:
+      - name: podinfo
+        url: %s
+        version: 6.3.0 # pinned
+    images:
+      - nginx:1.25.3
+      - "ghcr.io/example/api:2.0.0@sha256:old"
+    repos:
+      - https://github.com/example/config.git@v1.0.0
+      - https://github.com/example/docs.git
+`, server.URL)
+	file := filepath.Join(packageDir, "zarf.yaml")
+	require.NoError(t, os.WriteFile(file, []byte(zarfYaml), 0644))
+
+	updater := NewUpdater()
+	updater.ListTags = func(_ context.Context, url string) ([]string, error) {
+		return []string{"v1.0.0", "v1.0.2", "v1.1.0"}, nil
+	}
+	plan, err := updater.Plan(context.Background(), []string{packageDir})
+	require.NoError(t, err)
+	assert.Empty(t, plan.Errors)
+	assert.Equal(t, []Update{
+		{Type: UpdateChart, Package: packageDir, Component: "web", Name: "podinfo", From: "6.3.0", To: "6.5.1", Bump: BumpMinor,
+			File: file, Line: 9, Column: 18, Field: "components[0].charts[0].version", Value: "6.3.0", NewValue: "6.5.1"},
+		{Type: UpdateImage, Package: packageDir, Component: "web", Name: "nginx", From: "1.25.3", To: "1.25.4", Bump: BumpPatch,
+			File: file, Line: 11, Column: 9, Field: "components[0].images[0]", Value: "nginx:1.25.3", NewValue: "nginx:1.25.4"},
+		{Type: UpdateImage, Package: packageDir, Component: "web", Name: "ghcr.io/example/api", From: "2.0.0", To: "2.0.1", Bump: BumpPatch,
+			File: file, Line: 12, Column: 9, Field: "components[0].images[1]", Value: "ghcr.io/example/api:2.0.0@sha256:old",
+			NewValue: "ghcr.io/example/api:2.0.1@sha256:new"},
+		{Type: UpdateRepo, Package: packageDir, Component: "web", Name: "https://github.com/example/config.git", From: "v1.0.0", To: "v1.0.2",
+			Bump: BumpPatch, File: file, Line: 14, Column: 9, Field: "components[0].repos[0]",
+			Value: "https://github.com/example/config.git@v1.0.0", NewValue: "https://github.com/example/config.git@v1.0.2"},
+	}, plan.Updates)
+
+	updater.Types = []string{UpdateChart}
+	charts, err := updater.Plan(context.Background(), []string{packageDir})
+	require.NoError(t, err)
+	assert.Len(t, charts.Updates, 1)
+
+	// Updates of values changed since the plan was made are skipped
+	plan.Updates[1].Value = "nginx:1.25.2"
+	applied, errs := ApplyUpdates(plan.Updates)
+	assert.Len(t, applied, 3)
+	require.Len(t, errs, 1)
+	assert.EqualError(t, errs[0], file+": components[0].images[0] is no longer nginx:1.25.2, skipped updating it to nginx:1.25.4")
+	content, err := os.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), `
+        version: 6.5.1 # pinned
+    images:
+      - nginx:1.25.3
+      - "ghcr.io/example/api:2.0.1@sha256:new"
+    repos:
+      - https://github.com/example/config.git@v1.0.2
+`)
+}
@@ -15,37 +15,254 @@
 package zarf
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"log/slog"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/Masterminds/semver"
 	"github.com/cpepper96/zarf-testing/pkg/exec"
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/tracing"
 	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/yamllint"
+	"gopkg.in/yaml.v3"
 )
 
+// Finding severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+	SeverityInfo    = "info"
+)
+
+// Finding is a single validation finding tagged with the rule that produced it. File
+// is relative to the package directory; Line and Column are 1-based and zero when the
+// position is unknown.
+type Finding struct {
+	RuleID   string `json:"ruleId"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
 // ValidationResult represents the result of Zarf package validation
 type ValidationResult struct {
 	PackagePath string
 	Valid       bool
 	Errors      []string
 	Warnings    []string
+	Findings    []Finding
+	Suppressed  int           // Number of findings suppressed by a baseline
+	Duration    time.Duration // Time it took to validate the package
+}
+
+// RuleInternalError is the rule of findings recording that a package could not be
+// validated or deployed at all
+const RuleInternalError = "internal-error"
+
+// AddError records an error finding for ruleID and marks the result as invalid
+func (r *ValidationResult) AddError(ruleID, message string) {
+	r.Errors = append(r.Errors, message)
+	r.Findings = append(r.Findings, Finding{RuleID: ruleID, Severity: SeverityError, Message: message})
+	r.Valid = false
+}
+
+// AddWarning records a warning finding for ruleID
+func (r *ValidationResult) AddWarning(ruleID, message string) {
+	r.Warnings = append(r.Warnings, message)
+	r.Findings = append(r.Findings, Finding{RuleID: ruleID, Severity: SeverityWarning, Message: message})
+}
+
+// AddFinding records a finding with its position
+func (r *ValidationResult) AddFinding(finding Finding) {
+	switch finding.Severity {
+	case SeverityError:
+		r.Errors = append(r.Errors, finding.Message)
+		r.Valid = false
+	case SeverityWarning:
+		r.Warnings = append(r.Warnings, finding.Message)
+	}
+	r.Findings = append(r.Findings, finding)
+}
+
+// AddInfo records an informational finding for ruleID. Info findings never fail a run.
+func (r *ValidationResult) AddInfo(ruleID, message string) {
+	r.Findings = append(r.Findings, Finding{RuleID: ruleID, Severity: SeverityInfo, Message: message})
 }
 
 // PackageValidator handles Zarf package validation
 type PackageValidator struct {
-	UseSDK bool // Whether to use Zarf SDK or fallback to basic validation
+	UseSDK      bool   // Whether to use Zarf SDK or fallback to basic validation
+	KubeVersion string // Target Kubernetes version for manifest API deprecation checks
+
+	// CheckVersionIncrement compares each package against Since, or the merge base
+	// of Remote/TargetBranch and HEAD, and requires a version bump when it changed
+	CheckVersionIncrement bool
+	Remote                string
+	TargetBranch          string
+	Since                 string
+
+	// RequireMajorBumpOnRemoval makes removing components without a breaking
+	// version bump an error
+	RequireMajorBumpOnRemoval bool
+
+	// YamlLintConfig enables YAML linting of the package files when set
+	YamlLintConfig *yamllint.Config
+
+	// Files checked into Git (and not tracked with Git LFS) larger than
+	// LargeFileWarning are warned about, larger than LargeFileLimit are errors.
+	// A value of zero disables the check.
+	LargeFileWarning int64
+	LargeFileLimit   int64
+
+	// ZarfVersion is the version of the zarf CLI, detected when linting if empty.
+	// Packages with a newer minZarfVersion annotation are errors.
+	ZarfVersion string
+
+	// ZarfBinaries are zarf binaries by version that every package must build with
+	ZarfBinaries map[string]string
+
+	// ZarfArgs are additional arguments for zarf dev lint and zarf package create
+	ZarfArgs ZarfArgs
+
+	// PSSLevel is the Pod Security Standards level the workloads of a package are
+	// checked against, PSSPrivileged disables the checks
+	PSSLevel string
+
+	// ImagePolicy restricts the images of packages to approved sources when set
+	ImagePolicy *ImagePolicy
+
+	// ValidateRBAC reports cluster-admin bindings and wildcard RBAC rules,
+	// ValidateNetworkPolicies namespaces with workloads but without a NetworkPolicy
+	ValidateRBAC            bool
+	ValidateNetworkPolicies bool
+
+	// ScanSecrets scans the package files for credentials. Findings whose secret or
+	// file path matches a SecretsAllowlist pattern are skipped.
+	ScanSecrets      bool
+	SecretsAllowlist []*regexp.Regexp
+
+	// ResolveOCIImports reads the skeleton packages imported by URL with zarf to check
+	// that they define the imported components
+	ResolveOCIImports bool
+
+	// StrictYaml reports keys of zarf.yaml that the Zarf package schema does not
+	// define, usually misspelled fields, as errors
+	StrictYaml bool
+
+	// Duplicates are the images and charts used at more than one version across the
+	// packages of the repository, see FindDuplicates. Images and charts of a package
+	// used at another version by other packages are warned about when set.
+	Duplicates *Duplicates
+
+	// NamespaceUsers are the components deploying to each namespace across the
+	// packages of the repository, see FindNamespaceUsers. Namespaces of a package
+	// other packages deploy to as well are warned about when set.
+	NamespaceUsers map[string][]ImageUser
+
+	// ChartDrift compares the charts pulled from Helm repositories and OCI registries
+	// with the latest version of their repository when set, and warns about charts
+	// further behind than the policy allows
+	ChartDrift        *StalenessPolicy
+	chartRepositories *ChartRepositories
+
+	// ActionRules are the severities of the action command rules by rule ID, see
+	// DefaultActionRules
+	ActionRules map[string]string
+
+	// ValidateDocs enables the docs rules requiring a README.md and descriptions of the
+	// components and variables, for packages published in a catalog
+	ValidateDocs bool
+
+	// RequireMaintainers requires packages to declare their maintainers and to be
+	// covered by the CODEOWNERS file of the repository, if there is one
+	RequireMaintainers bool
+
+	// CheckRepos verifies with 'git ls-remote' that the Git repositories of components
+	// are reachable and define the refs they are pinned to
+	CheckRepos bool
+	// RepoSizeBudget warns about packages whose Git repositories are estimated to be
+	// larger in total, in bytes. Zero disables the estimate
+	RepoSizeBudget int64
+	gitRemotes     *GitRemotes
+
+	// Architecture is the architecture packages are tested on, used to warn about
+	// packages without components for it. Defaults to the architecture of the
+	// package, else the one zt runs on
+	Architecture string
+
+	// NamingPolicies are the naming conventions by entity, see DefaultNamingPolicies
+	NamingPolicies map[string]NamingPolicy
+
+	// ConfinePaths rejects packages referring to paths outside of their directory
+	// with file-reference errors, and validates them no further, for packages
+	// submitted by untrusted users
+	ConfinePaths bool
+
+	// DefinitionOnly validates package definitions without the files they refer to,
+	// such as ZarfPackage resources: the rules reading the local files of a package
+	// are skipped, remote references are validated as usual
+	DefinitionOnly bool
+
+	// Plugins are run against every package after the built-in rules
+	Plugins []Plugin
+
+	// Incremental only runs the rules depending on the files changed since Since, or
+	// the merge base of Remote/TargetBranch and HEAD
+	Incremental bool
+	changes     *packageChanges
+
+	// KeepGoing makes ValidatePackages record an error validating a package as an
+	// internal-error finding of the package and continue with the next package,
+	// instead of discarding all results and returning the error
+	KeepGoing bool
+
+	// PackageStarted and PackageFinished are called by ValidatePackages before and
+	// after validating each package when set, e.g. to report progress
+	PackageStarted  func(path string)
+	PackageFinished func(result *ValidationResult)
 }
 
+// Default size thresholds for files checked into Git, matching the limits of
+// common Git hosting services
+const (
+	DefaultLargeFileWarning = 50 << 20
+	DefaultLargeFileLimit   = 100 << 20
+)
+
 // NewPackageValidator creates a new package validator
 func NewPackageValidator() *PackageValidator {
 	return &PackageValidator{
-		UseSDK: true, // Try SDK first, fallback if it fails
+		UseSDK:                  true, // Try SDK first, fallback if it fails
+		CheckVersionIncrement:   true,
+		Remote:                  "origin",
+		TargetBranch:            "main",
+		Since:                   "HEAD",
+		LargeFileWarning:        DefaultLargeFileWarning,
+		LargeFileLimit:          DefaultLargeFileLimit,
+		PSSLevel:                PSSBaseline,
+		ValidateRBAC:            true,
+		ValidateNetworkPolicies: true,
+		ScanSecrets:             true,
+		NamingPolicies:          DefaultNamingPolicies(),
+		ActionRules:             DefaultActionRules(),
+		chartRepositories:       NewChartRepositories(),
+		gitRemotes:              NewGitRemotes(),
+		KeepGoing:               true,
 	}
 }
 
 // ValidatePackage validates a Zarf package at the given path
-func (v *PackageValidator) ValidatePackage(packagePath string) (*ValidationResult, error) {
+func (v *PackageValidator) ValidatePackage(ctx context.Context, packagePath string) (*ValidationResult, error) {
 	result := &ValidationResult{
 		PackagePath: packagePath,
 		Valid:       false,
@@ -53,32 +270,148 @@ func (v *PackageValidator) ValidatePackage(packagePath string) (*ValidationResul
 		Warnings:    []string{},
 	}
 	
+	slog.Info("Validating package", "package", packagePath)
+
 	// First check if this is actually a Zarf package
 	if !IsZarfPackage(packagePath) {
-		result.Errors = append(result.Errors, "Directory does not contain a zarf.yaml file")
+		result.AddError("package-structure", "Directory does not contain a zarf.yaml file")
 		return result, nil
 	}
 	
+	// References outside of the package are rejected before anything reads them
+	if v.ConfinePaths {
+		pkg, err := LoadPackageContext(packagePath)
+		if err == nil && !validateConfinedPaths(pkg, result) {
+			locateFindings(packagePath, result)
+			return result, nil
+		}
+	}
+
 	// Try SDK validation first
 	if v.UseSDK {
-		sdkResult, err := v.validateWithSDK(packagePath)
+		sdkCtx, span := tracing.Start(ctx, "lint.zarf", "package", packagePath)
+		sdkResult, err := v.validateWithSDK(sdkCtx, packagePath)
+		span.End(err)
 		if err != nil {
 			// SDK failed, log warning and fall back to basic validation
-			result.Warnings = append(result.Warnings, fmt.Sprintf("Zarf CLI validation failed, falling back to basic validation: %v", err))
+			result.AddWarning("zarf-lint", fmt.Sprintf("Zarf CLI validation failed, falling back to basic validation: %v", err))
 			v.UseSDK = false // Disable SDK for future calls in this session
 		} else {
 			// Add indicator that we used Zarf CLI validation
-			sdkResult.Warnings = append(sdkResult.Warnings, "Validated using Zarf CLI")
+			sdkResult.AddInfo("zarf-lint", "Validated using Zarf CLI")
+			locateFindings(packagePath, sdkResult)
 			return sdkResult, nil
 		}
 	}
 	
-	// Fallback to basic validation
-	return v.validateBasic(packagePath)
+	// Fallback to basic validation, keeping the reason for the fallback
+	basicResult, err := v.validateBasic(ctx, packagePath)
+	if err != nil {
+		return nil, err
+	}
+	basicResult.Warnings = append(result.Warnings, basicResult.Warnings...)
+	basicResult.Findings = append(result.Findings, basicResult.Findings...)
+	locateFindings(packagePath, basicResult)
+	return basicResult, nil
+}
+
+// PackageContext is a package loaded once for validation and passed to every rule
+type PackageContext struct {
+	Path     string             // Directory of the package
+	Content  []byte             // Raw content of zarf.yaml
+	ZarfYaml *util.ZarfYaml     // Parsed zarf.yaml
+	Problems []util.YamlProblem // Problems found parsing zarf.yaml in strict mode
+	Node     *yaml.Node         // Document node of zarf.yaml, for the positions of findings
+
+	rendered []renderedManifest // Manifests of the components, see renderedManifests
+}
+
+// LoadPackageContext reads and parses the zarf.yaml of the package at path, keeping
+// the problems found in strict mode for validateYamlProblems
+func LoadPackageContext(path string) (*PackageContext, error) {
+	content, err := os.ReadFile(filepath.Join(path, "zarf.yaml"))
+	if err != nil {
+		return nil, fmt.Errorf("could not read 'zarf.yaml': %w", err)
+	}
+	zarfYaml, node, problems, err := util.ParseZarfYamlNode(content, true)
+	if err != nil {
+		return nil, err
+	}
+	return &PackageContext{Path: path, Content: content, ZarfYaml: zarfYaml, Problems: problems, Node: node}, nil
+}
+
+// packageRule is a validation pass over a loaded package. Findings are added to the
+// result, errors abort the validation of the package.
+type packageRule struct {
+	name   string
+	inputs ruleInputs // Files the rule depends on, for incremental validation
+	check  func(ctx context.Context, pkg *PackageContext, result *ValidationResult) error
+}
+
+// withoutContext adapts a rule that does not need a context
+func withoutContext(check func(pkg *PackageContext, result *ValidationResult) error) func(context.Context, *PackageContext, *ValidationResult) error {
+	return func(_ context.Context, pkg *PackageContext, result *ValidationResult) error {
+		return check(pkg, result)
+	}
+}
+
+// withFiles adapts a rule that reads or renders the local files of a package, which
+// is skipped with DefinitionOnly
+func (v *PackageValidator) withFiles(check func(ctx context.Context, pkg *PackageContext, result *ValidationResult) error) func(context.Context, *PackageContext, *ValidationResult) error {
+	return func(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+		if v.DefinitionOnly {
+			return nil
+		}
+		return check(ctx, pkg, result)
+	}
+}
+
+// rules returns the validation passes run after zarf dev lint, in order
+func (v *PackageValidator) rules() []packageRule {
+	var rules []packageRule
+	if v.CheckVersionIncrement {
+		rules = append(rules, packageRule{"version increment validation", anyFile, v.validateVersionIncrement})
+	}
+	return append(rules,
+		packageRule{"YAML parse validation", zarfYamlOnly, withoutContext(v.validateYamlProblems)},
+		packageRule{"image pinning validation", zarfYamlOnly, withoutContext(v.validateImagePinning)},
+		packageRule{"component validation", zarfYamlOnly, withoutContext(v.validateComponents)},
+		packageRule{"naming validation", zarfYamlOnly, withoutContext(v.validateNaming)},
+		packageRule{"init package validation", zarfYamlOnly, withoutContext(v.validateInitPackage)},
+		packageRule{"component dependency validation", zarfYamlOnly, withoutContext(v.validateComponentDependencies)},
+		packageRule{"component group validation", zarfYamlOnly, withoutContext(v.validateComponentGroups)},
+		packageRule{"only validation", zarfYamlOnly, withoutContext(v.validateOnly)},
+		packageRule{"repository validation", zarfYamlOnly, v.validateRepos},
+		packageRule{"deprecation validation", zarfYamlOnly, withoutContext(v.validateDeprecations)},
+		packageRule{"action validation", zarfYamlOnly, withoutContext(v.validateActions)},
+		packageRule{"wait target validation", anyFile, v.validateWaitTargets},
+		packageRule{"CRD ordering validation", anyFile, v.validateCRDOrdering},
+		packageRule{"documentation validation", anyFile, withoutContext(v.validateDocs)},
+		packageRule{"maintainers validation", ownership, withoutContext(v.validateMaintainers)},
+		packageRule{"data injection validation", anyFile, withoutContext(v.validateDataInjections)},
+		packageRule{"import validation", anyFile, v.validateImports},
+		packageRule{"zarf config validation", anyFile, v.validateZarfConfig},
+		packageRule{"security validation", anyFile, v.validateSecurityBestPractices},
+		packageRule{"secret scanning", anyFile, v.validateSecrets},
+		packageRule{"image policy validation", zarfYamlOnly, v.validateImagePolicy},
+		packageRule{"chart drift validation", zarfYamlOnly, v.validateChartDrift},
+		packageRule{"duplicate version validation", anyFile, v.validateDuplicateVersions},
+		packageRule{"RBAC validation", anyFile, v.validateRBAC},
+		packageRule{"network policy validation", anyFile, v.validateNetworkPolicies},
+		packageRule{"namespace validation", anyFile, v.validateNamespaces},
+		packageRule{"resource validation", anyFile, v.validateResourceConstraints},
+		packageRule{"file reference validation", anyFile, v.withFiles(withoutContext(v.validateFileReferences))},
+		packageRule{"manifest validation", yamlFiles, v.withFiles(withoutContext(v.validateManifests))},
+		packageRule{"kustomization validation", anyFile, v.withFiles(v.validateKustomizations)},
+		packageRule{"YAML lint", yamlFiles, withoutContext(v.validateYaml)},
+		packageRule{"zarf version validation", zarfYamlOnly, withoutContext(v.validateMinZarfVersion)},
+		packageRule{"zarf version build", anyFile, v.withFiles(v.validateZarfVersions)},
+		packageRule{"plugin validation", anyFile, v.validatePlugins},
+	)
 }
 
 // validateWithSDK attempts to validate using the Zarf CLI wrapper
-func (v *PackageValidator) validateWithSDK(packagePath string) (*ValidationResult, error) {
+func (v *PackageValidator) validateWithSDK(ctx context.Context, packagePath string) (*ValidationResult, error) {
 	result := &ValidationResult{
 		PackagePath: packagePath,
 		Valid:       true,
@@ -90,13 +423,20 @@ func (v *PackageValidator) validateWithSDK(packagePath string) (*ValidationResul
 	executor := exec.NewProcessExecutor(false) // debug = false
 	
 	// Check if zarf CLI is available
-	_, err := executor.RunProcessAndCaptureOutput("zarf", "version")
+	versionOutput, err := executor.RunProcessAndCaptureOutput(ctx, "zarf", "version")
 	if err != nil {
-		return nil, fmt.Errorf("zarf CLI not found - please install Zarf CLI for full validation: %w", err)
+		return nil, fmt.Errorf("zarf CLI not found - please install Zarf CLI or pass --install-zarf for full validation: %w", err)
+	}
+	if v.ZarfVersion == "" {
+		v.ZarfVersion, _ = ParseZarfVersion(versionOutput)
 	}
 	
 	// Run zarf dev lint on the package - we need to capture output even on error
-	cmd, err := executor.CreateProcess("zarf", "dev", "lint")
+	lintArgs, err := v.ZarfArgs.lint(packagePath)
+	if err != nil {
+		return nil, err
+	}
+	cmd, err := executor.CreateProcess(ctx, "zarf", "dev", "lint", lintArgs)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create zarf process: %w", err)
 	}
@@ -120,21 +460,21 @@ func (v *PackageValidator) validateWithSDK(packagePath string) (*ValidationResul
 						// Extract message after "ERR "
 						parts := strings.SplitN(line, " ERR ", 2)
 						if len(parts) == 2 {
-							result.Errors = append(result.Errors, parts[1])
+							result.AddError("zarf-lint", parts[1])
 						} else {
-							result.Errors = append(result.Errors, line)
+							result.AddError("zarf-lint", line)
 						}
 					} else if strings.Contains(line, " WRN ") {
 						// Extract message after "WRN "
 						parts := strings.SplitN(line, " WRN ", 2)
 						if len(parts) == 2 {
-							result.Warnings = append(result.Warnings, parts[1])
+							result.AddWarning("zarf-lint", parts[1])
 						} else {
-							result.Warnings = append(result.Warnings, line)
+							result.AddWarning("zarf-lint", line)
 						}
 					} else if strings.Contains(line, "ERROR") || strings.Contains(line, "error") || 
 					         strings.Contains(line, "FAIL") || strings.Contains(line, "fail") {
-						result.Errors = append(result.Errors, line)
+						result.AddError("zarf-lint", line)
 					}
 				}
 			}
@@ -152,108 +492,158 @@ func (v *PackageValidator) validateWithSDK(packagePath string) (*ValidationResul
 					// Extract message after "WRN "
 					parts := strings.SplitN(line, " WRN ", 2)
 					if len(parts) == 2 {
-						result.Warnings = append(result.Warnings, parts[1])
+						result.AddWarning("zarf-lint", parts[1])
 					} else {
-						result.Warnings = append(result.Warnings, line)
+						result.AddWarning("zarf-lint", line)
 					}
 				}
 			}
 		}
 	}
 	
-	// Additional zarf-testing specific validations (beyond what zarf dev lint does)
-	versionErr := v.validateVersionIncrement(packagePath, result)
-	if versionErr != nil {
-		return nil, fmt.Errorf("version increment validation failed: %w", versionErr)
-	}
-	
-	// Add image pinning validation
-	imagePinErr := v.validateImagePinning(packagePath, result)
-	if imagePinErr != nil {
-		return nil, fmt.Errorf("image pinning validation failed: %w", imagePinErr)
-	}
-	
-	// Advanced component validation rules
-	componentErr := v.validateComponents(packagePath, result)
-	if componentErr != nil {
-		return nil, fmt.Errorf("component validation failed: %w", componentErr)
+	// Additional zarf-testing specific validations (beyond what zarf dev lint does) run
+	// against the package loaded once
+	pkg, err := LoadPackageContext(packagePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load package: %w", err)
 	}
-	
-	// Validate component dependencies
-	depsErr := v.validateComponentDependencies(packagePath, result)
-	if depsErr != nil {
-		return nil, fmt.Errorf("component dependency validation failed: %w", depsErr)
+	if err := v.runRules(ctx, pkg, result); err != nil {
+		return nil, err
 	}
-	
-	// Validate security best practices
-	securityErr := v.validateSecurityBestPractices(packagePath, result)
-	if securityErr != nil {
-		return nil, fmt.Errorf("security validation failed: %w", securityErr)
+	return result, nil
+}
+
+// runRules runs the built-in rules, or only those affected by the changed files with
+// Incremental, against the loaded package
+func (v *PackageValidator) runRules(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	rules := v.rules()
+	if v.Incremental {
+		rules = v.affectedRules(ctx, pkg, rules, result)
 	}
-	
-	// Validate resource constraints and sizing
-	resourceErr := v.validateResourceConstraints(packagePath, result)
-	if resourceErr != nil {
-		return nil, fmt.Errorf("resource validation failed: %w", resourceErr)
+	for _, rule := range rules {
+		ruleCtx, span := tracing.Start(ctx, "lint.rule", "rule", rule.name)
+		err := rule.check(ruleCtx, pkg, result)
+		span.End(err)
+		if err != nil {
+			return fmt.Errorf("%s failed: %w", rule.name, err)
+		}
 	}
-	
-	return result, nil
+	return nil
 }
 
-// validateVersionIncrement checks if package version was incremented when components changed
-func (v *PackageValidator) validateVersionIncrement(packagePath string, result *ValidationResult) error {
+// validateVersionIncrement checks if package version was incremented when the package
+// changed compared to the 'since' reference, or the merge base of the target branch and
+// HEAD if it is not set
+func (v *PackageValidator) validateVersionIncrement(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
 	// This is the key validation that zarf dev lint doesn't do
-	// We need to compare with the previous version from Git
-	
-	executor := exec.NewProcessExecutor(false)
+	// We need to compare with the version on the target branch
+	packagePath := pkg.Path
+	currentContent, currentYaml := pkg.ZarfYaml, pkg.Content
 	
-	// Get the current zarf.yaml
-	currentZarfPath := filepath.Join(packagePath, "zarf.yaml")
-	currentContent, err := util.ReadZarfYaml(currentZarfPath)
-	if err != nil {
-		return fmt.Errorf("failed to read current zarf.yaml: %w", err)
+	// Git reads the repository of the package so that paths are resolved relative
+	// to it regardless of the working directory of zt
+	git := tool.NewGit(packagePath)
+	base, err := baseRef(ctx, git, v.Remote, v.TargetBranch, v.Since)
+	target := fmt.Sprintf("%s/%s", v.Remote, v.TargetBranch)
+	if errors.Is(err, tool.ErrShallowClone) {
+		result.AddWarning("version-increment",
+			fmt.Sprintf("Repository is a shallow clone without the merge base of %s and HEAD, fetch more history to check version increments", target))
+		return nil
+	} else if err != nil {
+		result.AddWarning("version-increment",
+			fmt.Sprintf("Could not determine merge base of %s and HEAD, skipping version increment check", target))
+		return nil
 	}
 	
-	// Get the previous version from Git (HEAD~1 or target branch)
-	previousContent, err := executor.RunProcessAndCaptureOutput("git", "show", "HEAD~1:"+filepath.Join(packagePath, "zarf.yaml"))
-	if err != nil {
+	previousContent, err := git.ShowFile(ctx, base, "zarf.yaml")
+	if errors.Is(err, tool.ErrFileNotFound) {
+		// A package that does not exist on the base is new
+		return nil
+	} else if err != nil {
 		// If we can't get previous version, skip this validation
-		result.Warnings = append(result.Warnings, "Could not retrieve previous package version for comparison")
+		result.AddWarning("version-increment", "Could not retrieve previous package version for comparison")
 		return nil
 	}
 	
 	previousZarf, err := util.UnmarshalZarfYaml([]byte(previousContent))
 	if err != nil {
 		// If we can't parse previous version, skip this validation  
-		result.Warnings = append(result.Warnings, "Could not parse previous package version for comparison")
+		result.AddWarning("version-increment", "Could not parse previous package version for comparison")
 		return nil
 	}
 	
 	// Compare versions
 	if currentContent.Metadata.Version == previousZarf.Metadata.Version {
 		// Versions are the same - check if package content changed
-		currentYamlStr, _ := executor.RunProcessAndCaptureOutput("cat", currentZarfPath)
-		if currentYamlStr != previousContent {
-			result.Errors = append(result.Errors, 
+		if strings.TrimSpace(string(currentYaml)) != strings.TrimSpace(previousContent) {
+			result.AddError("version-increment",
 				fmt.Sprintf("Package content changed but version not incremented (still %s)", 
 				currentContent.Metadata.Version))
-			result.Valid = false
 		}
+		return nil
 	}
 	
+	v.validateVersionPolicy(previousZarf, currentContent, result)
 	return nil
 }
 
-// validateImagePinning checks if images are pinned with digests (similar to Zarf's warnings)
-func (v *PackageValidator) validateImagePinning(packagePath string, result *ValidationResult) error {
-	// Read the zarf.yaml to check for image references
-	zarfYamlPath := filepath.Join(packagePath, "zarf.yaml")
-	content, err := os.ReadFile(zarfYamlPath)
+// baseRef returns the revision packages are compared against: since, or the merge base
+// of the target branch and HEAD if since is empty or HEAD
+func baseRef(ctx context.Context, git tool.Git, remote, targetBranch, since string) (string, error) {
+	if since != "" && since != "HEAD" {
+		return since, nil
+	}
+	return git.MergeBase(ctx, fmt.Sprintf("%s/%s", remote, targetBranch), "HEAD")
+}
+
+// validateVersionPolicy checks that a version change matches the changes of the package
+// according to SemVer: versions must not decrease, new components or images should come
+// with at least a minor bump and, if required, removed components with a breaking bump
+func (v *PackageValidator) validateVersionPolicy(previous, current *util.ZarfYaml, result *ValidationResult) {
+	previousVersion := previous.Metadata.Version
+	currentVersion := current.Metadata.Version
+	
+	cmp, err := util.CompareVersions(previousVersion, currentVersion)
 	if err != nil {
-		return fmt.Errorf("failed to read zarf.yaml for image pinning validation: %w", err)
+		// Non-SemVer versions can only be checked for equality
+		return
+	}
+	if cmp > 0 {
+		result.AddError("version-policy",
+			fmt.Sprintf("Package version decreased from %s to %s", previousVersion, currentVersion))
+		return
 	}
 	
-	contentStr := string(content)
+	added, removed := diffComponentNames(previous, current)
+	addedImages := diffImages(previous, current)
+	
+	prev, _ := semver.NewVersion(previousVersion)
+	cur, _ := semver.NewVersion(currentVersion)
+	patchOnly := prev.Major() == cur.Major() && prev.Minor() == cur.Minor()
+	if patchOnly && len(added) > 0 {
+		result.AddWarning("version-policy",
+			fmt.Sprintf("Components added (%s) with only a patch version bump from %s to %s, consider a minor bump",
+				strings.Join(added, ", "), previousVersion, currentVersion))
+	}
+	if patchOnly && len(addedImages) > 0 {
+		result.AddWarning("version-policy",
+			fmt.Sprintf("Images added (%s) with only a patch version bump from %s to %s, consider a minor bump",
+				strings.Join(addedImages, ", "), previousVersion, currentVersion))
+	}
+	
+	if v.RequireMajorBumpOnRemoval && len(removed) > 0 {
+		breaking, _ := util.BreakingChangeAllowed(previousVersion, currentVersion)
+		if !breaking {
+			result.AddError("version-policy",
+				fmt.Sprintf("Components removed (%s) require a major version bump, got %s to %s",
+					strings.Join(removed, ", "), previousVersion, currentVersion))
+		}
+	}
+}
+
+// validateImagePinning checks if images are pinned with digests (similar to Zarf's warnings)
+func (v *PackageValidator) validateImagePinning(pkg *PackageContext, result *ValidationResult) error {
+	contentStr := string(pkg.Content)
 	
 	// Look for image references in the YAML content
 	// This is a simplified check - in production you'd parse the YAML structure
@@ -285,7 +675,7 @@ func (v *PackageValidator) validateImagePinning(packagePath string, result *Vali
 			if strings.Contains(imageName, ":") && !strings.Contains(imageName, "@sha256:") {
 				// Skip if it's a variable reference
 				if !strings.HasPrefix(imageName, "{{") && !strings.HasPrefix(imageName, "${") {
-					result.Warnings = append(result.Warnings, 
+					result.AddWarning("image-pinning",
 						fmt.Sprintf("Image not pinned with digest - %s", imageName))
 				}
 			}
@@ -301,7 +691,7 @@ func (v *PackageValidator) validateImagePinning(packagePath string, result *Vali
 			if strings.Contains(imagePart, ":") && !strings.Contains(imagePart, "@sha256:") {
 				// Skip if it's a variable reference
 				if !strings.HasPrefix(imagePart, "{{") && !strings.HasPrefix(imagePart, "${") {
-					result.Warnings = append(result.Warnings, 
+					result.AddWarning("image-pinning",
 						fmt.Sprintf("Image not pinned with digest - %s", imagePart))
 				}
 			}
@@ -311,8 +701,20 @@ func (v *PackageValidator) validateImagePinning(packagePath string, result *Vali
 	return nil
 }
 
-// validateBasic performs basic validation without the Zarf SDK
-func (v *PackageValidator) validateBasic(packagePath string) (*ValidationResult, error) {
+// validateYamlProblems reports keys defined more than once and documents after the
+// first one in zarf.yaml, and keys outside of the Zarf package schema with StrictYaml
+func (v *PackageValidator) validateYamlProblems(pkg *PackageContext, result *ValidationResult) error {
+	for _, problem := range pkg.Problems {
+		if problem.Kind != util.YamlUnknownField || v.StrictYaml {
+			result.AddFinding(Finding{RuleID: problem.Kind, Severity: SeverityError, Message: problem.Message, File: "zarf.yaml", Line: problem.Line})
+		}
+	}
+	return nil
+}
+
+// validateBasic checks the metadata that zarf dev lint would otherwise check and runs
+// the built-in rules, for when the zarf CLI is not available
+func (v *PackageValidator) validateBasic(ctx context.Context, packagePath string) (*ValidationResult, error) {
 	result := &ValidationResult{
 		PackagePath: packagePath,
 		Valid:       true,
@@ -321,56 +723,71 @@ func (v *PackageValidator) validateBasic(packagePath string) (*ValidationResult,
 	}
 	
 	// Load and parse the zarf.yaml file
-	zarfYamlPath := filepath.Join(packagePath, "zarf.yaml")
-	zarfYaml, err := util.ReadZarfYaml(zarfYamlPath)
+	pkg, err := LoadPackageContext(packagePath)
 	if err != nil {
 		result.Valid = false
-		result.Errors = append(result.Errors, fmt.Sprintf("Failed to parse zarf.yaml: %v", err))
+		result.AddError("zarf-yaml", fmt.Sprintf("Failed to parse zarf.yaml: %v", err))
 		return result, nil
 	}
+	zarfYaml := pkg.ZarfYaml
 	
 	// Basic validation checks
 	if zarfYaml.Kind == "" {
-		result.Errors = append(result.Errors, "Missing 'kind' field in zarf.yaml")
-		result.Valid = false
-	} else if zarfYaml.Kind != "ZarfPackageConfig" {
-		result.Errors = append(result.Errors, fmt.Sprintf("Invalid kind '%s', expected 'ZarfPackageConfig'", zarfYaml.Kind))
-		result.Valid = false
+		result.AddError("package-kind", "Missing 'kind' field in zarf.yaml")
+	} else if zarfYaml.Kind != util.ZarfPackageKind && !zarfYaml.IsInitPackage() {
+		result.AddError("package-kind", fmt.Sprintf("Invalid kind '%s', expected '%s' or '%s'", zarfYaml.Kind, util.ZarfPackageKind, util.ZarfInitKind))
 	}
 	
 	if zarfYaml.Metadata.Name == "" {
-		result.Errors = append(result.Errors, "Missing package name in metadata")
-		result.Valid = false
+		result.AddError("package-name", "Missing package name in metadata")
 	}
 	
 	if zarfYaml.Metadata.Version == "" {
-		result.Warnings = append(result.Warnings, "No version specified in metadata")
+		result.AddWarning("package-version", "No version specified in metadata")
 	}
 	
 	if zarfYaml.Metadata.Description == "" {
-		result.Warnings = append(result.Warnings, "No description provided in metadata")
+		result.AddWarning("package-description", "No description provided in metadata")
 	}
 	
 	// Check for common naming conventions
 	if zarfYaml.Metadata.Name != "" {
 		if len(zarfYaml.Metadata.Name) > 63 {
-			result.Errors = append(result.Errors, "Package name must be 63 characters or less")
-			result.Valid = false
+			result.AddError("package-name", "Package name must be 63 characters or less")
 		}
 	}
-	
+
+	// The built-in rules do not depend on the zarf CLI
+	if err := v.runRules(ctx, pkg, result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
-// ValidatePackages validates multiple packages and returns results
-func (v *PackageValidator) ValidatePackages(packagePaths []string) ([]*ValidationResult, error) {
+// ValidatePackages validates multiple packages and returns results. Without KeepGoing,
+// the first error validating a package is returned instead.
+func (v *PackageValidator) ValidatePackages(ctx context.Context, packagePaths []string) ([]*ValidationResult, error) {
 	var results []*ValidationResult
 	
 	for _, path := range packagePaths {
-		result, err := v.ValidatePackage(path)
-		if err != nil {
+		if v.PackageStarted != nil {
+			v.PackageStarted(path)
+		}
+		start := time.Now()
+		packageCtx, span := tracing.Start(ctx, "lint.package", "package", path)
+		result, err := v.ValidatePackage(packageCtx, path)
+		span.End(err)
+		if err != nil && !v.KeepGoing {
 			return nil, fmt.Errorf("failed to validate package %s: %w", path, err)
 		}
+		if err != nil {
+			result = &ValidationResult{PackagePath: path, Valid: true}
+			result.AddError(RuleInternalError, fmt.Sprintf("Failed to validate package: %v", err))
+		}
+		result.Duration = time.Since(start)
+		if v.PackageFinished != nil {
+			v.PackageFinished(result)
+		}
 		results = append(results, result)
 	}
 	
@@ -417,35 +834,25 @@ func HasValidationErrors(results []*ValidationResult) bool {
 }
 
 // validateComponents performs advanced component validation
-func (v *PackageValidator) validateComponents(packagePath string, result *ValidationResult) error {
-	zarfYaml, err := util.ReadZarfYaml(filepath.Join(packagePath, "zarf.yaml"))
-	if err != nil {
-		return fmt.Errorf("failed to read zarf.yaml for component validation: %w", err)
-	}
+func (v *PackageValidator) validateComponents(pkg *PackageContext, result *ValidationResult) error {
+	zarfYaml := pkg.ZarfYaml
 	
 	if len(zarfYaml.Components) == 0 {
-		result.Warnings = append(result.Warnings, "Package has no components defined")
+		result.AddWarning("no-components", "Package has no components defined")
 		return nil
 	}
 	
-	// Check for component naming conventions
 	componentNames := make(map[string]bool)
 	for _, component := range zarfYaml.Components {
 		// Check for duplicate component names
 		if componentNames[component.Name] {
-			result.Errors = append(result.Errors, fmt.Sprintf("Duplicate component name: %s", component.Name))
+			result.AddError("duplicate-component", fmt.Sprintf("Duplicate component name: %s", component.Name))
 		}
 		componentNames[component.Name] = true
 		
-		// Check component naming conventions
-		if !isValidComponentName(component.Name) {
-			result.Warnings = append(result.Warnings, 
-				fmt.Sprintf("Component name '%s' doesn't follow naming conventions (lowercase, hyphens, no spaces)", component.Name))
-		}
-		
 		// Check for required components without default
 		if component.Required && component.Default {
-			result.Warnings = append(result.Warnings, 
+			result.AddWarning("redundant-default",
 				fmt.Sprintf("Component '%s' is both required and default (redundant)", component.Name))
 		}
 		
@@ -453,7 +860,7 @@ func (v *PackageValidator) validateComponents(packagePath string, result *Valida
 		if len(component.Files) == 0 && len(component.Charts) == 0 && 
 		   len(component.Manifests) == 0 && len(component.Images) == 0 && 
 		   len(component.Repos) == 0 && len(component.DataInjections) == 0 {
-			result.Warnings = append(result.Warnings, 
+			result.AddWarning("empty-component",
 				fmt.Sprintf("Component '%s' appears to be empty (no files, charts, manifests, images, etc.)", component.Name))
 		}
 	}
@@ -462,11 +869,8 @@ func (v *PackageValidator) validateComponents(packagePath string, result *Valida
 }
 
 // validateComponentDependencies checks component dependency relationships
-func (v *PackageValidator) validateComponentDependencies(packagePath string, result *ValidationResult) error {
-	zarfYaml, err := util.ReadZarfYaml(filepath.Join(packagePath, "zarf.yaml"))
-	if err != nil {
-		return fmt.Errorf("failed to read zarf.yaml for dependency validation: %w", err)
-	}
+func (v *PackageValidator) validateComponentDependencies(pkg *PackageContext, result *ValidationResult) error {
+	zarfYaml := pkg.ZarfYaml
 	
 	if len(zarfYaml.Components) == 0 {
 		return nil
@@ -483,13 +887,13 @@ func (v *PackageValidator) validateComponentDependencies(packagePath string, res
 		for _, dep := range component.DepsWith {
 			// Check if dependency exists
 			if _, exists := componentMap[dep]; !exists {
-				result.Errors = append(result.Errors, 
+				result.AddError("missing-dependency",
 					fmt.Sprintf("Component '%s' depends on non-existent component '%s'", component.Name, dep))
 			}
 			
 			// Check for circular dependencies
 			if hasDependencyCycle(component.Name, dep, componentMap, make(map[string]bool)) {
-				result.Errors = append(result.Errors, 
+				result.AddError("circular-dependency",
 					fmt.Sprintf("Circular dependency detected between '%s' and '%s'", component.Name, dep))
 			}
 		}
@@ -497,7 +901,7 @@ func (v *PackageValidator) validateComponentDependencies(packagePath string, res
 		// Check for self-dependencies
 		for _, dep := range component.DepsWith {
 			if dep == component.Name {
-				result.Errors = append(result.Errors, 
+				result.AddError("self-dependency",
 					fmt.Sprintf("Component '%s' cannot depend on itself", component.Name))
 			}
 		}
@@ -506,41 +910,105 @@ func (v *PackageValidator) validateComponentDependencies(packagePath string, res
 	return nil
 }
 
-// validateSecurityBestPractices checks for security best practices
-func (v *PackageValidator) validateSecurityBestPractices(packagePath string, result *ValidationResult) error {
-	zarfYaml, err := util.ReadZarfYaml(filepath.Join(packagePath, "zarf.yaml"))
-	if err != nil {
-		return fmt.Errorf("failed to read zarf.yaml for security validation: %w", err)
+// validateComponentGroups checks the semantics of component groups: exactly one
+// component of a group is deployed, so every group needs exactly one default
+// component, more than one member to choose from and no required members
+func (v *PackageValidator) validateComponentGroups(pkg *PackageContext, result *ValidationResult) error {
+	var groups []string
+	members := map[string][]util.ZarfComponent{}
+	for _, component := range pkg.ZarfYaml.Components {
+		if component.Group == "" {
+			continue
+		}
+		if _, ok := members[component.Group]; !ok {
+			groups = append(groups, component.Group)
+		}
+		members[component.Group] = append(members[component.Group], component)
 	}
-	
-	for _, component := range zarfYaml.Components {
-		// Check for privileged containers in manifests
-		for _, manifest := range component.Manifests {
-			for _, file := range manifest.Files {
-				if err := v.checkManifestSecurity(filepath.Join(packagePath, file), result, component.Name); err != nil {
-					result.Warnings = append(result.Warnings, 
-						fmt.Sprintf("Failed to analyze manifest security for %s: %v", file, err))
-				}
+
+	for _, group := range groups {
+		var defaults []string
+		for _, component := range members[group] {
+			if component.Default {
+				defaults = append(defaults, component.Name)
+			}
+			if component.Required {
+				result.AddError("group-required",
+					fmt.Sprintf("Component '%s' is required but belongs to group '%s', whose components are a choice", component.Name, group))
 			}
 		}
-		
-		// Check for scripts that might contain secrets
-		if len(component.Scripts.Prepare) > 0 || len(component.Scripts.Before) > 0 || len(component.Scripts.After) > 0 {
-			allScripts := append(component.Scripts.Prepare, component.Scripts.Before...)
-			allScripts = append(allScripts, component.Scripts.After...)
-			
-			for _, script := range allScripts {
-				if containsPotentialSecrets(script) {
-					result.Warnings = append(result.Warnings, 
-						fmt.Sprintf("Component '%s' script may contain hardcoded secrets or sensitive data", component.Name))
-				}
+		switch {
+		case len(defaults) == 0:
+			result.AddError("group-default",
+				fmt.Sprintf("Group '%s' has no default component, exactly one component of a group must be default", group))
+		case len(defaults) > 1:
+			result.AddError("group-default",
+				fmt.Sprintf("Group '%s' has %d default components (%s), exactly one component of a group must be default",
+					group, len(defaults), strings.Join(defaults, ", ")))
+		}
+		if len(members[group]) == 1 {
+			result.AddWarning("group-single-member",
+				fmt.Sprintf("Group '%s' only contains component '%s', there is nothing to choose from", group, members[group][0].Name))
+		}
+	}
+
+	return nil
+}
+
+// validateDeprecations checks that deprecated components document a replacement and
+// warns about dependencies on deprecated components and imports of deprecated packages
+func (v *PackageValidator) validateDeprecations(pkg *PackageContext, result *ValidationResult) error {
+	packagePath, zarfYaml := pkg.Path, pkg.ZarfYaml
+
+	componentMap := make(map[string]*util.ZarfComponent)
+	for i := range zarfYaml.Components {
+		componentMap[zarfYaml.Components[i].Name] = &zarfYaml.Components[i]
+	}
+
+	for i, component := range zarfYaml.Components {
+		field := fmt.Sprintf("components[%d]", i)
+		if component.Zt.Deprecated {
+			if component.Zt.Replacement == "" {
+				result.AddFinding(pkg.at(field+".x-zt.deprecated", "deprecation", SeverityError,
+					fmt.Sprintf("Component '%s' is deprecated but does not document a replacement", component.Name)))
+			} else if _, exists := componentMap[component.Zt.Replacement]; !exists {
+				result.AddFinding(pkg.at(field+".x-zt.replacement", "deprecation", SeverityWarning,
+					fmt.Sprintf("Component '%s' names replacement '%s' which is not a component of this package", component.Name, component.Zt.Replacement)))
 			}
 		}
-		
+
+		for j, dep := range component.DepsWith {
+			if depComponent, exists := componentMap[dep]; exists && depComponent.Zt.Deprecated {
+				result.AddFinding(pkg.at(fmt.Sprintf("%s.depsWith[%d]", field, j), "deprecation", SeverityWarning,
+					fmt.Sprintf("Component '%s' depends on deprecated component '%s'", component.Name, dep)))
+			}
+		}
+
+		if component.Import.Path != "" {
+			imported, err := util.ReadZarfYaml(filepath.Join(packagePath, component.Import.Path, "zarf.yaml"))
+			if err == nil && imported.Metadata.Deprecated {
+				result.AddFinding(pkg.at(field+".import.path", "deprecation", SeverityWarning,
+					fmt.Sprintf("Component '%s' imports deprecated package '%s'", component.Name, imported.Metadata.Name)))
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateSecurityBestPractices checks for security best practices
+func (v *PackageValidator) validateSecurityBestPractices(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	zarfYaml := pkg.ZarfYaml
+
+	if err := v.validatePodSecurity(ctx, pkg, result); err != nil {
+		return err
+	}
+
+	for _, component := range zarfYaml.Components {
 		// Check for images from untrusted registries
 		for _, image := range component.Images {
 			if isUntrustedRegistry(image) {
-				result.Warnings = append(result.Warnings, 
+				result.AddWarning("untrusted-registry",
 					fmt.Sprintf("Component '%s' uses image from potentially untrusted registry: %s", component.Name, image))
 			}
 		}
@@ -550,28 +1018,17 @@ func (v *PackageValidator) validateSecurityBestPractices(packagePath string, res
 }
 
 // validateResourceConstraints checks for resource management best practices
-func (v *PackageValidator) validateResourceConstraints(packagePath string, result *ValidationResult) error {
-	zarfYaml, err := util.ReadZarfYaml(filepath.Join(packagePath, "zarf.yaml"))
-	if err != nil {
-		return fmt.Errorf("failed to read zarf.yaml for resource validation: %w", err)
-	}
+func (v *PackageValidator) validateResourceConstraints(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	packagePath, zarfYaml := pkg.Path, pkg.ZarfYaml
 	
+	if err := v.validateLargeFiles(ctx, pkg, result); err != nil {
+		return err
+	}
+
 	for _, component := range zarfYaml.Components {
-		// Check for large file transfers
-		for _, file := range component.Files {
-			filePath := filepath.Join(packagePath, file.Source)
-			if stat, err := os.Stat(filePath); err == nil {
-				sizeInMB := stat.Size() / (1024 * 1024)
-				if sizeInMB > 100 { // Files larger than 100MB
-					result.Warnings = append(result.Warnings, 
-						fmt.Sprintf("Component '%s' includes large file (%dMB): %s", component.Name, sizeInMB, file.Source))
-				}
-			}
-		}
-		
 		// Check for excessive number of images
 		if len(component.Images) > 10 {
-			result.Warnings = append(result.Warnings, 
+			result.AddWarning("image-count",
 				fmt.Sprintf("Component '%s' includes many images (%d) which may impact package size", component.Name, len(component.Images)))
 		}
 		
@@ -590,7 +1047,7 @@ func (v *PackageValidator) validateResourceConstraints(packagePath string, resul
 			}
 			
 			if !hasResourceLimits {
-				result.Warnings = append(result.Warnings, 
+				result.AddWarning("resource-limits",
 					fmt.Sprintf("Chart '%s' in component '%s' may not specify resource limits", chart.Name, component.Name))
 			}
 		}
@@ -599,18 +1056,309 @@ func (v *PackageValidator) validateResourceConstraints(packagePath string, resul
 	return nil
 }
 
+// validateLargeFiles checks the size of the files of the package checked into Git.
+// Large files bloat every clone of the repository; they belong in Git LFS or should be
+// downloaded from a remote file source verified by a shasum. Outside of a Git
+// repository, the local files of components are checked instead.
+func (v *PackageValidator) validateLargeFiles(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	check := func(path string, size int64) {
+		const advice = "track it with Git LFS or use a remote file source with a shasum"
+		switch {
+		case v.LargeFileLimit > 0 && size > v.LargeFileLimit:
+			result.AddError("large-file", fmt.Sprintf("File %s (%s) exceeds the limit of %s for files checked into Git; %s",
+				path, util.FormatSize(size), util.FormatSize(v.LargeFileLimit), advice))
+		case v.LargeFileWarning > 0 && size > v.LargeFileWarning:
+			result.AddWarning("large-file", fmt.Sprintf("File %s (%s) is large for a file checked into Git; %s",
+				path, util.FormatSize(size), advice))
+		}
+	}
+
+	files, err := tool.NewGit(pkg.Path).TrackedFiles(ctx, ".")
+	if errors.Is(err, tool.ErrNotRepository) {
+		for _, component := range pkg.ZarfYaml.Components {
+			for _, file := range component.Files {
+				if isRemoteReference(file.Source) {
+					continue
+				}
+				if stat, err := os.Stat(filepath.Join(pkg.Path, file.Source)); err == nil && !stat.IsDir() {
+					check(file.Source, stat.Size())
+				}
+			}
+		}
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if !file.LFSPointer {
+			check(file.Path, file.Size)
+		}
+	}
+	return nil
+}
+
+// validateFileReferences checks that every local path referenced by a component exists
+// relative to the package directory
+func (v *PackageValidator) validateFileReferences(pkg *PackageContext, result *ValidationResult) error {
+	for _, reference := range fileReferences(pkg.ZarfYaml) {
+		if !util.FileExists(filepath.Join(pkg.Path, reference.path)) {
+			result.AddFinding(pkg.at(reference.field, "file-reference", SeverityError,
+				fmt.Sprintf("Component '%s' references missing path at %s: %s", reference.component, reference.field, reference.path)))
+		}
+	}
+	return nil
+}
+
+// validateManifests lints the Kubernetes manifests bundled by each component
+func (v *PackageValidator) validateManifests(pkg *PackageContext, result *ValidationResult) error {
+	packagePath, zarfYaml := pkg.Path, pkg.ZarfYaml
+
+	for i, component := range zarfYaml.Components {
+		for j, manifest := range component.Manifests {
+			for k, file := range manifest.Files {
+				manifestPath := filepath.Join(packagePath, file)
+				if isRemoteReference(file) || !util.FileExists(manifestPath) {
+					continue
+				}
+				field := fmt.Sprintf("components[%d].manifests[%d].files[%d]", i, j, k)
+
+				lintResult, err := LintManifestFile(manifestPath, v.KubeVersion)
+				if err != nil {
+					result.AddFinding(pkg.at(field, "manifest-lint", SeverityWarning,
+						fmt.Sprintf("Failed to lint manifest %s in component '%s': %v", file, component.Name, err)))
+					continue
+				}
+
+				for _, msg := range lintResult.Errors {
+					result.AddFinding(pkg.at(field, "manifest-lint", SeverityError,
+						fmt.Sprintf("Component '%s' manifest %s: %s", component.Name, file, msg)))
+				}
+				for _, msg := range lintResult.Warnings {
+					result.AddFinding(pkg.at(field, "manifest-lint", SeverityWarning,
+						fmt.Sprintf("Component '%s' manifest %s: %s", component.Name, file, msg)))
+				}
+				for _, msg := range lintResult.Infos {
+					result.AddFinding(pkg.at(field, "manifest-lint", SeverityInfo,
+						fmt.Sprintf("Component '%s' manifest %s: %s", component.Name, file, msg)))
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateKustomizations builds each local kustomization referenced by a component and
+// lints the rendered manifests
+func (v *PackageValidator) validateKustomizations(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	for _, manifest := range pkg.renderedManifests(ctx) {
+		if manifest.Kind != sourceKustomization {
+			continue
+		}
+		if errors.Is(manifest.Err, tool.ErrKustomizeNotFound) {
+			result.AddWarning("kustomize-build",
+				fmt.Sprintf("Skipping kustomization build validation: %v", manifest.Err))
+			return nil
+		}
+		if manifest.Err != nil {
+			result.AddError("kustomize-build",
+				fmt.Sprintf("Component '%s' kustomization %s failed to build: %v", manifest.Component, manifest.Path, manifest.Err))
+			continue
+		}
+
+		lintResult, err := LintManifest(manifest.Content, v.KubeVersion)
+		if err != nil {
+			continue
+		}
+		for _, msg := range lintResult.Errors {
+			result.AddError("kustomize-build",
+				fmt.Sprintf("Component '%s' kustomization %s: %s", manifest.Component, manifest.Path, msg))
+		}
+		for _, msg := range lintResult.Warnings {
+			result.AddWarning("kustomize-build",
+				fmt.Sprintf("Component '%s' kustomization %s: %s", manifest.Component, manifest.Path, msg))
+		}
+		for _, msg := range lintResult.Infos {
+			result.AddInfo("kustomize-build",
+				fmt.Sprintf("Component '%s' kustomization %s: %s", manifest.Component, manifest.Path, msg))
+		}
+	}
+
+	return nil
+}
+
+// validateYaml lints zarf.yaml and all other YAML files in the package. Helm chart
+// templates are skipped since they are not valid YAML before rendering.
+func (v *PackageValidator) validateYaml(pkg *PackageContext, result *ValidationResult) error {
+	if v.YamlLintConfig == nil {
+		return nil
+	}
+	packagePath := pkg.Path
+
+	return filepath.Walk(packagePath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			if path != packagePath && (info.Name() == "templates" || strings.HasPrefix(info.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		ext := filepath.Ext(path)
+		if ext != ".yaml" && ext != ".yml" {
+			return nil
+		}
+
+		problems, err := yamllint.LintFile(path, v.YamlLintConfig)
+		if err != nil {
+			return err
+		}
+
+		relPath, _ := filepath.Rel(packagePath, path)
+		for _, problem := range problems {
+			severity := SeverityWarning
+			if problem.Level == yamllint.LevelError {
+				severity = SeverityError
+			}
+			result.AddFinding(Finding{
+				RuleID:   "yaml-lint",
+				Severity: severity,
+				Message:  fmt.Sprintf("YAML lint %s:%d:%d: %s (%s)", relPath, problem.Line, problem.Column, problem.Message, problem.Rule),
+				File:     filepath.ToSlash(relPath),
+				Line:     problem.Line,
+				Column:   problem.Column,
+			})
+		}
+		return nil
+	})
+}
+
+// validateMinZarfVersion checks the minZarfVersion annotation of the package against
+// the version of the zarf CLI
+func (v *PackageValidator) validateMinZarfVersion(pkg *PackageContext, result *ValidationResult) error {
+	annotation, ok := pkg.ZarfYaml.Metadata.Annotations[MinZarfVersionAnnotation]
+	if !ok {
+		return nil
+	}
+	minVersion, err := semver.NewVersion(annotation)
+	if err != nil {
+		result.AddError("min-zarf-version", fmt.Sprintf("Annotation %s '%s' is not a valid version", MinZarfVersionAnnotation, annotation))
+		return nil
+	}
+	if v.ZarfVersion == "" {
+		return nil
+	}
+	if zarfVersion, err := semver.NewVersion(v.ZarfVersion); err == nil && zarfVersion.LessThan(minVersion) {
+		result.AddError("min-zarf-version",
+			fmt.Sprintf("Package requires zarf %s or newer, but zarf %s is installed", annotation, v.ZarfVersion))
+	}
+	return nil
+}
+
+// validateZarfVersions builds the package with each of the zarf binaries, skipping
+// versions older than the minZarfVersion annotation of the package
+func (v *PackageValidator) validateZarfVersions(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	var versions []*semver.Version
+	for version := range v.ZarfBinaries {
+		parsed, err := semver.NewVersion(version)
+		if err != nil {
+			return fmt.Errorf("invalid zarf version %q: %w", version, err)
+		}
+		versions = append(versions, parsed)
+	}
+	sort.Sort(semver.Collection(versions))
+
+	buildArgs, err := v.ZarfArgs.build(pkg.Path)
+	if err != nil {
+		return err
+	}
+
+	minVersion, _ := semver.NewVersion(pkg.ZarfYaml.Metadata.Annotations[MinZarfVersionAnnotation])
+	for _, version := range versions {
+		name := version.Original()
+		if minVersion != nil && version.LessThan(minVersion) {
+			result.AddInfo("zarf-version-build", fmt.Sprintf("Skipped building with zarf %s, older than %s %s", name, MinZarfVersionAnnotation, minVersion.Original()))
+			continue
+		}
+
+		outputDir, err := os.MkdirTemp("", "zt-build-")
+		if err != nil {
+			return err
+		}
+		cmd, err := exec.NewProcessExecutor(false).CreateProcess(ctx, v.ZarfBinaries[name],
+			"package", "create", ".", "--confirm", "--output", outputDir, buildArgs)
+		if err != nil {
+			return err
+		}
+		cmd.Dir = pkg.Path
+		output, err := cmd.CombinedOutput()
+		os.RemoveAll(outputDir)
+		if err != nil {
+			lines := strings.Split(strings.TrimSpace(string(output)), "\n")
+			result.AddError("zarf-version-build", fmt.Sprintf("Package does not build with zarf %s: %s", name, lines[len(lines)-1]))
+			continue
+		}
+		result.AddInfo("zarf-version-build", fmt.Sprintf("Package builds with zarf %s", name))
+	}
+	return nil
+}
+
 // Helper functions
 
-// isValidComponentName checks if component name follows conventions
-func isValidComponentName(name string) bool {
-	// Component names should be lowercase, use hyphens, no spaces
-	if strings.Contains(name, " ") {
-		return false
+// isRemoteReference reports whether a component path points to a remote location
+// (URL, OCI reference or remote kustomization) rather than a file in the package
+func isRemoteReference(path string) bool {
+	remotePrefixes := []string{"http://", "https://", "oci://", "git::", "github.com/"}
+	for _, prefix := range remotePrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
 	}
-	if strings.ToLower(name) != name {
-		return false
+	return false
+}
+
+// diffComponentNames returns the names of components added and removed between two
+// revisions of a package
+func diffComponentNames(previous, current *util.ZarfYaml) (added, removed []string) {
+	previousNames := make(map[string]bool)
+	for _, component := range previous.Components {
+		previousNames[component.Name] = true
 	}
-	return true
+	currentNames := make(map[string]bool)
+	for _, component := range current.Components {
+		currentNames[component.Name] = true
+		if !previousNames[component.Name] {
+			added = append(added, component.Name)
+		}
+	}
+	for _, component := range previous.Components {
+		if !currentNames[component.Name] {
+			removed = append(removed, component.Name)
+		}
+	}
+	return added, removed
+}
+
+// diffImages returns the images referenced by current but not by previous
+func diffImages(previous, current *util.ZarfYaml) []string {
+	previousImages := make(map[string]bool)
+	for _, component := range previous.Components {
+		for _, image := range component.Images {
+			previousImages[image] = true
+		}
+	}
+	var added []string
+	for _, component := range current.Components {
+		for _, image := range component.Images {
+			if !previousImages[image] {
+				added = append(added, image)
+				previousImages[image] = true
+			}
+		}
+	}
+	return added
 }
 
 // hasDependencyCycle detects circular dependencies using DFS
@@ -636,51 +1384,54 @@ func hasDependencyCycle(start, current string, componentMap map[string]*util.Zar
 	return false
 }
 
-// checkManifestSecurity analyzes Kubernetes manifests for security issues
-func (v *PackageValidator) checkManifestSecurity(manifestPath string, result *ValidationResult, componentName string) error {
-	content, err := os.ReadFile(manifestPath)
-	if err != nil {
-		return err
-	}
-	
-	contentStr := string(content)
-	
-	// Check for privileged security contexts
-	if strings.Contains(contentStr, "privileged: true") {
-		result.Warnings = append(result.Warnings, 
-			fmt.Sprintf("Component '%s' manifest may use privileged containers", componentName))
-	}
-	
-	// Check for host network usage
-	if strings.Contains(contentStr, "hostNetwork: true") {
-		result.Warnings = append(result.Warnings, 
-			fmt.Sprintf("Component '%s' manifest uses host networking", componentName))
-	}
-	
-	// Check for host PID/IPC
-	if strings.Contains(contentStr, "hostPID: true") || strings.Contains(contentStr, "hostIPC: true") {
-		result.Warnings = append(result.Warnings, 
-			fmt.Sprintf("Component '%s' manifest uses host PID or IPC", componentName))
+// validatePodSecurity evaluates the workloads of the manifests, kustomizations and local
+// charts of every component against the Pod Security Standards level PSSLevel
+func (v *PackageValidator) validatePodSecurity(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	if v.PSSLevel == PSSPrivileged {
+		return nil
 	}
-	
-	return nil
-}
 
-// containsPotentialSecrets checks scripts for potential hardcoded secrets
-func containsPotentialSecrets(script string) bool {
-	secretPatterns := []string{
-		"password=", "PASSWORD=", "token=", "TOKEN=", "secret=", "SECRET=",
-		"key=", "KEY=", "api_key", "API_KEY", "aws_access", "AWS_ACCESS",
-	}
-	
-	scriptLower := strings.ToLower(script)
-	for _, pattern := range secretPatterns {
-		if strings.Contains(scriptLower, strings.ToLower(pattern)) {
-			return true
+	helmMissing := false
+	for _, manifest := range pkg.renderedManifests(ctx) {
+		switch {
+		case manifest.Err == nil:
+		case manifest.Kind == sourceManifest:
+			result.AddWarning("pod-security",
+				fmt.Sprintf("Failed to analyze manifest security for %s: %v", manifest.Path, manifest.Err))
+			continue
+		case errors.Is(manifest.Err, tool.ErrHelmNotFound):
+			if !helmMissing {
+				result.AddWarning("pod-security", fmt.Sprintf("Skipping Pod Security Standards checks of charts: %v", manifest.Err))
+			}
+			helmMissing = true
+			continue
+		case manifest.Kind == sourceChart:
+			result.AddWarning("pod-security",
+				fmt.Sprintf("Component '%s' chart %s failed to render: %v", manifest.Component, manifest.Path, manifest.Err))
+			continue
+		default:
+			// Kustomization build failures are reported by the kustomization validation
+			continue
+		}
+
+		for _, doc := range manifest.documents() {
+			kind, _ := doc["kind"].(string)
+			metadata, _ := toStringMap(doc["metadata"])
+			for _, violation := range checkPodSecurity(doc, v.PSSLevel) {
+				finding := Finding{
+					RuleID:   violation.RuleID,
+					Severity: SeverityWarning,
+					Message: fmt.Sprintf("Component '%s' %s %s: %s/%v %s (%s)", manifest.Component, manifest.Kind, manifest.Path,
+						kind, metadata["name"], violation.Message, violation.Level),
+				}
+				if manifest.Kind == sourceManifest {
+					finding.File = manifest.Path
+				}
+				result.AddFinding(finding)
+			}
 		}
 	}
-	
-	return false
+	return nil
 }
 
 // isUntrustedRegistry checks if an image comes from a potentially untrusted registry
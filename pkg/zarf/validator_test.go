@@ -0,0 +1,455 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadPackage(t *testing.T, path string) *PackageContext {
+	pkg, err := LoadPackageContext(path)
+	require.NoError(t, err)
+	return pkg
+}
+
+func TestValidateFileReferences(t *testing.T) {
+	v := NewPackageValidator()
+	result := &ValidationResult{Valid: true}
+
+	err := v.validateFileReferences(loadPackage(t, "testdata/file_references"), result)
+	require.NoError(t, err)
+
+	assert.False(t, result.Valid)
+	assert.Equal(t, []string{
+		"Component 'files' references missing path at components[0].files[1].source: missing.txt",
+		"Component 'files' references missing path at components[0].manifests[0].files[1]: manifests/service.yaml",
+		"Component 'files' references missing path at components[0].dataInjections[0].source: data",
+	}, result.Errors)
+}
+
+func TestValidatePackageWithoutZarfCLI(t *testing.T) {
+	t.Setenv("PATH", t.TempDir())
+
+	result, err := NewPackageValidator().ValidatePackage(context.Background(), "testdata/file_references")
+	require.NoError(t, err)
+
+	// The built-in rules still run when zt falls back from zarf dev lint
+	assert.False(t, result.Valid)
+	assert.Contains(t, result.Errors, "Component 'files' references missing path at components[0].files[1].source: missing.txt")
+}
+
+func TestValidateYamlProblems(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\ncomponents:\n  - name: web\n    requried: true\n    name: app\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	pkg := loadPackage(t, packageDir)
+
+	// The component is validated as it is first defined
+	assert.Equal(t, "web", pkg.ZarfYaml.Components[0].Name)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{Valid: true}
+	require.NoError(t, v.validateYamlProblems(pkg, result))
+	assert.False(t, result.Valid)
+	assert.Equal(t, []Finding{
+		{RuleID: "duplicate-key", Severity: SeverityError, Message: "Key 'name' in components[web] is defined more than once, first at line 5", File: "zarf.yaml", Line: 7},
+	}, result.Findings)
+
+	v.StrictYaml = true
+	result = &ValidationResult{Valid: true}
+	require.NoError(t, v.validateYamlProblems(pkg, result))
+	assert.Equal(t, []string{
+		"Unknown field 'requried' in components[web]",
+		"Key 'name' in components[web] is defined more than once, first at line 5",
+	}, result.Errors)
+}
+
+func TestLocateMessage(t *testing.T) {
+	lines := []string{
+		"kind: ZarfPackageConfig",
+		"metadata:",
+		"  name: app",
+		"components:",
+		"  - name: app",
+		"    images:",
+		"      - nginx:1.25",
+	}
+
+	testCases := []struct {
+		message string
+		line    int
+		column  int
+	}{
+		{"Component 'app' is both required and default (redundant)", 5, 5},
+		{"Image not pinned with digest - nginx:1.25", 7, 9},
+		{"No description provided in metadata", 2, 1},
+		{"Validated using Zarf CLI", 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.message, func(t *testing.T) {
+			line, column := locateMessage(lines, tc.message)
+			assert.Equal(t, tc.line, line)
+			assert.Equal(t, tc.column, column)
+		})
+	}
+}
+
+func TestLocateField(t *testing.T) {
+	content := `kind: ZarfPackageConfig
+metadata:
+  name: app
+x-common: &common
+  files:
+    - source: common.txt
+components:
+  - name: app
+    manifests:
+      - name: app
+        files:
+          - manifests/app.yaml
+  - <<: *common
+    name: merged
+`
+	_, node, _, err := util.ParseZarfYamlNode([]byte(content), false)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		field  string
+		line   int
+		column int
+	}{
+		{"components[0]", 8, 5},
+		{"components[0].manifests[0].files[0]", 12, 13},
+		{"components[1].files[0].source", 6, 15},
+		{"components[1].name", 14, 11},
+		{"components[2].name", 0, 0},
+		{"metadata.version", 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.field, func(t *testing.T) {
+			line, column := locateField(node, tc.field)
+			assert.Equal(t, tc.line, line)
+			assert.Equal(t, tc.column, column)
+		})
+	}
+}
+
+func TestValidateVersionIncrement(t *testing.T) {
+	repo := t.TempDir()
+	packageDir := filepath.Join(repo, "packages", "app")
+	require.NoError(t, os.MkdirAll(packageDir, 0755))
+
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		cmd.Dir = repo
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	commit := func(version, description string) {
+		content := "kind: ZarfPackageConfig\nmetadata:\n  name: app\n  version: " + version + "\n  description: " + description + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(content), 0644))
+		git("add", "-A")
+		git("commit", "-q", "-m", description)
+	}
+
+	git("init", "-q")
+	commit("1.0.0", "initial")
+	git("update-ref", "refs/remotes/origin/main", "HEAD")
+
+	// Several commits on the branch must be compared to the target branch, not HEAD~1
+	commit("1.0.0", "first change")
+	commit("1.0.0", "second change")
+
+	v := NewPackageValidator()
+	result := &ValidationResult{Valid: true}
+	require.NoError(t, v.validateVersionIncrement(context.Background(), loadPackage(t, packageDir), result))
+	assert.Equal(t, []string{"Package content changed but version not incremented (still 1.0.0)"}, result.Errors)
+
+	commit("1.0.1", "bump")
+	result = &ValidationResult{Valid: true}
+	require.NoError(t, v.validateVersionIncrement(context.Background(), loadPackage(t, packageDir), result))
+	assert.True(t, result.Valid)
+	assert.Empty(t, result.Findings)
+
+	// With --since the package is compared to the given reference instead
+	git("tag", "v1.0.1")
+	commit("1.0.1", "after release")
+	v.Since = "v1.0.1"
+	result = &ValidationResult{Valid: true}
+	require.NoError(t, v.validateVersionIncrement(context.Background(), loadPackage(t, packageDir), result))
+	assert.Equal(t, []string{"Package content changed but version not incremented (still 1.0.1)"}, result.Errors)
+}
+
+func TestValidateVersionPolicy(t *testing.T) {
+	pkg := func(version string, components ...util.ZarfComponent) *util.ZarfYaml {
+		zarfYaml := &util.ZarfYaml{Components: components}
+		zarfYaml.Metadata.Version = version
+		return zarfYaml
+	}
+	app := util.ZarfComponent{Name: "app", Images: []string{"nginx:1.25"}}
+	appWithSidecar := util.ZarfComponent{Name: "app", Images: []string{"nginx:1.25", "envoy:1.30"}}
+	db := util.ZarfComponent{Name: "db"}
+
+	testCases := []struct {
+		name           string
+		previous       *util.ZarfYaml
+		current        *util.ZarfYaml
+		requireMajor   bool
+		expectErrors   []string
+		expectWarnings []string
+	}{
+		{"version decreased", pkg("1.2.0", app), pkg("1.1.0", app), false,
+			[]string{"Package version decreased from 1.2.0 to 1.1.0"}, nil},
+		{"component added with patch bump", pkg("1.2.0", app), pkg("1.2.1", app, db), false,
+			nil, []string{"Components added (db) with only a patch version bump from 1.2.0 to 1.2.1, consider a minor bump"}},
+		{"image added with patch bump", pkg("1.2.0", app), pkg("1.2.1", appWithSidecar), false,
+			nil, []string{"Images added (envoy:1.30) with only a patch version bump from 1.2.0 to 1.2.1, consider a minor bump"}},
+		{"component added with minor bump", pkg("1.2.0", app), pkg("1.3.0", app, db), false, nil, nil},
+		{"component removed without major bump", pkg("1.2.0", app, db), pkg("1.3.0", app), true,
+			[]string{"Components removed (db) require a major version bump, got 1.2.0 to 1.3.0"}, nil},
+		{"component removed with major bump", pkg("1.2.0", app, db), pkg("2.0.0", app), true, nil, nil},
+		{"component removed without policy", pkg("1.2.0", app, db), pkg("1.3.0", app), false, nil, nil},
+		{"non-semver versions", pkg("latest", app), pkg("nightly", app), false, nil, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			v := NewPackageValidator()
+			v.RequireMajorBumpOnRemoval = tc.requireMajor
+			result := &ValidationResult{Valid: true}
+
+			v.validateVersionPolicy(tc.previous, tc.current, result)
+
+			assert.Equal(t, tc.expectErrors, result.Errors)
+			assert.Equal(t, tc.expectWarnings, result.Warnings)
+		})
+	}
+}
+
+func TestValidateDeprecations(t *testing.T) {
+	v := NewPackageValidator()
+	result := &ValidationResult{Valid: true}
+
+	err := v.validateDeprecations(loadPackage(t, "testdata/deprecation"), result)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		"Component 'old-app' is deprecated but does not document a replacement",
+	}, result.Errors)
+	assert.Equal(t, []string{
+		"Component 'old-db' names replacement 'db-v2' which is not a component of this package",
+		"Component 'consumer' depends on deprecated component 'old-app'",
+		"Component 'legacy' imports deprecated package 'legacy-tools'",
+	}, result.Warnings)
+
+	assert.Equal(t, []string{"testdata/deprecation"},
+		FilterDeprecatedPackages([]string{"testdata/deprecation", "testdata/deprecation/legacy"}))
+}
+
+func TestValidateComponentGroups(t *testing.T) {
+	zarfYaml := &util.ZarfYaml{
+		Components: []util.ZarfComponent{
+			{Name: "postgres", Group: "database", Default: true},
+			{Name: "mysql", Group: "database"},
+			{Name: "nginx", Group: "ingress"},
+			{Name: "traefik", Group: "ingress"},
+			{Name: "istio", Group: "mesh", Required: true},
+			{Name: "aws", Group: "cloud", Default: true},
+			{Name: "azure", Group: "cloud", Default: true},
+			{Name: "core", Required: true},
+		},
+	}
+
+	result := &ValidationResult{}
+	require.NoError(t, NewPackageValidator().validateComponentGroups(&PackageContext{ZarfYaml: zarfYaml}, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "group-default", Severity: SeverityError, Message: "Group 'ingress' has no default component, exactly one component of a group must be default"},
+		{RuleID: "group-required", Severity: SeverityError, Message: "Component 'istio' is required but belongs to group 'mesh', whose components are a choice"},
+		{RuleID: "group-default", Severity: SeverityError, Message: "Group 'mesh' has no default component, exactly one component of a group must be default"},
+		{RuleID: "group-single-member", Severity: SeverityWarning, Message: "Group 'mesh' only contains component 'istio', there is nothing to choose from"},
+		{RuleID: "group-default", Severity: SeverityError, Message: "Group 'cloud' has 2 default components (aws, azure), exactly one component of a group must be default"},
+	}, result.Findings)
+}
+
+func TestValidateKustomizations(t *testing.T) {
+	packageDir := t.TempDir()
+	writePackage(t, packageDir, `  - name: app
+    manifests:
+      - name: app
+        namespace: app
+        kustomizations:
+          - overlays/prod
+          - overlays/broken
+          - github.com/example/app//config?ref=v1.0.0
+`)
+	for _, overlay := range []string{"prod", "broken"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(packageDir, "overlays", overlay), 0755))
+	}
+	validate := func() []Finding {
+		result := &ValidationResult{Valid: true}
+		require.NoError(t, NewPackageValidator().validateKustomizations(context.Background(), loadPackage(t, packageDir), result))
+		return result.Findings
+	}
+
+	// Local kustomizations are built and linted, remote ones are left to zarf
+	fakeCommands(t, map[string]string{"kustomize": `case "$2" in
+*/broken) echo "accumulating resources: missing.yaml" >&2; exit 1 ;;
+esac
+printf 'apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: app\ndat: {}\n'`})
+	assert.Equal(t, []Finding{
+		{RuleID: "kustomize-build", Severity: SeverityError,
+			Message: "Component 'app' kustomization overlays/prod: document 1 (ConfigMap/app): unknown field \"dat\""},
+		{RuleID: "kustomize-build", Severity: SeverityError,
+			Message: "Component 'app' kustomization overlays/broken failed to build: accumulating resources: missing.yaml"},
+	}, validate())
+
+	t.Setenv("PATH", t.TempDir())
+	assert.Equal(t, []Finding{
+		{RuleID: "kustomize-build", Severity: SeverityWarning,
+			Message: "Skipping kustomization build validation: neither kustomize nor kubectl found in PATH"},
+	}, validate())
+}
+
+func TestValidateLargeFiles(t *testing.T) {
+	repo := t.TempDir()
+	packageDir := filepath.Join(repo, "packages", "app")
+	require.NoError(t, os.MkdirAll(packageDir, 0755))
+	git := func(args ...string) {
+		cmd := osexec.Command("git", append([]string{"-c", "user.name=zt", "-c", "user.email=zt@example.com"}, args...)...)
+		cmd.Dir = repo
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+	write := func(name string, size int) {
+		require.NoError(t, os.WriteFile(filepath.Join(packageDir, name), make([]byte, size), 0644))
+	}
+
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: app\ncomponents:\n  - name: app\n    files:\n      - source: huge.bin\n        target: /huge.bin\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	write("small.bin", 100)
+	write("large.bin", 2048)
+	write("huge.bin", 8192)
+	pointer := "version https://git-lfs.github.com/spec/v1\noid sha256:4d7a214614ab2935c943f9e0ff69d22eadbb8f32b1258daaa5e2ca24d17e2393\nsize 8192\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "lfs.bin"), []byte(pointer), 0644))
+
+	v := NewPackageValidator()
+	v.LargeFileWarning = 1024
+	v.LargeFileLimit = 4096
+
+	// Outside of a Git repository only the files of components are checked
+	result := &ValidationResult{Valid: true}
+	require.NoError(t, v.validateLargeFiles(context.Background(), loadPackage(t, packageDir), result))
+	assert.Equal(t, []string{"File huge.bin (8.0KiB) exceeds the limit of 4.0KiB for files checked into Git; track it with Git LFS or use a remote file source with a shasum"}, result.Errors)
+	assert.Empty(t, result.Warnings)
+
+	git("init", "-q")
+	git("add", "-A")
+	git("commit", "-q", "-m", "initial")
+	// LFS files are checked out with their content, but only the pointer is in Git
+	write("lfs.bin", 8192)
+
+	result = &ValidationResult{Valid: true}
+	require.NoError(t, v.validateLargeFiles(context.Background(), loadPackage(t, packageDir), result))
+	assert.Equal(t, []string{"File huge.bin (8.0KiB) exceeds the limit of 4.0KiB for files checked into Git; track it with Git LFS or use a remote file source with a shasum"}, result.Errors)
+	assert.Equal(t, []string{"File large.bin (2.0KiB) is large for a file checked into Git; track it with Git LFS or use a remote file source with a shasum"}, result.Warnings)
+}
+
+func TestValidateMinZarfVersion(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: app\n  annotations:\n    minZarfVersion: v0.40.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	pkg := loadPackage(t, packageDir)
+
+	tests := []struct {
+		zarfVersion string
+		errors      []string
+	}{
+		{zarfVersion: "", errors: nil},
+		{zarfVersion: "v0.42.0", errors: nil},
+		{zarfVersion: "v0.40.0", errors: nil},
+		{zarfVersion: "v0.38.1", errors: []string{"Package requires zarf v0.40.0 or newer, but zarf v0.38.1 is installed"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.zarfVersion, func(t *testing.T) {
+			v := NewPackageValidator()
+			v.ZarfVersion = tt.zarfVersion
+			result := &ValidationResult{Valid: true}
+			require.NoError(t, v.validateMinZarfVersion(pkg, result))
+			assert.Equal(t, tt.errors, result.Errors)
+		})
+	}
+
+	pkg.ZarfYaml.Metadata.Annotations[MinZarfVersionAnnotation] = "latest"
+	result := &ValidationResult{Valid: true}
+	require.NoError(t, NewPackageValidator().validateMinZarfVersion(pkg, result))
+	assert.Equal(t, []string{"Annotation minZarfVersion 'latest' is not a valid version"}, result.Errors)
+}
+
+func TestValidateZarfVersions(t *testing.T) {
+	fakeCommands(t, map[string]string{
+		"zarf-old":    `echo "ERR unknown field in zarf.yaml"; exit 1`,
+		"zarf-new":    `echo "Package created"`,
+		"zarf-oldest": `exit 1`,
+	})
+	packageDir := t.TempDir()
+	zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: app\n  annotations:\n    minZarfVersion: v0.38.0\n"
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+
+	v := NewPackageValidator()
+	v.ZarfBinaries = map[string]string{"v0.42.0": "zarf-new", "v0.38.0": "zarf-old", "v0.36.0": "zarf-oldest"}
+	result := &ValidationResult{Valid: true}
+	require.NoError(t, v.validateZarfVersions(context.Background(), loadPackage(t, packageDir), result))
+
+	assert.Equal(t, []Finding{
+		{RuleID: "zarf-version-build", Severity: SeverityInfo, Message: "Skipped building with zarf v0.36.0, older than minZarfVersion v0.38.0"},
+		{RuleID: "zarf-version-build", Severity: SeverityError, Message: "Package does not build with zarf v0.38.0: ERR unknown field in zarf.yaml"},
+		{RuleID: "zarf-version-build", Severity: SeverityInfo, Message: "Package builds with zarf v0.42.0"},
+	}, result.Findings)
+	assert.False(t, result.Valid)
+}
+
+func TestValidatePackagesHooks(t *testing.T) {
+	var packageDirs []string
+	for _, name := range []string{"app", "db"} {
+		packageDir := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.MkdirAll(packageDir, 0755))
+		zarfYaml := "kind: ZarfPackageConfig\nmetadata:\n  name: " + name + "\ncomponents:\n  - name: " + name + "\n"
+		require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+		packageDirs = append(packageDirs, packageDir)
+	}
+
+	var events []string
+	v := NewPackageValidator()
+	v.PackageStarted = func(path string) {
+		events = append(events, "started "+filepath.Base(path))
+	}
+	v.PackageFinished = func(result *ValidationResult) {
+		events = append(events, "finished "+filepath.Base(result.PackagePath))
+	}
+	results, err := v.ValidatePackages(context.Background(), packageDirs)
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, []string{"started app", "finished app", "started db", "finished db"}, events)
+}
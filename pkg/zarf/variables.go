@@ -0,0 +1,93 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"gopkg.in/yaml.v2"
+)
+
+// VariableSetsDir is the directory of a package holding its variable sets
+const VariableSetsDir = "zt-values"
+
+// VariableSet is a named set of values for the variables of a package, read from
+// zt-values/<name>.yaml
+type VariableSet struct {
+	Name      string
+	Path      string
+	Variables map[string]string
+}
+
+// SetArgs returns the arguments passing the variables to 'zarf package deploy'
+func (s VariableSet) SetArgs() []string {
+	names := make([]string, 0, len(s.Variables))
+	for name := range s.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var args []string
+	for _, name := range names {
+		args = append(args, "--set", fmt.Sprintf("%s=%s", name, s.Variables[name]))
+	}
+	return args
+}
+
+// LoadVariableSets reads the variable sets of the package in packagePath, sorted by
+// name. If selected is not empty, only the sets with these names are returned. A set
+// is a YAML map of variable names to values.
+func LoadVariableSets(packagePath string, selected []string) ([]VariableSet, error) {
+	files, err := filepath.Glob(filepath.Join(packagePath, VariableSetsDir, "*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	var sets []VariableSet
+	for _, file := range files {
+		name := strings.TrimSuffix(filepath.Base(file), ".yaml")
+		if len(selected) > 0 && !util.StringSliceContains(selected, name) {
+			continue
+		}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read variable set %s: %w", file, err)
+		}
+		values := map[string]interface{}{}
+		if err := yaml.Unmarshal(content, &values); err != nil {
+			return nil, fmt.Errorf("failed to parse variable set %s: %w", file, err)
+		}
+
+		set := VariableSet{Name: name, Path: file, Variables: map[string]string{}}
+		for variable, value := range values {
+			switch value.(type) {
+			case nil:
+				value = ""
+			case map[interface{}]interface{}, []interface{}:
+				return nil, fmt.Errorf("variable %s in %s must be a scalar value", variable, file)
+			}
+			set.Variables[strings.ToUpper(variable)] = fmt.Sprintf("%v", value)
+		}
+		sets = append(sets, set)
+	}
+	return sets, nil
+}
@@ -0,0 +1,55 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadVariableSets(t *testing.T) {
+	packageDir := t.TempDir()
+	setsDir := filepath.Join(packageDir, VariableSetsDir)
+	require.NoError(t, os.Mkdir(setsDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(setsDir, "ha.yaml"), []byte("replicas: 3\nDOMAIN: example.com\nTLS: true\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(setsDir, "minimal.yaml"), []byte("REPLICAS: 1\nDOMAIN:\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(setsDir, "notes.txt"), []byte("not a variable set\n"), 0644))
+
+	sets, err := LoadVariableSets(packageDir, nil)
+	require.NoError(t, err)
+	require.Len(t, sets, 2)
+	assert.Equal(t, "ha", sets[0].Name)
+	assert.Equal(t, map[string]string{"REPLICAS": "3", "DOMAIN": "example.com", "TLS": "true"}, sets[0].Variables)
+	assert.Equal(t, []string{"--set", "DOMAIN=example.com", "--set", "REPLICAS=3", "--set", "TLS=true"}, sets[0].SetArgs())
+	assert.Equal(t, "minimal", sets[1].Name)
+	assert.Equal(t, map[string]string{"REPLICAS": "1", "DOMAIN": ""}, sets[1].Variables)
+
+	sets, err = LoadVariableSets(packageDir, []string{"minimal", "unknown"})
+	require.NoError(t, err)
+	require.Len(t, sets, 1)
+	assert.Equal(t, "minimal", sets[0].Name)
+
+	require.NoError(t, os.WriteFile(filepath.Join(setsDir, "invalid.yaml"), []byte("HOSTS: [a, b]\n"), 0644))
+	_, err = LoadVariableSets(packageDir, nil)
+	assert.ErrorContains(t, err, "variable HOSTS")
+
+	sets, err = LoadVariableSets(t.TempDir(), nil)
+	require.NoError(t, err)
+	assert.Empty(t, sets)
+}
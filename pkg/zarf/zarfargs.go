@@ -0,0 +1,85 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/mattn/go-shellwords"
+)
+
+// ZarfArgs are additional arguments for zarf commands. Each is a Go template rendered
+// with the package path as {{ .PackagePath }} and split into arguments like a shell
+// would, e.g. "--set DOMAIN=test.local --log-level debug".
+type ZarfArgs struct {
+	Global string // Added to every zarf command that builds, deploys or inspects packages
+	Lint   string // Added to zarf dev lint
+	Build  string // Added to zarf package create
+	Deploy string // Added to zarf package deploy
+}
+
+// Validate checks that all arguments can be rendered
+func (a ZarfArgs) Validate() error {
+	_, err := renderArgs("", a.Global, a.Lint, a.Build, a.Deploy)
+	return err
+}
+
+// lint returns the arguments for zarf dev lint of the package at packagePath
+func (a ZarfArgs) lint(packagePath string) ([]string, error) {
+	return renderArgs(packagePath, a.Global, a.Lint)
+}
+
+// build returns the arguments for zarf package create of the package at packagePath
+func (a ZarfArgs) build(packagePath string) ([]string, error) {
+	return renderArgs(packagePath, a.Global, a.Build)
+}
+
+// deploy returns the arguments for zarf package deploy of the package at packagePath
+func (a ZarfArgs) deploy(packagePath string) ([]string, error) {
+	return renderArgs(packagePath, a.Global, a.Deploy)
+}
+
+// global returns the arguments for other zarf commands of the package at packagePath,
+// which is empty for commands such as zarf init that do not belong to a package
+func (a ZarfArgs) global(packagePath string) ([]string, error) {
+	return renderArgs(packagePath, a.Global)
+}
+
+// renderArgs renders and splits each of the argument templates
+func renderArgs(packagePath string, templates ...string) ([]string, error) {
+	data := struct{ PackagePath string }{PackagePath: packagePath}
+	var args []string
+	for _, argsTemplate := range templates {
+		if argsTemplate == "" {
+			continue
+		}
+		tmpl, err := template.New("args").Option("missingkey=error").Parse(argsTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid zarf arguments %q: %w", argsTemplate, err)
+		}
+		var rendered strings.Builder
+		if err := tmpl.Execute(&rendered, data); err != nil {
+			return nil, fmt.Errorf("invalid zarf arguments %q: %w", argsTemplate, err)
+		}
+		words, err := shellwords.Parse(rendered.String())
+		if err != nil {
+			return nil, fmt.Errorf("invalid zarf arguments %q: %w", argsTemplate, err)
+		}
+		args = append(args, words...)
+	}
+	return args, nil
+}
@@ -0,0 +1,74 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZarfArgs(t *testing.T) {
+	args := ZarfArgs{
+		Global: "--log-level debug",
+		Build:  `--set "MESSAGE=hello world" --zarf-cache {{ .PackagePath }}/.cache`,
+		Deploy: "--set DOMAIN=test.local",
+	}
+	require.NoError(t, args.Validate())
+
+	build, err := args.build("packages/podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--log-level", "debug", "--set", "MESSAGE=hello world", "--zarf-cache", "packages/podinfo/.cache"}, build)
+
+	deploy, err := args.deploy("packages/podinfo")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"--log-level", "debug", "--set", "DOMAIN=test.local"}, deploy)
+
+	lint, err := ZarfArgs{}.lint("packages/podinfo")
+	require.NoError(t, err)
+	assert.Empty(t, lint)
+
+	assert.EqualError(t, ZarfArgs{Lint: "{{ .Path }}"}.Validate(),
+		`invalid zarf arguments "{{ .Path }}": template: args:1:3: executing "args" at <.Path>: can't evaluate field Path in type struct { PackagePath string }`)
+	assert.EqualError(t, ZarfArgs{Deploy: `--set "A=b`}.Validate(), `invalid zarf arguments "--set \"A=b": invalid command line string`)
+}
+
+func TestBuildPackageExtraArgs(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $*" >> "$ZT_TEST_CALLS"; touch "$6/zarf-package-podinfo-amd64.tar.zst"`,
+	})
+	packageDir := t.TempDir()
+
+	d := NewPackageDeployer()
+	d.ZarfArgs = ZarfArgs{Global: "--log-level debug", Build: "--set SOURCE={{ .PackagePath }}"}
+	tarball, cleanup, err := d.buildPackage(context.Background(), "podinfo", packageDir, "v0.42.0", nil)
+	require.NoError(t, err)
+	outputDir := filepath.Dir(tarball)
+	assert.Equal(t, "zarf-package-podinfo-amd64.tar.zst", filepath.Base(tarball))
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	assert.Equal(t, "zarf package create . --confirm --output "+outputDir+" --log-level debug --set SOURCE="+packageDir+"\n", string(content))
+
+	// The package is built outside of the package directory, which is removed
+	cleanup()
+	assert.NoDirExists(t, outputDir)
+}
@@ -0,0 +1,190 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/Masterminds/semver"
+	"github.com/cpepper96/zarf-testing/pkg/exec"
+)
+
+// MinZarfVersionAnnotation is the zarf.yaml metadata annotation holding the oldest zarf
+// version a package supports
+const MinZarfVersionAnnotation = "minZarfVersion"
+
+// zarfReleaseURL is where zarf releases are downloaded from and zarfLatestURL redirects
+// to the latest release, overridden in tests
+var (
+	zarfReleaseURL = "https://github.com/zarf-dev/zarf/releases/download"
+	zarfLatestURL  = "https://github.com/zarf-dev/zarf/releases/latest"
+)
+
+// ParseZarfVersion returns the version printed by 'zarf version', e.g. v0.42.0
+func ParseZarfVersion(output string) (string, error) {
+	for _, field := range strings.Fields(output) {
+		if _, err := semver.NewVersion(field); err == nil {
+			return field, nil
+		}
+	}
+	return "", fmt.Errorf("no version found in %q", strings.TrimSpace(output))
+}
+
+// ZarfVersion returns the version of the zarf binary
+func ZarfVersion(ctx context.Context, binary string) (string, error) {
+	executor := exec.NewProcessExecutor(false)
+	output, err := executor.RunProcessAndCaptureStdout(ctx, binary, "version")
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the zarf version: %w", err)
+	}
+	return ParseZarfVersion(output)
+}
+
+// zarfAsset returns the name of the release asset of version for the host OS and
+// architecture
+func zarfAsset(version string) string {
+	goos := strings.ToUpper(runtime.GOOS[:1]) + runtime.GOOS[1:]
+	asset := fmt.Sprintf("zarf_%s_%s_%s", version, goos, runtime.GOARCH)
+	if runtime.GOOS == "windows" {
+		asset += ".exe"
+	}
+	return asset
+}
+
+// DownloadZarf downloads the zarf release version for the host OS and architecture
+// into dir, unless it was downloaded before, and returns the path of the binary, which
+// is named zarf. The download is verified against the checksums published with the
+// release.
+func DownloadZarf(ctx context.Context, version string, dir string) (string, error) {
+	if !strings.HasPrefix(version, "v") {
+		version = "v" + version
+	}
+	if _, err := semver.NewVersion(version); err != nil {
+		return "", fmt.Errorf("invalid zarf version %q: %w", version, err)
+	}
+
+	asset := zarfAsset(version)
+	binary := filepath.Join(dir, "zarf", version, "zarf")
+	if runtime.GOOS == "windows" {
+		binary += ".exe"
+	}
+	if _, err := os.Stat(binary); err == nil {
+		return binary, nil
+	}
+
+	checksums, err := download(ctx, fmt.Sprintf("%s/%s/checksums.txt", zarfReleaseURL, version))
+	if err != nil {
+		return "", fmt.Errorf("failed to download checksums of zarf %s: %w", version, err)
+	}
+	checksum := ""
+	scanner := bufio.NewScanner(strings.NewReader(string(checksums)))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 2 && fields[1] == asset {
+			checksum = fields[0]
+		}
+	}
+	if checksum == "" {
+		return "", fmt.Errorf("zarf %s has no release for %s/%s", version, runtime.GOOS, runtime.GOARCH)
+	}
+
+	content, err := download(ctx, fmt.Sprintf("%s/%s/%s", zarfReleaseURL, version, asset))
+	if err != nil {
+		return "", fmt.Errorf("failed to download zarf %s: %w", version, err)
+	}
+	sum := sha256.Sum256(content)
+	if hex.EncodeToString(sum[:]) != checksum {
+		return "", fmt.Errorf("checksum of downloaded zarf %s does not match", version)
+	}
+
+	// Write to a temporary file first so that an interrupted download is not used
+	if err := os.MkdirAll(filepath.Dir(binary), 0755); err != nil {
+		return "", err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(binary), asset+".*")
+	if err != nil {
+		return "", err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return "", err
+	}
+	if err := tmp.Close(); err != nil {
+		return "", err
+	}
+	if err := os.Chmod(tmp.Name(), 0755); err != nil {
+		return "", err
+	}
+	if err := os.Rename(tmp.Name(), binary); err != nil {
+		return "", err
+	}
+	return binary, nil
+}
+
+// LatestZarfVersion returns the version of the latest zarf release
+func LatestZarfVersion(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, zarfLatestURL, nil)
+	if err != nil {
+		return "", err
+	}
+	// The latest release redirects to the tag of the release
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine the latest zarf release: %w", err)
+	}
+	resp.Body.Close()
+	location := resp.Header.Get("Location")
+	version := location[strings.LastIndex(location, "/")+1:]
+	if _, err := semver.NewVersion(version); err != nil {
+		return "", fmt.Errorf("failed to determine the latest zarf release from %q", location)
+	}
+	return version, nil
+}
+
+// UseZarf makes binary the zarf CLI run by zt by putting its directory first in PATH
+func UseZarf(binary string) error {
+	return os.Setenv("PATH", filepath.Dir(binary)+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+// download returns the content at url
+func download(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s returned %s", url, resp.Status)
+	}
+	return io.ReadAll(resp.Body)
+}
@@ -0,0 +1,98 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseZarfVersion(t *testing.T) {
+	version, err := ParseZarfVersion("v0.42.0\n")
+	require.NoError(t, err)
+	assert.Equal(t, "v0.42.0", version)
+
+	_, err = ParseZarfVersion("command not found")
+	assert.EqualError(t, err, `no version found in "command not found"`)
+}
+
+func TestDownloadZarf(t *testing.T) {
+	binary := []byte("#!/bin/sh\necho v0.42.0\n")
+	sum := sha256.Sum256(binary)
+	asset := zarfAsset("v0.42.0")
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		switch r.URL.Path {
+		case "/v0.42.0/checksums.txt", "/v0.41.0/checksums.txt":
+			w.Write([]byte(hex.EncodeToString(sum[:]) + "  " + asset + "\n"))
+		case "/v0.42.0/" + asset:
+			w.Write(binary)
+		case "/v0.41.0/" + zarfAsset("v0.41.0"):
+			w.Write([]byte("tampered"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	zarfReleaseURL = server.URL
+	defer func() { zarfReleaseURL = "https://github.com/zarf-dev/zarf/releases/download" }()
+
+	dir := t.TempDir()
+	path, err := DownloadZarf(context.Background(), "0.42.0", dir)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "zarf", "v0.42.0", "zarf"), path)
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, binary, content)
+	assert.Equal(t, 2, requests)
+
+	// Downloaded versions are reused
+	_, err = DownloadZarf(context.Background(), "v0.42.0", dir)
+	require.NoError(t, err)
+	assert.Equal(t, 2, requests)
+
+	// The checksums of v0.41.0 list the asset of v0.42.0 only
+	_, err = DownloadZarf(context.Background(), "v0.41.0", dir)
+	assert.ErrorContains(t, err, "zarf v0.41.0 has no release for")
+
+	_, err = DownloadZarf(context.Background(), "v0.40.0", dir)
+	assert.ErrorContains(t, err, "failed to download checksums of zarf v0.40.0")
+
+	_, err = DownloadZarf(context.Background(), "latest", dir)
+	assert.ErrorContains(t, err, `invalid zarf version "vlatest"`)
+}
+
+func TestLatestZarfVersion(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/zarf-dev/zarf/releases/tag/v0.42.0", http.StatusFound)
+	}))
+	defer server.Close()
+	zarfLatestURL = server.URL
+	defer func() { zarfLatestURL = "https://github.com/zarf-dev/zarf/releases/latest" }()
+
+	version, err := LatestZarfVersion(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "v0.42.0", version)
+}
@@ -0,0 +1,135 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// zarfConfigFiles are the names of the zarf config files of a package, in the order
+// zarf looks for them
+var zarfConfigFiles = []string{"zarf-config.toml", "zarf-config.yaml", "zarf-config.yml", "zarf-config.json"}
+
+// FindZarfConfig returns the path of the zarf config file of the package in
+// packagePath, or an empty string if it has none
+func FindZarfConfig(packagePath string) string {
+	for _, name := range zarfConfigFiles {
+		path := filepath.Join(packagePath, name)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+	}
+	return ""
+}
+
+// ZarfConfig holds the values a zarf config file sets for a package. zarf reads the
+// file from ZARF_CONFIG, which zt sets when it creates, deploys and removes the package.
+type ZarfConfig struct {
+	Path string
+	// CreateSet are the package templates set for 'zarf package create'
+	// (package.create.set), DeploySet the variables set for 'zarf package deploy'
+	// (package.deploy.set). Names are upper case, as zarf treats them.
+	CreateSet map[string]string
+	DeploySet map[string]string
+}
+
+// LoadZarfConfig reads the zarf config file of the package in packagePath, or returns
+// nil if it has none
+func LoadZarfConfig(packagePath string) (*ZarfConfig, error) {
+	path := FindZarfConfig(packagePath)
+	if path == "" {
+		return nil, nil
+	}
+	v := viper.New()
+	v.SetConfigFile(path)
+	if err := v.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return &ZarfConfig{
+		Path:      path,
+		CreateSet: upperKeys(v.GetStringMapString("package.create.set")),
+		DeploySet: upperKeys(v.GetStringMapString("package.deploy.set")),
+	}, nil
+}
+
+func upperKeys(values map[string]string) map[string]string {
+	upper := make(map[string]string, len(values))
+	for key, value := range values {
+		upper[strings.ToUpper(key)] = value
+	}
+	return upper
+}
+
+// zarfConfigEnv returns the environment passing the zarf config file of the package in
+// packagePath to zarf
+func zarfConfigEnv(packagePath string) []string {
+	path := FindZarfConfig(packagePath)
+	if path == "" {
+		return nil
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return []string{"ZARF_CONFIG=" + path}
+}
+
+// validateZarfConfig checks that the variables a zarf config file sets for deployment
+// are declared by the package and that the package templates it sets are used
+func (v *PackageValidator) validateZarfConfig(ctx context.Context, pkg *PackageContext, result *ValidationResult) error {
+	config, err := LoadZarfConfig(pkg.Path)
+	if err != nil {
+		result.AddFinding(Finding{RuleID: "zarf-config", Severity: SeverityError, File: filepath.Base(FindZarfConfig(pkg.Path)),
+			Message: fmt.Sprintf("Failed to read the zarf config file: %v", err)})
+		return nil
+	}
+	if config == nil {
+		return nil
+	}
+	file := filepath.Base(config.Path)
+
+	declared := map[string]bool{}
+	for _, variable := range pkg.ZarfYaml.Variables {
+		declared[strings.ToUpper(variable.Name)] = true
+	}
+	for _, name := range sortedNames(config.DeploySet) {
+		if !declared[name] {
+			result.AddFinding(Finding{RuleID: "zarf-config", Severity: SeverityError, File: file,
+				Message: fmt.Sprintf("%s sets variable '%s' which is not declared by the package", file, name)})
+		}
+	}
+	for _, name := range sortedNames(config.CreateSet) {
+		if !strings.Contains(string(pkg.Content), "###ZARF_PKG_TMPL_"+name+"###") {
+			result.AddFinding(Finding{RuleID: "zarf-config", Severity: SeverityWarning, File: file,
+				Message: fmt.Sprintf("%s sets package template '%s' which is not used by zarf.yaml", file, name)})
+		}
+	}
+	return nil
+}
+
+func sortedNames(values map[string]string) []string {
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
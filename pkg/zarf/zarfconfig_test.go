@@ -0,0 +1,127 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadZarfConfig(t *testing.T) {
+	packageDir := t.TempDir()
+	config, err := LoadZarfConfig(packageDir)
+	require.NoError(t, err)
+	assert.Nil(t, config)
+
+	toml := `log_level = 'info'
+
+[package.create.set]
+podinfo_version = '6.4.0'
+
+[package.deploy.set]
+replicas = '2'
+DOMAIN = 'example.com'
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf-config.toml"), []byte(toml), 0644))
+	config, err = LoadZarfConfig(packageDir)
+	require.NoError(t, err)
+	assert.Equal(t, &ZarfConfig{
+		Path:      filepath.Join(packageDir, "zarf-config.toml"),
+		CreateSet: map[string]string{"PODINFO_VERSION": "6.4.0"},
+		DeploySet: map[string]string{"REPLICAS": "2", "DOMAIN": "example.com"},
+	}, config)
+
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf-config.toml"), []byte("[package\n"), 0644))
+	_, err = LoadZarfConfig(packageDir)
+	assert.Error(t, err)
+}
+
+func TestValidateZarfConfig(t *testing.T) {
+	packageDir := t.TempDir()
+	zarfYaml := `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+variables:
+  - name: REPLICAS
+components:
+  - name: podinfo
+    images:
+      - ghcr.io/stefanprodan/podinfo:###ZARF_PKG_TMPL_PODINFO_VERSION###
+`
+	config := `package:
+  create:
+    set:
+      podinfo_version: 6.4.0
+      registry: ghcr.io
+  deploy:
+    set:
+      replicas: 2
+      domain: example.com
+`
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf-config.yaml"), []byte(config), 0644))
+	pkg, err := LoadPackageContext(packageDir)
+	require.NoError(t, err)
+
+	v := NewPackageValidator()
+	result := &ValidationResult{PackagePath: packageDir, Valid: true}
+	require.NoError(t, v.validateZarfConfig(context.Background(), pkg, result))
+	assert.Equal(t, []Finding{
+		{RuleID: "zarf-config", Severity: SeverityError, File: "zarf-config.yaml",
+			Message: "zarf-config.yaml sets variable 'DOMAIN' which is not declared by the package"},
+		{RuleID: "zarf-config", Severity: SeverityWarning, File: "zarf-config.yaml",
+			Message: "zarf-config.yaml sets package template 'REGISTRY' which is not used by zarf.yaml"},
+	}, result.Findings)
+}
+
+func TestDeployPackageZarfConfig(t *testing.T) {
+	calls := filepath.Join(t.TempDir(), "calls")
+	t.Setenv("ZT_TEST_CALLS", calls)
+	fakeCommands(t, map[string]string{
+		"zarf": `echo "zarf $1 $2 ZARF_CONFIG=$ZARF_CONFIG" >> "$ZT_TEST_CALLS"
+[ "$1 $2" = "package create" ] && touch "$6/zarf-package-podinfo-amd64.tar.zst"
+exit 0`,
+		"kubectl": "exit 0",
+	})
+
+	packageDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf.yaml"), []byte("kind: ZarfPackageConfig\nmetadata:\n  name: podinfo\n"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(packageDir, "zarf-config.yaml"), []byte("package: {}\n"), 0644))
+
+	d := NewPackageDeployer()
+	_, err := d.DeployPackage(context.Background(), packageDir)
+	require.NoError(t, err)
+
+	content, err := os.ReadFile(calls)
+	require.NoError(t, err)
+	config := filepath.Join(packageDir, "zarf-config.yaml")
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(string(content)), "\n") {
+		if strings.HasPrefix(line, "zarf package ") {
+			lines = append(lines, line)
+		}
+	}
+	assert.Equal(t, []string{
+		"zarf package create ZARF_CONFIG=" + config,
+		"zarf package deploy ZARF_CONFIG=" + config,
+		"zarf package remove ZARF_CONFIG=" + config,
+	}, lines)
+}
@@ -0,0 +1,87 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/cpepper96/zarf-testing/pkg/tracing"
+)
+
+// IsZarfInitialized reports whether 'zarf init' has been run against the cluster, which
+// is recorded in the zarf-state secret
+func (d *PackageDeployer) IsZarfInitialized(ctx context.Context) (bool, error) {
+	output, err := d.run(ctx, "zarf-init", "", "kubectl", "get", "secret", "zarf-state",
+		"--namespace", "zarf", "--ignore-not-found", "--output", "name")
+	if err != nil {
+		return false, fmt.Errorf("failed to check for the zarf-state secret: %w", err)
+	}
+	return output != "", nil
+}
+
+// EnsureZarfInitialized runs 'zarf init' with the given optional components and extra
+// arguments unless the cluster has been initialized already. It returns whether zarf
+// init was run. The cluster is initialized with the init package built from
+// InitPackage if set, the default init package of zarf otherwise.
+func (d *PackageDeployer) EnsureZarfInitialized(ctx context.Context, components []string, extraArgs []string) (bool, error) {
+	initialized, err := d.IsZarfInitialized(ctx)
+	if err != nil || initialized {
+		return false, err
+	}
+
+	initCtx, cancel := context.WithTimeout(ctx, d.Timeout)
+	defer cancel()
+
+	dir := ""
+	if d.InitPackage != "" {
+		var cleanup func()
+		dir, cleanup, err = d.initPackageDir(initCtx, d.InitPackage)
+		if err != nil {
+			return false, fmt.Errorf("failed to build init package %s: %w", d.InitPackage, err)
+		}
+		defer cleanup()
+	}
+
+	args := []interface{}{"init", "--confirm"}
+	if len(components) > 0 {
+		args = append(args, "--components", strings.Join(components, ","))
+	}
+	globalArgs, err := d.ZarfArgs.global("")
+	if err != nil {
+		return false, err
+	}
+	args = append(args, extraArgs, globalArgs)
+	initCtx, span := tracing.Start(initCtx, "cluster.zarf-init")
+	_, err = d.run(initCtx, "zarf-init", dir, "zarf", args...)
+	span.End(err)
+	if err != nil {
+		if initCtx.Err() != nil {
+			return true, fmt.Errorf("zarf init timed out after %s", d.Timeout)
+		}
+		return true, fmt.Errorf("zarf init failed: %w", err)
+	}
+	return true, nil
+}
+
+// EnsureZarfInitialized initializes the cluster as configured, unless zarf init is
+// disabled or the cluster has been initialized already
+func (d *Deployer) EnsureZarfInitialized(ctx context.Context) (bool, error) {
+	if d.config.SkipZarfInit {
+		return false, nil
+	}
+	return d.deployer.EnsureZarfInitialized(ctx, d.config.ZarfInitComponents, strings.Fields(d.config.ZarfInitArgs))
+}
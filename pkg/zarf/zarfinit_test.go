@@ -0,0 +1,65 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarf
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnsureZarfInitialized(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       string
+		initialized bool
+		calls       string
+	}{
+		{
+			name:        "fresh cluster",
+			state:       "",
+			initialized: true,
+			calls:       "zarf init --confirm --components git-server --storage-class local-path\n",
+		},
+		{
+			name:        "initialized cluster",
+			state:       "secret/zarf-state",
+			initialized: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := filepath.Join(t.TempDir(), "calls")
+			t.Setenv("ZT_TEST_CALLS", calls)
+			fakeCommands(t, map[string]string{
+				"zarf":    `echo "zarf $*" >> "$ZT_TEST_CALLS"`,
+				"kubectl": "echo " + tt.state,
+			})
+
+			d := NewPackageDeployer()
+			initialized, err := d.EnsureZarfInitialized(context.Background(), []string{"git-server"}, []string{"--storage-class", "local-path"})
+			require.NoError(t, err)
+			assert.Equal(t, tt.initialized, initialized)
+
+			content, _ := os.ReadFile(calls)
+			assert.Equal(t, tt.calls, string(content))
+		})
+	}
+}
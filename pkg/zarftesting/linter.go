@@ -0,0 +1,394 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package zarftesting is the Go API of zt for tools embedding its package validation
+// without running the zt binary. It is configured with functional options and does
+// not depend on command line flags or configuration files:
+//
+//	linter, err := zarftesting.NewLinter(
+//		zarftesting.WithKubeVersion("1.29"),
+//		zarftesting.WithFailOn(zarf.FailOnWarning),
+//	)
+//	if err != nil {
+//		return err
+//	}
+//	result, err := linter.Run(ctx, []string{"packages/podinfo"})
+//	if err != nil {
+//		return err
+//	}
+//	if !result.Passed() {
+//		return result.Failure
+//	}
+package zarftesting
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/cpepper96/zarf-testing/pkg/yamllint"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+)
+
+// Linter validates Zarf packages with the rules of 'zt lint'. A Linter is safe for
+// concurrent use once created.
+type Linter struct {
+	validator   zarf.PackageValidator
+	baseline    *zarf.Baseline
+	failOn      string
+	maxWarnings int
+}
+
+// Option configures a Linter
+type Option func(*Linter) error
+
+// Result is the outcome of linting a set of packages
+type Result struct {
+	Report *zarf.LintReport
+
+	// Failure is set when the findings exceed the configured threshold, see
+	// WithFailOn and WithMaxWarnings
+	Failure error
+}
+
+// Passed reports whether the findings are within the configured threshold
+func (r *Result) Passed() bool {
+	return r.Failure == nil
+}
+
+// NewLinter creates a Linter. Without options, packages are validated with
+// 'zarf dev lint' (or zt's own metadata checks if the zarf CLI is missing), the
+// built-in rules and yamllint's relaxed rules as warnings, and fail on errors. The
+// version increment check is disabled unless WithVersionIncrement or WithSince is
+// given, since it requires a Git repository.
+func NewLinter(opts ...Option) (*Linter, error) {
+	l := &Linter{
+		validator:   *zarf.NewPackageValidator(),
+		failOn:      zarf.FailOnError,
+		maxWarnings: -1,
+	}
+	l.validator.CheckVersionIncrement = false
+	l.validator.YamlLintConfig = yamllint.DefaultConfig()
+	for _, opt := range opts {
+		if err := opt(l); err != nil {
+			return nil, err
+		}
+	}
+	if err := l.validator.ZarfArgs.Validate(); err != nil {
+		return nil, err
+	}
+	return l, nil
+}
+
+// Run validates the packages in the given directories; findings are reported in the
+// result. A package that cannot be validated is reported with an internal-error
+// finding and fails the result, or is returned as an error with WithStopOnError.
+func (l *Linter) Run(ctx context.Context, paths []string) (*Result, error) {
+	// The validator records state such as the detected zarf version while running,
+	// so each run uses its own copy
+	validator := l.validator
+	results, err := validator.ValidatePackages(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	if l.baseline != nil {
+		l.baseline.Apply(results)
+	}
+	report := zarf.NewLintReport(results)
+	return &Result{Report: report, Failure: report.Failure(l.failOn, l.maxWarnings)}, nil
+}
+
+// WithValidator replaces the validator configured by the options before it with a
+// copy of validator, for callers that configure the rules themselves
+func WithValidator(validator *zarf.PackageValidator) Option {
+	return func(l *Linter) error {
+		l.validator = *validator
+		return nil
+	}
+}
+
+// WithKubeVersion checks manifests against the APIs of the given Kubernetes version,
+// e.g. '1.29'
+func WithKubeVersion(version string) Option {
+	return func(l *Linter) error {
+		l.validator.KubeVersion = version
+		return nil
+	}
+}
+
+// WithVersionIncrement requires a package version bump when a package changed
+// compared to the merge base of remote/targetBranch and HEAD
+func WithVersionIncrement(remote, targetBranch string) Option {
+	return func(l *Linter) error {
+		l.validator.CheckVersionIncrement = true
+		l.validator.Remote = remote
+		l.validator.TargetBranch = targetBranch
+		l.validator.Since = "HEAD"
+		return nil
+	}
+}
+
+// WithSince requires a package version bump when a package changed compared to
+// the given Git reference
+func WithSince(ref string) Option {
+	return func(l *Linter) error {
+		l.validator.CheckVersionIncrement = true
+		l.validator.Since = ref
+		return nil
+	}
+}
+
+// WithRequireMajorBumpOnRemoval makes removing components without a breaking
+// version bump an error
+func WithRequireMajorBumpOnRemoval() Option {
+	return func(l *Linter) error {
+		l.validator.RequireMajorBumpOnRemoval = true
+		return nil
+	}
+}
+
+// WithYamlLintConfig lints the package files with the given rules. A nil config
+// disables YAML linting.
+func WithYamlLintConfig(config *yamllint.Config) Option {
+	return func(l *Linter) error {
+		l.validator.YamlLintConfig = config
+		return nil
+	}
+}
+
+// WithLargeFileLimits sets the sizes in bytes above which files checked into Git
+// are warned about and are errors. Zero disables the respective check.
+func WithLargeFileLimits(warning, limit int64) Option {
+	return func(l *Linter) error {
+		if warning < 0 || limit < 0 {
+			return fmt.Errorf("invalid large file limits %d and %d: must not be negative", warning, limit)
+		}
+		l.validator.LargeFileWarning = warning
+		l.validator.LargeFileLimit = limit
+		return nil
+	}
+}
+
+// WithZarfArgs adds arguments to the zarf commands run while linting
+func WithZarfArgs(args zarf.ZarfArgs) Option {
+	return func(l *Linter) error {
+		l.validator.ZarfArgs = args
+		return nil
+	}
+}
+
+// WithoutZarfCLI skips 'zarf dev lint' and checks the package metadata instead, as
+// zt does when the zarf CLI is missing. The built-in rules run either way
+func WithoutZarfCLI() Option {
+	return func(l *Linter) error {
+		l.validator.UseSDK = false
+		return nil
+	}
+}
+
+// WithConfinedPaths rejects packages referring to paths outside of their directory
+// without reading those paths, for linting packages submitted by untrusted users
+func WithConfinedPaths() Option {
+	return func(l *Linter) error {
+		l.validator.ConfinePaths = true
+		return nil
+	}
+}
+
+// WithDefinitionOnly validates package definitions without the files they refer to,
+// skipping the rules that read the local files of a package, e.g. for the spec of a
+// ZarfPackage resource
+func WithDefinitionOnly() Option {
+	return func(l *Linter) error {
+		l.validator.DefinitionOnly = true
+		return nil
+	}
+}
+
+// WithPSSLevel checks the workloads of every package against the given Pod Security
+// Standards level, see zarf.PSSBaseline and zarf.PSSRestricted
+func WithPSSLevel(level string) Option {
+	return func(l *Linter) error {
+		if err := zarf.ValidatePSSLevel(level); err != nil {
+			return err
+		}
+		l.validator.PSSLevel = level
+		return nil
+	}
+}
+
+// WithImagePolicy requires every image to come from an approved registry or to carry
+// a label, see zarf.ImagePolicy
+func WithImagePolicy(policy zarf.ImagePolicy) Option {
+	return func(l *Linter) error {
+		l.validator.ImagePolicy = &policy
+		return nil
+	}
+}
+
+// WithoutRBACValidation disables reporting cluster-admin bindings and wildcard RBAC
+// rules
+func WithoutRBACValidation() Option {
+	return func(l *Linter) error {
+		l.validator.ValidateRBAC = false
+		return nil
+	}
+}
+
+// WithoutNetworkPolicyValidation disables reporting namespaces with workloads but
+// without a NetworkPolicy
+func WithoutNetworkPolicyValidation() Option {
+	return func(l *Linter) error {
+		l.validator.ValidateNetworkPolicies = false
+		return nil
+	}
+}
+
+// WithSecretsAllowlist skips the secret findings whose secret or file path matches
+// one of the regular expressions
+func WithSecretsAllowlist(patterns ...string) Option {
+	return func(l *Linter) error {
+		allowlist, err := zarf.CompileSecretsAllowlist(patterns)
+		if err != nil {
+			return err
+		}
+		l.validator.SecretsAllowlist = append(l.validator.SecretsAllowlist, allowlist...)
+		return nil
+	}
+}
+
+// WithoutSecretScanning disables scanning the package files for credentials
+func WithoutSecretScanning() Option {
+	return func(l *Linter) error {
+		l.validator.ScanSecrets = false
+		return nil
+	}
+}
+
+// WithOCIImports checks that the skeleton packages imported by URL define the imported
+// components, reading them with zarf
+func WithOCIImports() Option {
+	return func(l *Linter) error {
+		l.validator.ResolveOCIImports = true
+		return nil
+	}
+}
+
+// WithDocsValidation enables the docs rules requiring a README.md and descriptions of
+// the components and variables of packages
+func WithDocsValidation() Option {
+	return func(l *Linter) error {
+		l.validator.ValidateDocs = true
+		return nil
+	}
+}
+
+// WithRequiredMaintainers requires packages to declare their maintainers and to have
+// owners in the CODEOWNERS file of the repository
+func WithRequiredMaintainers() Option {
+	return func(l *Linter) error {
+		l.validator.RequireMaintainers = true
+		return nil
+	}
+}
+
+// WithNamingPolicy overrides the naming convention of an entity, see
+// zarf.NewNamingPolicy
+func WithNamingPolicy(entity, pattern, severity string) Option {
+	return func(l *Linter) error {
+		policy, err := zarf.NewNamingPolicy(entity, pattern, severity)
+		if err != nil {
+			return err
+		}
+		if l.validator.NamingPolicies == nil {
+			l.validator.NamingPolicies = zarf.DefaultNamingPolicies()
+		}
+		l.validator.NamingPolicies[entity] = policy
+		return nil
+	}
+}
+
+// WithDuplicateVersions warns about images and charts of the linted packages that
+// other packages of packageDirs use at a different version, see zarf.FindDuplicates
+func WithDuplicateVersions(packageDirs ...string) Option {
+	return func(l *Linter) error {
+		duplicates, err := zarf.FindDuplicates(packageDirs)
+		if err != nil {
+			return err
+		}
+		l.validator.Duplicates = duplicates
+		return nil
+	}
+}
+
+// WithNamespaceCollisions warns about namespaces of the linted packages that other
+// packages of packageDirs deploy to as well, see zarf.FindNamespaceUsers
+func WithNamespaceCollisions(packageDirs ...string) Option {
+	return func(l *Linter) error {
+		users, err := zarf.FindNamespaceUsers(packageDirs)
+		if err != nil {
+			return err
+		}
+		l.validator.NamespaceUsers = users
+		return nil
+	}
+}
+
+// WithPlugins runs the given validation plugins against every package, see
+// zarf.DiscoverPlugins
+func WithPlugins(plugins ...zarf.Plugin) Option {
+	return func(l *Linter) error {
+		l.validator.Plugins = append(l.validator.Plugins, plugins...)
+		return nil
+	}
+}
+
+// WithStopOnError makes Run return the error of the first package that cannot be
+// validated instead of reporting it and continuing with the remaining packages
+func WithStopOnError() Option {
+	return func(l *Linter) error {
+		l.validator.KeepGoing = false
+		return nil
+	}
+}
+
+// WithBaseline suppresses the findings recorded in the baseline
+func WithBaseline(baseline *zarf.Baseline) Option {
+	return func(l *Linter) error {
+		l.baseline = baseline
+		return nil
+	}
+}
+
+// WithFailOn sets the minimum finding severity that fails a run: zarf.FailOnError
+// (the default), zarf.FailOnWarning or zarf.FailOnNever
+func WithFailOn(failOn string) Option {
+	return func(l *Linter) error {
+		switch failOn {
+		case zarf.FailOnError, zarf.FailOnWarning, zarf.FailOnNever:
+			l.failOn = failOn
+			return nil
+		default:
+			return fmt.Errorf("invalid fail-on severity %q: must be one of %s, %s or %s", failOn, zarf.FailOnError, zarf.FailOnWarning, zarf.FailOnNever)
+		}
+	}
+}
+
+// WithMaxWarnings fails a run when the total number of warnings exceeds max. A
+// negative value (the default) disables the limit.
+func WithMaxWarnings(max int) Option {
+	return func(l *Linter) error {
+		l.maxWarnings = max
+		return nil
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zarftesting
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writePackage(t *testing.T, zarfYaml string) string {
+	t.Helper()
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "zarf.yaml"), []byte(zarfYaml), 0644))
+	return dir
+}
+
+func TestLinterRun(t *testing.T) {
+	// The image is not pinned to a digest, which is a warning
+	packageDir := writePackage(t, `kind: ZarfPackageConfig
+metadata:
+  name: podinfo
+  version: 1.0.0
+components:
+  - name: podinfo
+    required: true
+    images:
+      - ghcr.io/stefanprodan/podinfo:6.4.0
+`)
+
+	linter, err := NewLinter(WithoutZarfCLI(), WithYamlLintConfig(nil))
+	require.NoError(t, err)
+	result, err := linter.Run(context.Background(), []string{packageDir})
+	require.NoError(t, err)
+	require.Len(t, result.Report.Packages, 1)
+	assert.True(t, result.Passed())
+	assert.Greater(t, result.Report.Summary.Warnings, 0)
+
+	linter, err = NewLinter(WithoutZarfCLI(), WithYamlLintConfig(nil), WithFailOn(zarf.FailOnWarning))
+	require.NoError(t, err)
+	result, err = linter.Run(context.Background(), []string{packageDir})
+	require.NoError(t, err)
+	assert.False(t, result.Passed())
+
+	// A baseline of the current findings suppresses them
+	results, err := (&linter.validator).ValidatePackages(context.Background(), []string{packageDir})
+	require.NoError(t, err)
+	linter, err = NewLinter(WithoutZarfCLI(), WithYamlLintConfig(nil), WithFailOn(zarf.FailOnWarning), WithBaseline(zarf.NewBaseline(results)))
+	require.NoError(t, err)
+	result, err = linter.Run(context.Background(), []string{packageDir})
+	require.NoError(t, err)
+	assert.True(t, result.Passed())
+	assert.Equal(t, 0, result.Report.Summary.Warnings)
+}
+
+func TestNewLinterOptions(t *testing.T) {
+	_, err := NewLinter(WithFailOn("sometimes"))
+	assert.EqualError(t, err, `invalid fail-on severity "sometimes": must be one of error, warning or never`)
+
+	_, err = NewLinter(WithLargeFileLimits(-1, 0))
+	assert.Error(t, err)
+
+	linter, err := NewLinter(WithSince("v1.0.0"), WithKubeVersion("1.29"), WithMaxWarnings(5))
+	require.NoError(t, err)
+	assert.True(t, linter.validator.CheckVersionIncrement)
+	assert.Equal(t, "v1.0.0", linter.validator.Since)
+	assert.Equal(t, "1.29", linter.validator.KubeVersion)
+	assert.Equal(t, 5, linter.maxWarnings)
+	assert.NotNil(t, linter.validator.YamlLintConfig)
+	assert.True(t, linter.validator.KeepGoing)
+
+	linter, err = NewLinter(WithStopOnError())
+	require.NoError(t, err)
+	assert.False(t, linter.validator.KeepGoing)
+}
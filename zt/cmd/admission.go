@@ -0,0 +1,78 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"log/slog"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/server"
+	"github.com/cpepper96/zarf-testing/pkg/zarftesting"
+	"github.com/spf13/cobra"
+)
+
+func newAdmissionWebhookCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admission-webhook",
+		Short: "Run a Kubernetes validating admission webhook for ZarfPackage resources",
+		Long: heredoc.Doc(`
+			Start a validating admission webhook that lints ZarfPackage custom
+			resources when they are created or updated, and denies packages that
+			fail the lint rules. Warnings are returned to the client.
+
+			The spec of a ZarfPackage is the package definition ('metadata',
+			'components', ...), named after the resource unless it sets
+			'metadata.name', or holds the raw 'zarf.yaml' in 'spec.zarfYaml'.
+
+			Reviews are served on POST /validate. Kubernetes requires webhooks to
+			be served over TLS, so pass '--tls-cert-file' and '--tls-private-key-file'
+			unless TLS is terminated in front of zt. Packages are linted with the
+			lint options, except that the version increment check is disabled.
+			Resources do not carry the files a package refers to, so the rules
+			reading local files are skipped, and references outside of the package
+			are errors.`),
+		RunE: admissionWebhook,
+	}
+
+	flags := cmd.Flags()
+	flags.Int("port", 8443, "Port to listen on")
+	flags.String("address", "", "Address to listen on, all interfaces if empty")
+	flags.String("tls-cert-file", "", "TLS certificate file of the webhook")
+	flags.String("tls-private-key-file", "", "TLS private key file matching '--tls-cert-file'")
+	addLintFlags(flags)
+	addCommonFlags(flags)
+	return cmd
+}
+
+func admissionWebhook(cmd *cobra.Command, _ []string) error {
+	port, _ := cmd.Flags().GetInt("port")
+	address, _ := cmd.Flags().GetString("address")
+	certFile, _ := cmd.Flags().GetString("tls-cert-file")
+	keyFile, _ := cmd.Flags().GetString("tls-private-key-file")
+	if (certFile == "") != (keyFile == "") {
+		return withExitCode(exitConfigError, fmt.Errorf("'--tls-cert-file' and '--tls-private-key-file' must be set together"))
+	}
+	if certFile == "" {
+		slog.Warn("Serving the admission webhook without TLS")
+	}
+
+	// Resources hold only the package definition, without the files it refers to
+	linter, err := newServiceLinter(cmd, zarftesting.WithDefinitionOnly())
+	if err != nil {
+		return err
+	}
+	return listenAndServe(cmd, address, port, server.NewAdmissionServer(linter), certFile, keyFile)
+}
@@ -0,0 +1,113 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newBumpCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "bump [packages...]",
+		Short: "Increment the versions of Zarf packages",
+		Long: heredoc.Doc(`
+			Increment metadata.version in the zarf.yaml of the given packages, or of
+			the changed packages if none are given, keeping the comments and
+			formatting of the file.
+
+			Versions are bumped from the version of the package on the target branch,
+			or the --since reference, so running bump again does not bump a package
+			twice. Without --major, --minor or --patch, each package gets the
+			smallest bump the version increment rule of 'zt lint' accepts: minor
+			when components or images were added, major when components were
+			removed and require-major-bump-on-removal is set, patch otherwise.`),
+		RunE: bump,
+	}
+
+	flags := cmd.Flags()
+	addCommonFlags(flags)
+	flags.Bool("major", false, "Bump the major version")
+	flags.Bool("minor", false, "Bump the minor version")
+	flags.Bool("patch", false, "Bump the patch version")
+	cmd.MarkFlagsMutuallyExclusive("major", "minor", "patch")
+	flags.String("changelog", "", heredoc.Doc(`
+		Add this entry under the new version to the CHANGELOG.md of every bumped
+		package, which is created if it does not exist`))
+	flags.String("format", "text", "Output format of the bumped versions: text, yaml, json")
+	return cmd
+}
+
+func bump(cmd *cobra.Command, args []string) error {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	packageDirs := args
+	if len(packageDirs) == 0 {
+		packageDirs, err = findChangedPackages(cmd, configuration)
+		if err != nil {
+			return err
+		}
+		packageDirs, err = filterPackages(packageDirs, configuration)
+		if err != nil {
+			return err
+		}
+	}
+
+	bumper := zarf.NewBumper()
+	bumper.Remote = configuration.Remote
+	bumper.TargetBranch = configuration.TargetBranch
+	bumper.Since = configuration.Since
+	bumper.RequireMajorBumpOnRemoval = configuration.RequireMajorBumpOnRemoval
+	bumper.Changelog, _ = cmd.Flags().GetString("changelog")
+	for _, level := range []string{zarf.BumpMajor, zarf.BumpMinor, zarf.BumpPatch} {
+		if set, _ := cmd.Flags().GetBool(level); set {
+			bumper.Level = level
+		}
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	results := []*zarf.BumpResult{}
+	for _, packageDir := range packageDirs {
+		result, err := bumper.Bump(cmd.Context(), packageDir)
+		if err != nil {
+			return explainGitError(err)
+		}
+		results = append(results, result)
+		if format != "text" {
+			continue
+		}
+		if result.Skipped != "" {
+			fmt.Printf("%s: %s, skipped (%s)\n", result.Path, result.Version, result.Skipped)
+		} else {
+			fmt.Printf("%s: %s -> %s (%s)\n", result.Path, result.Previous, result.Version, result.Level)
+		}
+	}
+	if format != "text" {
+		return printDocument(results, format)
+	}
+	if len(packageDirs) == 0 {
+		fmt.Println("No packages to bump")
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func newCatalogCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "catalog",
+		Short: "Generate a browsable index of Zarf packages",
+		Long: heredoc.Doc(`
+			Generate a catalog of the packages for publishing to a portal: the name,
+			version, description, images, architectures and maintainers of every
+			package. All packages in the package directories are included by
+			default.
+
+			The format is chosen by the extension of --output: .json, .yaml or .md
+			for a Markdown index page. Without --output, the Markdown index is
+			printed. Maintainers are read from the comma separated 'maintainers'
+			annotation of the package metadata, else from CODEOWNERS.
+
+			--sbom-url and --vulnerabilities-url add links to the SBOM and the
+			vulnerability summary of each package, with {name} and {version}
+			replaced, e.g. 'https://portal.example.com/{name}/{version}/sbom'.`),
+		RunE: catalog,
+	}
+
+	flags := cmd.Flags()
+	addCommonFlags(flags)
+	setFlagDefault(flags, "output", "")
+	flags.Lookup("output").Usage = "File to write the catalog to, e.g. catalog.json or index.md"
+	flags.Bool("all", false, "Include all packages (the default)")
+	flags.Bool("changed", false, "Include the changed packages only")
+	flags.StringSlice("packages", []string{}, heredoc.Doc(`
+		Specific packages to include. May be specified multiple times or separate
+		values with commas`))
+	flags.String("sbom-url", "", "URL template of the SBOM of each package")
+	flags.String("vulnerabilities-url", "", "URL template of the vulnerability summary of each package")
+	return cmd
+}
+
+func catalog(cmd *cobra.Command, _ []string) error {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	output, _ := cmd.Flags().GetString("output")
+	var encode func(*zarf.Catalog) ([]byte, error)
+	switch filepath.Ext(output) {
+	case ".json":
+		encode = func(c *zarf.Catalog) ([]byte, error) { return json.MarshalIndent(c, "", "  ") }
+	case ".yaml", ".yml":
+		encode = func(c *zarf.Catalog) ([]byte, error) { return yaml.Marshal(c) }
+	case ".md", "":
+		encode = func(c *zarf.Catalog) ([]byte, error) { return []byte(c.Markdown()), nil }
+	default:
+		return withExitCode(exitConfigError, fmt.Errorf("unsupported catalog file %q, must end in .json, .yaml or .md", output))
+	}
+
+	packageDirs, err := selectPackages(cmd, configuration)
+	if err != nil {
+		return err
+	}
+	sbomURL, _ := cmd.Flags().GetString("sbom-url")
+	vulnerabilitiesURL, _ := cmd.Flags().GetString("vulnerabilities-url")
+	built, err := zarf.BuildCatalog(packageDirs, zarf.CatalogLinks{SBOM: sbomURL, Vulnerabilities: vulnerabilitiesURL})
+	if err != nil {
+		return err
+	}
+	content, err := encode(built)
+	if err != nil {
+		return err
+	}
+	if output == "" {
+		fmt.Print(string(content))
+		return nil
+	}
+	if err := os.WriteFile(output, content, 0644); err != nil {
+		return fmt.Errorf("failed to write the catalog: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote the catalog of %d package(s) to %s\n", len(built.Packages), output)
+	return nil
+}
@@ -0,0 +1,179 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+func newCICmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "ci",
+		Short: "Lint, build, install and report on changed packages in CI",
+		Long: heredoc.Doc(`
+			Run the full pipeline for CI systems: detect changed packages, lint them,
+			then build and install them into an ephemeral kind cluster, collect
+			diagnostics of failed packages and write reports.
+
+			Every option can be set with an environment variable, e.g. ZT_ALL=true
+			or ZT_KIND_VERSION=1.30, so CI wrappers only need to run 'zt ci'. The
+			output format is detected from the CI system (GitHub Actions, GitLab CI
+			or TeamCity) unless set with --output or ZT_OUTPUT.
+
+			Packages are installed only if they pass linting. The lint and install
+			reports are written as JSON to the artifacts directory, together with a
+			Markdown summary that is also added to the GitHub Actions job summary.
+			A kind cluster is created unless clusters are configured with
+			--kube-context, --kube-versions or the config file.`),
+		RunE: ci,
+	}
+
+	flags := cmd.Flags()
+	addLintFlags(flags)
+	addInstallFlags(flags)
+	addCommonLintAndInstallFlags(flags)
+	flags.Bool("ephemeral-cluster", true, heredoc.Doc(`
+		Create a kind cluster to install packages into when no clusters are
+		configured. Packages are installed into the current kubeconfig context
+		otherwise`))
+	flags.String("kind-version", "1.31", "Kubernetes version of the ephemeral kind cluster")
+
+	// Reports are written to the artifacts directory, zarf is installed if missing
+	setFlagDefault(flags, "artifacts-dir", "zt-artifacts")
+	setFlagDefault(flags, "install-zarf", "latest")
+	_ = flags.MarkHidden("report-file")
+	_ = flags.MarkHidden("report-format")
+	return cmd
+}
+
+// ciFlagsFromEnv are the flags read by lint and install that are not bound to the
+// configuration, and so are taken from their ZT_ environment variable by 'zt ci'
+var ciFlagsFromEnv = []string{"all", "packages", "output", "no-color", "github-groups", "porcelain"}
+
+func ci(cmd *cobra.Command, _ []string) error {
+	flags := cmd.Flags()
+	applyCIEnvironment(flags)
+
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	if configuration.EphemeralCluster && len(configuration.Clusters) == 0 {
+		setFlagDefault(flags, "kube-versions", configuration.KindVersion)
+	}
+	artifactsDir := configuration.ArtifactsDir
+	if artifactsDir == "" {
+		artifactsDir = "."
+	}
+	if err := os.MkdirAll(artifactsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create artifacts directory: %w", err)
+	}
+
+	// The reports of both phases are always written, the summary even if a phase fails
+	lintReport := filepath.Join(artifactsDir, "lint-report.json")
+	installReport := filepath.Join(artifactsDir, "install-report.json")
+	_ = flags.Set("report-format", "json")
+	defer writeCISummary(filepath.Join(artifactsDir, "summary.md"), lintReport, installReport)
+
+	_ = flags.Set("report-file", lintReport)
+	if err := lint(cmd, nil); err != nil {
+		return err
+	}
+	_ = flags.Set("report-file", installReport)
+	return install(cmd, nil)
+}
+
+// applyCIEnvironment sets the flags of ciFlagsFromEnv that were not set on the command
+// line from their environment variable, and the output format from the CI system
+func applyCIEnvironment(flags *flag.FlagSet) {
+	for _, name := range ciFlagsFromEnv {
+		if value, ok := os.LookupEnv("ZT_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_"))); ok && !flags.Changed(name) {
+			setFlagDefault(flags, name, value)
+		}
+	}
+	if format := detectCIOutput(); format != "" && !flags.Changed("output") && os.Getenv("ZT_OUTPUT") == "" {
+		setFlagDefault(flags, "output", format)
+	}
+}
+
+// writeCISummary renders the reports that were written as Markdown to path and
+// appends it to the GitHub Actions job summary
+func writeCISummary(path string, reports ...string) {
+	var sections []string
+	for _, report := range reports {
+		content, err := os.ReadFile(report)
+		if err != nil {
+			continue
+		}
+		markdown, err := zarf.ReportMarkdown(content)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to render %s: %v\n", report, err)
+			continue
+		}
+		sections = append(sections, markdown)
+	}
+	if len(sections) == 0 {
+		return
+	}
+	summary := strings.Join(sections, "\n")
+	if err := os.WriteFile(path, []byte(summary), 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write summary: %v\n", err)
+	}
+
+	stepSummary := os.Getenv("GITHUB_STEP_SUMMARY")
+	if stepSummary == "" {
+		return
+	}
+	file, err := os.OpenFile(stepSummary, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err == nil {
+		_, err = file.WriteString(summary)
+		err = errors.Join(err, file.Close())
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to write GitHub job summary: %v\n", err)
+	}
+}
+
+// detectCIOutput returns the output format for the CI system zt runs in, if any
+func detectCIOutput() string {
+	switch {
+	case os.Getenv("GITHUB_ACTIONS") == "true":
+		return "github"
+	case os.Getenv("GITLAB_CI") != "":
+		return "gitlab"
+	case os.Getenv("TEAMCITY_VERSION") != "":
+		return "teamcity"
+	}
+	return ""
+}
+
+// setFlagDefault changes the value of a flag that was not set on the command line
+// without marking it as changed, so that the configuration file and environment
+// variables still take precedence
+func setFlagDefault(flags *flag.FlagSet, name string, value string) {
+	f := flags.Lookup(name)
+	_ = f.Value.Set(value)
+	f.DefValue = f.Value.String()
+}
@@ -0,0 +1,128 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// outsideCI clears the variables of the CI systems zt detects
+func outsideCI(t *testing.T) {
+	for _, name := range []string{"GITHUB_ACTIONS", "GITLAB_CI", "TEAMCITY_VERSION", "ZT_OUTPUT"} {
+		t.Setenv(name, "")
+		require.NoError(t, os.Unsetenv(name))
+	}
+}
+
+func TestApplyCIEnvironment(t *testing.T) {
+	outsideCI(t)
+	t.Setenv("ZT_ALL", "true")
+	t.Setenv("ZT_PACKAGES", "packages/api,packages/web")
+	t.Setenv("ZT_NO_COLOR", "true")
+
+	// Flags set on the command line take precedence
+	flags := newCICmd().Flags()
+	require.NoError(t, flags.Set("no-color", "false"))
+	applyCIEnvironment(flags)
+
+	all, _ := flags.GetBool("all")
+	assert.True(t, all)
+	assert.False(t, flags.Changed("all"), "environment variables must not mark flags as changed")
+	packages, _ := flags.GetStringSlice("packages")
+	assert.Equal(t, []string{"packages/api", "packages/web"}, packages)
+	noColor, _ := flags.GetBool("no-color")
+	assert.False(t, noColor)
+	output, _ := flags.GetString("output")
+	assert.Equal(t, "text", output)
+
+	// The output format is detected from the CI system unless set
+	t.Setenv("GITHUB_ACTIONS", "true")
+	flags = newCICmd().Flags()
+	applyCIEnvironment(flags)
+	output, _ = flags.GetString("output")
+	assert.Equal(t, "github", output)
+
+	t.Setenv("ZT_OUTPUT", "json")
+	flags = newCICmd().Flags()
+	applyCIEnvironment(flags)
+	output, _ = flags.GetString("output")
+	assert.Equal(t, "json", output)
+
+	flags = newCICmd().Flags()
+	require.NoError(t, flags.Set("output", "ndjson"))
+	applyCIEnvironment(flags)
+	output, _ = flags.GetString("output")
+	assert.Equal(t, "ndjson", output)
+}
+
+func TestDetectCIOutput(t *testing.T) {
+	testCases := []struct {
+		name     string
+		env      map[string]string
+		expected string
+	}{
+		{name: "outside of CI"},
+		{name: "GitHub Actions", env: map[string]string{"GITHUB_ACTIONS": "true"}, expected: "github"},
+		{name: "GitHub Actions disabled", env: map[string]string{"GITHUB_ACTIONS": "false"}},
+		{name: "GitLab CI", env: map[string]string{"GITLAB_CI": "true"}, expected: "gitlab"},
+		{name: "TeamCity", env: map[string]string{"TEAMCITY_VERSION": "2024.03"}, expected: "teamcity"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			outsideCI(t)
+			for name, value := range tc.env {
+				t.Setenv(name, value)
+			}
+			assert.Equal(t, tc.expected, detectCIOutput())
+		})
+	}
+}
+
+func TestWriteCISummary(t *testing.T) {
+	dir := t.TempDir()
+	stepSummary := filepath.Join(dir, "step-summary.md")
+	require.NoError(t, os.WriteFile(stepSummary, []byte("# Build\n"), 0644))
+	t.Setenv("GITHUB_STEP_SUMMARY", stepSummary)
+
+	// Reports of phases that did not run are skipped
+	lintReport := filepath.Join(dir, "lint-report.json")
+	content, err := json.Marshal(zarf.NewLintReport([]*zarf.ValidationResult{{PackagePath: "packages/api", Valid: true}}))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(lintReport, content, 0644))
+	expected, err := zarf.ReportMarkdown(content)
+	require.NoError(t, err)
+
+	summary := filepath.Join(dir, "summary.md")
+	writeCISummary(summary, lintReport, filepath.Join(dir, "install-report.json"))
+	written, err := os.ReadFile(summary)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(written))
+	appended, err := os.ReadFile(stepSummary)
+	require.NoError(t, err)
+	assert.Equal(t, "# Build\n"+expected, string(appended))
+
+	// Without any report there is nothing to summarize
+	require.NoError(t, os.Remove(summary))
+	writeCISummary(summary, filepath.Join(dir, "missing.json"))
+	assert.NoFileExists(t, summary)
+}
@@ -0,0 +1,141 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion bash|zsh|fish|powershell",
+		Short: "Generate the autocompletion script for a shell",
+		Long: heredoc.Doc(`
+			Print the autocompletion script for the given shell. Besides commands and
+			flags, package paths are completed for --packages and the <package>
+			arguments of 'zt inspect' and 'zt diff' by scanning the zarf-dirs.
+
+			Bash (requires the bash-completion package):
+
+			    source <(zt completion bash)
+
+			Zsh (with compinit enabled):
+
+			    zt completion zsh > "${fpath[1]}/_zt"
+
+			Fish:
+
+			    zt completion fish > ~/.config/fish/completions/zt.fish
+
+			PowerShell:
+
+			    zt completion powershell | Out-String | Invoke-Expression`),
+		ValidArgs:             []string{"bash", "zsh", "fish", "powershell"},
+		Args:                  cobra.MatchAll(cobra.ExactArgs(1), cobra.OnlyValidArgs),
+		DisableFlagsInUseLine: true,
+		RunE:                  completion,
+	}
+}
+
+func completion(cmd *cobra.Command, args []string) error {
+	root := cmd.Root()
+	switch args[0] {
+	case "bash":
+		return root.GenBashCompletionV2(os.Stdout, true)
+	case "zsh":
+		return root.GenZshCompletion(os.Stdout)
+	case "fish":
+		return root.GenFishCompletion(os.Stdout, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(os.Stdout)
+	}
+	return fmt.Errorf("unsupported shell %q", args[0])
+}
+
+// registerPackageCompletions completes package paths for the --packages flag and the
+// <package> argument of cmd and all of its subcommands
+func registerPackageCompletions(cmd *cobra.Command) {
+	if cmd.Flags().Lookup("packages") != nil {
+		_ = cmd.RegisterFlagCompletionFunc("packages", completePackageList)
+	}
+	if strings.Contains(cmd.Use, "<package>") {
+		cmd.ValidArgsFunction = completePackageArg
+	}
+	for _, sub := range cmd.Commands() {
+		registerPackageCompletions(sub)
+	}
+}
+
+// completePackageList completes the last of the comma separated packages being typed,
+// leaving out the packages listed already
+func completePackageList(cmd *cobra.Command, _ []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	listed, current := "", toComplete
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		listed, current = toComplete[:i+1], toComplete[i+1:]
+	}
+	skip := map[string]bool{}
+	for _, pkg := range strings.Split(listed, ",") {
+		skip[pkg] = true
+	}
+
+	var completions []string
+	for _, pkg := range packageCompletions(cmd, current) {
+		if !skip[pkg] {
+			completions = append(completions, listed+pkg)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
+
+func completePackageArg(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	return packageCompletions(cmd, toComplete), cobra.ShellCompDirectiveNoFileComp
+}
+
+// packageCompletions returns the packages in the zarf-dirs of the configuration,
+// without the excluded ones, whose path starts with prefix. Commands without a
+// zarf-dirs flag fall back to the zarf-dirs of the config file.
+func packageCompletions(cmd *cobra.Command, prefix string) []string {
+	zarfDirs, excluded := []string{"packages"}, []string{}
+	if configuration, err := config.LoadConfiguration(cfgFile, cmd, false); err == nil {
+		zarfDirs, excluded = configuration.ZarfDirs, configuration.ExcludedPackages
+	}
+
+	packageDirs, err := zarf.FindZarfPackages(zarfDirs)
+	if err != nil {
+		return nil
+	}
+	packageDirs, err = zarf.FilterExcludedPackages(packageDirs, excluded)
+	if err != nil {
+		return nil
+	}
+
+	var completions []string
+	for _, pkg := range packageDirs {
+		if strings.HasPrefix(pkg, prefix) {
+			completions = append(completions, pkg)
+		}
+	}
+	return completions
+}
@@ -0,0 +1,142 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func newConfigCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Validate and show the zt configuration",
+	}
+	cmd.AddCommand(newConfigValidateCmd())
+	cmd.AddCommand(newConfigShowCmd())
+	return cmd
+}
+
+func newConfigValidateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "validate",
+		Short: "Validate the config file",
+		Long: heredoc.Doc(`
+			Check that all keys of the config file (given with --config or found in the
+			config search locations) are known configuration keys, flagging typos such
+			as 'target_branch', and that all values are valid.`),
+		RunE: configValidate,
+	}
+	cmd.Flags().StringVar(&cfgFile, "config", "", "Config file")
+	return cmd
+}
+
+func newConfigShowCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "show",
+		Short: "Print the effective configuration",
+		Long: heredoc.Doc(`
+			Print the effective configuration of 'lint' and 'install' combining flags,
+			ZT_ environment variables, the config file and defaults. With --resolved,
+			the source of each value is printed as well.`),
+		RunE: configShow,
+	}
+
+	flags := cmd.Flags()
+	addLintFlags(flags)
+	addInstallFlags(flags)
+	addCommonLintAndInstallFlags(flags)
+	flags.Bool("resolved", false, "Print where each value came from: flag, env, file or default")
+	flags.String("format", "yaml", "Output format of the configuration: yaml, json")
+	return cmd
+}
+
+func configValidate(cmd *cobra.Command, _ []string) error {
+	path, err := config.FindConfigFile(cfgFile)
+	if err != nil {
+		return err
+	}
+	if path == "" {
+		return fmt.Errorf("no config file found, specify one with --config")
+	}
+
+	problems, err := config.ValidateConfigFile(path)
+	if err != nil {
+		return err
+	}
+	if _, err := config.LoadConfiguration(path, cmd, false); err != nil {
+		problems = append(problems, err.Error())
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("%s: %s\n", path, problem)
+	}
+	if len(problems) > 0 {
+		return withExitCode(exitConfigError, fmt.Errorf("config file %s has %d problem(s)", path, len(problems)))
+	}
+	fmt.Printf("Config file %s is valid\n", path)
+	return nil
+}
+
+func configShow(cmd *cobra.Command, _ []string) error {
+	resolved, err := config.Resolve(cfgFile, cmd)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	withSources, _ := cmd.Flags().GetBool("resolved")
+	format, _ := cmd.Flags().GetString("format")
+
+	switch format {
+	case "json":
+		document := map[string]interface{}{}
+		for _, value := range resolved {
+			if withSources {
+				document[value.Key] = map[string]interface{}{"value": value.Value, "source": value.Source}
+			} else {
+				document[value.Key] = value.Value
+			}
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(document)
+	case "yaml":
+		// A MapSlice keeps the keys in the order of the configuration
+		var document yaml.MapSlice
+		for _, value := range resolved {
+			if withSources {
+				document = append(document, yaml.MapItem{Key: value.Key, Value: yaml.MapSlice{
+					{Key: "value", Value: value.Value},
+					{Key: "source", Value: value.Source},
+				}})
+			} else {
+				document = append(document, yaml.MapItem{Key: value.Key, Value: value.Value})
+			}
+		}
+		out, err := yaml.Marshal(document)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q, must be one of: yaml, json", format)
+	}
+}
@@ -0,0 +1,76 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "diff <package>",
+		Short: "Summarize the changes of a Zarf package since a Git reference",
+		Long: heredoc.Doc(`
+			Compare the zarf.yaml of a package against its zarf.yaml at a Git
+			reference and print the components, images and variables added,
+			removed or changed, and the version bump, e.g. to review a pull
+			request. Packages that do not exist at the reference are new.
+
+			With --render, the charts and manifests of the package are rendered
+			at both revisions and the changed Kubernetes objects are shown as a
+			unified diff. Secret data is redacted.`),
+		Args: cobra.ExactArgs(1),
+		RunE: diff,
+	}
+
+	flags := cmd.Flags()
+	flags.String("ref", "main", "Git reference to compare the package against")
+	flags.String("format", "text", "Output format of the diff: text, json, markdown")
+	flags.Bool("render", false, "Render the charts and manifests at both revisions and diff the Kubernetes objects")
+	return cmd
+}
+
+func diff(cmd *cobra.Command, args []string) error {
+	ref, _ := cmd.Flags().GetString("ref")
+	format, _ := cmd.Flags().GetString("format")
+	render, _ := cmd.Flags().GetBool("render")
+	if format != "text" && format != "json" && format != "markdown" {
+		return withExitCode(exitConfigError, fmt.Errorf("unsupported format %q, must be one of: text, json, markdown", format))
+	}
+
+	packageDiff, err := zarf.DiffPackageAt(cmd.Context(), args[0], ref)
+	if err != nil {
+		return fmt.Errorf("failed to diff %s against %s: %w", args[0], ref, explainGitError(err))
+	}
+	if render {
+		packageDiff.Manifests, packageDiff.ManifestWarnings, err = zarf.DiffManifestsAt(cmd.Context(), args[0], ref)
+		if err != nil {
+			return fmt.Errorf("failed to diff the rendered manifests of %s against %s: %w", args[0], ref, explainGitError(err))
+		}
+	}
+	switch format {
+	case "text":
+		fmt.Print(packageDiff.Text())
+	case "markdown":
+		fmt.Print(packageDiff.Markdown())
+	default:
+		return printDocument(packageDiff, format)
+	}
+	return nil
+}
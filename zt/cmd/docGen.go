@@ -15,33 +15,152 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
 	"github.com/spf13/cobra"
 	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
 )
 
+// commandSpec describes a command and its flags for docs sites
+type commandSpec struct {
+	Name     string        `json:"name"`
+	Path     string        `json:"path"`
+	Usage    string        `json:"usage"`
+	Short    string        `json:"short"`
+	Long     string        `json:"long,omitempty"`
+	Aliases  []string      `json:"aliases,omitempty"`
+	Flags    []flagSpec    `json:"flags,omitempty"`
+	Commands []commandSpec `json:"commands,omitempty"`
+}
+
+type flagSpec struct {
+	Name        string `json:"name"`
+	Shorthand   string `json:"shorthand,omitempty"`
+	Type        string `json:"type"`
+	Default     string `json:"default"`
+	Description string `json:"description"`
+	Persistent  bool   `json:"persistent,omitempty"`
+}
+
 func newGenerateDocsCmd() *cobra.Command {
-	return &cobra.Command{
+	cmd := &cobra.Command{
 		Use:   "doc-gen",
 		Short: "Generate documentation",
 		Long: heredoc.Doc(`
-			Generate documentation for all commands
-			to the 'docs' directory.`),
+			Generate documentation for all commands to the 'doc' directory: a
+			Markdown page and a man page (in 'man') per command, the commands with
+			their flags as JSON for docs sites (zt.json) and a reference of the
+			config file keys (config-reference.md).`),
 		Hidden: true,
 		RunE:   generateDocs,
 	}
+	cmd.Flags().String("dir", "doc", "Directory to generate the documentation in")
+	return cmd
 }
 
-func generateDocs(_ *cobra.Command, _ []string) error {
+func generateDocs(cmd *cobra.Command, _ []string) error {
 	fmt.Println("Generating docs...")
 
-	err := doc.GenMarkdownTree(NewRootCmd(), "doc")
+	dir, _ := cmd.Flags().GetString("dir")
+	manDir := filepath.Join(dir, "man")
+	if err := os.MkdirAll(manDir, 0755); err != nil {
+		return err
+	}
+
+	root := NewRootCmd()
+	if err := doc.GenMarkdownTree(root, dir); err != nil {
+		return err
+	}
+	header := &doc.GenManHeader{Title: "ZT", Section: "1", Source: "zt " + Version}
+	if err := doc.GenManTree(root, header, manDir); err != nil {
+		return err
+	}
+
+	spec, err := json.MarshalIndent(newCommandSpec(root), "", "  ")
 	if err != nil {
 		return err
 	}
+	if err := os.WriteFile(filepath.Join(dir, "zt.json"), append(spec, '\n'), 0644); err != nil {
+		return err
+	}
+
+	flags := pflag.NewFlagSet("zt", pflag.ContinueOnError)
+	collectFlags(root, flags)
+	reference := configReference(config.Reference(flags))
+	if err := os.WriteFile(filepath.Join(dir, "config-reference.md"), []byte(reference), 0644); err != nil {
+		return err
+	}
 
 	fmt.Println("Done.")
 	return nil
 }
+
+// newCommandSpec describes cmd and its available subcommands. Inherited flags are only
+// listed on the command defining them.
+func newCommandSpec(cmd *cobra.Command) commandSpec {
+	spec := commandSpec{
+		Name:    cmd.Name(),
+		Path:    cmd.CommandPath(),
+		Usage:   cmd.UseLine(),
+		Short:   cmd.Short,
+		Long:    cmd.Long,
+		Aliases: cmd.Aliases,
+	}
+	cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Name == "help" {
+			return
+		}
+		spec.Flags = append(spec.Flags, flagSpec{
+			Name:        f.Name,
+			Shorthand:   f.Shorthand,
+			Type:        f.Value.Type(),
+			Default:     f.DefValue,
+			Description: strings.Join(strings.Fields(f.Usage), " "),
+			Persistent:  cmd.PersistentFlags().Lookup(f.Name) != nil,
+		})
+	})
+	for _, sub := range cmd.Commands() {
+		if sub.IsAvailableCommand() {
+			spec.Commands = append(spec.Commands, newCommandSpec(sub))
+		}
+	}
+	return spec
+}
+
+// collectFlags adds the flags of cmd and its subcommands to flags, keeping the first
+// definition of flags defined by several commands
+func collectFlags(cmd *cobra.Command, flags *pflag.FlagSet) {
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if flags.Lookup(f.Name) == nil {
+			flags.AddFlag(f)
+		}
+	})
+	for _, sub := range cmd.Commands() {
+		collectFlags(sub, flags)
+	}
+}
+
+// configReference renders the config file reference as a Markdown table
+func configReference(reference []config.KeyReference) string {
+	var b strings.Builder
+	b.WriteString("# Configuration reference\n\n")
+	b.WriteString("Keys of the zt config file (e.g. `zt.yaml`). Every key can also be set with its\n")
+	b.WriteString("environment variable, and most with the flag of the same name.\n\n")
+	b.WriteString("| Key | Type | Default | Environment variable | Description |\n")
+	b.WriteString("|-----|------|---------|----------------------|-------------|\n")
+	for _, key := range reference {
+		def := ""
+		if key.Default != "" {
+			def = "`" + key.Default + "`"
+		}
+		fmt.Fprintf(&b, "| `%s` | %s | %s | `%s` | %s |\n", key.Key, key.Type, def, key.Env, strings.ReplaceAll(key.Description, "|", "\\|"))
+	}
+	return b.String()
+}
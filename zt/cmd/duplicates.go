@@ -0,0 +1,92 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newDuplicatesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "duplicates",
+		Short: "List the images and charts used at different versions across Zarf packages",
+		Long: heredoc.Doc(`
+			List the images pinned at different tags or digests, and the charts used
+			at different versions, across the packages, with the packages and
+			components using each version, to help converge on a single version.
+
+			Images are compared by their fully qualified repository ('nginx' is
+			'docker.io/library/nginx'), local charts by the name and version of
+			their Chart.yaml. All packages in the package directories are included
+			by default.
+
+			'zt lint --check-duplicate-versions' reports the same as warnings on
+			the linted packages.`),
+		RunE: duplicates,
+	}
+
+	flags := cmd.Flags()
+	addCommonFlags(flags)
+	flags.Bool("all", false, "Compare all packages (the default)")
+	flags.Bool("changed", false, "Compare the changed packages only")
+	flags.StringSlice("packages", []string{}, heredoc.Doc(`
+		Specific packages to compare. May be specified multiple times or separate
+		values with commas`))
+	flags.String("format", "text", "Output format of the duplicates: text, yaml, json")
+	return cmd
+}
+
+func duplicates(cmd *cobra.Command, _ []string) error {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	packageDirs, err := selectPackages(cmd, configuration)
+	if err != nil {
+		return err
+	}
+	found, err := zarf.FindDuplicates(packageDirs)
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	if format != "text" {
+		return printDocument(found, format)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TYPE\tNAME\tVERSION\tUSED BY")
+	for _, kind := range []struct {
+		name       string
+		duplicates []zarf.Duplicate
+	}{{"image", found.Images}, {"chart", found.Charts}} {
+		for _, duplicate := range kind.duplicates {
+			for _, version := range duplicate.Versions {
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", kind.name, duplicate.Name, version.Version, imageUsers(zarf.ImageUsage{Users: version.Users}))
+			}
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,75 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"errors"
+	osexec "os/exec"
+)
+
+// Exit codes of zt. They are part of the command line interface, so scripts can
+// branch on them, and must not change.
+const (
+	exitSuccess = 0
+	// exitLintErrors is returned when packages fail linting, and for unexpected errors
+	exitLintErrors = 1
+	// exitDeployFailures is returned when packages fail to deploy or their tests fail
+	exitDeployFailures = 2
+	// exitConfigError is returned for invalid flags, config files and package selections
+	exitConfigError = 3
+	// exitEnvironmentError is returned when a required tool such as zarf or kubectl is
+	// missing, or the environment lacks what zt needs, such as the Git history
+	exitEnvironmentError = 4
+)
+
+// exitError is an error that terminates zt with a specific exit code
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// withExitCode returns err annotated with the exit code zt terminates with, or nil
+// if err is nil
+func withExitCode(code int, err error) error {
+	if err == nil {
+		return nil
+	}
+	return &exitError{code: code, err: err}
+}
+
+// exitCode returns the exit code for the error a command returned. Errors caused by
+// executables missing from the PATH are always environment errors, whatever the
+// command was doing when it ran them.
+func exitCode(err error) int {
+	var exitErr *exitError
+	switch {
+	case err == nil:
+		return exitSuccess
+	case errors.Is(err, osexec.ErrNotFound):
+		return exitEnvironmentError
+	case errors.As(err, &exitErr):
+		return exitErr.code
+	default:
+		return exitLintErrors
+	}
+}
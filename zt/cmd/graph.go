@@ -0,0 +1,82 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newGraphCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "graph",
+		Short: "Print the dependency graph of Zarf packages",
+		Long: heredoc.Doc(`
+			Build the dependency graph of all packages in the package directories
+			(or the packages given with --packages) and print it in DOT or Mermaid
+			format.
+
+			The graph contains component dependencies ('depsWith'), component
+			imports, charts and files referenced from other packages, and files
+			shared between packages.`),
+		RunE: graph,
+	}
+
+	flags := cmd.Flags()
+	addCommonFlags(flags)
+	flags.StringSlice("packages", []string{}, heredoc.Doc(`
+		Specific packages to include in the graph. May be specified multiple times
+		or separate values with commas`))
+	flags.String("format", "dot", "Output format of the graph: dot, mermaid")
+	return cmd
+}
+
+func graph(cmd *cobra.Command, _ []string) error {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	packageDirs := configuration.Packages
+	if len(packageDirs) == 0 {
+		packageDirs, err = zarf.FindZarfPackages(configuration.ZarfDirs)
+		if err != nil {
+			return fmt.Errorf("failed to find packages: %w", err)
+		}
+	}
+
+	dependencyGraph, err := zarf.BuildDependencyGraph(packageDirs)
+	if err != nil {
+		return fmt.Errorf("failed to build dependency graph: %w", err)
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	switch strings.ToLower(format) {
+	case "dot":
+		fmt.Print(dependencyGraph.DOT())
+	case "mermaid":
+		fmt.Print(dependencyGraph.Mermaid())
+	default:
+		return fmt.Errorf("unsupported graph format %q, must be one of: dot, mermaid", format)
+	}
+	return nil
+}
@@ -0,0 +1,219 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newImagesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "images",
+		Short: "List the images of Zarf packages",
+		Long: heredoc.Doc(`
+			List every image of the components of the packages, deduplicated, with
+			the packages and components using it. All packages in the package
+			directories are included, or only the changed packages with --changed.
+
+			With --diff, print the images added and removed compared to another Git
+			reference instead, e.g. to mirror only the new images to a registry.
+
+			With --non-compliant, print the images per package that violate the
+			image policy of --approved-images and --required-image-label, and exit
+			with a non-zero code if there are any.`),
+		RunE: images,
+	}
+
+	flags := cmd.Flags()
+	addCommonFlags(flags)
+	flags.Bool("all", false, "List the images of all packages (the default)")
+	flags.Bool("changed", false, "List the images of the changed packages only")
+	flags.StringSlice("packages", []string{}, heredoc.Doc(`
+		Specific packages to list the images of. May be specified multiple times
+		or separate values with commas`))
+	flags.String("diff", "", "Git reference to compare the image set against")
+	flags.String("format", "text", "Output format of the images: text, yaml, json")
+	flags.Bool("non-compliant", false, "List the images violating the image policy per package")
+	addImagePolicyFlags(flags)
+	return cmd
+}
+
+func images(cmd *cobra.Command, _ []string) error {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	changed, _ := cmd.Flags().GetBool("changed")
+	packageDirs, err := selectPackages(cmd, configuration)
+	if err != nil {
+		return err
+	}
+
+	inventory, err := zarf.BuildImageInventory(packageDirs)
+	if err != nil {
+		return err
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	diffRef, _ := cmd.Flags().GetString("diff")
+	if nonCompliant, _ := cmd.Flags().GetBool("non-compliant"); nonCompliant {
+		if diffRef != "" {
+			return withExitCode(exitConfigError, fmt.Errorf("specifying '--non-compliant' together with '--diff' is not allowed"))
+		}
+		return printImageViolations(cmd, configuration, inventory, format)
+	}
+	if diffRef == "" {
+		if format == "text" {
+			for _, usage := range inventory.Images {
+				fmt.Printf("%s\t%s\n", usage.Image, imageUsers(usage))
+			}
+			return nil
+		}
+		return printDocument(inventory, format)
+	}
+
+	// Without --changed or --packages, packages removed since the reference count too
+	baseDirs := packageDirs
+	if !changed && len(configuration.Packages) == 0 {
+		refDirs, err := zarf.FindZarfPackagesAt(cmd.Context(), diffRef, configuration.ZarfDirs)
+		if err != nil {
+			return fmt.Errorf("failed to find packages at %s: %w", diffRef, explainGitError(err))
+		}
+		if baseDirs, err = filterPackages(refDirs, configuration); err != nil {
+			return err
+		}
+	}
+	base, err := zarf.BuildImageInventoryAt(cmd.Context(), diffRef, baseDirs)
+	if err != nil {
+		return fmt.Errorf("failed to read packages at %s: %w", diffRef, explainGitError(err))
+	}
+	diff := zarf.DiffImages(base, inventory)
+	if format == "text" {
+		for _, usage := range diff.Added {
+			fmt.Printf("+ %s\t%s\n", usage.Image, imageUsers(usage))
+		}
+		for _, usage := range diff.Removed {
+			fmt.Printf("- %s\t%s\n", usage.Image, imageUsers(usage))
+		}
+		return nil
+	}
+	return printDocument(diff, format)
+}
+
+// printImageViolations prints the images of the inventory violating the image policy,
+// returning an error if there are any
+func printImageViolations(cmd *cobra.Command, configuration *config.Configuration, inventory *zarf.ImageInventory, format string) error {
+	policy := newImagePolicy(configuration)
+	if policy == nil {
+		return withExitCode(exitConfigError, fmt.Errorf("'--non-compliant' requires '--approved-images' or '--required-image-label'"))
+	}
+
+	violations := policy.Violations(cmd.Context(), inventory)
+	if format == "text" {
+		for _, pkg := range violations {
+			for _, image := range pkg.Images {
+				fmt.Printf("%s\t%s\t%s\t%s\n", pkg.Package, image.Image, strings.Join(image.Components, ", "), image.Reason)
+			}
+		}
+	} else if err := printDocument(violations, format); err != nil {
+		return err
+	}
+
+	count := 0
+	for _, pkg := range violations {
+		count += len(pkg.Images)
+	}
+	if count > 0 {
+		return withExitCode(exitLintErrors, fmt.Errorf("%d image(s) of %d package(s) do not comply with the image policy", count, len(violations)))
+	}
+	return nil
+}
+
+// selectPackages returns the packages of --packages, the changed packages with
+// --changed, or all packages, filtered by filterPackages
+func selectPackages(cmd *cobra.Command, configuration *config.Configuration) ([]string, error) {
+	changed, _ := cmd.Flags().GetBool("changed")
+	if changed && (configuration.ProcessAllPackages || len(configuration.Packages) > 0) {
+		return nil, fmt.Errorf("specifying '--changed' together with '--all' or '--packages' is not allowed")
+	}
+
+	var err error
+	packageDirs := configuration.Packages
+	if changed {
+		packageDirs, err = findChangedPackages(cmd, configuration)
+		if err != nil {
+			return nil, err
+		}
+	} else if len(packageDirs) == 0 {
+		packageDirs, err = zarf.FindZarfPackages(configuration.ZarfDirs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find packages: %w", err)
+		}
+	}
+
+	return filterPackages(packageDirs, configuration)
+}
+
+// findChangedPackages returns the packages changed compared to the target branch or
+// the since reference, fetching the history first with auto-fetch
+func findChangedPackages(cmd *cobra.Command, configuration *config.Configuration) ([]string, error) {
+	fetched, err := zarf.EnsureHistory(cmd.Context(), configuration.Remote, configuration.TargetBranch, configuration.Since, configuration.AutoFetch)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find changed packages: %w", explainGitError(err))
+	}
+	if fetched {
+		fmt.Fprintf(os.Stderr, "Fetched the history of %s/%s\n", configuration.Remote, configuration.TargetBranch)
+	}
+	packageDirs, err := zarf.FindChangedPackages(cmd.Context(), configuration.Remote, configuration.TargetBranch, configuration.Since, configuration.ZarfDirs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find changed packages: %w", explainGitError(err))
+	}
+	return packageDirs, nil
+}
+
+// filterPackages applies the excluded packages, selector and deprecation filters
+func filterPackages(packageDirs []string, configuration *config.Configuration) ([]string, error) {
+	packageDirs, err := zarf.FilterExcludedPackages(packageDirs, configuration.ExcludedPackages)
+	if err != nil {
+		return nil, withExitCode(exitConfigError, err)
+	}
+	packageDirs, err = zarf.FilterSelectedPackages(packageDirs, configuration.Selector)
+	if err != nil {
+		return nil, withExitCode(exitConfigError, err)
+	}
+	if configuration.ExcludeDeprecated {
+		packageDirs = zarf.FilterDeprecatedPackages(packageDirs)
+	}
+	return packageDirs, nil
+}
+
+// imageUsers formats the components using an image as package/component
+func imageUsers(usage zarf.ImageUsage) string {
+	users := make([]string, 0, len(usage.Users))
+	for _, user := range usage.Users {
+		users = append(users, user.Package+"/"+user.Component)
+	}
+	return strings.Join(users, ", ")
+}
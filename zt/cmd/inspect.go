@@ -0,0 +1,78 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v2"
+)
+
+func newInspectCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "inspect <package>",
+		Short: "Print the parsed model of a Zarf package",
+		Long: heredoc.Doc(`
+			Print the package as zt sees it: metadata, variables, constants and
+			components with their images, charts, manifests and files, after
+			defaults are applied (e.g. the package name falls back to the directory
+			name and chart release names to the chart name), together with the
+			variable sets and assertions used by 'zt install'.`),
+		Args: cobra.ExactArgs(1),
+		RunE: inspect,
+	}
+
+	flags := cmd.Flags()
+	flags.String("format", "yaml", "Output format of the package: yaml, json")
+	flags.String("cache-dir", "", "Directory for caching parsed zarf.yaml files across runs")
+	return cmd
+}
+
+func inspect(cmd *cobra.Command, args []string) error {
+	cacheDir, _ := cmd.Flags().GetString("cache-dir")
+	util.SetCacheDir(cacheDir)
+
+	model, err := zarf.InspectPackage(args[0])
+	if err != nil {
+		return err
+	}
+	format, _ := cmd.Flags().GetString("format")
+	return printDocument(model, format)
+}
+
+// printDocument prints doc to stdout as YAML or JSON
+func printDocument(doc interface{}, format string) error {
+	switch format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(doc)
+	case "yaml":
+		out, err := yaml.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		fmt.Print(string(out))
+		return nil
+	default:
+		return fmt.Errorf("unsupported format %q, must be one of: yaml, json", format)
+	}
+}
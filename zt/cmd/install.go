@@ -15,13 +15,17 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/cpepper96/zarf-testing/pkg/config"
 	"github.com/cpepper96/zarf-testing/pkg/output"
+	"github.com/cpepper96/zarf-testing/pkg/util"
 	"github.com/cpepper96/zarf-testing/pkg/zarf"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
@@ -66,50 +70,151 @@ func addInstallFlags(flags *flag.FlagSet) {
 		previous chart revision if they have been deleted or renamed at the current chart
 		revision`))
 	flags.String("namespace", "", heredoc.Doc(`
-		Namespace to install the release(s) into. If not specified, each release will be
-		installed in its own randomly generated namespace`))
+		Namespace to deploy packages with a single namespace into. If not specified, each
+		such package is deployed into its own randomly generated namespace`))
 	flags.String("release-name", "", heredoc.Doc(`
 		Name for the release. If not specified, is set to the chart name and a random 
 		identifier.`))
 	flags.Bool("skip-clean-up", false, "Skip resources clean-up after testing")
+	flags.Bool("check-cleanup", false, heredoc.Doc(`
+		Snapshot the cluster before deploying each package and after removing it, and
+		fail packages that leave resources such as CRDs, cluster roles or namespaces
+		behind. Has no effect with --skip-clean-up`))
+	flags.Int("soak", 1, heredoc.Doc(`
+		Deploy, test and remove every package this many times in a row, failing it
+		if the cluster does not return to its previous state after any cycle, e.g.
+		because remove actions leak PVCs, CRDs or namespaces. Packages are deployed
+		alone while soaking. Not allowed with --skip-clean-up`))
+	flags.Bool("test-removal", false, heredoc.Doc(`
+		Test removing packages that deployed and passed their tests: fail them if
+		'zarf package remove' fails or workloads, pods, services or PVCs remain in
+		the namespaces created by the deployment, and report the removal time. Not
+		allowed with --skip-clean-up`))
+	flags.Bool("mirror-resources", false, heredoc.Doc(`
+		Also test mirroring the images and repositories of every package with 'zarf
+		package mirror-resources', reporting whether each image arrived in the
+		registry. Images go to --mirror-registry-url, or a disposable registry started
+		with docker if not set; repositories are only mirrored with --mirror-git-url.
+		Credentials are read from ZT_MIRROR_REGISTRY_USERNAME,
+		ZT_MIRROR_REGISTRY_PASSWORD, ZT_MIRROR_GIT_USERNAME and ZT_MIRROR_GIT_PASSWORD`))
+	flags.String("mirror-registry-url", "", "Registry to mirror images to with --mirror-resources, e.g. registry.test:5000")
+	flags.String("mirror-git-url", "", "Git server to mirror repositories to with --mirror-resources, e.g. https://git.test")
+	flags.Bool("resilience", false, heredoc.Doc(`
+		After the tests of a deployment passed, delete the pods of its deployments,
+		stateful sets and daemon sets and fail the deployment unless each workload
+		recovers within --resilience-timeout. The recovery time of each workload
+		is reported`))
+	flags.Duration("resilience-timeout", 5*time.Minute, "Time each workload has to recover with --resilience")
+	flags.Bool("benchmark", false, heredoc.Doc(`
+		Record the deploy time, the time spent pulling images and the time until each
+		workload was ready of every deployment in the results and the report. Implied
+		by the max-* thresholds`))
+	flags.String("benchmark-history", "", heredoc.Doc(`
+		History store (see 'zt results push') to compare deploy times against. The
+		baseline of a deployment is its average deploy time over its last 10 successful
+		runs with a benchmark`))
+	flags.Duration("max-deploy-time", 0, "Fail deployments whose 'zarf package deploy' takes longer. Zero disables the limit")
+	flags.Duration("max-image-pull-time", 0, "Fail deployments that spend longer pulling images. Zero disables the limit")
+	flags.Duration("max-ready-time", 0, heredoc.Doc(`
+		Fail deployments with a workload that takes longer to become ready, counted from
+		the start of the deployment. Zero disables the limit`))
+	flags.Float64("max-deploy-regression", 0, heredoc.Doc(`
+		Fail deployments whose deploy time exceeds their baseline from
+		--benchmark-history by more than this percentage, e.g. 50. Zero disables the
+		check`))
+	flags.Bool("skip-zarf-init", false, heredoc.Doc(`
+		Do not run 'zarf init' against clusters that have not been initialized`))
+	flags.StringSlice("zarf-init-components", []string{}, heredoc.Doc(`
+		Optional components to deploy when running 'zarf init', e.g. git-server. May be
+		specified multiple times or separate values with commas`))
+	flags.String("zarf-init-args", "", heredoc.Doc(`
+		Additional arguments for 'zarf init' (e.g. "--storage-class local-path")`))
+	flags.String("init-package", "", heredoc.Doc(`
+		Directory of a custom init package (kind ZarfInitConfig) to build and initialize
+		clusters with instead of the default init package of zarf`))
+	flags.String("zarf-deploy-extra-args", "", heredoc.Doc(`
+		Additional arguments for 'zarf package deploy' (e.g. "--set DOMAIN=test.local"),
+		templated like --zarf-extra-args`))
+	flags.Bool("print-logs", false, heredoc.Doc(`
+		Stream the output of zarf and kubectl while packages are deployed, each line
+		prefixed with the package name. Implied by --debug`))
+	flags.String("artifacts-dir", "", heredoc.Doc(`
+		Directory to write pod descriptions, events and pod logs of the namespaces of
+		packages that fail to deploy or test. Diagnostics are not collected if empty`))
+	flags.String("build-cache-dir", "", heredoc.Doc(`
+		Directory to cache built packages in, e.g. .zt-cache/builds. Packages are only
+		rebuilt when their files, the files they reference, the zarf version or the
+		build arguments changed. Packages are built into a temporary directory and
+		rebuilt every run if empty`))
+	flags.Duration("deployment-timeout", 10*time.Minute, "Timeout for building and deploying a single package")
+	flags.Duration("test-timeout", 5*time.Minute, "Timeout for testing a single deployed package")
+	flags.Duration("run-timeout", 0, heredoc.Doc(`
+		Timeout for the whole install run. Remaining packages are skipped once it
+		expires. Zero disables the limit`))
+	flags.Duration("wait-for-cluster", 0, heredoc.Doc(`
+		Wait up to this long for the cluster to become reachable before deploying,
+		e.g. for a cluster that is still starting. Zero checks the cluster once`))
+	flags.StringSlice("kube-context", []string{}, heredoc.Doc(`
+		Kubeconfig contexts of the clusters to install packages into, one after the
+		other. Results are reported per cluster. Clusters can also be configured with
+		a kubeconfig file in the 'clusters' section of the config file. May be
+		specified multiple times or separate values with commas`))
+	flags.StringSlice("kube-versions", []string{}, heredoc.Doc(`
+		Kubernetes versions (e.g. 1.29 or 1.29.8) or kind node images to create a kind
+		cluster for and install packages into, one after the other. A compatibility
+		matrix of packages and versions is reported. Clusters are deleted afterwards
+		unless --skip-clean-up is passed. Requires kind`))
+	flags.Int("parallel-deploys", 1, heredoc.Doc(`
+		Number of packages deployed at the same time. Packages are started after the
+		packages they depend on, deployments never share a namespace, and packages
+		installing CRDs (or all packages with --check-cleanup or --soak) are deployed alone.
+		The output of each package is printed once it is done`))
+	flags.StringSlice("deploy-set", []string{}, heredoc.Doc(`
+		Names of the variable sets in the zt-values directory of packages to deploy with,
+		all sets if not specified. Packages without a selected set are deployed with their
+		default values. May be specified multiple times or separate values with commas`))
+	flags.String("component-matrix", "", heredoc.Doc(`
+		Deploy packages once per selection of optional components: 'full' deploys all
+		optional components, 'minimal' only the required ones and 'each' the required
+		ones, then each optional component on its own. Packages are deployed with
+		their default components if empty`))
+	flags.StringSlice("deploy-order", []string{}, heredoc.Doc(`
+		Packages (by path or name) that must be deployed in the given relative order,
+		in addition to the order derived from package dependencies. May be specified
+		multiple times or separate values with commas`))
 	
 
 }
 
 func install(cmd *cobra.Command, _ []string) error {
+	start := time.Now()
+
 	// Setup output formatter
 	outputFormat, _ := cmd.Flags().GetString("output")
 	noColor, _ := cmd.Flags().GetBool("no-color")
 	githubGroups, _ := cmd.Flags().GetBool("github-groups")
+	porcelain, _ := cmd.Flags().GetBool("porcelain")
 	
-	var format output.Format
-	switch strings.ToLower(outputFormat) {
-	case "json":
-		format = output.FormatJSON
-	case "github":
-		format = output.FormatGitHub
-	default:
-		format = output.FormatText
-	}
-	
+	format := output.ParseFormat(outputFormat)
 	formatter := output.NewFormatter(&output.Config{
 		Format:       format,
 		NoColor:      noColor,
 		GithubGroups: githubGroups,
-		Writer:       os.Stdout,
+		Writer:       outputWriter(porcelain),
 	})
 	
 	formatter.Section("Zarf Package Deployment Testing")
 	
 	// Load configuration
-	configuration, err := config.LoadConfiguration("", cmd, false)
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
 	if err != nil {
 		formatter.Error("Failed to load configuration: %v", err)
 		if format == output.FormatJSON {
 			formatter.PrintJSON()
 		}
-		return fmt.Errorf("failed to load configuration: %w", err)
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
 	}
+	util.SetCacheDir(configuration.CacheDir)
 
 	// Determine which packages to test
 	var packagesToTest []string
@@ -145,14 +250,22 @@ func install(cmd *cobra.Command, _ []string) error {
 				if format == output.FormatJSON {
 					formatter.PrintJSON()
 				}
-				return fmt.Errorf("package not found: %s", pkg)
+				return withExitCode(exitConfigError, fmt.Errorf("package not found: %s", pkg))
 			}
 		}
 		packagesToTest = packages
 	} else {
 		formatter.Progress("Finding changed packages...")
-		changedPackages, err := zarf.FindChangedPackages(configuration.Remote, configuration.TargetBranch, dirs)
+		fetched, err := zarf.EnsureHistory(cmd.Context(), configuration.Remote, configuration.TargetBranch, configuration.Since, configuration.AutoFetch)
+		if fetched && err == nil {
+			formatter.Info("Fetched the history of %s/%s", configuration.Remote, configuration.TargetBranch)
+		}
+		var changedPackages []string
+		if err == nil {
+			changedPackages, err = zarf.FindChangedPackages(cmd.Context(), configuration.Remote, configuration.TargetBranch, configuration.Since, dirs)
+		}
 		if err != nil {
+			err = explainGitError(err)
 			formatter.Error("Failed to find changed packages: %v", err)
 			if format == output.FormatJSON {
 				formatter.PrintJSON()
@@ -162,16 +275,55 @@ func install(cmd *cobra.Command, _ []string) error {
 		packagesToTest = changedPackages
 	}
 
+	packagesToTest, err = zarf.FilterExcludedPackages(packagesToTest, configuration.ExcludedPackages)
+	if err == nil {
+		packagesToTest, err = zarf.FilterSelectedPackages(packagesToTest, configuration.Selector)
+	}
+	if err != nil {
+		formatter.Error("%v", err)
+		if format == output.FormatJSON {
+			formatter.PrintJSON()
+		}
+		return withExitCode(exitConfigError, err)
+	}
+	if configuration.ExcludeDeprecated {
+		packagesToTest = zarf.FilterDeprecatedPackages(packagesToTest)
+	}
+
 	if len(packagesToTest) == 0 {
 		formatter.Success("No packages to test")
 		if format == output.FormatJSON {
 			formatter.PrintJSON()
 		}
+		formatter.Event(output.EventRunSummary, zarf.NewInstallReport(nil).Summary)
 		return nil
 	}
 
+	// Deploy packages after the packages they depend on
+	var dependencies map[string][]string
+	dependencyGraph, err := zarf.BuildDependencyGraph(packagesToTest)
+	if err == nil {
+		dependencies = dependencyGraph.DeployDependencies(packagesToTest, configuration.DeployOrder)
+		packagesToTest, err = dependencyGraph.DeployOrder(packagesToTest, configuration.DeployOrder)
+	}
+	if err != nil {
+		formatter.Error("Failed to determine deploy order: %v", err)
+		if format == output.FormatJSON {
+			formatter.PrintJSON()
+		}
+		return fmt.Errorf("failed to determine deploy order: %w", err)
+	}
+
 	formatter.Info("Testing %d packages: %v", len(packagesToTest), packagesToTest)
 
+	if err := ensureZarf(cmd.Context(), formatter, configuration); err != nil {
+		formatter.Error("Failed to install zarf: %v", err)
+		if format == output.FormatJSON {
+			formatter.PrintJSON()
+		}
+		return withExitCode(exitEnvironmentError, fmt.Errorf("failed to install zarf: %w", err))
+	}
+
 	// Initialize deployer
 	deployer, err := zarf.NewDeployer(configuration)
 	if err != nil {
@@ -179,61 +331,360 @@ func install(cmd *cobra.Command, _ []string) error {
 		if format == output.FormatJSON {
 			formatter.PrintJSON()
 		}
-		return fmt.Errorf("failed to initialize deployer: %w", err)
+		return withExitCode(exitConfigError, fmt.Errorf("failed to initialize deployer: %w", err))
+	}
+	deployer.OnPackageStarted(func(packagePath string) {
+		formatter.Event(output.EventPackageStarted, map[string]string{"path": packagePath})
+	})
+
+	// Limit the whole run if a run timeout is configured
+	ctx := cmd.Context()
+	if configuration.RunTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, configuration.RunTimeout)
+		defer cancel()
+	}
+
+	// skipMessages returns why the remaining packages are skipped once ctx is done, as
+	// printed and as recorded for each package
+	skipMessages := func() (string, string) {
+		if cmd.Context().Err() != nil {
+			return "Interrupted", "Skipped, zt was interrupted"
+		}
+		return fmt.Sprintf("Run timeout of %s exceeded", configuration.RunTimeout), "Skipped, the run timeout was exceeded"
+	}
+
+	// reportResults prints the results of a package, records them for the report file
+	// and returns whether the package passed
+	var deploymentResults []*zarf.DeploymentResult
+	reportResults := func(cluster string, packagePath string, results []*zarf.DeploymentResult) bool {
+		passed := true
+		for _, result := range results {
+			result.Cluster = cluster
+			deploymentResults = append(deploymentResults, result)
+			formatter.Event(output.EventPackageFinished, zarf.NewDeploymentReport(result))
+			var details []string
+			if result.VariableSet != "" {
+				details = append(details, "variable set "+result.VariableSet)
+			}
+			if result.Components != "" {
+				details = append(details, "components "+result.Components)
+			}
+			if result.Mirror {
+				details = append(details, "mirror-resources")
+			}
+			if result.Cycles > 0 {
+				details = append(details, fmt.Sprintf("%d soak cycle(s)", result.Cycles))
+			}
+			if cluster != "" {
+				details = append([]string{"cluster " + cluster}, details...)
+			}
+			label := packagePath
+			if len(details) > 0 {
+				label = fmt.Sprintf("%s (%s)", packagePath, strings.Join(details, ", "))
+			}
+
+			if result.Success {
+				formatter.Success("Package %s passed all tests", label)
+			} else if result.TimedOut {
+				formatter.Error("Package %s timed out", label)
+				for _, msg := range result.Errors {
+					formatter.Error("  - %s", msg)
+				}
+				passed = false
+			} else {
+				formatter.Error("Package %s failed validation", label)
+				for _, msg := range result.Errors {
+					formatter.Error("  - %s", msg)
+				}
+				for _, testResult := range result.ComponentTests {
+					if !testResult.Success {
+						formatter.Warning("  - %s: %s", testResult.ComponentName, testResult.Message)
+					}
+				}
+				passed = false
+			}
+			if result.Benchmark != nil {
+				formatter.Info("  %s", benchmarkSummary(result.Benchmark))
+			}
+			if result.RemoveTime > 0 {
+				formatter.Info("  Removed in %s", result.RemoveTime.Round(100*time.Millisecond))
+			}
+			for _, artifact := range result.Artifacts {
+				formatter.Info("  Diagnostics: %s", artifact)
+			}
+		}
+		return passed
+	}
+
+	// recordFailure records a package that failed without deployment results
+	recordFailure := func(cluster string, packagePath string, message string) {
+		result := &zarf.DeploymentResult{PackagePath: packagePath, Cluster: cluster, Errors: []string{message}}
+		deploymentResults = append(deploymentResults, result)
+		formatter.Event(output.EventPackageFinished, zarf.NewDeploymentReport(result))
+	}
+
+	recordClusterFailure := func(cluster string, message string) {
+		for _, packagePath := range packagesToTest {
+			recordFailure(cluster, packagePath, message)
+		}
+	}
+
+	// allPackages marks every package as failed on a cluster that could not be prepared
+	allPackages := map[string]bool{}
+	for _, packagePath := range packagesToTest {
+		allPackages[packagePath] = true
+	}
+
+	// testCluster installs the packages into the cluster of clusterDeployer and returns
+	// the packages that failed. Errors preparing the cluster are returned.
+	testCluster := func(cluster string, clusterDeployer *zarf.Deployer) (map[string]bool, error) {
+		if err := clusterDeployer.WaitForCluster(ctx); err != nil {
+			formatter.Error("Cluster did not become reachable: %v", err)
+			recordClusterFailure(cluster, fmt.Sprintf("Cluster did not become reachable: %v", err))
+			return allPackages, fmt.Errorf("cluster did not become reachable within %s: %w", configuration.WaitForCluster, err)
+		}
+
+		// Fresh clusters must be initialized before packages can be deployed
+		initialized, err := clusterDeployer.EnsureZarfInitialized(ctx)
+		if err != nil {
+			formatter.Error("Failed to initialize cluster: %v", err)
+			recordClusterFailure(cluster, fmt.Sprintf("Failed to initialize cluster: %v", err))
+			return allPackages, fmt.Errorf("failed to initialize cluster: %w", err)
+		}
+		if initialized {
+			formatter.Info("Initialized cluster with zarf init")
+		}
+
+		// Create progress bar for package testing
+		progressBar := formatter.NewProgressBar("Testing packages", len(packagesToTest))
+		defer progressBar.Finish("Testing complete")
+
+		failed := map[string]bool{}
+		if configuration.ParallelDeploys > 1 {
+			// Packages are reported in the order they finish
+			// Without keep-going, the first package failing with an internal error
+			// cancels the remaining deployments
+			completed := 0
+			var skipped []string
+			stopped := ""
+			parallelCtx, stop := context.WithCancel(ctx)
+			defer stop()
+			clusterDeployer.TestPackagesInParallel(parallelCtx, packagesToTest, dependencies, func(deployment zarf.PackageDeployment) {
+				if deployment.Skipped {
+					skipped = append(skipped, deployment.PackagePath)
+					failed[deployment.PackagePath] = true
+					if stopped != "" {
+						recordFailure(cluster, deployment.PackagePath, "Skipped after an internal error deploying "+stopped)
+					} else {
+						_, recorded := skipMessages()
+						recordFailure(cluster, deployment.PackagePath, recorded)
+					}
+					return
+				}
+				completed++
+				formatter.Step(completed, len(packagesToTest), "Tested package: %s", deployment.PackagePath)
+				progressBar.Update(completed, fmt.Sprintf("Tested %s", deployment.PackagePath))
+				fmt.Fprint(outputWriter(porcelain), deployment.Output)
+				if deployment.Err != nil {
+					formatter.Error("Package %s failed: %v", deployment.PackagePath, deployment.Err)
+					failed[deployment.PackagePath] = true
+					recordFailure(cluster, deployment.PackagePath, deployment.Err.Error())
+					if !configuration.KeepGoing && stopped == "" {
+						stopped = deployment.PackagePath
+						stop()
+					}
+					return
+				}
+				if !reportResults(cluster, deployment.PackagePath, deployment.Results) {
+					failed[deployment.PackagePath] = true
+				}
+			})
+			if len(skipped) > 0 && stopped != "" {
+				formatter.Error("Stopped after an internal error deploying %s, skipped packages: %v", stopped, skipped)
+			} else if len(skipped) > 0 {
+				printed, _ := skipMessages()
+				formatter.Error("%s, skipped packages: %v", printed, skipped)
+			}
+			return failed, nil
+		}
+
+		for i, packagePath := range packagesToTest {
+			if ctx.Err() != nil {
+				printed, recorded := skipMessages()
+				formatter.Error("%s, skipping remaining packages: %v", printed, packagesToTest[i:])
+				for _, skipped := range packagesToTest[i:] {
+					failed[skipped] = true
+					recordFailure(cluster, skipped, recorded)
+				}
+				break
+			}
+
+			formatter.Step(i+1, len(packagesToTest), "Testing package: %s", packagePath)
+			progressBar.Update(i, fmt.Sprintf("Testing %s", packagePath))
+
+			results, err := clusterDeployer.TestPackage(ctx, packagePath)
+			if err != nil {
+				formatter.Error("Package %s failed: %v", packagePath, err)
+				failed[packagePath] = true
+				recordFailure(cluster, packagePath, err.Error())
+				if !configuration.KeepGoing {
+					formatter.Error("Stopped after an internal error deploying %s, skipping remaining packages: %v", packagePath, packagesToTest[i+1:])
+					for _, skipped := range packagesToTest[i+1:] {
+						failed[skipped] = true
+						recordFailure(cluster, skipped, "Skipped after an internal error deploying "+packagePath)
+					}
+					break
+				}
+				continue
+			}
+			if !reportResults(cluster, packagePath, results) {
+				failed[packagePath] = true
+			}
+		}
+		return failed, nil
 	}
 
-	// Create progress bar for package testing
-	progressBar := formatter.NewProgressBar("Testing packages", len(packagesToTest))
-	
-	// Test each package
 	overallSuccess := true
-	for i, packagePath := range packagesToTest {
-		formatter.Step(i+1, len(packagesToTest), "Testing package: %s", packagePath)
-		progressBar.Update(i, fmt.Sprintf("Testing %s", packagePath))
-		
-		result, err := deployer.TestPackage(packagePath)
+	var clusterErr error
+	if len(configuration.Clusters) == 0 {
+		// Test against the cluster of the current kubeconfig context
+		// The report is written even if the cluster could not be prepared
+		failed, err := testCluster("", deployer)
+		formatter.EndSection()
+		clusterErr = err
+
+		overallSuccess = len(failed) == 0
+		if clusterErr == nil {
+			formatter.Section("Results")
+			if overallSuccess {
+				formatter.Success("All packages passed deployment testing")
+			} else {
+				formatter.Error("Some packages failed deployment testing")
+			}
+			formatter.EndSection()
+		}
+	} else {
+		kubeconfigDir, err := os.MkdirTemp("", "zt-kubeconfig-")
 		if err != nil {
-			formatter.Error("Package %s failed: %v", packagePath, err)
-			overallSuccess = false
-			continue
+			return fmt.Errorf("failed to create directory for kubeconfig files: %w", err)
 		}
+		defer os.RemoveAll(kubeconfigDir)
+		formatter.EndSection()
 
-		if result.Success {
-			formatter.Success("Package %s passed all tests", packagePath)
-		} else {
-			formatter.Error("Package %s failed validation", packagePath)
-			for _, testResult := range result.ComponentTests {
-				if !testResult.Success {
-					formatter.Warning("  - %s: %s", testResult.ComponentName, testResult.Message)
+		// Clusters are tested one after the other, a cluster that cannot be prepared
+		// fails all packages on it
+		failures := make([]map[string]bool, len(configuration.Clusters))
+		for i, cluster := range configuration.Clusters {
+			if ctx.Err() != nil {
+				_, recorded := skipMessages()
+				recordClusterFailure(cluster.Name, recorded)
+				failures[i] = allPackages
+				continue
+			}
+			formatter.Section(fmt.Sprintf("Cluster %s", cluster.Name))
+			if cluster.KubeVersion != "" {
+				formatter.Progress("Creating kind cluster for Kubernetes %s...", cluster.KubeVersion)
+			}
+			clusterDeployer, err := deployer.ForCluster(ctx, cluster, kubeconfigDir)
+			if err == nil {
+				failures[i], _ = testCluster(cluster.Name, clusterDeployer)
+				// Clusters are deleted even if the run timed out or zt was interrupted
+				if deleted, err := clusterDeployer.DeleteCluster(context.WithoutCancel(cmd.Context())); err != nil {
+					formatter.Warning("%v", err)
+				} else if deleted != "" {
+					formatter.Info("Deleted kind cluster %s", deleted)
 				}
+			} else {
+				formatter.Error("%v", err)
+				recordClusterFailure(cluster.Name, err.Error())
+				failures[i] = allPackages
 			}
+			formatter.EndSection()
+		}
+
+		formatter.Section("Results")
+		for i, cluster := range configuration.Clusters {
+			if len(failures[i]) == 0 {
+				formatter.Success("Cluster %s: all %d packages passed deployment testing", cluster.Name, len(packagesToTest))
+				continue
+			}
+			formatter.Error("Cluster %s: %d of %d packages failed deployment testing", cluster.Name, len(failures[i]), len(packagesToTest))
 			overallSuccess = false
 		}
+
+		// Compatibility matrix of packages and clusters
+		if len(configuration.Clusters) > 1 {
+			headers := []string{"PACKAGE"}
+			for _, cluster := range configuration.Clusters {
+				headers = append(headers, cluster.Name)
+			}
+			var rows [][]string
+			for _, packagePath := range packagesToTest {
+				row := []string{packagePath}
+				for i := range configuration.Clusters {
+					if failures[i][packagePath] {
+						row = append(row, "failed")
+					} else {
+						row = append(row, "passed")
+					}
+				}
+				rows = append(rows, row)
+			}
+			formatter.Table(headers, rows)
+		}
+		formatter.EndSection()
 	}
 
-	progressBar.Finish("Testing complete")
-	formatter.EndSection()
-	
-	formatter.Section("Results")
-	
-	if overallSuccess {
-		formatter.Success("All packages passed deployment testing")
-	} else {
-		formatter.Error("Some packages failed deployment testing")
+	report := zarf.NewInstallReport(deploymentResults)
+	if configuration.ReportFile != "" {
+		if err := writeReport(configuration.ReportFile, configuration.ReportFormat, report); err != nil {
+			return err
+		}
 	}
-	
-	formatter.EndSection()
-	
+	emitMetrics(context.WithoutCancel(cmd.Context()), formatter, configuration, zarf.ReportKindInstall, report.Metrics(time.Since(start)))
+
 	// Output JSON if requested
-	if format == output.FormatJSON {
+	if porcelain {
+		fmt.Print(report.Porcelain())
+	} else if format == output.FormatJSON {
 		if err := formatter.PrintJSON(); err != nil {
 			return fmt.Errorf("failed to output JSON: %w", err)
 		}
+	} else {
+		formatter.Event(output.EventRunSummary, report.Summary)
 	}
 	
+	if clusterErr != nil {
+		return withExitCode(exitDeployFailures, clusterErr)
+	}
+	if cmd.Context().Err() != nil {
+		return withExitCode(exitDeployFailures, fmt.Errorf("interrupted: %d of %d deployment(s) failed or were skipped", report.Summary.Failed, report.Summary.Deployments))
+	}
 	if !overallSuccess {
-		os.Exit(1)
+		return withExitCode(exitDeployFailures, fmt.Errorf("package deployment testing failed: %d of %d deployment(s) failed", report.Summary.Failed, report.Summary.Deployments))
 	}
 	
 	return nil
 }
+
+// benchmarkSummary describes the timings of a deployment on one line
+func benchmarkSummary(benchmark *zarf.Benchmark) string {
+	summary := fmt.Sprintf("Deployed in %s", benchmark.Deploy.Round(100*time.Millisecond))
+	if benchmark.Baseline > 0 {
+		summary += fmt.Sprintf(" (baseline %s)", benchmark.Baseline.Round(100*time.Millisecond))
+	}
+	summary += fmt.Sprintf(", pulled images for %s", benchmark.ImagePull.Round(100*time.Millisecond))
+	var workloads []string
+	for workload := range benchmark.Ready {
+		workloads = append(workloads, workload)
+	}
+	sort.Strings(workloads)
+	for i, workload := range workloads {
+		workloads[i] = fmt.Sprintf("%s %s", workload, benchmark.Ready[workload])
+	}
+	if len(workloads) > 0 {
+		summary += ", ready: " + strings.Join(workloads, ", ")
+	}
+	return summary
+}
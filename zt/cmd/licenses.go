@@ -0,0 +1,114 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newLicensesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "licenses",
+		Short: "Report and check the licenses of the contents of Zarf packages",
+		Long: heredoc.Doc(`
+			Aggregate the licenses of the images and local charts of every package
+			and check them against --allowed-licenses and --denied-licenses.
+
+			The licenses of images are read from SBOMs: generated with syft, or read
+			from the SBOMs extracted from built packages with
+			'zarf package inspect sbom <package> --output <dir>' when --sbom-dir is
+			set. Local charts are licensed by their 'artifacthub.io/license'
+			annotation. zt exits with a non-zero code if a forbidden license is found.`),
+		RunE: licenses,
+	}
+
+	flags := cmd.Flags()
+	addCommonFlags(flags)
+	flags.Bool("all", false, "Report the licenses of all packages (the default)")
+	flags.Bool("changed", false, "Report the licenses of the changed packages only")
+	flags.StringSlice("packages", []string{}, heredoc.Doc(`
+		Specific packages to report the licenses of. May be specified multiple times
+		or separate values with commas`))
+	flags.StringSlice("allowed-licenses", []string{}, heredoc.Doc(`
+		SPDX license identifiers or glob patterns (e.g. 'BSD-*') of the allowed
+		licenses. Any license is allowed if empty`))
+	flags.StringSlice("denied-licenses", []string{}, heredoc.Doc(`
+		SPDX license identifiers or glob patterns (e.g. 'AGPL-*') of forbidden
+		licenses, taking precedence over --allowed-licenses`))
+	flags.String("sbom-dir", "", heredoc.Doc(`
+		Directory with the SBOMs of the packages extracted by 'zarf package inspect
+		sbom', read from <sbom-dir>/<package name>/*.json. SBOMs of images are
+		generated with syft if empty`))
+	flags.String("format", "text", "Output format of the licenses: text, yaml, json")
+	return cmd
+}
+
+func licenses(cmd *cobra.Command, _ []string) error {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	packageDirs, err := selectPackages(cmd, configuration)
+	if err != nil {
+		return err
+	}
+
+	scanner := zarf.LicenseScanner{
+		SBOMDir: configuration.SBOMDir,
+		Policy: zarf.LicensePolicy{
+			Allowed: configuration.AllowedLicenses,
+			Denied:  configuration.DeniedLicenses,
+		},
+	}
+	report := []zarf.PackageLicenses{}
+	forbidden := 0
+	for _, packageDir := range packageDirs {
+		licenses, err := scanner.Scan(cmd.Context(), packageDir)
+		if err != nil {
+			return fmt.Errorf("failed to collect the licenses of %s: %w", packageDir, err)
+		}
+		report = append(report, *licenses)
+		forbidden += len(licenses.Forbidden())
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	if format == "text" {
+		for _, pkg := range report {
+			for _, usage := range pkg.Licenses {
+				status := "ok"
+				if usage.Forbidden {
+					status = "forbidden: " + usage.Reason
+				}
+				fmt.Printf("%s\t%s\t%d\t%s\t%s\n", pkg.Package, usage.License, usage.Artifacts, strings.Join(usage.Sources, ", "), status)
+			}
+		}
+	} else if err := printDocument(report, format); err != nil {
+		return err
+	}
+
+	if forbidden > 0 {
+		return withExitCode(exitLintErrors, fmt.Errorf("%d forbidden license(s) found", forbidden))
+	}
+	return nil
+}
@@ -16,11 +16,13 @@ package cmd
 
 import (
 	"fmt"
-	"os"
-	"strings"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
 	"github.com/cpepper96/zarf-testing/pkg/output"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/yamllint"
 	"github.com/cpepper96/zarf-testing/pkg/zarf"
 	"github.com/spf13/cobra"
 	flag "github.com/spf13/pflag"
@@ -50,16 +52,119 @@ func newLintCmd() *cobra.Command {
 	flags := cmd.Flags()
 	addLintFlags(flags)
 	addCommonLintAndInstallFlags(flags)
+	flags.Bool("fix", false, heredoc.Doc(`
+		Apply safe automatic fixes to 'zarf.yaml' before linting and print a diff of
+		the changes: pin images to digests (with 'crane digest'), normalize component
+		names, add description stubs, sort image lists and remove 'default' from
+		required components`))
 	return cmd
 }
 
 func addLintFlags(flags *flag.FlagSet) {
 	flags.String("lint-conf", "", heredoc.Doc(`
-		The config file for YAML linting. If not specified, 'lintconf.yaml'
-		is searched in the current directory, '$HOME/.zt', and '/etc/zt', in
-		that order`))
+		The yamllint-compatible config file for YAML linting. If not specified,
+		'lintconf.yaml' is searched in the config search locations ('.', '.zt',
+		'etc', '$HOME/.zt', '/etc/zt', ...). If none is found, yamllint's relaxed
+		rules apply and report problems as warnings`))
 	flags.Bool("check-version-increment", true, "Activates a check for package version increments")
+	flags.Bool("require-major-bump-on-removal", false, heredoc.Doc(`
+		Require a breaking version bump (major, or minor for 0.x versions) when
+		components are removed from a package`))
+	flags.Bool("incremental", false, heredoc.Doc(`
+		Only run the rules depending on the files of a package changed since
+		'--since', or the merge base of the target branch and HEAD, e.g. skip the
+		image checks when only manifests changed`))
 	flags.Bool("validate-yaml", true, "Enable linting of 'zarf.yaml' and configuration files")
+	flags.String("kube-version", "", heredoc.Doc(`
+		Target Kubernetes version (e.g. '1.29') for manifest API checks. APIs removed
+		in this version are reported as errors and deprecated APIs as warnings.
+		If not specified, all deprecated APIs are reported as warnings`))
+	flags.String("fail-on", "error", heredoc.Doc(`
+		Minimum finding severity that fails the lint run: 'error', 'warning' or
+		'never'. With 'never', findings are reported without failing the run
+		unless '--max-warnings' is exceeded`))
+	flags.Int("max-warnings", -1, heredoc.Doc(`
+		Fail the lint run if the total number of warnings across all packages
+		exceeds this number. A negative value disables the limit`))
+	flags.String("baseline", "", heredoc.Doc(`
+		Baseline file written by '--write-baseline'. Findings recorded in the
+		baseline are suppressed, so only new findings are reported and can fail the run`))
+	flags.String("write-baseline", "", heredoc.Doc(`
+		Record all current findings to the given file (e.g. '.zt-baseline.json')
+		instead of failing the run. Use with '--baseline' to adopt zt on existing
+		packages and fix known findings over time`))
+	flags.String("large-file-warning", "50MB", heredoc.Doc(`
+		Warn about package files checked into Git (and not tracked with Git LFS)
+		larger than this size, e.g. '20MB'. '0' disables the warning`))
+	flags.String("large-file-limit", "100MB", heredoc.Doc(`
+		Fail on package files checked into Git (and not tracked with Git LFS)
+		larger than this size, e.g. '1GiB'. '0' disables the limit`))
+	flags.String("zarf-lint-extra-args", "", heredoc.Doc(`
+		Additional arguments for 'zarf dev lint', templated like --zarf-extra-args`))
+	flags.StringSlice("zarf-versions", []string{}, heredoc.Doc(`
+		Zarf versions (e.g. 'v0.38.0,v0.42.0') to download and build every package
+		with, in addition to linting with the installed zarf. Versions older than
+		the 'minZarfVersion' annotation of a package are skipped`))
+	flags.String("pss-level", "baseline", heredoc.Doc(`
+		Pod Security Standards level the workloads of manifests, kustomizations
+		and local charts are checked against: 'baseline', 'restricted', or
+		'privileged' to disable the checks`))
+	addImagePolicyFlags(flags)
+	flags.Bool("validate-rbac", true, heredoc.Doc(`
+		Report bindings to the cluster-admin role and RBAC rules granting all
+		verbs or all resources`))
+	flags.Bool("validate-network-policies", true, heredoc.Doc(`
+		Report namespaces a component deploys workloads to without a
+		NetworkPolicy in the package`))
+	flags.Bool("resolve-oci-imports", false, heredoc.Doc(`
+		Read the skeleton packages imported by oci:// URL with 'zarf package
+		inspect definition' to check that they define the imported components`))
+	flags.Bool("strict-yaml", false, heredoc.Doc(`
+		Report keys of zarf.yaml that the Zarf package schema does not define,
+		usually misspelled fields, as errors. Keys starting with 'x-' are allowed.
+		Keys defined more than once are always errors`))
+	flags.String("architecture", "", heredoc.Doc(`
+		Architecture the packages are tested on (amd64 or arm64), used to warn about
+		packages whose components are all limited to other architectures. Defaults
+		to the metadata.architecture of each package, else the architecture zt
+		runs on`))
+	flags.Bool("validate-docs", false, heredoc.Doc(`
+		Enable the docs rules, which require a README.md per package,
+		descriptions of every component and variable, and documented defaults
+		of prompted variables, e.g. for packages published in a catalog`))
+	flags.Bool("require-maintainers", false, heredoc.Doc(`
+		Require packages to list their maintainers in the 'maintainers' annotation
+		of metadata and to have owners in the CODEOWNERS file of the repository`))
+	flags.Bool("check-repos", false, heredoc.Doc(`
+		Check with 'git ls-remote' that the Git repositories of components are
+		reachable and define the tag or branch they are pinned to`))
+	flags.String("repo-size-budget", "0", heredoc.Doc(`
+		Warn about packages whose Git repositories are estimated to be larger in
+		total than this size, e.g. '500MiB'. Sizes of GitHub repositories are read
+		from the GitHub API. '0' disables the estimate`))
+	flags.Bool("check-chart-drift", false, heredoc.Doc(`
+		Warn about charts pulled from Helm repositories or OCI registries that are
+		further behind the latest version of their repository than allowed by
+		'--max-chart-major-behind' and '--max-chart-minor-behind'`))
+	addStalenessFlags(flags)
+	flags.Bool("check-duplicate-versions", false, heredoc.Doc(`
+		Warn about images and charts of a package that other packages in the
+		package directories use at a different tag, digest or version`))
+	flags.Bool("check-namespace-collisions", false, heredoc.Doc(`
+		Warn about namespaces a package deploys charts or manifests to that other
+		packages in the package directories deploy to as well`))
+	flags.Bool("scan-secrets", true, heredoc.Doc(`
+		Scan the package files for credentials such as private keys, cloud and
+		API tokens, and high-entropy values of secret-like keys`))
+	flags.StringSlice("secrets-allowlist", []string{}, heredoc.Doc(`
+		Regular expressions matched against the secrets found and the paths of
+		their files relative to the package; matching findings are skipped.
+		Lines with a 'zt:allow-secret' comment are always skipped`))
+	flags.Bool("plugins", true, heredoc.Doc(`
+		Run validation plugins, executables named 'zt-validate-*' found in
+		'--plugins-dir' or on PATH, against every package`))
+	flags.StringSlice("plugins-dir", []string{}, heredoc.Doc(`
+		Directories searched for 'zt-validate-*' plugins before PATH`))
 	flags.StringSlice("additional-commands", []string{}, heredoc.Doc(`
 		Additional commands to run per package (default: [])
 		Commands will be executed in the same order as provided in the list and will
@@ -69,105 +174,424 @@ func addLintFlags(flags *flag.FlagSet) {
 
 }
 
+// addImagePolicyFlags adds the flags of the image policy shared by lint and images
+func addImagePolicyFlags(flags *flag.FlagSet) {
+	flags.StringSlice("approved-images", []string{}, heredoc.Doc(`
+		Image prefixes (e.g. 'registry1.dso.mil/ironbank/') or glob patterns
+		(e.g. 'cgr.dev/chainguard/*') every image must match, unless it carries
+		the '--required-image-label'. Images of Docker Hub are matched as
+		'docker.io/library/nginx'`))
+	flags.String("required-image-label", "", heredoc.Doc(`
+		A 'key' or 'key=value' label images outside '--approved-images' must
+		carry, read from the registry with 'crane config'`))
+}
+
 func lint(cmd *cobra.Command, _ []string) error {
+	start := time.Now()
+
 	// Setup output formatter
 	outputFormat, _ := cmd.Flags().GetString("output")
 	noColor, _ := cmd.Flags().GetBool("no-color")
 	githubGroups, _ := cmd.Flags().GetBool("github-groups")
+	porcelain, _ := cmd.Flags().GetBool("porcelain")
 	
-	var format output.Format
-	switch strings.ToLower(outputFormat) {
-	case "json":
-		format = output.FormatJSON
-	case "github":
-		format = output.FormatGitHub
-	default:
-		format = output.FormatText
-	}
-	
+	format := output.ParseFormat(outputFormat)
 	formatter := output.NewFormatter(&output.Config{
 		Format:       format,
 		NoColor:      noColor,
 		GithubGroups: githubGroups,
-		Writer:       os.Stdout,
+		Writer:       outputWriter(porcelain),
 	})
 	
 	formatter.Section("Zarf Package Linting")
 	
-	// Get flags for package discovery
-	zarfDirs, err := cmd.Flags().GetStringSlice("zarf-dirs")
+	// Load configuration
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
 	if err != nil {
-		formatter.Error("Failed to get zarf-dirs flag: %v", err)
+		formatter.Error("Failed to load configuration: %v", err)
 		if format == output.FormatJSON {
 			formatter.PrintJSON()
 		}
-		return err
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
 	}
+	util.SetCacheDir(configuration.CacheDir)
 	
-	all, err := cmd.Flags().GetBool("all")
-	if err != nil {
-		return err
-	}
-	
-	packages, err := cmd.Flags().GetStringSlice("packages")
-	if err != nil {
-		return err
-	}
-	
-	var packageDirs []string
+	var packageDirs, bundleDirs []string
 	
 	// Determine which packages to lint
-	if len(packages) > 0 {
+	if len(configuration.Packages) > 0 {
 		// Specific packages specified
-		packageDirs = packages
-		fmt.Printf("Linting specified packages: %v\n", packages)
-	} else if all {
+		packageDirs = configuration.Packages
+		formatter.Info("Linting specified packages: %v", configuration.Packages)
+	} else if configuration.ProcessAllPackages {
 		// Lint all packages
-		packageDirs, err = zarf.FindZarfPackages(zarfDirs)
+		packageDirs, err = zarf.FindZarfPackages(configuration.ZarfDirs)
 		if err != nil {
 			return fmt.Errorf("failed to find packages: %w", err)
 		}
-		fmt.Printf("Linting all packages in directories: %v\n", zarfDirs)
+		formatter.Info("Linting all packages in directories: %v", configuration.ZarfDirs)
 	} else {
 		// Default: lint changed packages
-		remote, err := cmd.Flags().GetString("remote")
+		fetched, err := zarf.EnsureHistory(cmd.Context(), configuration.Remote, configuration.TargetBranch, configuration.Since, configuration.AutoFetch)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to find changed packages: %w", explainGitError(err))
 		}
-		targetBranch, err := cmd.Flags().GetString("target-branch")
+		if fetched {
+			formatter.Info("Fetched the history of %s/%s", configuration.Remote, configuration.TargetBranch)
+		}
+		packageDirs, err = zarf.FindChangedPackages(cmd.Context(), configuration.Remote, configuration.TargetBranch, configuration.Since, configuration.ZarfDirs)
 		if err != nil {
-			return err
+			return fmt.Errorf("failed to find changed packages: %w", explainGitError(err))
 		}
-		
-		packageDirs, err = zarf.FindChangedPackages(remote, targetBranch, zarfDirs)
+		bundleDirs, err = zarf.FindChangedBundles(cmd.Context(), configuration.Remote, configuration.TargetBranch, configuration.Since, configuration.BundleDirs, configuration.ZarfDirs, packageDirs)
 		if err != nil {
-			return fmt.Errorf("failed to find changed packages: %w", err)
+			return fmt.Errorf("failed to find changed bundles: %w", explainGitError(err))
 		}
 		
-		if len(packageDirs) == 0 {
-			fmt.Println("No changed packages found")
+		if len(packageDirs) == 0 && len(bundleDirs) == 0 {
+			formatter.Info("No changed packages found")
+			if format == output.FormatJSON {
+				return formatter.PrintDocument(zarf.NewLintReport(nil))
+			}
+			formatter.Event(output.EventRunSummary, zarf.NewLintReport(nil).Summary)
 			return nil
 		}
-		fmt.Printf("Linting changed packages: %v\n", packageDirs)
+		formatter.Info("Linting changed packages: %v", packageDirs)
 	}
 	
+	packageDirs, err = zarf.FilterExcludedPackages(packageDirs, configuration.ExcludedPackages)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	packageDirs, err = zarf.FilterSelectedPackages(packageDirs, configuration.Selector)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	if configuration.ExcludeDeprecated {
+		packageDirs = zarf.FilterDeprecatedPackages(packageDirs)
+	}
+	
+	if err := ensureZarf(cmd.Context(), formatter, configuration); err != nil {
+		return withExitCode(exitEnvironmentError, fmt.Errorf("failed to install zarf: %w", err))
+	}
+
 	// Create validator
-	validator := zarf.NewPackageValidator()
+	validator, err := newPackageValidator(configuration)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	for _, plugin := range validator.Plugins {
+		formatter.Info("Using plugin %s (%s)", plugin.Name, plugin.Path)
+	}
+
+	if configuration.Fix {
+		if err := fixPackages(cmd, formatter, validator, packageDirs, format != output.FormatJSON && format != output.FormatNDJSON && !porcelain); err != nil {
+			return err
+		}
+	}
+
+	// Download the zarf versions every package must build with
+	if len(configuration.ZarfVersions) > 0 {
+		cacheDir, err := toolsCacheDir(configuration)
+		if err != nil {
+			return err
+		}
+		validator.ZarfBinaries = map[string]string{}
+		for _, version := range configuration.ZarfVersions {
+			formatter.Progress("Downloading zarf %s...", version)
+			binary, err := zarf.DownloadZarf(cmd.Context(), version, cacheDir)
+			if err != nil {
+				return withExitCode(exitEnvironmentError, err)
+			}
+			validator.ZarfBinaries[version] = binary
+		}
+	}
 	
+	// Findings of the baseline are suppressed as each package is validated, so
+	// streamed events leave them out as well
+	var baseline *zarf.Baseline
+	if configuration.Baseline != "" && configuration.WriteBaseline == "" {
+		if baseline, err = zarf.LoadBaseline(configuration.Baseline); err != nil {
+			return withExitCode(exitConfigError, fmt.Errorf("failed to load baseline: %w", err))
+		}
+	}
+	validator.PackageStarted = func(path string) {
+		formatter.Event(output.EventPackageStarted, map[string]string{"path": path})
+	}
+	validator.PackageFinished = func(result *zarf.ValidationResult) {
+		if baseline != nil {
+			baseline.Apply([]*zarf.ValidationResult{result})
+		}
+		for _, finding := range result.Findings {
+			formatter.Event(output.EventFinding, findingEvent{Path: result.PackagePath, Finding: finding})
+		}
+		formatter.Event(output.EventPackageFinished, zarf.NewPackageReport(result))
+	}
+
 	// Validate packages
-	results, err := validator.ValidatePackages(packageDirs)
+	results, err := validator.ValidatePackages(cmd.Context(), packageDirs)
 	if err != nil {
 		return fmt.Errorf("failed to validate packages: %w", err)
 	}
+	bundleResults, err := validateBundles(configuration, validator, packageDirs, bundleDirs)
+	if err != nil {
+		return fmt.Errorf("failed to validate bundles: %w", err)
+	}
+	results = append(results, bundleResults...)
 	
-	// Print results
-	zarf.PrintValidationResults(results)
+	if configuration.WriteBaseline != "" {
+		baseline := zarf.NewBaseline(results)
+		if err := baseline.Write(configuration.WriteBaseline); err != nil {
+			return fmt.Errorf("failed to write baseline: %w", err)
+		}
+		formatter.Success("Wrote %d finding(s) to baseline %s", len(baseline.Findings), configuration.WriteBaseline)
+		if format == output.FormatJSON {
+			return formatter.PrintDocument(zarf.NewLintReport(results))
+		}
+		formatter.Event(output.EventRunSummary, zarf.NewLintReport(results).Summary)
+		return nil
+	}
 	
-	// Check if there were any errors
-	if zarf.HasValidationErrors(results) {
-		return fmt.Errorf("package validation failed")
+	// Print results
+	report := zarf.NewLintReport(results)
+	if configuration.ReportFile != "" {
+		if err := writeReport(configuration.ReportFile, configuration.ReportFormat, report); err != nil {
+			return err
+		}
+	}
+	emitMetrics(cmd.Context(), formatter, configuration, zarf.ReportKindLint, report.Metrics(time.Since(start)))
+	if porcelain {
+		fmt.Print(report.Porcelain())
+	} else if format == output.FormatJSON {
+		if err := formatter.PrintDocument(report); err != nil {
+			return fmt.Errorf("failed to write lint report: %w", err)
+		}
+	} else if format == output.FormatNDJSON {
+		formatter.Event(output.EventRunSummary, report.Summary)
+	} else {
+		printLintReport(formatter, report)
 	}
 	
-	fmt.Println("\nAll packages linted successfully")
+	// Apply the configured failure threshold
+	return withExitCode(exitLintErrors, report.Failure(configuration.FailOn, configuration.MaxWarnings))
+}
+
+// fixPackages applies the automatic fixes to the packages, reporting the fixes and,
+// with printDiff, the diff of each package
+func fixPackages(cmd *cobra.Command, formatter *output.Formatter, validator *zarf.PackageValidator, packageDirs []string, printDiff bool) error {
+	fixer := zarf.NewFixer()
+	if policy := validator.NamingPolicies[zarf.NamingComponent]; policy.Severity != zarf.SeverityOff {
+		fixer.ComponentNaming = policy.Pattern
+	} else {
+		fixer.ComponentNaming = nil
+	}
+	fixes := 0
+	for _, packageDir := range packageDirs {
+		result, err := fixer.Fix(cmd.Context(), packageDir)
+		if err != nil {
+			return fmt.Errorf("failed to fix %s: %w", packageDir, err)
+		}
+		for _, fix := range result.Fixes {
+			formatter.Info("%s: [%s] %s", packageDir, fix.RuleID, fix.Message)
+		}
+		for _, fix := range result.Skipped {
+			formatter.Warning("%s: [%s] %s", packageDir, fix.RuleID, fix.Message)
+		}
+		if printDiff && result.Diff != "" {
+			fmt.Fprint(cmd.OutOrStdout(), result.Diff)
+		}
+		fixes += len(result.Fixes)
+	}
+	formatter.Success("Applied %d fix(es)", fixes)
 	return nil
 }
+
+// validateBundles validates the UDS bundles in the bundle directories: the changed
+// bundles, all bundles with --all, or the bundles referencing the packages given with
+// --packages
+func validateBundles(configuration *config.Configuration, validator *zarf.PackageValidator, packageDirs, changedBundles []string) ([]*zarf.ValidationResult, error) {
+	if len(configuration.BundleDirs) == 0 {
+		return nil, nil
+	}
+	allPackages, err := zarf.FindZarfPackages(configuration.ZarfDirs)
+	if err != nil {
+		return nil, err
+	}
+	bundleDirs := changedBundles
+	if configuration.ProcessAllPackages || len(configuration.Packages) > 0 {
+		if bundleDirs, err = zarf.FindBundles(configuration.BundleDirs); err != nil {
+			return nil, err
+		}
+		if !configuration.ProcessAllPackages {
+			if bundleDirs, err = zarf.BundlesReferencing(bundleDirs, packageDirs, allPackages); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	var results []*zarf.ValidationResult
+	for _, bundleDir := range bundleDirs {
+		if validator.PackageStarted != nil {
+			validator.PackageStarted(bundleDir)
+		}
+		result, err := zarf.ValidateBundle(bundleDir, allPackages)
+		if err != nil {
+			return nil, err
+		}
+		if validator.PackageFinished != nil {
+			validator.PackageFinished(result)
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// findingEvent is the data of an NDJSON finding event
+type findingEvent struct {
+	Path string `json:"path"`
+	zarf.Finding
+}
+
+// newPackageValidator creates a validator configured from the lint options
+func newPackageValidator(configuration *config.Configuration) (*zarf.PackageValidator, error) {
+	validator := zarf.NewPackageValidator()
+	validator.KubeVersion = configuration.KubeVersion
+	validator.CheckVersionIncrement = configuration.CheckVersionIncrement
+	validator.Remote = configuration.Remote
+	validator.TargetBranch = configuration.TargetBranch
+	validator.Since = configuration.Since
+	validator.RequireMajorBumpOnRemoval = configuration.RequireMajorBumpOnRemoval
+	validator.Incremental = configuration.Incremental
+	validator.PSSLevel = configuration.PSSLevel
+	validator.ImagePolicy = newImagePolicy(configuration)
+	validator.ValidateRBAC = configuration.ValidateRBAC
+	validator.ValidateNetworkPolicies = configuration.ValidateNetworkPolicies
+	validator.ResolveOCIImports = configuration.ResolveOCIImports
+	validator.StrictYaml = configuration.StrictYaml
+	validator.Architecture = configuration.Architecture
+	validator.ValidateDocs = configuration.ValidateDocs
+	validator.RequireMaintainers = configuration.RequireMaintainers
+	validator.CheckRepos = configuration.CheckRepos
+	validator.RepoSizeBudget, _ = util.ParseSize(configuration.RepoSizeBudget)
+	if configuration.CheckChartDrift {
+		validator.ChartDrift = stalenessPolicy(configuration)
+	}
+	validator.KeepGoing = configuration.KeepGoing
+	if configuration.CheckDuplicateVersions || configuration.CheckNamespaceCollisions {
+		packageDirs, err := zarf.FindZarfPackages(configuration.ZarfDirs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find packages: %w", err)
+		}
+		if packageDirs, err = zarf.FilterExcludedPackages(packageDirs, configuration.ExcludedPackages); err != nil {
+			return nil, err
+		}
+		if configuration.CheckDuplicateVersions {
+			if validator.Duplicates, err = zarf.FindDuplicates(packageDirs); err != nil {
+				return nil, err
+			}
+		}
+		if configuration.CheckNamespaceCollisions {
+			if validator.NamespaceUsers, err = zarf.FindNamespaceUsers(packageDirs); err != nil {
+				return nil, err
+			}
+		}
+	}
+	validator.ScanSecrets = configuration.ScanSecrets
+	allowlist, err := zarf.CompileSecretsAllowlist(configuration.SecretsAllowlist)
+	if err != nil {
+		return nil, err
+	}
+	validator.SecretsAllowlist = allowlist
+	for entity, configured := range configuration.NamingPolicies {
+		policy, err := zarf.NewNamingPolicy(entity, configured.Pattern, configured.Severity)
+		if err != nil {
+			return nil, err
+		}
+		validator.NamingPolicies[entity] = policy
+	}
+	for rule, severity := range configuration.ActionRules {
+		if err := zarf.SetActionRule(validator.ActionRules, rule, severity); err != nil {
+			return nil, err
+		}
+	}
+	// Sizes are validated when the configuration is loaded
+	validator.LargeFileWarning, _ = util.ParseSize(configuration.LargeFileWarning)
+	validator.LargeFileLimit, _ = util.ParseSize(configuration.LargeFileLimit)
+	validator.ZarfArgs = zarf.ZarfArgs{
+		Global: configuration.ZarfExtraArgs,
+		Lint:   configuration.ZarfLintExtraArgs,
+		Build:  configuration.ZarfBuildExtraArgs,
+	}
+	if err := validator.ZarfArgs.Validate(); err != nil {
+		return nil, err
+	}
+	if configuration.ValidateYaml {
+		lintConfig := yamllint.DefaultConfig()
+		if configuration.LintConf != "" {
+			var err error
+			lintConfig, err = yamllint.LoadConfig(configuration.LintConf)
+			if err != nil {
+				return nil, fmt.Errorf("failed to load lint config: %w", err)
+			}
+		}
+		validator.YamlLintConfig = lintConfig
+	}
+	if configuration.Plugins {
+		plugins, err := zarf.DiscoverPlugins(configuration.PluginsDir)
+		if err != nil {
+			return nil, err
+		}
+		validator.Plugins = plugins
+	}
+	return validator, nil
+}
+
+// newImagePolicy returns the configured image policy, or nil if none is configured
+func newImagePolicy(configuration *config.Configuration) *zarf.ImagePolicy {
+	if len(configuration.ApprovedImages) == 0 && configuration.RequiredImageLabel == "" {
+		return nil
+	}
+	return &zarf.ImagePolicy{
+		Approved: configuration.ApprovedImages,
+		Label:    configuration.RequiredImageLabel,
+	}
+}
+
+// printLintReport prints the findings of each package followed by a summary
+func printLintReport(formatter *output.Formatter, report *zarf.LintReport) {
+	for _, pkg := range report.Packages {
+		formatter.Section(fmt.Sprintf("Linting %s", pkg.Path))
+		for _, finding := range pkg.Findings {
+			switch finding.Severity {
+			case zarf.SeverityError:
+				formatter.Error("[%s] %s", finding.RuleID, finding.Message)
+			case zarf.SeverityWarning:
+				formatter.Warning("[%s] %s", finding.RuleID, finding.Message)
+			default:
+				formatter.Info("[%s] %s", finding.RuleID, finding.Message)
+			}
+		}
+		if pkg.Valid {
+			formatter.Success("Package validation successful")
+		} else {
+			formatter.Error("Package validation failed")
+		}
+		formatter.Info("Quality score: %d/100 (%s)", pkg.Quality.Score, pkg.Quality.Grade)
+		formatter.EndSection()
+	}
+
+	summary := report.Summary
+	formatter.Section("Summary")
+	formatter.Info("%d package(s) linted: %d passed, %d failed, %d error(s), %d warning(s)",
+		summary.Packages, summary.Passed, summary.Failed, summary.Errors, summary.Warnings)
+	if summary.Suppressed > 0 {
+		formatter.Info("%d finding(s) suppressed by baseline", summary.Suppressed)
+	}
+	if summary.Grade != "" {
+		formatter.Info("Average quality score: %d/100 (%s)", summary.Score, summary.Grade)
+	}
+	if summary.Failed == 0 {
+		formatter.Success("All packages linted successfully")
+	}
+	formatter.EndSection()
+}
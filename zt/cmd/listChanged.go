@@ -16,6 +16,8 @@ package cmd
 
 import (
 	"fmt"
+	"os"
+	"strings"
 
 	"github.com/MakeNowJust/heredoc"
 	"github.com/cpepper96/zarf-testing/pkg/zarf"
@@ -29,12 +31,17 @@ func newListChangedCmd() *cobra.Command {
 		Short:   "List changed packages",
 		Long: heredoc.Doc(`
 			"List changed Zarf packages based on configured package directories,
-			"remote, and target branch`),
+			"remote, and target branch
+
+			With --owners, the owners of each package are printed after it, separated
+			by a tab: the maintainers the package declares, else the code owners of
+			its zarf.yaml in CODEOWNERS`),
 		RunE: listChanged,
 	}
 
 	flags := cmd.Flags()
 	addCommonFlags(flags)
+	flags.Bool("owners", false, "Print the owners of each package after it, separated by a tab")
 	return cmd
 }
 
@@ -50,21 +57,87 @@ func listChanged(cmd *cobra.Command, _ []string) error {
 		return err
 	}
 	
+	since, err := cmd.Flags().GetString("since")
+	if err != nil {
+		return err
+	}
+	
 	zarfDirs, err := cmd.Flags().GetStringSlice("zarf-dirs")
 	if err != nil {
 		return err
 	}
 	
+	autoFetch, err := cmd.Flags().GetBool("auto-fetch")
+	if err != nil {
+		return err
+	}
+	
+	// Packages are printed to stdout, so progress goes to stderr
+	fetched, err := zarf.EnsureHistory(cmd.Context(), remote, targetBranch, since, autoFetch)
+	if err != nil {
+		return fmt.Errorf("failed to find changed packages: %w", explainGitError(err))
+	}
+	if fetched {
+		fmt.Fprintf(os.Stderr, "Fetched the history of %s/%s\n", remote, targetBranch)
+	}
+	
 	// Find changed packages
-	changedPackages, err := zarf.FindChangedPackages(remote, targetBranch, zarfDirs)
+	changedPackages, err := zarf.FindChangedPackages(cmd.Context(), remote, targetBranch, since, zarfDirs)
+	if err != nil {
+		return fmt.Errorf("failed to find changed packages: %w", explainGitError(err))
+	}
+	
+	excludedPackages, err := cmd.Flags().GetStringSlice("excluded-packages")
+	if err != nil {
+		return err
+	}
+	changedPackages, err = zarf.FilterExcludedPackages(changedPackages, excludedPackages)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	
+	selector, err := cmd.Flags().GetString("selector")
+	if err != nil {
+		return err
+	}
+	changedPackages, err = zarf.FilterSelectedPackages(changedPackages, selector)
 	if err != nil {
-		return fmt.Errorf("failed to find changed packages: %w", err)
+		return withExitCode(exitConfigError, err)
+	}
+	
+	excludeDeprecated, err := cmd.Flags().GetBool("exclude-deprecated")
+	if err != nil {
+		return err
+	}
+	if excludeDeprecated {
+		changedPackages = zarf.FilterDeprecatedPackages(changedPackages)
 	}
 	
 	// Output each changed package directory
+	owners, err := cmd.Flags().GetBool("owners")
+	if err != nil {
+		return err
+	}
 	for _, pkg := range changedPackages {
+		if owners {
+			fmt.Printf("%s\t%s\n", pkg, strings.Join(zarf.PackageOwners(pkg), ", "))
+			continue
+		}
 		fmt.Println(pkg)
 	}
 	
+	// Followed by the bundles referencing them
+	bundleDirs, err := cmd.Flags().GetStringSlice("bundle-dirs")
+	if err != nil {
+		return err
+	}
+	changedBundles, err := zarf.FindChangedBundles(cmd.Context(), remote, targetBranch, since, bundleDirs, zarfDirs, changedPackages)
+	if err != nil {
+		return fmt.Errorf("failed to find changed bundles: %w", explainGitError(err))
+	}
+	for _, bundle := range changedBundles {
+		fmt.Println(bundle)
+	}
+	
 	return nil
 }
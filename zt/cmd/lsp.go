@@ -0,0 +1,65 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/lsp"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newLspCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a language server publishing lint findings as diagnostics",
+		Long: heredoc.Doc(`
+			Start a Language Server Protocol server on stdin/stdout so editors can
+			show zt findings inline.
+
+			When a file inside a Zarf package is opened or saved, the package is
+			linted from disk and the findings are published as diagnostics for
+			'zarf.yaml' and the other files of the package.`),
+		RunE: runLsp,
+	}
+
+	flags := cmd.Flags()
+	addLintFlags(flags)
+	addCommonFlags(flags)
+	return cmd
+}
+
+func runLsp(cmd *cobra.Command, _ []string) error {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	validator, err := newPackageValidator(configuration)
+	if err != nil {
+		return err
+	}
+
+	server := lsp.NewServer(func(packagePath string) (*zarf.ValidationResult, error) {
+		return validator.ValidatePackage(cmd.Context(), packagePath)
+	})
+	return server.Serve(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,38 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/metrics"
+	"github.com/cpepper96/zarf-testing/pkg/output"
+)
+
+// emitMetrics writes the metrics of a run to the configured file and Pushgateway.
+// Failing to emit metrics does not fail the run.
+func emitMetrics(ctx context.Context, formatter *output.Formatter, configuration *config.Configuration, command string, set *metrics.Set) {
+	if configuration.MetricsFile != "" {
+		if err := set.WriteFile(configuration.MetricsFile); err != nil {
+			formatter.Warning("%v", err)
+		}
+	}
+	if configuration.MetricsPushgateway != "" {
+		if err := set.Push(ctx, configuration.MetricsPushgateway, configuration.MetricsJob, "command", command); err != nil {
+			formatter.Warning("%v", err)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"text/tabwriter"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+// outdatedReport is the document printed by 'zt outdated' with --format
+type outdatedReport struct {
+	Charts []zarf.OutdatedChart `yaml:"charts" json:"charts"`
+	Images []zarf.OutdatedImage `yaml:"images,omitempty" json:"images,omitempty"`
+}
+
+func newOutdatedCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "outdated",
+		Short: "List charts and images behind the latest version of their repository",
+		Long: heredoc.Doc(`
+			List the charts of the packages pulled from Helm repositories or OCI
+			registries that are behind the latest version published in their
+			repository, with the size of the update. Charts further behind than
+			'--max-chart-major-behind' and '--max-chart-minor-behind' are marked
+			as stale. Versions are read from the index.yaml of Helm repositories
+			and with 'crane ls' from OCI registries.
+
+			With --images, the tags of images are checked for newer patch releases
+			with 'crane ls', e.g. 'nginx:1.25.3-alpine' for 'nginx:1.25.4-alpine',
+			and images based on a distro past or within 90 days of its end of life
+			are listed. Distros are read from the SBOMs in --sbom-dir, extracted
+			with 'zarf package inspect sbom', or generated with syft.
+
+			All packages in the package directories are included by default.
+			'zt lint --check-chart-drift' warns about stale charts of the linted
+			packages.`),
+		RunE: outdated,
+	}
+
+	flags := cmd.Flags()
+	addCommonFlags(flags)
+	addStalenessFlags(flags)
+	flags.Bool("all", false, "Check all packages (the default)")
+	flags.Bool("changed", false, "Check the changed packages only")
+	flags.StringSlice("packages", []string{}, heredoc.Doc(`
+		Specific packages to check. May be specified multiple times or separate
+		values with commas`))
+	flags.Bool("images", false, "Check the images of the packages as well")
+	flags.String("sbom-dir", "", heredoc.Doc(`
+		Directory of SBOMs extracted with 'zarf package inspect sbom', read from
+		<sbom-dir>/<package name>/<image>.json to find the distros of images.
+		SBOMs are generated with syft if not specified`))
+	flags.Bool("fail-on-stale", false, heredoc.Doc(`
+		Exit with 1 if any chart is stale or, with --images, any image is based on
+		a distro past its end of life`))
+	flags.String("format", "text", "Output format of the outdated charts: text, yaml, json")
+	return cmd
+}
+
+// addStalenessFlags adds the flags of the chart staleness policy shared by lint and
+// outdated
+func addStalenessFlags(flags *flag.FlagSet) {
+	flags.Int("max-chart-major-behind", 0, "Major versions a chart may lag behind the latest version of its repository")
+	flags.Int("max-chart-minor-behind", 3, heredoc.Doc(`
+		Minor versions a chart on the latest major version may lag behind the latest
+		version of its repository`))
+}
+
+// stalenessPolicy returns the configured chart staleness policy
+func stalenessPolicy(configuration *config.Configuration) *zarf.StalenessPolicy {
+	return &zarf.StalenessPolicy{
+		MaxMajorBehind: configuration.MaxChartMajorBehind,
+		MaxMinorBehind: configuration.MaxChartMinorBehind,
+	}
+}
+
+func outdated(cmd *cobra.Command, _ []string) error {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	packageDirs, err := selectPackages(cmd, configuration)
+	if err != nil {
+		return err
+	}
+	policy := stalenessPolicy(configuration)
+	repositories := zarf.NewChartRepositories()
+	report := outdatedReport{Charts: []zarf.OutdatedChart{}}
+	stale := 0
+	for _, packageDir := range packageDirs {
+		charts, err := repositories.OutdatedCharts(cmd.Context(), packageDir, *policy)
+		if err != nil {
+			return err
+		}
+		for _, chart := range charts {
+			if chart.Stale {
+				stale++
+			}
+		}
+		report.Charts = append(report.Charts, charts...)
+	}
+
+	if checkImages, _ := cmd.Flags().GetBool("images"); checkImages {
+		freshness := zarf.NewImageFreshness()
+		freshness.SBOMDir = configuration.SBOMDir
+		if _, err := exec.LookPath("syft"); err == nil || freshness.SBOMDir != "" {
+			freshness.CheckEOL = true
+		} else {
+			fmt.Fprintln(os.Stderr, "syft not found in PATH and no --sbom-dir given, skipping the end of life checks of images")
+		}
+		report.Images = []zarf.OutdatedImage{}
+		for _, packageDir := range packageDirs {
+			images, err := freshness.OutdatedImages(cmd.Context(), packageDir)
+			if err != nil {
+				return err
+			}
+			for _, image := range images {
+				if image.EndOfLife {
+					stale++
+				}
+			}
+			report.Images = append(report.Images, images...)
+		}
+	}
+
+	format, _ := cmd.Flags().GetString("format")
+	if format != "text" {
+		if err := printDocument(report, format); err != nil {
+			return err
+		}
+	} else if err := printOutdated(report); err != nil {
+		return err
+	}
+
+	if failOnStale, _ := cmd.Flags().GetBool("fail-on-stale"); failOnStale && stale > 0 {
+		return withExitCode(exitLintErrors, fmt.Errorf("%d chart(s) or image(s) are stale", stale))
+	}
+	return nil
+}
+
+// printOutdated prints tables of the outdated charts and images, those that could not
+// be checked are listed on stderr
+func printOutdated(report outdatedReport) error {
+	if len(report.Charts) == 0 && len(report.Images) == 0 {
+		fmt.Println("All charts and images are up to date")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if len(report.Charts) > 0 {
+		fmt.Fprintln(w, "PACKAGE\tCOMPONENT\tCHART\tVERSION\tLATEST\tUPDATE")
+	}
+	for _, chart := range report.Charts {
+		if chart.Error != "" {
+			fmt.Fprintln(os.Stderr, chart.Message())
+			continue
+		}
+		update := chart.Behind
+		if chart.Stale {
+			update += " (stale)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", chart.Package, chart.Component, chart.Chart, chart.Version, chart.Latest, update)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	if len(report.Images) > 0 {
+		if len(report.Charts) > 0 {
+			fmt.Println()
+		}
+		fmt.Fprintln(w, "PACKAGE\tCOMPONENT\tIMAGE\tLATEST\tDISTRO\tEOL")
+	}
+	for _, image := range report.Images {
+		if image.Error != "" {
+			fmt.Fprintf(os.Stderr, "Could not check image %s of component '%s': %s\n", image.Image, image.Component, image.Error)
+		}
+		if image.Latest == "" && image.EOL == "" {
+			continue
+		}
+		distro, eol := "", image.EOL
+		if image.Distro != nil {
+			distro = image.Distro.String()
+		}
+		if image.EndOfLife {
+			eol += " (reached)"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", image.Package, image.Component, image.Image, image.Latest, distro, eol)
+	}
+	return w.Flush()
+}
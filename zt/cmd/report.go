@@ -0,0 +1,95 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+func newReportCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report <results.json>...",
+		Short: "Render saved results as a Markdown report",
+		Long: heredoc.Doc(`
+			Render lint and install results saved with '--report-format json' (or
+			the output of 'zt lint --output json') as a Markdown summary for pull
+			request comments. The reports of several files are concatenated.`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: report,
+	}
+
+	cmd.Flags().String("output-file", "", "File to write the report to instead of stdout")
+	return cmd
+}
+
+func report(cmd *cobra.Command, args []string) error {
+	var reports []string
+	for _, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read results: %w", err)
+		}
+		markdown, err := zarf.ReportMarkdown(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		reports = append(reports, markdown)
+	}
+
+	markdown := strings.Join(reports, "\n")
+	outputFile, _ := cmd.Flags().GetString("output-file")
+	if outputFile == "" {
+		fmt.Print(markdown)
+		return nil
+	}
+	return os.WriteFile(outputFile, []byte(markdown), 0644)
+}
+
+// markdownReport is a report that can be written as Markdown, JSON or a GitLab
+// Code Quality report
+type markdownReport interface {
+	Markdown() string
+	CodeQuality() []zarf.CodeQualityIssue
+}
+
+// writeReport writes report to path in format, markdown, json or codequality
+func writeReport(path string, format string, report markdownReport) error {
+	var content []byte
+	switch format {
+	case "json", "codequality":
+		var document interface{} = report
+		if format == "codequality" {
+			document = report.CodeQuality()
+		}
+		var err error
+		if content, err = json.MarshalIndent(document, "", "  "); err != nil {
+			return err
+		}
+		content = append(content, '\n')
+	default:
+		content = []byte(report.Markdown())
+	}
+	if err := os.WriteFile(path, content, 0644); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,211 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+)
+
+const defaultHistoryStore = "file://.zt/history.jsonl"
+
+func newResultsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "results",
+		Short: "Record results and report trends over time",
+	}
+	cmd.AddCommand(newResultsPushCmd())
+	cmd.AddCommand(newResultsTrendsCmd())
+	return cmd
+}
+
+func newResultsPushCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "push <results.json>...",
+		Short: "Append saved results to the history store",
+		Long: heredoc.Doc(`
+			Append lint and install results saved with '--report-format json' to the
+			history store, recording the commit, branch and build they belong to.
+			The store is a file of JSON lines, which CI can keep between runs like
+			any other cache, a SQLite database (sqlite://, using the sqlite3 CLI) or
+			a bucket prefix (s3:// or gs://, using the aws or gcloud CLI).`),
+		Args: cobra.MinimumNArgs(1),
+		RunE: resultsPush,
+	}
+
+	flags := cmd.Flags()
+	flags.String("store", defaultHistoryStore, "History store, a path or a file://, sqlite://, s3:// or gs:// URL")
+	flags.String("commit", "", "Commit the results belong to (default: HEAD of the repository)")
+	flags.String("branch", "", "Branch the results belong to (default: the checked out branch)")
+	flags.String("build-id", "", "ID of the CI build the results belong to")
+	return cmd
+}
+
+func newResultsTrendsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "trends",
+		Short: "Report trends of the results in the history store",
+		Long: heredoc.Doc(`
+			Report the slowest packages to lint and deploy, the lint rules that fail
+			most frequently and the flaky installs, deployments that both passed and
+			failed, across the runs in the history store.`),
+		RunE: resultsTrends,
+	}
+
+	flags := cmd.Flags()
+	flags.String("store", defaultHistoryStore, "History store, a path or a file://, sqlite://, s3:// or gs:// URL")
+	flags.String("since", "", "Only include runs within this period, e.g. 30d or 72h (default: all runs)")
+	flags.Int("limit", 10, "Maximum number of entries of each trend")
+	flags.String("format", "text", "Output format of the trends: text, yaml, json")
+	return cmd
+}
+
+func resultsPush(cmd *cobra.Command, args []string) error {
+	location, _ := cmd.Flags().GetString("store")
+	store, err := zarf.OpenHistoryStore(location)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+
+	commit, _ := cmd.Flags().GetString("commit")
+	branch, _ := cmd.Flags().GetString("branch")
+	buildID, _ := cmd.Flags().GetString("build-id")
+	if commit == "" {
+		// Results can be pushed outside of a repository, they are just not linked to
+		// a commit then
+		head, headBranch, err := tool.NewGit("").Head(cmd.Context())
+		if err == nil {
+			commit = head
+			if branch == "" {
+				branch = headBranch
+			}
+		}
+	}
+
+	var runs []zarf.HistoryRun
+	for _, path := range args {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read results: %w", err)
+		}
+		lint, install, err := zarf.ParseReport(content)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		run := zarf.HistoryRun{Commit: commit, Branch: branch, BuildID: buildID, Lint: lint, Install: install}
+		if lint != nil {
+			run.Timestamp = lint.Timestamp
+		} else {
+			run.Timestamp = install.Timestamp
+		}
+		runs = append(runs, run)
+	}
+
+	if err := store.Append(cmd.Context(), runs); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Pushed %d run(s) to %s\n", len(runs), location)
+	return nil
+}
+
+func resultsTrends(cmd *cobra.Command, _ []string) error {
+	location, _ := cmd.Flags().GetString("store")
+	period, _ := cmd.Flags().GetString("since")
+	limit, _ := cmd.Flags().GetInt("limit")
+	format, _ := cmd.Flags().GetString("format")
+
+	store, err := zarf.OpenHistoryStore(location)
+	if err != nil {
+		return withExitCode(exitConfigError, err)
+	}
+	var since time.Time
+	if period != "" {
+		duration, err := parsePeriod(period)
+		if err != nil {
+			return withExitCode(exitConfigError, err)
+		}
+		since = time.Now().Add(-duration)
+	}
+	runs, err := store.Runs(cmd.Context(), since)
+	if err != nil {
+		return err
+	}
+
+	trends := zarf.NewTrends(runs, limit)
+	if format != "text" {
+		return printDocument(trends, format)
+	}
+	printTrends(trends)
+	return nil
+}
+
+// parsePeriod parses a duration that may also be given in days, e.g. 30d
+func parsePeriod(period string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(period, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid period %q", period)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	duration, err := time.ParseDuration(period)
+	if err != nil {
+		return 0, fmt.Errorf("invalid period %q: %w", period, err)
+	}
+	return duration, nil
+}
+
+// printTrends prints a table per trend
+func printTrends(trends *zarf.Trends) {
+	if trends.Runs == 0 {
+		fmt.Println("No runs recorded")
+		return
+	}
+	fmt.Printf("%d run(s) from %s to %s\n", trends.Runs, trends.From, trends.To)
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "\nSlowest packages")
+	fmt.Fprintln(w, "  PACKAGE\tKIND\tRUNS\tAVERAGE\tMAX")
+	for _, timing := range trends.SlowestPackages {
+		fmt.Fprintf(w, "  %s\t%s\t%d\t%s\t%s\n", timing.Path, timing.Kind, timing.Runs, seconds(timing.Average), seconds(timing.Max))
+	}
+
+	fmt.Fprintln(w, "\nMost frequently failing rules")
+	fmt.Fprintln(w, "  RULE\tERRORS\tWARNINGS\tPACKAGES")
+	for _, rule := range trends.FailingRules {
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%d\n", rule.Rule, rule.Errors, rule.Warnings, rule.Packages)
+	}
+
+	fmt.Fprintln(w, "\nFlaky installs")
+	fmt.Fprintln(w, "  DEPLOYMENT\tPASSED\tFAILED\tFLIPS")
+	for _, flaky := range trends.FlakyInstalls {
+		fmt.Fprintf(w, "  %s\t%d\t%d\t%d\n", flaky.Label(), flaky.Passed, flaky.Failed, flaky.Flips)
+	}
+	w.Flush()
+}
+
+// seconds formats a duration in seconds to a tenth of a second
+func seconds(value float64) string {
+	return time.Duration(value * float64(time.Second)).Round(100 * time.Millisecond).String()
+}
@@ -15,16 +15,29 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"log/slog"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
 
 	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/logging"
+	"github.com/cpepper96/zarf-testing/pkg/tool"
+	"github.com/cpepper96/zarf-testing/pkg/tracing"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
 )
 
 var (
-	cfgFile string
+	cfgFile  string
+	logFile  *os.File
+	rootSpan *tracing.Span
 )
 
 func NewRootCmd() *cobra.Command {
@@ -39,40 +52,182 @@ func NewRootCmd() *cobra.Command {
 			* all packages
 
 			in given package directories.`),
-		SilenceUsage: true,
+		SilenceUsage:      true,
+		PersistentPreRunE: setup,
 	}
 
+	flags := cmd.PersistentFlags()
+	flags.String("log-level", "warn", heredoc.Doc(`
+		Level of the diagnostic log: debug, info, warn or error. At debug, every
+		external command zt runs is logged with its duration and result. Can also
+		be set with ZT_LOG_LEVEL`))
+	flags.String("log-format", "text", "Format of the diagnostic log: text or json. Can also be set with ZT_LOG_FORMAT")
+	flags.String("log-file", "", heredoc.Doc(`
+		File to append the diagnostic log to instead of stderr. Can also be set with
+		ZT_LOG_FILE`))
+	flags.String("otel-endpoint", "", heredoc.Doc(`
+		OTLP/HTTP endpoint to export OpenTelemetry traces of the run to, e.g.
+		http://localhost:4318. Can also be set with OTEL_EXPORTER_OTLP_ENDPOINT;
+		headers are read from OTEL_EXPORTER_OTLP_HEADERS`))
+
 	cmd.AddCommand(newLintCmd())
 	cmd.AddCommand(newInstallCmd())
 	cmd.AddCommand(newLintAndInstallCmd())
+	cmd.AddCommand(newCICmd())
 	cmd.AddCommand(newListChangedCmd())
+	cmd.AddCommand(newBumpCmd())
+	cmd.AddCommand(newLspCmd())
+	cmd.AddCommand(newServeCmd())
+	cmd.AddCommand(newAdmissionWebhookCmd())
+	cmd.AddCommand(newGraphCmd())
+	cmd.AddCommand(newInspectCmd())
+	cmd.AddCommand(newDiffCmd())
+	cmd.AddCommand(newImagesCmd())
+	cmd.AddCommand(newDuplicatesCmd())
+	cmd.AddCommand(newOutdatedCmd())
+	cmd.AddCommand(newUpdatePlanCmd())
+	cmd.AddCommand(newUpdateCmd())
+	cmd.AddCommand(newLicensesCmd())
+	cmd.AddCommand(newCatalogCmd())
+	cmd.AddCommand(newReportCmd())
+	cmd.AddCommand(newResultsCmd())
+	cmd.AddCommand(newConfigCmd())
 	cmd.AddCommand(newVersionCmd())
+	cmd.AddCommand(newCompletionCmd())
 	cmd.AddCommand(newGenerateDocsCmd())
+	registerPackageCompletions(cmd)
 
 	cmd.DisableAutoGenTag = true
+	cmd.SetFlagErrorFunc(func(_ *cobra.Command, err error) error {
+		return withExitCode(exitConfigError, err)
+	})
 
 	return cmd
 }
 
-// Execute runs the application
+// Execute runs the application and exits with the exit code for the result of the
+// command. Interrupting zt cancels the context of the running command, which kills
+// the external tools it started and lets the command clean up. A second interrupt
+// terminates zt right away.
 func Execute() {
-	if err := NewRootCmd().Execute(); err != nil {
-		fmt.Println(err)
-		os.Exit(1)
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		if sig, ok := <-signals; ok {
+			fmt.Fprintf(os.Stderr, "Received %s, cleaning up. Interrupt again to exit immediately\n", sig)
+			signal.Stop(signals)
+			cancel()
+		}
+	}()
+	err := NewRootCmd().ExecuteContext(ctx)
+	signal.Stop(signals)
+	close(signals)
+	cancel()
+	rootSpan.End(err)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	if err := tracing.Shutdown(shutdownCtx); err != nil {
+		slog.Warn("Failed to export traces", "error", err)
+	}
+	cancel()
+	if logFile != nil {
+		logFile.Close()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(exitCode(err))
 	}
 }
 
+// setup configures the diagnostic log and tracing before running a command
+func setup(cmd *cobra.Command, args []string) error {
+	if err := setupLogging(cmd, args); err != nil {
+		return err
+	}
+	return setupTracing(cmd)
+}
+
+// envFlag returns the value of the flag name, or of the environment variable env if
+// the flag is not set
+func envFlag(cmd *cobra.Command, name, env string) string {
+	value, _ := cmd.Flags().GetString(name)
+	if envValue, ok := os.LookupEnv(env); !cmd.Flags().Changed(name) && ok {
+		return envValue
+	}
+	return value
+}
+
+// setupLogging configures the diagnostic log from the log flags, falling back to the
+// ZT_LOG_* environment variables
+func setupLogging(cmd *cobra.Command, _ []string) error {
+	flag := func(name string) string {
+		return envFlag(cmd, name, "ZT_"+strings.ToUpper(strings.ReplaceAll(name, "-", "_")))
+	}
+
+	out := io.Writer(os.Stderr)
+	if path := flag("log-file"); path != "" {
+		file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return fmt.Errorf("failed to open log file: %w", err)
+		}
+		logFile, out = file, file
+	}
+	return logging.Setup(out, flag("log-level"), flag("log-format"))
+}
+
+// setupTracing starts exporting traces if an OTLP endpoint is configured, and starts
+// the span of the command, which Execute ends
+func setupTracing(cmd *cobra.Command) error {
+	endpoint := envFlag(cmd, "otel-endpoint", "OTEL_EXPORTER_OTLP_ENDPOINT")
+	if endpoint == "" {
+		return nil
+	}
+	headers, err := tracing.ParseHeaders(os.Getenv("OTEL_EXPORTER_OTLP_HEADERS"))
+	if err != nil {
+		return withExitCode(exitConfigError, fmt.Errorf("invalid OTEL_EXPORTER_OTLP_HEADERS: %w", err))
+	}
+	tracing.Setup(tracing.Config{Endpoint: endpoint, Headers: headers, ServiceName: "zt", ServiceVersion: Version})
+
+	var ctx context.Context
+	ctx, rootSpan = tracing.Start(cmd.Context(), cmd.CommandPath())
+	cmd.SetContext(ctx)
+	return nil
+}
+
 func addCommonFlags(flags *pflag.FlagSet) {
 	flags.StringVar(&cfgFile, "config", "", "Config file")
+	flags.String("profile", "", heredoc.Doc(`
+		Name of a profile in the 'profiles' section of the config file whose settings
+		override the other settings of the config file, e.g. 'ci' or 'local'`))
 	flags.String("remote", "origin", "The name of the Git remote used to identify changed charts")
 	flags.String("target-branch", "main", "The name of the target branch used to identify changed packages")
-	flags.String("since", "HEAD", "The Git reference used to identify changed packages")
+	flags.String("since", "HEAD", heredoc.Doc(`
+		The Git reference used to identify changed packages. Packages are compared
+		against this reference (e.g. the last release tag), or against the merge base
+		of the target branch and HEAD if it is HEAD`))
+	flags.String("cache-dir", "", heredoc.Doc(`
+		Directory to cache parsed zarf.yaml files in across runs, e.g. .zt-cache.
+		Files are cached by content, so the cache never needs to be cleared.
+		Parsed files are only cached in memory if empty`))
+	flags.Bool("auto-fetch", false, heredoc.Doc(`
+		Fetch the target branch and tags with the git CLI, unshallowing shallow clones,
+		when the history needed to identify changed packages is missing`))
 	flags.StringSlice("zarf-dirs", []string{"packages"}, heredoc.Doc(`
 		Directories containing Zarf packages. May be specified multiple times
 		or separate values with commas`))
+	flags.StringSlice("bundle-dirs", []string{}, heredoc.Doc(`
+		Directories containing UDS bundles ('uds-bundle.yaml'). Bundles are listed
+		and linted when a package they reference changed. May be specified multiple
+		times or separate values with commas`))
 	flags.StringSlice("excluded-packages", []string{}, heredoc.Doc(`
-		Packages that should be skipped. May be specified multiple times
+		Packages that should be skipped. Accepts package names, paths and
+		glob patterns such as 'team-a/*' or '**/examples', or a regular
+		expression prefixed with 're:'. May be specified multiple times
 		or separate values with commas`))
+	flags.String("selector", "", heredoc.Doc(`
+		Only include packages whose zarf.yaml metadata annotations match the
+		selector, e.g. 'team=platform,tier!=experimental'. Supports key=value,
+		key!=value, key (annotation is set) and !key (annotation is not set)`))
 	flags.Bool("print-config", false, "Prints the configuration to stderr")
 	flags.Bool("exclude-deprecated", false, "Skip packages that are marked as deprecated")
 	flags.Bool("github-groups", false, heredoc.Doc(`
@@ -80,7 +235,7 @@ func addCommonFlags(flags *pflag.FlagSet) {
 		for command output`))
 	
 	// Output formatting flags
-	flags.String("output", "text", "Output format: text, json, github")
+	flags.String("output", "text", "Output format: text, json, ndjson, github, gitlab, teamcity")
 	flags.Bool("no-color", false, "Disable colored output")
 }
 
@@ -93,7 +248,62 @@ func addCommonLintAndInstallFlags(flags *pflag.FlagSet) {
 		Specific packages to test. Disables changed package detection and
 		version increment checking. May be specified multiple times
 		or separate values with commas`))
+	flags.Bool("keep-going", true, heredoc.Doc(`
+		Record an internal error linting or deploying a package in the results of the
+		package and continue with the next one, reporting all results at the end.
+		Stops at the first such error if disabled`))
+	flags.String("install-zarf", "", heredoc.Doc(`
+		Download the given zarf release (e.g. 'v0.42.0') into the cache directory and
+		use it instead of the zarf CLI on the PATH. Without a version, the latest
+		release is downloaded only if zarf is not installed`))
+	flags.Lookup("install-zarf").NoOptDefVal = "latest"
+	flags.String("report-file", "", heredoc.Doc(`
+		Write a report of the results to this file, e.g. report.md to post as a pull
+		request comment`))
+	flags.String("report-format", "markdown", heredoc.Doc(`
+		Format of the report file: 'markdown' for a summary with a table per package
+		and collapsible details, 'json' to render it later with 'zt report', or
+		'codequality' for a GitLab Code Quality report artifact`))
+	flags.String("metrics-file", "", heredoc.Doc(`
+		Write metrics of the run in the Prometheus text format to this file, e.g. in
+		the directory of the node exporter's textfile collector`))
+	flags.String("metrics-pushgateway", "", heredoc.Doc(`
+		URL of a Prometheus Pushgateway to push metrics of the run to, e.g.
+		http://pushgateway:9091`))
+	flags.String("metrics-job", "zt", "Job name the metrics are pushed to the Pushgateway with")
+	flags.Bool("porcelain", false, heredoc.Doc(`
+		Print only the results, as tab-separated lines in a format that stays stable
+		across releases, for scripts to parse`))
+	flags.String("zarf-extra-args", "", heredoc.Doc(`
+		Additional arguments for every zarf command building, deploying or linting
+		packages (e.g. "--log-level debug"). Rendered as a Go template with the
+		package directory as {{ .PackagePath }} and split like a shell would`))
+	flags.String("zarf-build-extra-args", "", heredoc.Doc(`
+		Additional arguments for 'zarf package create' (e.g. "--set VERSION=1.0.0"),
+		templated like --zarf-extra-args`))
 
 
 	flags.Bool("debug", false, "Print CLI calls of external tools to stdout")
 }
+
+// explainGitError adds guidance on how to resolve errors reading the Git repository
+func explainGitError(err error) error {
+	switch {
+	case errors.Is(err, tool.ErrShallowClone):
+		return withExitCode(exitEnvironmentError, fmt.Errorf("%w; fetch the full history, e.g. with 'git fetch --unshallow' or 'fetch-depth: 0' for actions/checkout, or pass '--auto-fetch'", err))
+	case errors.Is(err, tool.ErrReferenceNotFound):
+		return withExitCode(exitEnvironmentError, fmt.Errorf("%w; fetch the target branch, e.g. with 'git fetch origin main', pass '--auto-fetch' or check '--remote', '--target-branch' and '--since'", err))
+	case errors.Is(err, tool.ErrNotRepository):
+		return withExitCode(exitEnvironmentError, fmt.Errorf("%w; run zt inside a Git repository or select packages with '--all' or '--packages'", err))
+	}
+	return err
+}
+
+// outputWriter returns where the formatter writes its messages: stdout, or nowhere
+// with --porcelain, which reserves stdout for the porcelain records
+func outputWriter(porcelain bool) io.Writer {
+	if porcelain {
+		return io.Discard
+	}
+	return os.Stdout
+}
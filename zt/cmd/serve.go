@@ -0,0 +1,144 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/server"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarftesting"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Run an HTTP server validating uploaded packages",
+		Long: heredoc.Doc(`
+			Start an HTTP server that lints packages submitted to it, so developer
+			portals can validate packages on upload without running CI.
+
+			POST /v1/lint accepts a 'zarf.yaml' (Content-Type: application/yaml),
+			or a tar archive of the package directory (application/x-tar, or
+			application/gzip when compressed), and responds with the findings as
+			JSON. GET /healthz reports that the server is running.
+
+			Uploads are linted with the lint options, except that the version
+			increment check is disabled as uploads have no Git history. Uploads
+			referring to files outside of the package fail with file-reference
+			errors, and are not validated further.`),
+		Example: heredoc.Doc(`
+			zt serve --port 8080
+			curl --data-binary @zarf.yaml -H 'Content-Type: application/yaml' localhost:8080/v1/lint
+			tar -czf - -C packages/podinfo . | curl --data-binary @- -H 'Content-Type: application/gzip' localhost:8080/v1/lint`),
+		RunE: serve,
+	}
+
+	flags := cmd.Flags()
+	flags.Int("port", 8080, "Port to listen on")
+	flags.String("address", "", "Address to listen on, all interfaces if empty")
+	flags.String("max-upload-size", "100MB", "Maximum size of an uploaded package, also once decompressed, e.g. '20MB'")
+	addLintFlags(flags)
+	addCommonFlags(flags)
+	return cmd
+}
+
+func serve(cmd *cobra.Command, _ []string) error {
+	port, _ := cmd.Flags().GetInt("port")
+	address, _ := cmd.Flags().GetString("address")
+	maxUploadSize, _ := cmd.Flags().GetString("max-upload-size")
+	maxUpload, err := util.ParseSize(maxUploadSize)
+	if err != nil || maxUpload <= 0 {
+		return withExitCode(exitConfigError, fmt.Errorf("invalid value %q for '--max-upload-size'", maxUploadSize))
+	}
+
+	linter, err := newServiceLinter(cmd)
+	if err != nil {
+		return err
+	}
+	return listenAndServe(cmd, address, port, server.NewServer(linter, maxUpload), "", "")
+}
+
+// newServiceLinter creates a linter for the packages submitted to a server, configured
+// from the lint options. Submitted packages have no Git history, so the version
+// increment check is disabled, and must not refer to files outside of their directory.
+// The options are applied after the lint options.
+func newServiceLinter(cmd *cobra.Command, opts ...zarftesting.Option) (*zarftesting.Linter, error) {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return nil, withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	validator, err := newPackageValidator(configuration)
+	if err != nil {
+		return nil, withExitCode(exitConfigError, err)
+	}
+	validator.CheckVersionIncrement = false
+	validator.ConfinePaths = true
+	linter, err := zarftesting.NewLinter(append([]zarftesting.Option{
+		zarftesting.WithValidator(validator),
+		zarftesting.WithFailOn(configuration.FailOn),
+		zarftesting.WithMaxWarnings(configuration.MaxWarnings),
+	}, opts...)...)
+	if err != nil {
+		return nil, withExitCode(exitConfigError, err)
+	}
+	return linter, nil
+}
+
+// listenAndServe serves handler until the command is interrupted, with TLS if a
+// certificate is given
+func listenAndServe(cmd *cobra.Command, address string, port int, handler http.Handler, certFile, keyFile string) error {
+	httpServer := &http.Server{
+		Addr:              net.JoinHostPort(address, strconv.Itoa(port)),
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	listener, err := net.Listen("tcp", httpServer.Addr)
+	if err != nil {
+		return withExitCode(exitEnvironmentError, err)
+	}
+	fmt.Fprintf(cmd.OutOrStdout(), "Listening on %s\n", listener.Addr())
+
+	// Stop accepting requests on SIGINT or SIGTERM and let running requests finish
+	done := make(chan error, 1)
+	go func() {
+		<-cmd.Context().Done()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		done <- httpServer.Shutdown(ctx)
+	}()
+	if certFile != "" {
+		err = httpServer.ServeTLS(listener, certFile, keyFile)
+	} else {
+		err = httpServer.Serve(listener)
+	}
+	if !errors.Is(err, http.ErrServerClosed) {
+		return withExitCode(exitEnvironmentError, err)
+	}
+	slog.Info("Server stopped")
+	return <-done
+}
@@ -0,0 +1,70 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/output"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+)
+
+// toolsCacheDir returns the directory downloaded tools are cached in, the configured
+// cache directory or zt in the user cache directory
+func toolsCacheDir(configuration *config.Configuration) (string, error) {
+	if configuration.CacheDir != "" {
+		return configuration.CacheDir, nil
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to determine cache directory: %w", err)
+	}
+	return filepath.Join(dir, "zt"), nil
+}
+
+// ensureZarf downloads and uses the zarf release requested with --install-zarf. The
+// latest release is only downloaded if zarf is not installed.
+func ensureZarf(ctx context.Context, formatter *output.Formatter, configuration *config.Configuration) error {
+	version := configuration.InstallZarf
+	if version == "" {
+		return nil
+	}
+	if version == "latest" {
+		if _, err := osexec.LookPath("zarf"); err == nil {
+			return nil
+		}
+		var err error
+		if version, err = zarf.LatestZarfVersion(ctx); err != nil {
+			return err
+		}
+	}
+
+	cacheDir, err := toolsCacheDir(configuration)
+	if err != nil {
+		return err
+	}
+	formatter.Progress("Installing zarf %s...", version)
+	binary, err := zarf.DownloadZarf(ctx, version, cacheDir)
+	if err != nil {
+		return err
+	}
+	formatter.Info("Using zarf %s from %s", version, binary)
+	return zarf.UseZarf(binary)
+}
@@ -0,0 +1,181 @@
+// Copyright The Helm Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/MakeNowJust/heredoc"
+	"github.com/cpepper96/zarf-testing/pkg/config"
+	"github.com/cpepper96/zarf-testing/pkg/util"
+	"github.com/cpepper96/zarf-testing/pkg/zarf"
+	"github.com/spf13/cobra"
+	flag "github.com/spf13/pflag"
+)
+
+func newUpdatePlanCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update-plan",
+		Short: "Plan the updates of the charts, images and repositories of packages",
+		Long: heredoc.Doc(`
+			List the updates available for the packages: charts from Helm
+			repositories and OCI registries are updated to the latest version of
+			their repository, images and Git repositories pinned to a tag to the
+			newest patch release of that tag. Images pinned to a digest are pinned
+			to the digest of the new tag.
+
+			With '--output json', the plan is printed as a document for bots opening
+			pull requests, listing the old and new version of every update with the
+			file, line, column and YAML path of the value to change. The plan can be
+			applied with 'zt update --apply --plan <file>'.
+
+			All packages in the package directories are included by default.`),
+		RunE: updatePlan,
+	}
+
+	flags := cmd.Flags()
+	addUpdateFlags(flags)
+	return cmd
+}
+
+func newUpdateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "update",
+		Short: "Update the charts, images and repositories of packages",
+		Long: heredoc.Doc(`
+			Plan the updates of the packages like 'zt update-plan' and, with --apply,
+			write them to the zarf.yaml of the packages, keeping the comments and
+			formatting of the files. Without --apply, the updates are only listed.
+
+			With --plan, the updates of a plan written by 'zt update-plan --output
+			json' are applied instead, e.g. a plan edited by a bot. Updates of values
+			that changed since the plan was made are skipped.`),
+		RunE: update,
+	}
+
+	flags := cmd.Flags()
+	addUpdateFlags(flags)
+	flags.Bool("apply", false, "Write the updates to the zarf.yaml of the packages")
+	flags.String("plan", "", "Apply the updates of this plan written by 'zt update-plan --output json'")
+	return cmd
+}
+
+// addUpdateFlags adds the flags shared by update-plan and update
+func addUpdateFlags(flags *flag.FlagSet) {
+	addCommonFlags(flags)
+	flags.Bool("all", false, "Update all packages (the default)")
+	flags.Bool("changed", false, "Update the changed packages only")
+	flags.StringSlice("packages", []string{}, heredoc.Doc(`
+		Specific packages to update. May be specified multiple times or separate
+		values with commas`))
+	flags.StringSlice("types", []string{}, heredoc.Doc(`
+		Types of updates to plan: chart, image, repo. All types are planned if not
+		specified`))
+}
+
+// planUpdates plans the updates of the selected packages
+func planUpdates(cmd *cobra.Command) (*zarf.UpdatePlan, error) {
+	configuration, err := config.LoadConfiguration(cfgFile, cmd, false)
+	if err != nil {
+		return nil, withExitCode(exitConfigError, fmt.Errorf("failed to load configuration: %w", err))
+	}
+	util.SetCacheDir(configuration.CacheDir)
+
+	packageDirs, err := selectPackages(cmd, configuration)
+	if err != nil {
+		return nil, err
+	}
+	updater := zarf.NewUpdater()
+	updater.Types, _ = cmd.Flags().GetStringSlice("types")
+	for _, kind := range updater.Types {
+		if kind != zarf.UpdateChart && kind != zarf.UpdateImage && kind != zarf.UpdateRepo {
+			return nil, withExitCode(exitConfigError, fmt.Errorf("invalid update type %q, must be one of: chart, image, repo", kind))
+		}
+	}
+	return updater.Plan(cmd.Context(), packageDirs)
+}
+
+func updatePlan(cmd *cobra.Command, _ []string) error {
+	plan, err := planUpdates(cmd)
+	if err != nil {
+		return err
+	}
+	output, _ := cmd.Flags().GetString("output")
+	if output != "text" {
+		return printDocument(plan, output)
+	}
+	return printUpdates(plan.Updates, plan.Errors)
+}
+
+func update(cmd *cobra.Command, _ []string) error {
+	var plan *zarf.UpdatePlan
+	if planFile, _ := cmd.Flags().GetString("plan"); planFile != "" {
+		content, err := os.ReadFile(planFile)
+		if err != nil {
+			return err
+		}
+		plan = &zarf.UpdatePlan{}
+		if err := json.Unmarshal(content, plan); err != nil {
+			return fmt.Errorf("failed to read plan %s: %w", planFile, err)
+		}
+	} else {
+		var err error
+		if plan, err = planUpdates(cmd); err != nil {
+			return err
+		}
+	}
+
+	if apply, _ := cmd.Flags().GetBool("apply"); !apply {
+		return printUpdates(plan.Updates, plan.Errors)
+	}
+	applied, errs := zarf.ApplyUpdates(plan.Updates)
+	for _, u := range applied {
+		fmt.Printf("%s:%d: updated %s %s from %s to %s\n", u.File, u.Line, u.Type, u.Name, u.From, u.To)
+	}
+	for _, message := range plan.Errors {
+		fmt.Fprintln(os.Stderr, message)
+	}
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(applied) == 0 && len(errs) == 0 {
+		fmt.Println("No updates to apply")
+	}
+	if len(errs) > 0 {
+		return withExitCode(exitLintErrors, fmt.Errorf("%d update(s) could not be applied", len(errs)))
+	}
+	return nil
+}
+
+// printUpdates prints a table of the updates, the charts, images and repositories that
+// could not be checked are listed on stderr
+func printUpdates(updates []zarf.Update, messages []string) error {
+	for _, message := range messages {
+		fmt.Fprintln(os.Stderr, message)
+	}
+	if len(updates) == 0 {
+		fmt.Println("All charts, images and repositories are up to date")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "PACKAGE\tCOMPONENT\tTYPE\tNAME\tFROM\tTO\tUPDATE")
+	for _, u := range updates {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", u.Package, u.Component, u.Type, u.Name, u.From, u.To, u.Bump)
+	}
+	return w.Flush()
+}